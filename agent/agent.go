@@ -31,6 +31,7 @@ import (
 	"tailscale.com/types/key"
 
 	"cdr.dev/slog"
+	"github.com/coder/coder/agent/asciicast"
 	"github.com/coder/coder/agent/usershell"
 	"github.com/coder/coder/peer"
 	"github.com/coder/coder/peer/peerwg"
@@ -40,9 +41,13 @@ import (
 )
 
 const (
-	ProtocolReconnectingPTY = "reconnecting-pty"
-	ProtocolSSH             = "ssh"
-	ProtocolDial            = "dial"
+	ProtocolReconnectingPTY       = "reconnecting-pty"
+	ProtocolSharedReconnectingPTY = "shared-reconnecting-pty"
+	ProtocolSSH                   = "ssh"
+	ProtocolDial                  = "dial"
+	ProtocolSessions              = "sessions"
+	ProtocolTerminateSession      = "terminate-session"
+	ProtocolListeningPorts        = "listening-ports"
 
 	// MagicSessionErrorCode indicates that something went wrong with the session, rather than the
 	// command just returning a nonzero exit code, and is chosen as an arbitrary, high number
@@ -55,8 +60,20 @@ type Options struct {
 	UploadWireguardKeys    UploadWireguardKeys
 	ListenWireguardPeers   ListenWireguardPeers
 	ReconnectingPTYTimeout time.Duration
-	EnvironmentVariables   map[string]string
-	Logger                 slog.Logger
+	// ReconnectingPTYBufferSize is the number of bytes of output that
+	// reconnecting PTYs replay to new connections. Defaults to 64KiB.
+	ReconnectingPTYBufferSize int
+	// PersistReconnectingPTYs runs reconnecting PTYs in tmux sessions, so
+	// they survive restarts of the agent.
+	PersistReconnectingPTYs bool
+	EnvironmentVariables    map[string]string
+	UploadSessionRecording  UploadSessionRecording
+	// HealthChecks are run every HealthCheckInterval, and the results are
+	// sent to ReportHealth. Defaults to one minute.
+	HealthChecks        []HealthCheck
+	HealthCheckInterval time.Duration
+	ReportHealth        ReportHealth
+	Logger              slog.Logger
 }
 
 type Metadata struct {
@@ -64,6 +81,7 @@ type Metadata struct {
 	EnvironmentVariables map[string]string  `json:"environment_variables"`
 	StartupScript        string             `json:"startup_script"`
 	Directory            string             `json:"directory"`
+	RecordSessions       bool               `json:"record_sessions"`
 }
 
 type WireguardPublicKeys struct {
@@ -71,9 +89,32 @@ type WireguardPublicKeys struct {
 	Disco  key.DiscoPublic `json:"disco"`
 }
 
+type SessionRecordingType string
+
+const (
+	SessionRecordingTypeSSH             SessionRecordingType = "ssh"
+	SessionRecordingTypeReconnectingPTY SessionRecordingType = "reconnecting_pty"
+)
+
+// SessionRecordingTokenEnv is the environment variable SSH clients set to
+// the session recording token coderd issued to them.
+const SessionRecordingTokenEnv = "CODER_SESSION_RECORDING_TOKEN"
+
+// SessionRecording is a terminal session recorded in the asciicast v2 format.
+type SessionRecording struct {
+	Type SessionRecordingType `json:"type"`
+	// Token is the session recording token of the user that started the
+	// session. It's empty if the client didn't send one.
+	Token     uuid.UUID `json:"token"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	Data      []byte    `json:"data"`
+}
+
 type Dialer func(ctx context.Context, logger slog.Logger) (Metadata, *peerbroker.Listener, error)
 type UploadWireguardKeys func(ctx context.Context, keys WireguardPublicKeys) error
 type ListenWireguardPeers func(ctx context.Context, logger slog.Logger) (<-chan peerwg.Handshake, func(), error)
+type UploadSessionRecording func(ctx context.Context, recording SessionRecording) error
 
 func New(dialer Dialer, options *Options) io.Closer {
 	if options == nil {
@@ -82,10 +123,18 @@ func New(dialer Dialer, options *Options) io.Closer {
 	if options.ReconnectingPTYTimeout == 0 {
 		options.ReconnectingPTYTimeout = 5 * time.Minute
 	}
+	if options.ReconnectingPTYBufferSize == 0 {
+		options.ReconnectingPTYBufferSize = 64 << 10
+	}
+	if options.HealthCheckInterval == 0 {
+		options.HealthCheckInterval = time.Minute
+	}
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	server := &agent{
 		dialer:                 dialer,
 		reconnectingPTYTimeout: options.ReconnectingPTYTimeout,
+		reconnectingPTYBuffer:  options.ReconnectingPTYBufferSize,
+		persistPTYs:            options.PersistReconnectingPTYs,
 		logger:                 options.Logger,
 		closeCancel:            cancelFunc,
 		closed:                 make(chan struct{}),
@@ -93,6 +142,10 @@ func New(dialer Dialer, options *Options) io.Closer {
 		enableWireguard:        options.EnableWireguard,
 		postKeys:               options.UploadWireguardKeys,
 		listenWireguardPeers:   options.ListenWireguardPeers,
+		uploadSessionRecording: options.UploadSessionRecording,
+		healthChecks:           options.HealthChecks,
+		healthCheckInterval:    options.HealthCheckInterval,
+		reportHealth:           options.ReportHealth,
 	}
 	server.init(ctx)
 	return server
@@ -104,6 +157,9 @@ type agent struct {
 
 	reconnectingPTYs       sync.Map
 	reconnectingPTYTimeout time.Duration
+	reconnectingPTYBuffer  int
+	persistPTYs            bool
+	tmuxConfig             tmuxConfigFile
 
 	connCloseWait sync.WaitGroup
 	closeCancel   context.CancelFunc
@@ -120,6 +176,16 @@ type agent struct {
 	network              *peerwg.Network
 	postKeys             UploadWireguardKeys
 	listenWireguardPeers ListenWireguardPeers
+
+	uploadSessionRecording UploadSessionRecording
+
+	healthChecks        []HealthCheck
+	healthCheckInterval time.Duration
+	reportHealth        ReportHealth
+	healthChecksStarted atomic.Bool
+
+	sessions       sessionRegistry
+	listeningPorts listeningPortsHandler
 }
 
 func (a *agent) run(ctx context.Context) {
@@ -164,6 +230,18 @@ func (a *agent) run(ctx context.Context) {
 		}()
 	}
 
+	if len(a.healthChecks) > 0 && a.reportHealth != nil && a.healthChecksStarted.CAS(false, true) {
+		// Health checks run with the metadata of the workspace, so they
+		// start after the first connection.
+		a.closeMutex.Lock()
+		a.connCloseWait.Add(1)
+		a.closeMutex.Unlock()
+		go func() {
+			defer a.connCloseWait.Done()
+			a.runHealthChecks(ctx)
+		}()
+	}
+
 	if a.enableWireguard {
 		err = a.startWireguard(ctx, metadata.WireguardAddresses)
 		if err != nil {
@@ -244,8 +322,16 @@ func (a *agent) handlePeerConn(ctx context.Context, conn *peer.Conn) {
 			go a.sshServer.HandleConn(channel.NetConn())
 		case ProtocolReconnectingPTY:
 			go a.handleReconnectingPTY(ctx, channel.Label(), channel.NetConn())
+		case ProtocolSharedReconnectingPTY:
+			go a.handleSharedReconnectingPTY(ctx, channel.Label(), channel.NetConn())
 		case ProtocolDial:
 			go a.handleDial(ctx, channel.Label(), channel.NetConn())
+		case ProtocolSessions:
+			go a.handleSessions(ctx, channel.NetConn())
+		case ProtocolTerminateSession:
+			go a.handleTerminateSession(ctx, channel.Label(), channel.NetConn())
+		case ProtocolListeningPorts:
+			go a.handleListeningPorts(ctx, channel.NetConn())
 		default:
 			a.logger.Warn(ctx, "unhandled protocol from channel",
 				slog.F("protocol", channel.Protocol()),
@@ -273,7 +359,7 @@ func (a *agent) init(ctx context.Context) {
 	a.sshServer = &ssh.Server{
 		ChannelHandlers: map[string]ssh.ChannelHandler{
 			"direct-tcpip": ssh.DirectTCPIPHandler,
-			"session":      ssh.DefaultSessionHandler,
+			"session":      a.handleSessionChannel,
 		},
 		ConnectionFailedCallback: func(conn net.Conn, err error) {
 			sshLogger.Info(ctx, "ssh connection ended", slog.Error(err))
@@ -323,7 +409,19 @@ func (a *agent) init(ctx context.Context) {
 		},
 		SubsystemHandlers: map[string]ssh.SubsystemHandler{
 			"sftp": func(session ssh.Session) {
-				server, err := sftp.NewServer(session)
+				tracked := a.sessions.start(SessionTypeSSH, session.User(), "sftp", func() {
+					_ = session.Close()
+				})
+				defer tracked.end()
+				server, err := sftp.NewServer(struct {
+					io.Reader
+					io.Writer
+					io.Closer
+				}{
+					Reader: tracked.reader(session),
+					Writer: tracked.writer(session),
+					Closer: session,
+				})
 				if err != nil {
 					a.logger.Debug(session.Context(), "initialize sftp server", slog.Error(err))
 					return
@@ -432,7 +530,17 @@ func (a *agent) createCommand(ctx context.Context, rawCommand string, env []stri
 }
 
 func (a *agent) handleSSHSession(session ssh.Session) (retErr error) {
-	cmd, err := a.createCommand(session.Context(), session.RawCommand(), session.Environ())
+	ctx, cancelFunc := context.WithCancel(session.Context())
+	defer cancelFunc()
+	tracked := a.sessions.start(SessionTypeSSH, session.User(), session.RawCommand(), func() {
+		// Canceling the context kills the command.
+		cancelFunc()
+		_ = session.Close()
+	})
+	defer tracked.end()
+
+	env, recordingToken := sessionRecordingToken(session.Environ())
+	cmd, err := a.createCommand(ctx, session.RawCommand(), env)
 	if err != nil {
 		return err
 	}
@@ -470,19 +578,28 @@ func (a *agent) handleSSHSession(session ssh.Session) (retErr error) {
 		if err != nil {
 			return xerrors.Errorf("resize ptty: %w", err)
 		}
+		output := tracked.writer(session)
+		recorder := a.startRecording(sshPty.Window.Width, sshPty.Window.Height, sshPty.Term)
+		if recorder != nil {
+			output = io.MultiWriter(output, recorder)
+			defer a.uploadRecording(SessionRecordingTypeSSH, recordingToken, recorder)
+		}
 		go func() {
 			for win := range windowSize {
 				resizeErr := ptty.Resize(uint16(win.Height), uint16(win.Width))
 				if resizeErr != nil {
 					a.logger.Warn(context.Background(), "failed to resize tty", slog.Error(resizeErr))
 				}
+				if recorder != nil {
+					recorder.Resize(win.Width, win.Height)
+				}
 			}
 		}()
 		go func() {
-			_, _ = io.Copy(ptty.Input(), session)
+			_, _ = io.Copy(ptty.Input(), tracked.reader(session))
 		}()
 		go func() {
-			_, _ = io.Copy(session, ptty.Output())
+			_, _ = io.Copy(output, ptty.Output())
 		}()
 		err = process.Wait()
 		var exitErr *exec.ExitError
@@ -495,8 +612,8 @@ func (a *agent) handleSSHSession(session ssh.Session) (retErr error) {
 		return err
 	}
 
-	cmd.Stdout = session
-	cmd.Stderr = session.Stderr()
+	cmd.Stdout = tracked.writer(session)
+	cmd.Stderr = tracked.writer(session.Stderr())
 	// This blocks forever until stdin is received if we don't
 	// use StdinPipe. It's unknown what causes this.
 	stdinPipe, err := cmd.StdinPipe()
@@ -504,7 +621,7 @@ func (a *agent) handleSSHSession(session ssh.Session) (retErr error) {
 		return xerrors.Errorf("create stdin pipe: %w", err)
 	}
 	go func() {
-		_, _ = io.Copy(stdinPipe, session)
+		_, _ = io.Copy(stdinPipe, tracked.reader(session))
 		_ = stdinPipe.Close()
 	}()
 	err = cmd.Start()
@@ -518,7 +635,7 @@ func (a *agent) handleReconnectingPTY(ctx context.Context, rawID string, conn ne
 	defer conn.Close()
 
 	// The ID format is referenced in conn.go.
-	// <uuid>:<height>:<width>
+	// <uuid>:<height>:<width>:<command>
 	idParts := strings.SplitN(rawID, ":", 4)
 	if len(idParts) != 4 {
 		a.logger.Warn(ctx, "client sent invalid id format", slog.F("raw-id", rawID))
@@ -542,7 +659,6 @@ func (a *agent) handleReconnectingPTY(ctx context.Context, rawID string, conn ne
 		a.logger.Warn(ctx, "client sent invalid width", slog.F("id", id), slog.F("width", idParts[2]))
 		return
 	}
-
 	var rpty *reconnectingPTY
 	rawRPTY, ok := a.reconnectingPTYs.Load(id)
 	if ok {
@@ -558,14 +674,22 @@ func (a *agent) handleReconnectingPTY(ctx context.Context, rawID string, conn ne
 			return
 		}
 		cmd.Env = append(cmd.Env, "TERM=xterm-256color")
+		persistent := false
+		if a.persistPTYs {
+			err = persistentPTYCommand(cmd, &a.tmuxConfig, id)
+			if err != nil {
+				a.logger.Warn(ctx, "persist reconnecting pty", slog.F("id", id), slog.Error(err))
+			} else {
+				persistent = true
+			}
+		}
 
 		ptty, process, err := pty.Start(cmd)
 		if err != nil {
 			a.logger.Warn(ctx, "start reconnecting pty command", slog.F("id", id))
 		}
 
-		// Default to buffer 64KiB.
-		circularBuffer, err := circbuf.NewBuffer(64 << 10)
+		circularBuffer, err := circbuf.NewBuffer(int64(a.reconnectingPTYBuffer))
 		if err != nil {
 			a.logger.Warn(ctx, "create circular buffer", slog.Error(err))
 			return
@@ -575,12 +699,28 @@ func (a *agent) handleReconnectingPTY(ctx context.Context, rawID string, conn ne
 		a.connCloseWait.Add(1)
 		a.closeMutex.Unlock()
 		ctx, cancelFunc := context.WithCancel(ctx)
+		end := cancelFunc
+		if persistent {
+			// Timeouts and terminations end the tmux session. Restarts
+			// of the agent only cancel the context, which leaves it
+			// running.
+			end = func() {
+				err := endPersistentPTY(context.Background(), id)
+				if err != nil {
+					a.logger.Warn(ctx, "end persistent reconnecting pty", slog.F("id", id), slog.Error(err))
+				}
+				cancelFunc()
+			}
+		}
 		rpty = &reconnectingPTY{
 			activeConns: make(map[string]net.Conn),
 			ptty:        ptty,
 			// Timeouts created with an after func can be reset!
-			timeout:        time.AfterFunc(a.reconnectingPTYTimeout, cancelFunc),
+			timeout:        time.AfterFunc(a.reconnectingPTYTimeout, end),
+			end:            end,
 			circularBuffer: circularBuffer,
+			command:        idParts[3],
+			recorder:       a.startRecording(width, height, "xterm-256color"),
 		}
 		a.reconnectingPTYs.Store(id, rpty)
 		go func() {
@@ -614,6 +754,9 @@ func (a *agent) handleReconnectingPTY(ctx context.Context, rawID string, conn ne
 					a.logger.Error(ctx, "reconnecting pty write buffer", slog.Error(err), slog.F("id", id))
 					break
 				}
+				if rpty.recorder != nil {
+					_, _ = rpty.recorder.Write(part)
+				}
 				rpty.activeConnsMutex.Lock()
 				for _, conn := range rpty.activeConns {
 					_, _ = conn.Write(part)
@@ -626,6 +769,9 @@ func (a *agent) handleReconnectingPTY(ctx context.Context, rawID string, conn ne
 			_ = process.Kill()
 			rpty.Close()
 			a.reconnectingPTYs.Delete(id)
+			if rpty.recorder != nil {
+				a.uploadRecording(SessionRecordingTypeReconnectingPTY, rpty.RecordingToken(), rpty.recorder)
+			}
 			a.connCloseWait.Done()
 		}()
 	}
@@ -635,19 +781,88 @@ func (a *agent) handleReconnectingPTY(ctx context.Context, rawID string, conn ne
 		// We can continue after this, it's not fatal!
 		a.logger.Error(ctx, "resize reconnecting pty", slog.F("id", id), slog.Error(err))
 	}
+	a.attachReconnectingPTY(ctx, id, rpty, conn, ptyAccessOwner)
+}
+
+// handleSharedReconnectingPTY attaches a connection to an existing
+// reconnecting PTY that was shared with another user. Shared connections
+// never start a PTY or change its size, and read-only connections can't
+// write to it.
+func (a *agent) handleSharedReconnectingPTY(ctx context.Context, rawID string, conn net.Conn) {
+	defer conn.Close()
+
+	// The ID format is referenced in conn.go.
+	// <uuid>:<mode>
+	idParts := strings.SplitN(rawID, ":", 2)
+	if len(idParts) != 2 {
+		a.logger.Warn(ctx, "client sent invalid shared pty id format", slog.F("raw-id", rawID))
+		return
+	}
+	id := idParts[0]
+	var access ptyAccess
+	switch idParts[1] {
+	case sharedPTYReadOnly:
+		access = ptyAccessReadOnly
+	case sharedPTYReadWrite:
+		access = ptyAccessReadWrite
+	default:
+		a.logger.Warn(ctx, "client sent invalid shared pty mode", slog.F("id", id), slog.F("mode", idParts[1]))
+		return
+	}
+
+	rawRPTY, ok := a.reconnectingPTYs.Load(id)
+	if !ok {
+		a.logger.Debug(ctx, "shared reconnecting pty not found", slog.F("id", id))
+		return
+	}
+	rpty, ok := rawRPTY.(*reconnectingPTY)
+	if !ok {
+		a.logger.Warn(ctx, "found invalid type in reconnecting pty map", slog.F("id", id))
+		return
+	}
+	a.attachReconnectingPTY(ctx, id, rpty, conn, access)
+}
+
+// ptyAccess is the access a connection has to a reconnecting PTY.
+type ptyAccess int
+
+const (
+	// ptyAccessOwner connections can write to and resize the PTY.
+	ptyAccessOwner ptyAccess = iota
+	// ptyAccessReadWrite connections can write to the PTY.
+	ptyAccessReadWrite
+	// ptyAccessReadOnly connections can only watch the PTY.
+	ptyAccessReadOnly
+)
+
+func (a *agent) attachReconnectingPTY(ctx context.Context, id string, rpty *reconnectingPTY, conn net.Conn, access ptyAccess) {
+	terminate := func() {
+		_ = conn.Close()
+	}
+	if access == ptyAccessOwner {
+		// Terminating the session of an owner ends the PTY, so
+		// it can't be reconnected to.
+		terminate = func() {
+			rpty.end()
+			rpty.Close()
+		}
+	}
+	tracked := a.sessions.start(SessionTypeReconnectingPTY, "", rpty.command, terminate)
+	defer tracked.end()
+	conn = tracked.conn(conn)
+
 	// Write any previously stored data for the TTY.
 	rpty.circularBufferMutex.RLock()
-	_, err = conn.Write(rpty.circularBuffer.Bytes())
+	_, err := conn.Write(rpty.circularBuffer.Bytes())
 	rpty.circularBufferMutex.RUnlock()
 	if err != nil {
 		a.logger.Warn(ctx, "write reconnecting pty buffer", slog.F("id", id), slog.Error(err))
 		return
 	}
 	connectionID := uuid.NewString()
-	// Multiple connections to the same TTY are permitted.
-	// This could easily be used for terminal sharing, but
-	// we do it because it's a nice user experience to
-	// copy/paste a terminal URL and have it _just work_.
+	// Multiple connections to the same TTY are permitted. It's
+	// a nice user experience to copy/paste a terminal URL and
+	// have it _just work_, and it's used for terminal sharing.
 	rpty.activeConnsMutex.Lock()
 	rpty.activeConns[connectionID] = conn
 	rpty.activeConnsMutex.Unlock()
@@ -689,13 +904,25 @@ func (a *agent) handleReconnectingPTY(ctx context.Context, rawID string, conn ne
 			a.logger.Warn(ctx, "reconnecting pty buffer read error", slog.F("id", id), slog.Error(err))
 			return
 		}
+		if req.RecordingToken != nil {
+			if access == ptyAccessOwner {
+				rpty.SetRecordingToken(*req.RecordingToken)
+			}
+			continue
+		}
+		if access == ptyAccessReadOnly {
+			// Keep reading, so the client can't block the
+			// connection, but drop the input.
+			continue
+		}
 		_, err = rpty.ptty.Input().Write([]byte(req.Data))
 		if err != nil {
 			a.logger.Warn(ctx, "write to reconnecting pty", slog.F("id", id), slog.Error(err))
 			return
 		}
-		// Check if a resize needs to happen!
-		if req.Height == 0 || req.Width == 0 {
+		// Check if a resize needs to happen! Only the owner
+		// controls the size of a shared PTY.
+		if access != ptyAccessOwner || req.Height == 0 || req.Width == 0 {
 			continue
 		}
 		err = rpty.ptty.Resize(req.Height, req.Width)
@@ -703,7 +930,72 @@ func (a *agent) handleReconnectingPTY(ctx context.Context, rawID string, conn ne
 			// We can continue after this, it's not fatal!
 			a.logger.Error(ctx, "resize reconnecting pty", slog.F("id", id), slog.Error(err))
 		}
+		if rpty.recorder != nil {
+			rpty.recorder.Resize(int(req.Width), int(req.Height))
+		}
+	}
+}
+
+// startRecording returns a recorder for a new terminal session, or nil if the
+// template of the workspace doesn't record sessions.
+func (a *agent) startRecording(width, height int, term string) *asciicast.Recorder {
+	if a.uploadSessionRecording == nil {
+		return nil
+	}
+	metadata, valid := a.metadata.Load().(Metadata)
+	if !valid || !metadata.RecordSessions {
+		return nil
+	}
+	return asciicast.NewRecorder(width, height, map[string]string{"TERM": term})
+}
+
+// uploadRecording uploads a finished recording in the background. Closing the
+// agent waits for pending uploads.
+func (a *agent) uploadRecording(recordingType SessionRecordingType, token uuid.UUID, recorder *asciicast.Recorder) {
+	if recorder.Truncated() {
+		a.logger.Warn(context.Background(), "session recording truncated",
+			slog.F("type", recordingType),
+			slog.F("max_size", asciicast.MaxSize),
+		)
+	}
+	recording := SessionRecording{
+		Type:      recordingType,
+		Token:     token,
+		StartedAt: recorder.StartedAt(),
+		EndedAt:   time.Now(),
+		Data:      recorder.Bytes(),
+	}
+	a.closeMutex.Lock()
+	a.connCloseWait.Add(1)
+	a.closeMutex.Unlock()
+	go func() {
+		defer a.connCloseWait.Done()
+		ctx, cancelFunc := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancelFunc()
+		err := a.uploadSessionRecording(ctx, recording)
+		if err != nil {
+			a.logger.Warn(ctx, "upload session recording", slog.F("type", recordingType), slog.Error(err))
+		}
+	}()
+}
+
+// sessionRecordingToken removes the session recording token from the
+// environment of an SSH session, so it doesn't leak into the command.
+func sessionRecordingToken(env []string) ([]string, uuid.UUID) {
+	var token uuid.UUID
+	filtered := make([]string, 0, len(env))
+	for _, kv := range env {
+		value := strings.TrimPrefix(kv, SessionRecordingTokenEnv+"=")
+		if value == kv {
+			filtered = append(filtered, kv)
+			continue
+		}
+		parsed, err := uuid.Parse(value)
+		if err == nil {
+			token = parsed
+		}
 	}
+	return filtered, token
 }
 
 // dialResponse is written to datachannels with protocol "dial" by the agent as
@@ -767,7 +1059,12 @@ func (a *agent) handleDial(ctx context.Context, label string, conn net.Conn) {
 		return
 	}
 
-	Bicopy(ctx, conn, nconn)
+	tracked := a.sessions.start(SessionTypeDial, "", label, func() {
+		_ = conn.Close()
+		_ = nconn.Close()
+	})
+	defer tracked.end()
+	Bicopy(ctx, tracked.conn(conn), nconn)
 }
 
 // isClosed returns whether the API is closed or not.
@@ -790,6 +1087,7 @@ func (a *agent) Close() error {
 	a.closeCancel()
 	_ = a.sshServer.Close()
 	a.connCloseWait.Wait()
+	a.tmuxConfig.remove()
 	return nil
 }
 
@@ -800,7 +1098,34 @@ type reconnectingPTY struct {
 	circularBuffer      *circbuf.Buffer
 	circularBufferMutex sync.RWMutex
 	timeout             *time.Timer
-	ptty                pty.PTY
+	// end stops the process of the PTY, including persisted ones.
+	end     func()
+	ptty    pty.PTY
+	command string
+	// recorder is nil unless the session is recorded.
+	recorder *asciicast.Recorder
+
+	recordingTokenMutex sync.Mutex
+	recordingToken      uuid.UUID
+}
+
+// SetRecordingToken attributes the recording to the user of the token.
+// Recordings are attributed to the first connection that sends one, which is
+// the connection that starts the PTY.
+func (r *reconnectingPTY) SetRecordingToken(token uuid.UUID) {
+	r.recordingTokenMutex.Lock()
+	defer r.recordingTokenMutex.Unlock()
+	if r.recordingToken == uuid.Nil {
+		r.recordingToken = token
+	}
+}
+
+// RecordingToken returns the token the recording is attributed to, or
+// uuid.Nil if no connection sent one.
+func (r *reconnectingPTY) RecordingToken() uuid.UUID {
+	r.recordingTokenMutex.Lock()
+	defer r.recordingTokenMutex.Unlock()
+	return r.recordingToken
 }
 
 // Close ends all connections to the reconnecting
@@ -2,11 +2,14 @@ package agent_test
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -33,6 +36,7 @@ import (
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/slogtest"
 	"github.com/coder/coder/agent"
+	"github.com/coder/coder/agent/asciicast"
 	"github.com/coder/coder/peer"
 	"github.com/coder/coder/peerbroker"
 	"github.com/coder/coder/peerbroker/proto"
@@ -192,6 +196,60 @@ func TestAgent(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("X11", func(t *testing.T) {
+		t.Parallel()
+		if runtime.GOOS == "windows" {
+			t.Skip("The display is printed with a POSIX shell.")
+		}
+		sshClient, err := setupAgent(t, agent.Metadata{}, 0).SSHClient()
+		require.NoError(t, err)
+		channels := sshClient.HandleChannelOpen("x11")
+		session, err := sshClient.NewSession()
+		require.NoError(t, err)
+		defer session.Close()
+		ok, err := session.SendRequest("x11-req", true, ssh.Marshal(struct {
+			SingleConnection bool
+			AuthProtocol     string
+			AuthCookie       string
+			ScreenNumber     uint32
+		}{
+			AuthProtocol: "MIT-MAGIC-COOKIE-1",
+			AuthCookie:   "00000000000000000000000000000000",
+		}))
+		require.NoError(t, err)
+		require.True(t, ok)
+		stdin, err := session.StdinPipe()
+		require.NoError(t, err)
+		defer stdin.Close()
+		stdout, err := session.StdoutPipe()
+		require.NoError(t, err)
+		err = session.Start("echo $DISPLAY; cat")
+		require.NoError(t, err)
+		display, err := bufio.NewReader(stdout).ReadString('\n')
+		require.NoError(t, err)
+		display = strings.TrimSpace(display)
+		require.True(t, strings.HasPrefix(display, "localhost:"), display)
+
+		number, _, _ := strings.Cut(strings.TrimPrefix(display, "localhost:"), ".")
+		port, err := strconv.Atoi(number)
+		require.NoError(t, err)
+		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", 6000+port))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		// Connections to the display are forwarded to the client.
+		channel, requests, err := (<-channels).Accept()
+		require.NoError(t, err)
+		go ssh.DiscardRequests(requests)
+		defer channel.Close()
+		_, err = conn.Write([]byte("hello"))
+		require.NoError(t, err)
+		buf := make([]byte, 5)
+		_, err = io.ReadFull(channel, buf)
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(buf))
+	})
+
 	t.Run("EnvironmentVariables", func(t *testing.T) {
 		t.Parallel()
 		key := "EXAMPLE"
@@ -316,7 +374,7 @@ func TestAgent(t *testing.T) {
 
 		conn := setupAgent(t, agent.Metadata{}, 0)
 		id := uuid.NewString()
-		netConn, err := conn.ReconnectingPTY(id, 100, 100, "/bin/bash")
+		netConn, err := conn.ReconnectingPTY(id, 100, 100, uuid.Nil, "/bin/bash")
 		require.NoError(t, err)
 		bufRead := bufio.NewReader(netConn)
 
@@ -354,7 +412,7 @@ func TestAgent(t *testing.T) {
 		expectLine(matchEchoOutput)
 
 		_ = netConn.Close()
-		netConn, err = conn.ReconnectingPTY(id, 100, 100, "/bin/bash")
+		netConn, err = conn.ReconnectingPTY(id, 100, 100, uuid.Nil, "/bin/bash")
 		require.NoError(t, err)
 		bufRead = bufio.NewReader(netConn)
 
@@ -363,6 +421,322 @@ func TestAgent(t *testing.T) {
 		expectLine(matchEchoOutput)
 	})
 
+	t.Run("PersistentReconnectingPTY", func(t *testing.T) {
+		t.Parallel()
+		if _, err := exec.LookPath("tmux"); err != nil {
+			t.Skip("tmux isn't installed.")
+		}
+
+		id := uuid.NewString()
+		t.Cleanup(func() {
+			_ = exec.Command("tmux", "-L", "coder-agent", "kill-session", "-t", "coder-"+id).Run()
+		})
+		expectOutput := func(r io.Reader, output string) {
+			var buf bytes.Buffer
+			for !strings.Contains(buf.String(), output) {
+				b := make([]byte, 1024)
+				n, err := r.Read(b)
+				require.NoError(t, err)
+				buf.Write(b[:n])
+			}
+		}
+
+		conn, closer := setupAgentWithCloser(t, agent.Metadata{}, &agent.Options{
+			PersistReconnectingPTYs: true,
+		})
+		netConn, err := conn.ReconnectingPTY(id, 100, 100, uuid.Nil, "/bin/bash")
+		require.NoError(t, err)
+		time.Sleep(100 * time.Millisecond)
+		data, err := json.Marshal(agent.ReconnectingPTYRequest{
+			Data: "echo persisted-$((40+2))\r",
+		})
+		require.NoError(t, err)
+		_, err = netConn.Write(data)
+		require.NoError(t, err)
+		expectOutput(netConn, "persisted-42")
+		_ = netConn.Close()
+		err = closer.Close()
+		require.NoError(t, err)
+
+		// The shell survives the restart of the agent, and its output is
+		// shown again when a new agent attaches to it.
+		conn = setupAgentWithOptions(t, agent.Metadata{}, &agent.Options{
+			PersistReconnectingPTYs: true,
+		})
+		netConn, err = conn.ReconnectingPTY(id, 100, 100, uuid.Nil, "/bin/bash")
+		require.NoError(t, err)
+		defer netConn.Close()
+		expectOutput(netConn, "persisted-42")
+	})
+
+	t.Run("SharedReconnectingPTY", func(t *testing.T) {
+		t.Parallel()
+		if runtime.GOOS == "windows" {
+			t.Skip("ConPTY appears to be inconsistent on Windows.")
+		}
+
+		conn := setupAgent(t, agent.Metadata{}, 0)
+
+		// Sessions that don't exist can't be shared.
+		missingConn, err := conn.SharedReconnectingPTY(uuid.NewString(), true)
+		require.NoError(t, err)
+		_, err = missingConn.Read(make([]byte, 1))
+		require.Error(t, err)
+
+		id := uuid.NewString()
+		ownerConn, err := conn.ReconnectingPTY(id, 100, 100, uuid.Nil, "/bin/bash")
+		require.NoError(t, err)
+		defer ownerConn.Close()
+		// Brief pause to reduce the likelihood that we send keystrokes while
+		// the shell is simultaneously sending a prompt.
+		time.Sleep(100 * time.Millisecond)
+
+		viewerConn, err := conn.SharedReconnectingPTY(id, true)
+		require.NoError(t, err)
+		defer viewerConn.Close()
+		viewerRead := bufio.NewReader(viewerConn)
+
+		write := func(c net.Conn, data string) {
+			payload, err := json.Marshal(agent.ReconnectingPTYRequest{
+				Data: data,
+			})
+			require.NoError(t, err)
+			_, err = c.Write(payload)
+			require.NoError(t, err)
+		}
+		// Input of read-only viewers is dropped.
+		write(viewerConn, "echo viewer-input\r\n")
+		write(ownerConn, "echo owner-input\r\n")
+
+		for {
+			line, err := viewerRead.ReadString('\n')
+			require.NoError(t, err)
+			require.NotContains(t, line, "viewer-input")
+			if strings.Contains(line, "owner-input") && !strings.Contains(line, "echo") {
+				break
+			}
+		}
+	})
+
+	t.Run("SessionRecording", func(t *testing.T) {
+		t.Parallel()
+		if runtime.GOOS == "windows" {
+			t.Skip("ConPTY appears to be inconsistent on Windows.")
+		}
+
+		recordings := make(chan agent.SessionRecording, 1)
+		conn := setupAgentWithOptions(t, agent.Metadata{
+			RecordSessions: true,
+		}, &agent.Options{
+			UploadSessionRecording: func(ctx context.Context, recording agent.SessionRecording) error {
+				recordings <- recording
+				return nil
+			},
+		})
+		recordingToken := uuid.New()
+		netConn, err := conn.ReconnectingPTY(uuid.NewString(), 24, 80, recordingToken, "echo recorded && sleep 1")
+		require.NoError(t, err)
+		defer netConn.Close()
+
+		// The recording is uploaded once the command exits.
+		var recording agent.SessionRecording
+		select {
+		case recording = <-recordings:
+		case <-time.After(testutil.WaitLong):
+			t.Fatal("timed out waiting for the session recording")
+		}
+		require.Equal(t, agent.SessionRecordingTypeReconnectingPTY, recording.Type)
+		require.Equal(t, recordingToken, recording.Token)
+		require.False(t, recording.EndedAt.Before(recording.StartedAt))
+
+		header, events, err := asciicast.Decode(bytes.NewReader(recording.Data))
+		require.NoError(t, err)
+		require.Equal(t, 80, header.Width)
+		require.Equal(t, 24, header.Height)
+		var output strings.Builder
+		for _, event := range events {
+			if event.Type == asciicast.EventTypeOutput {
+				output.WriteString(event.Data)
+			}
+		}
+		require.Contains(t, output.String(), "recorded")
+	})
+
+	t.Run("SessionRecordingSSH", func(t *testing.T) {
+		t.Parallel()
+		if runtime.GOOS == "windows" {
+			t.Skip("ConPTY appears to be inconsistent on Windows.")
+		}
+
+		recordings := make(chan agent.SessionRecording, 1)
+		conn := setupAgentWithOptions(t, agent.Metadata{
+			RecordSessions: true,
+		}, &agent.Options{
+			UploadSessionRecording: func(ctx context.Context, recording agent.SessionRecording) error {
+				recordings <- recording
+				return nil
+			},
+		})
+		sshClient, err := conn.SSHClient()
+		require.NoError(t, err)
+		defer sshClient.Close()
+		session, err := sshClient.NewSession()
+		require.NoError(t, err)
+		defer session.Close()
+		recordingToken := uuid.New()
+		err = session.Setenv(agent.SessionRecordingTokenEnv, recordingToken.String())
+		require.NoError(t, err)
+		err = session.RequestPty("xterm", 24, 80, ssh.TerminalModes{})
+		require.NoError(t, err)
+
+		// The token isn't passed on to the command.
+		output, err := session.Output("echo \"token=$" + agent.SessionRecordingTokenEnv + "\"")
+		require.NoError(t, err)
+		require.Equal(t, "token=", strings.TrimSpace(string(output)))
+
+		var recording agent.SessionRecording
+		select {
+		case recording = <-recordings:
+		case <-time.After(testutil.WaitLong):
+			t.Fatal("timed out waiting for the session recording")
+		}
+		require.Equal(t, agent.SessionRecordingTypeSSH, recording.Type)
+		require.Equal(t, recordingToken, recording.Token)
+	})
+
+	t.Run("ListeningPorts", func(t *testing.T) {
+		t.Parallel()
+		if runtime.GOOS != "linux" {
+			t.Skip("Listening ports are only scanned on Linux.")
+		}
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		port := uint16(listener.Addr().(*net.TCPAddr).Port)
+
+		ports, err := setupAgent(t, agent.Metadata{}, 0).ListeningPorts(context.Background())
+		require.NoError(t, err)
+		var found *agent.ListeningPort
+		for i, listeningPort := range ports {
+			if listeningPort.Port == port {
+				found = &ports[i]
+			}
+		}
+		require.NotNil(t, found, "port %d wasn't found in %v", port, ports)
+		require.Equal(t, "tcp", found.Network)
+		require.NotEmpty(t, found.ProcessName)
+	})
+
+	t.Run("HealthChecks", func(t *testing.T) {
+		t.Parallel()
+		if runtime.GOOS == "windows" {
+			t.Skip("Health check commands use a POSIX shell.")
+		}
+
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = rw.Write([]byte("docker is stuck"))
+		}))
+		defer srv.Close()
+
+		reports := make(chan []agent.HealthCheckResult, 1)
+		setupAgentWithOptions(t, agent.Metadata{}, &agent.Options{
+			HealthChecks: []agent.HealthCheck{
+				{Name: "ok", Command: "echo fine"},
+				{Name: "disk", Command: "echo disk full && exit 1"},
+				{Name: "docker", URL: srv.URL},
+				// The background process holds the output open, which must
+				// not block the check until it exits.
+				{Name: "background", Command: "sleep 300 & echo started"},
+			},
+			ReportHealth: func(ctx context.Context, results []agent.HealthCheckResult) error {
+				select {
+				case reports <- results:
+				default:
+				}
+				return nil
+			},
+		})
+
+		var results []agent.HealthCheckResult
+		select {
+		case results = <-reports:
+		case <-time.After(testutil.WaitLong):
+			t.Fatal("timed out waiting for health checks")
+		}
+		require.Len(t, results, 4)
+		require.Equal(t, "ok", results[0].Name)
+		require.True(t, results[0].Healthy)
+		require.Equal(t, "fine", results[0].Output)
+		require.False(t, results[1].Healthy)
+		require.Contains(t, results[1].Output, "disk full")
+		require.False(t, results[2].Healthy)
+		require.Contains(t, results[2].Output, "docker is stuck")
+		require.Contains(t, results[2].Output, "503")
+		require.True(t, results[3].Healthy)
+		require.Equal(t, "started", results[3].Output)
+	})
+
+	t.Run("Sessions", func(t *testing.T) {
+		t.Parallel()
+		if runtime.GOOS == "windows" {
+			t.Skip("sleep isn't available on Windows")
+		}
+
+		conn := setupAgent(t, agent.Metadata{}, 0)
+		sshClient, err := conn.SSHClient()
+		require.NoError(t, err)
+		defer sshClient.Close()
+		session, err := sshClient.NewSession()
+		require.NoError(t, err)
+		defer session.Close()
+		err = session.Start("sleep 30")
+		require.NoError(t, err)
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		go func() {
+			c, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			testAccept(t, c)
+		}()
+		dialConn, err := conn.DialContext(context.Background(), "tcp", listener.Addr().String())
+		require.NoError(t, err)
+		defer dialConn.Close()
+		testDial(t, dialConn)
+
+		var sshSession agent.Session
+		require.Eventually(t, func() bool {
+			sessions, err := conn.Sessions(context.Background())
+			if !assert.NoError(t, err) {
+				return false
+			}
+			var dialed bool
+			for _, s := range sessions {
+				switch s.Type {
+				case agent.SessionTypeSSH:
+					sshSession = s
+				case agent.SessionTypeDial:
+					dialed = s.RxBytes == int64(len(dialTestPayload)) && s.TxBytes == int64(len(dialTestPayload))
+				}
+			}
+			return dialed && sshSession.Command == "sleep 30"
+		}, testutil.WaitShort, testutil.IntervalFast)
+
+		err = conn.TerminateSession(context.Background(), sshSession.ID)
+		require.NoError(t, err)
+		// The command is killed when the session is terminated.
+		err = session.Wait()
+		require.Error(t, err)
+
+		err = conn.TerminateSession(context.Background(), uuid.New())
+		require.ErrorIs(t, err, agent.ErrSessionNotFound)
+	})
+
 	t.Run("Dial", func(t *testing.T) {
 		t.Parallel()
 
@@ -506,14 +880,25 @@ func setupSSHSession(t *testing.T, options agent.Metadata) *ssh.Session {
 }
 
 func setupAgent(t *testing.T, metadata agent.Metadata, ptyTimeout time.Duration) *agent.Conn {
+	return setupAgentWithOptions(t, metadata, &agent.Options{
+		ReconnectingPTYTimeout: ptyTimeout,
+	})
+}
+
+func setupAgentWithOptions(t *testing.T, metadata agent.Metadata, options *agent.Options) *agent.Conn {
+	conn, _ := setupAgentWithCloser(t, metadata, options)
+	return conn
+}
+
+// setupAgentWithCloser is like setupAgentWithOptions, but also returns the
+// agent so tests can stop it.
+func setupAgentWithCloser(t *testing.T, metadata agent.Metadata, options *agent.Options) (*agent.Conn, io.Closer) {
 	client, server := provisionersdk.TransportPipe()
+	options.Logger = slogtest.Make(t, nil).Leveled(slog.LevelDebug)
 	closer := agent.New(func(ctx context.Context, logger slog.Logger) (agent.Metadata, *peerbroker.Listener, error) {
 		listener, err := peerbroker.Listen(server, nil)
 		return metadata, listener, err
-	}, &agent.Options{
-		Logger:                 slogtest.Make(t, nil).Leveled(slog.LevelDebug),
-		ReconnectingPTYTimeout: ptyTimeout,
-	})
+	}, options)
 	t.Cleanup(func() {
 		_ = client.Close()
 		_ = server.Close()
@@ -533,7 +918,7 @@ func setupAgent(t *testing.T, metadata agent.Metadata, ptyTimeout time.Duration)
 	return &agent.Conn{
 		Negotiator: api,
 		Conn:       conn,
-	}
+	}, closer
 }
 
 var dialTestPayload = []byte("dean-was-here123")
@@ -566,3 +951,31 @@ func assertWritePayload(t *testing.T, w io.Writer, payload []byte) {
 	assert.NoError(t, err, "write payload")
 	assert.Equal(t, len(payload), n, "payload length does not match")
 }
+
+func TestParseHealthCheck(t *testing.T) {
+	t.Parallel()
+	for _, testCase := range []struct {
+		Raw      string
+		Expected agent.HealthCheck
+		Error    bool
+	}{
+		{"disk=test $(df --output=pcent / | tail -1 | tr -dc 0-9) -lt 90", agent.HealthCheck{Name: "disk", Command: "test $(df --output=pcent / | tail -1 | tr -dc 0-9) -lt 90"}, false},
+		{"docker=docker info", agent.HealthCheck{Name: "docker", Command: "docker info"}, false},
+		{"web = http://localhost:8080/healthz", agent.HealthCheck{Name: "web", URL: "http://localhost:8080/healthz"}, false},
+		{"docker info", agent.HealthCheck{}, true},
+		{"=docker info", agent.HealthCheck{}, true},
+		{"docker=", agent.HealthCheck{}, true},
+	} {
+		testCase := testCase
+		t.Run(testCase.Raw, func(t *testing.T) {
+			t.Parallel()
+			check, err := agent.ParseHealthCheck(testCase.Raw)
+			if testCase.Error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, testCase.Expected, check)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+// Package asciicast records terminal sessions in the asciicast v2 format.
+// See: https://github.com/asciinema/asciinema/blob/develop/doc/asciicast-v2.md
+package asciicast
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+// MaxSize is the maximum size of a recording in bytes. Output that exceeds
+// it is dropped, so a runaway process can't exhaust the agent's memory.
+// Truncated recordings end with a TruncatedMarker event.
+const MaxSize = 32 << 20
+
+// TruncatedMarker is the label of the marker event that ends a recording
+// that exceeded MaxSize.
+const TruncatedMarker = "recording truncated"
+
+// truncatedMarkerSize is the space reserved for the truncated marker event.
+const truncatedMarkerSize = 64
+
+// EventType is the type of an event in a recording.
+type EventType string
+
+const (
+	// EventTypeOutput is data written to the terminal.
+	EventTypeOutput EventType = "o"
+	// EventTypeResize is a change of the terminal size. The data is formatted
+	// as "<width>x<height>".
+	EventTypeResize EventType = "r"
+	// EventTypeMarker marks a point in the recording. The data is a label.
+	EventTypeMarker EventType = "m"
+)
+
+// Header is the first line of a recording.
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Event is a single line after the header of a recording.
+type Event struct {
+	// Time is the number of seconds since the start of the recording.
+	Time float64
+	Type EventType
+	Data string
+}
+
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{e.Time, e.Type, e.Data})
+}
+
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+	if len(raw) != 3 {
+		return xerrors.Errorf("expected 3 elements in event, got %d", len(raw))
+	}
+	err = json.Unmarshal(raw[0], &e.Time)
+	if err != nil {
+		return xerrors.Errorf("unmarshal time: %w", err)
+	}
+	err = json.Unmarshal(raw[1], &e.Type)
+	if err != nil {
+		return xerrors.Errorf("unmarshal type: %w", err)
+	}
+	err = json.Unmarshal(raw[2], &e.Data)
+	if err != nil {
+		return xerrors.Errorf("unmarshal data: %w", err)
+	}
+	return nil
+}
+
+// Recorder records the output and size changes of a terminal. It's safe for
+// concurrent use.
+type Recorder struct {
+	mutex     sync.Mutex
+	startedAt time.Time
+	buffer    bytes.Buffer
+	// pending holds the start of a multi-byte character that was split
+	// across writes. Events must contain valid UTF-8.
+	pending []byte
+	full    bool
+}
+
+// NewRecorder starts a recording of a terminal with the given size.
+func NewRecorder(width, height int, env map[string]string) *Recorder {
+	r := &Recorder{
+		startedAt: time.Now(),
+	}
+	header, _ := json.Marshal(Header{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: r.startedAt.Unix(),
+		Env:       env,
+	})
+	_, _ = r.buffer.Write(header)
+	_ = r.buffer.WriteByte('\n')
+	return r
+}
+
+// StartedAt returns when the recording was started.
+func (r *Recorder) StartedAt() time.Time {
+	return r.startedAt
+}
+
+// Write records terminal output. It never fails, so it can be used with
+// io.MultiWriter.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	data := append(r.pending, p...)
+	end := len(data)
+	// Hold back an incomplete character at the end of the output. It's
+	// completed by the next write.
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		if !utf8.RuneStart(data[len(data)-i]) {
+			continue
+		}
+		if !utf8.FullRune(data[len(data)-i:]) {
+			end = len(data) - i
+		}
+		break
+	}
+	r.pending = append([]byte(nil), data[end:]...)
+	if end > 0 {
+		r.writeEvent(EventTypeOutput, string(data[:end]))
+	}
+	return len(p), nil
+}
+
+// Resize records a change of the terminal size.
+func (r *Recorder) Resize(width, height int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.writeEvent(EventTypeResize, fmt.Sprintf("%dx%d", width, height))
+}
+
+// Truncated returns whether output was dropped because the recording
+// exceeded MaxSize.
+func (r *Recorder) Truncated() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.full
+}
+
+// Bytes returns the recording.
+func (r *Recorder) Bytes() []byte {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]byte(nil), r.buffer.Bytes()...)
+}
+
+func (r *Recorder) writeEvent(eventType EventType, data string) {
+	if r.full {
+		return
+	}
+	line, err := json.Marshal(Event{
+		Time: time.Since(r.startedAt).Seconds(),
+		Type: eventType,
+		Data: data,
+	})
+	if err != nil {
+		return
+	}
+	if r.buffer.Len()+len(line)+1 > MaxSize-truncatedMarkerSize {
+		r.full = true
+		line, _ = json.Marshal(Event{
+			Time: time.Since(r.startedAt).Seconds(),
+			Type: EventTypeMarker,
+			Data: TruncatedMarker,
+		})
+	}
+	_, _ = r.buffer.Write(line)
+	_ = r.buffer.WriteByte('\n')
+}
+
+// Decode reads a recording.
+func Decode(reader io.Reader) (Header, []Event, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64<<10), MaxSize)
+	if !scanner.Scan() {
+		if scanner.Err() != nil {
+			return Header{}, nil, xerrors.Errorf("read header: %w", scanner.Err())
+		}
+		return Header{}, nil, xerrors.New("recording is empty")
+	}
+	var header Header
+	err := json.Unmarshal(scanner.Bytes(), &header)
+	if err != nil {
+		return Header{}, nil, xerrors.Errorf("unmarshal header: %w", err)
+	}
+	if header.Version != 2 {
+		return Header{}, nil, xerrors.Errorf("unsupported asciicast version %d", header.Version)
+	}
+
+	events := make([]Event, 0)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var event Event
+		err = json.Unmarshal(scanner.Bytes(), &event)
+		if err != nil {
+			return Header{}, nil, xerrors.Errorf("unmarshal event %d: %w", len(events), err)
+		}
+		events = append(events, event)
+	}
+	if scanner.Err() != nil {
+		return Header{}, nil, xerrors.Errorf("read events: %w", scanner.Err())
+	}
+	return header, events, nil
+}
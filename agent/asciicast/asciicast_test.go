@@ -0,0 +1,98 @@
+package asciicast_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/agent/asciicast"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		t.Parallel()
+		recorder := asciicast.NewRecorder(80, 24, map[string]string{"TERM": "xterm-256color"})
+		_, err := recorder.Write([]byte("hello "))
+		require.NoError(t, err)
+		recorder.Resize(120, 40)
+		_, err = recorder.Write([]byte("world\r\n"))
+		require.NoError(t, err)
+
+		header, events, err := asciicast.Decode(bytes.NewReader(recorder.Bytes()))
+		require.NoError(t, err)
+		require.Equal(t, 2, header.Version)
+		require.Equal(t, 80, header.Width)
+		require.Equal(t, 24, header.Height)
+		require.Equal(t, recorder.StartedAt().Unix(), header.Timestamp)
+		require.Equal(t, "xterm-256color", header.Env["TERM"])
+		require.Len(t, events, 3)
+		require.Equal(t, asciicast.EventTypeOutput, events[0].Type)
+		require.Equal(t, "hello ", events[0].Data)
+		require.Equal(t, asciicast.EventTypeResize, events[1].Type)
+		require.Equal(t, "120x40", events[1].Data)
+		require.Equal(t, "world\r\n", events[2].Data)
+		require.LessOrEqual(t, events[0].Time, events[2].Time)
+	})
+
+	t.Run("SplitCharacter", func(t *testing.T) {
+		t.Parallel()
+		recorder := asciicast.NewRecorder(80, 24, nil)
+		data := []byte("a€b")
+		// Split the euro sign across two writes.
+		_, err := recorder.Write(data[:2])
+		require.NoError(t, err)
+		_, err = recorder.Write(data[2:])
+		require.NoError(t, err)
+
+		_, events, err := asciicast.Decode(bytes.NewReader(recorder.Bytes()))
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, "a", events[0].Data)
+		require.Equal(t, "€b", events[1].Data)
+	})
+
+	t.Run("MaxSize", func(t *testing.T) {
+		t.Parallel()
+		recorder := asciicast.NewRecorder(80, 24, nil)
+		chunk := []byte(strings.Repeat("x", 1<<20))
+		for i := 0; i < asciicast.MaxSize>>20+1; i++ {
+			_, err := recorder.Write(chunk)
+			require.NoError(t, err)
+		}
+		require.LessOrEqual(t, len(recorder.Bytes()), asciicast.MaxSize)
+		require.True(t, recorder.Truncated())
+
+		// Truncated recordings end with a marker.
+		_, events, err := asciicast.Decode(bytes.NewReader(recorder.Bytes()))
+		require.NoError(t, err)
+		last := events[len(events)-1]
+		require.Equal(t, asciicast.EventTypeMarker, last.Type)
+		require.Equal(t, asciicast.TruncatedMarker, last.Data)
+	})
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+		_, _, err := asciicast.Decode(strings.NewReader(""))
+		require.Error(t, err)
+	})
+
+	t.Run("UnsupportedVersion", func(t *testing.T) {
+		t.Parallel()
+		_, _, err := asciicast.Decode(strings.NewReader(`{"version":1,"width":80,"height":24}`))
+		require.Error(t, err)
+	})
+
+	t.Run("InvalidEvent", func(t *testing.T) {
+		t.Parallel()
+		_, _, err := asciicast.Decode(strings.NewReader("{\"version\":2,\"width\":80,\"height\":24}\n[1.0, \"o\"]\n"))
+		require.Error(t, err)
+	})
+}
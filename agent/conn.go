@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/google/uuid"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/xerrors"
 
@@ -21,6 +22,10 @@ type ReconnectingPTYRequest struct {
 	Data   string `json:"data"`
 	Height uint16 `json:"height"`
 	Width  uint16 `json:"width"`
+	// RecordingToken attributes the session recording of the PTY to the
+	// user that connected. It's sent in a message of its own, which older
+	// agents handle as empty input.
+	RecordingToken *uuid.UUID `json:"recording_token,omitempty"`
 }
 
 // Conn wraps a peer connection with helper functions to
@@ -35,14 +40,55 @@ type Conn struct {
 // ReconnectingPTY returns a connection serving a TTY that can
 // be reconnected to via ID.
 //
-// The command is optional and defaults to start a shell.
-func (c *Conn) ReconnectingPTY(id string, height, width uint16, command string) (net.Conn, error) {
+// The command is optional and defaults to start a shell. The session
+// recording token is sent back to coderd with the recording of the TTY, and
+// may be uuid.Nil.
+func (c *Conn) ReconnectingPTY(id string, height, width uint16, recordingToken uuid.UUID, command string) (net.Conn, error) {
 	channel, err := c.CreateChannel(context.Background(), fmt.Sprintf("%s:%d:%d:%s", id, height, width, command), &peer.ChannelOptions{
 		Protocol: ProtocolReconnectingPTY,
 	})
 	if err != nil {
 		return nil, xerrors.Errorf("pty: %w", err)
 	}
+	netConn := channel.NetConn()
+	if recordingToken != uuid.Nil {
+		// The token isn't part of the channel label, since agents that
+		// predate recordings would run it as part of the command.
+		data, err := json.Marshal(ReconnectingPTYRequest{
+			RecordingToken: &recordingToken,
+		})
+		if err != nil {
+			_ = netConn.Close()
+			return nil, xerrors.Errorf("marshal recording token: %w", err)
+		}
+		_, err = netConn.Write(data)
+		if err != nil {
+			_ = netConn.Close()
+			return nil, xerrors.Errorf("write recording token: %w", err)
+		}
+	}
+	return netConn, nil
+}
+
+const (
+	sharedPTYReadOnly  = "read-only"
+	sharedPTYReadWrite = "read-write"
+)
+
+// SharedReconnectingPTY attaches to a running reconnecting PTY of another
+// connection. Read-only connections can't write to the PTY, and shared
+// connections never resize it.
+func (c *Conn) SharedReconnectingPTY(id string, readOnly bool) (net.Conn, error) {
+	mode := sharedPTYReadWrite
+	if readOnly {
+		mode = sharedPTYReadOnly
+	}
+	channel, err := c.CreateChannel(context.Background(), fmt.Sprintf("%s:%s", id, mode), &peer.ChannelOptions{
+		Protocol: ProtocolSharedReconnectingPTY,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("shared pty: %w", err)
+	}
 	return channel.NetConn(), nil
 }
 
@@ -112,6 +158,67 @@ func (c *Conn) DialContext(ctx context.Context, network string, addr string) (ne
 	return channel.NetConn(), nil
 }
 
+// Sessions returns the active sessions of the agent.
+func (c *Conn) Sessions(ctx context.Context) ([]Session, error) {
+	res, err := c.sessionsRequest(ctx, "sessions", ProtocolSessions)
+	if err != nil {
+		return nil, err
+	}
+	return res.Sessions, nil
+}
+
+// TerminateSession ends an active session of the agent. ErrSessionNotFound is
+// returned if the session doesn't exist.
+func (c *Conn) TerminateSession(ctx context.Context, id uuid.UUID) error {
+	_, err := c.sessionsRequest(ctx, id.String(), ProtocolTerminateSession)
+	return err
+}
+
+func (c *Conn) sessionsRequest(ctx context.Context, label, protocol string) (sessionsResponse, error) {
+	channel, err := c.CreateChannel(ctx, label, &peer.ChannelOptions{
+		Protocol: protocol,
+	})
+	if err != nil {
+		return sessionsResponse{}, xerrors.Errorf("create datachannel: %w", err)
+	}
+	defer channel.Close()
+
+	var res sessionsResponse
+	err = json.NewDecoder(channel).Decode(&res)
+	if err != nil {
+		return sessionsResponse{}, xerrors.Errorf("decode agent %s response: %w", protocol, err)
+	}
+	if res.Error == errSessionNotFound {
+		return sessionsResponse{}, ErrSessionNotFound
+	}
+	if res.Error != "" {
+		return sessionsResponse{}, xerrors.Errorf("remote %s error: %v", protocol, res.Error)
+	}
+	return res, nil
+}
+
+// ListeningPorts returns the TCP ports that processes in the workspace listen
+// on.
+func (c *Conn) ListeningPorts(ctx context.Context) ([]ListeningPort, error) {
+	channel, err := c.CreateChannel(ctx, "listening-ports", &peer.ChannelOptions{
+		Protocol: ProtocolListeningPorts,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("create datachannel: %w", err)
+	}
+	defer channel.Close()
+
+	var res listeningPortsResponse
+	err = json.NewDecoder(channel).Decode(&res)
+	if err != nil {
+		return nil, xerrors.Errorf("decode agent listening ports response: %w", err)
+	}
+	if res.Error != "" {
+		return nil, xerrors.Errorf("remote listening ports error: %v", res.Error)
+	}
+	return res.Ports, nil
+}
+
 func (c *Conn) Close() error {
 	_ = c.Negotiator.DRPCConn().Close()
 	return c.Conn.Close()
@@ -0,0 +1,232 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+const (
+	// healthCheckTimeout is how long a single health check may run before it
+	// fails.
+	healthCheckTimeout = 10 * time.Second
+	// healthCheckOutputLimit is the number of bytes of output kept from a
+	// health check. Only the end of the output is kept, because that's where
+	// errors usually are.
+	healthCheckOutputLimit = 1024
+	// healthCheckWaitDelay is how long the output of a health check command
+	// is read after the command exits. Processes it started in the
+	// background can hold the output open forever.
+	healthCheckWaitDelay = time.Second
+)
+
+// HealthCheck is a command or HTTP probe that the agent runs periodically to
+// report whether the workspace is healthy.
+type HealthCheck struct {
+	Name string
+	// Command is run with the shell of the user, and fails if it exits with
+	// a non-zero code.
+	Command string
+	// URL is requested with GET, and fails if the status code is 400 or
+	// higher.
+	URL string
+}
+
+// ParseHealthCheck parses a health check in the form "name=command" or
+// "name=url". Values starting with "http://" or "https://" are HTTP probes.
+func ParseHealthCheck(raw string) (HealthCheck, error) {
+	name, value, ok := strings.Cut(raw, "=")
+	name = strings.TrimSpace(name)
+	value = strings.TrimSpace(value)
+	if !ok || name == "" || value == "" {
+		return HealthCheck{}, xerrors.Errorf("health check %q must be in the form name=command or name=url", raw)
+	}
+	if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+		return HealthCheck{Name: name, URL: value}, nil
+	}
+	return HealthCheck{Name: name, Command: value}, nil
+}
+
+// HealthCheckResult is the outcome of the last run of a health check.
+type HealthCheckResult struct {
+	Name      string    `json:"name"`
+	Healthy   bool      `json:"healthy"`
+	Output    string    `json:"output"`
+	CheckedAt time.Time `json:"checked_at"`
+	// Interval is how often the check runs, so coderd can tell when the
+	// agent stopped reporting.
+	Interval time.Duration `json:"interval"`
+}
+
+// ReportHealth reports the results of all health checks to coderd.
+type ReportHealth func(ctx context.Context, results []HealthCheckResult) error
+
+// runHealthChecks runs all health checks every interval and reports the
+// results until the context is canceled.
+func (a *agent) runHealthChecks(ctx context.Context) {
+	ticker := time.NewTicker(a.healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		results := make([]HealthCheckResult, len(a.healthChecks))
+		var wg sync.WaitGroup
+		for index, check := range a.healthChecks {
+			index, check := index, check
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[index] = a.runHealthCheck(ctx, check)
+			}()
+		}
+		wg.Wait()
+		if ctx.Err() != nil {
+			return
+		}
+		for _, result := range results {
+			if !result.Healthy {
+				a.logger.Warn(ctx, "health check failed", slog.F("name", result.Name), slog.F("output", result.Output))
+			}
+		}
+		err := a.reportHealth(ctx, results)
+		if err != nil && ctx.Err() == nil {
+			a.logger.Warn(ctx, "report health", slog.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *agent) runHealthCheck(ctx context.Context, check HealthCheck) HealthCheckResult {
+	ctx, cancelFunc := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancelFunc()
+
+	output := &tailBuffer{limit: healthCheckOutputLimit}
+	var err error
+	if check.URL != "" {
+		err = probeHealthCheckURL(ctx, check.URL, output)
+	} else {
+		err = a.runHealthCheckCommand(ctx, check.Command, output)
+	}
+	if err != nil {
+		if output.Len() > 0 {
+			_, _ = output.WriteString("\n")
+		}
+		_, _ = output.WriteString(err.Error())
+	}
+	return HealthCheckResult{
+		Name:      check.Name,
+		Healthy:   err == nil,
+		Output:    strings.TrimSpace(output.String()),
+		CheckedAt: time.Now(),
+		Interval:  a.healthCheckInterval,
+	}
+}
+
+func (a *agent) runHealthCheckCommand(ctx context.Context, command string, output io.Writer) error {
+	cmd, err := a.createCommand(ctx, command, nil)
+	if err != nil {
+		return xerrors.Errorf("create command: %w", err)
+	}
+	setHealthCheckProcessGroup(cmd)
+	// The command writes to a pipe instead of output, so waiting for it
+	// doesn't also wait for every process that inherited the pipe.
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return xerrors.Errorf("create pipe: %w", err)
+	}
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	err = cmd.Start()
+	_ = writer.Close()
+	if err != nil {
+		_ = reader.Close()
+		return err
+	}
+	copied := make(chan struct{})
+	go func() {
+		defer close(copied)
+		_, _ = io.Copy(output, reader)
+	}()
+	err = cmd.Wait()
+	select {
+	case <-copied:
+	case <-ctx.Done():
+	case <-time.After(healthCheckWaitDelay):
+	}
+	select {
+	case <-copied:
+	default:
+		// Something the command started is still running, or it timed
+		// out. Closing the pipe stops the copy, and output written after
+		// this is dropped.
+		killHealthCheckProcessGroup(cmd)
+	}
+	_ = reader.Close()
+	if ctx.Err() != nil {
+		return xerrors.Errorf("timed out after %s", healthCheckTimeout)
+	}
+	return err
+}
+
+func probeHealthCheckURL(ctx context.Context, url string, output io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return xerrors.Errorf("create request: %w", err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= http.StatusBadRequest {
+		_, _ = io.Copy(output, res.Body)
+		return xerrors.Errorf("unexpected status code %d", res.StatusCode)
+	}
+	_, _ = io.WriteString(output, res.Status)
+	return nil
+}
+
+// tailBuffer keeps the last limit bytes written to it.
+type tailBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+	limit  int
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	n, _ := t.buffer.Write(p)
+	if overflow := t.buffer.Len() - t.limit; overflow > 0 {
+		t.buffer.Next(overflow)
+	}
+	return n, nil
+}
+
+func (t *tailBuffer) WriteString(s string) (int, error) {
+	return t.Write([]byte(s))
+}
+
+func (t *tailBuffer) Len() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.buffer.Len()
+}
+
+func (t *tailBuffer) String() string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.buffer.String()
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+// +build !windows
+
+package agent
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setHealthCheckProcessGroup starts the command in a new process group, so
+// processes it starts can be killed with it.
+func setHealthCheckProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// killHealthCheckProcessGroup kills the command and every process it started.
+func killHealthCheckProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process == nil {
+		return
+	}
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
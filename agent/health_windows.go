@@ -0,0 +1,17 @@
+//go:build windows
+// +build windows
+
+package agent
+
+import "os/exec"
+
+func setHealthCheckProcessGroup(_ *exec.Cmd) {}
+
+// killHealthCheckProcessGroup kills the command. Processes it started are
+// left running, since Windows doesn't have process groups.
+func killHealthCheckProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process == nil {
+		return
+	}
+	_ = cmd.Process.Kill()
+}
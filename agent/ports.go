@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"cdr.dev/slog"
+)
+
+// listeningPortsCacheDuration limits how often the listening ports are
+// scanned, since clients poll them.
+const listeningPortsCacheDuration = time.Second
+
+// ListeningPort is a TCP port that a process in the workspace listens on.
+type ListeningPort struct {
+	ProcessName string `json:"process_name"`
+	Network     string `json:"network"`
+	Port        uint16 `json:"port"`
+}
+
+// listeningPortsResponse is written to datachannels with protocol
+// "listening-ports" by the agent.
+type listeningPortsResponse struct {
+	Ports []ListeningPort `json:"ports"`
+	Error string          `json:"error,omitempty"`
+}
+
+type listeningPortsHandler struct {
+	mutex     sync.Mutex
+	ports     []ListeningPort
+	scannedAt time.Time
+}
+
+// get returns the listening ports, scanning them again if the last scan is
+// older than listeningPortsCacheDuration.
+func (l *listeningPortsHandler) get() ([]ListeningPort, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if time.Since(l.scannedAt) < listeningPortsCacheDuration {
+		return l.ports, nil
+	}
+	ports, err := scanListeningPorts()
+	if err != nil {
+		return nil, err
+	}
+	l.ports = ports
+	l.scannedAt = time.Now()
+	return ports, nil
+}
+
+func (a *agent) handleListeningPorts(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	var response listeningPortsResponse
+	ports, err := a.listeningPorts.get()
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Ports = ports
+	err = json.NewEncoder(conn).Encode(response)
+	if err != nil {
+		a.logger.Warn(ctx, "write listening ports response", slog.Error(err))
+	}
+}
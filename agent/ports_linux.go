@@ -0,0 +1,105 @@
+//go:build linux
+// +build linux
+
+package agent
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// tcpListenState is the state of listening sockets in /proc/net/tcp.
+const tcpListenState = "0A"
+
+// scanListeningPorts reads the listening TCP sockets from /proc/net/tcp and
+// /proc/net/tcp6, and finds the processes that own them.
+func scanListeningPorts() ([]ListeningPort, error) {
+	// Maps socket inodes to the ports they listen on.
+	inodes := map[string]uint16{}
+	for _, name := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		err := readListeningSockets(name, inodes)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// IPv6 may be disabled.
+				continue
+			}
+			return nil, xerrors.Errorf("read %s: %w", name, err)
+		}
+	}
+
+	processNames := map[uint16]string{}
+	fds, _ := filepath.Glob("/proc/[0-9]*/fd/[0-9]*")
+	for _, fd := range fds {
+		// Links of sockets are formatted as "socket:[<inode>]". Other
+		// users' processes can't be read, so errors are ignored.
+		link, err := os.Readlink(fd)
+		if err != nil || !strings.HasPrefix(link, "socket:[") {
+			continue
+		}
+		port, ok := inodes[strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")]
+		if !ok || processNames[port] != "" {
+			continue
+		}
+		comm, err := os.ReadFile(filepath.Join(filepath.Dir(filepath.Dir(fd)), "comm"))
+		if err != nil {
+			continue
+		}
+		processNames[port] = strings.TrimSpace(string(comm))
+	}
+
+	seen := map[uint16]struct{}{}
+	ports := make([]ListeningPort, 0, len(inodes))
+	for _, port := range inodes {
+		// Sockets listening on IPv4 and IPv6 are reported once.
+		if _, ok := seen[port]; ok {
+			continue
+		}
+		seen[port] = struct{}{}
+		ports = append(ports, ListeningPort{
+			ProcessName: processNames[port],
+			Network:     "tcp",
+			Port:        port,
+		})
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		return ports[i].Port < ports[j].Port
+	})
+	return ports, nil
+}
+
+// readListeningSockets adds the inodes of listening sockets in a
+// /proc/net/tcp formatted file to inodes.
+func readListeningSockets(name string, inodes map[string]uint16) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	// The first line is a header.
+	scanner.Scan()
+	for scanner.Scan() {
+		// Lines are formatted as:
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != tcpListenState {
+			continue
+		}
+		_, hexPort, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		port, err := strconv.ParseUint(hexPort, 16, 16)
+		if err != nil {
+			continue
+		}
+		inodes[fields[9]] = uint16(port)
+	}
+	return scanner.Err()
+}
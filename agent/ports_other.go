@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package agent
+
+import (
+	"runtime"
+
+	"golang.org/x/xerrors"
+)
+
+func scanListeningPorts() ([]ListeningPort, error) {
+	return nil, xerrors.Errorf("listening ports can't be scanned on %s", runtime.GOOS)
+}
@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// tmuxSocketName separates the tmux server of persistent reconnecting
+	// PTYs from the user's own tmux sessions.
+	tmuxSocketName = "coder-agent"
+	// tmuxConfig keeps tmux out of the way of web terminals. Prefix keys are
+	// disabled so shortcuts reach the shell, and the alternate screen is
+	// disabled so output ends up in the scrollback of the web terminal.
+	tmuxConfig = `set -g status off
+set -g prefix None
+set -g prefix2 None
+set -g history-limit 10000
+set -g terminal-overrides 'xterm*:smcup@:rmcup@'
+`
+)
+
+// tmuxConfigFile is the tmux config written for an agent. It's written to a
+// new temporary file, since a fixed path in the shared temporary directory
+// could already be created by another user.
+type tmuxConfigFile struct {
+	once sync.Once
+	path string
+	err  error
+}
+
+// get writes the config the first time it's called, and returns its path.
+func (f *tmuxConfigFile) get() (string, error) {
+	f.once.Do(func() {
+		file, err := os.CreateTemp("", "coder-agent-tmux-*.conf")
+		if err != nil {
+			f.err = xerrors.Errorf("create tmux config: %w", err)
+			return
+		}
+		defer file.Close()
+		_, err = file.WriteString(tmuxConfig)
+		if err != nil {
+			_ = os.Remove(file.Name())
+			f.err = xerrors.Errorf("write tmux config: %w", err)
+			return
+		}
+		f.path = file.Name()
+	})
+	return f.path, f.err
+}
+
+// remove deletes the config if it was written. tmux only reads it when its
+// server starts, so running sessions aren't affected.
+func (f *tmuxConfigFile) remove() {
+	// Wait for a concurrent write to finish.
+	f.once.Do(func() {})
+	if f.path != "" {
+		_ = os.Remove(f.path)
+	}
+}
+
+// persistentPTYCommand wraps the command of a reconnecting PTY in a tmux
+// session named after the PTY. tmux keeps the session running when the agent
+// restarts, and the command attaches to the existing session instead of
+// starting a new one if it's still running.
+func persistentPTYCommand(cmd *exec.Cmd, config *tmuxConfigFile, id string) error {
+	tmux, err := exec.LookPath("tmux")
+	if err != nil {
+		return xerrors.Errorf("tmux must be installed to persist reconnecting ptys: %w", err)
+	}
+	configPath, err := config.get()
+	if err != nil {
+		return err
+	}
+	args := []string{tmux, "-L", tmuxSocketName, "-f", configPath, "new-session", "-A", "-s", persistentPTYSession(id), "--", cmd.Path}
+	cmd.Path = tmux
+	cmd.Args = append(args, cmd.Args[1:]...)
+	return nil
+}
+
+// endPersistentPTY kills the tmux session of a reconnecting PTY. Without it,
+// the session would keep running after the PTY times out or is terminated.
+func endPersistentPTY(ctx context.Context, id string) error {
+	out, err := exec.CommandContext(ctx, "tmux", "-L", tmuxSocketName, "kill-session", "-t", persistentPTYSession(id)).CombinedOutput()
+	if err != nil {
+		return xerrors.Errorf("kill tmux session: %w: %s", err, out)
+	}
+	return nil
+}
+
+func persistentPTYSession(id string) string {
+	return "coder-" + id
+}
@@ -0,0 +1,218 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/atomic"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+type SessionType string
+
+const (
+	SessionTypeSSH             SessionType = "ssh"
+	SessionTypeReconnectingPTY SessionType = "reconnecting_pty"
+	// SessionTypeDial sessions are port forwards, and connections of the
+	// application proxy.
+	SessionTypeDial SessionType = "dial"
+)
+
+// Session is a connection to the agent that is currently active.
+type Session struct {
+	ID   uuid.UUID   `json:"id"`
+	Type SessionType `json:"type"`
+	// RemoteUser is the user name sent by SSH clients. It's empty for
+	// other sessions.
+	RemoteUser string    `json:"remote_user"`
+	StartedAt  time.Time `json:"started_at"`
+	// RxBytes is the number of bytes received from the client.
+	RxBytes int64 `json:"rx_bytes"`
+	// TxBytes is the number of bytes sent to the client.
+	TxBytes int64 `json:"tx_bytes"`
+	// Command is the command of terminal sessions, or the dialed address of
+	// dial sessions. It's empty for shells.
+	Command string `json:"command"`
+}
+
+// sessionRegistry tracks the active sessions of the agent, so they can be
+// listed and terminated remotely.
+type sessionRegistry struct {
+	mutex    sync.Mutex
+	sessions map[uuid.UUID]*trackedSession
+}
+
+type trackedSession struct {
+	Session
+	registry *sessionRegistry
+	rx       atomic.Int64
+	tx       atomic.Int64
+	// terminate ends the session. It must be safe to call more than once.
+	terminate func()
+}
+
+// start registers a new session. The caller must call end when the session
+// ends.
+func (r *sessionRegistry) start(sessionType SessionType, remoteUser, command string, terminate func()) *trackedSession {
+	session := &trackedSession{
+		Session: Session{
+			ID:         uuid.New(),
+			Type:       sessionType,
+			RemoteUser: remoteUser,
+			StartedAt:  time.Now(),
+			Command:    command,
+		},
+		registry:  r,
+		terminate: terminate,
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.sessions == nil {
+		r.sessions = map[uuid.UUID]*trackedSession{}
+	}
+	r.sessions[session.ID] = session
+	return session
+}
+
+// list returns the active sessions, oldest first.
+func (r *sessionRegistry) list() []Session {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	sessions := make([]Session, 0, len(r.sessions))
+	for _, session := range r.sessions {
+		info := session.Session
+		info.RxBytes = session.rx.Load()
+		info.TxBytes = session.tx.Load()
+		sessions = append(sessions, info)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].StartedAt.Before(sessions[j].StartedAt)
+	})
+	return sessions
+}
+
+// terminate ends an active session. It returns false if the session doesn't
+// exist.
+func (r *sessionRegistry) terminate(id uuid.UUID) bool {
+	r.mutex.Lock()
+	session, ok := r.sessions[id]
+	r.mutex.Unlock()
+	if !ok {
+		return false
+	}
+	session.terminate()
+	return true
+}
+
+func (s *trackedSession) end() {
+	s.registry.mutex.Lock()
+	defer s.registry.mutex.Unlock()
+	delete(s.registry.sessions, s.ID)
+}
+
+// reader counts the bytes received from the client.
+func (s *trackedSession) reader(r io.Reader) io.Reader {
+	return &countingReader{Reader: r, count: &s.rx}
+}
+
+// writer counts the bytes sent to the client.
+func (s *trackedSession) writer(w io.Writer) io.Writer {
+	return &countingWriter{Writer: w, count: &s.tx}
+}
+
+// conn counts the bytes moved over a connection to the client.
+func (s *trackedSession) conn(c net.Conn) net.Conn {
+	return &countingConn{Conn: c, session: s}
+}
+
+type countingReader struct {
+	io.Reader
+	count *atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	c.count.Add(int64(n))
+	return n, err
+}
+
+type countingWriter struct {
+	io.Writer
+	count *atomic.Int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.Writer.Write(p)
+	c.count.Add(int64(n))
+	return n, err
+}
+
+type countingConn struct {
+	net.Conn
+	session *trackedSession
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.session.rx.Add(int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.session.tx.Add(int64(n))
+	return n, err
+}
+
+// sessionsResponse is written to datachannels with protocol "sessions" and
+// "terminate-session" by the agent.
+type sessionsResponse struct {
+	Sessions []Session `json:"sessions,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+func (a *agent) handleSessions(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	err := json.NewEncoder(conn).Encode(sessionsResponse{
+		Sessions: a.sessions.list(),
+	})
+	if err != nil {
+		a.logger.Warn(ctx, "write sessions response", slog.Error(err))
+	}
+}
+
+func (a *agent) handleTerminateSession(ctx context.Context, label string, conn net.Conn) {
+	defer conn.Close()
+
+	var response sessionsResponse
+	id, err := uuid.Parse(label)
+	switch {
+	case err != nil:
+		response.Error = xerrors.Errorf("parse session id %q: %w", label, err).Error()
+	case !a.sessions.terminate(id):
+		response.Error = errSessionNotFound
+	default:
+		a.logger.Info(ctx, "terminated session", slog.F("id", id))
+	}
+	err = json.NewEncoder(conn).Encode(response)
+	if err != nil {
+		a.logger.Warn(ctx, "write terminate session response", slog.Error(err))
+	}
+}
+
+// errSessionNotFound is returned by the agent when terminating a session that
+// doesn't exist.
+const errSessionNotFound = "session not found"
+
+// ErrSessionNotFound is returned by Conn.TerminateSession when the session
+// doesn't exist.
+var ErrSessionNotFound = xerrors.New(errSessionNotFound)
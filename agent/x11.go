@@ -0,0 +1,169 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/gliderlabs/ssh"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+const (
+	// x11DisplayOffset matches the default of OpenSSH, which leaves the
+	// lower displays for local X servers.
+	x11DisplayOffset = 10
+	x11MaxDisplays   = 1000
+	x11StartPort     = 6000
+)
+
+// x11Request is the payload of an "x11-req" request.
+// See: https://www.rfc-editor.org/rfc/rfc4254#section-6.3.1
+type x11Request struct {
+	SingleConnection bool
+	AuthProtocol     string
+	AuthCookie       string
+	ScreenNumber     uint32
+}
+
+// x11ChannelData is the payload of an "x11" channel open request.
+// See: https://www.rfc-editor.org/rfc/rfc4254#section-6.3.2
+type x11ChannelData struct {
+	OriginatorAddress string
+	OriginatorPort    uint32
+}
+
+// handleSessionChannel serves session channels like ssh.DefaultSessionHandler
+// but also handles "x11-req" requests, which the ssh package rejects.
+func (a *agent) handleSessionChannel(srv *ssh.Server, conn *gossh.ServerConn, newChan gossh.NewChannel, ctx ssh.Context) {
+	ssh.DefaultSessionHandler(srv, conn, &x11SessionChannel{
+		NewChannel: newChan,
+		agent:      a,
+		conn:       conn,
+		ctx:        ctx,
+	}, ctx)
+}
+
+type x11SessionChannel struct {
+	gossh.NewChannel
+	agent *agent
+	conn  *gossh.ServerConn
+	ctx   context.Context
+}
+
+func (c *x11SessionChannel) Accept() (gossh.Channel, <-chan *gossh.Request, error) {
+	channel, requests, err := c.NewChannel.Accept()
+	if err != nil {
+		return nil, nil, err
+	}
+	filtered := make(chan *gossh.Request)
+	go func() {
+		defer close(filtered)
+		var listener net.Listener
+		defer func() {
+			if listener != nil {
+				_ = listener.Close()
+			}
+		}()
+		for req := range requests {
+			if req.Type != "x11-req" {
+				filtered <- req
+				continue
+			}
+			if listener != nil {
+				_ = req.Reply(false, nil)
+				continue
+			}
+			var (
+				display string
+				err     error
+			)
+			listener, display, err = c.agent.forwardX11(c.ctx, c.conn, req.Payload)
+			if err != nil {
+				c.agent.logger.Warn(c.ctx, "x11 forwarding failed", slog.Error(err))
+				_ = req.Reply(false, nil)
+				continue
+			}
+			_ = req.Reply(true, nil)
+			// Sessions read their environment from "env" requests, so
+			// DISPLAY is passed to the command the same way.
+			filtered <- &gossh.Request{
+				Type: "env",
+				Payload: gossh.Marshal(struct{ Key, Value string }{
+					Key:   "DISPLAY",
+					Value: display,
+				}),
+			}
+		}
+	}()
+	return channel, filtered, nil
+}
+
+// forwardX11 allocates a display for an "x11-req" request, adds its cookie to
+// the user's Xauthority file and proxies connections to the display back to
+// the client. The returned listener must be closed when the session ends.
+func (a *agent) forwardX11(ctx context.Context, conn *gossh.ServerConn, payload []byte) (net.Listener, string, error) {
+	var req x11Request
+	err := gossh.Unmarshal(payload, &req)
+	if err != nil {
+		return nil, "", xerrors.Errorf("parse request: %w", err)
+	}
+
+	var (
+		listener net.Listener
+		number   int
+	)
+	for number = x11DisplayOffset; number < x11DisplayOffset+x11MaxDisplays; number++ {
+		listener, err = net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", x11StartPort+number))
+		if err == nil {
+			break
+		}
+	}
+	if listener == nil {
+		return nil, "", xerrors.Errorf("no displays available: %w", err)
+	}
+
+	// Like OpenSSH, the cookie is added for the local display so X clients
+	// find it for "localhost" displays too.
+	cmd := exec.CommandContext(ctx, "xauth", "-q", "-")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("remove unix:%d.%d\nadd unix:%d.%d %s %s\n",
+		number, req.ScreenNumber, number, req.ScreenNumber, req.AuthProtocol, req.AuthCookie))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		// X servers may accept connections without a cookie, so this
+		// isn't fatal.
+		a.logger.Warn(ctx, "add x11 cookie with xauth", slog.Error(err), slog.F("output", string(out)))
+	}
+
+	go func() {
+		for {
+			local, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if req.SingleConnection {
+				_ = listener.Close()
+			}
+			host, port, _ := net.SplitHostPort(local.RemoteAddr().String())
+			originatorPort, _ := strconv.ParseUint(port, 10, 32)
+			channel, requests, err := conn.OpenChannel("x11", gossh.Marshal(x11ChannelData{
+				OriginatorAddress: host,
+				OriginatorPort:    uint32(originatorPort),
+			}))
+			if err != nil {
+				a.logger.Debug(ctx, "open x11 channel", slog.Error(err))
+				_ = local.Close()
+				continue
+			}
+			go gossh.DiscardRequests(requests)
+			go Bicopy(ctx, local, channel)
+		}
+	}()
+	return listener, fmt.Sprintf("localhost:%d.%d", number, req.ScreenNumber), nil
+}
@@ -54,6 +54,11 @@ func Version() string {
 	return version
 }
 
+// IsDevVersion returns whether the version is a developer build.
+func IsDevVersion(v string) bool {
+	return strings.HasPrefix(v, develPrefix)
+}
+
 // VersionsMatch compares the two versions. It assumes the versions match if
 // the major and the minor versions are equivalent. Patch versions are
 // disregarded. If it detects that either version is a developer build it
@@ -61,7 +66,7 @@ func Version() string {
 func VersionsMatch(v1, v2 string) bool {
 	// Developer versions are disregarded...hopefully they know what they are
 	// doing.
-	if strings.HasPrefix(v1, develPrefix) || strings.HasPrefix(v2, develPrefix) {
+	if IsDevVersion(v1) || IsDevVersion(v2) {
 		return true
 	}
 
@@ -31,6 +31,14 @@ func TestBuildInfo(t *testing.T) {
 		require.False(t, valid)
 	})
 
+	t.Run("IsDevVersion", func(t *testing.T) {
+		t.Parallel()
+		require.True(t, buildinfo.IsDevVersion(buildinfo.Version()))
+		require.True(t, buildinfo.IsDevVersion("v0.0.0-devel+123abac"))
+		require.False(t, buildinfo.IsDevVersion("v1.2.3-devel+123abac"))
+		require.False(t, buildinfo.IsDevVersion("v1.2.3"))
+	})
+
 	t.Run("VersionsMatch", func(t *testing.T) {
 		t.Parallel()
 
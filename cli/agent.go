@@ -20,6 +20,7 @@ import (
 	"cdr.dev/slog/sloggers/sloghuman"
 	"github.com/coder/coder/agent"
 	"github.com/coder/coder/agent/reaper"
+	"github.com/coder/coder/buildinfo"
 	"github.com/coder/coder/cli/cliflag"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/retry"
@@ -32,6 +33,11 @@ func workspaceAgent() *cobra.Command {
 		pprofAddress string
 		noReap       bool
 		wireguard    bool
+		ptyBuffer    int
+		persistPTYs  bool
+		autoUpdate   bool
+		rawChecks    []string
+		checkEvery   time.Duration
 	)
 	cmd := &cobra.Command{
 		Use: "agent",
@@ -46,6 +52,14 @@ func workspaceAgent() *cobra.Command {
 			if err != nil {
 				return xerrors.Errorf("parse %q: %w", rawURL, err)
 			}
+			healthChecks := make([]agent.HealthCheck, 0, len(rawChecks))
+			for _, rawCheck := range rawChecks {
+				healthCheck, err := agent.ParseHealthCheck(rawCheck)
+				if err != nil {
+					return err
+				}
+				healthChecks = append(healthChecks, healthCheck)
+			}
 
 			logWriter := &lumberjack.Logger{
 				Filename: filepath.Join(os.TempDir(), "coder-agent.log"),
@@ -172,6 +186,27 @@ func workspaceAgent() *cobra.Command {
 				return xerrors.Errorf("add executable to $PATH: %w", err)
 			}
 
+			if updatedFrom, ok := os.LookupEnv(agentUpdatedFromEnv); ok {
+				// Don't leak the variable into the shells of the workspace.
+				_ = os.Unsetenv(agentUpdatedFromEnv)
+				if autoUpdate && updatedFrom == buildinfo.Version() {
+					logger.Error(cmd.Context(), "agent was restarted after an update but the version didn't change, disabling updates",
+						slog.F("version", updatedFrom))
+					autoUpdate = false
+				}
+			}
+			if autoUpdate {
+				updated, err := updateAgentBinary(cmd.Context(), client, executablePath, buildinfo.Version())
+				if err != nil {
+					logger.Warn(cmd.Context(), "update agent", slog.Error(err))
+				}
+				if updated {
+					logger.Info(cmd.Context(), "agent was updated, restarting")
+					_ = os.Setenv(agentUpdatedFromEnv, buildinfo.Version())
+					return reexecAgent(executablePath)
+				}
+			}
+
 			closer := agent.New(client.ListenWorkspaceAgent, &agent.Options{
 				Logger: logger,
 				EnvironmentVariables: map[string]string{
@@ -179,10 +214,45 @@ func workspaceAgent() *cobra.Command {
 					// shells so "gitssh" works!
 					"CODER_AGENT_TOKEN": client.SessionToken,
 				},
-				EnableWireguard:      wireguard,
-				UploadWireguardKeys:  client.UploadWorkspaceAgentKeys,
-				ListenWireguardPeers: client.WireguardPeerListener,
+				EnableWireguard:           wireguard,
+				UploadWireguardKeys:       client.UploadWorkspaceAgentKeys,
+				ListenWireguardPeers:      client.WireguardPeerListener,
+				UploadSessionRecording:    client.UploadWorkspaceAgentSessionRecording,
+				ReconnectingPTYBufferSize: ptyBuffer,
+				PersistReconnectingPTYs:   persistPTYs,
+				HealthChecks:              healthChecks,
+				HealthCheckInterval:       checkEvery,
+				ReportHealth:              client.PostWorkspaceAgentHealth,
 			})
+			if autoUpdate {
+				go func() {
+					ticker := time.NewTicker(agentUpdateInterval)
+					defer ticker.Stop()
+					for {
+						select {
+						case <-cmd.Context().Done():
+							return
+						case <-ticker.C:
+						}
+						updated, err := updateAgentBinary(cmd.Context(), client, executablePath, buildinfo.Version())
+						if err != nil {
+							logger.Warn(cmd.Context(), "update agent", slog.Error(err))
+							continue
+						}
+						if !updated {
+							continue
+						}
+						logger.Info(cmd.Context(), "agent was updated, restarting")
+						_ = closer.Close()
+						_ = os.Setenv(agentUpdatedFromEnv, buildinfo.Version())
+						err = reexecAgent(executablePath)
+						if err != nil {
+							logger.Fatal(cmd.Context(), "restart updated agent", slog.Error(err))
+						}
+					}
+				}()
+			}
+
 			<-cmd.Context().Done()
 			return closer.Close()
 		},
@@ -192,6 +262,11 @@ func workspaceAgent() *cobra.Command {
 	cliflag.BoolVarP(cmd.Flags(), &pprofEnabled, "pprof-enable", "", "CODER_AGENT_PPROF_ENABLE", false, "Enable serving pprof metrics on the address defined by --pprof-address.")
 	cliflag.BoolVarP(cmd.Flags(), &noReap, "no-reap", "", "", false, "Do not start a process reaper.")
 	cliflag.StringVarP(cmd.Flags(), &pprofAddress, "pprof-address", "", "CODER_AGENT_PPROF_ADDRESS", "127.0.0.1:6060", "The address to serve pprof.")
+	cliflag.IntVarP(cmd.Flags(), &ptyBuffer, "pty-buffer-size", "", "CODER_AGENT_PTY_BUFFER_SIZE", 64<<10, "The number of bytes of output that web terminals replay when they reconnect.")
+	cliflag.BoolVarP(cmd.Flags(), &persistPTYs, "persist-ptys", "", "CODER_AGENT_PERSIST_PTYS", false, "Run web terminals in tmux sessions, so they survive restarts of the agent. Requires tmux.")
+	cliflag.BoolVarP(cmd.Flags(), &autoUpdate, "auto-update", "", "CODER_AGENT_AUTO_UPDATE", false, "Download the agent binary from coderd and restart when the version of coderd changes.")
+	cliflag.StringArrayLinesVarP(cmd.Flags(), &rawChecks, "health-check", "", "CODER_AGENT_HEALTH_CHECKS", nil, "A health check in the form name=command or name=url. Commands fail with a non-zero exit code, and URLs fail with a status code of 400 or higher. Can be specified multiple times, or on separate lines of the environment variable.")
+	cliflag.DurationVarP(cmd.Flags(), &checkEvery, "health-check-interval", "", "CODER_AGENT_HEALTH_CHECK_INTERVAL", time.Minute, "How often health checks run.")
 	cliflag.BoolVarP(cmd.Flags(), &wireguard, "wireguard", "", "CODER_AGENT_WIREGUARD", true, "Whether to start the Wireguard interface.")
 	return cmd
 }
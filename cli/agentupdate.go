@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha1" //#nosec // coderd publishes SHA-1 hashes of its binaries.
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/buildinfo"
+	"github.com/coder/coder/codersdk"
+)
+
+// agentUpdateInterval is how often agents with --auto-update check whether
+// coderd was upgraded.
+const agentUpdateInterval = 30 * time.Minute
+
+// agentUpdatedFromEnv is set to the previous version when the agent restarts
+// itself after an update. If the restarted agent still reports that version,
+// updating didn't change anything and must not be retried.
+const agentUpdatedFromEnv = "CODER_AGENT_UPDATED_FROM"
+
+// updateAgentBinary replaces the agent binary at executable with the binary
+// served by coderd, if the version of coderd differs from currentVersion. It
+// returns whether the binary was replaced. Developer builds are never
+// replaced.
+//
+// The download is checked against the hashes coderd serves in coder.sha1, and
+// must report the version of coderd before it replaces the running binary.
+func updateAgentBinary(ctx context.Context, client *codersdk.Client, executable, currentVersion string) (bool, error) {
+	info, err := client.BuildInfo(ctx)
+	if err != nil {
+		return false, xerrors.Errorf("get coderd version: %w", err)
+	}
+	if info.Version == currentVersion || buildinfo.IsDevVersion(info.Version) || buildinfo.IsDevVersion(currentVersion) {
+		return false, nil
+	}
+
+	// These are the binaries that templates download to start agents.
+	name := fmt.Sprintf("coder-%s-%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	wantHash, err := agentBinaryHash(ctx, client, name)
+	if err != nil {
+		return false, err
+	}
+	res, err := client.Request(ctx, http.MethodGet, "/bin/"+name, nil)
+	if err != nil {
+		return false, xerrors.Errorf("download %s: %w", name, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return false, xerrors.Errorf("download %s: unexpected status code %d", name, res.StatusCode)
+	}
+
+	// The new binary is written next to the old one, so it can be renamed
+	// into place atomically.
+	file, err := os.CreateTemp(filepath.Dir(executable), filepath.Base(executable)+".*.new")
+	if err != nil {
+		return false, xerrors.Errorf("create file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	hash := sha1.New() //nolint:gosec // coderd publishes SHA-1 hashes.
+	_, err = io.Copy(io.MultiWriter(file, hash), res.Body)
+	if err != nil {
+		_ = file.Close()
+		return false, xerrors.Errorf("write %s: %w", file.Name(), err)
+	}
+	err = file.Close()
+	if err != nil {
+		return false, xerrors.Errorf("close %s: %w", file.Name(), err)
+	}
+	gotHash := hex.EncodeToString(hash.Sum(nil))
+	if wantHash != "" && gotHash != wantHash {
+		return false, xerrors.Errorf("download %s: sha1 %s does not match %s", name, gotHash, wantHash)
+	}
+	currentHash, err := sha1File(executable)
+	if err != nil {
+		return false, xerrors.Errorf("hash %s: %w", executable, err)
+	}
+	if currentHash == gotHash {
+		// coderd serves the binary that is already running, so replacing
+		// it would only restart the agent.
+		return false, nil
+	}
+	err = os.Chmod(file.Name(), 0o755)
+	if err != nil {
+		return false, xerrors.Errorf("chmod %s: %w", file.Name(), err)
+	}
+	err = verifyAgentBinaryVersion(ctx, file.Name(), info.Version)
+	if err != nil {
+		return false, err
+	}
+	if runtime.GOOS == "windows" {
+		// Running executables can't be replaced on Windows, but they
+		// can be renamed.
+		old := executable + ".old"
+		_ = os.Remove(old)
+		err = os.Rename(executable, old)
+		if err != nil {
+			return false, xerrors.Errorf("move %s: %w", executable, err)
+		}
+	}
+	err = os.Rename(file.Name(), executable)
+	if err != nil {
+		return false, xerrors.Errorf("replace %s: %w", executable, err)
+	}
+	return true, nil
+}
+
+// agentBinaryHash returns the hex-encoded SHA-1 of the named binary from the
+// coder.sha1 file served by coderd. An empty hash is returned if coderd
+// doesn't serve the file, like when binaries were placed in the cache
+// directory by hand.
+func agentBinaryHash(ctx context.Context, client *codersdk.Client, name string) (string, error) {
+	res, err := client.Request(ctx, http.MethodGet, "/bin/coder.sha1", nil)
+	if err != nil {
+		return "", xerrors.Errorf("download coder.sha1: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", xerrors.Errorf("download coder.sha1: unexpected status code %d", res.StatusCode)
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", xerrors.Errorf("read coder.sha1: %w", err)
+	}
+	// Lines are in the format of "shasum -b": "<hash> *<name>".
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), " *", 2)
+		if len(parts) == 2 && parts[1] == name {
+			return strings.ToLower(parts[0]), nil
+		}
+	}
+	return "", xerrors.Errorf("coder.sha1 has no hash for %s", name)
+}
+
+func sha1File(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha1.New() //nolint:gosec // Only used to compare binaries.
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// verifyAgentBinaryVersion runs "<path> version" and checks that the binary
+// reports the expected version. This catches binaries that don't run on this
+// system, and coderd versions that don't match the binaries it serves, which
+// would otherwise restart the agent every time it checks for updates.
+func verifyAgentBinaryVersion(ctx context.Context, path, version string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	//nolint:gosec // The binary was verified against coder.sha1.
+	out, err := exec.CommandContext(ctx, path, "version").Output()
+	if err != nil {
+		return xerrors.Errorf("run %s version: %w", path, err)
+	}
+	// The output starts with "Coder <version>".
+	fields := strings.Fields(string(out))
+	if len(fields) < 2 || fields[1] != version {
+		return xerrors.Errorf("downloaded binary reports version %q, expected %q", strings.TrimSpace(string(out)), version)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha1" //#nosec // coderd publishes SHA-1 hashes of its binaries.
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestUpdateAgentBinary(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("The served binary is a shell script.")
+	}
+
+	// binary returns a script that prints version like "coder version".
+	binary := func(version string) []byte {
+		return []byte(fmt.Sprintf("#!/bin/sh\necho 'Coder %s'\n", version))
+	}
+	hash := func(data []byte) string {
+		sum := sha1.Sum(data) //nolint:gosec
+		return hex.EncodeToString(sum[:])
+	}
+	setup := func(t *testing.T, serverVersion string, served, current []byte, sha1File string) (*codersdk.Client, string) {
+		name := fmt.Sprintf("coder-%s-%s", runtime.GOOS, runtime.GOARCH)
+		if sha1File == "" {
+			sha1File = fmt.Sprintf("%s *%s\n", hash(served), name)
+		}
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v2/buildinfo":
+				_ = json.NewEncoder(rw).Encode(codersdk.BuildInfoResponse{Version: serverVersion})
+			case "/bin/" + name:
+				_, _ = rw.Write(served)
+			case "/bin/coder.sha1":
+				_, _ = rw.Write([]byte(sha1File))
+			default:
+				rw.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		t.Cleanup(srv.Close)
+		serverURL, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+
+		executable := filepath.Join(t.TempDir(), "coder")
+		err = os.WriteFile(executable, current, 0o755)
+		require.NoError(t, err)
+		return codersdk.New(serverURL), executable
+	}
+
+	for _, testCase := range []struct {
+		Name           string
+		ServerVersion  string
+		CurrentVersion string
+		// Served is the version the served binary reports.
+		Served string
+		// SHA1 overrides the served coder.sha1.
+		SHA1    string
+		Updated bool
+		Error   string
+	}{
+		{Name: "Outdated", ServerVersion: "v1.1.0", CurrentVersion: "v1.0.0", Served: "v1.1.0", Updated: true},
+		{Name: "SameVersion", ServerVersion: "v1.0.0", CurrentVersion: "v1.0.0", Served: "v1.0.0"},
+		{Name: "DevServer", ServerVersion: "v0.0.0-devel+12ab34c", CurrentVersion: "v1.0.0", Served: "v1.1.0"},
+		{Name: "DevAgent", ServerVersion: "v1.1.0", CurrentVersion: "v0.0.0-devel+12ab34c", Served: "v1.1.0"},
+		// The served binary is the one that's already running.
+		{Name: "Identical", ServerVersion: "v1.1.0", CurrentVersion: "v1.0.0", Served: "v1.0.0"},
+		{Name: "WrongVersion", ServerVersion: "v1.1.0", CurrentVersion: "v1.0.0", Served: "v1.2.0", Error: "reports version"},
+		{Name: "HashMismatch", ServerVersion: "v1.1.0", CurrentVersion: "v1.0.0", Served: "v1.1.0", SHA1: "0000 *coder-" + runtime.GOOS + "-" + runtime.GOARCH, Error: "does not match"},
+		{Name: "MissingHash", ServerVersion: "v1.1.0", CurrentVersion: "v1.0.0", Served: "v1.1.0", SHA1: "0000 *coder-plan9-amd64", Error: "no hash"},
+	} {
+		testCase := testCase
+		t.Run(testCase.Name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+			defer cancel()
+
+			current := binary("v1.0.0")
+			served := binary(testCase.Served)
+			client, executable := setup(t, testCase.ServerVersion, served, current, testCase.SHA1)
+			updated, err := updateAgentBinary(ctx, client, executable, testCase.CurrentVersion)
+			if testCase.Error != "" {
+				require.ErrorContains(t, err, testCase.Error)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, testCase.Updated, updated)
+
+			data, err := os.ReadFile(executable)
+			require.NoError(t, err)
+			if testCase.Updated {
+				require.Equal(t, served, data)
+			} else {
+				require.Equal(t, current, data)
+			}
+			// Temporary files must not be left behind.
+			entries, err := os.ReadDir(filepath.Dir(executable))
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+		})
+	}
+}
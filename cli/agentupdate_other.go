@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+// reexecAgent replaces the agent process with the updated binary.
+func reexecAgent(executable string) error {
+	return syscall.Exec(executable, os.Args, os.Environ())
+}
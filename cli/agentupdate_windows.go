@@ -0,0 +1,26 @@
+//go:build windows
+// +build windows
+
+package cli
+
+import (
+	"os"
+	"os/exec"
+)
+
+// reexecAgent starts the updated binary and exits, since Windows can't replace
+// the image of a running process.
+func reexecAgent(executable string) error {
+	//nolint:gosec // The arguments are the ones the agent was started with.
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	err := cmd.Start()
+	if err != nil {
+		return err
+	}
+	os.Exit(0)
+	return nil
+}
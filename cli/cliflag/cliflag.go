@@ -73,6 +73,23 @@ func StringArrayVarP(flagset *pflag.FlagSet, ptr *[]string, name string, shortha
 	flagset.StringArrayVarP(ptr, name, shorthand, def, fmtUsage(usage, env))
 }
 
+// StringArrayLinesVarP sets a string array flag on the given flag set. Values
+// in the environment variable are separated by newlines instead of commas, so
+// they can contain commas. Blank lines are ignored.
+func StringArrayLinesVarP(flagset *pflag.FlagSet, ptr *[]string, name string, shorthand string, env string, def []string, usage string) {
+	val, ok := os.LookupEnv(env)
+	if ok {
+		def = []string{}
+		for _, line := range strings.Split(val, "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" {
+				def = append(def, line)
+			}
+		}
+	}
+	flagset.StringArrayVarP(ptr, name, shorthand, def, fmtUsage(usage, env))
+}
+
 // Uint8VarP sets a uint8 flag on the given flag set.
 func Uint8VarP(flagset *pflag.FlagSet, ptr *uint8, name string, shorthand string, env string, def uint8, usage string) {
 	val, ok := os.LookupEnv(env)
@@ -90,6 +107,23 @@ func Uint8VarP(flagset *pflag.FlagSet, ptr *uint8, name string, shorthand string
 	flagset.Uint8VarP(ptr, name, shorthand, uint8(vi64), fmtUsage(usage, env))
 }
 
+// IntVarP sets an int flag on the given flag set.
+func IntVarP(flagset *pflag.FlagSet, ptr *int, name string, shorthand string, env string, def int, usage string) {
+	val, ok := os.LookupEnv(env)
+	if !ok || val == "" {
+		flagset.IntVarP(ptr, name, shorthand, def, fmtUsage(usage, env))
+		return
+	}
+
+	vi, err := strconv.Atoi(val)
+	if err != nil {
+		flagset.IntVarP(ptr, name, shorthand, def, fmtUsage(usage, env))
+		return
+	}
+
+	flagset.IntVarP(ptr, name, shorthand, vi, fmtUsage(usage, env))
+}
+
 func Bool(flagset *pflag.FlagSet, name, shorthand, env string, def bool, usage string) {
 	val, ok := os.LookupEnv(env)
 	if !ok || val == "" {
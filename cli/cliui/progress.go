@@ -0,0 +1,90 @@
+package cliui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProgressBar displays the progress of a transfer. Writes to the bar advance
+// it, so it can be used with io.TeeReader or io.MultiWriter.
+type ProgressBar struct {
+	writer io.Writer
+	name   string
+	total  int64
+
+	mutex      sync.Mutex
+	current    int64
+	lastRender time.Time
+}
+
+// Progress starts a progress bar for a transfer of total bytes. Done must be
+// called when the transfer ends.
+func Progress(writer io.Writer, name string, total int64) *ProgressBar {
+	bar := &ProgressBar{
+		writer: writer,
+		name:   name,
+		total:  total,
+	}
+	bar.render()
+	return bar
+}
+
+// Add advances the bar without a write, e.g. for resumed transfers.
+func (p *ProgressBar) Add(n int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.current += n
+	// Avoid flooding the terminal when writes are small.
+	if time.Since(p.lastRender) < 100*time.Millisecond {
+		return
+	}
+	p.render()
+}
+
+func (p *ProgressBar) Write(b []byte) (int, error) {
+	p.Add(int64(len(b)))
+	return len(b), nil
+}
+
+// Done renders the final state of the bar and ends its line.
+func (p *ProgressBar) Done() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.render()
+	_, _ = fmt.Fprintln(p.writer)
+}
+
+func (p *ProgressBar) render() {
+	const width = 30
+	p.lastRender = time.Now()
+	ratio := 1.0
+	if p.total > 0 {
+		ratio = float64(p.current) / float64(p.total)
+	}
+	if ratio > 1 {
+		ratio = 1
+	}
+	filled := int(ratio * width)
+	bar := strings.Repeat("=", filled)
+	if filled < width {
+		bar += ">" + strings.Repeat(" ", width-filled-1)
+	}
+	_, _ = fmt.Fprintf(p.writer, "\r%s [%s] %3.0f%% %s / %s", p.name, Styles.Keyword.Render(bar), ratio*100, FormatBytes(p.current), FormatBytes(p.total))
+}
+
+// FormatBytes formats a number of bytes with a binary unit, e.g. "1.5 MiB".
+func FormatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,37 @@
+package cliui_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/cliui"
+)
+
+func TestProgress(t *testing.T) {
+	t.Parallel()
+
+	buf := new(bytes.Buffer)
+	bar := cliui.Progress(buf, "file.txt", 2048)
+	_, err := io.Copy(bar, strings.NewReader(strings.Repeat("x", 2048)))
+	require.NoError(t, err)
+	bar.Done()
+
+	lines := strings.Split(buf.String(), "\r")
+	last := lines[len(lines)-1]
+	require.Contains(t, last, "file.txt")
+	require.Contains(t, last, "100%")
+	require.Contains(t, last, "2.0 KiB / 2.0 KiB")
+	require.True(t, strings.HasSuffix(last, "\n"))
+}
+
+func TestFormatBytes(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "512 B", cliui.FormatBytes(512))
+	require.Equal(t, "1.5 KiB", cliui.FormatBytes(1536))
+	require.Equal(t, "3.0 GiB", cliui.FormatBytes(3<<30))
+}
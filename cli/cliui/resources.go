@@ -103,6 +103,9 @@ func WorkspaceResources(writer io.Writer, resources []codersdk.WorkspaceResource
 							Styles.Placeholder.Render("["+strconv.Itoa(int(since.Seconds()))+"s]")
 					case codersdk.WorkspaceAgentConnected:
 						agentStatus = Styles.Keyword.Render("⦿ connected")
+						if !agent.Health.Healthy {
+							agentStatus = Styles.Warn.Render("⦿ degraded")
+						}
 					}
 				}
 				row = append(row, agentStatus)
@@ -28,6 +28,9 @@ func TestWorkspaceResources(t *testing.T) {
 					Status:          codersdk.WorkspaceAgentConnected,
 					Architecture:    "amd64",
 					OperatingSystem: "linux",
+					Health: codersdk.WorkspaceAgentHealth{
+						Healthy: true,
+					},
 				}},
 			}}, cliui.WorkspaceResourcesOptions{
 				WorkspaceName: "example",
@@ -73,6 +76,11 @@ func TestWorkspaceResources(t *testing.T) {
 					Name:            "go",
 					Architecture:    "amd64",
 					OperatingSystem: "linux",
+					Health: codersdk.WorkspaceAgentHealth{
+						Checks: []codersdk.WorkspaceAgentHealthCheck{{
+							Name: "disk",
+						}},
+					},
 				}, {
 					DisconnectedAt:  &disconnected,
 					Status:          codersdk.WorkspaceAgentDisconnected,
@@ -90,6 +98,7 @@ func TestWorkspaceResources(t *testing.T) {
 		}()
 		ptty.ExpectMatch("google_compute_disk.root")
 		ptty.ExpectMatch("google_compute_instance.dev")
+		ptty.ExpectMatch("degraded")
 		ptty.ExpectMatch("coder ssh dev.postgres")
 		<-done
 	})
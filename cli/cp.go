@@ -0,0 +1,458 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"github.com/spf13/cobra"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func cp() *cobra.Command {
+	var (
+		recursive bool
+		resume    bool
+	)
+	cmd := &cobra.Command{
+		Annotations: workspaceCommand,
+		Use:         "cp <source>... <destination>",
+		Short:       "Copy files to and from a workspace",
+		Long: "Workspace paths are prefixed with the name of the workspace, e.g. \"my-workspace:~/notes.txt\". " +
+			"Relative workspace paths start in the directory of SSH sessions, and sources can be glob patterns. " +
+			"Files are transferred over the SFTP subsystem of the workspace agent, so OpenSSH isn't required.",
+		Example: formatExamples(
+			example{
+				Description: "Copy a file to the home directory of a workspace",
+				Command:     "coder cp ./notes.txt my-workspace:~",
+			},
+			example{
+				Description: "Copy the logs of a workspace into a local directory",
+				Command:     "coder cp --recursive 'my-workspace:/var/log/*.log' ./logs",
+			},
+			example{
+				Description: "Continue an interrupted download of a large file",
+				Command:     "coder cp --resume my-workspace:dump.sql .",
+			},
+		),
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			sources, destination := args[:len(args)-1], args[len(args)-1]
+
+			workspaceName, destinationPath, destinationRemote := parseCopyPath(destination)
+			sourcePaths := make([]string, 0, len(sources))
+			for _, source := range sources {
+				name, sourcePath, remote := parseCopyPath(source)
+				if remote == destinationRemote {
+					return xerrors.New("either the sources or the destination must be in a workspace, but not both")
+				}
+				if remote {
+					if workspaceName != "" && name != workspaceName {
+						return xerrors.New("sources must be in the same workspace")
+					}
+					workspaceName = name
+				}
+				sourcePaths = append(sourcePaths, sourcePath)
+			}
+
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			workspace, workspaceAgent, err := getWorkspaceAndAgent(ctx, cmd, client, codersdk.Me, workspaceName, false)
+			if err != nil {
+				return err
+			}
+			err = cliui.Agent(ctx, cmd.ErrOrStderr(), cliui.AgentOptions{
+				WorkspaceName: workspace.Name,
+				Fetch: func(ctx context.Context) (codersdk.WorkspaceAgent, error) {
+					return client.WorkspaceAgent(ctx, workspaceAgent.ID)
+				},
+			})
+			if err != nil {
+				return xerrors.Errorf("await agent: %w", err)
+			}
+			conn, err := client.DialWorkspaceAgent(ctx, workspaceAgent.ID, nil)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+			sshClient, err := conn.SSHClient()
+			if err != nil {
+				return xerrors.Errorf("ssh: %w", err)
+			}
+			defer sshClient.Close()
+			sftpClient, err := sftp.NewClient(sshClient)
+			if err != nil {
+				return xerrors.Errorf("sftp: %w", err)
+			}
+			defer sftpClient.Close()
+			workingDirectory, homeDirectory, err := remoteDirectories(sshClient, workspaceAgent.OperatingSystem)
+			if err != nil {
+				return xerrors.Errorf("get workspace directories: %w", err)
+			}
+
+			copier := &copier{
+				recursive: recursive,
+				resume:    resume,
+				progress:  cmd.ErrOrStderr(),
+				src:       localFS{},
+				dst: remoteFS{
+					client:           sftpClient,
+					workingDirectory: workingDirectory,
+					homeDirectory:    homeDirectory,
+				},
+			}
+			if !destinationRemote {
+				copier.src, copier.dst = copier.dst, copier.src
+			}
+			return copier.copy(sourcePaths, destinationPath)
+		},
+	}
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Copy directories recursively.")
+	cmd.Flags().BoolVar(&resume, "resume", false, "Continue copying files that were partially copied before, instead of overwriting them.")
+	return cmd
+}
+
+// parseCopyPath splits "<workspace>:<path>" arguments. Paths without a
+// workspace are local.
+func parseCopyPath(arg string) (workspace string, filePath string, remote bool) {
+	index := strings.Index(arg, ":")
+	// Windows paths start with a drive letter, e.g. "C:\Users".
+	if index <= 0 || (runtime.GOOS == "windows" && index == 1) || strings.ContainsAny(arg[:index], `/\`) {
+		return "", arg, false
+	}
+	return arg[:index], arg[index+1:], true
+}
+
+// remoteDirectories returns the directory that SSH sessions start in and the
+// home directory of the workspace, which are used to resolve relative paths.
+func remoteDirectories(sshClient *gossh.Client, operatingSystem string) (workingDirectory string, homeDirectory string, err error) {
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return "", "", err
+	}
+	defer session.Close()
+	command := `pwd && echo "$HOME"`
+	if operatingSystem == "windows" {
+		command = "echo %CD%& echo %USERPROFILE%"
+	}
+	out, err := session.Output(command)
+	if err != nil {
+		return "", "", err
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 2 {
+		return "", "", xerrors.Errorf("unexpected output %q", out)
+	}
+	// SFTP paths use forward slashes on every platform.
+	workingDirectory = strings.ReplaceAll(strings.TrimSpace(lines[0]), `\`, "/")
+	homeDirectory = strings.ReplaceAll(strings.TrimSpace(lines[1]), `\`, "/")
+	return workingDirectory, homeDirectory, nil
+}
+
+// copyFS is the local or the workspace filesystem.
+type copyFS interface {
+	// Resolve expands the home directory and relative paths.
+	Resolve(name string) (string, error)
+	Glob(pattern string) ([]string, error)
+	Stat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.FileInfo, error)
+	MkdirAll(name string) error
+	// Open opens a file for reading from offset.
+	Open(name string, offset int64) (io.ReadCloser, error)
+	// Create opens a file for writing from offset. The file is truncated
+	// when offset is zero.
+	Create(name string, offset int64, perm fs.FileMode) (io.WriteCloser, error)
+	Join(elem ...string) string
+	Base(name string) string
+}
+
+type copier struct {
+	recursive bool
+	resume    bool
+	progress  io.Writer
+	src       copyFS
+	dst       copyFS
+}
+
+func (c *copier) copy(sources []string, destination string) error {
+	var matches []string
+	for _, source := range sources {
+		source, err := c.src.Resolve(source)
+		if err != nil {
+			return err
+		}
+		sourceMatches, err := c.src.Glob(source)
+		if err != nil {
+			return xerrors.Errorf("glob %q: %w", source, err)
+		}
+		if len(sourceMatches) == 0 {
+			return xerrors.Errorf("%q: no such file or directory", source)
+		}
+		matches = append(matches, sourceMatches...)
+	}
+
+	destination, err := c.dst.Resolve(destination)
+	if err != nil {
+		return err
+	}
+	info, err := c.dst.Stat(destination)
+	destinationIsDir := err == nil && info.IsDir()
+	if !destinationIsDir && (len(matches) > 1 || strings.HasSuffix(destination, "/")) {
+		if err == nil {
+			return xerrors.Errorf("%q is not a directory", destination)
+		}
+		err = c.dst.MkdirAll(destination)
+		if err != nil {
+			return xerrors.Errorf("create directory %q: %w", destination, err)
+		}
+		destinationIsDir = true
+	}
+
+	for _, match := range matches {
+		target := destination
+		if destinationIsDir {
+			target = c.dst.Join(destination, c.src.Base(match))
+		}
+		err = c.copyPath(match, target)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *copier) copyPath(source, destination string) error {
+	info, err := c.src.Stat(source)
+	if err != nil {
+		return err
+	}
+	if info.Mode().IsRegular() {
+		return c.copyFile(source, destination, info)
+	}
+	if !info.IsDir() {
+		_, _ = fmt.Fprintf(c.progress, "Skipping %q, it isn't a regular file.\n", source)
+		return nil
+	}
+	if !c.recursive {
+		return xerrors.Errorf("%q is a directory, use --recursive to copy it", source)
+	}
+	err = c.dst.MkdirAll(destination)
+	if err != nil {
+		return xerrors.Errorf("create directory %q: %w", destination, err)
+	}
+	entries, err := c.src.ReadDir(source)
+	if err != nil {
+		return xerrors.Errorf("read directory %q: %w", source, err)
+	}
+	for _, entry := range entries {
+		err = c.copyPath(c.src.Join(source, entry.Name()), c.dst.Join(destination, entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *copier) copyFile(source, destination string, info fs.FileInfo) error {
+	var offset int64
+	if c.resume {
+		// Partially copied files are assumed to be a prefix of the
+		// source.
+		existing, err := c.dst.Stat(destination)
+		if err == nil && existing.Mode().IsRegular() && existing.Size() <= info.Size() {
+			offset = existing.Size()
+		}
+	}
+
+	reader, err := c.src.Open(source, offset)
+	if err != nil {
+		return xerrors.Errorf("open %q: %w", source, err)
+	}
+	defer reader.Close()
+	writer, err := c.dst.Create(destination, offset, info.Mode().Perm())
+	if err != nil {
+		return xerrors.Errorf("create %q: %w", destination, err)
+	}
+	defer writer.Close()
+
+	bar := cliui.Progress(c.progress, c.src.Base(source), info.Size())
+	bar.Add(offset)
+	_, err = io.Copy(writer, io.TeeReader(reader, bar))
+	bar.Done()
+	if err != nil {
+		return xerrors.Errorf("copy %q: %w", source, err)
+	}
+	err = writer.Close()
+	if err != nil {
+		return xerrors.Errorf("close %q: %w", destination, err)
+	}
+	return nil
+}
+
+type localFS struct{}
+
+func (localFS) Resolve(name string) (string, error) {
+	if name == "~" || strings.HasPrefix(name, "~/") {
+		return agent.ExpandRelativeHomePath(name)
+	}
+	return name, nil
+}
+
+func (localFS) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+func (localFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (localFS) ReadDir(name string) ([]fs.FileInfo, error) {
+	dir, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+	return dir.Readdir(-1)
+}
+
+func (localFS) MkdirAll(name string) error {
+	return os.MkdirAll(name, 0o755)
+}
+
+func (localFS) Open(name string, offset int64) (io.ReadCloser, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+func (localFS) Create(name string, offset int64, perm fs.FileMode) (io.WriteCloser, error) {
+	flags := os.O_WRONLY | os.O_CREATE
+	if offset == 0 {
+		flags |= os.O_TRUNC
+	}
+	file, err := os.OpenFile(name, flags, perm)
+	if err != nil {
+		return nil, err
+	}
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+func (localFS) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+func (localFS) Base(name string) string {
+	return filepath.Base(name)
+}
+
+type remoteFS struct {
+	client           *sftp.Client
+	workingDirectory string
+	homeDirectory    string
+}
+
+func (r remoteFS) Resolve(name string) (string, error) {
+	switch {
+	case name == "~":
+		return r.homeDirectory, nil
+	case strings.HasPrefix(name, "~/"):
+		return path.Join(r.homeDirectory, name[2:]), nil
+	case name == "":
+		return r.workingDirectory, nil
+	case path.IsAbs(name) || (len(name) > 1 && name[1] == ':'):
+		return name, nil
+	}
+	resolved := path.Join(r.workingDirectory, name)
+	if strings.HasSuffix(name, "/") {
+		resolved += "/"
+	}
+	return resolved, nil
+}
+
+func (r remoteFS) Glob(pattern string) ([]string, error) {
+	return r.client.Glob(pattern)
+}
+
+func (r remoteFS) Stat(name string) (fs.FileInfo, error) {
+	return r.client.Stat(name)
+}
+
+func (r remoteFS) ReadDir(name string) ([]fs.FileInfo, error) {
+	return r.client.ReadDir(name)
+}
+
+func (r remoteFS) MkdirAll(name string) error {
+	return r.client.MkdirAll(name)
+}
+
+func (r remoteFS) Open(name string, offset int64) (io.ReadCloser, error) {
+	file, err := r.client.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+func (r remoteFS) Create(name string, offset int64, perm fs.FileMode) (io.WriteCloser, error) {
+	flags := os.O_WRONLY | os.O_CREATE
+	if offset == 0 {
+		flags |= os.O_TRUNC
+	}
+	file, err := r.client.OpenFile(name, flags)
+	if err != nil {
+		return nil, err
+	}
+	if offset == 0 {
+		err = file.Chmod(perm)
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	}
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+func (remoteFS) Join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+func (remoteFS) Base(name string) string {
+	return path.Base(name)
+}
@@ -0,0 +1,128 @@
+package cli_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestCp(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("Workspace paths are resolved with a POSIX shell.")
+	}
+
+	client, workspace, agentToken := setupWorkspaceForSSH(t)
+	agentClient := codersdk.New(client.URL)
+	agentClient.SessionToken = agentToken
+	agentCloser := agent.New(agentClient.ListenWorkspaceAgent, &agent.Options{
+		Logger: slogtest.Make(t, nil),
+	})
+	t.Cleanup(func() {
+		_ = agentCloser.Close()
+	})
+	coderdtest.AwaitWorkspaceAgents(t, client, workspace.LatestBuild.ID)
+
+	run := func(t *testing.T, args ...string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		cmd, root := clitest.New(t, append([]string{"cp"}, args...)...)
+		clitest.SetupConfig(t, client, root)
+		return cmd.ExecuteContext(ctx)
+	}
+
+	t.Run("File", func(t *testing.T) {
+		t.Parallel()
+		local := t.TempDir()
+		remote := t.TempDir()
+		err := os.WriteFile(filepath.Join(local, "notes.txt"), []byte("hello"), 0o600)
+		require.NoError(t, err)
+
+		err = run(t, filepath.Join(local, "notes.txt"), workspace.Name+":"+remote)
+		require.NoError(t, err)
+		data, err := os.ReadFile(filepath.Join(remote, "notes.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(data))
+
+		err = run(t, workspace.Name+":"+filepath.Join(remote, "notes.txt"), filepath.Join(local, "copy.txt"))
+		require.NoError(t, err)
+		data, err = os.ReadFile(filepath.Join(local, "copy.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(data))
+	})
+
+	t.Run("Recursive", func(t *testing.T) {
+		t.Parallel()
+		local := t.TempDir()
+		remote := t.TempDir()
+		err := os.MkdirAll(filepath.Join(local, "project", "src"), 0o755)
+		require.NoError(t, err)
+		err = os.WriteFile(filepath.Join(local, "project", "src", "main.go"), []byte("package main"), 0o600)
+		require.NoError(t, err)
+
+		err = run(t, filepath.Join(local, "project"), workspace.Name+":"+remote)
+		require.ErrorContains(t, err, "--recursive")
+
+		err = run(t, "--recursive", filepath.Join(local, "project"), workspace.Name+":"+remote)
+		require.NoError(t, err)
+		data, err := os.ReadFile(filepath.Join(remote, "project", "src", "main.go"))
+		require.NoError(t, err)
+		require.Equal(t, "package main", string(data))
+	})
+
+	t.Run("Glob", func(t *testing.T) {
+		t.Parallel()
+		local := t.TempDir()
+		remote := t.TempDir()
+		for _, name := range []string{"a.log", "b.log", "c.txt"} {
+			err := os.WriteFile(filepath.Join(remote, name), []byte(name), 0o600)
+			require.NoError(t, err)
+		}
+
+		err := run(t, workspace.Name+":"+filepath.Join(remote, "*.log"), local)
+		require.NoError(t, err)
+		entries, err := os.ReadDir(local)
+		require.NoError(t, err)
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		require.Equal(t, []string{"a.log", "b.log"}, names)
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		t.Parallel()
+		local := t.TempDir()
+		remote := t.TempDir()
+		content := strings.Repeat("0123456789", 1000)
+		err := os.WriteFile(filepath.Join(remote, "dump.sql"), []byte(content), 0o600)
+		require.NoError(t, err)
+		// Simulate an interrupted download.
+		err = os.WriteFile(filepath.Join(local, "dump.sql"), []byte(content[:4000]), 0o600)
+		require.NoError(t, err)
+
+		err = run(t, "--resume", workspace.Name+":"+filepath.Join(remote, "dump.sql"), local)
+		require.NoError(t, err)
+		data, err := os.ReadFile(filepath.Join(local, "dump.sql"))
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+	})
+
+	t.Run("BothLocal", func(t *testing.T) {
+		t.Parallel()
+		err := run(t, "a.txt", "b.txt")
+		require.ErrorContains(t, err, "must be in a workspace")
+	})
+}
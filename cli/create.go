@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
@@ -21,6 +22,7 @@ func create() *cobra.Command {
 		startAt       string
 		stopAfter     time.Duration
 		workspaceName string
+		presetName    string
 	)
 	cmd := &cobra.Command{
 		Annotations: workspaceCommand,
@@ -120,11 +122,21 @@ func create() *cobra.Command {
 				schedSpec = ptr.Ref(sched.String())
 			}
 
+			// Parameter files set every parameter, so presets aren't offered.
+			var preset *codersdk.TemplateVersionPreset
+			if parameterFile == "" || presetName != "" {
+				preset, err = selectTemplatePreset(cmd, client, template.ActiveVersionID, presetName)
+				if err != nil {
+					return err
+				}
+			}
+
 			parameters, err := prepWorkspaceBuild(cmd, client, prepWorkspaceBuildArgs{
 				Template:         template,
 				ExistingParams:   []codersdk.Parameter{},
 				ParameterFile:    parameterFile,
 				NewWorkspaceName: workspaceName,
+				Preset:           preset,
 			})
 			if err != nil {
 				return err
@@ -145,6 +157,7 @@ func create() *cobra.Command {
 				AutostartSchedule: schedSpec,
 				TTLMillis:         ptr.Ref(stopAfter.Milliseconds()),
 				ParameterValues:   parameters,
+				Preset:            presetNameOf(preset),
 			})
 			if err != nil {
 				return err
@@ -163,6 +176,7 @@ func create() *cobra.Command {
 	cliui.AllowSkipPrompt(cmd)
 	cliflag.StringVarP(cmd.Flags(), &templateName, "template", "t", "CODER_TEMPLATE_NAME", "", "Specify a template name.")
 	cliflag.StringVarP(cmd.Flags(), &parameterFile, "parameter-file", "", "CODER_PARAMETER_FILE", "", "Specify a file path with parameter values.")
+	cliflag.StringVarP(cmd.Flags(), &presetName, "preset", "", "CODER_PRESET_NAME", "", "Specify a parameter preset of the template. Parameters the preset doesn't set are prompted for.")
 	cliflag.StringVarP(cmd.Flags(), &startAt, "start-at", "", "CODER_WORKSPACE_START_AT", "", "Specify the workspace autostart schedule. Check `coder schedule start --help` for the syntax.")
 	cliflag.DurationVarP(cmd.Flags(), &stopAfter, "stop-after", "", "CODER_WORKSPACE_STOP_AFTER", 8*time.Hour, "Specify a duration after which the workspace should shut down (e.g. 8h).")
 	return cmd
@@ -173,6 +187,72 @@ type prepWorkspaceBuildArgs struct {
 	ExistingParams   []codersdk.Parameter
 	ParameterFile    string
 	NewWorkspaceName string
+	// Preset sets parameters without prompting for them.
+	Preset *codersdk.TemplateVersionPreset
+}
+
+// selectTemplatePreset returns the preset of a template version with the
+// given name. If name is empty, the user picks one of the presets, or none to
+// answer each parameter. Nil is returned if no preset is used.
+func selectTemplatePreset(cmd *cobra.Command, client *codersdk.Client, templateVersionID uuid.UUID, name string) (*codersdk.TemplateVersionPreset, error) {
+	presets, err := client.TemplateVersionPresets(cmd.Context(), templateVersionID)
+	if err != nil {
+		return nil, xerrors.Errorf("get template version presets: %w", err)
+	}
+	if name != "" {
+		preset := findTemplatePreset(presets, name)
+		if preset == nil {
+			return nil, xerrors.Errorf("preset %q doesn't exist", name)
+		}
+		return preset, nil
+	}
+	if len(presets) == 0 {
+		return nil, nil
+	}
+
+	const customize = "Customize parameters"
+	options := make([]string, 0, len(presets)+1)
+	presetByOption := make(map[string]codersdk.TemplateVersionPreset, len(presets))
+	for _, preset := range presets {
+		option := preset.Name
+		if preset.Description != "" {
+			option += cliui.Styles.Placeholder.Render(" (" + preset.Description + ")")
+		}
+		options = append(options, option)
+		presetByOption[option] = preset
+	}
+	options = append(options, customize)
+
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), cliui.Styles.Wrap.Render("Select a preset of parameters, or customize each parameter:"))
+	option, err := cliui.Select(cmd, cliui.SelectOptions{
+		Options:    options,
+		HideSearch: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	preset, ok := presetByOption[option]
+	if !ok {
+		return nil, nil
+	}
+	return &preset, nil
+}
+
+func findTemplatePreset(presets []codersdk.TemplateVersionPreset, name string) *codersdk.TemplateVersionPreset {
+	for _, preset := range presets {
+		if preset.Name == name {
+			preset := preset
+			return &preset
+		}
+	}
+	return nil
+}
+
+func presetNameOf(preset *codersdk.TemplateVersionPreset) string {
+	if preset == nil {
+		return ""
+	}
+	return preset.Name
 }
 
 // prepWorkspaceBuild will ensure a workspace build will succeed on the latest template version.
@@ -206,6 +286,17 @@ PromptParamLoop:
 		if !parameterSchema.AllowOverrideSource {
 			continue
 		}
+		if args.Preset != nil {
+			if value, ok := args.Preset.Parameters[parameterSchema.Name]; ok {
+				parameters = append(parameters, codersdk.CreateParameterRequest{
+					Name:              parameterSchema.Name,
+					SourceValue:       value,
+					SourceScheme:      codersdk.ParameterSourceSchemeData,
+					DestinationScheme: parameterSchema.DefaultDestinationScheme,
+				})
+				continue
+			}
+		}
 		if !disclaimerPrinted {
 			_, _ = fmt.Fprintln(cmd.OutOrStdout(), cliui.Styles.Paragraph.Render("This template has customizable parameters. Values can be changed after create, but may have unintended side effects (like data loss).")+"\r\n")
 			disclaimerPrinted = true
@@ -2,12 +2,14 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 
 	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/coderd/autobuild/notify"
 	"github.com/coder/coder/coderd/autobuild/schedule"
 	"github.com/coder/coder/coderd/util/ptr"
 	"github.com/coder/coder/codersdk"
@@ -21,10 +23,18 @@ type workspaceListRow struct {
 	Outdated   bool   `table:"outdated"`
 	StartsAt   string `table:"starts at"`
 	StopsAfter string `table:"stops after"`
+	Dormant    string `table:"dormant"`
 }
 
+// dormantNotifyCountdown is when owners are warned that a workspace will
+// become dormant, or that a dormant workspace will be deleted.
+var dormantNotifyCountdown = []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour}
+
 func workspaceListRowFromWorkspace(now time.Time, usersByID map[uuid.UUID]codersdk.User, workspace codersdk.Workspace) workspaceListRow {
 	status := codersdk.WorkspaceDisplayStatus(workspace.LatestBuild.Job.Status, workspace.LatestBuild.Transition)
+	if status == "Running" && !workspace.Health.Healthy {
+		status = "Degraded"
+	}
 
 	lastBuilt := now.UTC().Sub(workspace.LatestBuild.Job.CreatedAt).Truncate(time.Second)
 	autostartDisplay := "-"
@@ -44,6 +54,14 @@ func workspaceListRowFromWorkspace(now time.Time, usersByID map[uuid.UUID]coders
 		}
 	}
 
+	dormantDisplay := "-"
+	if workspace.DormantAt != nil {
+		dormantDisplay = fmt.Sprintf("since %s", workspace.DormantAt.Local().Format(time.Stamp))
+		if workspace.DeletingAt != nil {
+			dormantDisplay = fmt.Sprintf("%s (deleted %s)", dormantDisplay, relative(workspace.DeletingAt.Sub(now)))
+		}
+	}
+
 	user := usersByID[workspace.OwnerID]
 	return workspaceListRow{
 		Workspace:  user.Username + "/" + workspace.Name,
@@ -53,6 +71,44 @@ func workspaceListRowFromWorkspace(now time.Time, usersByID map[uuid.UUID]coders
 		Outdated:   workspace.Outdated,
 		StartsAt:   autostartDisplay,
 		StopsAfter: autostopDisplay,
+		Dormant:    dormantDisplay,
+	}
+}
+
+// warnDormantWorkspaces warns about workspaces that are about to become
+// dormant, or be deleted because they are dormant.
+func warnDormantWorkspaces(w io.Writer, workspaces []codersdk.Workspace) {
+	for _, workspace := range workspaces {
+		workspace := workspace
+		var (
+			deadline time.Time
+			message  string
+		)
+		switch {
+		case workspace.DeletingAt != nil:
+			deadline = *workspace.DeletingAt
+			message = "The dormant workspace %s will be deleted %s! Run %s to keep it.\n"
+		case workspace.BecomesDormantAt != nil:
+			deadline = *workspace.BecomesDormantAt
+			message = "The workspace %s will become dormant %s! Run %s to keep using it.\n"
+		default:
+			continue
+		}
+		notifier := notify.New(func(now time.Time) (time.Time, func()) {
+			return deadline, func() {
+				_, _ = fmt.Fprintf(w, "%s "+message,
+					cliui.Styles.Warn.Render("Warning:"),
+					cliui.Styles.Keyword.Render(workspace.Name),
+					relative(deadline.Sub(now)),
+					cliui.Styles.Code.Render("coder start "+workspace.Name),
+				)
+			}
+		}, dormantNotifyCountdown...)
+		// Poll exactly once.
+		ticker := make(chan time.Time)
+		close(ticker)
+		notifier.Poll(ticker)
+		_ = notifier.Close()
 	}
 }
 
@@ -61,6 +117,7 @@ func list() *cobra.Command {
 		columns     []string
 		searchQuery string
 		me          bool
+		dormant     bool
 	)
 	cmd := &cobra.Command{
 		Annotations: workspaceCommand,
@@ -75,6 +132,7 @@ func list() *cobra.Command {
 			}
 			filter := codersdk.WorkspaceFilter{
 				FilterQuery: searchQuery,
+				Dormant:     dormant,
 			}
 			if me {
 				myUser, err := client.User(cmd.Context(), codersdk.Me)
@@ -115,12 +173,17 @@ func list() *cobra.Command {
 			}
 
 			_, err = fmt.Fprintln(cmd.OutOrStdout(), out)
-			return err
+			if err != nil {
+				return err
+			}
+			warnDormantWorkspaces(cmd.ErrOrStderr(), workspaces)
+			return nil
 		},
 	}
 	cmd.Flags().StringArrayVarP(&columns, "column", "c", nil,
 		"Specify a column to filter in the table.")
 	cmd.Flags().StringVar(&searchQuery, "search", "", "Search for a workspace with a query.")
 	cmd.Flags().BoolVar(&me, "me", false, "Only show workspaces owned by the current user.")
+	cmd.Flags().BoolVar(&dormant, "dormant", false, "Include dormant workspaces, which are hidden by default.")
 	return cmd
 }
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+type timingsTableRow struct {
+	Stage    string `table:"stage"`
+	Resource string `table:"resource"`
+	Action   string `table:"action"`
+	Duration string `table:"duration"`
+}
+
+func logs() *cobra.Command {
+	var (
+		buildName string
+		columns   []string
+	)
+	cmd := &cobra.Command{
+		Use:   "logs <workspace>",
+		Short: "Show the logs of a workspace build, and how long each stage took",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			workspace, err := namedWorkspace(cmd, client, args[0])
+			if err != nil {
+				return err
+			}
+			build := workspace.LatestBuild
+			if buildName != "latest" {
+				build, err = client.WorkspaceBuildByName(cmd.Context(), workspace.ID, buildName)
+				if err != nil {
+					return xerrors.Errorf("get workspace build: %w", err)
+				}
+			}
+
+			buildLogs, err := client.WorkspaceBuildLogsBefore(cmd.Context(), build.ID, time.Time{})
+			if err != nil {
+				return xerrors.Errorf("get workspace build logs: %w", err)
+			}
+			timings, err := client.WorkspaceBuildTimings(cmd.Context(), build.ID)
+			if err != nil {
+				return xerrors.Errorf("get workspace build timings: %w", err)
+			}
+
+			out := cmd.OutOrStdout()
+			sort.SliceStable(buildLogs, func(i, j int) bool {
+				return buildLogs[i].CreatedAt.Before(buildLogs[j].CreatedAt)
+			})
+			stage := ""
+			for _, log := range buildLogs {
+				if log.Stage != stage && log.Stage != "" {
+					stage = log.Stage
+					_, _ = fmt.Fprintln(out, cliui.Styles.Field.Render(stage))
+				}
+				_, _ = fmt.Fprintf(out, "  %s\n", log.Output)
+			}
+			if len(timings) == 0 {
+				return nil
+			}
+
+			_, _ = fmt.Fprintf(out, "\nTimings of build %s:\n", cliui.Styles.Keyword.Render(build.Name))
+			rows := make([]timingsTableRow, 0, len(timings))
+			for _, timing := range sortTimings(timings) {
+				rows = append(rows, timingsTableRow{
+					Stage:    timing.Stage,
+					Resource: timing.Resource,
+					Action:   timing.Action,
+					Duration: timing.EndedAt.Sub(timing.StartedAt).Round(time.Millisecond).String(),
+				})
+			}
+			table, err := cliui.DisplayTable(rows, "", columns)
+			if err != nil {
+				return xerrors.Errorf("render table: %w", err)
+			}
+			_, err = fmt.Fprintln(out, table)
+			return err
+		},
+	}
+	cmd.Flags().StringVarP(&buildName, "build", "b", "latest", "Specify a workspace build to target by name.")
+	cmd.Flags().StringArrayVarP(&columns, "column", "c", nil, "Specify a column to filter in the timings table.")
+	return cmd
+}
+
+// sortTimings orders stages by when they started. The resources of a stage
+// follow it, slowest first, so the resources that held up a build stand out.
+func sortTimings(timings []codersdk.ProvisionerTiming) []codersdk.ProvisionerTiming {
+	stageStartedAt := map[string]time.Time{}
+	for _, timing := range timings {
+		startedAt, ok := stageStartedAt[timing.Stage]
+		if !ok || timing.StartedAt.Before(startedAt) {
+			stageStartedAt[timing.Stage] = timing.StartedAt
+		}
+	}
+	sorted := append([]codersdk.ProvisionerTiming{}, timings...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Stage != b.Stage {
+			return stageStartedAt[a.Stage].Before(stageStartedAt[b.Stage])
+		}
+		if (a.Resource == "") != (b.Resource == "") {
+			return a.Resource == ""
+		}
+		return a.EndedAt.Sub(a.StartedAt) > b.EndedAt.Sub(b.StartedAt)
+	})
+	return sorted
+}
@@ -0,0 +1,65 @@
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+)
+
+func TestLogs(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+	user := coderdtest.CreateFirstUser(t, client)
+	startedAt := time.Now()
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse: echo.ParseComplete,
+		Provision: []*proto.Provision_Response{{
+			Type: &proto.Provision_Response_Log{
+				Log: &proto.Log{
+					Level:  proto.LogLevel_INFO,
+					Output: "creating the container",
+				},
+			},
+		}, {
+			Type: &proto.Provision_Response_Complete{
+				Complete: &proto.Provision_Complete{
+					Timings: []*proto.Timing{{
+						Stage:     "apply",
+						Resource:  "docker_volume.home",
+						Action:    "create",
+						StartedAt: startedAt.UnixMilli(),
+						EndedAt:   startedAt.Add(time.Second).UnixMilli(),
+					}, {
+						Stage:     "apply",
+						Resource:  "docker_container.workspace",
+						Action:    "create",
+						StartedAt: startedAt.UnixMilli(),
+						EndedAt:   startedAt.Add(3 * time.Second).UnixMilli(),
+					}},
+				},
+			},
+		}},
+	})
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	cmd, root := clitest.New(t, "logs", workspace.Name, "--column", "resource", "--column", "duration")
+	clitest.SetupConfig(t, client, root)
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	err := cmd.Execute()
+	require.NoError(t, err)
+	output := buf.String()
+	require.Contains(t, output, "creating the container")
+	// The slowest resource is shown first.
+	require.Regexp(t, `(?s)docker_container\.workspace\s+3s.*docker_volume\.home\s+1s`, output)
+}
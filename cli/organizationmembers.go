@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+)
+
+func organizationMembers() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "members",
+		Short:   "Manage the members of the current organization",
+		Aliases: []string{"member"},
+	}
+	cmd.AddCommand(
+		organizationMemberList(),
+		organizationMemberAdd(),
+		organizationMemberRemove(),
+	)
+	return cmd
+}
+
+func organizationMemberList() *cobra.Command {
+	var (
+		columns      []string
+		outputFormat string
+	)
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the members of the current organization",
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			organization, err := currentOrganization(cmd, client)
+			if err != nil {
+				return err
+			}
+			members, err := client.OrganizationMembers(cmd.Context(), organization.ID)
+			if err != nil {
+				return err
+			}
+
+			out := ""
+			switch outputFormat {
+			case "table", "":
+				out, err = cliui.DisplayTable(members, "Username", columns)
+				if err != nil {
+					return xerrors.Errorf("render table: %w", err)
+				}
+			case "json":
+				outBytes, err := json.Marshal(members)
+				if err != nil {
+					return xerrors.Errorf("marshal members to JSON: %w", err)
+				}
+				out = string(outBytes)
+			default:
+				return xerrors.Errorf(`unknown output format %q, only "table" and "json" are supported`, outputFormat)
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), out)
+			return err
+		},
+	}
+	cmd.Flags().StringArrayVarP(&columns, "column", "c", []string{"username", "email", "created_at"},
+		"Specify a column to filter in the table. Available columns are: user_id, username, email, created_at.")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "table", "Output format. Available formats are: table, json.")
+	return cmd
+}
+
+func organizationMemberAdd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <username|user_id>",
+		Short: "Add a user to the current organization",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			organization, err := currentOrganization(cmd, client)
+			if err != nil {
+				return err
+			}
+			_, err = client.AddOrganizationMember(cmd.Context(), organization.ID, args[0])
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "User %s has been added to %s!\n", cliui.Styles.Keyword.Render(args[0]), cliui.Styles.Keyword.Render(organization.Name))
+			return nil
+		},
+	}
+	return cmd
+}
+
+func organizationMemberRemove() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "remove <username|user_id>",
+		Short:   "Remove a user from the current organization",
+		Aliases: []string{"rm"},
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			organization, err := currentOrganization(cmd, client)
+			if err != nil {
+				return err
+			}
+			err = client.RemoveOrganizationMember(cmd.Context(), organization.ID, args[0])
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "User %s has been removed from %s!\n", cliui.Styles.Keyword.Render(args[0]), cliui.Styles.Keyword.Render(organization.Name))
+			return nil
+		},
+	}
+	return cmd
+}
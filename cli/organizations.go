@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func organizations() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "organizations",
+		Short:   "Manage organizations and their members",
+		Aliases: []string{"organization", "org", "orgs"},
+		Example: formatExamples(
+			example{
+				Description: "Create an organization and make it the default for future commands",
+				Command:     "coder organizations create engineering\ncoder organizations switch engineering",
+			},
+			example{
+				Description: "Run a single command against another organization",
+				Command:     "coder templates list --org engineering",
+			},
+		),
+	}
+	cmd.AddCommand(
+		organizationList(),
+		organizationCreate(),
+		organizationRename(),
+		organizationDelete(),
+		organizationSwitch(),
+		organizationMembers(),
+	)
+	return cmd
+}
+
+// namedOrganization fetches an organization by name or ID. Site-wide owners
+// can fetch organizations they are not a member of.
+func namedOrganization(cmd *cobra.Command, client *codersdk.Client, identifier string) (codersdk.Organization, error) {
+	orgs, err := client.Organizations(cmd.Context())
+	if err != nil {
+		return codersdk.Organization{}, xerrors.Errorf("get organizations: %w", err)
+	}
+	for _, org := range orgs {
+		if org.Name == identifier || org.ID.String() == identifier {
+			return org, nil
+		}
+	}
+	return codersdk.Organization{}, xerrors.Errorf("organization %q does not exist or you do not have access to it", identifier)
+}
+
+func organizationList() *cobra.Command {
+	var (
+		columns      []string
+		outputFormat string
+	)
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List all organizations you can access",
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			orgs, err := client.Organizations(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			out := ""
+			switch outputFormat {
+			case "table", "":
+				out, err = cliui.DisplayTable(orgs, "Name", columns)
+				if err != nil {
+					return xerrors.Errorf("render table: %w", err)
+				}
+			case "json":
+				outBytes, err := json.Marshal(orgs)
+				if err != nil {
+					return xerrors.Errorf("marshal organizations to JSON: %w", err)
+				}
+				out = string(outBytes)
+			default:
+				return xerrors.Errorf(`unknown output format %q, only "table" and "json" are supported`, outputFormat)
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), out)
+			return err
+		},
+	}
+	cmd.Flags().StringArrayVarP(&columns, "column", "c", []string{"name", "id", "created_at"},
+		"Specify a column to filter in the table. Available columns are: id, name, created_at, updated_at.")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "table", "Output format. Available formats are: table, json.")
+	return cmd
+}
+
+func organizationCreate() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create an organization",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			org, err := client.CreateOrganization(cmd.Context(), codersdk.CreateOrganizationRequest{
+				Name: args[0],
+			})
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Organization %s has been created!\n", cliui.Styles.Keyword.Render(org.Name))
+			return nil
+		},
+	}
+	return cmd
+}
+
+func organizationRename() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename <new-name>",
+		Short: "Rename the current organization",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			organization, err := currentOrganization(cmd, client)
+			if err != nil {
+				return err
+			}
+			org, err := client.UpdateOrganization(cmd.Context(), organization.ID, codersdk.UpdateOrganizationRequest{
+				Name: args[0],
+			})
+			if err != nil {
+				return err
+			}
+
+			// Keep the persisted selection pointing at the renamed organization.
+			config := createConfig(cmd)
+			selected, _ := config.Organization().Read()
+			if strings.TrimSpace(selected) == organization.Name {
+				err = config.Organization().Write(org.Name)
+				if err != nil {
+					return xerrors.Errorf("write organization: %w", err)
+				}
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Organization %s has been renamed to %s!\n", cliui.Styles.Keyword.Render(organization.Name), cliui.Styles.Keyword.Render(org.Name))
+			return nil
+		},
+	}
+	return cmd
+}
+
+func organizationDelete() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete an organization. All templates must be deleted or moved first.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			org, err := namedOrganization(cmd, client, args[0])
+			if err != nil {
+				return err
+			}
+
+			_, err = cliui.Prompt(cmd, cliui.PromptOptions{
+				Text:      fmt.Sprintf("Delete organization %s?", cliui.Styles.Code.Render(org.Name)),
+				IsConfirm: true,
+				Default:   cliui.ConfirmNo,
+			})
+			if err != nil {
+				return err
+			}
+
+			err = client.DeleteOrganization(cmd.Context(), org.ID)
+			if err != nil {
+				return xerrors.Errorf("delete organization %q: %w", org.Name, err)
+			}
+
+			config := createConfig(cmd)
+			selected, _ := config.Organization().Read()
+			if strings.TrimSpace(selected) == org.Name {
+				_ = config.Organization().Delete()
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Organization %s has been deleted!\n", cliui.Styles.Keyword.Render(org.Name))
+			return nil
+		},
+	}
+	cliui.AllowSkipPrompt(cmd)
+	return cmd
+}
+
+func organizationSwitch() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "switch <name>",
+		Short: "Set the organization used by commands that omit --org",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			org, err := namedOrganization(cmd, client, args[0])
+			if err != nil {
+				return err
+			}
+			err = createConfig(cmd).Organization().Write(org.Name)
+			if err != nil {
+				return xerrors.Errorf("write organization: %w", err)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Switched to organization %s!\n", cliui.Styles.Keyword.Render(org.Name))
+			return nil
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,141 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOrganizations(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CreateAndList", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		cmd, root := clitest.New(t, "organizations", "create", "engineering")
+		clitest.SetupConfig(t, client, root)
+		require.NoError(t, cmd.Execute())
+
+		cmd, root = clitest.New(t, "organizations", "list")
+		clitest.SetupConfig(t, client, root)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		require.NoError(t, cmd.Execute())
+		require.Contains(t, buf.String(), "engineering")
+	})
+
+	t.Run("Switch", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "engineering",
+		})
+		require.NoError(t, err)
+
+		cmd, root := clitest.New(t, "organizations", "switch", org.Name)
+		clitest.SetupConfig(t, client, root)
+		require.NoError(t, cmd.Execute())
+
+		// The template lives in the first organization, so it is
+		// hidden after switching.
+		cmd, _ = clitest.New(t, "templates", "list", "--global-config", string(root))
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		require.NoError(t, cmd.Execute())
+		require.NotContains(t, buf.String(), template.Name)
+
+		// --org takes precedence over the persisted organization.
+		first, err := client.Organization(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		cmd, _ = clitest.New(t, "templates", "list", "--global-config", string(root), "--org", first.Name)
+		buf = new(bytes.Buffer)
+		cmd.SetOut(buf)
+		require.NoError(t, cmd.Execute())
+		require.Contains(t, buf.String(), template.Name)
+	})
+
+	t.Run("Members", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		other := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		otherUser, err := other.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "engineering",
+		})
+		require.NoError(t, err)
+
+		cmd, root := clitest.New(t, "organizations", "members", "add", otherUser.Username, "--org", org.Name)
+		clitest.SetupConfig(t, client, root)
+		require.NoError(t, cmd.Execute())
+
+		members, err := client.OrganizationMembers(ctx, org.ID)
+		require.NoError(t, err)
+		require.Len(t, members, 2)
+
+		cmd, root = clitest.New(t, "organizations", "members", "remove", otherUser.Username, "--org", org.Name)
+		clitest.SetupConfig(t, client, root)
+		require.NoError(t, cmd.Execute())
+
+		members, err = client.OrganizationMembers(ctx, org.ID)
+		require.NoError(t, err)
+		require.Len(t, members, 1)
+	})
+
+	t.Run("MoveTemplateAndDelete", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "engineering",
+		})
+		require.NoError(t, err)
+		version := coderdtest.CreateTemplateVersion(t, client, org.ID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, org.ID, version.ID)
+
+		cmd, root := clitest.New(t, "organizations", "delete", org.Name, "--yes")
+		clitest.SetupConfig(t, client, root)
+		require.Error(t, cmd.Execute())
+
+		firstOrg, err := client.Organization(ctx, user.OrganizationID)
+		require.NoError(t, err)
+
+		cmd, root = clitest.New(t, "templates", "move", template.Name, firstOrg.Name, "--org", org.Name, "--yes")
+		clitest.SetupConfig(t, client, root)
+		require.NoError(t, cmd.Execute())
+
+		cmd, root = clitest.New(t, "organizations", "delete", org.Name, "--yes")
+		clitest.SetupConfig(t, client, root)
+		require.NoError(t, cmd.Execute())
+
+		_, err = client.Organization(ctx, org.ID)
+		require.Error(t, err)
+	})
+}
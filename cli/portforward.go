@@ -11,9 +11,12 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/pion/udp"
 	"github.com/spf13/cobra"
+	gossh "golang.org/x/crypto/ssh"
 	"golang.org/x/xerrors"
 
 	coderagent "github.com/coder/coder/agent"
@@ -26,6 +29,8 @@ func portForward() *cobra.Command {
 		tcpForwards  []string // <port>:<port>
 		udpForwards  []string // <port>:<port>
 		unixForwards []string // <path>:<path> OR <port>:<path>
+		remoteTCP    []string // <port>:<port>
+		auto         bool
 	)
 	cmd := &cobra.Command{
 		Use:     "port-forward <workspace>",
@@ -53,16 +58,24 @@ func portForward() *cobra.Command {
 				Description: "Port forward multiple TCP ports and a UDP port",
 				Command:     "coder port-forward <workspace> --tcp 8080:8080 --tcp 9000:3000 --udp 5353:53",
 			},
+			example{
+				Description: "Expose port 5432 on your local machine as port 5432 in the workspace",
+				Command:     "coder port-forward <workspace> --remote-tcp 5432",
+			},
+			example{
+				Description: "Forward every TCP port that processes in the workspace listen on to the same port on your local machine",
+				Command:     "coder port-forward <workspace> --auto",
+			},
 		),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx, cancel := context.WithCancel(cmd.Context())
 			defer cancel()
 
-			specs, err := parsePortForwards(tcpForwards, udpForwards, unixForwards)
+			specs, err := parsePortForwards(tcpForwards, udpForwards, unixForwards, remoteTCP)
 			if err != nil {
 				return xerrors.Errorf("parse port-forward specs: %w", err)
 			}
-			if len(specs) == 0 {
+			if len(specs) == 0 && !auto {
 				err = cmd.Help()
 				if err != nil {
 					return xerrors.Errorf("generate help output: %w", err)
@@ -108,24 +121,53 @@ func portForward() *cobra.Command {
 			// Start all listeners.
 			var (
 				wg                = new(sync.WaitGroup)
-				listeners         = make([]net.Listener, len(specs))
+				listenersMutex    sync.Mutex
+				listeners         = make([]net.Listener, 0, len(specs))
 				closeAllListeners = func() {
+					listenersMutex.Lock()
+					defer listenersMutex.Unlock()
 					for _, l := range listeners {
-						if l == nil {
-							continue
-						}
 						_ = l.Close()
 					}
 				}
 			)
 			defer closeAllListeners()
 
-			for i, spec := range specs {
-				l, err := listenAndPortForward(ctx, cmd, conn, wg, spec)
+			var sshClient *gossh.Client
+			for _, spec := range specs {
+				var l net.Listener
+				if spec.reverse {
+					if sshClient == nil {
+						sshClient, err = conn.SSHClient()
+						if err != nil {
+							return xerrors.Errorf("ssh: %w", err)
+						}
+						defer sshClient.Close()
+					}
+					l, err = listenAndReversePortForward(ctx, cmd, sshClient, wg, spec)
+				} else {
+					l, err = listenAndPortForward(ctx, cmd, conn, wg, spec)
+				}
 				if err != nil {
 					return err
 				}
-				listeners[i] = l
+				listeners = append(listeners, l)
+			}
+
+			if auto {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					autoPortForward(ctx, cmd, client, conn, agent.ID, wg, specs, func(l net.Listener) {
+						listenersMutex.Lock()
+						defer listenersMutex.Unlock()
+						listeners = append(listeners, l)
+						if ctx.Err() != nil {
+							// The listeners were closed already.
+							_ = l.Close()
+						}
+					})
+				}()
 			}
 
 			// Wait for the context to be canceled or for a signal and close
@@ -159,6 +201,8 @@ func portForward() *cobra.Command {
 	cmd.Flags().StringArrayVarP(&tcpForwards, "tcp", "p", []string{}, "Forward a TCP port from the workspace to the local machine")
 	cmd.Flags().StringArrayVar(&udpForwards, "udp", []string{}, "Forward a UDP port from the workspace to the local machine. The UDP connection has TCP-like semantics to support stateful UDP protocols")
 	cmd.Flags().StringArrayVar(&unixForwards, "unix", []string{}, "Forward a Unix socket in the workspace to a local Unix socket or TCP port")
+	cmd.Flags().StringArrayVarP(&remoteTCP, "remote-tcp", "R", []string{}, "Forward a TCP port from the local machine to the workspace, formatted as <workspace port>:<local port>")
+	cmd.Flags().BoolVar(&auto, "auto", false, "Forward TCP ports to the same local port as processes in the workspace start listening on them")
 
 	return cmd
 }
@@ -227,15 +271,113 @@ func listenAndPortForward(ctx context.Context, cmd *cobra.Command, conn *coderag
 	return l, nil
 }
 
+// listenAndReversePortForward listens in the workspace with the "tcpip-forward"
+// request of the agent SSH server, and forwards connections to the local
+// machine.
+func listenAndReversePortForward(ctx context.Context, cmd *cobra.Command, sshClient *gossh.Client, wg *sync.WaitGroup, spec portForwardSpec) (net.Listener, error) {
+	_, _ = fmt.Fprintf(cmd.OutOrStderr(), "Forwarding '%v://%v' in the workspace to '%v://%v' locally\n", spec.listenNetwork, spec.listenAddress, spec.dialNetwork, spec.dialAddress)
+
+	l, err := sshClient.Listen(spec.listenNetwork, spec.listenAddress)
+	if err != nil {
+		return nil, xerrors.Errorf("listen '%v://%v' in the workspace: %w", spec.listenNetwork, spec.listenAddress, err)
+	}
+
+	wg.Add(1)
+	go func(spec portForwardSpec) {
+		defer wg.Done()
+		for {
+			remoteConn, err := l.Accept()
+			if err != nil {
+				if ctx.Err() == nil {
+					_, _ = fmt.Fprintf(cmd.OutOrStderr(), "Error accepting connection from '%v://%v' in the workspace: %+v\n", spec.listenNetwork, spec.listenAddress, err)
+					_, _ = fmt.Fprintln(cmd.OutOrStderr(), "Killing listener")
+				}
+				return
+			}
+
+			go func(remoteConn net.Conn) {
+				defer remoteConn.Close()
+				var d net.Dialer
+				netConn, err := d.DialContext(ctx, spec.dialNetwork, spec.dialAddress)
+				if err != nil {
+					_, _ = fmt.Fprintf(cmd.OutOrStderr(), "Failed to dial '%v://%v' locally: %s\n", spec.dialNetwork, spec.dialAddress, err)
+					return
+				}
+				defer netConn.Close()
+
+				coderagent.Bicopy(ctx, remoteConn, netConn)
+			}(remoteConn)
+		}
+	}(spec)
+
+	return l, nil
+}
+
+// autoForwardInterval is how often the listening ports of the workspace are
+// checked by "port-forward --auto".
+var autoForwardInterval = 2 * time.Second
+
+// autoPortForward forwards the listening TCP ports of the workspace to the
+// same local ports until the context is canceled. Ports that are already
+// forwarded by specs, or that can't be listened on locally, are skipped.
+func autoPortForward(ctx context.Context, cmd *cobra.Command, client *codersdk.Client, conn *coderagent.Conn, agentID uuid.UUID, wg *sync.WaitGroup, specs []portForwardSpec, addListener func(net.Listener)) {
+	forwarded := map[string]struct{}{}
+	for _, spec := range specs {
+		forwarded[spec.dialNetwork+"://"+spec.dialAddress] = struct{}{}
+	}
+
+	ticker := time.NewTicker(autoForwardInterval)
+	defer ticker.Stop()
+	for {
+		ports, err := client.WorkspaceAgentListeningPorts(ctx, agentID)
+		if err != nil && ctx.Err() == nil {
+			_, _ = fmt.Fprintf(cmd.OutOrStderr(), "Failed to get listening ports: %s\n", err)
+		}
+		for _, port := range ports {
+			spec := portForwardSpec{
+				listenNetwork: "tcp",
+				listenAddress: fmt.Sprintf("127.0.0.1:%d", port.Port),
+				dialNetwork:   "tcp",
+				dialAddress:   fmt.Sprintf("127.0.0.1:%d", port.Port),
+			}
+			key := spec.dialNetwork + "://" + spec.dialAddress
+			if _, ok := forwarded[key]; ok {
+				continue
+			}
+			// Ports are only tried once, so failures aren't repeated
+			// on every tick.
+			forwarded[key] = struct{}{}
+			if port.ProcessName != "" {
+				_, _ = fmt.Fprintf(cmd.OutOrStderr(), "Detected port %d of %s\n", port.Port, port.ProcessName)
+			}
+			l, err := listenAndPortForward(ctx, cmd, conn, wg, spec)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.OutOrStderr(), "Skipping port %d: %s\n", port.Port, err)
+				continue
+			}
+			addListener(l)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 type portForwardSpec struct {
 	listenNetwork string // tcp, udp, unix
 	listenAddress string // <ip>:<port> or path
 
 	dialNetwork string // tcp, udp, unix
 	dialAddress string // <ip>:<port> or path
+
+	// reverse specs listen in the workspace and dial the local machine.
+	reverse bool
 }
 
-func parsePortForwards(tcpSpecs, udpSpecs, unixSpecs []string) ([]portForwardSpec, error) {
+func parsePortForwards(tcpSpecs, udpSpecs, unixSpecs, remoteTCPSpecs []string) ([]portForwardSpec, error) {
 	specs := []portForwardSpec{}
 
 	for _, spec := range tcpSpecs {
@@ -289,12 +431,31 @@ func parsePortForwards(tcpSpecs, udpSpecs, unixSpecs []string) ([]portForwardSpe
 		specs = append(specs, spec)
 	}
 
+	for _, spec := range remoteTCPSpecs {
+		remote, local, err := parsePortPort(spec)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to parse remote TCP port-forward specification %q: %w", spec, err)
+		}
+
+		specs = append(specs, portForwardSpec{
+			listenNetwork: "tcp",
+			listenAddress: fmt.Sprintf("127.0.0.1:%v", remote),
+			dialNetwork:   "tcp",
+			dialAddress:   fmt.Sprintf("127.0.0.1:%v", local),
+			reverse:       true,
+		})
+	}
+
 	// Check for duplicate entries.
 	locals := map[string]struct{}{}
 	for _, spec := range specs {
-		localStr := fmt.Sprintf("%v:%v", spec.listenNetwork, spec.listenAddress)
+		side := "local"
+		if spec.reverse {
+			side = "workspace"
+		}
+		localStr := fmt.Sprintf("%v:%v:%v", side, spec.listenNetwork, spec.listenAddress)
 		if _, ok := locals[localStr]; ok {
-			return nil, xerrors.Errorf("local %v %v is specified twice", spec.listenNetwork, spec.listenAddress)
+			return nil, xerrors.Errorf("%v %v %v is specified twice", side, spec.listenNetwork, spec.listenAddress)
 		}
 		locals[localStr] = struct{}{}
 	}
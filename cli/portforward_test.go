@@ -285,6 +285,79 @@ func TestPortForward(t *testing.T) {
 		require.ErrorIs(t, err, context.Canceled)
 	})
 
+	t.Run("Auto", func(t *testing.T) {
+		t.Parallel()
+		if runtime.GOOS != "linux" {
+			t.Skip("Listening ports are only scanned on Linux.")
+		}
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		port := setupTestListener(t, l)
+
+		cmd, root := clitest.New(t, "port-forward", workspace.Name, "--auto")
+		clitest.SetupConfig(t, client, root)
+		buf := newThreadSafeBuffer()
+		cmd.SetOut(buf)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		errC := make(chan error)
+		go func() {
+			errC <- cmd.ExecuteContext(ctx)
+		}()
+		waitForPortForwardReady(t, buf)
+
+		// The agent runs on this machine, so the port is detected but
+		// can't be listened on locally.
+		require.Eventually(t, func() bool {
+			return strings.Contains(buf.String(), fmt.Sprintf("Skipping port %s", port))
+		}, testutil.WaitLong, testutil.IntervalFast)
+
+		cancel()
+		err = <-errC
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	//nolint:paralleltest
+	t.Run("RemoteTCP", func(t *testing.T) {
+		var (
+			tcpCase = cases[0]
+			// The "local" service that is exposed in the workspace.
+			p1 = setupTestListener(t, tcpCase.setupRemote(t))
+			// The agent runs on this machine, so the workspace port is
+			// reserved the same way as local ports.
+			remoteAddress, remotePort = tcpCase.setupLocal(t)
+		)
+
+		cmd, root := clitest.New(t, "port-forward", workspace.Name, fmt.Sprintf("--remote-tcp=%v:%v", remotePort, p1))
+		clitest.SetupConfig(t, client, root)
+		buf := newThreadSafeBuffer()
+		cmd.SetOut(buf)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		errC := make(chan error)
+		go func() {
+			errC <- cmd.ExecuteContext(ctx)
+		}()
+		waitForPortForwardReady(t, buf)
+
+		t.Parallel() // Port is reserved, enable parallel execution.
+
+		d := net.Dialer{Timeout: testutil.WaitShort}
+		c1, err := d.DialContext(ctx, tcpCase.network, remoteAddress)
+		require.NoError(t, err, "open connection 1 to listener in the workspace")
+		defer c1.Close()
+		c2, err := d.DialContext(ctx, tcpCase.network, remoteAddress)
+		require.NoError(t, err, "open connection 2 to listener in the workspace")
+		defer c2.Close()
+		testDial(t, c2)
+		testDial(t, c1)
+
+		cancel()
+		err = <-errC
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
 	// Test doing TCP, UDP and Unix at the same time.
 	//nolint:paralleltest
 	t.Run("All", func(t *testing.T) {
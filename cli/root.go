@@ -44,6 +44,7 @@ const (
 	varNoVersionCheck  = "no-version-warning"
 	varForceTty        = "force-tty"
 	varVerbose         = "verbose"
+	varOrganization    = "org"
 	notLoggedInMessage = "You are not logged in. Try logging in using 'coder login <url>'."
 
 	envNoVersionCheck = "CODER_NO_VERSION_WARNING"
@@ -62,18 +63,22 @@ func init() {
 func Core() []*cobra.Command {
 	return []*cobra.Command{
 		configSSH(),
+		cp(),
 		create(),
 		deleteWorkspace(),
 		dotfiles(),
 		gitssh(),
 		list(),
 		login(),
+		logs(),
 		logout(),
+		organizations(),
 		parameters(),
 		portForward(),
 		publickey(),
 		resetPassword(),
 		schedules(),
+		sessions(),
 		show(),
 		ssh(),
 		start(),
@@ -81,6 +86,7 @@ func Core() []*cobra.Command {
 		stop(),
 		rename(),
 		templates(),
+		terminals(),
 		update(),
 		users(),
 		versionCmd(),
@@ -163,6 +169,7 @@ func Root(subcommands []*cobra.Command) *cobra.Command {
 	cmd.PersistentFlags().Bool(varNoOpen, false, "Block automatically opening URLs in the browser.")
 	_ = cmd.PersistentFlags().MarkHidden(varNoOpen)
 	cliflag.Bool(cmd.PersistentFlags(), varVerbose, "v", "CODER_VERBOSE", false, "Enable verbose output")
+	cliflag.String(cmd.PersistentFlags(), varOrganization, "", "CODER_ORGANIZATION", "", "Select an organization by name or ID. Defaults to the organization set with `coder organizations switch`.")
 
 	return cmd
 }
@@ -247,14 +254,31 @@ func createAgentClient(cmd *cobra.Command) (*codersdk.Client, error) {
 }
 
 // currentOrganization returns the currently active organization for the authenticated user.
+// The organization is selected with the --org flag, falling back to the one persisted by
+// "coder organizations switch", and finally to the first organization the user belongs to.
 func currentOrganization(cmd *cobra.Command, client *codersdk.Client) (codersdk.Organization, error) {
-	orgs, err := client.OrganizationsByUser(cmd.Context(), codersdk.Me)
+	selected, err := cmd.Flags().GetString(varOrganization)
 	if err != nil {
-		return codersdk.Organization{}, nil
+		return codersdk.Organization{}, err
 	}
-	// For now, we won't use the config to set this.
-	// Eventually, we will support changing using "coder switch <org>"
-	return orgs[0], nil
+	if selected == "" {
+		// The file does not exist until an organization is switched to.
+		selected, _ = createConfig(cmd).Organization().Read()
+		selected = strings.TrimSpace(selected)
+	}
+
+	if selected == "" {
+		orgs, err := client.OrganizationsByUser(cmd.Context(), codersdk.Me)
+		if err != nil {
+			return codersdk.Organization{}, xerrors.Errorf("get organizations: %w", err)
+		}
+		if len(orgs) == 0 {
+			return codersdk.Organization{}, xerrors.New("you are not a member of any organizations")
+		}
+		return orgs[0], nil
+	}
+
+	return namedOrganization(cmd, client, selected)
 }
 
 // namedWorkspace fetches and returns a workspace by an identifier, which may be either
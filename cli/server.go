@@ -41,6 +41,7 @@ import (
 	"golang.org/x/xerrors"
 	"google.golang.org/api/idtoken"
 	"google.golang.org/api/option"
+	"gopkg.in/yaml.v3"
 
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/sloghuman"
@@ -50,12 +51,16 @@ import (
 	"github.com/coder/coder/cli/config"
 	"github.com/coder/coder/coderd"
 	"github.com/coder/coder/coderd/autobuild/executor"
+	"github.com/coder/coder/coderd/blobstore"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/databasefake"
 	"github.com/coder/coder/coderd/devtunnel"
 	"github.com/coder/coder/coderd/gitsshkey"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/insights"
 	"github.com/coder/coder/coderd/prometheusmetrics"
 	"github.com/coder/coder/coderd/telemetry"
+	"github.com/coder/coder/coderd/templatesync"
 	"github.com/coder/coder/coderd/tracing"
 	"github.com/coder/coder/coderd/turnconn"
 	"github.com/coder/coder/codersdk"
@@ -79,9 +84,21 @@ func Server(newAPI func(*coderd.Options) *coderd.API) *cobra.Command {
 		pprofAddress          string
 		cacheDir              string
 		inMemoryDatabase      bool
+		fileGCInterval        time.Duration
+		templateSyncInterval  time.Duration
+		insightsInterval      time.Duration
 		// provisionerDaemonCount is a uint8 to ensure a number > 0.
 		provisionerDaemonCount           uint8
+		provisionerOffline               bool
 		postgresURL                      string
+		blobStore                        string
+		blobStoreDirectory               string
+		blobStoreS3Endpoint              string
+		blobStoreS3Region                string
+		blobStoreS3Bucket                string
+		blobStoreS3Prefix                string
+		blobStoreS3AccessKeyID           string
+		blobStoreS3SecretAccessKey       string
 		oauth2GithubClientID             string
 		oauth2GithubClientSecret         string
 		oauth2GithubAllowedOrganizations []string
@@ -94,6 +111,9 @@ func Server(newAPI func(*coderd.Options) *coderd.API) *cobra.Command {
 		oidcEmailDomain                  string
 		oidcIssuerURL                    string
 		oidcScopes                       []string
+		oidcRoleField                    string
+		oidcRoleMapping                  []string
+		oidcProvidersFile                string
 		telemetryEnable                  bool
 		telemetryURL                     string
 		tlsCertFile                      string
@@ -346,6 +366,17 @@ func Server(newAPI func(*coderd.Options) *coderd.API) *cobra.Command {
 				if err != nil {
 					return xerrors.Errorf("parse oidc oauth callback url: %w", err)
 				}
+				var roleSync *coderd.OIDCRoleSync
+				if oidcRoleField != "" {
+					roleMapping, err := parseOIDCRoleMapping(oidcRoleMapping)
+					if err != nil {
+						return xerrors.Errorf("parse oidc role mapping: %w", err)
+					}
+					roleSync = &coderd.OIDCRoleSync{
+						Field:   oidcRoleField,
+						Mapping: roleMapping,
+					}
+				}
 				options.OIDCConfig = &coderd.OIDCConfig{
 					OAuth2Config: &oauth2.Config{
 						ClientID:     oidcClientID,
@@ -357,8 +388,21 @@ func Server(newAPI func(*coderd.Options) *coderd.API) *cobra.Command {
 					Verifier: oidcProvider.Verifier(&oidc.Config{
 						ClientID: oidcClientID,
 					}),
+					IssuerURL:    oidcIssuerURL,
 					EmailDomain:  oidcEmailDomain,
 					AllowSignups: oidcAllowSignups,
+					RoleSync:     roleSync,
+				}
+			}
+
+			if oidcProvidersFile != "" {
+				var defaultIssuerURL string
+				if options.OIDCConfig != nil {
+					defaultIssuerURL = oidcIssuerURL
+				}
+				options.OIDCProviders, err = configureOIDCProviders(ctx, accessURLParsed, oidcProvidersFile, defaultIssuerURL)
+				if err != nil {
+					return xerrors.Errorf("configure oidc providers: %w", err)
 				}
 			}
 
@@ -388,6 +432,17 @@ func Server(newAPI func(*coderd.Options) *coderd.API) *cobra.Command {
 				defer options.Pubsub.Close()
 			}
 
+			options.BlobStore, err = configureBlobStore(blobStore, blobStoreDirectory, blobStoreS3Endpoint, blobstore.S3Options{
+				Region:          blobStoreS3Region,
+				Bucket:          blobStoreS3Bucket,
+				Prefix:          blobStoreS3Prefix,
+				AccessKeyID:     blobStoreS3AccessKeyID,
+				SecretAccessKey: blobStoreS3SecretAccessKey,
+			})
+			if err != nil {
+				return xerrors.Errorf("configure blob store: %w", err)
+			}
+
 			deploymentID, err := options.Database.GetDeploymentID(ctx)
 			if errors.Is(err, sql.ErrNoRows) {
 				err = nil
@@ -420,7 +475,7 @@ func Server(newAPI func(*coderd.Options) *coderd.API) *cobra.Command {
 					Logger:          logger.Named("telemetry"),
 					URL:             telemetryURL,
 					GitHubOAuth:     oauth2GithubClientID != "",
-					OIDCAuth:        oidcClientID != "",
+					OIDCAuth:        oidcClientID != "" || len(options.OIDCProviders) > 0,
 					OIDCIssuerURL:   oidcIssuerURL,
 					Prometheus:      promEnabled,
 					STUN:            len(stunServers) != 0,
@@ -487,7 +542,7 @@ func Server(newAPI func(*coderd.Options) *coderd.API) *cobra.Command {
 				}
 			}()
 			for i := 0; uint8(i) < provisionerDaemonCount; i++ {
-				daemon, err := newProvisionerDaemon(ctx, coderAPI, logger, cacheDir, errCh, false)
+				daemon, err := newProvisionerDaemon(ctx, coderAPI, logger, cacheDir, provisionerOffline, errCh, false)
 				if err != nil {
 					return xerrors.Errorf("create provisioner daemon: %w", err)
 				}
@@ -554,6 +609,26 @@ func Server(newAPI func(*coderd.Options) *coderd.API) *cobra.Command {
 			autobuildExecutor := executor.New(ctx, options.Database, logger, autobuildPoller.C)
 			autobuildExecutor.Run()
 
+			if fileGCInterval > 0 {
+				fileGCTicker := time.NewTicker(fileGCInterval)
+				defer fileGCTicker.Stop()
+				// Files are kept for a day so an upload isn't collected
+				// before a template version is created from it.
+				blobstore.NewCollector(ctx, options.Database, coderAPI.BlobStore, logger.Named("filegc"), fileGCTicker.C, 24*time.Hour).Run()
+			}
+
+			if templateSyncInterval > 0 {
+				templateSyncTicker := time.NewTicker(templateSyncInterval)
+				defer templateSyncTicker.Stop()
+				templatesync.New(ctx, options.Database, coderAPI.BlobStore, logger.Named("templatesync"), templateSyncTicker.C).Run()
+			}
+
+			if insightsInterval > 0 {
+				insightsTicker := time.NewTicker(insightsInterval)
+				defer insightsTicker.Stop()
+				insights.NewAggregator(ctx, options.Database, logger.Named("insights"), insightsTicker.C).Run()
+			}
+
 			// This is helpful for tests, but can be silently ignored.
 			// Coder may be ran as users that don't have permission to write in the homedir,
 			// such as via the systemd service.
@@ -704,17 +779,36 @@ func Server(newAPI func(*coderd.Options) *coderd.API) *cobra.Command {
 	cliflag.StringVarP(root.Flags(), &promAddress, "prometheus-address", "", "CODER_PROMETHEUS_ADDRESS", "127.0.0.1:2112", "The address to serve prometheus metrics.")
 	cliflag.BoolVarP(root.Flags(), &pprofEnabled, "pprof-enable", "", "CODER_PPROF_ENABLE", false, "Enable serving pprof metrics on the address defined by --pprof-address.")
 	cliflag.StringVarP(root.Flags(), &pprofAddress, "pprof-address", "", "CODER_PPROF_ADDRESS", "127.0.0.1:6060", "The address to serve pprof.")
-	defaultCacheDir := filepath.Join(os.TempDir(), "coder-cache")
-	if dir := os.Getenv("CACHE_DIRECTORY"); dir != "" {
-		// For compatibility with systemd.
-		defaultCacheDir = dir
-	}
-	cliflag.StringVarP(root.Flags(), &cacheDir, "cache-dir", "", "CODER_CACHE_DIRECTORY", defaultCacheDir, "Specifies a directory to cache binaries for provision operations. If unspecified and $CACHE_DIRECTORY is set, it will be used for compatibility with systemd.")
+	cliflag.StringVarP(root.Flags(), &cacheDir, "cache-dir", "", "CODER_CACHE_DIRECTORY", defaultCacheDir(), "Specifies a directory to cache binaries, Terraform providers and modules for provision operations. If unspecified and $CACHE_DIRECTORY is set, it will be used for compatibility with systemd.")
+	cliflag.StringVarP(root.Flags(), &blobStore, "blob-store", "", "CODER_BLOB_STORE", "postgres",
+		`Specifies where the contents of uploaded files such as template archives are stored. Accepted values are "postgres", "filesystem" or "s3". Files stored in postgres before this changed are still read from postgres.`)
+	cliflag.StringVarP(root.Flags(), &blobStoreDirectory, "blob-store-directory", "", "CODER_BLOB_STORE_DIRECTORY", "",
+		"Specifies the directory files are stored in with the filesystem blob store.")
+	cliflag.StringVarP(root.Flags(), &blobStoreS3Endpoint, "blob-store-s3-endpoint", "", "CODER_BLOB_STORE_S3_ENDPOINT", "",
+		"Specifies the URL of an S3-compatible API for the s3 blob store. Defaults to the AWS endpoint of the region.")
+	cliflag.StringVarP(root.Flags(), &blobStoreS3Region, "blob-store-s3-region", "", "CODER_BLOB_STORE_S3_REGION", "us-east-1",
+		"Specifies the region of the bucket for the s3 blob store.")
+	cliflag.StringVarP(root.Flags(), &blobStoreS3Bucket, "blob-store-s3-bucket", "", "CODER_BLOB_STORE_S3_BUCKET", "",
+		"Specifies the bucket files are stored in with the s3 blob store.")
+	cliflag.StringVarP(root.Flags(), &blobStoreS3Prefix, "blob-store-s3-prefix", "", "CODER_BLOB_STORE_S3_PREFIX", "",
+		"Specifies a prefix for the keys of files stored with the s3 blob store.")
+	cliflag.StringVarP(root.Flags(), &blobStoreS3AccessKeyID, "blob-store-s3-access-key-id", "", "CODER_BLOB_STORE_S3_ACCESS_KEY_ID", "",
+		"Specifies the access key ID used to sign requests to the s3 blob store.")
+	cliflag.StringVarP(root.Flags(), &blobStoreS3SecretAccessKey, "blob-store-s3-secret-access-key", "", "CODER_BLOB_STORE_S3_SECRET_ACCESS_KEY", "",
+		"Specifies the secret access key used to sign requests to the s3 blob store.")
+	cliflag.DurationVarP(root.Flags(), &fileGCInterval, "file-gc-interval", "", "CODER_FILE_GC_INTERVAL", time.Hour,
+		"Specifies how often uploaded files that no template version or build uses are deleted. Files are kept for at least a day. Set to 0 to disable.")
+	cliflag.DurationVarP(root.Flags(), &templateSyncInterval, "template-sync-interval", "", "CODER_TEMPLATE_SYNC_INTERVAL", 5*time.Minute,
+		"Specifies how often templates synced from git repositories are fetched for new commits. Set to 0 to disable.")
+	cliflag.DurationVarP(root.Flags(), &insightsInterval, "insights-interval", "", "CODER_INSIGHTS_INTERVAL", 15*time.Minute,
+		"Specifies how often the usage insights of templates are computed. Set to 0 to disable.")
 	cliflag.BoolVarP(root.Flags(), &inMemoryDatabase, "in-memory", "", "CODER_INMEMORY", false,
 		"Specifies whether data will be stored in an in-memory database.")
 	_ = root.Flags().MarkHidden("in-memory")
 	cliflag.StringVarP(root.Flags(), &postgresURL, "postgres-url", "", "CODER_PG_CONNECTION_URL", "", "The URL of a PostgreSQL database to connect to. If empty, PostgreSQL binaries will be downloaded from Maven (https://repo1.maven.org/maven2) and store all data in the config root. Access the built-in database with \"coder server postgres-builtin-url\"")
 	cliflag.Uint8VarP(root.Flags(), &provisionerDaemonCount, "provisioner-daemons", "", "CODER_PROVISIONER_DAEMONS", 3, "The amount of provisioner daemons to create on start.")
+	cliflag.BoolVarP(root.Flags(), &provisionerOffline, "provisioner-offline", "", "CODER_PROVISIONER_OFFLINE", false,
+		`Refuse to download Terraform, providers and modules. They're installed from the cache directory instead, which "coder templates mirror" populates.`)
 	cliflag.StringVarP(root.Flags(), &oauth2GithubClientID, "oauth2-github-client-id", "", "CODER_OAUTH2_GITHUB_CLIENT_ID", "",
 		"Specifies a client ID to use for oauth2 with GitHub.")
 	cliflag.StringVarP(root.Flags(), &oauth2GithubClientSecret, "oauth2-github-client-secret", "", "CODER_OAUTH2_GITHUB_CLIENT_SECRET", "",
@@ -739,6 +833,12 @@ func Server(newAPI func(*coderd.Options) *coderd.API) *cobra.Command {
 		"Specifies an issuer URL to use for OIDC.")
 	cliflag.StringArrayVarP(root.Flags(), &oidcScopes, "oidc-scopes", "", "CODER_OIDC_SCOPES", []string{oidc.ScopeOpenID, "profile", "email"},
 		"Specifies scopes to grant when authenticating with OIDC.")
+	cliflag.StringVarP(root.Flags(), &oidcRoleField, "oidc-role-field", "", "CODER_OIDC_ROLE_FIELD", "",
+		"Specifies the OIDC claim that contains the groups or roles of a user. When set, the roles of a user are synced on every login.")
+	cliflag.StringArrayVarP(root.Flags(), &oidcRoleMapping, "oidc-role-mapping", "", "CODER_OIDC_ROLE_MAPPING", nil,
+		"Specifies the Coder roles granted by a value of the OIDC role claim. Formatted as: <claim-value>=<role>. Organization roles are formatted as <role>:<organization-id>.")
+	cliflag.StringVarP(root.Flags(), &oidcProvidersFile, "oidc-providers-file", "", "CODER_OIDC_PROVIDERS_FILE", "",
+		"Specifies a YAML file containing additional named OIDC providers. Each provider is shown as its own login method.")
 	enableTelemetryByDefault := !isTest()
 	cliflag.BoolVarP(root.Flags(), &telemetryEnable, "telemetry", "", "CODER_TELEMETRY", enableTelemetryByDefault, "Specifies whether telemetry is enabled or not. Coder collects anonymized usage data to help improve our product.")
 	cliflag.StringVarP(root.Flags(), &telemetryURL, "telemetry-url", "", "CODER_TELEMETRY_URL", "https://telemetry.coder.com", "Specifies a URL to send telemetry to.")
@@ -824,6 +924,16 @@ func isLocalURL(ctx context.Context, u *url.URL) (bool, error) {
 	return false, nil
 }
 
+// defaultCacheDir is where provisioners cache binaries, providers and
+// modules unless a cache directory is specified.
+func defaultCacheDir() string {
+	if dir := os.Getenv("CACHE_DIRECTORY"); dir != "" {
+		// For compatibility with systemd.
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "coder-cache")
+}
+
 func shutdownWithTimeout(s interface{ Shutdown(context.Context) error }, timeout time.Duration) error {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
@@ -832,7 +942,7 @@ func shutdownWithTimeout(s interface{ Shutdown(context.Context) error }, timeout
 
 // nolint:revive
 func newProvisionerDaemon(ctx context.Context, coderAPI *coderd.API,
-	logger slog.Logger, cacheDir string, errCh chan error, dev bool,
+	logger slog.Logger, cacheDir string, offline bool, errCh chan error, dev bool,
 ) (srv *provisionerd.Server, err error) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer func() {
@@ -861,6 +971,7 @@ func newProvisionerDaemon(ctx context.Context, coderAPI *coderd.API,
 			},
 			CachePath: cacheDir,
 			Logger:    logger,
+			Offline:   offline,
 		})
 		if err != nil && !xerrors.Is(err, context.Canceled) {
 			select {
@@ -1005,6 +1116,31 @@ func configureTLS(listener net.Listener, tlsMinVersion, tlsClientAuth, tlsCertFi
 	return tls.NewListener(listener, tlsConfig), nil
 }
 
+func configureBlobStore(kind, directory, s3Endpoint string, s3Options blobstore.S3Options) (blobstore.Store, error) {
+	switch kind {
+	case "postgres":
+		// The API defaults to storing files in the database.
+		return nil, nil
+	case "filesystem":
+		if directory == "" {
+			return nil, xerrors.New("a directory must be set with --blob-store-directory")
+		}
+		return blobstore.NewFilesystem(directory)
+	case "s3":
+		if s3Endpoint == "" {
+			s3Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", s3Options.Region)
+		}
+		endpoint, err := url.Parse(s3Endpoint)
+		if err != nil {
+			return nil, xerrors.Errorf("parse s3 endpoint: %w", err)
+		}
+		s3Options.Endpoint = endpoint
+		return blobstore.NewS3(s3Options)
+	default:
+		return nil, xerrors.Errorf("blob store %q is not supported", kind)
+	}
+}
+
 func configureGithubOAuth2(accessURL *url.URL, clientID, clientSecret string, allowSignups bool, allowOrgs []string, rawTeams []string, enterpriseBaseURL string) (*coderd.GithubOAuth2Config, error) {
 	redirectURL, err := accessURL.Parse("/api/v2/users/oauth2/github/callback")
 	if err != nil {
@@ -1103,6 +1239,119 @@ func configureGithubOAuth2(accessURL *url.URL, clientID, clientSecret string, al
 	}, nil
 }
 
+// oidcProviderConfig is a named OIDC provider in the file specified by
+// --oidc-providers-file.
+type oidcProviderConfig struct {
+	Name         string              `yaml:"name"`
+	DisplayName  string              `yaml:"display_name"`
+	IconURL      string              `yaml:"icon_url"`
+	IssuerURL    string              `yaml:"issuer_url"`
+	ClientID     string              `yaml:"client_id"`
+	ClientSecret string              `yaml:"client_secret"`
+	Scopes       []string            `yaml:"scopes"`
+	EmailDomain  string              `yaml:"email_domain"`
+	AllowSignups bool                `yaml:"allow_signups"`
+	RoleField    string              `yaml:"role_field"`
+	RoleMapping  map[string][]string `yaml:"role_mapping"`
+}
+
+// configureOIDCProviders reads the named OIDC providers from a file. Users are
+// identified by the issuer and subject of their tokens, so providers can't
+// share an issuer with each other or with the default provider.
+func configureOIDCProviders(ctx context.Context, accessURL *url.URL, path, defaultIssuerURL string) ([]*coderd.OIDCConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("read file: %w", err)
+	}
+	var providerConfigs []oidcProviderConfig
+	err = yaml.Unmarshal(data, &providerConfigs)
+	if err != nil {
+		return nil, xerrors.Errorf("parse file: %w", err)
+	}
+
+	seen := map[string]struct{}{}
+	issuers := map[string]string{}
+	if defaultIssuerURL != "" {
+		issuers[strings.TrimSuffix(defaultIssuerURL, "/")] = "the default OIDC provider"
+	}
+	for _, providerConfig := range providerConfigs {
+		if !httpapi.UsernameValid(providerConfig.Name) {
+			return nil, xerrors.Errorf("oidc provider name %q is invalid", providerConfig.Name)
+		}
+		if _, ok := seen[providerConfig.Name]; ok {
+			return nil, xerrors.Errorf("oidc provider %q is specified more than once", providerConfig.Name)
+		}
+		seen[providerConfig.Name] = struct{}{}
+		if providerConfig.ClientID == "" || providerConfig.IssuerURL == "" {
+			return nil, xerrors.Errorf("oidc provider %q must specify a client ID and issuer URL", providerConfig.Name)
+		}
+		issuer := strings.TrimSuffix(providerConfig.IssuerURL, "/")
+		if other, ok := issuers[issuer]; ok {
+			return nil, xerrors.Errorf("oidc provider %q has the same issuer URL as %s", providerConfig.Name, other)
+		}
+		issuers[issuer] = fmt.Sprintf("oidc provider %q", providerConfig.Name)
+	}
+
+	providers := make([]*coderd.OIDCConfig, 0, len(providerConfigs))
+	for _, providerConfig := range providerConfigs {
+		if len(providerConfig.Scopes) == 0 {
+			providerConfig.Scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+		}
+		if providerConfig.DisplayName == "" {
+			providerConfig.DisplayName = providerConfig.Name
+		}
+
+		oidcProvider, err := oidc.NewProvider(ctx, providerConfig.IssuerURL)
+		if err != nil {
+			return nil, xerrors.Errorf("configure oidc provider %q: %w", providerConfig.Name, err)
+		}
+		redirectURL, err := accessURL.Parse(fmt.Sprintf("/api/v2/users/oidc/%s/callback", providerConfig.Name))
+		if err != nil {
+			return nil, xerrors.Errorf("parse oidc oauth callback url: %w", err)
+		}
+		var roleSync *coderd.OIDCRoleSync
+		if providerConfig.RoleField != "" {
+			roleSync = &coderd.OIDCRoleSync{
+				Field:   providerConfig.RoleField,
+				Mapping: providerConfig.RoleMapping,
+			}
+		}
+		providers = append(providers, &coderd.OIDCConfig{
+			OAuth2Config: &oauth2.Config{
+				ClientID:     providerConfig.ClientID,
+				ClientSecret: providerConfig.ClientSecret,
+				RedirectURL:  redirectURL.String(),
+				Endpoint:     oidcProvider.Endpoint(),
+				Scopes:       providerConfig.Scopes,
+			},
+			Verifier: oidcProvider.Verifier(&oidc.Config{
+				ClientID: providerConfig.ClientID,
+			}),
+			Name:         providerConfig.Name,
+			DisplayName:  providerConfig.DisplayName,
+			IconURL:      providerConfig.IconURL,
+			IssuerURL:    providerConfig.IssuerURL,
+			EmailDomain:  providerConfig.EmailDomain,
+			AllowSignups: providerConfig.AllowSignups,
+			RoleSync:     roleSync,
+		})
+	}
+	return providers, nil
+}
+
+// parseOIDCRoleMapping parses role mappings formatted as <claim-value>=<role>.
+func parseOIDCRoleMapping(rawMapping []string) (map[string][]string, error) {
+	mapping := map[string][]string{}
+	for _, raw := range rawMapping {
+		parts := strings.SplitN(raw, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, xerrors.Errorf("oidc role mapping is formatted incorrectly. got %s; wanted <claim-value>=<role>", raw)
+		}
+		mapping[parts[0]] = append(mapping[parts[0]], parts[1])
+	}
+	return mapping, nil
+}
+
 func serveHandler(ctx context.Context, logger slog.Logger, handler http.Handler, addr, name string) (closeFunc func()) {
 	logger.Debug(ctx, "http server listening", slog.F("addr", addr), slog.F("name", name))
 
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureOIDCProviders(t *testing.T) {
+	t.Parallel()
+	accessURL, err := url.Parse("https://coder.example.com")
+	require.NoError(t, err)
+	writeProviders := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "providers.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	t.Run("DuplicateIssuer", func(t *testing.T) {
+		t.Parallel()
+		path := writeProviders(t, `
+- name: first
+  issuer_url: https://idp.example.com
+  client_id: first
+- name: second
+  issuer_url: https://idp.example.com/
+  client_id: second
+`)
+		_, err := configureOIDCProviders(context.Background(), accessURL, path, "")
+		require.ErrorContains(t, err, `oidc provider "second" has the same issuer URL as oidc provider "first"`)
+	})
+
+	t.Run("DefaultIssuer", func(t *testing.T) {
+		t.Parallel()
+		path := writeProviders(t, `
+- name: first
+  issuer_url: https://idp.example.com
+  client_id: first
+`)
+		_, err := configureOIDCProviders(context.Background(), accessURL, path, "https://idp.example.com/")
+		require.ErrorContains(t, err, "the default OIDC provider")
+	})
+}
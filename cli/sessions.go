@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/agent/asciicast"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func sessions() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "List and replay recorded terminal sessions of a workspace",
+		Long: "Terminal sessions are recorded when session recording is enabled for the template " +
+			"of a workspace with \"coder templates edit --record-sessions\".",
+		Aliases: []string{"session"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(sessionList(), sessionReplay())
+	return cmd
+}
+
+type sessionTableRow struct {
+	ID        uuid.UUID                     `table:"id"`
+	User      string                        `table:"user"`
+	Type      codersdk.SessionRecordingType `table:"type"`
+	StartedAt string                        `table:"started at"`
+	Duration  time.Duration                 `table:"duration"`
+	Size      string                        `table:"size"`
+}
+
+func sessionList() *cobra.Command {
+	var columns []string
+	cmd := &cobra.Command{
+		Use:     "list <workspace>",
+		Aliases: []string{"ls"},
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			workspace, err := namedWorkspace(cmd, client, args[0])
+			if err != nil {
+				return err
+			}
+			recordings, err := client.WorkspaceSessionRecordings(cmd.Context(), workspace.ID)
+			if err != nil {
+				return xerrors.Errorf("get session recordings: %w", err)
+			}
+			if len(recordings) == 0 {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s has no recorded sessions.\n", cliui.Styles.Keyword.Render(workspace.Name))
+				return nil
+			}
+
+			rows := make([]sessionTableRow, 0, len(recordings))
+			for _, recording := range recordings {
+				rows = append(rows, sessionTableRow{
+					ID:        recording.ID,
+					User:      recording.Username,
+					Type:      recording.Type,
+					StartedAt: recording.StartedAt.Format(time.Stamp),
+					Duration:  recording.EndedAt.Sub(recording.StartedAt).Round(time.Second),
+					Size:      fmt.Sprintf("%.1f KiB", float64(recording.Size)/1024),
+				})
+			}
+			out, err := cliui.DisplayTable(rows, "", columns)
+			if err != nil {
+				return xerrors.Errorf("render table: %w", err)
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), out)
+			return err
+		},
+	}
+	cmd.Flags().StringArrayVarP(&columns, "column", "c", nil,
+		"Specify a column to filter in the table. Available columns are: id, user, type, started at, duration, size.")
+	return cmd
+}
+
+func sessionReplay() *cobra.Command {
+	var (
+		speed   float64
+		maxIdle time.Duration
+		raw     bool
+	)
+	cmd := &cobra.Command{
+		Use:   "replay <workspace> <id>",
+		Short: "Replay a recorded terminal session",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if speed <= 0 {
+				return xerrors.New("speed must be greater than zero")
+			}
+			recordingID, err := uuid.Parse(args[1])
+			if err != nil {
+				return xerrors.Errorf("%q must be a session id", args[1])
+			}
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			workspace, err := namedWorkspace(cmd, client, args[0])
+			if err != nil {
+				return err
+			}
+			data, err := client.DownloadWorkspaceSessionRecording(cmd.Context(), workspace.ID, recordingID)
+			if err != nil {
+				return xerrors.Errorf("download session recording: %w", err)
+			}
+			if raw {
+				// The raw recording can be played with other asciicast
+				// players, e.g. "asciinema play".
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			}
+
+			_, events, err := asciicast.Decode(bytes.NewReader(data))
+			if err != nil {
+				return xerrors.Errorf("decode session recording: %w", err)
+			}
+			var last float64
+			for _, event := range events {
+				wait := time.Duration((event.Time - last) / speed * float64(time.Second))
+				last = event.Time
+				if maxIdle > 0 && wait > maxIdle {
+					wait = maxIdle
+				}
+				if wait > 0 {
+					timer := time.NewTimer(wait)
+					select {
+					case <-cmd.Context().Done():
+						timer.Stop()
+						return cmd.Context().Err()
+					case <-timer.C:
+					}
+				}
+				if event.Type == asciicast.EventTypeMarker && event.Data == asciicast.TruncatedMarker {
+					_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "\r\nThe recording was truncated, since the session exceeded the maximum recording size.")
+					continue
+				}
+				if event.Type != asciicast.EventTypeOutput {
+					continue
+				}
+				_, err = fmt.Fprint(cmd.OutOrStdout(), event.Data)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Float64Var(&speed, "speed", 1, "Specify the playback speed multiplier.")
+	cmd.Flags().DurationVar(&maxIdle, "max-idle", 2*time.Second, "Specify the longest pause between output. Zero keeps the recorded pauses.")
+	cmd.Flags().BoolVar(&raw, "raw", false, "Write the recording in the asciicast v2 format instead of replaying it.")
+	return cmd
+}
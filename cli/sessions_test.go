@@ -0,0 +1,84 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/util/ptr"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestSessions(t *testing.T) {
+	t.Parallel()
+
+	client, workspace, agentToken := setupWorkspaceForSSH(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	_, err := client.UpdateTemplateMeta(ctx, workspace.TemplateID, codersdk.UpdateTemplateMeta{
+		RecordSessions: ptr.Ref(true),
+	})
+	require.NoError(t, err)
+
+	data := []byte("{\"version\":2,\"width\":80,\"height\":24}\n[0.1,\"o\",\"hello \"]\n[0.2,\"r\",\"100x30\"]\n[0.3,\"o\",\"world\"]\n")
+	agentClient := codersdk.New(client.URL)
+	agentClient.SessionToken = agentToken
+	err = agentClient.UploadWorkspaceAgentSessionRecording(ctx, agent.SessionRecording{
+		Type:      agent.SessionRecordingTypeReconnectingPTY,
+		StartedAt: time.Now().Add(-time.Minute),
+		EndedAt:   time.Now(),
+		Data:      data,
+	})
+	require.NoError(t, err)
+	recordings, err := client.WorkspaceSessionRecordings(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.Len(t, recordings, 1)
+
+	t.Run("List", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		cmd, root := clitest.New(t, "sessions", "list", workspace.Name)
+		clitest.SetupConfig(t, client, root)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		err := cmd.ExecuteContext(ctx)
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), recordings[0].ID.String())
+		require.Contains(t, buf.String(), "reconnecting_pty")
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		cmd, root := clitest.New(t, "sessions", "replay", workspace.Name, recordings[0].ID.String(), "--speed", "10")
+		clitest.SetupConfig(t, client, root)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		err := cmd.ExecuteContext(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "hello world", buf.String())
+	})
+
+	t.Run("Raw", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		cmd, root := clitest.New(t, "sessions", "replay", workspace.Name, recordings[0].ID.String(), "--raw")
+		clitest.SetupConfig(t, client, root)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		err := cmd.ExecuteContext(ctx)
+		require.NoError(t, err)
+		require.Equal(t, data, buf.Bytes())
+	})
+}
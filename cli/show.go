@@ -1,17 +1,22 @@
 package cli
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
 )
 
 func show() *cobra.Command {
 	return &cobra.Command{
 		Annotations: workspaceCommand,
 		Use:         "show <workspace>",
-		Short:       "Show details of a workspace's resources and agents",
+		Short:       "Show details of a workspace's resources, agents and active sessions",
 		Args:        cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, err := CreateClient(cmd)
@@ -26,9 +31,60 @@ func show() *cobra.Command {
 			if err != nil {
 				return xerrors.Errorf("get workspace resources: %w", err)
 			}
-			return cliui.WorkspaceResources(cmd.OutOrStdout(), resources, cliui.WorkspaceResourcesOptions{
+			err = cliui.WorkspaceResources(cmd.OutOrStdout(), resources, cliui.WorkspaceResourcesOptions{
 				WorkspaceName: workspace.Name,
 			})
+			if err != nil {
+				return err
+			}
+
+			rows := make([]agentSessionTableRow, 0)
+			for _, resource := range resources {
+				for _, agent := range resource.Agents {
+					if agent.Status != codersdk.WorkspaceAgentConnected {
+						continue
+					}
+					sessions, err := client.WorkspaceAgentSessions(cmd.Context(), agent.ID)
+					if err != nil {
+						// Sessions are informational, so a failure to
+						// reach the agent shouldn't fail the command.
+						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Failed to get the sessions of agent %s: %s\n", agent.Name, err)
+						continue
+					}
+					for _, session := range sessions {
+						rows = append(rows, agentSessionTableRow{
+							ID:         session.ID,
+							Agent:      agent.Name,
+							Type:       session.Type,
+							RemoteUser: session.RemoteUser,
+							Duration:   time.Since(session.StartedAt).Round(time.Second),
+							Received:   fmt.Sprintf("%.1f KiB", float64(session.RxBytes)/1024),
+							Sent:       fmt.Sprintf("%.1f KiB", float64(session.TxBytes)/1024),
+							Command:    session.Command,
+						})
+					}
+				}
+			}
+			if len(rows) == 0 {
+				return nil
+			}
+			out, err := cliui.DisplayTable(rows, "", nil)
+			if err != nil {
+				return xerrors.Errorf("render table: %w", err)
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "\nActive sessions:\n%s\n", out)
+			return err
 		},
 	}
 }
+
+type agentSessionTableRow struct {
+	ID         uuid.UUID                          `table:"id"`
+	Agent      string                             `table:"agent"`
+	Type       codersdk.WorkspaceAgentSessionType `table:"type"`
+	RemoteUser string                             `table:"remote user"`
+	Duration   time.Duration                      `table:"duration"`
+	Received   string                             `table:"received"`
+	Sent       string                             `table:"sent"`
+	Command    string                             `table:"command"`
+}
@@ -1,14 +1,23 @@
 package cli_test
 
 import (
+	"bytes"
+	"context"
+	"runtime"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/agent"
 	"github.com/coder/coder/cli/clitest"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/provisioner/echo"
 	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
 )
 
 func TestShow(t *testing.T) {
@@ -58,4 +67,40 @@ func TestShow(t *testing.T) {
 		}
 		<-doneChan
 	})
+	t.Run("Sessions", func(t *testing.T) {
+		t.Parallel()
+		if runtime.GOOS == "windows" {
+			t.Skip("ConPTY appears to be inconsistent on Windows.")
+		}
+		client, workspace, agentToken := setupWorkspaceForSSH(t)
+		agentClient := codersdk.New(client.URL)
+		agentClient.SessionToken = agentToken
+		agentCloser := agent.New(agentClient.ListenWorkspaceAgent, &agent.Options{
+			Logger: slogtest.Make(t, nil),
+		})
+		defer func() {
+			_ = agentCloser.Close()
+		}()
+		resources := coderdtest.AwaitWorkspaceAgents(t, client, workspace.LatestBuild.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		conn, err := client.WorkspaceAgentReconnectingPTY(ctx, resources[0].Agents[0].ID, uuid.New(), 80, 80, "/bin/bash")
+		require.NoError(t, err)
+		defer conn.Close()
+		require.Eventually(t, func() bool {
+			sessions, err := client.WorkspaceAgentSessions(ctx, resources[0].Agents[0].ID)
+			return assert.NoError(t, err) && len(sessions) == 1
+		}, testutil.WaitShort, testutil.IntervalFast)
+
+		cmd, root := clitest.New(t, "show", workspace.Name)
+		clitest.SetupConfig(t, client, root)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		err = cmd.ExecuteContext(ctx)
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "Active sessions")
+		require.Contains(t, buf.String(), "reconnecting_pty")
+		require.Contains(t, buf.String(), "/bin/bash")
+	})
 }
@@ -5,8 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,6 +27,7 @@ import (
 
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/sloghuman"
+	"github.com/coder/coder/agent"
 	"github.com/coder/coder/cli/cliflag"
 	"github.com/coder/coder/cli/cliui"
 	"github.com/coder/coder/coderd/autobuild/notify"
@@ -40,12 +44,14 @@ var (
 
 func ssh() *cobra.Command {
 	var (
-		stdio          bool
-		shuffle        bool
-		forwardAgent   bool
-		identityAgent  string
-		wsPollInterval time.Duration
-		wireguard      bool
+		stdio             bool
+		shuffle           bool
+		forwardAgent      bool
+		forwardX11        bool
+		forwardX11Trusted bool
+		identityAgent     string
+		wsPollInterval    time.Duration
+		wireguard         bool
 	)
 	cmd := &cobra.Command{
 		Annotations: workspaceCommand,
@@ -189,6 +195,19 @@ func ssh() *cobra.Command {
 				_ = sshSession.Close()
 			}()
 
+			// The agent sends the token back with the recording of
+			// the session, which attributes it to us.
+			recordingToken, err := client.WorkspaceAgentSessionRecordingToken(ctx, workspaceAgent.ID)
+			if err != nil {
+				return xerrors.Errorf("get session recording token: %w", err)
+			}
+			if recordingToken.Token != uuid.Nil {
+				err = sshSession.Setenv(agent.SessionRecordingTokenEnv, recordingToken.Token.String())
+				if err != nil {
+					return xerrors.Errorf("set session recording token: %w", err)
+				}
+			}
+
 			if identityAgent == "" {
 				identityAgent = os.Getenv("SSH_AUTH_SOCK")
 			}
@@ -203,6 +222,13 @@ func ssh() *cobra.Command {
 				}
 			}
 
+			if forwardX11 || forwardX11Trusted {
+				err = forwardX11ToLocal(ctx, sshClient, sshSession, forwardX11Trusted)
+				if err != nil {
+					return xerrors.Errorf("forward x11: %w", err)
+				}
+			}
+
 			stdoutFile, validOut := cmd.OutOrStdout().(*os.File)
 			stdinFile, validIn := cmd.InOrStdin().(*os.File)
 			if validOut && validIn && isatty.IsTerminal(stdoutFile.Fd()) {
@@ -266,6 +292,8 @@ func ssh() *cobra.Command {
 	cliflag.BoolVarP(cmd.Flags(), &shuffle, "shuffle", "", "CODER_SSH_SHUFFLE", false, "Specifies whether to choose a random workspace")
 	_ = cmd.Flags().MarkHidden("shuffle")
 	cliflag.BoolVarP(cmd.Flags(), &forwardAgent, "forward-agent", "A", "CODER_SSH_FORWARD_AGENT", false, "Specifies whether to forward the SSH agent specified in $SSH_AUTH_SOCK")
+	cliflag.BoolVarP(cmd.Flags(), &forwardX11, "forward-x11", "X", "CODER_SSH_FORWARD_X11", false, "Specifies whether to forward X11 connections to the display specified in $DISPLAY, with restricted access to the display")
+	cliflag.BoolVarP(cmd.Flags(), &forwardX11Trusted, "forward-x11-trusted", "Y", "CODER_SSH_FORWARD_X11_TRUSTED", false, "Specifies whether to forward X11 connections to the display specified in $DISPLAY, with full access to the display")
 	cliflag.StringVarP(cmd.Flags(), &identityAgent, "identity-agent", "", "CODER_SSH_IDENTITY_AGENT", "", "Specifies which identity agent to use (overrides $SSH_AUTH_SOCK), forward agent must also be enabled")
 	cliflag.DurationVarP(cmd.Flags(), &wsPollInterval, "workspace-poll-interval", "", "CODER_WORKSPACE_POLL_INTERVAL", workspacePollInterval, "Specifies how often to poll for workspace automated shutdown.")
 	cliflag.BoolVarP(cmd.Flags(), &wireguard, "wireguard", "", "CODER_SSH_WIREGUARD", false, "Whether to use Wireguard for SSH tunneling.")
@@ -405,3 +433,135 @@ func notifyCondition(ctx context.Context, client *codersdk.Client, workspaceID u
 		return deadline.Truncate(time.Minute), callback
 	}
 }
+
+// forwardX11ToLocal requests X11 forwarding for the session and proxies X11
+// connections from the workspace to the local display. Like with "ssh -X",
+// X clients in the workspace get an untrusted cookie, which keeps them from
+// accessing other windows of the display. Trusted forwarding sends the cookie
+// of the local display like with "ssh -Y".
+func forwardX11ToLocal(ctx context.Context, sshClient *gossh.Client, sshSession *gossh.Session, trusted bool) error {
+	display := os.Getenv("DISPLAY")
+	if display == "" {
+		return xerrors.New("$DISPLAY is not set")
+	}
+	var (
+		authProtocol, authCookie string
+		err                      error
+	)
+	if trusted {
+		authProtocol, authCookie, err = localX11Cookie(ctx, display)
+	} else {
+		authProtocol, authCookie, err = untrustedX11Cookie(ctx, display)
+	}
+	if err != nil {
+		return err
+	}
+	channels := sshClient.HandleChannelOpen("x11")
+	if channels == nil {
+		return xerrors.New("x11 forwarding is already handled")
+	}
+	ok, err := sshSession.SendRequest("x11-req", true, gossh.Marshal(struct {
+		SingleConnection bool
+		AuthProtocol     string
+		AuthCookie       string
+		ScreenNumber     uint32
+	}{
+		AuthProtocol: authProtocol,
+		AuthCookie:   authCookie,
+	}))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return xerrors.New("the workspace agent rejected the request")
+	}
+	go func() {
+		for newChannel := range channels {
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				continue
+			}
+			go gossh.DiscardRequests(requests)
+			local, err := dialX11(display)
+			if err != nil {
+				_ = channel.Close()
+				continue
+			}
+			go agent.Bicopy(ctx, local, channel)
+		}
+	}()
+	return nil
+}
+
+// localX11Cookie returns the authorization of the local display. A random
+// cookie is used when xauth isn't available, for X servers that don't check
+// it.
+func localX11Cookie(ctx context.Context, display string) (authProtocol string, authCookie string, err error) {
+	out, err := exec.CommandContext(ctx, "xauth", "list", display).Output()
+	if err == nil {
+		authProtocol, authCookie, err = parseXauthList(out)
+		if err == nil {
+			return authProtocol, authCookie, nil
+		}
+	}
+	cookie, err := cryptorand.HexString(32)
+	if err != nil {
+		return "", "", err
+	}
+	return "MIT-MAGIC-COOKIE-1", cookie, nil
+}
+
+// untrustedX11Cookie generates a cookie for the local display with the X
+// SECURITY extension. X clients using it can't access other clients of the
+// display. The cookie expires once it has been unused for 20 minutes, like the
+// default of OpenSSH.
+func untrustedX11Cookie(ctx context.Context, display string) (authProtocol string, authCookie string, err error) {
+	dir, err := os.MkdirTemp("", "coder-xauth")
+	if err != nil {
+		return "", "", xerrors.Errorf("create xauth directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	authFile := filepath.Join(dir, "xauthfile")
+
+	out, err := exec.CommandContext(ctx, "xauth", "-f", authFile, "generate", display, "MIT-MAGIC-COOKIE-1", "untrusted", "timeout", "1200").CombinedOutput()
+	if err != nil {
+		return "", "", xerrors.Errorf("generate untrusted xauth cookie, use --forward-x11-trusted if the display doesn't support it: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	out, err = exec.CommandContext(ctx, "xauth", "-f", authFile, "list", display).Output()
+	if err != nil {
+		return "", "", xerrors.Errorf("list untrusted xauth cookie: %w", err)
+	}
+	return parseXauthList(out)
+}
+
+// parseXauthList returns the first cookie of "xauth list". Each line is
+// formatted as "<display> <protocol> <hex cookie>".
+func parseXauthList(out []byte) (authProtocol string, authCookie string, err error) {
+	fields := strings.Fields(string(out))
+	if len(fields) < 3 {
+		return "", "", xerrors.New("xauth didn't list a cookie")
+	}
+	return fields[1], fields[2], nil
+}
+
+// dialX11 connects to a display formatted as "[host]:<display>[.<screen>]".
+func dialX11(display string) (net.Conn, error) {
+	index := strings.LastIndex(display, ":")
+	if index < 0 {
+		return nil, xerrors.Errorf("invalid display %q", display)
+	}
+	host := display[:index]
+	number, _, _ := strings.Cut(display[index+1:], ".")
+	switch {
+	case host == "" || host == "unix":
+		return net.Dial("unix", "/tmp/.X11-unix/X"+number)
+	case strings.HasPrefix(host, "/"):
+		// XQuartz on macOS uses the path of its socket as the host.
+		return net.Dial("unix", host+":"+number)
+	}
+	port, err := strconv.Atoi(number)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid display %q", display)
+	}
+	return net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(6000+port)))
+}
@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseXauthList(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Cookie", func(t *testing.T) {
+		t.Parallel()
+		authProtocol, authCookie, err := parseXauthList([]byte("host/unix:0  MIT-MAGIC-COOKIE-1  0123456789abcdef\nhost/unix:1  MIT-MAGIC-COOKIE-1  fedcba9876543210\n"))
+		require.NoError(t, err)
+		require.Equal(t, "MIT-MAGIC-COOKIE-1", authProtocol)
+		require.Equal(t, "0123456789abcdef", authCookie)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+		_, _, err := parseXauthList(nil)
+		require.Error(t, err)
+	})
+}
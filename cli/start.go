@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/cli/cliui"
 	"github.com/coder/coder/codersdk"
@@ -25,6 +26,23 @@ func start() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if workspace.DormantAt != nil {
+				_, err = cliui.Prompt(cmd, cliui.PromptOptions{
+					Text:      fmt.Sprintf("The %s workspace is dormant. Wake it up and start it?", cliui.Styles.Keyword.Render(workspace.Name)),
+					IsConfirm: true,
+					Default:   cliui.ConfirmYes,
+				})
+				if err != nil {
+					return err
+				}
+				err = client.UpdateWorkspaceDormancy(cmd.Context(), workspace.ID, codersdk.UpdateWorkspaceDormancyRequest{
+					Dormant: false,
+				})
+				if err != nil {
+					return xerrors.Errorf("wake workspace: %w", err)
+				}
+			}
+
 			before := time.Now()
 			build, err := client.CreateWorkspaceBuild(cmd.Context(), workspace.ID, codersdk.CreateWorkspaceBuildRequest{
 				Transition: codersdk.WorkspaceTransitionStart,
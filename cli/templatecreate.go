@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/coder/coder/cli/cliui"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/templatesync"
 	"github.com/coder/coder/coderd/util/ptr"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/provisionerd"
@@ -26,6 +28,11 @@ func templateCreate() *cobra.Command {
 		parameterFile        string
 		maxTTL               time.Duration
 		minAutostartInterval time.Duration
+		gitURL               string
+		gitRef               string
+		gitSubdirectory      string
+		gitAutoPromote       bool
+		variableFlags        templateVariableFlags
 	)
 	cmd := &cobra.Command{
 		Use:   "create [name]",
@@ -42,11 +49,43 @@ func templateCreate() *cobra.Command {
 				return err
 			}
 
+			// Templates created from a git repository are checked out into a
+			// temporary directory, and synced from the repository afterwards.
+			var (
+				message string
+				git     *codersdk.TemplateVersionGitMetadata
+			)
+			prettyDir := prettyDirectoryPath(directory)
+			if gitURL != "" {
+				checkout, err := os.MkdirTemp("", "coder-template-")
+				if err != nil {
+					return xerrors.Errorf("create temporary directory: %w", err)
+				}
+				defer os.RemoveAll(checkout)
+				commit, err := templatesync.Checkout(cmd.Context(), checkout, gitURL, gitRef)
+				if err != nil {
+					return xerrors.Errorf("checkout %s: %w", templatesync.RedactURL(gitURL), err)
+				}
+				directory, err = templatesync.SourceDirectory(checkout, gitSubdirectory)
+				if err != nil {
+					return err
+				}
+				message = commit.Subject
+				git = &commit.TemplateVersionGitMetadata
+				prettyDir = templateGitSourceLocation(codersdk.TemplateGitSource{
+					RepositoryURL: templatesync.RedactURL(gitURL),
+					Subdirectory:  gitSubdirectory,
+				}) + " at " + commit.CommitSHA
+			}
+
 			var templateName string
-			if len(args) == 0 {
-				templateName = filepath.Base(directory)
-			} else {
+			switch {
+			case len(args) > 0:
 				templateName = args[0]
+			case gitURL != "" && gitSubdirectory == "":
+				templateName = strings.TrimSuffix(path.Base(gitURL), ".git")
+			default:
+				templateName = filepath.Base(directory)
 			}
 
 			_, err = client.TemplateByName(cmd.Context(), organization.ID, templateName)
@@ -54,8 +93,12 @@ func templateCreate() *cobra.Command {
 				return xerrors.Errorf("A template already exists named %q!", templateName)
 			}
 
+			variables, err := variableFlags.parse(directory)
+			if err != nil {
+				return err
+			}
+
 			// Confirm upload of the directory.
-			prettyDir := prettyDirectoryPath(directory)
 			_, err = cliui.Prompt(cmd, cliui.PromptOptions{
 				Text:      fmt.Sprintf("Create and upload %q?", prettyDir),
 				IsConfirm: true,
@@ -87,6 +130,9 @@ func templateCreate() *cobra.Command {
 				Provisioner:   database.ProvisionerType(provisioner),
 				FileHash:      resp.Hash,
 				ParameterFile: parameterFile,
+				Message:       message,
+				Git:           git,
+				Variables:     variables,
 			})
 			if err != nil {
 				return err
@@ -107,11 +153,26 @@ func templateCreate() *cobra.Command {
 				MinAutostartIntervalMillis: ptr.Ref(minAutostartInterval.Milliseconds()),
 			}
 
-			_, err = client.CreateTemplate(cmd.Context(), organization.ID, createReq)
+			template, err := client.CreateTemplate(cmd.Context(), organization.ID, createReq)
 			if err != nil {
 				return err
 			}
 
+			if gitURL != "" {
+				source, err := client.UpdateTemplateGitSource(cmd.Context(), template.ID, codersdk.UpdateTemplateGitSourceRequest{
+					RepositoryURL: gitURL,
+					Ref:           gitRef,
+					Subdirectory:  gitSubdirectory,
+					AutoPromote:   gitAutoPromote,
+				})
+				if err != nil {
+					return xerrors.Errorf("sync template from git: %w", err)
+				}
+				if source.SyncError != "" {
+					_, _ = fmt.Fprintln(cmd.ErrOrStderr(), cliui.Styles.Warn.Render("Syncing the template from git failed: "+source.SyncError))
+				}
+			}
+
 			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "\n"+cliui.Styles.Wrap.Render(
 				"The "+cliui.Styles.Keyword.Render(templateName)+" template has been created at "+cliui.Styles.DateTimeStamp.Render(time.Now().Format(time.Stamp))+"! "+
 					"Developers can provision a workspace with this template using:")+"\n")
@@ -127,7 +188,12 @@ func templateCreate() *cobra.Command {
 	cmd.Flags().StringVarP(&provisioner, "test.provisioner", "", "terraform", "Customize the provisioner backend")
 	cmd.Flags().StringVarP(&parameterFile, "parameter-file", "", "", "Specify a file path with parameter values.")
 	cmd.Flags().DurationVarP(&maxTTL, "max-ttl", "", 24*time.Hour, "Specify a maximum TTL for workspaces created from this template.")
+	cmd.Flags().StringVarP(&gitURL, "git-url", "", "", "Specify the URL of a git repository to create the template from instead of a directory. The template is kept in sync with the repository.")
+	cmd.Flags().StringVarP(&gitRef, "git-ref", "", "main", "Specify the branch, tag or commit of the git repository to create the template from.")
+	cmd.Flags().StringVarP(&gitSubdirectory, "git-subdirectory", "", "", "Specify the directory of the template within the git repository.")
+	cmd.Flags().BoolVarP(&gitAutoPromote, "git-auto-promote", "", false, "Promote template versions synced from the git repository once a dry run of them succeeds.")
 	cmd.Flags().DurationVarP(&minAutostartInterval, "min-autostart-interval", "", time.Hour, "Specify a minimum autostart interval for workspaces created from this template.")
+	variableFlags.register(cmd)
 	// This is for testing!
 	err := cmd.Flags().MarkHidden("test.provisioner")
 	if err != nil {
@@ -149,6 +215,15 @@ type createValidTemplateVersionArgs struct {
 	// before prompting the user. Set to false to always prompt for param
 	// values.
 	ReuseParameters bool
+	// Detached creates the version without adding it to the Template, which
+	// is then only used to inherit parameters and variables.
+	Detached bool
+	// Message describes the changes in the version.
+	Message string
+	// Git is the git commit the version is created from, if any.
+	Git *codersdk.TemplateVersionGitMetadata
+	// Variables set template variables of the version.
+	Variables []codersdk.CreateTemplateVersionVariable
 }
 
 func createValidTemplateVersion(cmd *cobra.Command, args createValidTemplateVersionArgs, parameters ...codersdk.CreateParameterRequest) (*codersdk.TemplateVersion, []codersdk.CreateParameterRequest, error) {
@@ -160,9 +235,13 @@ func createValidTemplateVersion(cmd *cobra.Command, args createValidTemplateVers
 		StorageSource:   args.FileHash,
 		Provisioner:     codersdk.ProvisionerType(args.Provisioner),
 		ParameterValues: parameters,
+		Message:         args.Message,
+		Git:             args.Git,
+		Variables:       args.Variables,
 	}
 	if args.Template != nil {
 		req.TemplateID = args.Template.ID
+		req.Detached = args.Detached
 	}
 	version, err := client.CreateTemplateVersion(cmd.Context(), args.Organization.ID, req)
 	if err != nil {
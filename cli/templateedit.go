@@ -8,6 +8,7 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/coderd/util/ptr"
 	"github.com/coder/coder/codersdk"
 )
 
@@ -18,6 +19,12 @@ func templateEdit() *cobra.Command {
 		icon                 string
 		maxTTL               time.Duration
 		minAutostartInterval time.Duration
+		inactivityTTL        time.Duration
+		dormantAutoDeleteTTL time.Duration
+		maintenanceWindow    string
+		maintenanceDuration  time.Duration
+		maintenanceRestart   bool
+		recordSessions       bool
 	)
 
 	cmd := &cobra.Command{
@@ -46,6 +53,26 @@ func templateEdit() *cobra.Command {
 				MaxTTLMillis:               maxTTL.Milliseconds(),
 				MinAutostartIntervalMillis: minAutostartInterval.Milliseconds(),
 			}
+			// The dormancy and maintenance policies can be disabled with zero
+			// values, so only send them when they were explicitly set.
+			if cmd.Flags().Changed("inactivity-ttl") {
+				req.InactivityTTLMillis = ptr.Ref(inactivityTTL.Milliseconds())
+			}
+			if cmd.Flags().Changed("dormant-autodelete-ttl") {
+				req.DormantAutoDeleteTTLMillis = ptr.Ref(dormantAutoDeleteTTL.Milliseconds())
+			}
+			if cmd.Flags().Changed("maintenance-window") {
+				req.MaintenanceWindow = ptr.Ref(maintenanceWindow)
+			}
+			if cmd.Flags().Changed("maintenance-window-duration") {
+				req.MaintenanceWindowMillis = ptr.Ref(maintenanceDuration.Milliseconds())
+			}
+			if cmd.Flags().Changed("maintenance-restart-outdated") {
+				req.MaintenanceRestartOutdated = ptr.Ref(maintenanceRestart)
+			}
+			if cmd.Flags().Changed("record-sessions") {
+				req.RecordSessions = ptr.Ref(recordSessions)
+			}
 
 			_, err = client.UpdateTemplateMeta(cmd.Context(), template.ID, req)
 			if err != nil {
@@ -61,6 +88,12 @@ func templateEdit() *cobra.Command {
 	cmd.Flags().StringVarP(&icon, "icon", "", "", "Edit the template icon path")
 	cmd.Flags().DurationVarP(&maxTTL, "max-ttl", "", 0, "Edit the template maximum time before shutdown - workspaces created from this template cannot stay running longer than this.")
 	cmd.Flags().DurationVarP(&minAutostartInterval, "min-autostart-interval", "", 0, "Edit the template minimum autostart interval - workspaces created from this template must wait at least this long between autostarts.")
+	cmd.Flags().DurationVarP(&inactivityTTL, "inactivity-ttl", "", 0, "Edit the template inactivity period - stopped workspaces unused for this long are marked dormant and cannot be started until their owner wakes them. Zero disables dormancy.")
+	cmd.Flags().DurationVarP(&dormantAutoDeleteTTL, "dormant-autodelete-ttl", "", 0, "Edit the template dormant auto-delete period - dormant workspaces are deleted after this long. Zero disables automatic deletion.")
+	cmd.Flags().StringVarP(&maintenanceWindow, "maintenance-window", "", "", `Edit the template maintenance window as a weekly cron schedule, e.g. "CRON_TZ=Europe/Dublin 0 2 * * 1-5". Autostops are deferred until a window opens. An empty value removes the window.`)
+	cmd.Flags().DurationVarP(&maintenanceDuration, "maintenance-window-duration", "", 0, "Edit how long each maintenance window lasts.")
+	cmd.Flags().BoolVarP(&maintenanceRestart, "maintenance-restart-outdated", "", false, "Restart running workspaces on the active template version during the maintenance window.")
+	cmd.Flags().BoolVarP(&recordSessions, "record-sessions", "", false, "Record terminal sessions in workspaces created from this template. Recordings can be replayed with \"coder sessions replay\".")
 	cliui.AllowSkipPrompt(cmd)
 
 	return cmd
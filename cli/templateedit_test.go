@@ -103,4 +103,88 @@ func TestTemplateEdit(t *testing.T) {
 		assert.Equal(t, template.MaxTTLMillis, updated.MaxTTLMillis)
 		assert.Equal(t, template.MinAutostartIntervalMillis, updated.MinAutostartIntervalMillis)
 	})
+
+	t.Run("Dormancy", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		inactivityTTL := 30 * 24 * time.Hour
+		dormantAutoDeleteTTL := 7 * 24 * time.Hour
+		cmd, root := clitest.New(t, "templates", "edit", template.Name,
+			"--inactivity-ttl", inactivityTTL.String(),
+			"--dormant-autodelete-ttl", dormantAutoDeleteTTL.String(),
+		)
+		clitest.SetupConfig(t, client, root)
+		err := cmd.Execute()
+		require.NoError(t, err)
+
+		updated, err := client.Template(context.Background(), template.ID)
+		require.NoError(t, err)
+		assert.Equal(t, inactivityTTL.Milliseconds(), updated.InactivityTTLMillis)
+		assert.Equal(t, dormantAutoDeleteTTL.Milliseconds(), updated.DormantAutoDeleteTTLMillis)
+
+		// Editing other fields leaves the policy alone.
+		cmd, root = clitest.New(t, "templates", "edit", template.Name, "--description", "changed")
+		clitest.SetupConfig(t, client, root)
+		err = cmd.Execute()
+		require.NoError(t, err)
+
+		updated, err = client.Template(context.Background(), template.ID)
+		require.NoError(t, err)
+		assert.Equal(t, inactivityTTL.Milliseconds(), updated.InactivityTTLMillis)
+		assert.Equal(t, dormantAutoDeleteTTL.Milliseconds(), updated.DormantAutoDeleteTTLMillis)
+
+		// Zero disables the policy.
+		cmd, root = clitest.New(t, "templates", "edit", template.Name, "--inactivity-ttl", "0s")
+		clitest.SetupConfig(t, client, root)
+		err = cmd.Execute()
+		require.NoError(t, err)
+
+		updated, err = client.Template(context.Background(), template.ID)
+		require.NoError(t, err)
+		assert.Zero(t, updated.InactivityTTLMillis)
+		assert.Equal(t, dormantAutoDeleteTTL.Milliseconds(), updated.DormantAutoDeleteTTLMillis)
+	})
+
+	t.Run("MaintenanceWindow", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		window := "CRON_TZ=US/Central 30 1 * * 1-5"
+		cmd, root := clitest.New(t, "templates", "edit", template.Name,
+			"--maintenance-window", window,
+			"--maintenance-window-duration", "3h",
+			"--maintenance-restart-outdated",
+		)
+		clitest.SetupConfig(t, client, root)
+		err := cmd.Execute()
+		require.NoError(t, err)
+
+		updated, err := client.Template(context.Background(), template.ID)
+		require.NoError(t, err)
+		assert.Equal(t, window, updated.MaintenanceWindow)
+		assert.Equal(t, (3 * time.Hour).Milliseconds(), updated.MaintenanceWindowMillis)
+		assert.True(t, updated.MaintenanceRestartOutdated)
+
+		cmd, root = clitest.New(t, "templates", "edit", template.Name,
+			"--maintenance-window", "",
+			"--maintenance-restart-outdated=false",
+		)
+		clitest.SetupConfig(t, client, root)
+		err = cmd.Execute()
+		require.NoError(t, err)
+
+		updated, err = client.Template(context.Background(), template.ID)
+		require.NoError(t, err)
+		assert.Empty(t, updated.MaintenanceWindow)
+		assert.False(t, updated.MaintenanceRestartOutdated)
+	})
 }
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+
+	"github.com/coder/coder/coderd/templatesync"
+	"github.com/coder/coder/codersdk"
+)
+
+// templateGitCommit describes the commit a template directory is checked out
+// at.
+type templateGitCommit struct {
+	Metadata codersdk.TemplateVersionGitMetadata
+	// Subject is the first line of the commit message.
+	Subject string
+}
+
+// readTemplateGitCommit returns the git commit that directory is checked out
+// at. It returns false if the directory isn't in a git repository or git isn't
+// installed.
+func readTemplateGitCommit(ctx context.Context, directory string) (templateGitCommit, bool) {
+	git := func(args ...string) (string, error) {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = directory
+		out, err := cmd.Output()
+		return strings.TrimSpace(string(out)), err
+	}
+
+	sha, err := git("rev-parse", "HEAD")
+	if err != nil {
+		return templateGitCommit{}, false
+	}
+	commit := templateGitCommit{
+		Metadata: codersdk.TemplateVersionGitMetadata{
+			CommitSHA: sha,
+		},
+	}
+	// The remaining fields are best effort. A detached HEAD has no branch
+	// and a local repository has no remote.
+	if branch, err := git("rev-parse", "--abbrev-ref", "HEAD"); err == nil && branch != "HEAD" {
+		commit.Metadata.Branch = branch
+	}
+	if remote, err := git("remote", "get-url", "origin"); err == nil {
+		commit.Metadata.RemoteURL = templatesync.RedactURL(remote)
+	}
+	if author, err := git("log", "-1", "--format=%an <%ae>"); err == nil {
+		commit.Metadata.Author = author
+	}
+	if subject, err := git("log", "-1", "--format=%s"); err == nil {
+		commit.Subject = subject
+	}
+	// Only changes to the pushed directory make the version differ from
+	// the commit.
+	if status, err := git("status", "--porcelain", "--", "."); err == nil {
+		commit.Metadata.Dirty = status != ""
+	}
+	return commit, true
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func templateGitSource() *cobra.Command {
+	var (
+		repositoryURL string
+		ref           string
+		subdirectory  string
+		autoPromote   bool
+		remove        bool
+		sync          bool
+	)
+	cmd := &cobra.Command{
+		Use:   "git-source <template>",
+		Short: "Show or change the git repository a template is synced from",
+		Long: "Coder periodically fetches the repository, and creates a template version whenever the ref " +
+			"points at a new commit. With --auto-promote, new versions become active once a dry run of them succeeds.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			organization, err := currentOrganization(cmd, client)
+			if err != nil {
+				return err
+			}
+			template, err := client.TemplateByName(cmd.Context(), organization.ID, args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+
+			if remove {
+				err = client.DeleteTemplateGitSource(cmd.Context(), template.ID)
+				if err != nil {
+					return xerrors.Errorf("delete template git source: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Template %s is no longer synced from git.\n", cliui.Styles.Keyword.Render(template.Name))
+				return nil
+			}
+
+			var source codersdk.TemplateGitSource
+			changed := cmd.Flags().Changed("repository-url") || cmd.Flags().Changed("ref") ||
+				cmd.Flags().Changed("subdirectory") || cmd.Flags().Changed("auto-promote")
+			if changed {
+				req := codersdk.UpdateTemplateGitSourceRequest{
+					RepositoryURL: repositoryURL,
+					Ref:           ref,
+					Subdirectory:  subdirectory,
+					AutoPromote:   autoPromote,
+				}
+				// Settings that aren't specified are kept. An empty repository
+				// URL keeps the current one, since the URL returned by the API
+				// has its credentials removed.
+				current, err := client.TemplateGitSource(cmd.Context(), template.ID)
+				if err == nil {
+					if !cmd.Flags().Changed("ref") {
+						req.Ref = current.Ref
+					}
+					if !cmd.Flags().Changed("subdirectory") {
+						req.Subdirectory = current.Subdirectory
+					}
+					if !cmd.Flags().Changed("auto-promote") {
+						req.AutoPromote = current.AutoPromote
+					}
+				}
+				source, err = client.UpdateTemplateGitSource(cmd.Context(), template.ID, req)
+				if err != nil {
+					return xerrors.Errorf("update template git source: %w", err)
+				}
+			} else if sync {
+				source, err = client.SyncTemplateGitSource(cmd.Context(), template.ID)
+				if err != nil {
+					return xerrors.Errorf("sync template: %w", err)
+				}
+			} else {
+				source, err = client.TemplateGitSource(cmd.Context(), template.ID)
+				if err != nil {
+					return xerrors.Errorf("get template git source: %w", err)
+				}
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Template %s is synced from %s at %s.\n",
+				cliui.Styles.Keyword.Render(template.Name), cliui.Styles.Code.Render(templateGitSourceLocation(source)), cliui.Styles.Code.Render(source.Ref))
+			if source.CommitSHA != "" {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  Latest commit: %s\n", source.CommitSHA)
+			}
+			if source.AutoPromote {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "  New versions are promoted once a dry run succeeds.")
+			}
+			if source.PendingVersionID != nil {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  Waiting to promote version %s.\n", *source.PendingVersionID)
+			}
+			if source.SyncedAt != nil {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  Last synced: %s\n", source.SyncedAt.Format("January 2, 2006 15:04:05"))
+			}
+			if source.SyncError != "" {
+				return xerrors.Errorf("sync template: %s", source.SyncError)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&repositoryURL, "repository-url", "", "", "Specify the URL of the git repository to sync the template from. Credentials for private repositories can be included in the URL.")
+	cmd.Flags().StringVarP(&ref, "ref", "", "main", "Specify the branch, tag or commit to sync the template from.")
+	cmd.Flags().StringVarP(&subdirectory, "subdirectory", "", "", "Specify the directory of the template within the repository.")
+	cmd.Flags().BoolVarP(&autoPromote, "auto-promote", "", false, "Promote new template versions once a dry run of them succeeds.")
+	cmd.Flags().BoolVarP(&remove, "remove", "", false, "Stop syncing the template from git. Existing versions are kept.")
+	cmd.Flags().BoolVarP(&sync, "sync", "", false, "Fetch the repository now instead of waiting for the next periodic sync.")
+	return cmd
+}
+
+// templateGitSourceLocation describes where in a repository a template is
+// synced from.
+func templateGitSourceLocation(source codersdk.TemplateGitSource) string {
+	if source.Subdirectory == "" {
+		return source.RepositoryURL
+	}
+	return source.RepositoryURL + "//" + source.Subdirectory
+}
@@ -0,0 +1,77 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/provisioner/echo"
+)
+
+func TestTemplateGitSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CreateFromGit", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		repository := clitest.CreateTemplateVersionSource(t, &echo.Responses{
+			Parse:     echo.ParseComplete,
+			Provision: echo.ProvisionComplete,
+		})
+		git := func(args ...string) string {
+			c := exec.Command("git", args...)
+			c.Dir = repository
+			out, err := c.CombinedOutput()
+			require.NoError(t, err, string(out))
+			return strings.TrimSpace(string(out))
+		}
+		git("init", "--initial-branch", "main")
+		git("config", "user.email", "ci@coder.com")
+		git("config", "user.name", "C I")
+		git("add", ".")
+		git("commit", "-m", "Add template")
+		sha := git("rev-parse", "HEAD")
+
+		cmd, root := clitest.New(t, "templates", "create", "synced", "-y",
+			"--git-url", repository,
+			"--test.provisioner", string(database.ProvisionerTypeEcho))
+		clitest.SetupConfig(t, client, root)
+		require.NoError(t, cmd.Execute())
+
+		template, err := client.TemplateByName(context.Background(), user.OrganizationID, "synced")
+		require.NoError(t, err)
+		version, err := client.TemplateVersion(context.Background(), template.ActiveVersionID)
+		require.NoError(t, err)
+		require.Equal(t, "Add template", version.Message)
+		require.Equal(t, sha, version.Git.CommitSHA)
+
+		// The first sync doesn't create another version of the same commit.
+		source, err := client.TemplateGitSource(context.Background(), template.ID)
+		require.NoError(t, err)
+		require.Equal(t, sha, source.CommitSHA)
+		require.Empty(t, source.SyncError)
+
+		cmd, root = clitest.New(t, "templates", "git-source", "synced", "--ref", "main", "--auto-promote")
+		clitest.SetupConfig(t, client, root)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		require.NoError(t, cmd.Execute())
+		require.Contains(t, buf.String(), "is synced from")
+		require.Contains(t, buf.String(), sha)
+		require.Contains(t, buf.String(), "promoted once a dry run succeeds")
+
+		cmd, root = clitest.New(t, "templates", "git-source", "synced", "--remove")
+		clitest.SetupConfig(t, client, root)
+		require.NoError(t, cmd.Execute())
+		_, err = client.TemplateGitSource(context.Background(), template.ID)
+		require.Error(t, err)
+	})
+}
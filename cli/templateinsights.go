@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+type templateInsightsTableRow struct {
+	Date            string `table:"date"`
+	ActiveUsers     int64  `table:"active users"`
+	Builds          int64  `table:"builds"`
+	BuildsSucceeded int64  `table:"succeeded"`
+	BuildDuration   string `table:"median build"`
+	AgentConnect    string `table:"median agent connect"`
+	Apps            string `table:"apps"`
+}
+
+func templateInsights() *cobra.Command {
+	var (
+		startDate string
+		endDate   string
+		columns   []string
+	)
+	cmd := &cobra.Command{
+		Use:   "insights <template>",
+		Short: "Show how a template is used: active users, builds, agent connections and apps",
+		Long: "Insights are computed per UTC day by the server every few minutes, so the current " +
+			"day may lag behind. The last week is shown by default.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var req codersdk.TemplateInsightsRequest
+			var err error
+			if startDate != "" {
+				req.StartDate, err = time.Parse(codersdk.InsightsDateFormat, startDate)
+				if err != nil {
+					return xerrors.Errorf("start date must be formatted as %q: %w", codersdk.InsightsDateFormat, err)
+				}
+			}
+			if endDate != "" {
+				req.EndDate, err = time.Parse(codersdk.InsightsDateFormat, endDate)
+				if err != nil {
+					return xerrors.Errorf("end date must be formatted as %q: %w", codersdk.InsightsDateFormat, err)
+				}
+			}
+
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			organization, err := currentOrganization(cmd, client)
+			if err != nil {
+				return err
+			}
+			template, err := client.TemplateByName(cmd.Context(), organization.ID, args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+			insights, err := client.TemplateInsights(cmd.Context(), template.ID, req)
+			if err != nil {
+				return xerrors.Errorf("get template insights: %w", err)
+			}
+
+			out := cmd.OutOrStdout()
+			_, _ = fmt.Fprintf(out, "Insights of %s from %s to %s (UTC):\n", cliui.Styles.Keyword.Render(template.Name),
+				insights.StartDate.Format(codersdk.InsightsDateFormat), insights.EndDate.Format(codersdk.InsightsDateFormat))
+			_, _ = fmt.Fprintf(out, "  Builds: %d (%.0f%% succeeded)\n", insights.BuildsTotal, insights.BuildSuccessRate*100)
+			_, _ = fmt.Fprintf(out, "  Median build duration: %s\n", insightsMillisDisplay(insights.BuildDurationMedianMillis))
+			_, _ = fmt.Fprintf(out, "  Median time until an agent connected: %s\n\n", insightsMillisDisplay(insights.AgentConnectMedianMillis))
+
+			rows := make([]templateInsightsTableRow, 0, len(insights.Days))
+			for _, day := range insights.Days {
+				rows = append(rows, templateInsightsTableRow{
+					Date:            day.Date.Format(codersdk.InsightsDateFormat),
+					ActiveUsers:     day.ActiveUsers,
+					Builds:          day.BuildsTotal,
+					BuildsSucceeded: day.BuildsSucceeded,
+					BuildDuration:   insightsMillisDisplay(day.BuildDurationMedianMillis),
+					AgentConnect:    insightsMillisDisplay(day.AgentConnectMedianMillis),
+					Apps:            insightsAppsDisplay(day.AppUsage),
+				})
+			}
+			table, err := cliui.DisplayTable(rows, "", columns)
+			if err != nil {
+				return xerrors.Errorf("render table: %w", err)
+			}
+			_, err = fmt.Fprintln(out, table)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&startDate, "start-date", "", fmt.Sprintf("Specify the first day to show insights for, formatted as %q.", codersdk.InsightsDateFormat))
+	cmd.Flags().StringVar(&endDate, "end-date", "", fmt.Sprintf("Specify the last day to show insights for, formatted as %q. Defaults to today.", codersdk.InsightsDateFormat))
+	cmd.Flags().StringArrayVarP(&columns, "column", "c", nil, "Specify a column to filter in the table.")
+	return cmd
+}
+
+func insightsMillisDisplay(millis *int64) string {
+	if millis == nil {
+		return "-"
+	}
+	return (time.Duration(*millis) * time.Millisecond).Round(time.Second).String()
+}
+
+// insightsAppsDisplay lists the apps that were used with how many users used
+// them, such as "code-server (3), jupyter (1)".
+func insightsAppsDisplay(usage map[string]int64) string {
+	if len(usage) == 0 {
+		return "-"
+	}
+	apps := make([]string, 0, len(usage))
+	for name, users := range usage {
+		apps = append(apps, fmt.Sprintf("%s (%d)", name, users))
+	}
+	sort.Strings(apps)
+	return strings.Join(apps, ", ")
+}
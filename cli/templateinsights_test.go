@@ -0,0 +1,48 @@
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+)
+
+func TestTemplateInsights(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Report", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		cmd, root := clitest.New(t, "templates", "insights", template.Name, "--start-date", "2022-07-11", "--end-date", "2022-07-12")
+		clitest.SetupConfig(t, client, root)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+
+		err := cmd.Execute()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "from 2022-07-11 to 2022-07-12")
+		require.Contains(t, buf.String(), "Builds: 0")
+		require.Contains(t, buf.String(), "2022-07-12")
+	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		cmd, root := clitest.New(t, "templates", "insights", template.Name, "--start-date", "yesterday")
+		clitest.SetupConfig(t, client, root)
+
+		err := cmd.Execute()
+		require.ErrorContains(t, err, "start date must be formatted")
+	})
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/provisioner/terraform"
+	"github.com/coder/coder/provisionersdk/proto"
+)
+
+func templateLint() *cobra.Command {
+	return &cobra.Command{
+		Use:   "lint [directory]",
+		Short: "Find mistakes in a template without importing it",
+		Long: "Validates a Terraform template offline. It reports problems such as agents that aren't attached to a " +
+			"resource, duplicate app names and startup scripts that can't run, which otherwise surface only after the template is imported.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			directory, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			if len(args) > 0 {
+				directory = args[0]
+			}
+
+			diagnostics, err := terraform.Lint(directory)
+			if err != nil {
+				return xerrors.Errorf("lint template: %w", err)
+			}
+			if len(diagnostics) == 0 {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s No problems found in %s.\n", cliui.Styles.Checkmark, prettyDirectoryPath(directory))
+				return nil
+			}
+
+			errors := 0
+			for _, diagnostic := range diagnostics {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), formatTemplateDiagnostic(diagnostic))
+				if diagnostic.Severity == proto.Diagnostic_ERROR {
+					errors++
+				}
+			}
+			if errors > 0 {
+				return xerrors.Errorf("found %d errors in the template", errors)
+			}
+			return nil
+		},
+	}
+}
+
+func formatTemplateDiagnostic(diagnostic *proto.Diagnostic) string {
+	severity := cliui.Styles.Warn.Render("warning")
+	if diagnostic.Severity == proto.Diagnostic_ERROR {
+		severity = cliui.Styles.Error.Render("error")
+	}
+	var output strings.Builder
+	if diagnostic.Filename != "" {
+		_, _ = fmt.Fprintf(&output, "%s:%d: ", diagnostic.Filename, diagnostic.Line)
+	}
+	_, _ = fmt.Fprintf(&output, "%s: %s", severity, diagnostic.Summary)
+	if diagnostic.Detail != "" {
+		_, _ = fmt.Fprintf(&output, "\n%s", cliui.Styles.Wrap.Copy().PaddingLeft(2).Render(diagnostic.Detail))
+	}
+	return output.String()
+}
@@ -0,0 +1,57 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+)
+
+func TestTemplateLint(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+		directory := t.TempDir()
+		err := os.WriteFile(filepath.Join(directory, "main.tf"), []byte(`variable "image" {
+	default = "ubuntu"
+}`), 0o600)
+		require.NoError(t, err)
+
+		cmd, _ := clitest.New(t, "templates", "lint", directory)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		require.NoError(t, cmd.Execute())
+		require.Contains(t, buf.String(), "No problems found")
+	})
+
+	t.Run("Problems", func(t *testing.T) {
+		t.Parallel()
+		directory := t.TempDir()
+		err := os.WriteFile(filepath.Join(directory, "main.tf"), []byte(`resource "coder_agent" "main" {
+	os   = "linux"
+	arch = "amd64"
+}
+resource "coder_app" "code" {
+	agent_id = coder_agent.main.id
+	name     = "code"
+}
+resource "coder_app" "vscode" {
+	agent_id = coder_agent.main.id
+	name     = "code"
+}`), 0o600)
+		require.NoError(t, err)
+
+		cmd, _ := clitest.New(t, "templates", "lint", directory)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		err = cmd.Execute()
+		require.ErrorContains(t, err, "found 1 errors")
+		require.Contains(t, buf.String(), `main.tf:1: warning: Agent "main" isn't attached to a resource`)
+		require.Contains(t, buf.String(), `main.tf:9: error: Duplicate app name "code"`)
+	})
+}
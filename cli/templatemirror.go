@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliflag"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/provisioner/terraform"
+)
+
+func templateMirror() *cobra.Command {
+	var (
+		cacheDir  string
+		platforms []string
+	)
+	cmd := &cobra.Command{
+		Use:   "mirror [directory]",
+		Short: "Download the Terraform providers and modules of a template into a provisioner cache directory",
+		Long: "Populates the cache of provisioners that run with --provisioner-offline, so they can provision the " +
+			"template without network access. Run it on a machine with network access, and copy the cache directory to " +
+			"the air-gapped provisioners if they don't share it.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			directory, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			if len(args) > 0 {
+				directory = args[0]
+			}
+
+			err = terraform.Mirror(cmd.Context(), terraform.MirrorOptions{
+				CachePath: cacheDir,
+				Directory: directory,
+				Platforms: platforms,
+				Output:    cmd.ErrOrStderr(),
+			})
+			if err != nil {
+				return xerrors.Errorf("mirror template: %w", err)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s Mirrored the providers and modules of %s into %s.\n",
+				cliui.Styles.Checkmark, prettyDirectoryPath(directory), cliui.Styles.Keyword.Render(cacheDir))
+			return nil
+		},
+	}
+	cliflag.StringVarP(cmd.Flags(), &cacheDir, "cache-dir", "", "CODER_CACHE_DIRECTORY", defaultCacheDir(), "Specifies the cache directory of the provisioners.")
+	cmd.Flags().StringArrayVar(&platforms, "platform", nil, `Specify a platform to mirror providers for, such as "linux_amd64". Defaults to the platform of this machine.`)
+	return cmd
+}
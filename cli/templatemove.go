@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func templateMove() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "move <template> <organization>",
+		Short: "Move a template, its versions and workspaces to another organization",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			organization, err := currentOrganization(cmd, client)
+			if err != nil {
+				return err
+			}
+			template, err := client.TemplateByName(cmd.Context(), organization.ID, args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+			target, err := namedOrganization(cmd, client, args[1])
+			if err != nil {
+				return err
+			}
+
+			_, err = cliui.Prompt(cmd, cliui.PromptOptions{
+				Text: fmt.Sprintf("Move template %s and all of its workspaces from %s to %s?",
+					cliui.Styles.Code.Render(template.Name), cliui.Styles.Code.Render(organization.Name), cliui.Styles.Code.Render(target.Name)),
+				IsConfirm: true,
+				Default:   cliui.ConfirmNo,
+			})
+			if err != nil {
+				return err
+			}
+
+			_, err = client.UpdateTemplateOrganization(cmd.Context(), template.ID, codersdk.UpdateTemplateOrganizationRequest{
+				OrganizationID: target.ID,
+			})
+			if err != nil {
+				return xerrors.Errorf("move template: %w", err)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Template %s has been moved to %s!\n", cliui.Styles.Keyword.Render(template.Name), cliui.Styles.Keyword.Render(target.Name))
+			return nil
+		},
+	}
+	cliui.AllowSkipPrompt(cmd)
+	return cmd
+}
@@ -1,16 +1,366 @@
 package cli
 
 import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisionersdk"
 )
 
 func templatePlan() *cobra.Command {
-	return &cobra.Command{
+	var (
+		templateName  string
+		provisioner   string
+		parameterFile string
+	)
+	cmd := &cobra.Command{
 		Use:   "plan <directory>",
-		Args:  cobra.MinimumNArgs(1),
+		Args:  cobra.ExactArgs(1),
 		Short: "Plan a template push from the current directory",
+		Long: "Plan a template push by comparing the resources, agents and apps of the directory against the active version " +
+			"of the template. The active version isn't changed.",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			organization, err := currentOrganization(cmd, client)
+			if err != nil {
+				return err
+			}
+
+			directory := args[0]
+			if templateName == "" {
+				templateName = filepath.Base(filepath.Clean(directory))
+			}
+			template, err := client.TemplateByName(cmd.Context(), organization.ID, templateName)
+			if err != nil {
+				return err
+			}
+
+			spin := spinner.New(spinner.CharSets[5], 100*time.Millisecond)
+			spin.Writer = cmd.OutOrStdout()
+			spin.Suffix = cliui.Styles.Keyword.Render(" Uploading directory...")
+			spin.Start()
+			defer spin.Stop()
+			content, err := provisionersdk.Tar(directory, provisionersdk.TemplateArchiveLimit)
+			if err != nil {
+				return err
+			}
+			resp, err := client.Upload(cmd.Context(), codersdk.ContentTypeTar, content)
+			if err != nil {
+				return err
+			}
+			spin.Stop()
+
+			version, _, err := createValidTemplateVersion(cmd, createValidTemplateVersionArgs{
+				Client:          client,
+				Organization:    organization,
+				Provisioner:     database.ProvisionerType(provisioner),
+				FileHash:        resp.Hash,
+				ParameterFile:   parameterFile,
+				Template:        &template,
+				ReuseParameters: true,
+				// Planning mustn't add a version to the template.
+				Detached: true,
+			})
+			if err != nil {
+				return err
+			}
+			if version.Job.Status != codersdk.ProvisionerJobSucceeded {
+				return xerrors.Errorf("job failed: %s", version.Job.Status)
+			}
+
+			var parameterMapFromFile map[string]string
+			if parameterFile != "" {
+				parameterMapFromFile, err = createParameterMapFromFile(parameterFile)
+				if err != nil {
+					return err
+				}
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Planning the active version...")
+			activeResources, err := dryRunTemplateVersion(cmd, client, template.ActiveVersionID, parameterMapFromFile)
+			if err != nil {
+				return xerrors.Errorf("plan active version: %w", err)
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Planning the new version...")
+			resources, err := dryRunTemplateVersion(cmd, client, version.ID, parameterMapFromFile)
+			if err != nil {
+				return xerrors.Errorf("plan new version: %w", err)
+			}
+
+			_, _ = fmt.Fprintln(cmd.OutOrStdout())
+			writeTemplatePlan(cmd.OutOrStdout(), diffTemplateResources(activeResources, resources))
 			return nil
 		},
 	}
+
+	cmd.Flags().StringVarP(&templateName, "template", "t", "", "The template to plan against. Defaults to the name of the directory.")
+	cmd.Flags().StringVarP(&provisioner, "test.provisioner", "", "terraform", "Customize the provisioner backend")
+	cmd.Flags().StringVarP(&parameterFile, "parameter-file", "", "", "Specify a file path with parameter values.")
+	// This is for testing!
+	err := cmd.Flags().MarkHidden("test.provisioner")
+	if err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+// dryRunTemplateVersion plans a workspace of a template version and returns
+// the resources it would create. Workspace parameters use their defaults
+// unless they're in the parameter file.
+func dryRunTemplateVersion(cmd *cobra.Command, client *codersdk.Client, versionID uuid.UUID, parameterMapFromFile map[string]string) ([]codersdk.WorkspaceResource, error) {
+	parameterSchemas, err := client.TemplateVersionSchema(cmd.Context(), versionID)
+	if err != nil {
+		return nil, err
+	}
+	parameters := make([]codersdk.CreateParameterRequest, 0)
+	for _, parameterSchema := range parameterSchemas {
+		value, ok := parameterMapFromFile[parameterSchema.Name]
+		if !ok || !parameterSchema.AllowOverrideSource {
+			continue
+		}
+		parameters = append(parameters, codersdk.CreateParameterRequest{
+			Name:              parameterSchema.Name,
+			SourceValue:       value,
+			SourceScheme:      codersdk.ParameterSourceSchemeData,
+			DestinationScheme: parameterSchema.DefaultDestinationScheme,
+		})
+	}
+
+	after := time.Now()
+	dryRun, err := client.CreateTemplateVersionDryRun(cmd.Context(), versionID, codersdk.CreateTemplateVersionDryRunRequest{
+		WorkspaceName:   "plan",
+		ParameterValues: parameters,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("begin dry-run: %w", err)
+	}
+	err = cliui.ProvisionerJob(cmd.Context(), cmd.OutOrStdout(), cliui.ProvisionerJobOptions{
+		Fetch: func() (codersdk.ProvisionerJob, error) {
+			return client.TemplateVersionDryRun(cmd.Context(), versionID, dryRun.ID)
+		},
+		Cancel: func() error {
+			return client.CancelTemplateVersionDryRun(cmd.Context(), versionID, dryRun.ID)
+		},
+		Logs: func() (<-chan codersdk.ProvisionerJobLog, error) {
+			return client.TemplateVersionDryRunLogsAfter(cmd.Context(), versionID, dryRun.ID, after)
+		},
+		// Don't show log output for the dry-run unless there's an error.
+		Silent: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return client.TemplateVersionDryRunResources(cmd.Context(), versionID, dryRun.ID)
+}
+
+type templatePlanAction string
+
+const (
+	templatePlanAdd    templatePlanAction = "+"
+	templatePlanChange templatePlanAction = "~"
+	templatePlanRemove templatePlanAction = "-"
+)
+
+// templatePlanEntry is a resource, agent or app that differs between two
+// template versions.
+type templatePlanEntry struct {
+	Action templatePlanAction
+	Kind   string
+	Name   string
+	// Fields are the names of the changed fields.
+	Fields []string
+}
+
+// diffTemplateResources compares the resources of two template versions.
+// Resources are matched by type and name, agents by name within their
+// resource, and apps by name within their agent.
+func diffTemplateResources(previous, next []codersdk.WorkspaceResource) []templatePlanEntry {
+	previousByName := templateResourcesByName(previous)
+	nextByName := templateResourcesByName(next)
+
+	entries := make([]templatePlanEntry, 0)
+	for _, name := range unionKeys(previousByName, nextByName) {
+		previousResource, inPrevious := previousByName[name]
+		nextResource, inNext := nextByName[name]
+		switch {
+		case !inPrevious:
+			entries = append(entries, templatePlanEntry{Action: templatePlanAdd, Kind: "resource", Name: name})
+		case !inNext:
+			entries = append(entries, templatePlanEntry{Action: templatePlanRemove, Kind: "resource", Name: name})
+		default:
+			if !reflect.DeepEqual(resourceMetadataMap(previousResource), resourceMetadataMap(nextResource)) {
+				entries = append(entries, templatePlanEntry{Action: templatePlanChange, Kind: "resource", Name: name, Fields: []string{"metadata"}})
+			}
+		}
+
+		previousAgents := agentsByName(previousResource.Agents)
+		nextAgents := agentsByName(nextResource.Agents)
+		for _, agentName := range unionKeys(previousAgents, nextAgents) {
+			previousAgent, inPrevious := previousAgents[agentName]
+			nextAgent, inNext := nextAgents[agentName]
+			agentName = name + "." + agentName
+			switch {
+			case !inPrevious:
+				entries = append(entries, templatePlanEntry{Action: templatePlanAdd, Kind: "agent", Name: agentName})
+			case !inNext:
+				entries = append(entries, templatePlanEntry{Action: templatePlanRemove, Kind: "agent", Name: agentName})
+			default:
+				fields := changedAgentFields(previousAgent, nextAgent)
+				if len(fields) > 0 {
+					entries = append(entries, templatePlanEntry{Action: templatePlanChange, Kind: "agent", Name: agentName, Fields: fields})
+				}
+			}
+
+			previousApps := appsByName(previousAgent.Apps)
+			nextApps := appsByName(nextAgent.Apps)
+			for _, appName := range unionKeys(previousApps, nextApps) {
+				previousApp, inPrevious := previousApps[appName]
+				nextApp, inNext := nextApps[appName]
+				appName = agentName + "." + appName
+				switch {
+				case !inPrevious:
+					entries = append(entries, templatePlanEntry{Action: templatePlanAdd, Kind: "app", Name: appName})
+				case !inNext:
+					entries = append(entries, templatePlanEntry{Action: templatePlanRemove, Kind: "app", Name: appName})
+				default:
+					var fields []string
+					if previousApp.Command != nextApp.Command {
+						fields = append(fields, "command")
+					}
+					if previousApp.Icon != nextApp.Icon {
+						fields = append(fields, "icon")
+					}
+					if len(fields) > 0 {
+						entries = append(entries, templatePlanEntry{Action: templatePlanChange, Kind: "app", Name: appName, Fields: fields})
+					}
+				}
+			}
+		}
+	}
+	return entries
+}
+
+func writeTemplatePlan(w io.Writer, entries []templatePlanEntry) {
+	if len(entries) == 0 {
+		_, _ = fmt.Fprintln(w, cliui.Styles.Paragraph.Render("No changes. The resources match the active version."))
+		return
+	}
+	counts := map[templatePlanAction]int{}
+	for _, entry := range entries {
+		counts[entry.Action]++
+		line := fmt.Sprintf("%s %s %s", entry.Action, entry.Kind, entry.Name)
+		if len(entry.Fields) > 0 {
+			line += " (" + strings.Join(entry.Fields, ", ") + ")"
+		}
+		switch entry.Action {
+		case templatePlanAdd:
+			line = cliui.Styles.Keyword.Render(line)
+		case templatePlanChange:
+			line = cliui.Styles.Warn.Render(line)
+		case templatePlanRemove:
+			line = cliui.Styles.Error.Render(line)
+		}
+		_, _ = fmt.Fprintln(w, "  "+line)
+	}
+	_, _ = fmt.Fprintf(w, "\nPlan: %d to add, %d to change, %d to remove.\n",
+		counts[templatePlanAdd], counts[templatePlanChange], counts[templatePlanRemove])
+}
+
+// templateResourcesByName keys the resources of the start transition by type
+// and name. Resources created with count share a name, so they're numbered.
+func templateResourcesByName(resources []codersdk.WorkspaceResource) map[string]codersdk.WorkspaceResource {
+	byName := map[string]codersdk.WorkspaceResource{}
+	for _, resource := range resources {
+		if resource.Transition != codersdk.WorkspaceTransitionStart {
+			continue
+		}
+		name := resource.Type + "." + resource.Name
+		if _, exists := byName[name]; exists {
+			for index := 1; ; index++ {
+				indexed := fmt.Sprintf("%s[%d]", name, index)
+				if _, exists := byName[indexed]; !exists {
+					name = indexed
+					break
+				}
+			}
+		}
+		byName[name] = resource
+	}
+	return byName
+}
+
+func agentsByName(agents []codersdk.WorkspaceAgent) map[string]codersdk.WorkspaceAgent {
+	byName := make(map[string]codersdk.WorkspaceAgent, len(agents))
+	for _, agent := range agents {
+		byName[agent.Name] = agent
+	}
+	return byName
+}
+
+func appsByName(apps []codersdk.WorkspaceApp) map[string]codersdk.WorkspaceApp {
+	byName := make(map[string]codersdk.WorkspaceApp, len(apps))
+	for _, app := range apps {
+		byName[app.Name] = app
+	}
+	return byName
+}
+
+func resourceMetadataMap(resource codersdk.WorkspaceResource) map[string]string {
+	metadata := make(map[string]string, len(resource.Metadata))
+	for _, item := range resource.Metadata {
+		metadata[item.Key] = item.Value
+	}
+	return metadata
+}
+
+func changedAgentFields(previous, next codersdk.WorkspaceAgent) []string {
+	var fields []string
+	if previous.OperatingSystem != next.OperatingSystem {
+		fields = append(fields, "operating_system")
+	}
+	if previous.Architecture != next.Architecture {
+		fields = append(fields, "architecture")
+	}
+	if previous.Directory != next.Directory {
+		fields = append(fields, "directory")
+	}
+	if previous.StartupScript != next.StartupScript {
+		fields = append(fields, "startup_script")
+	}
+	if len(previous.EnvironmentVariables) != 0 || len(next.EnvironmentVariables) != 0 {
+		if !reflect.DeepEqual(previous.EnvironmentVariables, next.EnvironmentVariables) {
+			fields = append(fields, "environment_variables")
+		}
+	}
+	return fields
+}
+
+// unionKeys returns the keys of both maps in sorted order.
+func unionKeys[T any](a, b map[string]T) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, exists := a[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
 }
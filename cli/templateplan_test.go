@@ -0,0 +1,207 @@
+package cli_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/pty/ptytest"
+)
+
+func TestTemplatePlan(t *testing.T) {
+	t.Parallel()
+
+	provisionResources := func(resources ...*proto.Resource) *echo.Responses {
+		provision := []*proto.Provision_Response{{
+			Type: &proto.Provision_Response_Complete{
+				Complete: &proto.Provision_Complete{
+					Resources: resources,
+				},
+			},
+		}}
+		return &echo.Responses{
+			Parse:           echo.ParseComplete,
+			Provision:       provision,
+			ProvisionDryRun: provision,
+		}
+	}
+	activeResources := provisionResources(&proto.Resource{
+		Name: "dev",
+		Type: "aws_instance",
+		Agents: []*proto.Agent{{
+			Name:          "dev",
+			StartupScript: "code-server &",
+			Auth:          &proto.Agent_Token{},
+			Apps: []*proto.App{{
+				Name:    "code-server",
+				Command: "code-server",
+			}},
+		}},
+	}, &proto.Resource{
+		Name: "home",
+		Type: "aws_ebs_volume",
+	})
+
+	t.Run("Changes", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, activeResources)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		source := clitest.CreateTemplateVersionSource(t, provisionResources(&proto.Resource{
+			Name: "dev",
+			Type: "aws_instance",
+			Agents: []*proto.Agent{{
+				Name:          "dev",
+				StartupScript: "code-server --auth none &",
+				Auth:          &proto.Agent_Token{},
+			}},
+		}, &proto.Resource{
+			Name: "home",
+			Type: "google_compute_disk",
+		}))
+		cmd, root := clitest.New(t, "templates", "plan", source, "--template", template.Name, "--test.provisioner", string(database.ProvisionerTypeEcho))
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t)
+		cmd.SetIn(pty.Input())
+		cmd.SetOut(pty.Output())
+
+		execDone := make(chan error)
+		go func() {
+			execDone <- cmd.Execute()
+		}()
+		pty.ExpectMatch("- resource aws_ebs_volume.home")
+		pty.ExpectMatch("~ agent aws_instance.dev.dev (startup_script)")
+		pty.ExpectMatch("- app aws_instance.dev.dev.code-server")
+		pty.ExpectMatch("+ resource google_compute_disk.home")
+		pty.ExpectMatch("Plan: 1 to add, 1 to change, 2 to remove.")
+		require.NoError(t, <-execDone)
+
+		// The active version must not change.
+		template, err := client.Template(cmd.Context(), template.ID)
+		require.NoError(t, err)
+		require.Equal(t, version.ID, template.ActiveVersionID)
+		// Nor is the planned version added to the template.
+		versions, err := client.TemplateVersionsByTemplate(cmd.Context(), codersdk.TemplateVersionsByTemplateRequest{
+			TemplateID: template.ID,
+		})
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+	})
+
+	t.Run("NoChanges", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, activeResources)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		source := clitest.CreateTemplateVersionSource(t, activeResources)
+		cmd, root := clitest.New(t, "templates", "plan", source, "--template", template.Name, "--test.provisioner", string(database.ProvisionerTypeEcho))
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t)
+		cmd.SetIn(pty.Input())
+		cmd.SetOut(pty.Output())
+
+		execDone := make(chan error)
+		go func() {
+			execDone <- cmd.Execute()
+		}()
+		pty.ExpectMatch("No changes")
+		require.NoError(t, <-execDone)
+	})
+
+	t.Run("Variables", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		// The echo provisioner fails unless the template variable overrides
+		// the default, so planning only succeeds if the variable is
+		// inherited from the active version.
+		responses := provisionResources()
+		responses.Parse = []*proto.Parse_Response{{
+			Type: &proto.Parse_Response_Complete{
+				Complete: &proto.Parse_Complete{
+					ParameterSchemas: []*proto.ParameterSchema{{
+						Name: echo.ParameterExecKey,
+						DefaultSource: &proto.ParameterSource{
+							Scheme: proto.ParameterSource_DATA,
+							Value:  echo.ParameterError("variable not inherited"),
+						},
+						DefaultDestination: &proto.ParameterDestination{
+							Scheme: proto.ParameterDestination_PROVISIONER_VARIABLE,
+						},
+					}},
+				},
+			},
+		}}
+		data, err := echo.Tar(responses)
+		require.NoError(t, err)
+		file, err := client.Upload(context.Background(), codersdk.ContentTypeTar, data)
+		require.NoError(t, err)
+		version, err := client.CreateTemplateVersion(context.Background(), user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			StorageMethod: codersdk.ProvisionerStorageMethodFile,
+			StorageSource: file.Hash,
+			Provisioner:   codersdk.ProvisionerTypeEcho,
+			Variables: []codersdk.CreateTemplateVersionVariable{{
+				Name:      echo.ParameterExecKey,
+				Value:     echo.ParameterSucceed(),
+				Sensitive: true,
+			}},
+		})
+		require.NoError(t, err)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		source := clitest.CreateTemplateVersionSource(t, responses)
+		cmd, root := clitest.New(t, "templates", "plan", source, "--template", template.Name, "--test.provisioner", string(database.ProvisionerTypeEcho))
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t)
+		cmd.SetIn(pty.Input())
+		cmd.SetOut(pty.Output())
+
+		execDone := make(chan error)
+		go func() {
+			execDone <- cmd.Execute()
+		}()
+		pty.ExpectMatch("No changes")
+		require.NoError(t, <-execDone)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, activeResources)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		source := clitest.CreateTemplateVersionSource(t, &echo.Responses{
+			Parse: echo.ParseComplete,
+			Provision: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{
+						Error: "invalid template",
+					},
+				},
+			}},
+		})
+		cmd, root := clitest.New(t, "templates", "plan", source, "--template", template.Name, "--test.provisioner", string(database.ProvisionerTypeEcho))
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t)
+		cmd.SetIn(pty.Input())
+		cmd.SetOut(pty.Output())
+		require.Error(t, cmd.Execute())
+	})
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliflag"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func templatePrebuilds() *cobra.Command {
+	var (
+		count         int32
+		parameterFile string
+	)
+	cmd := &cobra.Command{
+		Use:   "prebuilds <template>",
+		Short: "Show or change the pool of prebuilt workspaces kept ready for a template",
+		Long: "Prebuilt workspaces are handed to users that create a workspace with exactly the " +
+			"parameter values of the pool, so they don't have to wait for a build.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			organization, err := currentOrganization(cmd, client)
+			if err != nil {
+				return err
+			}
+			template, err := client.TemplateByName(cmd.Context(), organization.ID, args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+
+			prebuilds, err := client.TemplatePrebuilds(cmd.Context(), template.ID)
+			if err != nil {
+				return xerrors.Errorf("get template prebuilds: %w", err)
+			}
+
+			if cmd.Flags().Changed("count") || parameterFile != "" {
+				req := codersdk.UpdateTemplatePrebuildsRequest{
+					Count: prebuilds.Count,
+				}
+				if cmd.Flags().Changed("count") {
+					req.Count = count
+				}
+				if parameterFile != "" {
+					req.ParameterValues, err = prebuildParameterValues(cmd, client, template, parameterFile)
+					if err != nil {
+						return err
+					}
+				}
+				prebuilds, err = client.UpdateTemplatePrebuilds(cmd.Context(), template.ID, req)
+				if err != nil {
+					return xerrors.Errorf("update template prebuilds: %w", err)
+				}
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Template %s keeps %s prebuilt workspaces ready (%d ready, %d pending).\n",
+				cliui.Styles.Keyword.Render(template.Name), cliui.Styles.Keyword.Render(fmt.Sprint(prebuilds.Count)), prebuilds.Ready, prebuilds.Pending)
+			for _, parameterValue := range prebuilds.ParameterValues {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  %s: %s\n", parameterValue.Name, cliui.Styles.Code.Render(parameterValue.SourceValue))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Int32Var(&count, "count", 0, "Specify the number of prebuilt workspaces to keep ready. Zero removes the pool.")
+	cliflag.StringVarP(cmd.Flags(), &parameterFile, "parameter-file", "", "CODER_PARAMETER_FILE", "", "Specify a file path with the parameter values prebuilt workspaces are built with.")
+	return cmd
+}
+
+// prebuildParameterValues reads the parameter values for prebuilt workspaces
+// from a file, the same way "coder create" does. Workspaces are only handed a
+// prebuilt workspace when they are created with exactly these values.
+func prebuildParameterValues(cmd *cobra.Command, client *codersdk.Client, template codersdk.Template, parameterFile string) ([]codersdk.CreateParameterRequest, error) {
+	parameterMap, err := createParameterMapFromFile(parameterFile)
+	if err != nil {
+		return nil, err
+	}
+	parameterSchemas, err := client.TemplateVersionSchema(cmd.Context(), template.ActiveVersionID)
+	if err != nil {
+		return nil, xerrors.Errorf("get template version schema: %w", err)
+	}
+
+	parameterValues := make([]codersdk.CreateParameterRequest, 0)
+	for _, parameterSchema := range parameterSchemas {
+		if !parameterSchema.AllowOverrideSource {
+			continue
+		}
+		parameterValue, err := getParameterValueFromMapOrInput(cmd, parameterMap, parameterSchema)
+		if err != nil {
+			return nil, err
+		}
+		parameterValues = append(parameterValues, codersdk.CreateParameterRequest{
+			Name:              parameterSchema.Name,
+			SourceValue:       parameterValue,
+			SourceScheme:      codersdk.ParameterSourceSchemeData,
+			DestinationScheme: parameterSchema.DefaultDestinationScheme,
+		})
+	}
+	return parameterValues, nil
+}
@@ -0,0 +1,55 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+)
+
+func TestTemplatePrebuilds(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Show", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		_, err := client.UpdateTemplatePrebuilds(context.Background(), template.ID, codersdk.UpdateTemplatePrebuildsRequest{Count: 2})
+		require.NoError(t, err)
+
+		cmd, root := clitest.New(t, "templates", "prebuilds", template.Name)
+		clitest.SetupConfig(t, client, root)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+
+		err = cmd.Execute()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "keeps 2 prebuilt workspaces ready")
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		cmd, root := clitest.New(t, "templates", "prebuilds", template.Name, "--count", "3")
+		clitest.SetupConfig(t, client, root)
+
+		err := cmd.Execute()
+		require.NoError(t, err)
+
+		prebuilds, err := client.TemplatePrebuilds(context.Background(), template.ID)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, prebuilds.Count)
+	})
+}
@@ -22,6 +22,8 @@ func templatePush() *cobra.Command {
 		provisioner   string
 		parameterFile string
 		alwaysPrompt  bool
+		message       string
+		variableFlags templateVariableFlags
 	)
 
 	cmd := &cobra.Command{
@@ -48,6 +50,11 @@ func templatePush() *cobra.Command {
 				return err
 			}
 
+			variables, err := variableFlags.parse(directory)
+			if err != nil {
+				return err
+			}
+
 			// Confirm upload of the directory.
 			prettyDir := prettyDirectoryPath(directory)
 			_, err = cliui.Prompt(cmd, cliui.PromptOptions{
@@ -74,6 +81,14 @@ func templatePush() *cobra.Command {
 			}
 			spin.Stop()
 
+			var git *codersdk.TemplateVersionGitMetadata
+			if commit, ok := readTemplateGitCommit(cmd.Context(), directory); ok {
+				git = &commit.Metadata
+				if message == "" {
+					message = commit.Subject
+				}
+			}
+
 			job, _, err := createValidTemplateVersion(cmd, createValidTemplateVersionArgs{
 				Client:          client,
 				Organization:    organization,
@@ -82,6 +97,9 @@ func templatePush() *cobra.Command {
 				ParameterFile:   parameterFile,
 				Template:        &template,
 				ReuseParameters: !alwaysPrompt,
+				Message:         message,
+				Git:             git,
+				Variables:       variables,
 			})
 			if err != nil {
 				return err
@@ -107,7 +125,9 @@ func templatePush() *cobra.Command {
 	cmd.Flags().StringVarP(&directory, "directory", "d", currentDirectory, "Specify the directory to create from")
 	cmd.Flags().StringVarP(&provisioner, "test.provisioner", "", "terraform", "Customize the provisioner backend")
 	cmd.Flags().StringVarP(&parameterFile, "parameter-file", "", "", "Specify a file path with parameter values.")
+	cmd.Flags().StringVarP(&message, "message", "m", "", "Describe the changes in this version. Defaults to the subject of the current git commit.")
 	cmd.Flags().BoolVar(&alwaysPrompt, "always-prompt", false, "Always prompt all parameters. Does not pull parameter values from active template version")
+	variableFlags.register(cmd)
 	cliui.AllowSkipPrompt(cmd)
 	// This is for testing!
 	err := cmd.Flags().MarkHidden("test.provisioner")
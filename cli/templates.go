@@ -21,6 +21,10 @@ func templates() *cobra.Command {
 				Description: "Create a template for developers to create workspaces",
 				Command:     "coder templates create",
 			},
+			example{
+				Description: "Find mistakes in a template before pushing it",
+				Command:     "coder templates lint",
+			},
 			example{
 				Description: "Make changes to your template, and plan the changes",
 				Command:     "coder templates plan my-template",
@@ -34,13 +38,19 @@ func templates() *cobra.Command {
 	cmd.AddCommand(
 		templateCreate(),
 		templateEdit(),
+		templateGitSource(),
 		templateInit(),
+		templateInsights(),
+		templateLint(),
 		templateList(),
+		templateMirror(),
 		templatePlan(),
+		templatePrebuilds(),
 		templatePush(),
 		templateVersions(),
 		templateDelete(),
 		templatePull(),
+		templateMove(),
 	)
 
 	return cmd
@@ -56,6 +66,9 @@ type templateTableRow struct {
 	UsedBy               string                   `table:"used by"`
 	MaxTTL               time.Duration            `table:"max ttl"`
 	MinAutostartInterval time.Duration            `table:"min autostart"`
+	InactivityTTL        time.Duration            `table:"inactivity ttl"`
+	DormantAutoDeleteTTL time.Duration            `table:"dormant autodelete ttl"`
+	MaintenanceWindow    string                   `table:"maintenance window"`
 }
 
 // displayTemplates will return a table displaying all templates passed in.
@@ -79,8 +92,22 @@ func displayTemplates(filterColumns []string, templates ...codersdk.Template) (s
 			UsedBy:               cliui.Styles.Fuchsia.Render(fmt.Sprintf("%d developer%s", template.WorkspaceOwnerCount, suffix)),
 			MaxTTL:               (time.Duration(template.MaxTTLMillis) * time.Millisecond),
 			MinAutostartInterval: (time.Duration(template.MinAutostartIntervalMillis) * time.Millisecond),
+			InactivityTTL:        (time.Duration(template.InactivityTTLMillis) * time.Millisecond),
+			DormantAutoDeleteTTL: (time.Duration(template.DormantAutoDeleteTTLMillis) * time.Millisecond),
+			MaintenanceWindow:    maintenanceWindowDisplay(template),
 		}
 	}
 
 	return cliui.DisplayTable(rows, "name", filterColumns)
 }
+
+func maintenanceWindowDisplay(template codersdk.Template) string {
+	if template.MaintenanceWindow == "" {
+		return "-"
+	}
+	display := fmt.Sprintf("%s for %s", template.MaintenanceWindow, time.Duration(template.MaintenanceWindowMillis)*time.Millisecond)
+	if template.MaintenanceRestartOutdated {
+		display += " (restarts outdated)"
+	}
+	return display
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/codersdk"
+)
+
+// templateVariableFlags are the flags that set template variables when a
+// template version is created.
+type templateVariableFlags struct {
+	variables          []string
+	sensitiveVariables []string
+}
+
+func (f *templateVariableFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&f.variables, "var", nil, "Set a template variable in the form name=value. Variables of the active version that aren't set are kept. Can be specified multiple times.")
+	cmd.Flags().StringArrayVar(&f.sensitiveVariables, "sensitive-var", nil, "Set a template variable in the form name=value whose value is never shown. Can be specified multiple times.")
+}
+
+// parse validates the variables against the template in directory, so typos
+// are caught before anything is uploaded.
+func (f *templateVariableFlags) parse(directory string) ([]codersdk.CreateTemplateVersionVariable, error) {
+	if len(f.variables) == 0 && len(f.sensitiveVariables) == 0 {
+		return nil, nil
+	}
+	module, diags := tfconfig.LoadModule(directory)
+	if diags.HasErrors() {
+		return nil, xerrors.Errorf("load template: %w", diags.Err())
+	}
+
+	variables := make([]codersdk.CreateTemplateVersionVariable, 0, len(f.variables)+len(f.sensitiveVariables))
+	add := func(raw string, sensitive bool) error {
+		name, value, ok := strings.Cut(raw, "=")
+		if !ok || name == "" {
+			return xerrors.Errorf("variable %q must be in the form name=value", raw)
+		}
+		if _, ok := module.Variables[name]; !ok {
+			return xerrors.Errorf("variable %q isn't declared by the template", name)
+		}
+		for _, variable := range variables {
+			if variable.Name == name {
+				return xerrors.Errorf("variable %q is set more than once", name)
+			}
+		}
+		variables = append(variables, codersdk.CreateTemplateVersionVariable{
+			Name:      name,
+			Value:     value,
+			Sensitive: sensitive,
+		})
+		return nil
+	}
+	for _, raw := range f.variables {
+		if err := add(raw, false); err != nil {
+			return nil, err
+		}
+	}
+	for _, raw := range f.sensitiveVariables {
+		if err := add(raw, true); err != nil {
+			return nil, err
+		}
+	}
+	return variables, nil
+}
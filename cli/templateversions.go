@@ -23,10 +23,15 @@ func templateVersions() *cobra.Command {
 				Description: "List versions of a specific template",
 				Command:     "coder templates versions list my-template",
 			},
+			example{
+				Description: "Show the source changes between two versions of a template",
+				Command:     "coder templates versions diff my-template old-version new-version",
+			},
 		),
 	}
 	cmd.AddCommand(
 		templateVersionsList(),
+		templateVersionsDiff(),
 	)
 
 	return cmd
@@ -74,6 +79,7 @@ type templateVersionRow struct {
 	Name      string    `table:"name"`
 	CreatedAt time.Time `table:"created at"`
 	CreatedBy string    `table:"created by"`
+	Message   string    `table:"message"`
 	Status    string    `table:"status"`
 	Active    string    `table:"active"`
 }
@@ -88,10 +94,14 @@ func displayTemplateVersions(activeVersionID uuid.UUID, templateVersions ...code
 			activeStatus = cliui.Styles.Code.Render(cliui.Styles.Keyword.Render("Active"))
 		}
 
+		// Only the first line of the message fits in the table.
+		message, _, _ := strings.Cut(templateVersion.Message, "\n")
+
 		rows[i] = templateVersionRow{
 			Name:      templateVersion.Name,
 			CreatedAt: templateVersion.CreatedAt,
 			CreatedBy: templateVersion.CreatedByName,
+			Message:   message,
 			Status:    strings.Title(string(templateVersion.Job.Status)),
 			Active:    activeStatus,
 		}
@@ -99,3 +109,50 @@ func displayTemplateVersions(activeVersionID uuid.UUID, templateVersions ...code
 
 	return cliui.DisplayTable(rows, "name", nil)
 }
+
+func templateVersionsDiff() *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff <template> <from-version> <to-version>",
+		Args:  cobra.ExactArgs(3),
+		Short: "Show the source changes between two versions of the specified template",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create client: %w", err)
+			}
+			organization, err := currentOrganization(cmd, client)
+			if err != nil {
+				return xerrors.Errorf("get current organization: %w", err)
+			}
+			template, err := client.TemplateByName(cmd.Context(), organization.ID, args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+			from, err := client.TemplateVersionByName(cmd.Context(), template.ID, args[1])
+			if err != nil {
+				return xerrors.Errorf("get template version %q: %w", args[1], err)
+			}
+			to, err := client.TemplateVersionByName(cmd.Context(), template.ID, args[2])
+			if err != nil {
+				return xerrors.Errorf("get template version %q: %w", args[2], err)
+			}
+
+			diff, err := client.TemplateVersionDiff(cmd.Context(), from.ID, to.ID)
+			if err != nil {
+				return xerrors.Errorf("diff template versions: %w", err)
+			}
+			if len(diff.Files) == 0 {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "No source changes between %s and %s.\n", from.Name, to.Name)
+				return nil
+			}
+			for _, file := range diff.Files {
+				if file.Binary {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Binary file %s %s\n", file.Path, file.Status)
+					continue
+				}
+				_, _ = fmt.Fprint(cmd.OutOrStdout(), file.Diff)
+			}
+			return nil
+		},
+	}
+}
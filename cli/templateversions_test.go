@@ -1,12 +1,16 @@
 package cli_test
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/cli/clitest"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/pty/ptytest"
 )
 
@@ -38,4 +42,37 @@ func TestTemplateVersions(t *testing.T) {
 		pty.ExpectMatch(version.CreatedByName)
 		pty.ExpectMatch("Active")
 	})
+	t.Run("Diff", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		source := clitest.CreateTemplateVersionSource(t, nil)
+		err := os.WriteFile(filepath.Join(source, "main.tf"), []byte("resource \"null_resource\" \"dev\" {}\n"), 0o600)
+		require.NoError(t, err)
+		cmd, root := clitest.New(t, "templates", "push", template.Name, "-y", "--directory", source, "--test.provisioner", string(database.ProvisionerTypeEcho))
+		clitest.SetupConfig(t, client, root)
+		require.NoError(t, cmd.Execute())
+		template, err = client.Template(context.Background(), template.ID)
+		require.NoError(t, err)
+		newVersion, err := client.TemplateVersion(context.Background(), template.ActiveVersionID)
+		require.NoError(t, err)
+
+		cmd, root = clitest.New(t, "templates", "versions", "diff", template.Name, version.Name, newVersion.Name)
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t)
+		cmd.SetIn(pty.Input())
+		cmd.SetOut(pty.Output())
+
+		errC := make(chan error)
+		go func() {
+			errC <- cmd.Execute()
+		}()
+		pty.ExpectMatch("+++ b/main.tf")
+		pty.ExpectMatch(`+resource "null_resource" "dev" {}`)
+		require.NoError(t, <-errC)
+	})
 }
@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func terminals() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "terminals",
+		Short: "Share web terminals of a workspace with other users",
+		Long: "Web terminals are identified by the \"reconnect\" query parameter of their URL. " +
+			"Shared terminals can be joined by the users they're shared with until the share expires " +
+			"or is revoked.",
+		Aliases: []string{"terminal"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(terminalShare(), terminalList(), terminalRevoke(), terminalJoin())
+	return cmd
+}
+
+func terminalShare() *cobra.Command {
+	var (
+		write     bool
+		usernames []string
+		ttl       time.Duration
+	)
+	cmd := &cobra.Command{
+		Use: "share <workspace> <terminal-id>",
+		Example: formatExamples(
+			example{
+				Description: "Let two users watch a terminal for the next 30 minutes",
+				Command:     "coder terminals share my-workspace <terminal-id> --user alice --user bob --ttl 30m",
+			},
+		),
+		Short: "Share a web terminal of a workspace",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reconnectID, err := uuid.Parse(args[1])
+			if err != nil {
+				return xerrors.Errorf("%q must be a terminal id", args[1])
+			}
+			if len(usernames) == 0 {
+				return xerrors.New("at least one user to share the terminal with must be specified with --user")
+			}
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			_, workspaceAgent, err := getWorkspaceAndAgent(cmd.Context(), cmd, client, codersdk.Me, args[0], false)
+			if err != nil {
+				return err
+			}
+			recipients := make([]uuid.UUID, 0, len(usernames))
+			for _, username := range usernames {
+				user, err := client.User(cmd.Context(), username)
+				if err != nil {
+					return xerrors.Errorf("get user %q: %w", username, err)
+				}
+				recipients = append(recipients, user.ID)
+			}
+			mode, access := codersdk.TerminalShareModeReadOnly, "read-only"
+			if write {
+				mode, access = codersdk.TerminalShareModeReadWrite, "read-write"
+			}
+			share, err := client.CreateTerminalShare(cmd.Context(), workspaceAgent.ID, codersdk.CreateTerminalShareRequest{
+				ReconnectID: reconnectID,
+				Mode:        mode,
+				Recipients:  recipients,
+				TTLMillis:   ttl.Milliseconds(),
+			})
+			if err != nil {
+				return xerrors.Errorf("create terminal share: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Shared the terminal %s until %s. %s can join it with:\n\n%s\n",
+				cliui.Styles.Keyword.Render(access), share.ExpiresAt.Local().Format(time.Stamp),
+				strings.Join(usernames, ", "), cliui.Styles.Code.Render("coder terminals join "+share.ID.String()))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&write, "write", false, "Allow users joining the terminal to type into it.")
+	cmd.Flags().StringArrayVarP(&usernames, "user", "u", nil, "A user to share the terminal with. Can be specified multiple times.")
+	cmd.Flags().DurationVar(&ttl, "ttl", time.Hour, "How long the share lasts, at most 24h.")
+	return cmd
+}
+
+type terminalShareTableRow struct {
+	ID         uuid.UUID                  `table:"id"`
+	Terminal   uuid.UUID                  `table:"terminal"`
+	Mode       codersdk.TerminalShareMode `table:"mode"`
+	CreatedAt  string                     `table:"created at"`
+	ExpiresAt  string                     `table:"expires at"`
+	SharedWith string                     `table:"shared with"`
+	Viewers    string                     `table:"viewers"`
+}
+
+func terminalList() *cobra.Command {
+	var columns []string
+	cmd := &cobra.Command{
+		Use:     "list <workspace>",
+		Short:   "List the shared terminals of a workspace and who is viewing them",
+		Aliases: []string{"ls"},
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			workspace, workspaceAgent, err := getWorkspaceAndAgent(cmd.Context(), cmd, client, codersdk.Me, args[0], false)
+			if err != nil {
+				return err
+			}
+			shares, err := client.TerminalShares(cmd.Context(), workspaceAgent.ID)
+			if err != nil {
+				return xerrors.Errorf("get terminal shares: %w", err)
+			}
+			if len(shares) == 0 {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s has no shared terminals.\n", cliui.Styles.Keyword.Render(workspace.Name))
+				return nil
+			}
+
+			users, err := client.Users(cmd.Context(), codersdk.UsersRequest{})
+			if err != nil {
+				return xerrors.Errorf("get users: %w", err)
+			}
+			usernames := make(map[uuid.UUID]string, len(users))
+			for _, user := range users {
+				usernames[user.ID] = user.Username
+			}
+
+			rows := make([]terminalShareTableRow, 0, len(shares))
+			for _, share := range shares {
+				sharedWith := make([]string, 0, len(share.Recipients))
+				for _, recipient := range share.Recipients {
+					sharedWith = append(sharedWith, usernames[recipient])
+				}
+				viewers := make([]string, 0, len(share.Viewers))
+				for _, viewer := range share.Viewers {
+					viewers = append(viewers, viewer.Username)
+				}
+				rows = append(rows, terminalShareTableRow{
+					ID:         share.ID,
+					Terminal:   share.ReconnectID,
+					Mode:       share.Mode,
+					CreatedAt:  share.CreatedAt.Format(time.Stamp),
+					ExpiresAt:  share.ExpiresAt.Format(time.Stamp),
+					SharedWith: strings.Join(sharedWith, ", "),
+					Viewers:    strings.Join(viewers, ", "),
+				})
+			}
+			out, err := cliui.DisplayTable(rows, "", columns)
+			if err != nil {
+				return xerrors.Errorf("render table: %w", err)
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), out)
+			return err
+		},
+	}
+	cmd.Flags().StringArrayVarP(&columns, "column", "c", nil,
+		"Specify a column to filter in the table. Available columns are: id, terminal, mode, created at, expires at, shared with, viewers.")
+	return cmd
+}
+
+func terminalRevoke() *cobra.Command {
+	return &cobra.Command{
+		Use:     "revoke <share-id>",
+		Short:   "Revoke a terminal share and disconnect its viewers",
+		Aliases: []string{"rm"},
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shareID, err := uuid.Parse(args[0])
+			if err != nil {
+				return xerrors.Errorf("%q must be a share id", args[0])
+			}
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			err = client.DeleteTerminalShare(cmd.Context(), shareID)
+			if err != nil {
+				return xerrors.Errorf("revoke terminal share: %w", err)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Revoked the terminal share %s.\n", cliui.Styles.Keyword.Render(shareID.String()))
+			return nil
+		},
+	}
+}
+
+func terminalJoin() *cobra.Command {
+	return &cobra.Command{
+		Use:   "join <share-id>",
+		Short: "Join a terminal shared by another user",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shareID, err := uuid.Parse(args[0])
+			if err != nil {
+				return xerrors.Errorf("%q must be a share id", args[0])
+			}
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			share, err := client.TerminalShare(cmd.Context(), shareID)
+			if err != nil {
+				return xerrors.Errorf("get terminal share: %w", err)
+			}
+			conn, err := client.TerminalSharePTY(cmd.Context(), shareID)
+			if err != nil {
+				return xerrors.Errorf("join terminal: %w", err)
+			}
+			defer conn.Close()
+
+			if share.Mode == codersdk.TerminalShareModeReadWrite {
+				// Keystrokes are sent to the shared terminal as they are typed,
+				// so interrupts reach the remote shell like they do for ssh.
+				stdoutFile, validOut := cmd.OutOrStdout().(*os.File)
+				stdinFile, validIn := cmd.InOrStdin().(*os.File)
+				if validOut && validIn && isatty.IsTerminal(stdoutFile.Fd()) {
+					state, err := term.MakeRaw(int(stdinFile.Fd()))
+					if err != nil {
+						return err
+					}
+					defer func() {
+						_ = term.Restore(int(stdinFile.Fd()), state)
+					}()
+				}
+				go func() {
+					encoder := json.NewEncoder(conn)
+					buffer := make([]byte, 1024)
+					for {
+						n, err := cmd.InOrStdin().Read(buffer)
+						if err != nil {
+							return
+						}
+						err = encoder.Encode(agent.ReconnectingPTYRequest{
+							Data: string(buffer[:n]),
+						})
+						if err != nil {
+							return
+						}
+					}
+				}()
+			} else {
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Joined a read-only terminal. Press Ctrl+C to leave.")
+			}
+
+			_, err = io.Copy(cmd.OutOrStdout(), conn)
+			return err
+		},
+	}
+}
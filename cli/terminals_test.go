@@ -0,0 +1,65 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTerminals(t *testing.T) {
+	t.Parallel()
+
+	client, workspace, _ := setupWorkspaceForSSH(t)
+	reconnectID := uuid.New()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	template, err := client.Template(ctx, workspace.TemplateID)
+	require.NoError(t, err)
+	_, viewer := coderdtest.CreateAnotherUserWithUser(t, client, template.OrganizationID)
+
+	cmd, root := clitest.New(t, "terminals", "share", workspace.Name, reconnectID.String(), "--write", "--user", viewer.Username)
+	clitest.SetupConfig(t, client, root)
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	err = cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "read-write")
+
+	resources, err := client.WorkspaceResourcesByBuild(ctx, workspace.LatestBuild.ID)
+	require.NoError(t, err)
+	shares, err := client.TerminalShares(ctx, resources[0].Agents[0].ID)
+	require.NoError(t, err)
+	require.Len(t, shares, 1)
+	require.Equal(t, reconnectID, shares[0].ReconnectID)
+	require.Equal(t, codersdk.TerminalShareModeReadWrite, shares[0].Mode)
+	require.Equal(t, []uuid.UUID{viewer.ID}, shares[0].Recipients)
+	require.Contains(t, buf.String(), "coder terminals join "+shares[0].ID.String())
+
+	cmd, root = clitest.New(t, "terminals", "list", workspace.Name)
+	clitest.SetupConfig(t, client, root)
+	buf = new(bytes.Buffer)
+	cmd.SetOut(buf)
+	err = cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), shares[0].ID.String())
+	require.Contains(t, buf.String(), reconnectID.String())
+
+	cmd, root = clitest.New(t, "terminals", "revoke", shares[0].ID.String())
+	clitest.SetupConfig(t, client, root)
+	err = cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+
+	shares, err = client.TerminalShares(ctx, resources[0].Agents[0].ID)
+	require.NoError(t, err)
+	require.Empty(t, shares)
+}
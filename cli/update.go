@@ -47,11 +47,24 @@ func update() *cobra.Command {
 				}
 			}
 
+			// The preset the workspace was created with sets its values in the
+			// new version too. Presets that were removed from the template
+			// leave the existing values alone.
+			var preset *codersdk.TemplateVersionPreset
+			if workspace.Preset != "" && parameterFile == "" {
+				presets, err := client.TemplateVersionPresets(cmd.Context(), template.ActiveVersionID)
+				if err != nil {
+					return err
+				}
+				preset = findTemplatePreset(presets, workspace.Preset)
+			}
+
 			parameters, err := prepWorkspaceBuild(cmd, client, prepWorkspaceBuildArgs{
 				Template:         template,
 				ExistingParams:   existingParams,
 				ParameterFile:    parameterFile,
 				NewWorkspaceName: workspace.Name,
+				Preset:           preset,
 			})
 			if err != nil {
 				return nil
@@ -55,7 +55,7 @@ func wireguardPortForward() *cobra.Command {
 			ctx, cancel := context.WithCancel(cmd.Context())
 			defer cancel()
 
-			specs, err := parsePortForwards(tcpForwards, nil, nil)
+			specs, err := parsePortForwards(tcpForwards, nil, nil, nil)
 			if err != nil {
 				return xerrors.Errorf("parse port-forward specs: %w", err)
 			}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -140,6 +141,19 @@ func convertDiffType(left, right any) (newLeft, newRight any, changed bool) {
 
 		return leftInt64Ptr, rightInt64Ptr, true
 
+	case sql.NullTime:
+		leftStr := typed.Time.Format(time.RFC3339Nano)
+		if !typed.Valid {
+			leftStr = "null"
+		}
+
+		rightStr := right.(sql.NullTime).Time.Format(time.RFC3339Nano)
+		if !right.(sql.NullTime).Valid {
+			rightStr = "null"
+		}
+
+		return leftStr, rightStr, true
+
 	default:
 		return left, right, false
 	}
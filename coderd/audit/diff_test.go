@@ -213,6 +213,22 @@ func TestDiff(t *testing.T) {
 				"name":        "rust workspace",
 			},
 		},
+		{
+			name: "Dormant",
+			left: database.Workspace{
+				ID:   uuid.UUID{1},
+				Name: "rust workspace",
+			},
+			right: database.Workspace{
+				ID:         uuid.UUID{1},
+				Name:       "rust workspace",
+				LastUsedAt: time.Now(),
+				DormantAt:  sql.NullTime{Time: time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+			},
+			exp: audit.Map{
+				"dormant_at": "2022-08-01T00:00:00Z",
+			},
+		},
 	})
 }
 
@@ -61,19 +61,27 @@ var AuditableResources = auditMap(map[any]map[string]Action{
 		"updated_at":  ActionIgnore, // Changes, but is implicit and not helpful in a diff.
 	},
 	&database.Template{}: {
-		"id":                     ActionTrack,
-		"created_at":             ActionIgnore, // Never changes, but is implicit and not helpful in a diff.
-		"updated_at":             ActionIgnore, // Changes, but is implicit and not helpful in a diff.
-		"organization_id":        ActionTrack,
-		"deleted":                ActionIgnore, // Changes, but is implicit when a delete event is fired.
-		"name":                   ActionTrack,
-		"provisioner":            ActionTrack,
-		"active_version_id":      ActionTrack,
-		"description":            ActionTrack,
-		"icon":                   ActionTrack,
-		"max_ttl":                ActionTrack,
-		"min_autostart_interval": ActionTrack,
-		"created_by":             ActionTrack,
+		"id":                           ActionTrack,
+		"created_at":                   ActionIgnore, // Never changes, but is implicit and not helpful in a diff.
+		"updated_at":                   ActionIgnore, // Changes, but is implicit and not helpful in a diff.
+		"organization_id":              ActionTrack,
+		"deleted":                      ActionIgnore, // Changes, but is implicit when a delete event is fired.
+		"name":                         ActionTrack,
+		"provisioner":                  ActionTrack,
+		"active_version_id":            ActionTrack,
+		"description":                  ActionTrack,
+		"icon":                         ActionTrack,
+		"max_ttl":                      ActionTrack,
+		"min_autostart_interval":       ActionTrack,
+		"created_by":                   ActionTrack,
+		"inactivity_ttl":               ActionTrack,
+		"dormant_autodelete_ttl":       ActionTrack,
+		"maintenance_window":           ActionTrack,
+		"maintenance_window_duration":  ActionTrack,
+		"maintenance_restart_outdated": ActionTrack,
+		"prebuild_count":               ActionTrack,
+		"prebuild_parameters":          ActionTrack,
+		"record_sessions":              ActionTrack,
 	},
 	&database.TemplateVersion{}: {
 		"id":              ActionTrack,
@@ -85,6 +93,9 @@ var AuditableResources = auditMap(map[any]map[string]Action{
 		"readme":          ActionTrack,
 		"job_id":          ActionIgnore, // Not helpful in a diff because jobs aren't tracked in audit logs.
 		"created_by":      ActionTrack,
+		"message":         ActionTrack,
+		"git_metadata":    ActionIgnore, // Recorded with the version and never changes.
+		"diagnostics":     ActionIgnore, // Set by the import job, which isn't tracked in audit logs.
 	},
 	&database.User{}: {
 		"id":              ActionTrack,
@@ -108,6 +119,10 @@ var AuditableResources = auditMap(map[any]map[string]Action{
 		"name":               ActionTrack,
 		"autostart_schedule": ActionTrack,
 		"ttl":                ActionTrack,
+		"last_used_at":       ActionIgnore, // Changes, but is implicit and not helpful in a diff.
+		"dormant_at":         ActionTrack,
+		"prebuild":           ActionTrack,
+		"preset":             ActionTrack,
 	},
 })
 
@@ -2,42 +2,53 @@ package executor
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"sort"
+	"strings"
 	"time"
 
 	"cdr.dev/slog"
 
 	"github.com/coder/coder/coderd/autobuild/schedule"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/prebuild"
 
 	"github.com/google/uuid"
 	"github.com/moby/moby/pkg/namesgenerator"
 	"golang.org/x/xerrors"
 )
 
-// Executor automatically starts or stops workspaces.
+// Executor automatically starts or stops workspaces, and enforces the
+// dormancy and maintenance policies of their templates.
 type Executor struct {
 	ctx     context.Context
 	db      database.Store
 	log     slog.Logger
 	tick    <-chan time.Time
 	statsCh chan<- Stats
+	// prebuildBackoffs are keyed by template ID. They're only accessed by
+	// the goroutine that runs the executor.
+	prebuildBackoffs map[uuid.UUID]*prebuildBackoff
 }
 
 // Stats contains information about one run of Executor.
 type Stats struct {
 	Transitions map[uuid.UUID]database.WorkspaceTransition
-	Elapsed     time.Duration
-	Error       error
+	// Dormant contains the workspaces that were marked dormant.
+	Dormant []uuid.UUID
+	Elapsed time.Duration
+	Error   error
 }
 
 // New returns a new autobuild executor.
 func New(ctx context.Context, db database.Store, log slog.Logger, tick <-chan time.Time) *Executor {
 	le := &Executor{
-		ctx:  ctx,
-		db:   db,
-		tick: tick,
-		log:  log,
+		ctx:              ctx,
+		db:               db,
+		tick:             tick,
+		log:              log,
+		prebuildBackoffs: map[uuid.UUID]*prebuildBackoff{},
 	}
 	return le
 }
@@ -73,7 +84,7 @@ func (e *Executor) Run() {
 					case e.statsCh <- stats:
 					}
 				}
-				e.log.Debug(e.ctx, "run stats", slog.F("elapsed", stats.Elapsed), slog.F("transitions", stats.Transitions))
+				e.log.Debug(e.ctx, "run stats", slog.F("elapsed", stats.Elapsed), slog.F("transitions", stats.Transitions), slog.F("dormant", stats.Dormant))
 			}
 		}
 	}()
@@ -144,13 +155,49 @@ func (e *Executor) runOnce(t time.Time) Stats {
 				continue
 			}
 
+			var reason database.BuildReason
+			switch validTransition {
+			case database.WorkspaceTransitionStart:
+				reason = database.BuildReasonAutostart
+			case database.WorkspaceTransitionStop:
+				reason = database.BuildReasonAutostop
+				// Templates with a maintenance window defer autostops until
+				// the window opens.
+				template, err := db.GetTemplateByID(e.ctx, ws.TemplateID)
+				if err != nil {
+					e.log.Warn(e.ctx, "get workspace template",
+						slog.F("workspace_id", ws.ID),
+						slog.Error(err),
+					)
+					continue
+				}
+				if template.MaintenanceWindow != "" {
+					inWindow, err := inMaintenanceWindow(template, currentTick)
+					if err != nil {
+						e.log.Warn(e.ctx, "check template maintenance window",
+							slog.F("workspace_id", ws.ID),
+							slog.F("template_id", template.ID),
+							slog.Error(err),
+						)
+						continue
+					}
+					if !inWindow {
+						e.log.Debug(e.ctx, "deferring autostop until maintenance window",
+							slog.F("workspace_id", ws.ID),
+							slog.F("current_tick", currentTick),
+						)
+						continue
+					}
+				}
+			}
+
 			e.log.Info(e.ctx, "scheduling workspace transition",
 				slog.F("workspace_id", ws.ID),
 				slog.F("transition", validTransition),
 			)
 
 			stats.Transitions[ws.ID] = validTransition
-			if err := build(e.ctx, db, ws, validTransition, priorHistory, priorJob); err != nil {
+			if err := build(e.ctx, db, ws, validTransition, reason, priorHistory, priorJob); err != nil {
 				e.log.Error(e.ctx, "unable to transition workspace",
 					slog.F("workspace_id", ws.ID),
 					slog.F("transition", validTransition),
@@ -158,11 +205,471 @@ func (e *Executor) runOnce(t time.Time) Stats {
 				)
 			}
 		}
+
+		// Templates may mark stopped workspaces dormant after a period of
+		// inactivity, and delete dormant workspaces after another period.
+		dormancyWorkspaces, err := db.GetWorkspacesDormancy(e.ctx)
+		if err != nil {
+			return xerrors.Errorf("get eligible workspaces for dormancy: %w", err)
+		}
+
+		for _, ws := range dormancyWorkspaces {
+			template, err := db.GetTemplateByID(e.ctx, ws.TemplateID)
+			if err != nil {
+				e.log.Warn(e.ctx, "get workspace template",
+					slog.F("workspace_id", ws.ID),
+					slog.Error(err),
+				)
+				continue
+			}
+
+			priorHistory, err := db.GetLatestWorkspaceBuildByWorkspaceID(e.ctx, ws.ID)
+			if err != nil {
+				e.log.Warn(e.ctx, "get latest workspace build",
+					slog.F("workspace_id", ws.ID),
+					slog.Error(err),
+				)
+				continue
+			}
+
+			priorJob, err := db.GetProvisionerJobByID(e.ctx, priorHistory.JobID)
+			if err != nil {
+				e.log.Warn(e.ctx, "get last provisioner job for workspace",
+					slog.F("workspace_id", ws.ID),
+					slog.Error(err),
+				)
+				continue
+			}
+
+			if !ws.DormantAt.Valid {
+				dormantAt, err := getDormantAt(ws, template, priorHistory, priorJob)
+				if err != nil {
+					e.log.Debug(e.ctx, "skipping workspace dormancy",
+						slog.Error(err),
+						slog.F("workspace_id", ws.ID),
+					)
+					continue
+				}
+				if currentTick.Before(dormantAt) {
+					continue
+				}
+
+				e.log.Info(e.ctx, "marking workspace dormant",
+					slog.F("workspace_id", ws.ID),
+					slog.F("last_used_at", ws.LastUsedAt),
+				)
+				err = db.UpdateWorkspaceDormantAt(e.ctx, database.UpdateWorkspaceDormantAtParams{
+					ID:        ws.ID,
+					DormantAt: sql.NullTime{Time: database.Now(), Valid: true},
+				})
+				if err != nil {
+					e.log.Error(e.ctx, "unable to mark workspace dormant",
+						slog.F("workspace_id", ws.ID),
+						slog.Error(err),
+					)
+					continue
+				}
+				stats.Dormant = append(stats.Dormant, ws.ID)
+				continue
+			}
+
+			deletingAt, err := getDeletingAt(ws, template, priorHistory, priorJob)
+			if err != nil {
+				e.log.Debug(e.ctx, "skipping dormant workspace",
+					slog.Error(err),
+					slog.F("workspace_id", ws.ID),
+				)
+				continue
+			}
+			if currentTick.Before(deletingAt) {
+				continue
+			}
+
+			e.log.Info(e.ctx, "deleting dormant workspace",
+				slog.F("workspace_id", ws.ID),
+				slog.F("dormant_at", ws.DormantAt.Time),
+			)
+
+			stats.Transitions[ws.ID] = database.WorkspaceTransitionDelete
+			if err := build(e.ctx, db, ws, database.WorkspaceTransitionDelete, database.BuildReasonAutodelete, priorHistory, priorJob); err != nil {
+				e.log.Error(e.ctx, "unable to delete dormant workspace",
+					slog.F("workspace_id", ws.ID),
+					slog.Error(err),
+				)
+			}
+		}
+
+		// Templates may restart outdated workspaces on the active template
+		// version during their maintenance window.
+		maintenanceWorkspaces, err := db.GetWorkspacesMaintenance(e.ctx)
+		if err != nil {
+			return xerrors.Errorf("get eligible workspaces for maintenance: %w", err)
+		}
+
+		for _, ws := range maintenanceWorkspaces {
+			if _, ok := stats.Transitions[ws.ID]; ok {
+				// Already transitioned on this tick.
+				continue
+			}
+
+			template, err := db.GetTemplateByID(e.ctx, ws.TemplateID)
+			if err != nil {
+				e.log.Warn(e.ctx, "get workspace template",
+					slog.F("workspace_id", ws.ID),
+					slog.Error(err),
+				)
+				continue
+			}
+
+			inWindow, err := inMaintenanceWindow(template, currentTick)
+			if err != nil {
+				e.log.Warn(e.ctx, "check template maintenance window",
+					slog.F("workspace_id", ws.ID),
+					slog.F("template_id", template.ID),
+					slog.Error(err),
+				)
+				continue
+			}
+			if !inWindow {
+				continue
+			}
+
+			priorHistory, err := db.GetLatestWorkspaceBuildByWorkspaceID(e.ctx, ws.ID)
+			if err != nil {
+				e.log.Warn(e.ctx, "get latest workspace build",
+					slog.F("workspace_id", ws.ID),
+					slog.Error(err),
+				)
+				continue
+			}
+
+			priorJob, err := db.GetProvisionerJobByID(e.ctx, priorHistory.JobID)
+			if err != nil {
+				e.log.Warn(e.ctx, "get last provisioner job for workspace",
+					slog.F("workspace_id", ws.ID),
+					slog.Error(err),
+				)
+				continue
+			}
+
+			// Only running workspaces are restarted, stopped workspaces pick
+			// up the active version when they are next started.
+			if !priorJob.CompletedAt.Valid || priorJob.Error.String != "" ||
+				priorHistory.Transition != database.WorkspaceTransitionStart {
+				continue
+			}
+			if priorHistory.TemplateVersionID == template.ActiveVersionID {
+				continue
+			}
+
+			e.log.Info(e.ctx, "restarting outdated workspace in maintenance window",
+				slog.F("workspace_id", ws.ID),
+				slog.F("template_version_id", template.ActiveVersionID),
+			)
+
+			stats.Transitions[ws.ID] = database.WorkspaceTransitionStart
+			if err := build(e.ctx, db, ws, database.WorkspaceTransitionStart, database.BuildReasonMaintenance, priorHistory, priorJob); err != nil {
+				e.log.Error(e.ctx, "unable to restart outdated workspace",
+					slog.F("workspace_id", ws.ID),
+					slog.Error(err),
+				)
+			}
+		}
+
+		// Templates keep a pool of prebuilt workspaces ready to be claimed
+		// by users.
+		prebuildTemplates, err := db.GetTemplatesWithPrebuilds(e.ctx)
+		if err != nil {
+			return xerrors.Errorf("get templates with prebuilt workspaces: %w", err)
+		}
+
+		for _, template := range prebuildTemplates {
+			backoff, ok := e.prebuildBackoffs[template.ID]
+			if !ok {
+				backoff = &prebuildBackoff{}
+				e.prebuildBackoffs[template.ID] = backoff
+			}
+			err := reconcilePrebuilds(e.ctx, e.log, db, template, t, backoff, stats.Transitions)
+			if err != nil {
+				e.log.Error(e.ctx, "unable to reconcile prebuilt workspaces",
+					slog.F("template_id", template.ID),
+					slog.Error(err),
+				)
+			}
+		}
 		return nil
 	})
 	return stats
 }
 
+const (
+	// prebuildBackoffMin is how long a template waits before it builds
+	// prebuilds again after one failed.
+	prebuildBackoffMin = time.Minute
+	// prebuildBackoffMax caps the backoff of templates whose prebuilds keep
+	// failing.
+	prebuildBackoffMax = time.Hour
+)
+
+// prebuildBackoff tracks the consecutive failed prebuilds of a template, so a
+// broken template doesn't build a new prebuild on every tick.
+type prebuildBackoff struct {
+	failures    int
+	lastFailure time.Time
+}
+
+// observe counts the prebuilds that failed since the last observed failure,
+// and resets the count when a prebuild succeeded after it. Builds are
+// observed in the order they completed.
+func (b *prebuildBackoff) observe(prebuilds []prebuild.Workspace) {
+	completed := make([]prebuild.Workspace, 0, len(prebuilds))
+	for _, ws := range prebuilds {
+		if ws.State == prebuild.StateFailed || ws.State == prebuild.StateReady {
+			completed = append(completed, ws)
+		}
+	}
+	sort.Slice(completed, func(i, j int) bool {
+		return completed[i].Job.CompletedAt.Time.Before(completed[j].Job.CompletedAt.Time)
+	})
+	for _, ws := range completed {
+		completedAt := ws.Job.CompletedAt.Time
+		if !completedAt.After(b.lastFailure) {
+			continue
+		}
+		if ws.State == prebuild.StateReady {
+			b.failures = 0
+			continue
+		}
+		b.failures++
+		b.lastFailure = completedAt
+	}
+}
+
+// retryAt returns when new prebuilds may be built. The delay doubles with
+// every consecutive failure.
+func (b *prebuildBackoff) retryAt() time.Time {
+	if b.failures == 0 {
+		return time.Time{}
+	}
+	delay := prebuildBackoffMax
+	// Shifting further would exceed the maximum, or overflow.
+	if b.failures <= 6 {
+		delay = prebuildBackoffMin << (b.failures - 1)
+	}
+	return b.lastFailure.Add(delay)
+}
+
+// reconcilePrebuilds builds and deletes prebuilt workspaces of the template
+// until its pool has the desired size. Prebuilds that failed or no longer
+// match the template are replaced. Templates whose prebuilds keep failing
+// back off before they build new ones.
+func reconcilePrebuilds(ctx context.Context, log slog.Logger, db database.Store, template database.Template, now time.Time, backoff *prebuildBackoff, transitions map[uuid.UUID]database.WorkspaceTransition) error {
+	prebuilds, err := prebuild.GetWorkspaces(ctx, db, template)
+	if err != nil {
+		return err
+	}
+	backoff.observe(prebuilds)
+
+	var live int32
+	for _, ws := range prebuilds {
+		if _, ok := transitions[ws.ID]; ok {
+			// Already transitioned on this tick.
+			live++
+			continue
+		}
+
+		switch ws.State {
+		case prebuild.StateDeleting:
+			continue
+		case prebuild.StatePending, prebuild.StateReady:
+			if live < template.PrebuildCount {
+				live++
+				continue
+			}
+			if ws.State == prebuild.StatePending {
+				// Surplus prebuilds are deleted once their build completes,
+				// so no resources are orphaned.
+				continue
+			}
+		}
+
+		log.Info(ctx, "deleting prebuilt workspace",
+			slog.F("workspace_id", ws.ID),
+			slog.F("template_id", template.ID),
+			slog.F("state", ws.State),
+		)
+		transitions[ws.ID] = database.WorkspaceTransitionDelete
+		err := build(ctx, db, ws.Workspace, database.WorkspaceTransitionDelete, database.BuildReasonInitiator, ws.Build, ws.Job)
+		if err != nil {
+			return xerrors.Errorf("delete prebuilt workspace %q: %w", ws.ID, err)
+		}
+	}
+
+	if live < template.PrebuildCount {
+		if retryAt := backoff.retryAt(); now.Before(retryAt) {
+			log.Warn(ctx, "prebuilt workspaces keep failing, backing off",
+				slog.F("template_id", template.ID),
+				slog.F("failures", backoff.failures),
+				slog.F("retry_at", retryAt),
+			)
+			return nil
+		}
+	}
+	for ; live < template.PrebuildCount; live++ {
+		workspace, err := createPrebuild(ctx, db, template)
+		if err != nil {
+			return xerrors.Errorf("create prebuilt workspace: %w", err)
+		}
+		log.Info(ctx, "created prebuilt workspace",
+			slog.F("workspace_id", workspace.ID),
+			slog.F("template_id", template.ID),
+		)
+		transitions[workspace.ID] = database.WorkspaceTransitionStart
+	}
+	return nil
+}
+
+// createPrebuild creates a prebuilt workspace on the active version of the
+// template. Prebuilt workspaces are owned by the template creator until they
+// are claimed.
+func createPrebuild(ctx context.Context, store database.Store, template database.Template) (database.Workspace, error) {
+	preset, err := prebuild.Parameters(template)
+	if err != nil {
+		return database.Workspace{}, err
+	}
+	templateVersion, err := store.GetTemplateVersionByID(ctx, template.ActiveVersionID)
+	if err != nil {
+		return database.Workspace{}, xerrors.Errorf("get active template version: %w", err)
+	}
+	templateVersionJob, err := store.GetProvisionerJobByID(ctx, templateVersion.JobID)
+	if err != nil {
+		return database.Workspace{}, xerrors.Errorf("get active template version job: %w", err)
+	}
+	if !templateVersionJob.CompletedAt.Valid || templateVersionJob.Error.Valid {
+		return database.Workspace{}, xerrors.Errorf("active template version %q has not imported successfully", templateVersion.Name)
+	}
+
+	now := database.Now()
+	workspace, err := store.InsertWorkspace(ctx, database.InsertWorkspaceParams{
+		ID:             uuid.New(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		OwnerID:        template.CreatedBy,
+		OrganizationID: template.OrganizationID,
+		TemplateID:     template.ID,
+		Name:           "prebuild-" + strings.ReplaceAll(uuid.NewString(), "-", "")[:8],
+		Prebuild:       true,
+	})
+	if err != nil {
+		return database.Workspace{}, xerrors.Errorf("insert workspace: %w", err)
+	}
+	for _, parameterValue := range preset {
+		_, err = store.InsertParameterValue(ctx, database.InsertParameterValueParams{
+			ID:                uuid.New(),
+			Name:              parameterValue.Name,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+			Scope:             database.ParameterScopeWorkspace,
+			ScopeID:           workspace.ID,
+			SourceScheme:      database.ParameterSourceScheme(parameterValue.SourceScheme),
+			SourceValue:       parameterValue.SourceValue,
+			DestinationScheme: database.ParameterDestinationScheme(parameterValue.DestinationScheme),
+		})
+		if err != nil {
+			return database.Workspace{}, xerrors.Errorf("insert parameter value: %w", err)
+		}
+	}
+
+	workspaceBuildID := uuid.New()
+	input, err := json.Marshal(struct {
+		WorkspaceBuildID string `json:"workspace_build_id"`
+	}{
+		WorkspaceBuildID: workspaceBuildID.String(),
+	})
+	if err != nil {
+		return database.Workspace{}, xerrors.Errorf("marshal provision job: %w", err)
+	}
+	provisionerJob, err := store.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+		ID:             uuid.New(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		InitiatorID:    template.CreatedBy,
+		OrganizationID: template.OrganizationID,
+		Provisioner:    template.Provisioner,
+		Type:           database.ProvisionerJobTypeWorkspaceBuild,
+		StorageMethod:  templateVersionJob.StorageMethod,
+		StorageSource:  templateVersionJob.StorageSource,
+		Input:          input,
+	})
+	if err != nil {
+		return database.Workspace{}, xerrors.Errorf("insert provisioner job: %w", err)
+	}
+	_, err = store.InsertWorkspaceBuild(ctx, database.InsertWorkspaceBuildParams{
+		ID:                workspaceBuildID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		WorkspaceID:       workspace.ID,
+		TemplateVersionID: templateVersion.ID,
+		BuildNumber:       1,
+		Name:              namesgenerator.GetRandomName(1),
+		InitiatorID:       template.CreatedBy,
+		Transition:        database.WorkspaceTransitionStart,
+		JobID:             provisionerJob.ID,
+		Reason:            database.BuildReasonInitiator,
+	})
+	if err != nil {
+		return database.Workspace{}, xerrors.Errorf("insert workspace build: %w", err)
+	}
+	return workspace, nil
+}
+
+// getDormantAt returns the time at which a workspace becomes dormant. Only
+// workspaces that were successfully stopped can become dormant.
+func getDormantAt(
+	ws database.Workspace,
+	template database.Template,
+	priorHistory database.WorkspaceBuild,
+	priorJob database.ProvisionerJob,
+) (time.Time, error) {
+	if template.InactivityTtl <= 0 {
+		return time.Time{}, xerrors.Errorf("template has no inactivity ttl")
+	}
+	if !priorJob.CompletedAt.Valid || priorJob.Error.String != "" {
+		return time.Time{}, xerrors.Errorf("last workspace build did not complete successfully")
+	}
+	if priorHistory.Transition != database.WorkspaceTransitionStop {
+		return time.Time{}, xerrors.Errorf("workspace is not stopped")
+	}
+
+	// A workspace that was never connected to is considered used when it
+	// was last built.
+	lastUsedAt := ws.LastUsedAt
+	if priorHistory.CreatedAt.After(lastUsedAt) {
+		lastUsedAt = priorHistory.CreatedAt
+	}
+	return lastUsedAt.Add(time.Duration(template.InactivityTtl)), nil
+}
+
+// getDeletingAt returns the time at which a dormant workspace is deleted.
+func getDeletingAt(
+	ws database.Workspace,
+	template database.Template,
+	priorHistory database.WorkspaceBuild,
+	priorJob database.ProvisionerJob,
+) (time.Time, error) {
+	if template.DormantAutodeleteTtl <= 0 {
+		return time.Time{}, xerrors.Errorf("template has no dormant autodelete ttl")
+	}
+	if !priorJob.CompletedAt.Valid {
+		return time.Time{}, xerrors.Errorf("last workspace build is still running")
+	}
+	// Don't retry a failed deletion on every tick, an admin needs to look at it.
+	if priorHistory.Reason == database.BuildReasonAutodelete {
+		return time.Time{}, xerrors.Errorf("workspace deletion was already attempted")
+	}
+	return ws.DormantAt.Time.Add(time.Duration(template.DormantAutodeleteTtl)), nil
+}
+
 func getNextTransition(
 	ws database.Workspace,
 	priorHistory database.WorkspaceBuild,
@@ -185,6 +692,9 @@ func getNextTransition(
 		// it ensures we will not stop too early.
 		return database.WorkspaceTransitionStop, priorHistory.Deadline, nil
 	case database.WorkspaceTransitionStop:
+		if ws.DormantAt.Valid {
+			return "", time.Time{}, xerrors.Errorf("workspace is dormant")
+		}
 		sched, err := schedule.Weekly(ws.AutostartSchedule.String)
 		if err != nil {
 			return "", time.Time{}, xerrors.Errorf("workspace has invalid autostart schedule: %w", err)
@@ -198,14 +708,43 @@ func getNextTransition(
 	}
 }
 
+// inMaintenanceWindow returns whether t is within one of the maintenance
+// windows of the template.
+func inMaintenanceWindow(template database.Template, t time.Time) (bool, error) {
+	sched, err := schedule.Weekly(template.MaintenanceWindow)
+	if err != nil {
+		return false, xerrors.Errorf("template has invalid maintenance window: %w", err)
+	}
+	// The latest window that may still be open started after t - duration.
+	windowStart := sched.Next(t.Add(-time.Duration(template.MaintenanceWindowDuration)))
+	return !windowStart.After(t), nil
+}
+
 // TODO(cian): this function duplicates most of api.postWorkspaceBuilds. Refactor.
 // See: https://github.com/coder/coder/issues/1401
-func build(ctx context.Context, store database.Store, workspace database.Workspace, trans database.WorkspaceTransition, priorHistory database.WorkspaceBuild, priorJob database.ProvisionerJob) error {
+func build(ctx context.Context, store database.Store, workspace database.Workspace, trans database.WorkspaceTransition, reason database.BuildReason, priorHistory database.WorkspaceBuild, priorJob database.ProvisionerJob) error {
 	template, err := store.GetTemplateByID(ctx, workspace.TemplateID)
 	if err != nil {
 		return xerrors.Errorf("get workspace template: %w", err)
 	}
 
+	// Builds use the same template version as before, except for maintenance
+	// restarts which update the workspace to the active version.
+	templateVersionID := priorHistory.TemplateVersionID
+	storageMethod, storageSource := priorJob.StorageMethod, priorJob.StorageSource
+	if reason == database.BuildReasonMaintenance {
+		templateVersion, err := store.GetTemplateVersionByID(ctx, template.ActiveVersionID)
+		if err != nil {
+			return xerrors.Errorf("get active template version: %w", err)
+		}
+		templateVersionJob, err := store.GetProvisionerJobByID(ctx, templateVersion.JobID)
+		if err != nil {
+			return xerrors.Errorf("get active template version job: %w", err)
+		}
+		templateVersionID = templateVersion.ID
+		storageMethod, storageSource = templateVersionJob.StorageMethod, templateVersionJob.StorageSource
+	}
+
 	priorBuildNumber := priorHistory.BuildNumber
 
 	// This must happen in a transaction to ensure history can be inserted, and
@@ -222,16 +761,6 @@ func build(ctx context.Context, store database.Store, workspace database.Workspa
 	provisionerJobID := uuid.New()
 	now := database.Now()
 
-	var buildReason database.BuildReason
-	switch trans {
-	case database.WorkspaceTransitionStart:
-		buildReason = database.BuildReasonAutostart
-	case database.WorkspaceTransitionStop:
-		buildReason = database.BuildReasonAutostop
-	default:
-		return xerrors.Errorf("Unsupported transition: %q", trans)
-	}
-
 	newProvisionerJob, err := store.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
 		ID:             provisionerJobID,
 		CreatedAt:      now,
@@ -240,8 +769,8 @@ func build(ctx context.Context, store database.Store, workspace database.Workspa
 		OrganizationID: template.OrganizationID,
 		Provisioner:    template.Provisioner,
 		Type:           database.ProvisionerJobTypeWorkspaceBuild,
-		StorageMethod:  priorJob.StorageMethod,
-		StorageSource:  priorJob.StorageSource,
+		StorageMethod:  storageMethod,
+		StorageSource:  storageSource,
 		Input:          input,
 	})
 	if err != nil {
@@ -252,14 +781,14 @@ func build(ctx context.Context, store database.Store, workspace database.Workspa
 		CreatedAt:         now,
 		UpdatedAt:         now,
 		WorkspaceID:       workspace.ID,
-		TemplateVersionID: priorHistory.TemplateVersionID,
+		TemplateVersionID: templateVersionID,
 		BuildNumber:       priorBuildNumber + 1,
 		Name:              namesgenerator.GetRandomName(1),
 		ProvisionerState:  priorHistory.ProvisionerState,
 		InitiatorID:       workspace.OwnerID,
 		Transition:        trans,
 		JobID:             newProvisionerJob.ID,
-		Reason:            buildReason,
+		Reason:            reason,
 	})
 	if err != nil {
 		return xerrors.Errorf("insert workspace build: %w", err)
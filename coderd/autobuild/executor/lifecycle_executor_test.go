@@ -2,6 +2,7 @@ package executor_test
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -14,7 +15,10 @@ import (
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/util/ptr"
 	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -540,6 +544,415 @@ func TestExecutorAutostartMultipleOK(t *testing.T) {
 	assert.Len(t, stats2.Transitions, 0)
 }
 
+func TestExecutorDormancyOK(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = context.Background()
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan executor.Stats)
+		client  = coderdtest.New(t, &coderdtest.Options{
+			AutobuildTicker:     tickCh,
+			IncludeProvisionerD: true,
+			AutobuildStats:      statsCh,
+		})
+		// Given: we have a user with a workspace that does not have autostart enabled
+		workspace = mustProvisionWorkspace(t, client, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.AutostartSchedule = nil
+		})
+	)
+	// Given: the template marks workspaces dormant after a day of inactivity
+	_, err := client.UpdateTemplateMeta(ctx, workspace.TemplateID, codersdk.UpdateTemplateMeta{
+		InactivityTTLMillis: ptr.Ref((24 * time.Hour).Milliseconds()),
+	})
+	require.NoError(t, err)
+
+	// Given: workspace is stopped
+	workspace = coderdtest.MustTransitionWorkspace(t, client, workspace.ID, database.WorkspaceTransitionStart, database.WorkspaceTransitionStop)
+
+	// When: the autobuild executor ticks after the inactivity period
+	go func() {
+		tickCh <- workspace.LatestBuild.CreatedAt.Add(25 * time.Hour)
+		close(tickCh)
+	}()
+
+	// Then: the workspace should be marked dormant, but not transitioned
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.Transitions, 0)
+	require.Equal(t, []uuid.UUID{workspace.ID}, stats.Dormant)
+
+	workspace = coderdtest.MustWorkspace(t, client, workspace.ID)
+	require.NotNil(t, workspace.DormantAt)
+	// No autodelete policy, so the workspace is never deleted.
+	require.Nil(t, workspace.DeletingAt)
+}
+
+func TestExecutorDormancyTooEarly(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = context.Background()
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan executor.Stats)
+		client  = coderdtest.New(t, &coderdtest.Options{
+			AutobuildTicker:     tickCh,
+			IncludeProvisionerD: true,
+			AutobuildStats:      statsCh,
+		})
+		// Given: we have a user with a workspace that does not have autostart enabled
+		workspace = mustProvisionWorkspace(t, client, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.AutostartSchedule = nil
+		})
+	)
+	// Given: the template marks workspaces dormant after a day of inactivity
+	_, err := client.UpdateTemplateMeta(ctx, workspace.TemplateID, codersdk.UpdateTemplateMeta{
+		InactivityTTLMillis: ptr.Ref((24 * time.Hour).Milliseconds()),
+	})
+	require.NoError(t, err)
+
+	// Given: workspace is stopped
+	workspace = coderdtest.MustTransitionWorkspace(t, client, workspace.ID, database.WorkspaceTransitionStart, database.WorkspaceTransitionStop)
+
+	// When: the autobuild executor ticks before the inactivity period ends
+	go func() {
+		tickCh <- workspace.LatestBuild.CreatedAt.Add(time.Hour)
+		close(tickCh)
+	}()
+
+	// Then: nothing should happen
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.Transitions, 0)
+	require.Len(t, stats.Dormant, 0)
+}
+
+func TestExecutorDormantAutostart(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = context.Background()
+		sched   = mustSchedule(t, "CRON_TZ=UTC 0 * * * *")
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan executor.Stats)
+		client  = coderdtest.New(t, &coderdtest.Options{
+			AutobuildTicker:     tickCh,
+			IncludeProvisionerD: true,
+			AutobuildStats:      statsCh,
+		})
+		// Given: we have a user with a workspace that has autostart enabled
+		workspace = mustProvisionWorkspace(t, client, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.AutostartSchedule = ptr.Ref(sched.String())
+		})
+	)
+	// Given: workspace is stopped and dormant
+	workspace = coderdtest.MustTransitionWorkspace(t, client, workspace.ID, database.WorkspaceTransitionStart, database.WorkspaceTransitionStop)
+	err := client.UpdateWorkspaceDormancy(ctx, workspace.ID, codersdk.UpdateWorkspaceDormancyRequest{Dormant: true})
+	require.NoError(t, err)
+
+	// When: the autobuild executor ticks after the scheduled time
+	go func() {
+		tickCh <- sched.Next(workspace.LatestBuild.CreatedAt)
+		close(tickCh)
+	}()
+
+	// Then: the workspace should not be started
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.Transitions, 0)
+}
+
+func TestExecutorDormantAutodeleteOK(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = context.Background()
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan executor.Stats)
+		client  = coderdtest.New(t, &coderdtest.Options{
+			AutobuildTicker:     tickCh,
+			IncludeProvisionerD: true,
+			AutobuildStats:      statsCh,
+		})
+		// Given: we have a user with a workspace that does not have autostart enabled
+		workspace = mustProvisionWorkspace(t, client, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.AutostartSchedule = nil
+		})
+	)
+	// Given: the template deletes workspaces a week after they become dormant
+	_, err := client.UpdateTemplateMeta(ctx, workspace.TemplateID, codersdk.UpdateTemplateMeta{
+		DormantAutoDeleteTTLMillis: ptr.Ref((7 * 24 * time.Hour).Milliseconds()),
+	})
+	require.NoError(t, err)
+
+	// Given: workspace is stopped and dormant
+	workspace = coderdtest.MustTransitionWorkspace(t, client, workspace.ID, database.WorkspaceTransitionStart, database.WorkspaceTransitionStop)
+	err = client.UpdateWorkspaceDormancy(ctx, workspace.ID, codersdk.UpdateWorkspaceDormancyRequest{Dormant: true})
+	require.NoError(t, err)
+	workspace = coderdtest.MustWorkspace(t, client, workspace.ID)
+	require.NotNil(t, workspace.DeletingAt)
+
+	// When: the autobuild executor ticks after the deletion time
+	go func() {
+		tickCh <- workspace.DeletingAt.Add(time.Minute)
+		close(tickCh)
+	}()
+
+	// Then: the workspace should be deleted
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.Transitions, 1)
+	require.Equal(t, database.WorkspaceTransitionDelete, stats.Transitions[workspace.ID])
+
+	builds, err := client.WorkspaceBuilds(ctx, codersdk.WorkspaceBuildsRequest{WorkspaceID: workspace.ID})
+	require.NoError(t, err)
+	require.Equal(t, codersdk.WorkspaceTransitionDelete, builds[0].Transition)
+	require.Equal(t, codersdk.BuildReasonAutodelete, builds[0].Reason)
+}
+
+func TestExecutorAutostopMaintenanceWindow(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = context.Background()
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan executor.Stats)
+		client  = coderdtest.New(t, &coderdtest.Options{
+			AutobuildTicker:     tickCh,
+			IncludeProvisionerD: true,
+			AutobuildStats:      statsCh,
+		})
+		// Given: we have a user with a workspace
+		workspace = mustProvisionWorkspace(t, client)
+	)
+	// Given: workspace is running
+	require.Equal(t, codersdk.WorkspaceTransitionStart, workspace.LatestBuild.Transition)
+	require.NotZero(t, workspace.LatestBuild.Deadline)
+
+	// Given: the template has a maintenance window starting a few hours after the deadline
+	windowStart := workspace.LatestBuild.Deadline.Time.UTC().Add(3 * time.Hour).Truncate(time.Minute)
+	_, err := client.UpdateTemplateMeta(ctx, workspace.TemplateID, codersdk.UpdateTemplateMeta{
+		MaintenanceWindow:       ptr.Ref(dailyWindow(windowStart)),
+		MaintenanceWindowMillis: ptr.Ref(time.Hour.Milliseconds()),
+	})
+	require.NoError(t, err)
+
+	// When: the autobuild executor ticks after the deadline, but outside the window
+	go func() {
+		tickCh <- workspace.LatestBuild.Deadline.Time.Add(time.Minute)
+		tickCh <- windowStart.Add(time.Minute)
+		close(tickCh)
+	}()
+
+	// Then: the workspace should not be stopped
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.Transitions, 0)
+
+	// When: the autobuild executor ticks inside the window
+	// Then: the workspace should be stopped
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.Transitions, 1)
+	require.Equal(t, database.WorkspaceTransitionStop, stats.Transitions[workspace.ID])
+}
+
+func TestExecutorMaintenanceRestartOutdated(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = context.Background()
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan executor.Stats)
+		client  = coderdtest.New(t, &coderdtest.Options{
+			AutobuildTicker:     tickCh,
+			IncludeProvisionerD: true,
+			AutobuildStats:      statsCh,
+		})
+		// Given: we have a user with a workspace that does not have autostop enabled
+		workspace = mustProvisionWorkspace(t, client, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.AutostartSchedule = nil
+			cwr.TTLMillis = nil
+		})
+	)
+	// Given: the template restarts outdated workspaces in its maintenance window
+	windowStart := time.Now().UTC().Add(time.Hour).Truncate(time.Minute)
+	_, err := client.UpdateTemplateMeta(ctx, workspace.TemplateID, codersdk.UpdateTemplateMeta{
+		MaintenanceWindow:          ptr.Ref(dailyWindow(windowStart)),
+		MaintenanceWindowMillis:    ptr.Ref(time.Hour.Milliseconds()),
+		MaintenanceRestartOutdated: ptr.Ref(true),
+	})
+	require.NoError(t, err)
+
+	// Given: the workspace template has been updated
+	orgs, err := client.OrganizationsByUser(ctx, workspace.OwnerID.String())
+	require.NoError(t, err)
+	newVersion := coderdtest.UpdateTemplateVersion(t, client, orgs[0].ID, nil, workspace.TemplateID)
+	coderdtest.AwaitTemplateVersionJob(t, client, newVersion.ID)
+	require.NoError(t, client.UpdateActiveTemplateVersion(ctx, workspace.TemplateID, codersdk.UpdateActiveTemplateVersion{
+		ID: newVersion.ID,
+	}))
+
+	// When: the autobuild executor ticks before and inside the window
+	go func() {
+		tickCh <- windowStart.Add(-time.Minute)
+		tickCh <- windowStart.Add(time.Minute)
+		close(tickCh)
+	}()
+
+	// Then: the workspace should only be restarted inside the window
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.Transitions, 0)
+
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.Transitions, 1)
+	require.Equal(t, database.WorkspaceTransitionStart, stats.Transitions[workspace.ID])
+
+	ws := coderdtest.MustWorkspace(t, client, workspace.ID)
+	require.Equal(t, newVersion.ID, ws.LatestBuild.TemplateVersionID)
+	require.Equal(t, codersdk.BuildReasonMaintenance, ws.LatestBuild.Reason)
+}
+
+func TestExecutorPrebuilds(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = context.Background()
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan executor.Stats)
+		client  = coderdtest.New(t, &coderdtest.Options{
+			AutobuildTicker:     tickCh,
+			IncludeProvisionerD: true,
+			AutobuildStats:      statsCh,
+		})
+		user     = coderdtest.CreateFirstUser(t, client)
+		version  = coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template = coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+
+	// Given: the template keeps two prebuilt workspaces ready
+	_, err := client.UpdateTemplatePrebuilds(ctx, template.ID, codersdk.UpdateTemplatePrebuildsRequest{Count: 2})
+	require.NoError(t, err)
+
+	// When: the autobuild executor ticks
+	go func() {
+		tickCh <- time.Now()
+	}()
+
+	// Then: two prebuilt workspaces should be built
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.Transitions, 2)
+	for id, transition := range stats.Transitions {
+		require.Equal(t, database.WorkspaceTransitionStart, transition)
+		ws := coderdtest.MustWorkspace(t, client, id)
+		require.True(t, ws.Prebuild)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, ws.LatestBuild.ID)
+	}
+	prebuilds, err := client.TemplatePrebuilds(ctx, template.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, prebuilds.Ready)
+
+	// Prebuilt workspaces are hidden unless asked for.
+	workspaces, err := client.Workspaces(ctx, codersdk.WorkspaceFilter{})
+	require.NoError(t, err)
+	require.Len(t, workspaces, 0)
+	workspaces, err = client.Workspaces(ctx, codersdk.WorkspaceFilter{Prebuild: true})
+	require.NoError(t, err)
+	require.Len(t, workspaces, 2)
+
+	// Given: the pool is shrunk
+	_, err = client.UpdateTemplatePrebuilds(ctx, template.ID, codersdk.UpdateTemplatePrebuildsRequest{Count: 1})
+	require.NoError(t, err)
+
+	// When: the autobuild executor ticks again
+	go func() {
+		tickCh <- time.Now()
+		close(tickCh)
+	}()
+
+	// Then: the surplus prebuilt workspace should be deleted
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.Transitions, 1)
+	for _, transition := range stats.Transitions {
+		require.Equal(t, database.WorkspaceTransitionDelete, transition)
+	}
+}
+
+func TestExecutorPrebuildsBackoff(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = context.Background()
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan executor.Stats)
+		client  = coderdtest.New(t, &coderdtest.Options{
+			AutobuildTicker:     tickCh,
+			IncludeProvisionerD: true,
+			AutobuildStats:      statsCh,
+		})
+		user = coderdtest.CreateFirstUser(t, client)
+		// Workspace builds of the template fail.
+		version = coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:           echo.ParseComplete,
+			ProvisionDryRun: echo.ProvisionComplete,
+			Provision: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{
+						Error: "failed to build",
+					},
+				},
+			}},
+		})
+		template = coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	_, err := client.UpdateTemplatePrebuilds(ctx, template.ID, codersdk.UpdateTemplatePrebuildsRequest{Count: 1})
+	require.NoError(t, err)
+
+	// When: the prebuild fails
+	go func() {
+		tickCh <- time.Now()
+	}()
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.Transitions, 1)
+	for id := range stats.Transitions {
+		ws := coderdtest.MustWorkspace(t, client, id)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, ws.LatestBuild.ID)
+		build, err := client.WorkspaceBuild(ctx, ws.LatestBuild.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.ProvisionerJobFailed, build.Job.Status)
+	}
+
+	// Then: the failed prebuild is deleted, but not replaced yet
+	go func() {
+		tickCh <- time.Now()
+	}()
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.Transitions, 1)
+	for _, transition := range stats.Transitions {
+		require.Equal(t, database.WorkspaceTransitionDelete, transition)
+	}
+
+	// Then: it's replaced once the backoff passed
+	go func() {
+		tickCh <- time.Now().Add(2 * time.Minute)
+		close(tickCh)
+	}()
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.Transitions, 1)
+	for _, transition := range stats.Transitions {
+		require.Equal(t, database.WorkspaceTransitionStart, transition)
+	}
+}
+
 func mustProvisionWorkspace(t *testing.T, client *codersdk.Client, mut ...func(*codersdk.CreateWorkspaceRequest)) codersdk.Workspace {
 	t.Helper()
 	user := coderdtest.CreateFirstUser(t, client)
@@ -558,6 +971,11 @@ func mustSchedule(t *testing.T, s string) *schedule.Schedule {
 	return sched
 }
 
+// dailyWindow returns a daily maintenance window schedule starting at t.
+func dailyWindow(t time.Time) string {
+	return fmt.Sprintf("CRON_TZ=UTC %d %d * * *", t.Minute(), t.Hour())
+}
+
 func TestMain(m *testing.M) {
 	goleak.VerifyTestMain(m)
 }
@@ -0,0 +1,70 @@
+// Package blobstore stores the contents of files uploaded to coderd, such as
+// template source archives. The files table only holds metadata; contents are
+// kept by a Store, keyed by the hash of the file.
+package blobstore
+
+import (
+	"context"
+	"io"
+	"regexp"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+)
+
+// ErrNotExist is returned when a blob doesn't exist.
+var ErrNotExist = xerrors.New("blob does not exist")
+
+// Store is a backend for blob contents.
+type Store interface {
+	// Put stores size bytes read from r under key. Putting a key that
+	// already exists is a no-op, since keys are content hashes.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Get returns the contents of a blob. The caller must close the reader.
+	// ErrNotExist is returned if the blob doesn't exist.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes a blob. Deleting a blob that doesn't exist is not an
+	// error.
+	Delete(ctx context.Context, key string) error
+}
+
+// databaseStore is implemented by stores that keep blobs in the database, so
+// they can be used in a transaction.
+type databaseStore interface {
+	withDatabase(db database.Store) Store
+}
+
+// withDatabase returns store using db if it keeps blobs in the database.
+func withDatabase(store Store, db database.Store) Store {
+	if s, ok := store.(databaseStore); ok {
+		return s.withDatabase(db)
+	}
+	return store
+}
+
+// ReadAll reads the full contents of a blob into memory.
+func ReadAll(ctx context.Context, store Store, key string) ([]byte, error) {
+	reader, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, xerrors.Errorf("read blob %q: %w", key, err)
+	}
+	return data, nil
+}
+
+// keyRegex matches the keys blobs can be stored with. Keys are used as file
+// names and in URLs, so they're restricted to characters that are safe in
+// both.
+var keyRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+func validateKey(key string) error {
+	if !keyRegex.MatchString(key) {
+		return xerrors.Errorf("invalid blob key %q", key)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package blobstore_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/blobstore"
+	"github.com/coder/coder/coderd/database/databasefake"
+	"github.com/coder/coder/testutil"
+)
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		Name  string
+		Store func(t *testing.T) blobstore.Store
+	}{{
+		Name: "Postgres",
+		Store: func(t *testing.T) blobstore.Store {
+			return blobstore.NewPostgres(databasefake.New())
+		},
+	}, {
+		Name: "Filesystem",
+		Store: func(t *testing.T) blobstore.Store {
+			store, err := blobstore.NewFilesystem(t.TempDir())
+			require.NoError(t, err)
+			return store
+		},
+	}, {
+		Name: "Fallback",
+		Store: func(t *testing.T) blobstore.Store {
+			store, err := blobstore.NewFilesystem(t.TempDir())
+			require.NoError(t, err)
+			return blobstore.WithFallback(store, blobstore.NewPostgres(databasefake.New()))
+		},
+	}, {
+		Name: "S3",
+		Store: func(t *testing.T) blobstore.Store {
+			server := httptest.NewServer(newFakeS3(t, "bucket"))
+			t.Cleanup(server.Close)
+			endpoint, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			store, err := blobstore.NewS3(blobstore.S3Options{
+				Endpoint:        endpoint,
+				Bucket:          "bucket",
+				Prefix:          "coder-",
+				AccessKeyID:     "access-key",
+				SecretAccessKey: "secret-key",
+			})
+			require.NoError(t, err)
+			return store
+		},
+	}} {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			store := tc.Store(t)
+
+			ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+			defer cancel()
+
+			_, err := store.Get(ctx, "missing")
+			require.ErrorIs(t, err, blobstore.ErrNotExist)
+
+			data := bytes.Repeat([]byte("template"), 1024)
+			err = store.Put(ctx, "abc123", bytes.NewReader(data), int64(len(data)))
+			require.NoError(t, err)
+			// Keys are content hashes, so putting a key again is a no-op.
+			err = store.Put(ctx, "abc123", bytes.NewReader(data), int64(len(data)))
+			require.NoError(t, err)
+			got, err := blobstore.ReadAll(ctx, store, "abc123")
+			require.NoError(t, err)
+			require.Equal(t, data, got)
+
+			err = store.Put(ctx, "empty", bytes.NewReader(nil), 0)
+			require.NoError(t, err)
+			got, err = blobstore.ReadAll(ctx, store, "empty")
+			require.NoError(t, err)
+			require.Empty(t, got)
+
+			err = store.Delete(ctx, "abc123")
+			require.NoError(t, err)
+			_, err = store.Get(ctx, "abc123")
+			require.ErrorIs(t, err, blobstore.ErrNotExist)
+			// Deleting a missing blob isn't an error.
+			err = store.Delete(ctx, "abc123")
+			require.NoError(t, err)
+
+			// Keys can't escape the store.
+			err = store.Put(ctx, "../escape", bytes.NewReader(data), int64(len(data)))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestFallback(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	postgres := blobstore.NewPostgres(databasefake.New())
+	filesystem, err := blobstore.NewFilesystem(t.TempDir())
+	require.NoError(t, err)
+	store := blobstore.WithFallback(filesystem, postgres)
+
+	// Blobs stored before the store was changed are still readable.
+	data := []byte("template")
+	err = postgres.Put(ctx, "old", bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	got, err := blobstore.ReadAll(ctx, store, "old")
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+
+	// New blobs are only written to the new store.
+	err = store.Put(ctx, "new", bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	_, err = postgres.Get(ctx, "new")
+	require.ErrorIs(t, err, blobstore.ErrNotExist)
+	_, err = filesystem.Get(ctx, "new")
+	require.NoError(t, err)
+
+	// Deleting removes blobs from both.
+	err = store.Delete(ctx, "old")
+	require.NoError(t, err)
+	_, err = store.Get(ctx, "old")
+	require.ErrorIs(t, err, blobstore.ErrNotExist)
+}
+
+// newFakeS3 returns a minimal stand-in for the S3 object API that serves a
+// single bucket and requires signed requests.
+func newFakeS3(t *testing.T, bucket string) http.Handler {
+	var (
+		mutex   sync.Mutex
+		objects = map[string][]byte{}
+	)
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access-key/") ||
+			r.Header.Get("X-Amz-Content-Sha256") != "UNSIGNED-PAYLOAD" {
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, "/"+bucket+"/") {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/"+bucket+"/")
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			data, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			if int64(len(data)) != r.ContentLength {
+				rw.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			objects[key] = data
+			rw.WriteHeader(http.StatusOK)
+		case http.MethodGet:
+			data, ok := objects[key]
+			if !ok {
+				rw.WriteHeader(http.StatusNotFound)
+				_, _ = io.WriteString(rw, "<Error><Code>NoSuchKey</Code></Error>")
+				return
+			}
+			_, _ = rw.Write(data)
+		case http.MethodDelete:
+			delete(objects, key)
+			rw.WriteHeader(http.StatusNoContent)
+		default:
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package blobstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"time"
+
+	"cdr.dev/slog"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+)
+
+// Collector periodically deletes files that no provisioner job references.
+type Collector struct {
+	ctx    context.Context
+	db     database.Store
+	store  Store
+	log    slog.Logger
+	tick   <-chan time.Time
+	minAge time.Duration
+}
+
+// NewCollector returns a garbage collector for uploaded files. Files are only
+// deleted once they're older than minAge, so files that were just uploaded
+// aren't deleted before a template version is created from them.
+func NewCollector(ctx context.Context, db database.Store, store Store, log slog.Logger, tick <-chan time.Time, minAge time.Duration) *Collector {
+	return &Collector{
+		ctx:    ctx,
+		db:     db,
+		store:  store,
+		log:    log,
+		tick:   tick,
+		minAge: minAge,
+	}
+}
+
+// Run collects garbage on every tick from its channel. It will stop when its
+// context is Done, or when its channel is closed.
+func (c *Collector) Run() {
+	go func() {
+		for {
+			select {
+			case <-c.ctx.Done():
+				return
+			case t, ok := <-c.tick:
+				if !ok {
+					return
+				}
+				deleted, err := CollectGarbage(c.ctx, c.db, c.store, t.Add(-c.minAge))
+				if err != nil {
+					c.log.Error(c.ctx, "collect unreferenced files", slog.Error(err))
+				}
+				c.log.Debug(c.ctx, "collected unreferenced files", slog.F("deleted", len(deleted)))
+			}
+		}
+	}()
+}
+
+// CollectGarbage deletes the files created before createdBefore that no
+// provisioner job references, and returns their hashes.
+func CollectGarbage(ctx context.Context, db database.Store, store Store, createdBefore time.Time) ([]string, error) {
+	files, err := db.GetUnreferencedFiles(ctx, createdBefore)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("get unreferenced files: %w", err)
+	}
+	deleted := make([]string, 0, len(files))
+	for _, file := range files {
+		var collected bool
+		err = db.InTx(func(tx database.Store) error {
+			// The file is checked again when it's deleted, since a job may
+			// have started using it or it may have been uploaded again.
+			_, err := tx.DeleteUnreferencedFileByHash(ctx, database.DeleteUnreferencedFileByHashParams{
+				Hash:      file.Hash,
+				CreatedAt: createdBefore,
+			})
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			if err != nil {
+				return xerrors.Errorf("delete file %q: %w", file.Hash, err)
+			}
+			// The blob is deleted while the row is locked, so StoreFile
+			// waits for it before storing the blob again.
+			err = withDatabase(store, tx).Delete(ctx, file.Hash)
+			if err != nil {
+				return xerrors.Errorf("delete blob %q: %w", file.Hash, err)
+			}
+			collected = true
+			return nil
+		})
+		if err != nil {
+			return deleted, err
+		}
+		if collected {
+			deleted = append(deleted, file.Hash)
+		}
+	}
+	return deleted, nil
+}
+
+// StoreFile inserts a file and stores its contents. A file that's already
+// stored is refreshed instead, so it isn't collected before it's used. The
+// row is written before the blob, so the blob of a file that's collected at
+// the same time is stored again.
+func StoreFile(ctx context.Context, db database.Store, store Store, arg database.InsertFileParams, r io.Reader, size int64) (database.File, error) {
+	file, err := db.UpdateFileCreatedAtByHash(ctx, database.UpdateFileCreatedAtByHashParams{
+		Hash:      arg.Hash,
+		CreatedAt: arg.CreatedAt,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		file, err = db.InsertFile(ctx, arg)
+		if database.IsUniqueViolation(err) {
+			// The same file was uploaded concurrently.
+			file, err = db.GetFileByHash(ctx, arg.Hash)
+		}
+	}
+	if err != nil {
+		return database.File{}, xerrors.Errorf("insert file: %w", err)
+	}
+	err = store.Put(ctx, arg.Hash, r, size)
+	if err != nil {
+		return database.File{}, xerrors.Errorf("store blob: %w", err)
+	}
+	return file, nil
+}
@@ -0,0 +1,83 @@
+package blobstore_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/blobstore"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/databasefake"
+	"github.com/coder/coder/testutil"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	db := databasefake.New()
+	store := blobstore.NewPostgres(db)
+	now := database.Now()
+	insertFile := func(hash string, createdAt time.Time) {
+		err := store.Put(ctx, hash, bytes.NewReader([]byte(hash)), int64(len(hash)))
+		require.NoError(t, err)
+		_, err = db.InsertFile(ctx, database.InsertFileParams{
+			Hash:      hash,
+			CreatedAt: createdAt,
+			CreatedBy: uuid.New(),
+			Mimetype:  "application/x-tar",
+		})
+		require.NoError(t, err)
+	}
+	insertFile("referenced", now.Add(-48*time.Hour))
+	insertFile("unreferenced", now.Add(-48*time.Hour))
+	insertFile("recent", now)
+	_, err := db.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+		ID:            uuid.New(),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Provisioner:   database.ProvisionerTypeEcho,
+		StorageMethod: database.ProvisionerStorageMethodFile,
+		StorageSource: "referenced",
+		Type:          database.ProvisionerJobTypeTemplateVersionImport,
+		Input:         []byte("{}"),
+	})
+	require.NoError(t, err)
+
+	deleted, err := blobstore.CollectGarbage(ctx, db, store, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, []string{"unreferenced"}, deleted)
+
+	_, err = db.GetFileByHash(ctx, "unreferenced")
+	require.Error(t, err)
+	_, err = store.Get(ctx, "unreferenced")
+	require.ErrorIs(t, err, blobstore.ErrNotExist)
+	for _, hash := range []string{"referenced", "recent"} {
+		_, err = db.GetFileByHash(ctx, hash)
+		require.NoError(t, err)
+		data, err := blobstore.ReadAll(ctx, store, hash)
+		require.NoError(t, err)
+		require.Equal(t, hash, string(data))
+	}
+
+	// Files that are uploaded again aren't collected.
+	insertFile("reuploaded", now.Add(-48*time.Hour))
+	_, err = blobstore.StoreFile(ctx, db, store, database.InsertFileParams{
+		Hash:      "reuploaded",
+		CreatedAt: now,
+		CreatedBy: uuid.New(),
+		Mimetype:  "application/x-tar",
+	}, bytes.NewReader([]byte("reuploaded")), int64(len("reuploaded")))
+	require.NoError(t, err)
+
+	// Nothing is left to collect.
+	deleted, err = blobstore.CollectGarbage(ctx, db, store, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, deleted)
+}
@@ -0,0 +1,48 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/coder/coder/coderd/database"
+)
+
+// WithFallback returns a Store that writes blobs to store, and reads blobs
+// it doesn't have from fallback. Files uploaded before the blob store of a
+// deployment was changed stay readable this way.
+func WithFallback(store, fallback Store) Store {
+	return &fallbackStore{store: store, fallback: fallback}
+}
+
+type fallbackStore struct {
+	store    Store
+	fallback Store
+}
+
+func (f *fallbackStore) withDatabase(db database.Store) Store {
+	return &fallbackStore{
+		store:    withDatabase(f.store, db),
+		fallback: withDatabase(f.fallback, db),
+	}
+}
+
+func (f *fallbackStore) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	return f.store.Put(ctx, key, r, size)
+}
+
+func (f *fallbackStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	reader, err := f.store.Get(ctx, key)
+	if errors.Is(err, ErrNotExist) {
+		return f.fallback.Get(ctx, key)
+	}
+	return reader, err
+}
+
+func (f *fallbackStore) Delete(ctx context.Context, key string) error {
+	err := f.store.Delete(ctx, key)
+	if err != nil {
+		return err
+	}
+	return f.fallback.Delete(ctx, key)
+}
@@ -0,0 +1,88 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/xerrors"
+)
+
+// NewFilesystem returns a Store that keeps blobs as files in directory. The
+// directory is created if it doesn't exist. It must not be shared between
+// coderd replicas unless it's on a shared filesystem.
+func NewFilesystem(directory string) (Store, error) {
+	err := os.MkdirAll(directory, 0o700)
+	if err != nil {
+		return nil, xerrors.Errorf("create blob directory: %w", err)
+	}
+	return &filesystemStore{directory: directory}, nil
+}
+
+type filesystemStore struct {
+	directory string
+}
+
+func (f *filesystemStore) Put(_ context.Context, key string, r io.Reader, size int64) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	path := filepath.Join(f.directory, key)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	// Write to a temporary file first so a partial write is never visible
+	// under the key.
+	tmp, err := os.CreateTemp(f.directory, ".tmp-"+key+"-*")
+	if err != nil {
+		return xerrors.Errorf("create temporary file: %w", err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+	written, err := io.Copy(tmp, r)
+	if err != nil {
+		return xerrors.Errorf("write blob: %w", err)
+	}
+	if written != size {
+		return xerrors.Errorf("wrote %d bytes, expected %d", written, size)
+	}
+	err = tmp.Close()
+	if err != nil {
+		return xerrors.Errorf("close temporary file: %w", err)
+	}
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return xerrors.Errorf("rename blob: %w", err)
+	}
+	return nil
+}
+
+func (f *filesystemStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filepath.Join(f.directory, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("open blob: %w", err)
+	}
+	return file, nil
+}
+
+func (f *filesystemStore) Delete(_ context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(f.directory, key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return xerrors.Errorf("remove blob: %w", err)
+	}
+	return nil
+}
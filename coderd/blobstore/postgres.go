@@ -0,0 +1,71 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+)
+
+// NewPostgres returns a Store that keeps blobs in the database. It's the
+// default, but every blob is held in memory while it's read or written.
+func NewPostgres(db database.Store) Store {
+	return &postgresStore{db: db}
+}
+
+type postgresStore struct {
+	db database.Store
+}
+
+func (p *postgresStore) withDatabase(db database.Store) Store {
+	return &postgresStore{db: db}
+}
+
+func (p *postgresStore) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	data := make([]byte, size)
+	_, err := io.ReadFull(r, data)
+	if err != nil {
+		return xerrors.Errorf("read blob: %w", err)
+	}
+	err = p.db.InsertFileBlob(ctx, database.InsertFileBlobParams{
+		Key:  key,
+		Data: data,
+	})
+	if err != nil {
+		return xerrors.Errorf("insert file blob: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	blob, err := p.db.GetFileBlobByKey(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("get file blob: %w", err)
+	}
+	return io.NopCloser(bytes.NewReader(blob.Data)), nil
+}
+
+func (p *postgresStore) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	err := p.db.DeleteFileBlobByKey(ctx, key)
+	if err != nil {
+		return xerrors.Errorf("delete file blob: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package blobstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// S3Options configures an S3-compatible Store.
+type S3Options struct {
+	// Endpoint is the URL of the S3 API, e.g.
+	// "https://s3.us-east-1.amazonaws.com" or the URL of a MinIO server.
+	// Requests use path-style addressing, so it must not include the bucket.
+	Endpoint *url.URL
+	Region   string
+	Bucket   string
+	// Prefix is prepended to the key of every blob.
+	Prefix string
+	// AccessKeyID and SecretAccessKey sign requests with AWS Signature
+	// Version 4. Requests are unsigned if AccessKeyID is empty.
+	AccessKeyID     string
+	SecretAccessKey string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// NewS3 returns a Store that keeps blobs in an S3-compatible bucket.
+func NewS3(options S3Options) (Store, error) {
+	if options.Endpoint == nil {
+		return nil, xerrors.New("s3 endpoint must be set")
+	}
+	if options.Bucket == "" {
+		return nil, xerrors.New("s3 bucket must be set")
+	}
+	if options.Region == "" {
+		options.Region = "us-east-1"
+	}
+	if options.HTTPClient == nil {
+		options.HTTPClient = http.DefaultClient
+	}
+	return &s3Store{options: options}, nil
+}
+
+type s3Store struct {
+	options S3Options
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	res, err := s.request(ctx, http.MethodPut, key, r, size)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return s3Error(res)
+	}
+	return nil
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	res, err := s.request(ctx, http.MethodGet, key, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		_ = res.Body.Close()
+		return nil, ErrNotExist
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, s3Error(res)
+	}
+	return res.Body, nil
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	res, err := s.request(ctx, http.MethodDelete, key, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return s3Error(res)
+	}
+}
+
+func (s *s3Store) request(ctx context.Context, method, key string, body io.Reader, size int64) (*http.Response, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	objectURL := *s.options.Endpoint
+	objectURL.Path = strings.TrimSuffix(objectURL.Path, "/") + "/" + s.options.Bucket + "/" + s.options.Prefix + key
+	objectURL.RawPath = ""
+	req, err := http.NewRequestWithContext(ctx, method, objectURL.String(), nil)
+	if err != nil {
+		return nil, xerrors.Errorf("create request: %w", err)
+	}
+	if body != nil {
+		req.Body = io.NopCloser(body)
+		req.ContentLength = size
+		if size == 0 {
+			req.Body = http.NoBody
+		}
+	}
+	if s.options.AccessKeyID != "" {
+		// The payload isn't hashed so uploads can be streamed.
+		signS3Request(req, "UNSIGNED-PAYLOAD", s.options.AccessKeyID, s.options.SecretAccessKey, s.options.Region, time.Now())
+	}
+	res, err := s.options.HTTPClient.Do(req)
+	if err != nil {
+		return nil, xerrors.Errorf("%s %s: %w", method, key, err)
+	}
+	return res, nil
+}
+
+func s3Error(res *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+	return xerrors.Errorf("unexpected s3 status code %d: %s", res.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// signS3Request adds an AWS Signature Version 4 authorization header to req.
+// All headers already set on the request are signed.
+// See: https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func signS3Request(req *http.Request, payloadHash, accessKeyID, secretAccessKey, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{
+		"host": req.URL.Host,
+	}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		_, _ = fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, headers[name])
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	scope := strings.Join([]string{date, region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3EscapePath URI-encodes every byte of path except unreserved characters
+// and slashes, as required for canonical requests.
+func s3EscapePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	var escaped strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			_ = escaped.WriteByte(c)
+			continue
+		}
+		_, _ = fmt.Fprintf(&escaped, "%%%02X", c)
+	}
+	return escaped.String()
+}
+
+func canonicalQuery(values url.Values) string {
+	// url.Values.Encode sorts by key, but encodes spaces as "+".
+	return strings.ReplaceAll(values.Encode(), "+", "%20")
+}
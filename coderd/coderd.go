@@ -24,10 +24,12 @@ import (
 	"cdr.dev/slog"
 	"github.com/coder/coder/buildinfo"
 	"github.com/coder/coder/coderd/awsidentity"
+	"github.com/coder/coder/coderd/blobstore"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/gitsshkey"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/insights"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/telemetry"
 	"github.com/coder/coder/coderd/tracing"
@@ -43,6 +45,10 @@ type Options struct {
 	Logger    slog.Logger
 	Database  database.Store
 	Pubsub    database.Pubsub
+	// BlobStore stores the contents of uploaded files. It defaults to
+	// storing them in the database, and otherwise falls back to reading
+	// them from the database.
+	BlobStore blobstore.Store
 
 	// CacheDir is used for caching files served by the API.
 	CacheDir string
@@ -59,6 +65,7 @@ type Options struct {
 	GoogleTokenValidator *idtoken.Validator
 	GithubOAuth2Config   *GithubOAuth2Config
 	OIDCConfig           *OIDCConfig
+	OIDCProviders        []*OIDCConfig
 	PrometheusRegistry   *prometheus.Registry
 	ICEServers           []webrtc.ICEServer
 	SecureAuthCookie     bool
@@ -97,6 +104,13 @@ func New(options *Options) *API {
 	if options.LicenseHandler == nil {
 		options.LicenseHandler = licenses()
 	}
+	if options.BlobStore == nil {
+		options.BlobStore = blobstore.NewPostgres(options.Database)
+	} else {
+		// Files uploaded while the database stored blobs must stay readable
+		// after switching to another store.
+		options.BlobStore = blobstore.WithFallback(options.BlobStore, blobstore.NewPostgres(options.Database))
+	}
 
 	siteCacheDir := options.CacheDir
 	if siteCacheDir != "" {
@@ -118,9 +132,15 @@ func New(options *Options) *API {
 		},
 	}
 	api.workspaceAgentCache = wsconncache.New(api.dialWorkspaceAgent, 0)
+	api.terminalShareViewers = newTerminalShareViewers()
+	api.insightsTracker = insights.NewTracker(options.Database)
 	oauthConfigs := &httpmw.OAuth2Configs{
-		Github: options.GithubOAuth2Config,
-		OIDC:   options.OIDCConfig,
+		Github:        options.GithubOAuth2Config,
+		OIDC:          options.OIDCConfig,
+		OIDCProviders: map[string]httpmw.OAuth2Config{},
+	}
+	for _, provider := range options.OIDCProviders {
+		oauthConfigs.OIDCProviders[provider.Name] = provider
 	}
 	apiKeyMiddleware := httpmw.ExtractAPIKey(options.Database, oauthConfigs, false)
 
@@ -197,12 +217,15 @@ func New(options *Options) *API {
 			r.Use(
 				apiKeyMiddleware,
 			)
+			r.Get("/", api.organizations)
 			r.Post("/", api.postOrganizations)
 			r.Route("/{organization}", func(r chi.Router) {
 				r.Use(
 					httpmw.ExtractOrganizationParam(options.Database),
 				)
 				r.Get("/", api.organization)
+				r.Patch("/", api.patchOrganization)
+				r.Delete("/", api.deleteOrganization)
 				r.Post("/templateversions", api.postTemplateVersionsByOrganization)
 				r.Route("/templates", func(r chi.Router) {
 					r.Post("/", api.postTemplateByOrganization)
@@ -211,13 +234,20 @@ func New(options *Options) *API {
 				})
 				r.Post("/workspaces", api.postWorkspacesByOrganization)
 				r.Route("/members", func(r chi.Router) {
+					r.Get("/", api.organizationMembers)
 					r.Get("/roles", api.assignableOrgRoles)
 					r.Route("/{user}", func(r chi.Router) {
 						r.Use(
 							httpmw.ExtractUserParam(options.Database),
-							httpmw.ExtractOrganizationMemberParam(options.Database),
 						)
-						r.Put("/roles", api.putMemberRoles)
+						r.Post("/", api.postOrganizationMember)
+						r.Group(func(r chi.Router) {
+							r.Use(
+								httpmw.ExtractOrganizationMemberParam(options.Database),
+							)
+							r.Delete("/", api.deleteOrganizationMember)
+							r.Put("/roles", api.putMemberRoles)
+						})
 					})
 				})
 			})
@@ -239,6 +269,16 @@ func New(options *Options) *API {
 			r.Get("/", api.template)
 			r.Delete("/", api.deleteTemplate)
 			r.Patch("/", api.patchTemplateMeta)
+			r.Put("/organization", api.putTemplateOrganization)
+			r.Get("/prebuilds", api.templatePrebuilds)
+			r.Put("/prebuilds", api.putTemplatePrebuilds)
+			r.Get("/insights", api.templateInsights)
+			r.Route("/git-source", func(r chi.Router) {
+				r.Get("/", api.templateGitSource)
+				r.Put("/", api.putTemplateGitSource)
+				r.Delete("/", api.deleteTemplateGitSource)
+				r.Post("/sync", api.postTemplateGitSourceSync)
+			})
 			r.Route("/versions", func(r chi.Router) {
 				r.Get("/", api.templateVersionsByTemplate)
 				r.Patch("/", api.patchActiveTemplateVersion)
@@ -255,8 +295,11 @@ func New(options *Options) *API {
 			r.Patch("/cancel", api.patchCancelTemplateVersion)
 			r.Get("/schema", api.templateVersionSchema)
 			r.Get("/parameters", api.templateVersionParameters)
+			r.Get("/variables", api.templateVersionVariables)
+			r.Get("/presets", api.templateVersionPresets)
 			r.Get("/resources", api.templateVersionResources)
 			r.Get("/logs", api.templateVersionLogs)
+			r.Get("/diff/{target}", api.templateVersionDiff)
 			r.Route("/dry-run", func(r chi.Router) {
 				r.Post("/", api.postTemplateVersionDryRun)
 				r.Get("/{jobID}", api.templateVersionDryRun)
@@ -278,8 +321,14 @@ func New(options *Options) *API {
 			})
 			r.Route("/oidc/callback", func(r chi.Router) {
 				r.Use(httpmw.ExtractOAuth2(options.OIDCConfig))
-				r.Get("/", api.userOIDC)
+				r.Get("/", api.userOIDC(options.OIDCConfig))
 			})
+			for _, provider := range options.OIDCProviders {
+				r.Route(fmt.Sprintf("/oidc/%s/callback", provider.Name), func(r chi.Router) {
+					r.Use(httpmw.ExtractOAuth2(provider))
+					r.Get("/", api.userOIDC(provider))
+				})
+			}
 			r.Group(func(r chi.Router) {
 				r.Use(
 					apiKeyMiddleware,
@@ -339,6 +388,8 @@ func New(options *Options) *API {
 				r.Get("/iceservers", api.workspaceAgentICEServers)
 				r.Get("/wireguardlisten", api.workspaceAgentWireguardListener)
 				r.Post("/keys", api.postWorkspaceAgentKeys)
+				r.Post("/sessions", api.postWorkspaceAgentSessionRecording)
+				r.Post("/health", api.postWorkspaceAgentHealth)
 				r.Get("/derp", api.derpMap)
 			})
 			r.Route("/{workspaceagent}", func(r chi.Router) {
@@ -354,8 +405,28 @@ func New(options *Options) *API {
 				r.Get("/pty", api.workspaceAgentPTY)
 				r.Get("/iceservers", api.workspaceAgentICEServers)
 				r.Get("/derp", api.derpMap)
+				r.Get("/listening-ports", api.workspaceAgentListeningPorts)
+				r.Post("/sessionrecordingtoken", api.postWorkspaceAgentSessionRecordingToken)
+				r.Route("/sessions", func(r chi.Router) {
+					r.Get("/", api.workspaceAgentSessions)
+					r.Delete("/{workspaceagentsession}", api.deleteWorkspaceAgentSession)
+				})
+				r.Route("/terminalshares", func(r chi.Router) {
+					r.Get("/", api.terminalShares)
+					r.Post("/", api.postTerminalShare)
+				})
 			})
 		})
+		r.Route("/terminalshares/{terminalshare}", func(r chi.Router) {
+			r.Use(
+				apiKeyMiddleware,
+				httpmw.ExtractTerminalShareParam(options.Database),
+				httpmw.ExtractWorkspaceParam(options.Database),
+			)
+			r.Get("/", api.terminalShare)
+			r.Delete("/", api.deleteTerminalShare)
+			r.Get("/pty", api.terminalSharePTY)
+		})
 		r.Route("/workspaceresources/{workspaceresource}", func(r chi.Router) {
 			r.Use(
 				apiKeyMiddleware,
@@ -388,6 +459,11 @@ func New(options *Options) *API {
 				})
 				r.Get("/watch", api.watchWorkspace)
 				r.Put("/extend", api.putExtendWorkspace)
+				r.Put("/dormant", api.putWorkspaceDormant)
+				r.Route("/sessions", func(r chi.Router) {
+					r.Get("/", api.workspaceSessionRecordings)
+					r.Get("/{sessionrecording}", api.workspaceSessionRecording)
+				})
 			})
 		})
 		r.Route("/workspacebuilds/{workspacebuild}", func(r chi.Router) {
@@ -401,6 +477,7 @@ func New(options *Options) *API {
 			r.Get("/logs", api.workspaceBuildLogs)
 			r.Get("/resources", api.workspaceBuildResources)
 			r.Get("/state", api.workspaceBuildState)
+			r.Get("/timings", api.workspaceBuildTimings)
 		})
 		r.Route("/entitlements", func(r chi.Router) {
 			r.Use(apiKeyMiddleware)
@@ -425,6 +502,9 @@ type API struct {
 	websocketWaitGroup  sync.WaitGroup
 	workspaceAgentCache *wsconncache.Cache
 	httpAuth            *HTTPAuthorizer
+
+	terminalShareViewers *terminalShareViewers
+	insightsTracker      *insights.Tracker
 }
 
 // Close waits for all WebSocket connections to drain before returning.
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/xerrors"
@@ -118,6 +119,13 @@ func NewAuthTester(ctx context.Context, t *testing.T, options *Options) *AuthTes
 	})
 	require.NoError(t, err, "create template param")
 
+	terminalShare, err := client.CreateTerminalShare(ctx, workspaceResources[0].Agents[0].ID, codersdk.CreateTerminalShareRequest{
+		ReconnectID: uuid.New(),
+		Mode:        codersdk.TerminalShareModeReadOnly,
+		Recipients:  []uuid.UUID{admin.UserID},
+	})
+	require.NoError(t, err, "create terminal share")
+
 	urlParameters := map[string]string{
 		"{organization}":       admin.OrganizationID.String(),
 		"{user}":               admin.UserID.String(),
@@ -134,7 +142,9 @@ func NewAuthTester(ctx context.Context, t *testing.T, options *Options) *AuthTes
 		"{workspaceapp}":       workspaceResources[0].Agents[0].Apps[0].Name,
 		"{templateversion}":    version.ID.String(),
 		"{jobID}":              templateVersionDryRun.ID.String(),
+		"{target}":             version.ID.String(),
 		"{templatename}":       template.Name,
+		"{terminalshare}":      terminalShare.ID.String(),
 		// Only checking template scoped params here
 		"parameters/{scope}/{id}": fmt.Sprintf("parameters/%s/%s",
 			string(templateParam.Scope), templateParam.ScopeID.String()),
@@ -203,12 +213,35 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 		"GET:/api/v2/workspaceagents/me/derp":                     {NoAuthorize: true},
 		"GET:/api/v2/workspaceagents/me/wireguardlisten":          {NoAuthorize: true},
 		"POST:/api/v2/workspaceagents/me/keys":                    {NoAuthorize: true},
+		"POST:/api/v2/workspaceagents/me/sessions":                {NoAuthorize: true},
+		"POST:/api/v2/workspaceagents/me/health":                  {NoAuthorize: true},
 		"GET:/api/v2/workspaceagents/{workspaceagent}/iceservers": {NoAuthorize: true},
 		"GET:/api/v2/workspaceagents/{workspaceagent}/derp":       {NoAuthorize: true},
 
 		// These endpoints have more assertions. This is good, add more endpoints to assert if you can!
 		"GET:/api/v2/organizations/{organization}": {AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID)},
 		"GET:/api/v2/users/{user}/organizations":   {StatusCode: http.StatusOK, AssertObject: rbac.ResourceOrganization},
+		"GET:/api/v2/organizations":                {StatusCode: http.StatusOK, AssertObject: rbac.ResourceOrganization},
+		"PATCH:/api/v2/organizations/{organization}": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"DELETE:/api/v2/organizations/{organization}": {
+			AssertAction: rbac.ActionDelete,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"GET:/api/v2/organizations/{organization}/members": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceOrganizationMember.InOrg(a.Admin.OrganizationID),
+		},
+		"POST:/api/v2/organizations/{organization}/members/{user}": {
+			AssertAction: rbac.ActionCreate,
+			AssertObject: rbac.ResourceOrganizationMember.InOrg(a.Admin.OrganizationID),
+		},
+		"DELETE:/api/v2/organizations/{organization}/members/{user}": {
+			AssertAction: rbac.ActionDelete,
+			AssertObject: rbac.ResourceOrganizationMember.InOrg(a.Admin.OrganizationID),
+		},
 		"GET:/api/v2/users/{user}/workspace/{workspacename}": {
 			AssertObject: rbac.ResourceWorkspace,
 			AssertAction: rbac.ActionRead,
@@ -245,6 +278,18 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 			AssertAction: rbac.ActionUpdate,
 			AssertObject: workspaceRBACObj,
 		},
+		"PUT:/api/v2/workspaces/{workspace}/dormant": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: workspaceRBACObj,
+		},
+		"GET:/api/v2/workspaces/{workspace}/sessions": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: workspaceRBACObj,
+		},
+		"GET:/api/v2/workspaces/{workspace}/sessions/{sessionrecording}": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: workspaceRBACObj,
+		},
 		"GET:/api/v2/workspaceresources/{workspaceresource}": {
 			AssertAction: rbac.ActionRead,
 			AssertObject: workspaceRBACObj,
@@ -261,6 +306,10 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 			AssertAction: rbac.ActionRead,
 			AssertObject: workspaceRBACObj,
 		},
+		"GET:/api/v2/workspacebuilds/{workspacebuild}/timings": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: workspaceRBACObj,
+		},
 		"GET:/api/v2/workspaceagents/{workspaceagent}": {
 			AssertAction: rbac.ActionRead,
 			AssertObject: workspaceRBACObj,
@@ -277,6 +326,42 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 			AssertAction: rbac.ActionCreate,
 			AssertObject: workspaceExecObj,
 		},
+		"GET:/api/v2/workspaceagents/{workspaceagent}/listening-ports": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: workspaceRBACObj,
+		},
+		"POST:/api/v2/workspaceagents/{workspaceagent}/sessionrecordingtoken": {
+			AssertAction: rbac.ActionCreate,
+			AssertObject: workspaceExecObj,
+		},
+		"GET:/api/v2/workspaceagents/{workspaceagent}/sessions": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: workspaceRBACObj,
+		},
+		"DELETE:/api/v2/workspaceagents/{workspaceagent}/sessions/{workspaceagentsession}": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: workspaceRBACObj,
+		},
+		"GET:/api/v2/workspaceagents/{workspaceagent}/terminalshares": {
+			AssertAction: rbac.ActionCreate,
+			AssertObject: workspaceExecObj,
+		},
+		"POST:/api/v2/workspaceagents/{workspaceagent}/terminalshares": {
+			AssertAction: rbac.ActionCreate,
+			AssertObject: workspaceExecObj,
+		},
+		"GET:/api/v2/terminalshares/{terminalshare}": {
+			AssertAction: rbac.ActionCreate,
+			AssertObject: workspaceExecObj,
+		},
+		"DELETE:/api/v2/terminalshares/{terminalshare}": {
+			AssertAction: rbac.ActionCreate,
+			AssertObject: workspaceExecObj,
+		},
+		"GET:/api/v2/terminalshares/{terminalshare}/pty": {
+			AssertAction: rbac.ActionCreate,
+			AssertObject: workspaceExecObj,
+		},
 		"GET:/api/v2/workspaces/": {
 			StatusCode:   http.StatusOK,
 			AssertAction: rbac.ActionRead,
@@ -295,10 +380,42 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 			AssertAction: rbac.ActionDelete,
 			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
 		},
+		"PUT:/api/v2/templates/{template}/organization": {
+			AssertAction: rbac.ActionDelete,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
 		"GET:/api/v2/templates/{template}": {
 			AssertAction: rbac.ActionRead,
 			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
 		},
+		"GET:/api/v2/templates/{template}/prebuilds": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"PUT:/api/v2/templates/{template}/prebuilds": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"GET:/api/v2/templates/{template}/insights": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"GET:/api/v2/templates/{template}/git-source": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"PUT:/api/v2/templates/{template}/git-source": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"DELETE:/api/v2/templates/{template}/git-source": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"POST:/api/v2/templates/{template}/git-source/sync": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
 		"POST:/api/v2/files": {AssertAction: rbac.ActionCreate, AssertObject: rbac.ResourceFile},
 		"GET:/api/v2/files/{hash}": {
 			AssertAction: rbac.ActionRead,
@@ -332,6 +449,14 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 			AssertAction: rbac.ActionRead,
 			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
 		},
+		"GET:/api/v2/templateversions/{templateversion}/presets": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"GET:/api/v2/templateversions/{templateversion}/variables": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
 		"GET:/api/v2/templateversions/{templateversion}/resources": {
 			AssertAction: rbac.ActionRead,
 			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
@@ -340,6 +465,10 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 			AssertAction: rbac.ActionRead,
 			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
 		},
+		"GET:/api/v2/templateversions/{templateversion}/diff/{target}": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
 		"POST:/api/v2/templateversions/{templateversion}/dry-run": {
 			// The first check is to read the template
 			AssertAction: rbac.ActionRead,
@@ -3,6 +3,7 @@ package databasefake
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"sort"
 	"strings"
 	"sync"
@@ -29,6 +30,7 @@ func New() database.Store {
 
 			auditLogs:                      make([]database.AuditLog, 0),
 			files:                          make([]database.File, 0),
+			fileBlobs:                      make(map[string][]byte),
 			gitSSHKey:                      make([]database.GitSSHKey, 0),
 			parameterSchemas:               make([]database.ParameterSchema, 0),
 			parameterValues:                make([]database.ParameterValue, 0),
@@ -80,19 +82,29 @@ type data struct {
 	// New tables
 	auditLogs                      []database.AuditLog
 	files                          []database.File
+	fileBlobs                      map[string][]byte
 	gitSSHKey                      []database.GitSSHKey
 	parameterSchemas               []database.ParameterSchema
 	parameterValues                []database.ParameterValue
 	provisionerDaemons             []database.ProvisionerDaemon
 	provisionerJobAgents           []database.WorkspaceAgent
 	provisionerJobLogs             []database.ProvisionerJobLog
+	provisionerJobTimings          []database.ProvisionerJobTiming
 	provisionerJobResources        []database.WorkspaceResource
 	provisionerJobResourceMetadata []database.WorkspaceResourceMetadatum
 	provisionerJobs                []database.ProvisionerJob
+	templateGitSources             []database.TemplateGitSource
+	templateInsights               []database.TemplateInsight
+	templateUsageStats             []database.TemplateUsageStat
 	templateVersions               []database.TemplateVersion
+	templateVersionPresets         []database.TemplateVersionPreset
+	templateVersionVariables       []database.TemplateVersionVariable
 	templates                      []database.Template
+	terminalShares                 []database.TerminalShare
 	workspaceBuilds                []database.WorkspaceBuild
 	workspaceApps                  []database.WorkspaceApp
+	workspaceSessionRecordings     []database.WorkspaceSessionRecording
+	sessionRecordingTokens         []database.WorkspaceSessionRecordingToken
 	workspaces                     []database.Workspace
 	licenses                       []database.License
 
@@ -407,6 +419,12 @@ func (q *fakeQuerier) GetWorkspaces(_ context.Context, arg database.GetWorkspace
 		if !arg.Deleted && workspace.Deleted {
 			continue
 		}
+		if arg.ExcludeDormant && workspace.DormantAt.Valid {
+			continue
+		}
+		if arg.ExcludePrebuilds && workspace.Prebuild {
+			continue
+		}
 
 		if arg.Name != "" && !strings.Contains(strings.ToLower(workspace.Name), strings.ToLower(arg.Name)) {
 			continue
@@ -528,6 +546,91 @@ func (q *fakeQuerier) GetWorkspacesAutostart(_ context.Context) ([]database.Work
 	return workspaces, nil
 }
 
+func (q *fakeQuerier) GetWorkspacesDormancy(_ context.Context) ([]database.Workspace, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	workspaces := make([]database.Workspace, 0)
+	for _, ws := range q.workspaces {
+		if ws.Deleted {
+			continue
+		}
+		for _, template := range q.templates {
+			if template.ID != ws.TemplateID {
+				continue
+			}
+			if (!ws.DormantAt.Valid && template.InactivityTtl > 0) ||
+				(ws.DormantAt.Valid && template.DormantAutodeleteTtl > 0) {
+				workspaces = append(workspaces, ws)
+			}
+			break
+		}
+	}
+	return workspaces, nil
+}
+
+func (q *fakeQuerier) GetWorkspacesMaintenance(_ context.Context) ([]database.Workspace, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	workspaces := make([]database.Workspace, 0)
+	for _, ws := range q.workspaces {
+		if ws.Deleted {
+			continue
+		}
+		for _, template := range q.templates {
+			if template.ID != ws.TemplateID {
+				continue
+			}
+			if template.MaintenanceWindow != "" && template.MaintenanceRestartOutdated {
+				workspaces = append(workspaces, ws)
+			}
+			break
+		}
+	}
+	return workspaces, nil
+}
+
+func (q *fakeQuerier) GetPrebuiltWorkspacesByTemplateID(_ context.Context, templateID uuid.UUID) ([]database.Workspace, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	workspaces := make([]database.Workspace, 0)
+	for _, ws := range q.workspaces {
+		if ws.TemplateID != templateID || !ws.Prebuild || ws.Deleted {
+			continue
+		}
+		workspaces = append(workspaces, ws)
+	}
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].CreatedAt.Before(workspaces[j].CreatedAt)
+	})
+	return workspaces, nil
+}
+
+func (q *fakeQuerier) ClaimPrebuiltWorkspace(_ context.Context, arg database.ClaimPrebuiltWorkspaceParams) (database.Workspace, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, workspace := range q.workspaces {
+		if workspace.ID != arg.ID || !workspace.Prebuild {
+			continue
+		}
+		workspace.OwnerID = arg.OwnerID
+		workspace.Name = arg.Name
+		workspace.AutostartSchedule = arg.AutostartSchedule
+		workspace.Ttl = arg.Ttl
+		workspace.UpdatedAt = arg.UpdatedAt
+		workspace.LastUsedAt = arg.UpdatedAt
+		workspace.Prebuild = false
+		workspace.Preset = arg.Preset
+		q.workspaces[index] = workspace
+		return workspace, nil
+	}
+
+	return database.Workspace{}, sql.ErrNoRows
+}
+
 func (q *fakeQuerier) GetWorkspaceOwnerCountsByTemplateIDs(_ context.Context, templateIDs []uuid.UUID) ([]database.GetWorkspaceOwnerCountsByTemplateIDsRow, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -815,6 +918,82 @@ func (q *fakeQuerier) GetOrganizationsByUserID(_ context.Context, userID uuid.UU
 	return organizations, nil
 }
 
+func (q *fakeQuerier) UpdateOrganizationByID(_ context.Context, arg database.UpdateOrganizationByIDParams) (database.Organization, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, organization := range q.organizations {
+		if organization.ID != arg.ID {
+			continue
+		}
+		organization.Name = arg.Name
+		organization.Description = arg.Description
+		organization.UpdatedAt = arg.UpdatedAt
+		q.organizations[index] = organization
+		return organization, nil
+	}
+	return database.Organization{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteOrganizationByID(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, workspace := range q.workspaces {
+		if workspace.OrganizationID == id {
+			return &pq.Error{
+				Code:       "23503",
+				Message:    "update or delete on table \"organizations\" violates foreign key constraint \"workspaces_organization_id_fkey\" on table \"workspaces\"",
+				Constraint: "workspaces_organization_id_fkey",
+			}
+		}
+	}
+
+	for index, organization := range q.organizations {
+		if organization.ID != id {
+			continue
+		}
+		q.organizations[index] = q.organizations[len(q.organizations)-1]
+		q.organizations = q.organizations[:len(q.organizations)-1]
+
+		// Organization members and templates are deleted by a cascading
+		// foreign key.
+		members := make([]database.OrganizationMember, 0, len(q.organizationMembers))
+		for _, member := range q.organizationMembers {
+			if member.OrganizationID == id {
+				continue
+			}
+			members = append(members, member)
+		}
+		q.organizationMembers = members
+		templates := make([]database.Template, 0, len(q.templates))
+		for _, template := range q.templates {
+			if template.OrganizationID == id {
+				continue
+			}
+			templates = append(templates, template)
+		}
+		q.templates = templates
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteDeletedWorkspacesByOrganizationID(_ context.Context, organizationID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	workspaces := make([]database.Workspace, 0, len(q.workspaces))
+	for _, workspace := range q.workspaces {
+		if workspace.OrganizationID == organizationID && workspace.Deleted {
+			continue
+		}
+		workspaces = append(workspaces, workspace)
+	}
+	q.workspaces = workspaces
+	return nil
+}
+
 func (q *fakeQuerier) ParameterValues(_ context.Context, arg database.ParameterValuesParams) ([]database.ParameterValue, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -890,6 +1069,30 @@ func (q *fakeQuerier) UpdateTemplateMetaByID(_ context.Context, arg database.Upd
 		tpl.Icon = arg.Icon
 		tpl.MaxTtl = arg.MaxTtl
 		tpl.MinAutostartInterval = arg.MinAutostartInterval
+		tpl.InactivityTtl = arg.InactivityTtl
+		tpl.DormantAutodeleteTtl = arg.DormantAutodeleteTtl
+		tpl.MaintenanceWindow = arg.MaintenanceWindow
+		tpl.MaintenanceWindowDuration = arg.MaintenanceWindowDuration
+		tpl.MaintenanceRestartOutdated = arg.MaintenanceRestartOutdated
+		tpl.RecordSessions = arg.RecordSessions
+		q.templates[idx] = tpl
+		return nil
+	}
+
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateTemplatePrebuildsByID(_ context.Context, arg database.UpdateTemplatePrebuildsByIDParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for idx, tpl := range q.templates {
+		if tpl.ID != arg.ID {
+			continue
+		}
+		tpl.UpdatedAt = arg.UpdatedAt
+		tpl.PrebuildCount = arg.PrebuildCount
+		tpl.PrebuildParameters = arg.PrebuildParameters
 		q.templates[idx] = tpl
 		return nil
 	}
@@ -897,6 +1100,29 @@ func (q *fakeQuerier) UpdateTemplateMetaByID(_ context.Context, arg database.Upd
 	return sql.ErrNoRows
 }
 
+func (q *fakeQuerier) GetTemplatesWithPrebuilds(_ context.Context) ([]database.Template, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	templates := make([]database.Template, 0)
+	for _, template := range q.templates {
+		if template.Deleted {
+			continue
+		}
+		if template.PrebuildCount > 0 {
+			templates = append(templates, template)
+			continue
+		}
+		for _, ws := range q.workspaces {
+			if ws.TemplateID == template.ID && ws.Prebuild && !ws.Deleted {
+				templates = append(templates, template)
+				break
+			}
+		}
+	}
+	return templates, nil
+}
+
 func (q *fakeQuerier) GetTemplatesWithFilter(_ context.Context, arg database.GetTemplatesWithFilterParams) ([]database.Template, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -1013,6 +1239,83 @@ func (q *fakeQuerier) GetTemplateVersionsCreatedAfter(_ context.Context, after t
 	return versions, nil
 }
 
+func (q *fakeQuerier) GetTemplateInsights(_ context.Context, arg database.GetTemplateInsightsParams) ([]database.TemplateInsight, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	insights := make([]database.TemplateInsight, 0)
+	for _, insight := range q.templateInsights {
+		if insight.TemplateID != arg.TemplateID {
+			continue
+		}
+		if insight.Date.Before(arg.StartDate) || !insight.Date.Before(arg.EndDate) {
+			continue
+		}
+		insights = append(insights, insight)
+	}
+	if len(insights) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	sort.Slice(insights, func(i, j int) bool {
+		return insights[i].Date.Before(insights[j].Date)
+	})
+	return insights, nil
+}
+
+func (q *fakeQuerier) GetTemplateUsageStatsAfter(_ context.Context, date time.Time) ([]database.TemplateUsageStat, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	stats := make([]database.TemplateUsageStat, 0)
+	for _, stat := range q.templateUsageStats {
+		if !stat.Date.Before(date) {
+			stats = append(stats, stat)
+		}
+	}
+	if len(stats) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return stats, nil
+}
+
+func (q *fakeQuerier) GetTemplateVersionPresets(_ context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionPreset, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	presets := make([]database.TemplateVersionPreset, 0)
+	for _, preset := range q.templateVersionPresets {
+		if preset.TemplateVersionID == templateVersionID {
+			presets = append(presets, preset)
+		}
+	}
+	if len(presets) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	sort.Slice(presets, func(i, j int) bool {
+		return presets[i].Index < presets[j].Index
+	})
+	return presets, nil
+}
+
+func (q *fakeQuerier) GetTemplateVersionVariables(_ context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionVariable, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	variables := make([]database.TemplateVersionVariable, 0)
+	for _, variable := range q.templateVersionVariables {
+		if variable.TemplateVersionID == templateVersionID {
+			variables = append(variables, variable)
+		}
+	}
+	if len(variables) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	sort.Slice(variables, func(i, j int) bool {
+		return variables[i].Name < variables[j].Name
+	})
+	return variables, nil
+}
+
 func (q *fakeQuerier) GetTemplateVersionByTemplateIDAndName(_ context.Context, arg database.GetTemplateVersionByTemplateIDAndNameParams) (database.TemplateVersion, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -1176,6 +1479,38 @@ func (q *fakeQuerier) GetOrganizationMembershipsByUserID(_ context.Context, user
 	return memberships, nil
 }
 
+func (q *fakeQuerier) GetOrganizationMembersByOrganizationID(_ context.Context, organizationID uuid.UUID) ([]database.OrganizationMember, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	members := make([]database.OrganizationMember, 0)
+	for _, organizationMember := range q.organizationMembers {
+		if organizationMember.OrganizationID != organizationID {
+			continue
+		}
+		members = append(members, organizationMember)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].CreatedAt.Before(members[j].CreatedAt)
+	})
+	return members, nil
+}
+
+func (q *fakeQuerier) DeleteOrganizationMember(_ context.Context, arg database.DeleteOrganizationMemberParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, member := range q.organizationMembers {
+		if member.OrganizationID != arg.OrganizationID || member.UserID != arg.UserID {
+			continue
+		}
+		q.organizationMembers[index] = q.organizationMembers[len(q.organizationMembers)-1]
+		q.organizationMembers = q.organizationMembers[:len(q.organizationMembers)-1]
+		return nil
+	}
+	return nil
+}
+
 func (q *fakeQuerier) UpdateMemberRoles(_ context.Context, arg database.UpdateMemberRolesParams) (database.OrganizationMember, error) {
 	for i, mem := range q.organizationMembers {
 		if mem.UserID == arg.UserID && mem.OrganizationID == arg.OrgID {
@@ -1353,6 +1688,22 @@ func (q *fakeQuerier) GetWorkspaceResourcesByJobID(_ context.Context, jobID uuid
 	return resources, nil
 }
 
+func (q *fakeQuerier) GetWorkspaceResourcesByJobIDs(_ context.Context, jobIDs []uuid.UUID) ([]database.WorkspaceResource, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	resources := make([]database.WorkspaceResource, 0)
+	for _, resource := range q.provisionerJobResources {
+		for _, jobID := range jobIDs {
+			if resource.JobID != jobID {
+				continue
+			}
+			resources = append(resources, resource)
+		}
+	}
+	return resources, nil
+}
+
 func (q *fakeQuerier) GetWorkspaceResourcesCreatedAfter(_ context.Context, after time.Time) ([]database.WorkspaceResource, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -1470,6 +1821,26 @@ func (q *fakeQuerier) GetProvisionerLogsByIDBetween(_ context.Context, arg datab
 	return logs, nil
 }
 
+func (q *fakeQuerier) GetProvisionerJobTimingsByJobID(_ context.Context, jobID uuid.UUID) ([]database.ProvisionerJobTiming, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	timings := make([]database.ProvisionerJobTiming, 0)
+	for _, timing := range q.provisionerJobTimings {
+		if timing.JobID != jobID {
+			continue
+		}
+		timings = append(timings, timing)
+	}
+	if len(timings) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	sort.SliceStable(timings, func(i, j int) bool {
+		return timings[i].StartedAt.Before(timings[j].StartedAt)
+	})
+	return timings, nil
+}
+
 func (q *fakeQuerier) InsertAPIKey(_ context.Context, arg database.InsertAPIKeyParams) (database.APIKey, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -1499,51 +1870,145 @@ func (q *fakeQuerier) InsertFile(_ context.Context, arg database.InsertFileParam
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
+	for _, file := range q.files {
+		if file.Hash == arg.Hash {
+			return database.File{}, &pq.Error{Code: "23505"}
+		}
+	}
 	//nolint:gosimple
 	file := database.File{
 		Hash:      arg.Hash,
 		CreatedAt: arg.CreatedAt,
 		CreatedBy: arg.CreatedBy,
 		Mimetype:  arg.Mimetype,
-		Data:      arg.Data,
 	}
 	q.files = append(q.files, file)
 	return file, nil
 }
 
-func (q *fakeQuerier) InsertOrganization(_ context.Context, arg database.InsertOrganizationParams) (database.Organization, error) {
+func (q *fakeQuerier) UpdateFileCreatedAtByHash(_ context.Context, arg database.UpdateFileCreatedAtByHashParams) (database.File, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
-	organization := database.Organization{
-		ID:        arg.ID,
-		Name:      arg.Name,
-		CreatedAt: arg.CreatedAt,
-		UpdatedAt: arg.UpdatedAt,
+	for index, file := range q.files {
+		if file.Hash != arg.Hash {
+			continue
+		}
+		file.CreatedAt = arg.CreatedAt
+		q.files[index] = file
+		return file, nil
 	}
-	q.organizations = append(q.organizations, organization)
-	return organization, nil
+	return database.File{}, sql.ErrNoRows
 }
 
-func (q *fakeQuerier) InsertOrganizationMember(_ context.Context, arg database.InsertOrganizationMemberParams) (database.OrganizationMember, error) {
+func (q *fakeQuerier) DeleteUnreferencedFileByHash(_ context.Context, arg database.DeleteUnreferencedFileByHashParams) (database.File, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
-	//nolint:gosimple
-	organizationMember := database.OrganizationMember{
-		OrganizationID: arg.OrganizationID,
-		UserID:         arg.UserID,
-		CreatedAt:      arg.CreatedAt,
-		UpdatedAt:      arg.UpdatedAt,
-		Roles:          arg.Roles,
+	for _, job := range q.provisionerJobs {
+		if job.StorageMethod == database.ProvisionerStorageMethodFile && job.StorageSource == arg.Hash {
+			return database.File{}, sql.ErrNoRows
+		}
 	}
-	q.organizationMembers = append(q.organizationMembers, organizationMember)
-	return organizationMember, nil
+	for index, file := range q.files {
+		if file.Hash != arg.Hash || !file.CreatedAt.Before(arg.CreatedAt) {
+			continue
+		}
+		q.files[index] = q.files[len(q.files)-1]
+		q.files = q.files[:len(q.files)-1]
+		return file, nil
+	}
+	return database.File{}, sql.ErrNoRows
 }
 
-func (q *fakeQuerier) InsertParameterValue(_ context.Context, arg database.InsertParameterValueParams) (database.ParameterValue, error) {
-	q.mutex.Lock()
-	defer q.mutex.Unlock()
+func (q *fakeQuerier) GetUnreferencedFiles(_ context.Context, createdAt time.Time) ([]database.File, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	referenced := map[string]struct{}{}
+	for _, job := range q.provisionerJobs {
+		if job.StorageMethod == database.ProvisionerStorageMethodFile {
+			referenced[job.StorageSource] = struct{}{}
+		}
+	}
+	files := make([]database.File, 0)
+	for _, file := range q.files {
+		if _, ok := referenced[file.Hash]; ok || !file.CreatedAt.Before(createdAt) {
+			continue
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return files, nil
+}
+
+func (q *fakeQuerier) GetFileBlobByKey(_ context.Context, key string) (database.FileBlob, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	data, ok := q.fileBlobs[key]
+	if !ok {
+		return database.FileBlob{}, sql.ErrNoRows
+	}
+	return database.FileBlob{
+		Key:  key,
+		Data: data,
+	}, nil
+}
+
+func (q *fakeQuerier) InsertFileBlob(_ context.Context, arg database.InsertFileBlobParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if _, ok := q.fileBlobs[arg.Key]; !ok {
+		q.fileBlobs[arg.Key] = arg.Data
+	}
+	return nil
+}
+
+func (q *fakeQuerier) DeleteFileBlobByKey(_ context.Context, key string) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	delete(q.fileBlobs, key)
+	return nil
+}
+
+func (q *fakeQuerier) InsertOrganization(_ context.Context, arg database.InsertOrganizationParams) (database.Organization, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	organization := database.Organization{
+		ID:        arg.ID,
+		Name:      arg.Name,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+	}
+	q.organizations = append(q.organizations, organization)
+	return organization, nil
+}
+
+func (q *fakeQuerier) InsertOrganizationMember(_ context.Context, arg database.InsertOrganizationMemberParams) (database.OrganizationMember, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	organizationMember := database.OrganizationMember{
+		OrganizationID: arg.OrganizationID,
+		UserID:         arg.UserID,
+		CreatedAt:      arg.CreatedAt,
+		UpdatedAt:      arg.UpdatedAt,
+		Roles:          arg.Roles,
+	}
+	q.organizationMembers = append(q.organizationMembers, organizationMember)
+	return organizationMember, nil
+}
+
+func (q *fakeQuerier) InsertParameterValue(_ context.Context, arg database.InsertParameterValueParams) (database.ParameterValue, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
 
 	//nolint:gosimple
 	parameterValue := database.ParameterValue{
@@ -1582,6 +2047,7 @@ func (q *fakeQuerier) InsertTemplate(_ context.Context, arg database.InsertTempl
 		MaxTtl:               arg.MaxTtl,
 		MinAutostartInterval: arg.MinAutostartInterval,
 		CreatedBy:            arg.CreatedBy,
+		PrebuildParameters:   json.RawMessage("[]"),
 	}
 	q.templates = append(q.templates, template)
 	return template, nil
@@ -1602,11 +2068,75 @@ func (q *fakeQuerier) InsertTemplateVersion(_ context.Context, arg database.Inse
 		Readme:         arg.Readme,
 		JobID:          arg.JobID,
 		CreatedBy:      arg.CreatedBy,
+		Message:        arg.Message,
+		GitMetadata:    arg.GitMetadata,
+		Diagnostics:    json.RawMessage("[]"),
 	}
 	q.templateVersions = append(q.templateVersions, version)
 	return version, nil
 }
 
+func (q *fakeQuerier) InsertTemplateUsageStat(_ context.Context, arg database.InsertTemplateUsageStatParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, stat := range q.templateUsageStats {
+		if stat.TemplateID == arg.TemplateID && stat.UserID == arg.UserID &&
+			stat.Date.Equal(arg.Date) && stat.AppName == arg.AppName {
+			return nil
+		}
+	}
+	//nolint:gosimple
+	q.templateUsageStats = append(q.templateUsageStats, database.TemplateUsageStat{
+		TemplateID: arg.TemplateID,
+		UserID:     arg.UserID,
+		Date:       arg.Date,
+		AppName:    arg.AppName,
+	})
+	return nil
+}
+
+func (q *fakeQuerier) InsertTemplateVersionPreset(_ context.Context, arg database.InsertTemplateVersionPresetParams) (database.TemplateVersionPreset, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, preset := range q.templateVersionPresets {
+		if preset.TemplateVersionID == arg.TemplateVersionID && preset.Name == arg.Name {
+			return database.TemplateVersionPreset{}, &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"}
+		}
+	}
+	//nolint:gosimple
+	preset := database.TemplateVersionPreset{
+		TemplateVersionID: arg.TemplateVersionID,
+		Name:              arg.Name,
+		Description:       arg.Description,
+		Parameters:        arg.Parameters,
+		Index:             arg.Index,
+	}
+	q.templateVersionPresets = append(q.templateVersionPresets, preset)
+	return preset, nil
+}
+
+func (q *fakeQuerier) InsertTemplateVersionVariable(_ context.Context, arg database.InsertTemplateVersionVariableParams) (database.TemplateVersionVariable, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, variable := range q.templateVersionVariables {
+		if variable.TemplateVersionID == arg.TemplateVersionID && variable.Name == arg.Name {
+			return database.TemplateVersionVariable{}, &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"}
+		}
+	}
+	//nolint:gosimple
+	variable := database.TemplateVersionVariable{
+		TemplateVersionID: arg.TemplateVersionID,
+		Name:              arg.Name,
+		Value:             arg.Value,
+		Sensitive:         arg.Sensitive,
+	}
+	q.templateVersionVariables = append(q.templateVersionVariables, variable)
+	return variable, nil
+}
+
 func (q *fakeQuerier) InsertProvisionerJobLogs(_ context.Context, arg database.InsertProvisionerJobLogsParams) ([]database.ProvisionerJobLog, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -1627,6 +2157,26 @@ func (q *fakeQuerier) InsertProvisionerJobLogs(_ context.Context, arg database.I
 	return logs, nil
 }
 
+func (q *fakeQuerier) InsertProvisionerJobTimings(_ context.Context, arg database.InsertProvisionerJobTimingsParams) ([]database.ProvisionerJobTiming, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	timings := make([]database.ProvisionerJobTiming, 0)
+	for index, stage := range arg.Stage {
+		timings = append(timings, database.ProvisionerJobTiming{
+			ID:        arg.ID[index],
+			JobID:     arg.JobID,
+			Stage:     stage,
+			Resource:  arg.Resource[index],
+			Action:    arg.Action[index],
+			StartedAt: arg.StartedAt[index],
+			EndedAt:   arg.EndedAt[index],
+		})
+	}
+	q.provisionerJobTimings = append(q.provisionerJobTimings, timings...)
+	return timings, nil
+}
+
 func (q *fakeQuerier) InsertParameterSchema(_ context.Context, arg database.InsertParameterSchemaParams) (database.ParameterSchema, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -1749,6 +2299,187 @@ func (q *fakeQuerier) InsertWorkspaceResourceMetadata(_ context.Context, arg dat
 	return metadatum, nil
 }
 
+func (q *fakeQuerier) InsertTerminalShare(_ context.Context, arg database.InsertTerminalShareParams) (database.TerminalShare, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	share := database.TerminalShare{
+		ID:          arg.ID,
+		CreatedAt:   arg.CreatedAt,
+		WorkspaceID: arg.WorkspaceID,
+		AgentID:     arg.AgentID,
+		ReconnectID: arg.ReconnectID,
+		CreatedBy:   arg.CreatedBy,
+		Mode:        arg.Mode,
+		Recipients:  arg.Recipients,
+		ExpiresAt:   arg.ExpiresAt,
+	}
+	q.terminalShares = append(q.terminalShares, share)
+	return share, nil
+}
+
+func (q *fakeQuerier) GetTerminalShareByID(_ context.Context, id uuid.UUID) (database.TerminalShare, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, share := range q.terminalShares {
+		if share.ID == id {
+			return share, nil
+		}
+	}
+	return database.TerminalShare{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetTerminalSharesByAgentID(_ context.Context, agentID uuid.UUID) ([]database.TerminalShare, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	shares := make([]database.TerminalShare, 0)
+	for _, share := range q.terminalShares {
+		if share.AgentID == agentID {
+			shares = append(shares, share)
+		}
+	}
+	if len(shares) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return shares, nil
+}
+
+func (q *fakeQuerier) DeleteTemplateVersionVariable(_ context.Context, arg database.DeleteTemplateVersionVariableParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, variable := range q.templateVersionVariables {
+		if variable.TemplateVersionID != arg.TemplateVersionID || variable.Name != arg.Name {
+			continue
+		}
+		q.templateVersionVariables = append(q.templateVersionVariables[:index], q.templateVersionVariables[index+1:]...)
+		return nil
+	}
+	return nil
+}
+
+func (q *fakeQuerier) DeleteTerminalShareByID(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, share := range q.terminalShares {
+		if share.ID != id {
+			continue
+		}
+		// Shares are listed by creation time, so keep the order.
+		q.terminalShares = append(q.terminalShares[:index], q.terminalShares[index+1:]...)
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) InsertWorkspaceSessionRecording(_ context.Context, arg database.InsertWorkspaceSessionRecordingParams) (database.WorkspaceSessionRecording, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	recording := database.WorkspaceSessionRecording{
+		ID:          arg.ID,
+		CreatedAt:   arg.CreatedAt,
+		WorkspaceID: arg.WorkspaceID,
+		AgentID:     arg.AgentID,
+		UserID:      arg.UserID,
+		Type:        arg.Type,
+		StartedAt:   arg.StartedAt,
+		EndedAt:     arg.EndedAt,
+		Data:        arg.Data,
+	}
+	q.workspaceSessionRecordings = append(q.workspaceSessionRecordings, recording)
+	return recording, nil
+}
+
+func (q *fakeQuerier) InsertWorkspaceSessionRecordingToken(_ context.Context, arg database.InsertWorkspaceSessionRecordingTokenParams) (database.WorkspaceSessionRecordingToken, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	token := database.WorkspaceSessionRecordingToken{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		AgentID:   arg.AgentID,
+		UserID:    arg.UserID,
+	}
+	q.sessionRecordingTokens = append(q.sessionRecordingTokens, token)
+	return token, nil
+}
+
+func (q *fakeQuerier) GetWorkspaceSessionRecordingTokenByID(_ context.Context, id uuid.UUID) (database.WorkspaceSessionRecordingToken, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, token := range q.sessionRecordingTokens {
+		if token.ID == id {
+			return token, nil
+		}
+	}
+	return database.WorkspaceSessionRecordingToken{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteWorkspaceSessionRecordingTokenByID(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, token := range q.sessionRecordingTokens {
+		if token.ID != id {
+			continue
+		}
+		q.sessionRecordingTokens[index] = q.sessionRecordingTokens[len(q.sessionRecordingTokens)-1]
+		q.sessionRecordingTokens = q.sessionRecordingTokens[:len(q.sessionRecordingTokens)-1]
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetWorkspaceSessionRecordingByID(_ context.Context, id uuid.UUID) (database.WorkspaceSessionRecording, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, recording := range q.workspaceSessionRecordings {
+		if recording.ID == id {
+			return recording, nil
+		}
+	}
+	return database.WorkspaceSessionRecording{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetWorkspaceSessionRecordingsByWorkspaceID(_ context.Context, workspaceID uuid.UUID) ([]database.GetWorkspaceSessionRecordingsByWorkspaceIDRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	rows := make([]database.GetWorkspaceSessionRecordingsByWorkspaceIDRow, 0)
+	for _, recording := range q.workspaceSessionRecordings {
+		if recording.WorkspaceID != workspaceID {
+			continue
+		}
+		rows = append(rows, database.GetWorkspaceSessionRecordingsByWorkspaceIDRow{
+			ID:          recording.ID,
+			CreatedAt:   recording.CreatedAt,
+			WorkspaceID: recording.WorkspaceID,
+			AgentID:     recording.AgentID,
+			UserID:      recording.UserID,
+			Type:        recording.Type,
+			StartedAt:   recording.StartedAt,
+			EndedAt:     recording.EndedAt,
+			Size:        int64(len(recording.Data)),
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].StartedAt.After(rows[j].StartedAt)
+	})
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return rows, nil
+}
+
 func (q *fakeQuerier) InsertUser(_ context.Context, arg database.InsertUserParams) (database.User, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -1860,6 +2591,8 @@ func (q *fakeQuerier) InsertWorkspace(_ context.Context, arg database.InsertWork
 		Name:              arg.Name,
 		AutostartSchedule: arg.AutostartSchedule,
 		Ttl:               arg.Ttl,
+		Prebuild:          arg.Prebuild,
+		Preset:            arg.Preset,
 	}
 	q.workspaces = append(q.workspaces, workspace)
 	return workspace, nil
@@ -1956,6 +2689,52 @@ func (q *fakeQuerier) UpdateTemplateDeletedByID(_ context.Context, arg database.
 	return sql.ErrNoRows
 }
 
+func (q *fakeQuerier) UpdateTemplateOrganizationByID(_ context.Context, arg database.UpdateTemplateOrganizationByIDParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, template := range q.templates {
+		if template.ID != arg.ID {
+			continue
+		}
+		template.OrganizationID = arg.OrganizationID
+		template.UpdatedAt = arg.UpdatedAt
+		q.templates[index] = template
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateTemplateVersionsOrganizationByTemplateID(_ context.Context, arg database.UpdateTemplateVersionsOrganizationByTemplateIDParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, templateVersion := range q.templateVersions {
+		if templateVersion.TemplateID != arg.TemplateID {
+			continue
+		}
+		templateVersion.OrganizationID = arg.OrganizationID
+		templateVersion.UpdatedAt = arg.UpdatedAt
+		q.templateVersions[index] = templateVersion
+	}
+	return nil
+}
+
+func (q *fakeQuerier) UpdateWorkspacesOrganizationByTemplateID(_ context.Context, arg database.UpdateWorkspacesOrganizationByTemplateIDParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, workspace := range q.workspaces {
+		if workspace.TemplateID != arg.TemplateID {
+			continue
+		}
+		workspace.OrganizationID = arg.OrganizationID
+		workspace.UpdatedAt = arg.UpdatedAt
+		q.workspaces[index] = workspace
+	}
+	return nil
+}
+
 func (q *fakeQuerier) UpdateTemplateVersionByID(_ context.Context, arg database.UpdateTemplateVersionByIDParams) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -1988,6 +2767,22 @@ func (q *fakeQuerier) UpdateTemplateVersionDescriptionByJobID(_ context.Context,
 	return sql.ErrNoRows
 }
 
+func (q *fakeQuerier) UpdateTemplateVersionDiagnosticsByJobID(_ context.Context, arg database.UpdateTemplateVersionDiagnosticsByJobIDParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, templateVersion := range q.templateVersions {
+		if templateVersion.JobID != arg.JobID {
+			continue
+		}
+		templateVersion.Diagnostics = arg.Diagnostics
+		templateVersion.UpdatedAt = arg.UpdatedAt
+		q.templateVersions[index] = templateVersion
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
 func (q *fakeQuerier) UpdateProvisionerDaemonByID(_ context.Context, arg database.UpdateProvisionerDaemonByIDParams) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -2040,6 +2835,38 @@ func (q *fakeQuerier) UpdateWorkspaceAgentKeysByID(_ context.Context, arg databa
 	return sql.ErrNoRows
 }
 
+func (q *fakeQuerier) UpdateWorkspaceAgentVersionByID(_ context.Context, arg database.UpdateWorkspaceAgentVersionByIDParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, agent := range q.provisionerJobAgents {
+		if agent.ID != arg.ID {
+			continue
+		}
+
+		agent.Version = arg.Version
+		q.provisionerJobAgents[index] = agent
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateWorkspaceAgentHealthChecksByID(_ context.Context, arg database.UpdateWorkspaceAgentHealthChecksByIDParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, agent := range q.provisionerJobAgents {
+		if agent.ID != arg.ID {
+			continue
+		}
+
+		agent.HealthChecks = arg.HealthChecks
+		q.provisionerJobAgents[index] = agent
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
 func (q *fakeQuerier) UpdateProvisionerJobByID(_ context.Context, arg database.UpdateProvisionerJobByIDParams) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -2145,6 +2972,38 @@ func (q *fakeQuerier) UpdateWorkspaceTTL(_ context.Context, arg database.UpdateW
 	return sql.ErrNoRows
 }
 
+func (q *fakeQuerier) UpdateWorkspaceDormantAt(_ context.Context, arg database.UpdateWorkspaceDormantAtParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, workspace := range q.workspaces {
+		if workspace.ID != arg.ID {
+			continue
+		}
+		workspace.DormantAt = arg.DormantAt
+		q.workspaces[index] = workspace
+		return nil
+	}
+
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateWorkspaceLastUsedAt(_ context.Context, arg database.UpdateWorkspaceLastUsedAtParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, workspace := range q.workspaces {
+		if workspace.ID != arg.ID {
+			continue
+		}
+		workspace.LastUsedAt = arg.LastUsedAt
+		q.workspaces[index] = workspace
+		return nil
+	}
+
+	return sql.ErrNoRows
+}
+
 func (q *fakeQuerier) UpdateWorkspaceBuildByID(_ context.Context, arg database.UpdateWorkspaceBuildByIDParams) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -2378,6 +3237,7 @@ func (q *fakeQuerier) InsertUserLink(_ context.Context, args database.InsertUser
 		OAuthAccessToken:  args.OAuthAccessToken,
 		OAuthRefreshToken: args.OAuthRefreshToken,
 		OAuthExpiry:       args.OAuthExpiry,
+		OIDCProvider:      args.OIDCProvider,
 	}
 
 	q.userLinks = append(q.userLinks, link)
@@ -2392,6 +3252,7 @@ func (q *fakeQuerier) UpdateUserLinkedID(_ context.Context, params database.Upda
 	for i, link := range q.userLinks {
 		if link.UserID == params.UserID && link.LoginType == params.LoginType {
 			link.LinkedID = params.LinkedID
+			link.OIDCProvider = params.OIDCProvider
 
 			q.userLinks[i] = link
 			return link, nil
@@ -2418,3 +3279,150 @@ func (q *fakeQuerier) UpdateUserLink(_ context.Context, params database.UpdateUs
 
 	return database.UserLink{}, sql.ErrNoRows
 }
+
+func (q *fakeQuerier) AcquireTemplateGitSourceSync(_ context.Context, arg database.AcquireTemplateGitSourceSyncParams) (database.TemplateGitSource, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, source := range q.templateGitSources {
+		if source.TemplateID != arg.TemplateID {
+			continue
+		}
+		if source.SyncStartedAt.Valid && !source.SyncStartedAt.Time.Before(arg.StaleBefore) {
+			return database.TemplateGitSource{}, sql.ErrNoRows
+		}
+		source.SyncStartedAt = sql.NullTime{
+			Time:  arg.SyncStartedAt,
+			Valid: true,
+		}
+		q.templateGitSources[index] = source
+		return source, nil
+	}
+	return database.TemplateGitSource{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetTemplateGitSourceByTemplateID(_ context.Context, templateID uuid.UUID) (database.TemplateGitSource, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, source := range q.templateGitSources {
+		if source.TemplateID == templateID {
+			return source, nil
+		}
+	}
+	return database.TemplateGitSource{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetTemplateGitSources(_ context.Context) ([]database.TemplateGitSource, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	sources := make([]database.TemplateGitSource, 0)
+	for _, source := range q.templateGitSources {
+		for _, template := range q.templates {
+			if template.ID == source.TemplateID && !template.Deleted {
+				sources = append(sources, source)
+				break
+			}
+		}
+	}
+	if len(sources) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return sources, nil
+}
+
+func (q *fakeQuerier) UpsertTemplateGitSource(_ context.Context, arg database.UpsertTemplateGitSourceParams) (database.TemplateGitSource, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, source := range q.templateGitSources {
+		if source.TemplateID != arg.TemplateID {
+			continue
+		}
+		if source.RepositoryUrl != arg.RepositoryUrl || source.Ref != arg.Ref || source.Subdirectory != arg.Subdirectory {
+			source.CommitSha = ""
+		}
+		source.UpdatedAt = arg.UpdatedAt
+		source.CreatedBy = arg.CreatedBy
+		source.RepositoryUrl = arg.RepositoryUrl
+		source.Ref = arg.Ref
+		source.Subdirectory = arg.Subdirectory
+		source.AutoPromote = arg.AutoPromote
+		q.templateGitSources[index] = source
+		return source, nil
+	}
+
+	//nolint:gosimple
+	source := database.TemplateGitSource{
+		TemplateID:    arg.TemplateID,
+		CreatedAt:     arg.CreatedAt,
+		UpdatedAt:     arg.UpdatedAt,
+		CreatedBy:     arg.CreatedBy,
+		RepositoryUrl: arg.RepositoryUrl,
+		Ref:           arg.Ref,
+		Subdirectory:  arg.Subdirectory,
+		AutoPromote:   arg.AutoPromote,
+	}
+	q.templateGitSources = append(q.templateGitSources, source)
+	return source, nil
+}
+
+func (q *fakeQuerier) UpsertTemplateInsights(_ context.Context, arg database.UpsertTemplateInsightsParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	insight := database.TemplateInsight{
+		TemplateID:            arg.TemplateID,
+		Date:                  arg.Date,
+		UpdatedAt:             arg.UpdatedAt,
+		ActiveUsers:           arg.ActiveUsers,
+		BuildsTotal:           arg.BuildsTotal,
+		BuildsSucceeded:       arg.BuildsSucceeded,
+		BuildDurationMedianMs: arg.BuildDurationMedianMs,
+		AgentConnectMedianMs:  arg.AgentConnectMedianMs,
+		AppUsage:              arg.AppUsage,
+	}
+	for index, existing := range q.templateInsights {
+		if existing.TemplateID == arg.TemplateID && existing.Date.Equal(arg.Date) {
+			q.templateInsights[index] = insight
+			return nil
+		}
+	}
+	q.templateInsights = append(q.templateInsights, insight)
+	return nil
+}
+
+func (q *fakeQuerier) UpdateTemplateGitSourceSyncByTemplateID(_ context.Context, arg database.UpdateTemplateGitSourceSyncByTemplateIDParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, source := range q.templateGitSources {
+		if source.TemplateID != arg.TemplateID {
+			continue
+		}
+		source.CommitSha = arg.CommitSha
+		source.PendingVersionID = arg.PendingVersionID
+		source.PendingDryRunJobID = arg.PendingDryRunJobID
+		source.SyncedAt = arg.SyncedAt
+		source.SyncError = arg.SyncError
+		source.SyncStartedAt = sql.NullTime{}
+		q.templateGitSources[index] = source
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteTemplateGitSourceByTemplateID(_ context.Context, templateID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, source := range q.templateGitSources {
+		if source.TemplateID == templateID {
+			q.templateGitSources = append(q.templateGitSources[:index], q.templateGitSources[index+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
@@ -9,7 +9,9 @@ CREATE TYPE audit_action AS ENUM (
 CREATE TYPE build_reason AS ENUM (
     'initiator',
     'autostart',
-    'autostop'
+    'autostop',
+    'autodelete',
+    'maintenance'
 );
 
 CREATE TYPE log_level AS ENUM (
@@ -76,6 +78,16 @@ CREATE TYPE resource_type AS ENUM (
     'workspace'
 );
 
+CREATE TYPE session_recording_type AS ENUM (
+    'ssh',
+    'reconnecting_pty'
+);
+
+CREATE TYPE terminal_share_mode AS ENUM (
+    'read_only',
+    'read_write'
+);
+
 CREATE TYPE user_status AS ENUM (
     'active',
     'suspended'
@@ -115,12 +127,16 @@ CREATE TABLE audit_logs (
     status_code integer NOT NULL
 );
 
+CREATE TABLE file_blobs (
+    key text NOT NULL,
+    data bytea NOT NULL
+);
+
 CREATE TABLE files (
     hash character varying(64) NOT NULL,
     created_at timestamp with time zone NOT NULL,
     created_by uuid NOT NULL,
-    mimetype character varying(64) NOT NULL,
-    data bytea NOT NULL
+    mimetype character varying(64) NOT NULL
 );
 
 CREATE TABLE gitsshkeys (
@@ -216,6 +232,16 @@ CREATE TABLE provisioner_job_logs (
     output character varying(1024) NOT NULL
 );
 
+CREATE TABLE provisioner_job_timings (
+    id uuid NOT NULL,
+    job_id uuid NOT NULL,
+    stage text NOT NULL,
+    resource text DEFAULT ''::text NOT NULL,
+    action text DEFAULT ''::text NOT NULL,
+    started_at timestamp with time zone NOT NULL,
+    ended_at timestamp with time zone NOT NULL
+);
+
 CREATE TABLE provisioner_jobs (
     id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -239,6 +265,57 @@ CREATE TABLE site_configs (
     value character varying(8192) NOT NULL
 );
 
+CREATE TABLE template_git_sources (
+    template_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    created_by uuid NOT NULL,
+    repository_url text NOT NULL,
+    ref text NOT NULL,
+    subdirectory text DEFAULT ''::text NOT NULL,
+    auto_promote boolean DEFAULT false NOT NULL,
+    commit_sha text DEFAULT ''::text NOT NULL,
+    pending_version_id uuid,
+    pending_dry_run_job_id uuid,
+    synced_at timestamp with time zone,
+    sync_error text DEFAULT ''::text NOT NULL,
+    sync_started_at timestamp with time zone
+);
+
+CREATE TABLE template_insights (
+    template_id uuid NOT NULL,
+    date date NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    active_users integer DEFAULT 0 NOT NULL,
+    builds_total integer DEFAULT 0 NOT NULL,
+    builds_succeeded integer DEFAULT 0 NOT NULL,
+    build_duration_median_ms bigint,
+    agent_connect_median_ms bigint,
+    app_usage jsonb DEFAULT '{}'::jsonb NOT NULL
+);
+
+CREATE TABLE template_usage_stats (
+    template_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    date date NOT NULL,
+    app_name text DEFAULT ''::text NOT NULL
+);
+
+CREATE TABLE template_version_presets (
+    template_version_id uuid NOT NULL,
+    name text NOT NULL,
+    description text DEFAULT ''::text NOT NULL,
+    parameters jsonb DEFAULT '{}'::jsonb NOT NULL,
+    index integer NOT NULL
+);
+
+CREATE TABLE template_version_variables (
+    template_version_id uuid NOT NULL,
+    name text NOT NULL,
+    value text NOT NULL,
+    sensitive boolean DEFAULT false NOT NULL
+);
+
 CREATE TABLE template_versions (
     id uuid NOT NULL,
     template_id uuid,
@@ -248,7 +325,10 @@ CREATE TABLE template_versions (
     name character varying(64) NOT NULL,
     readme character varying(1048576) NOT NULL,
     job_id uuid NOT NULL,
-    created_by uuid
+    created_by uuid,
+    message text DEFAULT ''::text NOT NULL,
+    git_metadata jsonb,
+    diagnostics jsonb DEFAULT '[]'::jsonb NOT NULL
 );
 
 CREATE TABLE templates (
@@ -264,7 +344,27 @@ CREATE TABLE templates (
     max_ttl bigint DEFAULT '604800000000000'::bigint NOT NULL,
     min_autostart_interval bigint DEFAULT '3600000000000'::bigint NOT NULL,
     created_by uuid NOT NULL,
-    icon character varying(256) DEFAULT ''::character varying NOT NULL
+    icon character varying(256) DEFAULT ''::character varying NOT NULL,
+    inactivity_ttl bigint DEFAULT 0 NOT NULL,
+    dormant_autodelete_ttl bigint DEFAULT 0 NOT NULL,
+    maintenance_window text DEFAULT ''::text NOT NULL,
+    maintenance_window_duration bigint DEFAULT 0 NOT NULL,
+    maintenance_restart_outdated boolean DEFAULT false NOT NULL,
+    prebuild_count integer DEFAULT 0 NOT NULL,
+    prebuild_parameters jsonb DEFAULT '[]'::jsonb NOT NULL,
+    record_sessions boolean DEFAULT false NOT NULL
+);
+
+CREATE TABLE terminal_shares (
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    workspace_id uuid NOT NULL,
+    agent_id uuid NOT NULL,
+    reconnect_id uuid NOT NULL,
+    created_by uuid NOT NULL,
+    mode terminal_share_mode NOT NULL,
+    recipients uuid[] DEFAULT '{}'::uuid[] NOT NULL,
+    expires_at timestamp with time zone NOT NULL
 );
 
 CREATE TABLE user_links (
@@ -273,7 +373,8 @@ CREATE TABLE user_links (
     linked_id text DEFAULT ''::text NOT NULL,
     oauth_access_token text DEFAULT ''::text NOT NULL,
     oauth_refresh_token text DEFAULT ''::text NOT NULL,
-    oauth_expiry timestamp with time zone DEFAULT '0001-01-01 00:00:00+00'::timestamp with time zone NOT NULL
+    oauth_expiry timestamp with time zone DEFAULT '0001-01-01 00:00:00+00'::timestamp with time zone NOT NULL,
+    oidc_provider text DEFAULT ''::text NOT NULL
 );
 
 CREATE TABLE users (
@@ -308,7 +409,9 @@ CREATE TABLE workspace_agents (
     directory character varying(4096) DEFAULT ''::character varying NOT NULL,
     wireguard_node_ipv6 inet DEFAULT '::'::inet NOT NULL,
     wireguard_node_public_key character varying(128) DEFAULT 'nodekey:0000000000000000000000000000000000000000000000000000000000000000'::character varying NOT NULL,
-    wireguard_disco_public_key character varying(128) DEFAULT 'discokey:0000000000000000000000000000000000000000000000000000000000000000'::character varying NOT NULL
+    wireguard_disco_public_key character varying(128) DEFAULT 'discokey:0000000000000000000000000000000000000000000000000000000000000000'::character varying NOT NULL,
+    version text DEFAULT ''::text NOT NULL,
+    health_checks jsonb
 );
 
 CREATE TABLE workspace_apps (
@@ -354,6 +457,25 @@ CREATE TABLE workspace_resources (
     name character varying(64) NOT NULL
 );
 
+CREATE TABLE workspace_session_recordings (
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    workspace_id uuid NOT NULL,
+    agent_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    type session_recording_type NOT NULL,
+    started_at timestamp with time zone NOT NULL,
+    ended_at timestamp with time zone NOT NULL,
+    data bytea NOT NULL
+);
+
+CREATE TABLE workspace_session_recording_tokens (
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    agent_id uuid NOT NULL,
+    user_id uuid NOT NULL
+);
+
 CREATE TABLE workspaces (
     id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -364,7 +486,11 @@ CREATE TABLE workspaces (
     deleted boolean DEFAULT false NOT NULL,
     name character varying(64) NOT NULL,
     autostart_schedule text,
-    ttl bigint
+    ttl bigint,
+    last_used_at timestamp with time zone DEFAULT '0001-01-01 00:00:00+00'::timestamp with time zone NOT NULL,
+    dormant_at timestamp with time zone,
+    prebuild boolean DEFAULT false NOT NULL,
+    preset text DEFAULT ''::text NOT NULL
 );
 
 ALTER TABLE ONLY licenses ALTER COLUMN id SET DEFAULT nextval('public.licenses_id_seq'::regclass);
@@ -375,6 +501,9 @@ ALTER TABLE ONLY api_keys
 ALTER TABLE ONLY audit_logs
     ADD CONSTRAINT audit_logs_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY file_blobs
+    ADD CONSTRAINT file_blobs_pkey PRIMARY KEY (key);
+
 ALTER TABLE ONLY files
     ADD CONSTRAINT files_pkey PRIMARY KEY (hash);
 
@@ -414,12 +543,30 @@ ALTER TABLE ONLY provisioner_daemons
 ALTER TABLE ONLY provisioner_job_logs
     ADD CONSTRAINT provisioner_job_logs_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY provisioner_job_timings
+    ADD CONSTRAINT provisioner_job_timings_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY provisioner_jobs
     ADD CONSTRAINT provisioner_jobs_pkey PRIMARY KEY (id);
 
 ALTER TABLE ONLY site_configs
     ADD CONSTRAINT site_configs_key_key UNIQUE (key);
 
+ALTER TABLE ONLY template_git_sources
+    ADD CONSTRAINT template_git_sources_pkey PRIMARY KEY (template_id);
+
+ALTER TABLE ONLY template_insights
+    ADD CONSTRAINT template_insights_pkey PRIMARY KEY (template_id, date);
+
+ALTER TABLE ONLY template_usage_stats
+    ADD CONSTRAINT template_usage_stats_pkey PRIMARY KEY (template_id, user_id, date, app_name);
+
+ALTER TABLE ONLY template_version_presets
+    ADD CONSTRAINT template_version_presets_pkey PRIMARY KEY (template_version_id, name);
+
+ALTER TABLE ONLY template_version_variables
+    ADD CONSTRAINT template_version_variables_pkey PRIMARY KEY (template_version_id, name);
+
 ALTER TABLE ONLY template_versions
     ADD CONSTRAINT template_versions_pkey PRIMARY KEY (id);
 
@@ -429,6 +576,9 @@ ALTER TABLE ONLY template_versions
 ALTER TABLE ONLY templates
     ADD CONSTRAINT templates_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY terminal_shares
+    ADD CONSTRAINT terminal_shares_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY user_links
     ADD CONSTRAINT user_links_pkey PRIMARY KEY (user_id, login_type);
 
@@ -462,6 +612,12 @@ ALTER TABLE ONLY workspace_resource_metadata
 ALTER TABLE ONLY workspace_resources
     ADD CONSTRAINT workspace_resources_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY workspace_session_recordings
+    ADD CONSTRAINT workspace_session_recordings_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY workspace_session_recording_tokens
+    ADD CONSTRAINT workspace_session_recording_tokens_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY workspaces
     ADD CONSTRAINT workspaces_pkey PRIMARY KEY (id);
 
@@ -483,10 +639,16 @@ CREATE UNIQUE INDEX idx_organization_name ON organizations USING btree (name);
 
 CREATE UNIQUE INDEX idx_organization_name_lower ON organizations USING btree (lower(name));
 
+CREATE INDEX idx_provisioner_job_timings_job_id ON provisioner_job_timings USING btree (job_id);
+
+CREATE INDEX idx_terminal_shares_agent_id ON terminal_shares USING btree (agent_id);
+
 CREATE UNIQUE INDEX idx_users_email ON users USING btree (email);
 
 CREATE UNIQUE INDEX idx_users_username ON users USING btree (username);
 
+CREATE INDEX idx_workspace_session_recordings_workspace_id ON workspace_session_recordings USING btree (workspace_id);
+
 CREATE UNIQUE INDEX templates_organization_id_name_idx ON templates USING btree (organization_id, lower((name)::text)) WHERE (deleted = false);
 
 CREATE UNIQUE INDEX users_username_lower_idx ON users USING btree (lower(username));
@@ -511,9 +673,36 @@ ALTER TABLE ONLY parameter_schemas
 ALTER TABLE ONLY provisioner_job_logs
     ADD CONSTRAINT provisioner_job_logs_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY provisioner_job_timings
+    ADD CONSTRAINT provisioner_job_timings_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY provisioner_jobs
     ADD CONSTRAINT provisioner_jobs_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY template_git_sources
+    ADD CONSTRAINT template_git_sources_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_git_sources
+    ADD CONSTRAINT template_git_sources_pending_version_id_fkey FOREIGN KEY (pending_version_id) REFERENCES template_versions(id) ON DELETE SET NULL;
+
+ALTER TABLE ONLY template_git_sources
+    ADD CONSTRAINT template_git_sources_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_insights
+    ADD CONSTRAINT template_insights_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_usage_stats
+    ADD CONSTRAINT template_usage_stats_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_usage_stats
+    ADD CONSTRAINT template_usage_stats_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_version_presets
+    ADD CONSTRAINT template_version_presets_template_version_id_fkey FOREIGN KEY (template_version_id) REFERENCES template_versions(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_version_variables
+    ADD CONSTRAINT template_version_variables_template_version_id_fkey FOREIGN KEY (template_version_id) REFERENCES template_versions(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY template_versions
     ADD CONSTRAINT template_versions_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE RESTRICT;
 
@@ -529,6 +718,15 @@ ALTER TABLE ONLY templates
 ALTER TABLE ONLY templates
     ADD CONSTRAINT templates_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY terminal_shares
+    ADD CONSTRAINT terminal_shares_agent_id_fkey FOREIGN KEY (agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY terminal_shares
+    ADD CONSTRAINT terminal_shares_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY terminal_shares
+    ADD CONSTRAINT terminal_shares_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY user_links
     ADD CONSTRAINT user_links_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
 
@@ -553,6 +751,21 @@ ALTER TABLE ONLY workspace_resource_metadata
 ALTER TABLE ONLY workspace_resources
     ADD CONSTRAINT workspace_resources_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_session_recordings
+    ADD CONSTRAINT workspace_session_recordings_agent_id_fkey FOREIGN KEY (agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_session_recordings
+    ADD CONSTRAINT workspace_session_recordings_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_session_recordings
+    ADD CONSTRAINT workspace_session_recordings_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_session_recording_tokens
+    ADD CONSTRAINT workspace_session_recording_tokens_agent_id_fkey FOREIGN KEY (agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_session_recording_tokens
+    ADD CONSTRAINT workspace_session_recording_tokens_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspaces
     ADD CONSTRAINT workspaces_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE RESTRICT;
 
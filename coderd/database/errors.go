@@ -36,3 +36,13 @@ func IsUniqueViolation(err error, uniqueConstraints ...UniqueConstraint) bool {
 
 	return false
 }
+
+// IsForeignKeyViolation checks if the error is due to a foreign key violation.
+func IsForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code.Name() == "foreign_key_violation"
+	}
+
+	return false
+}
@@ -0,0 +1,9 @@
+ALTER TABLE ONLY workspaces DROP COLUMN IF EXISTS dormant_at;
+ALTER TABLE ONLY workspaces DROP COLUMN IF EXISTS last_used_at;
+
+ALTER TABLE ONLY templates DROP COLUMN IF EXISTS dormant_autodelete_ttl;
+ALTER TABLE ONLY templates DROP COLUMN IF EXISTS inactivity_ttl;
+
+-- It's not possible to drop enum values from enum types, so the UP has "IF NOT
+-- EXISTS". Builds using the value are kept with the closest reason.
+UPDATE workspace_builds SET reason = 'autostop' WHERE reason = 'autodelete';
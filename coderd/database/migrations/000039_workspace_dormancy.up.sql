@@ -0,0 +1,8 @@
+ALTER TYPE build_reason ADD VALUE IF NOT EXISTS 'autodelete';
+
+-- A value of zero disables the policy.
+ALTER TABLE ONLY templates ADD COLUMN IF NOT EXISTS inactivity_ttl BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE ONLY templates ADD COLUMN IF NOT EXISTS dormant_autodelete_ttl BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE ONLY workspaces ADD COLUMN IF NOT EXISTS last_used_at timestamp with time zone NOT NULL DEFAULT '0001-01-01 00:00:00+00:00';
+ALTER TABLE ONLY workspaces ADD COLUMN IF NOT EXISTS dormant_at timestamp with time zone;
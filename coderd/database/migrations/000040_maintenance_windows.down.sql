@@ -0,0 +1,7 @@
+ALTER TABLE ONLY templates DROP COLUMN IF EXISTS maintenance_restart_outdated;
+ALTER TABLE ONLY templates DROP COLUMN IF EXISTS maintenance_window_duration;
+ALTER TABLE ONLY templates DROP COLUMN IF EXISTS maintenance_window;
+
+-- It's not possible to drop enum values from enum types, so the UP has "IF NOT
+-- EXISTS". Builds using the value are kept with the closest reason.
+UPDATE workspace_builds SET reason = 'autostart' WHERE reason = 'maintenance';
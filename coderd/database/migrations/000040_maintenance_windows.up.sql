@@ -0,0 +1,7 @@
+ALTER TYPE build_reason ADD VALUE IF NOT EXISTS 'maintenance';
+
+-- Weekly cron schedule for when maintenance windows start. An empty
+-- schedule means the template has no maintenance window.
+ALTER TABLE ONLY templates ADD COLUMN IF NOT EXISTS maintenance_window text NOT NULL DEFAULT '';
+ALTER TABLE ONLY templates ADD COLUMN IF NOT EXISTS maintenance_window_duration BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE ONLY templates ADD COLUMN IF NOT EXISTS maintenance_restart_outdated boolean NOT NULL DEFAULT false;
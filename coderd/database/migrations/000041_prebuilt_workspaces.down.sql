@@ -0,0 +1,3 @@
+ALTER TABLE ONLY workspaces DROP COLUMN IF EXISTS prebuild;
+ALTER TABLE ONLY templates DROP COLUMN IF EXISTS prebuild_parameters;
+ALTER TABLE ONLY templates DROP COLUMN IF EXISTS prebuild_count;
@@ -0,0 +1,8 @@
+-- Number of prebuilt workspaces to keep ready for the template, and the
+-- parameter values they are built with.
+ALTER TABLE ONLY templates ADD COLUMN IF NOT EXISTS prebuild_count integer NOT NULL DEFAULT 0;
+ALTER TABLE ONLY templates ADD COLUMN IF NOT EXISTS prebuild_parameters jsonb NOT NULL DEFAULT '[]'::jsonb;
+
+-- Prebuilt workspaces are owned by the template creator until they are
+-- claimed by a user.
+ALTER TABLE ONLY workspaces ADD COLUMN IF NOT EXISTS prebuild boolean NOT NULL DEFAULT false;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS workspace_session_recordings;
+DROP TYPE IF EXISTS session_recording_type;
+ALTER TABLE ONLY templates DROP COLUMN IF EXISTS record_sessions;
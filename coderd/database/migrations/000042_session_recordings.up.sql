@@ -0,0 +1,20 @@
+-- Templates can opt in to recording interactive terminal sessions.
+ALTER TABLE ONLY templates ADD COLUMN IF NOT EXISTS record_sessions boolean NOT NULL DEFAULT false;
+
+CREATE TYPE session_recording_type AS ENUM ('ssh', 'reconnecting_pty');
+
+-- Recordings are stored in the asciicast v2 format.
+CREATE TABLE IF NOT EXISTS workspace_session_recordings (
+	id uuid NOT NULL,
+	created_at timestamptz NOT NULL,
+	workspace_id uuid NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+	agent_id uuid NOT NULL REFERENCES workspace_agents (id) ON DELETE CASCADE,
+	user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	type session_recording_type NOT NULL,
+	started_at timestamptz NOT NULL,
+	ended_at timestamptz NOT NULL,
+	data bytea NOT NULL,
+	PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_session_recordings_workspace_id ON workspace_session_recordings USING btree (workspace_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS terminal_shares;
+DROP TYPE IF EXISTS terminal_share_mode;
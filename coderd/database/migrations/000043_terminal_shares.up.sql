@@ -0,0 +1,16 @@
+CREATE TYPE terminal_share_mode AS ENUM ('read_only', 'read_write');
+
+-- Terminal shares let other users attach to a reconnecting PTY of a
+-- workspace agent. Revoking a share deletes it.
+CREATE TABLE IF NOT EXISTS terminal_shares (
+	id uuid NOT NULL,
+	created_at timestamptz NOT NULL,
+	workspace_id uuid NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+	agent_id uuid NOT NULL REFERENCES workspace_agents (id) ON DELETE CASCADE,
+	reconnect_id uuid NOT NULL,
+	created_by uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	mode terminal_share_mode NOT NULL,
+	PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_terminal_shares_agent_id ON terminal_shares USING btree (agent_id);
@@ -0,0 +1 @@
+ALTER TABLE workspace_agents ADD COLUMN version text DEFAULT '' NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE workspace_agents DROP COLUMN health_checks;
@@ -0,0 +1 @@
+ALTER TABLE workspace_agents ADD COLUMN health_checks jsonb;
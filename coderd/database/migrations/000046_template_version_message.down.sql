@@ -0,0 +1,2 @@
+ALTER TABLE template_versions DROP COLUMN git_metadata;
+ALTER TABLE template_versions DROP COLUMN message;
@@ -0,0 +1,2 @@
+ALTER TABLE template_versions ADD COLUMN message text DEFAULT '' NOT NULL;
+ALTER TABLE template_versions ADD COLUMN git_metadata jsonb;
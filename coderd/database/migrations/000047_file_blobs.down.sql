@@ -0,0 +1,7 @@
+ALTER TABLE files ADD COLUMN data bytea NOT NULL DEFAULT ''::bytea;
+
+UPDATE files SET data = file_blobs.data FROM file_blobs WHERE file_blobs.key = files.hash;
+
+ALTER TABLE files ALTER COLUMN data DROP DEFAULT;
+
+DROP TABLE file_blobs;
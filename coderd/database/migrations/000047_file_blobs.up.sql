@@ -0,0 +1,11 @@
+-- The contents of files are stored by a pluggable blob store. This table
+-- backs the default Postgres blob store.
+CREATE TABLE file_blobs (
+    key text NOT NULL,
+    data bytea NOT NULL,
+    PRIMARY KEY (key)
+);
+
+INSERT INTO file_blobs (key, data) SELECT hash, data FROM files;
+
+ALTER TABLE files DROP COLUMN data;
@@ -0,0 +1,23 @@
+-- Templates can be sourced from a git repository. Coderd fetches the
+-- repository periodically and creates a new template version whenever the
+-- ref points at a different commit.
+CREATE TABLE IF NOT EXISTS template_git_sources (
+	template_id uuid NOT NULL REFERENCES templates (id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL,
+	updated_at timestamptz NOT NULL,
+	-- Template versions created by syncing are initiated by this user.
+	created_by uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	repository_url text NOT NULL,
+	ref text NOT NULL,
+	subdirectory text DEFAULT '' NOT NULL,
+	auto_promote boolean DEFAULT false NOT NULL,
+	-- The commit the latest template version was created from.
+	commit_sha text DEFAULT '' NOT NULL,
+	-- The template version waiting to be promoted, and the dry-run job it
+	-- must pass first.
+	pending_version_id uuid REFERENCES template_versions (id) ON DELETE SET NULL,
+	pending_dry_run_job_id uuid,
+	synced_at timestamptz,
+	sync_error text DEFAULT '' NOT NULL,
+	PRIMARY KEY (template_id)
+);
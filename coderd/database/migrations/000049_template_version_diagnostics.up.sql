@@ -0,0 +1 @@
+ALTER TABLE template_versions ADD COLUMN diagnostics jsonb DEFAULT '[]'::jsonb NOT NULL;
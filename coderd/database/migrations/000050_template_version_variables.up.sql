@@ -0,0 +1,10 @@
+-- Template variables are set by template admins when they push a version,
+-- unlike parameters which workspace owners are prompted for.
+CREATE TABLE IF NOT EXISTS template_version_variables (
+	template_version_id uuid NOT NULL REFERENCES template_versions (id) ON DELETE CASCADE,
+	name text NOT NULL,
+	value text NOT NULL,
+	-- Sensitive values are never returned by the API.
+	sensitive boolean DEFAULT false NOT NULL,
+	PRIMARY KEY (template_version_id, name)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE workspaces DROP COLUMN IF EXISTS preset;
+
+DROP TABLE IF EXISTS template_version_presets;
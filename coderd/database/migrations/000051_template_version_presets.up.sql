@@ -0,0 +1,14 @@
+-- Presets are named sets of parameter values defined by template authors.
+CREATE TABLE IF NOT EXISTS template_version_presets (
+	template_version_id uuid NOT NULL REFERENCES template_versions (id) ON DELETE CASCADE,
+	name text NOT NULL,
+	description text DEFAULT '' NOT NULL,
+	parameters jsonb DEFAULT '{}'::jsonb NOT NULL,
+	-- Presets are listed in the order they're defined in.
+	index integer NOT NULL,
+	PRIMARY KEY (template_version_id, name)
+);
+
+-- The preset a workspace was created with, which is applied again when the
+-- workspace is updated to a new template version.
+ALTER TABLE workspaces ADD COLUMN IF NOT EXISTS preset text DEFAULT '' NOT NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS template_insights;
+
+DROP TABLE IF EXISTS template_usage_stats;
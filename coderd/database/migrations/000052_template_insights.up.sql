@@ -0,0 +1,25 @@
+-- Users that used a workspace of a template on a given day. An empty app
+-- name is recorded for connections to the workspace agent.
+CREATE TABLE IF NOT EXISTS template_usage_stats (
+	template_id uuid NOT NULL REFERENCES templates (id) ON DELETE CASCADE,
+	user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	date date NOT NULL,
+	app_name text DEFAULT '' NOT NULL,
+	PRIMARY KEY (template_id, user_id, date, app_name)
+);
+
+-- Daily rollups of how a template is used, computed periodically from
+-- builds, agents and usage stats.
+CREATE TABLE IF NOT EXISTS template_insights (
+	template_id uuid NOT NULL REFERENCES templates (id) ON DELETE CASCADE,
+	date date NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	active_users integer DEFAULT 0 NOT NULL,
+	builds_total integer DEFAULT 0 NOT NULL,
+	builds_succeeded integer DEFAULT 0 NOT NULL,
+	build_duration_median_ms bigint,
+	agent_connect_median_ms bigint,
+	-- Maps app names to the number of users that used them.
+	app_usage jsonb DEFAULT '{}'::jsonb NOT NULL,
+	PRIMARY KEY (template_id, date)
+);
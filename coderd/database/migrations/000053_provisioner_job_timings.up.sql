@@ -0,0 +1,13 @@
+-- How long each stage of a provisioner job took. Resources are set for the
+-- stages that report per-resource timings, such as applying.
+CREATE TABLE IF NOT EXISTS provisioner_job_timings (
+	id uuid NOT NULL PRIMARY KEY,
+	job_id uuid NOT NULL REFERENCES provisioner_jobs (id) ON DELETE CASCADE,
+	stage text NOT NULL,
+	resource text DEFAULT '' NOT NULL,
+	action text DEFAULT '' NOT NULL,
+	started_at timestamp with time zone NOT NULL,
+	ended_at timestamp with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_provisioner_job_timings_job_id ON provisioner_job_timings USING btree (job_id);
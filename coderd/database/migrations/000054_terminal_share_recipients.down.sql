@@ -0,0 +1,2 @@
+ALTER TABLE terminal_shares DROP COLUMN expires_at;
+ALTER TABLE terminal_shares DROP COLUMN recipients;
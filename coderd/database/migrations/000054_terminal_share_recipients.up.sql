@@ -0,0 +1,5 @@
+-- Shares are restricted to the users they're shared with, and expire.
+ALTER TABLE terminal_shares ADD COLUMN recipients uuid[] DEFAULT '{}'::uuid[] NOT NULL;
+ALTER TABLE terminal_shares ADD COLUMN expires_at timestamptz;
+UPDATE terminal_shares SET expires_at = created_at + interval '1 hour';
+ALTER TABLE terminal_shares ALTER COLUMN expires_at SET NOT NULL;
@@ -0,0 +1,3 @@
+-- The name of the OIDC provider a link was created by. It's empty for the
+-- default OIDC provider and other login types.
+ALTER TABLE user_links ADD COLUMN oidc_provider text DEFAULT ''::text NOT NULL;
@@ -0,0 +1 @@
+DROP TABLE workspace_session_recording_tokens;
@@ -0,0 +1,11 @@
+-- Tokens are issued to users connecting to a workspace agent and handed to
+-- the agent, which sends them back with the session recording. This
+-- attributes recordings to the user that connected without trusting the
+-- agent to name them.
+CREATE TABLE IF NOT EXISTS workspace_session_recording_tokens (
+	id uuid NOT NULL,
+	created_at timestamptz NOT NULL,
+	agent_id uuid NOT NULL REFERENCES workspace_agents (id) ON DELETE CASCADE,
+	user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	PRIMARY KEY (id)
+);
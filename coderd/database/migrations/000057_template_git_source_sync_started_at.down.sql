@@ -0,0 +1 @@
+ALTER TABLE template_git_sources DROP COLUMN IF EXISTS sync_started_at;
@@ -0,0 +1,3 @@
+-- Only one sync of a template runs at a time. It's claimed by setting
+-- sync_started_at, which is cleared once the sync finishes.
+ALTER TABLE template_git_sources ADD COLUMN IF NOT EXISTS sync_started_at timestamptz;
@@ -38,9 +38,11 @@ func (e *AuditAction) Scan(src interface{}) error {
 type BuildReason string
 
 const (
-	BuildReasonInitiator BuildReason = "initiator"
-	BuildReasonAutostart BuildReason = "autostart"
-	BuildReasonAutostop  BuildReason = "autostop"
+	BuildReasonInitiator   BuildReason = "initiator"
+	BuildReasonAutostart   BuildReason = "autostart"
+	BuildReasonAutostop    BuildReason = "autostop"
+	BuildReasonAutodelete  BuildReason = "autodelete"
+	BuildReasonMaintenance BuildReason = "maintenance"
 )
 
 func (e *BuildReason) Scan(src interface{}) error {
@@ -273,6 +275,44 @@ func (e *ResourceType) Scan(src interface{}) error {
 	return nil
 }
 
+type SessionRecordingType string
+
+const (
+	SessionRecordingTypeSsh             SessionRecordingType = "ssh"
+	SessionRecordingTypeReconnectingPty SessionRecordingType = "reconnecting_pty"
+)
+
+func (e *SessionRecordingType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = SessionRecordingType(s)
+	case string:
+		*e = SessionRecordingType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for SessionRecordingType: %T", src)
+	}
+	return nil
+}
+
+type TerminalShareMode string
+
+const (
+	TerminalShareModeReadOnly  TerminalShareMode = "read_only"
+	TerminalShareModeReadWrite TerminalShareMode = "read_write"
+)
+
+func (e *TerminalShareMode) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TerminalShareMode(s)
+	case string:
+		*e = TerminalShareMode(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TerminalShareMode: %T", src)
+	}
+	return nil
+}
+
 type UserStatus string
 
 const (
@@ -345,7 +385,11 @@ type File struct {
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	CreatedBy uuid.UUID `db:"created_by" json:"created_by"`
 	Mimetype  string    `db:"mimetype" json:"mimetype"`
-	Data      []byte    `db:"data" json:"data"`
+}
+
+type FileBlob struct {
+	Key  string `db:"key" json:"key"`
+	Data []byte `db:"data" json:"data"`
 }
 
 type GitSSHKey struct {
@@ -448,37 +492,121 @@ type ProvisionerJobLog struct {
 	Output    string    `db:"output" json:"output"`
 }
 
+type ProvisionerJobTiming struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	JobID     uuid.UUID `db:"job_id" json:"job_id"`
+	Stage     string    `db:"stage" json:"stage"`
+	Resource  string    `db:"resource" json:"resource"`
+	Action    string    `db:"action" json:"action"`
+	StartedAt time.Time `db:"started_at" json:"started_at"`
+	EndedAt   time.Time `db:"ended_at" json:"ended_at"`
+}
+
 type SiteConfig struct {
 	Key   string `db:"key" json:"key"`
 	Value string `db:"value" json:"value"`
 }
 
 type Template struct {
-	ID                   uuid.UUID       `db:"id" json:"id"`
-	CreatedAt            time.Time       `db:"created_at" json:"created_at"`
-	UpdatedAt            time.Time       `db:"updated_at" json:"updated_at"`
-	OrganizationID       uuid.UUID       `db:"organization_id" json:"organization_id"`
-	Deleted              bool            `db:"deleted" json:"deleted"`
-	Name                 string          `db:"name" json:"name"`
-	Provisioner          ProvisionerType `db:"provisioner" json:"provisioner"`
-	ActiveVersionID      uuid.UUID       `db:"active_version_id" json:"active_version_id"`
-	Description          string          `db:"description" json:"description"`
-	MaxTtl               int64           `db:"max_ttl" json:"max_ttl"`
-	MinAutostartInterval int64           `db:"min_autostart_interval" json:"min_autostart_interval"`
-	CreatedBy            uuid.UUID       `db:"created_by" json:"created_by"`
-	Icon                 string          `db:"icon" json:"icon"`
+	ID                         uuid.UUID       `db:"id" json:"id"`
+	CreatedAt                  time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt                  time.Time       `db:"updated_at" json:"updated_at"`
+	OrganizationID             uuid.UUID       `db:"organization_id" json:"organization_id"`
+	Deleted                    bool            `db:"deleted" json:"deleted"`
+	Name                       string          `db:"name" json:"name"`
+	Provisioner                ProvisionerType `db:"provisioner" json:"provisioner"`
+	ActiveVersionID            uuid.UUID       `db:"active_version_id" json:"active_version_id"`
+	Description                string          `db:"description" json:"description"`
+	MaxTtl                     int64           `db:"max_ttl" json:"max_ttl"`
+	MinAutostartInterval       int64           `db:"min_autostart_interval" json:"min_autostart_interval"`
+	CreatedBy                  uuid.UUID       `db:"created_by" json:"created_by"`
+	Icon                       string          `db:"icon" json:"icon"`
+	InactivityTtl              int64           `db:"inactivity_ttl" json:"inactivity_ttl"`
+	DormantAutodeleteTtl       int64           `db:"dormant_autodelete_ttl" json:"dormant_autodelete_ttl"`
+	MaintenanceWindow          string          `db:"maintenance_window" json:"maintenance_window"`
+	MaintenanceWindowDuration  int64           `db:"maintenance_window_duration" json:"maintenance_window_duration"`
+	MaintenanceRestartOutdated bool            `db:"maintenance_restart_outdated" json:"maintenance_restart_outdated"`
+	PrebuildCount              int32           `db:"prebuild_count" json:"prebuild_count"`
+	PrebuildParameters         json.RawMessage `db:"prebuild_parameters" json:"prebuild_parameters"`
+	RecordSessions             bool            `db:"record_sessions" json:"record_sessions"`
+}
+
+type TemplateGitSource struct {
+	TemplateID         uuid.UUID     `db:"template_id" json:"template_id"`
+	CreatedAt          time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time     `db:"updated_at" json:"updated_at"`
+	CreatedBy          uuid.UUID     `db:"created_by" json:"created_by"`
+	RepositoryUrl      string        `db:"repository_url" json:"repository_url"`
+	Ref                string        `db:"ref" json:"ref"`
+	Subdirectory       string        `db:"subdirectory" json:"subdirectory"`
+	AutoPromote        bool          `db:"auto_promote" json:"auto_promote"`
+	CommitSha          string        `db:"commit_sha" json:"commit_sha"`
+	PendingVersionID   uuid.NullUUID `db:"pending_version_id" json:"pending_version_id"`
+	PendingDryRunJobID uuid.NullUUID `db:"pending_dry_run_job_id" json:"pending_dry_run_job_id"`
+	SyncedAt           sql.NullTime  `db:"synced_at" json:"synced_at"`
+	SyncError          string        `db:"sync_error" json:"sync_error"`
+	SyncStartedAt      sql.NullTime  `db:"sync_started_at" json:"sync_started_at"`
+}
+
+type TemplateInsight struct {
+	TemplateID            uuid.UUID       `db:"template_id" json:"template_id"`
+	Date                  time.Time       `db:"date" json:"date"`
+	UpdatedAt             time.Time       `db:"updated_at" json:"updated_at"`
+	ActiveUsers           int32           `db:"active_users" json:"active_users"`
+	BuildsTotal           int32           `db:"builds_total" json:"builds_total"`
+	BuildsSucceeded       int32           `db:"builds_succeeded" json:"builds_succeeded"`
+	BuildDurationMedianMs sql.NullInt64   `db:"build_duration_median_ms" json:"build_duration_median_ms"`
+	AgentConnectMedianMs  sql.NullInt64   `db:"agent_connect_median_ms" json:"agent_connect_median_ms"`
+	AppUsage              json.RawMessage `db:"app_usage" json:"app_usage"`
+}
+
+type TemplateUsageStat struct {
+	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
+	UserID     uuid.UUID `db:"user_id" json:"user_id"`
+	Date       time.Time `db:"date" json:"date"`
+	AppName    string    `db:"app_name" json:"app_name"`
 }
 
 type TemplateVersion struct {
-	ID             uuid.UUID     `db:"id" json:"id"`
-	TemplateID     uuid.NullUUID `db:"template_id" json:"template_id"`
-	OrganizationID uuid.UUID     `db:"organization_id" json:"organization_id"`
-	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time     `db:"updated_at" json:"updated_at"`
-	Name           string        `db:"name" json:"name"`
-	Readme         string        `db:"readme" json:"readme"`
-	JobID          uuid.UUID     `db:"job_id" json:"job_id"`
-	CreatedBy      uuid.NullUUID `db:"created_by" json:"created_by"`
+	ID             uuid.UUID             `db:"id" json:"id"`
+	TemplateID     uuid.NullUUID         `db:"template_id" json:"template_id"`
+	OrganizationID uuid.UUID             `db:"organization_id" json:"organization_id"`
+	CreatedAt      time.Time             `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time             `db:"updated_at" json:"updated_at"`
+	Name           string                `db:"name" json:"name"`
+	Readme         string                `db:"readme" json:"readme"`
+	JobID          uuid.UUID             `db:"job_id" json:"job_id"`
+	CreatedBy      uuid.NullUUID         `db:"created_by" json:"created_by"`
+	Message        string                `db:"message" json:"message"`
+	GitMetadata    pqtype.NullRawMessage `db:"git_metadata" json:"git_metadata"`
+	Diagnostics    json.RawMessage       `db:"diagnostics" json:"diagnostics"`
+}
+
+type TemplateVersionPreset struct {
+	TemplateVersionID uuid.UUID       `db:"template_version_id" json:"template_version_id"`
+	Name              string          `db:"name" json:"name"`
+	Description       string          `db:"description" json:"description"`
+	Parameters        json.RawMessage `db:"parameters" json:"parameters"`
+	Index             int32           `db:"index" json:"index"`
+}
+
+type TemplateVersionVariable struct {
+	TemplateVersionID uuid.UUID `db:"template_version_id" json:"template_version_id"`
+	Name              string    `db:"name" json:"name"`
+	Value             string    `db:"value" json:"value"`
+	Sensitive         bool      `db:"sensitive" json:"sensitive"`
+}
+
+type TerminalShare struct {
+	ID          uuid.UUID         `db:"id" json:"id"`
+	CreatedAt   time.Time         `db:"created_at" json:"created_at"`
+	WorkspaceID uuid.UUID         `db:"workspace_id" json:"workspace_id"`
+	AgentID     uuid.UUID         `db:"agent_id" json:"agent_id"`
+	ReconnectID uuid.UUID         `db:"reconnect_id" json:"reconnect_id"`
+	CreatedBy   uuid.UUID         `db:"created_by" json:"created_by"`
+	Mode        TerminalShareMode `db:"mode" json:"mode"`
+	Recipients  []uuid.UUID       `db:"recipients" json:"recipients"`
+	ExpiresAt   time.Time         `db:"expires_at" json:"expires_at"`
 }
 
 type User struct {
@@ -500,6 +628,7 @@ type UserLink struct {
 	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
 	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
 	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
+	OIDCProvider      string    `db:"oidc_provider" json:"oidc_provider"`
 }
 
 type Workspace struct {
@@ -513,6 +642,10 @@ type Workspace struct {
 	Name              string         `db:"name" json:"name"`
 	AutostartSchedule sql.NullString `db:"autostart_schedule" json:"autostart_schedule"`
 	Ttl               sql.NullInt64  `db:"ttl" json:"ttl"`
+	LastUsedAt        time.Time      `db:"last_used_at" json:"last_used_at"`
+	DormantAt         sql.NullTime   `db:"dormant_at" json:"dormant_at"`
+	Prebuild          bool           `db:"prebuild" json:"prebuild"`
+	Preset            string         `db:"preset" json:"preset"`
 }
 
 type WorkspaceAgent struct {
@@ -536,6 +669,8 @@ type WorkspaceAgent struct {
 	WireguardNodeIPv6       pqtype.Inet           `db:"wireguard_node_ipv6" json:"wireguard_node_ipv6"`
 	WireguardNodePublicKey  dbtypes.NodePublic    `db:"wireguard_node_public_key" json:"wireguard_node_public_key"`
 	WireguardDiscoPublicKey dbtypes.DiscoPublic   `db:"wireguard_disco_public_key" json:"wireguard_disco_public_key"`
+	Version                 string                `db:"version" json:"version"`
+	HealthChecks            pqtype.NullRawMessage `db:"health_checks" json:"health_checks"`
 }
 
 type WorkspaceApp struct {
@@ -580,3 +715,22 @@ type WorkspaceResourceMetadatum struct {
 	Value               sql.NullString `db:"value" json:"value"`
 	Sensitive           bool           `db:"sensitive" json:"sensitive"`
 }
+
+type WorkspaceSessionRecording struct {
+	ID          uuid.UUID            `db:"id" json:"id"`
+	CreatedAt   time.Time            `db:"created_at" json:"created_at"`
+	WorkspaceID uuid.UUID            `db:"workspace_id" json:"workspace_id"`
+	AgentID     uuid.UUID            `db:"agent_id" json:"agent_id"`
+	UserID      uuid.UUID            `db:"user_id" json:"user_id"`
+	Type        SessionRecordingType `db:"type" json:"type"`
+	StartedAt   time.Time            `db:"started_at" json:"started_at"`
+	EndedAt     time.Time            `db:"ended_at" json:"ended_at"`
+	Data        []byte               `db:"data" json:"data"`
+}
+
+type WorkspaceSessionRecordingToken struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	AgentID   uuid.UUID `db:"agent_id" json:"agent_id"`
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+}
@@ -19,10 +19,29 @@ type querier interface {
 	// multiple provisioners from acquiring the same jobs. See:
 	// https://www.postgresql.org/docs/9.5/sql-select.html#SQL-FOR-UPDATE-SHARE
 	AcquireProvisionerJob(ctx context.Context, arg AcquireProvisionerJobParams) (ProvisionerJob, error)
+	// Claims the sync of a template, so only one runs at a time across replicas.
+	// A sync that started before @stale_before is assumed to have crashed.
+	AcquireTemplateGitSourceSync(ctx context.Context, arg AcquireTemplateGitSourceSyncParams) (TemplateGitSource, error)
+	// Transfers a prebuilt workspace to its new owner. No rows are returned if
+	// the workspace has already been claimed.
+	ClaimPrebuiltWorkspace(ctx context.Context, arg ClaimPrebuiltWorkspaceParams) (Workspace, error)
 	DeleteAPIKeyByID(ctx context.Context, id string) error
+	// Soft-deleted workspaces restrict the deletion of their organization and
+	// template, so they're removed for good before the organization is deleted.
+	DeleteDeletedWorkspacesByOrganizationID(ctx context.Context, organizationID uuid.UUID) error
+	DeleteFileBlobByKey(ctx context.Context, key string) error
 	DeleteGitSSHKey(ctx context.Context, userID uuid.UUID) error
 	DeleteLicense(ctx context.Context, id int32) (int32, error)
+	DeleteOrganizationByID(ctx context.Context, id uuid.UUID) error
+	DeleteOrganizationMember(ctx context.Context, arg DeleteOrganizationMemberParams) error
 	DeleteParameterValueByID(ctx context.Context, id uuid.UUID) error
+	DeleteTemplateGitSourceByTemplateID(ctx context.Context, templateID uuid.UUID) error
+	DeleteTemplateVersionVariable(ctx context.Context, arg DeleteTemplateVersionVariableParams) error
+	DeleteTerminalShareByID(ctx context.Context, id uuid.UUID) error
+	// Deletes a file if it was created before the given time and no provisioner
+	// job uses it as its source.
+	DeleteUnreferencedFileByHash(ctx context.Context, arg DeleteUnreferencedFileByHashParams) (File, error)
+	DeleteWorkspaceSessionRecordingTokenByID(ctx context.Context, id uuid.UUID) error
 	GetAPIKeyByID(ctx context.Context, id string) (APIKey, error)
 	GetAPIKeysLastUsedAfter(ctx context.Context, lastUsed time.Time) ([]APIKey, error)
 	// GetAuditLogsBefore retrieves `limit` number of audit logs before the provided
@@ -32,6 +51,7 @@ type querier interface {
 	// are included.
 	GetAuthorizationUserRoles(ctx context.Context, userID uuid.UUID) (GetAuthorizationUserRolesRow, error)
 	GetDeploymentID(ctx context.Context) (string, error)
+	GetFileBlobByKey(ctx context.Context, key string) (FileBlob, error)
 	GetFileByHash(ctx context.Context, hash string) (File, error)
 	GetGitSSHKey(ctx context.Context, userID uuid.UUID) (GitSSHKey, error)
 	GetLatestWorkspaceBuildByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (WorkspaceBuild, error)
@@ -42,27 +62,44 @@ type querier interface {
 	GetOrganizationByName(ctx context.Context, name string) (Organization, error)
 	GetOrganizationIDsByMemberIDs(ctx context.Context, ids []uuid.UUID) ([]GetOrganizationIDsByMemberIDsRow, error)
 	GetOrganizationMemberByUserID(ctx context.Context, arg GetOrganizationMemberByUserIDParams) (OrganizationMember, error)
+	GetOrganizationMembersByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]OrganizationMember, error)
 	GetOrganizationMembershipsByUserID(ctx context.Context, userID uuid.UUID) ([]OrganizationMember, error)
 	GetOrganizations(ctx context.Context) ([]Organization, error)
 	GetOrganizationsByUserID(ctx context.Context, userID uuid.UUID) ([]Organization, error)
 	GetParameterSchemasByJobID(ctx context.Context, jobID uuid.UUID) ([]ParameterSchema, error)
 	GetParameterSchemasCreatedAfter(ctx context.Context, createdAt time.Time) ([]ParameterSchema, error)
 	GetParameterValueByScopeAndName(ctx context.Context, arg GetParameterValueByScopeAndNameParams) (ParameterValue, error)
+	GetPrebuiltWorkspacesByTemplateID(ctx context.Context, templateID uuid.UUID) ([]Workspace, error)
 	GetProvisionerDaemonByID(ctx context.Context, id uuid.UUID) (ProvisionerDaemon, error)
 	GetProvisionerDaemons(ctx context.Context) ([]ProvisionerDaemon, error)
 	GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (ProvisionerJob, error)
+	GetProvisionerJobTimingsByJobID(ctx context.Context, jobID uuid.UUID) ([]ProvisionerJobTiming, error)
 	GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]ProvisionerJob, error)
 	GetProvisionerJobsCreatedAfter(ctx context.Context, createdAt time.Time) ([]ProvisionerJob, error)
 	GetProvisionerLogsByIDBetween(ctx context.Context, arg GetProvisionerLogsByIDBetweenParams) ([]ProvisionerJobLog, error)
 	GetTemplateByID(ctx context.Context, id uuid.UUID) (Template, error)
 	GetTemplateByOrganizationAndName(ctx context.Context, arg GetTemplateByOrganizationAndNameParams) (Template, error)
+	GetTemplateGitSourceByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateGitSource, error)
+	GetTemplateGitSources(ctx context.Context) ([]TemplateGitSource, error)
+	GetTemplateInsights(ctx context.Context, arg GetTemplateInsightsParams) ([]TemplateInsight, error)
+	GetTemplateUsageStatsAfter(ctx context.Context, date time.Time) ([]TemplateUsageStat, error)
 	GetTemplateVersionByID(ctx context.Context, id uuid.UUID) (TemplateVersion, error)
 	GetTemplateVersionByJobID(ctx context.Context, jobID uuid.UUID) (TemplateVersion, error)
 	GetTemplateVersionByTemplateIDAndName(ctx context.Context, arg GetTemplateVersionByTemplateIDAndNameParams) (TemplateVersion, error)
+	GetTemplateVersionPresets(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionPreset, error)
+	GetTemplateVersionVariables(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionVariable, error)
 	GetTemplateVersionsByTemplateID(ctx context.Context, arg GetTemplateVersionsByTemplateIDParams) ([]TemplateVersion, error)
 	GetTemplateVersionsCreatedAfter(ctx context.Context, createdAt time.Time) ([]TemplateVersion, error)
 	GetTemplates(ctx context.Context) ([]Template, error)
 	GetTemplatesWithFilter(ctx context.Context, arg GetTemplatesWithFilterParams) ([]Template, error)
+	// Returns templates that want prebuilt workspaces, or that still have
+	// prebuilt workspaces that need to be cleaned up.
+	GetTemplatesWithPrebuilds(ctx context.Context) ([]Template, error)
+	GetTerminalShareByID(ctx context.Context, id uuid.UUID) (TerminalShare, error)
+	GetTerminalSharesByAgentID(ctx context.Context, agentID uuid.UUID) ([]TerminalShare, error)
+	// Returns files created before the given time that no provisioner job uses
+	// as its source.
+	GetUnreferencedFiles(ctx context.Context, createdAt time.Time) ([]File, error)
 	GetUserByEmailOrUsername(ctx context.Context, arg GetUserByEmailOrUsernameParams) (User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (User, error)
 	GetUserCount(ctx context.Context) (int64, error)
@@ -93,13 +130,26 @@ type querier interface {
 	GetWorkspaceResourceMetadataByResourceIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceResourceMetadatum, error)
 	GetWorkspaceResourceMetadataCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceResourceMetadatum, error)
 	GetWorkspaceResourcesByJobID(ctx context.Context, jobID uuid.UUID) ([]WorkspaceResource, error)
+	GetWorkspaceResourcesByJobIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceResource, error)
 	GetWorkspaceResourcesCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceResource, error)
+	GetWorkspaceSessionRecordingByID(ctx context.Context, id uuid.UUID) (WorkspaceSessionRecording, error)
+	GetWorkspaceSessionRecordingTokenByID(ctx context.Context, id uuid.UUID) (WorkspaceSessionRecordingToken, error)
+	// The recording data is left out, since it can be large.
+	GetWorkspaceSessionRecordingsByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) ([]GetWorkspaceSessionRecordingsByWorkspaceIDRow, error)
 	GetWorkspaces(ctx context.Context, arg GetWorkspacesParams) ([]Workspace, error)
 	GetWorkspacesAutostart(ctx context.Context) ([]Workspace, error)
+	// Returns workspaces whose template has a dormancy policy that still applies
+	// to them: active workspaces may become dormant, and dormant workspaces may
+	// be deleted.
+	GetWorkspacesDormancy(ctx context.Context) ([]Workspace, error)
+	// Returns workspaces whose template restarts outdated workspaces during its
+	// maintenance window.
+	GetWorkspacesMaintenance(ctx context.Context) ([]Workspace, error)
 	InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (APIKey, error)
 	InsertAuditLog(ctx context.Context, arg InsertAuditLogParams) (AuditLog, error)
 	InsertDeploymentID(ctx context.Context, value string) error
 	InsertFile(ctx context.Context, arg InsertFileParams) (File, error)
+	InsertFileBlob(ctx context.Context, arg InsertFileBlobParams) error
 	InsertGitSSHKey(ctx context.Context, arg InsertGitSSHKeyParams) (GitSSHKey, error)
 	InsertLicense(ctx context.Context, arg InsertLicenseParams) (License, error)
 	InsertOrganization(ctx context.Context, arg InsertOrganizationParams) (Organization, error)
@@ -109,8 +159,13 @@ type querier interface {
 	InsertProvisionerDaemon(ctx context.Context, arg InsertProvisionerDaemonParams) (ProvisionerDaemon, error)
 	InsertProvisionerJob(ctx context.Context, arg InsertProvisionerJobParams) (ProvisionerJob, error)
 	InsertProvisionerJobLogs(ctx context.Context, arg InsertProvisionerJobLogsParams) ([]ProvisionerJobLog, error)
+	InsertProvisionerJobTimings(ctx context.Context, arg InsertProvisionerJobTimingsParams) ([]ProvisionerJobTiming, error)
 	InsertTemplate(ctx context.Context, arg InsertTemplateParams) (Template, error)
+	InsertTemplateUsageStat(ctx context.Context, arg InsertTemplateUsageStatParams) error
 	InsertTemplateVersion(ctx context.Context, arg InsertTemplateVersionParams) (TemplateVersion, error)
+	InsertTemplateVersionPreset(ctx context.Context, arg InsertTemplateVersionPresetParams) (TemplateVersionPreset, error)
+	InsertTemplateVersionVariable(ctx context.Context, arg InsertTemplateVersionVariableParams) (TemplateVersionVariable, error)
+	InsertTerminalShare(ctx context.Context, arg InsertTerminalShareParams) (TerminalShare, error)
 	InsertUser(ctx context.Context, arg InsertUserParams) (User, error)
 	InsertUserLink(ctx context.Context, arg InsertUserLinkParams) (UserLink, error)
 	InsertWorkspace(ctx context.Context, arg InsertWorkspaceParams) (Workspace, error)
@@ -119,20 +174,31 @@ type querier interface {
 	InsertWorkspaceBuild(ctx context.Context, arg InsertWorkspaceBuildParams) (WorkspaceBuild, error)
 	InsertWorkspaceResource(ctx context.Context, arg InsertWorkspaceResourceParams) (WorkspaceResource, error)
 	InsertWorkspaceResourceMetadata(ctx context.Context, arg InsertWorkspaceResourceMetadataParams) (WorkspaceResourceMetadatum, error)
+	InsertWorkspaceSessionRecording(ctx context.Context, arg InsertWorkspaceSessionRecordingParams) (WorkspaceSessionRecording, error)
+	InsertWorkspaceSessionRecordingToken(ctx context.Context, arg InsertWorkspaceSessionRecordingTokenParams) (WorkspaceSessionRecordingToken, error)
 	ParameterValue(ctx context.Context, id uuid.UUID) (ParameterValue, error)
 	ParameterValues(ctx context.Context, arg ParameterValuesParams) ([]ParameterValue, error)
 	UpdateAPIKeyByID(ctx context.Context, arg UpdateAPIKeyByIDParams) error
+	// Refreshes a file that's uploaded again, so it isn't collected as garbage
+	// before it's used.
+	UpdateFileCreatedAtByHash(ctx context.Context, arg UpdateFileCreatedAtByHashParams) (File, error)
 	UpdateGitSSHKey(ctx context.Context, arg UpdateGitSSHKeyParams) error
 	UpdateMemberRoles(ctx context.Context, arg UpdateMemberRolesParams) (OrganizationMember, error)
+	UpdateOrganizationByID(ctx context.Context, arg UpdateOrganizationByIDParams) (Organization, error)
 	UpdateProvisionerDaemonByID(ctx context.Context, arg UpdateProvisionerDaemonByIDParams) error
 	UpdateProvisionerJobByID(ctx context.Context, arg UpdateProvisionerJobByIDParams) error
 	UpdateProvisionerJobWithCancelByID(ctx context.Context, arg UpdateProvisionerJobWithCancelByIDParams) error
 	UpdateProvisionerJobWithCompleteByID(ctx context.Context, arg UpdateProvisionerJobWithCompleteByIDParams) error
 	UpdateTemplateActiveVersionByID(ctx context.Context, arg UpdateTemplateActiveVersionByIDParams) error
 	UpdateTemplateDeletedByID(ctx context.Context, arg UpdateTemplateDeletedByIDParams) error
+	UpdateTemplateGitSourceSyncByTemplateID(ctx context.Context, arg UpdateTemplateGitSourceSyncByTemplateIDParams) error
 	UpdateTemplateMetaByID(ctx context.Context, arg UpdateTemplateMetaByIDParams) error
+	UpdateTemplateOrganizationByID(ctx context.Context, arg UpdateTemplateOrganizationByIDParams) error
+	UpdateTemplatePrebuildsByID(ctx context.Context, arg UpdateTemplatePrebuildsByIDParams) error
 	UpdateTemplateVersionByID(ctx context.Context, arg UpdateTemplateVersionByIDParams) error
 	UpdateTemplateVersionDescriptionByJobID(ctx context.Context, arg UpdateTemplateVersionDescriptionByJobIDParams) error
+	UpdateTemplateVersionDiagnosticsByJobID(ctx context.Context, arg UpdateTemplateVersionDiagnosticsByJobIDParams) error
+	UpdateTemplateVersionsOrganizationByTemplateID(ctx context.Context, arg UpdateTemplateVersionsOrganizationByTemplateIDParams) error
 	UpdateUserHashedPassword(ctx context.Context, arg UpdateUserHashedPasswordParams) error
 	UpdateUserLink(ctx context.Context, arg UpdateUserLinkParams) (UserLink, error)
 	UpdateUserLinkedID(ctx context.Context, arg UpdateUserLinkedIDParams) (UserLink, error)
@@ -141,11 +207,20 @@ type querier interface {
 	UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (User, error)
 	UpdateWorkspace(ctx context.Context, arg UpdateWorkspaceParams) (Workspace, error)
 	UpdateWorkspaceAgentConnectionByID(ctx context.Context, arg UpdateWorkspaceAgentConnectionByIDParams) error
+	UpdateWorkspaceAgentHealthChecksByID(ctx context.Context, arg UpdateWorkspaceAgentHealthChecksByIDParams) error
 	UpdateWorkspaceAgentKeysByID(ctx context.Context, arg UpdateWorkspaceAgentKeysByIDParams) error
+	UpdateWorkspaceAgentVersionByID(ctx context.Context, arg UpdateWorkspaceAgentVersionByIDParams) error
 	UpdateWorkspaceAutostart(ctx context.Context, arg UpdateWorkspaceAutostartParams) error
 	UpdateWorkspaceBuildByID(ctx context.Context, arg UpdateWorkspaceBuildByIDParams) error
 	UpdateWorkspaceDeletedByID(ctx context.Context, arg UpdateWorkspaceDeletedByIDParams) error
+	UpdateWorkspaceDormantAt(ctx context.Context, arg UpdateWorkspaceDormantAtParams) error
+	UpdateWorkspaceLastUsedAt(ctx context.Context, arg UpdateWorkspaceLastUsedAtParams) error
 	UpdateWorkspaceTTL(ctx context.Context, arg UpdateWorkspaceTTLParams) error
+	UpdateWorkspacesOrganizationByTemplateID(ctx context.Context, arg UpdateWorkspacesOrganizationByTemplateIDParams) error
+	// Changing where the template is sourced from resets the sync state, so the
+	// next sync creates a template version.
+	UpsertTemplateGitSource(ctx context.Context, arg UpsertTemplateGitSourceParams) (TemplateGitSource, error)
+	UpsertTemplateInsights(ctx context.Context, arg UpsertTemplateInsightsParams) error
 }
 
 var _ querier = (*sqlQuerier)(nil)
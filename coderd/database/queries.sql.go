@@ -315,9 +315,75 @@ func (q *sqlQuerier) InsertAuditLog(ctx context.Context, arg InsertAuditLogParam
 	return i, err
 }
 
+const deleteFileBlobByKey = `-- name: DeleteFileBlobByKey :exec
+DELETE FROM
+	file_blobs
+WHERE
+	key = $1
+`
+
+func (q *sqlQuerier) DeleteFileBlobByKey(ctx context.Context, key string) error {
+	_, err := q.db.ExecContext(ctx, deleteFileBlobByKey, key)
+	return err
+}
+
+const deleteUnreferencedFileByHash = `-- name: DeleteUnreferencedFileByHash :one
+DELETE FROM
+	files
+WHERE
+	hash = $1
+	AND created_at < $2
+	AND NOT EXISTS (
+		SELECT
+			1
+		FROM
+			provisioner_jobs
+		WHERE
+			provisioner_jobs.storage_method = 'file'
+			AND provisioner_jobs.storage_source = files.hash
+	) RETURNING hash, created_at, created_by, mimetype
+`
+
+type DeleteUnreferencedFileByHashParams struct {
+	Hash      string    `db:"hash" json:"hash"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Deletes a file if it was created before the given time and no provisioner
+// job uses it as its source.
+func (q *sqlQuerier) DeleteUnreferencedFileByHash(ctx context.Context, arg DeleteUnreferencedFileByHashParams) (File, error) {
+	row := q.db.QueryRowContext(ctx, deleteUnreferencedFileByHash, arg.Hash, arg.CreatedAt)
+	var i File
+	err := row.Scan(
+		&i.Hash,
+		&i.CreatedAt,
+		&i.CreatedBy,
+		&i.Mimetype,
+	)
+	return i, err
+}
+
+const getFileBlobByKey = `-- name: GetFileBlobByKey :one
+SELECT
+	key, data
+FROM
+	file_blobs
+WHERE
+	key = $1
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetFileBlobByKey(ctx context.Context, key string) (FileBlob, error) {
+	row := q.db.QueryRowContext(ctx, getFileBlobByKey, key)
+	var i FileBlob
+	err := row.Scan(&i.Key, &i.Data)
+	return i, err
+}
+
 const getFileByHash = `-- name: GetFileByHash :one
 SELECT
-	hash, created_at, created_by, mimetype, data
+	hash, created_at, created_by, mimetype
 FROM
 	files
 WHERE
@@ -334,16 +400,63 @@ func (q *sqlQuerier) GetFileByHash(ctx context.Context, hash string) (File, erro
 		&i.CreatedAt,
 		&i.CreatedBy,
 		&i.Mimetype,
-		&i.Data,
 	)
 	return i, err
 }
 
+const getUnreferencedFiles = `-- name: GetUnreferencedFiles :many
+SELECT
+	hash, created_at, created_by, mimetype
+FROM
+	files
+WHERE
+	created_at < $1
+	AND NOT EXISTS (
+		SELECT
+			1
+		FROM
+			provisioner_jobs
+		WHERE
+			provisioner_jobs.storage_method = 'file'
+			AND provisioner_jobs.storage_source = files.hash
+	)
+`
+
+// Returns files created before the given time that no provisioner job uses
+// as its source.
+func (q *sqlQuerier) GetUnreferencedFiles(ctx context.Context, createdAt time.Time) ([]File, error) {
+	rows, err := q.db.QueryContext(ctx, getUnreferencedFiles, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []File
+	for rows.Next() {
+		var i File
+		if err := rows.Scan(
+			&i.Hash,
+			&i.CreatedAt,
+			&i.CreatedBy,
+			&i.Mimetype,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const insertFile = `-- name: InsertFile :one
 INSERT INTO
-	files (hash, created_at, created_by, mimetype, "data")
+	files (hash, created_at, created_by, mimetype)
 VALUES
-	($1, $2, $3, $4, $5) RETURNING hash, created_at, created_by, mimetype, data
+	($1, $2, $3, $4) RETURNING hash, created_at, created_by, mimetype
 `
 
 type InsertFileParams struct {
@@ -351,7 +464,6 @@ type InsertFileParams struct {
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	CreatedBy uuid.UUID `db:"created_by" json:"created_by"`
 	Mimetype  string    `db:"mimetype" json:"mimetype"`
-	Data      []byte    `db:"data" json:"data"`
 }
 
 func (q *sqlQuerier) InsertFile(ctx context.Context, arg InsertFileParams) (File, error) {
@@ -360,7 +472,6 @@ func (q *sqlQuerier) InsertFile(ctx context.Context, arg InsertFileParams) (File
 		arg.CreatedAt,
 		arg.CreatedBy,
 		arg.Mimetype,
-		arg.Data,
 	)
 	var i File
 	err := row.Scan(
@@ -368,7 +479,51 @@ func (q *sqlQuerier) InsertFile(ctx context.Context, arg InsertFileParams) (File
 		&i.CreatedAt,
 		&i.CreatedBy,
 		&i.Mimetype,
-		&i.Data,
+	)
+	return i, err
+}
+
+const insertFileBlob = `-- name: InsertFileBlob :exec
+INSERT INTO
+	file_blobs (key, data)
+VALUES
+	($1, $2) ON CONFLICT (key) DO NOTHING
+`
+
+type InsertFileBlobParams struct {
+	Key  string `db:"key" json:"key"`
+	Data []byte `db:"data" json:"data"`
+}
+
+func (q *sqlQuerier) InsertFileBlob(ctx context.Context, arg InsertFileBlobParams) error {
+	_, err := q.db.ExecContext(ctx, insertFileBlob, arg.Key, arg.Data)
+	return err
+}
+
+const updateFileCreatedAtByHash = `-- name: UpdateFileCreatedAtByHash :one
+UPDATE
+	files
+SET
+	created_at = $2
+WHERE
+	hash = $1 RETURNING hash, created_at, created_by, mimetype
+`
+
+type UpdateFileCreatedAtByHashParams struct {
+	Hash      string    `db:"hash" json:"hash"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Refreshes a file that's uploaded again, so it isn't collected as garbage
+// before it's used.
+func (q *sqlQuerier) UpdateFileCreatedAtByHash(ctx context.Context, arg UpdateFileCreatedAtByHashParams) (File, error) {
+	row := q.db.QueryRowContext(ctx, updateFileCreatedAtByHash, arg.Hash, arg.CreatedAt)
+	var i File
+	err := row.Scan(
+		&i.Hash,
+		&i.CreatedAt,
+		&i.CreatedBy,
+		&i.Mimetype,
 	)
 	return i, err
 }
@@ -551,6 +706,24 @@ func (q *sqlQuerier) InsertLicense(ctx context.Context, arg InsertLicenseParams)
 	return i, err
 }
 
+const deleteOrganizationMember = `-- name: DeleteOrganizationMember :exec
+DELETE FROM
+	organization_members
+WHERE
+	organization_id = $1
+	AND user_id = $2
+`
+
+type DeleteOrganizationMemberParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *sqlQuerier) DeleteOrganizationMember(ctx context.Context, arg DeleteOrganizationMemberParams) error {
+	_, err := q.db.ExecContext(ctx, deleteOrganizationMember, arg.OrganizationID, arg.UserID)
+	return err
+}
+
 const getOrganizationIDsByMemberIDs = `-- name: GetOrganizationIDsByMemberIDs :many
 SELECT
     user_id, array_agg(organization_id) :: uuid [ ] AS "organization_IDs"
@@ -620,6 +793,46 @@ func (q *sqlQuerier) GetOrganizationMemberByUserID(ctx context.Context, arg GetO
 	return i, err
 }
 
+const getOrganizationMembersByOrganizationID = `-- name: GetOrganizationMembersByOrganizationID :many
+SELECT
+	user_id, organization_id, created_at, updated_at, roles
+FROM
+	organization_members
+WHERE
+	organization_id = $1
+ORDER BY
+	created_at ASC
+`
+
+func (q *sqlQuerier) GetOrganizationMembersByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]OrganizationMember, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationMembersByOrganizationID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrganizationMember
+	for rows.Next() {
+		var i OrganizationMember
+		if err := rows.Scan(
+			&i.UserID,
+			&i.OrganizationID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			pq.Array(&i.Roles),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getOrganizationMembershipsByUserID = `-- name: GetOrganizationMembershipsByUserID :many
 SELECT
 	user_id, organization_id, created_at, updated_at, roles
@@ -729,6 +942,18 @@ func (q *sqlQuerier) UpdateMemberRoles(ctx context.Context, arg UpdateMemberRole
 	return i, err
 }
 
+const deleteOrganizationByID = `-- name: DeleteOrganizationByID :exec
+DELETE FROM
+	organizations
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) DeleteOrganizationByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteOrganizationByID, id)
+	return err
+}
+
 const getOrganizationByID = `-- name: GetOrganizationByID :one
 SELECT
 	id, name, description, created_at, updated_at
@@ -817,7 +1042,7 @@ SELECT
 FROM
 	organizations
 WHERE
-	id = (
+	id = ANY(
 		SELECT
 			organization_id
 		FROM
@@ -890,6 +1115,43 @@ func (q *sqlQuerier) InsertOrganization(ctx context.Context, arg InsertOrganizat
 	return i, err
 }
 
+const updateOrganizationByID = `-- name: UpdateOrganizationByID :one
+UPDATE
+	organizations
+SET
+	"name" = $2,
+	description = $3,
+	updated_at = $4
+WHERE
+	id = $1
+RETURNING id, name, description, created_at, updated_at
+`
+
+type UpdateOrganizationByIDParams struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	Name        string    `db:"name" json:"name"`
+	Description string    `db:"description" json:"description"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateOrganizationByID(ctx context.Context, arg UpdateOrganizationByIDParams) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, updateOrganizationByID,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.UpdatedAt,
+	)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getParameterSchemasByJobID = `-- name: GetParameterSchemasByJobID :many
 SELECT
 	id, created_at, job_id, name, description, default_source_scheme, default_source_value, allow_override_source, default_destination_scheme, allow_override_destination, default_refresh, redisplay_value, validation_error, validation_condition, validation_type_system, validation_value_type, index
@@ -1840,39 +2102,554 @@ func (q *sqlQuerier) UpdateProvisionerJobWithCompleteByID(ctx context.Context, a
 	return err
 }
 
-const getDeploymentID = `-- name: GetDeploymentID :one
-SELECT value FROM site_configs WHERE key = 'deployment_id'
+const getProvisionerJobTimingsByJobID = `-- name: GetProvisionerJobTimingsByJobID :many
+SELECT
+	id, job_id, stage, resource, action, started_at, ended_at
+FROM
+	provisioner_job_timings
+WHERE
+	job_id = $1
+ORDER BY
+	started_at, id
 `
 
-func (q *sqlQuerier) GetDeploymentID(ctx context.Context) (string, error) {
-	row := q.db.QueryRowContext(ctx, getDeploymentID)
-	var value string
-	err := row.Scan(&value)
-	return value, err
+func (q *sqlQuerier) GetProvisionerJobTimingsByJobID(ctx context.Context, jobID uuid.UUID) ([]ProvisionerJobTiming, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerJobTimingsByJobID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerJobTiming
+	for rows.Next() {
+		var i ProvisionerJobTiming
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.Stage,
+			&i.Resource,
+			&i.Action,
+			&i.StartedAt,
+			&i.EndedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const insertDeploymentID = `-- name: InsertDeploymentID :exec
-INSERT INTO site_configs (key, value) VALUES ('deployment_id', $1)
+const insertProvisionerJobTimings = `-- name: InsertProvisionerJobTimings :many
+INSERT INTO
+	provisioner_job_timings
+SELECT
+	unnest($1 :: uuid [ ]) AS id,
+	$2 :: uuid AS job_id,
+	unnest($3 :: text [ ]) AS stage,
+	unnest($4 :: text [ ]) AS resource,
+	unnest($5 :: text [ ]) AS action,
+	unnest($6 :: timestamptz [ ]) AS started_at,
+	unnest($7 :: timestamptz [ ]) AS ended_at RETURNING id, job_id, stage, resource, action, started_at, ended_at
 `
 
-func (q *sqlQuerier) InsertDeploymentID(ctx context.Context, value string) error {
-	_, err := q.db.ExecContext(ctx, insertDeploymentID, value)
-	return err
+type InsertProvisionerJobTimingsParams struct {
+	ID        []uuid.UUID `db:"id" json:"id"`
+	JobID     uuid.UUID   `db:"job_id" json:"job_id"`
+	Stage     []string    `db:"stage" json:"stage"`
+	Resource  []string    `db:"resource" json:"resource"`
+	Action    []string    `db:"action" json:"action"`
+	StartedAt []time.Time `db:"started_at" json:"started_at"`
+	EndedAt   []time.Time `db:"ended_at" json:"ended_at"`
 }
 
-const getTemplateByID = `-- name: GetTemplateByID :one
-SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon
-FROM
-	templates
-WHERE
-	id = $1
-LIMIT
-	1
-`
-
-func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Template, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateByID, id)
+func (q *sqlQuerier) InsertProvisionerJobTimings(ctx context.Context, arg InsertProvisionerJobTimingsParams) ([]ProvisionerJobTiming, error) {
+	rows, err := q.db.QueryContext(ctx, insertProvisionerJobTimings,
+		pq.Array(arg.ID),
+		arg.JobID,
+		pq.Array(arg.Stage),
+		pq.Array(arg.Resource),
+		pq.Array(arg.Action),
+		pq.Array(arg.StartedAt),
+		pq.Array(arg.EndedAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerJobTiming
+	for rows.Next() {
+		var i ProvisionerJobTiming
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.Stage,
+			&i.Resource,
+			&i.Action,
+			&i.StartedAt,
+			&i.EndedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeploymentID = `-- name: GetDeploymentID :one
+SELECT value FROM site_configs WHERE key = 'deployment_id'
+`
+
+func (q *sqlQuerier) GetDeploymentID(ctx context.Context) (string, error) {
+	row := q.db.QueryRowContext(ctx, getDeploymentID)
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+const insertDeploymentID = `-- name: InsertDeploymentID :exec
+INSERT INTO site_configs (key, value) VALUES ('deployment_id', $1)
+`
+
+func (q *sqlQuerier) InsertDeploymentID(ctx context.Context, value string) error {
+	_, err := q.db.ExecContext(ctx, insertDeploymentID, value)
+	return err
+}
+
+const acquireTemplateGitSourceSync = `-- name: AcquireTemplateGitSourceSync :one
+UPDATE
+	template_git_sources
+SET
+	sync_started_at = $1 :: timestamptz
+WHERE
+	template_id = $2
+	AND (sync_started_at IS NULL OR sync_started_at < $3 :: timestamptz)
+RETURNING template_id, created_at, updated_at, created_by, repository_url, ref, subdirectory, auto_promote, commit_sha, pending_version_id, pending_dry_run_job_id, synced_at, sync_error, sync_started_at
+`
+
+type AcquireTemplateGitSourceSyncParams struct {
+	SyncStartedAt time.Time `db:"sync_started_at" json:"sync_started_at"`
+	TemplateID    uuid.UUID `db:"template_id" json:"template_id"`
+	StaleBefore   time.Time `db:"stale_before" json:"stale_before"`
+}
+
+// Claims the sync of a template, so only one runs at a time across replicas.
+// A sync that started before @stale_before is assumed to have crashed.
+func (q *sqlQuerier) AcquireTemplateGitSourceSync(ctx context.Context, arg AcquireTemplateGitSourceSyncParams) (TemplateGitSource, error) {
+	row := q.db.QueryRowContext(ctx, acquireTemplateGitSourceSync, arg.SyncStartedAt, arg.TemplateID, arg.StaleBefore)
+	var i TemplateGitSource
+	err := row.Scan(
+		&i.TemplateID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.RepositoryUrl,
+		&i.Ref,
+		&i.Subdirectory,
+		&i.AutoPromote,
+		&i.CommitSha,
+		&i.PendingVersionID,
+		&i.PendingDryRunJobID,
+		&i.SyncedAt,
+		&i.SyncError,
+		&i.SyncStartedAt,
+	)
+	return i, err
+}
+
+const deleteTemplateGitSourceByTemplateID = `-- name: DeleteTemplateGitSourceByTemplateID :exec
+DELETE FROM
+	template_git_sources
+WHERE
+	template_id = $1
+`
+
+func (q *sqlQuerier) DeleteTemplateGitSourceByTemplateID(ctx context.Context, templateID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteTemplateGitSourceByTemplateID, templateID)
+	return err
+}
+
+const getTemplateGitSourceByTemplateID = `-- name: GetTemplateGitSourceByTemplateID :one
+SELECT
+	template_id, created_at, updated_at, created_by, repository_url, ref, subdirectory, auto_promote, commit_sha, pending_version_id, pending_dry_run_job_id, synced_at, sync_error, sync_started_at
+FROM
+	template_git_sources
+WHERE
+	template_id = $1
+`
+
+func (q *sqlQuerier) GetTemplateGitSourceByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateGitSource, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateGitSourceByTemplateID, templateID)
+	var i TemplateGitSource
+	err := row.Scan(
+		&i.TemplateID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.RepositoryUrl,
+		&i.Ref,
+		&i.Subdirectory,
+		&i.AutoPromote,
+		&i.CommitSha,
+		&i.PendingVersionID,
+		&i.PendingDryRunJobID,
+		&i.SyncedAt,
+		&i.SyncError,
+		&i.SyncStartedAt,
+	)
+	return i, err
+}
+
+const getTemplateGitSources = `-- name: GetTemplateGitSources :many
+SELECT
+	template_git_sources.template_id, template_git_sources.created_at, template_git_sources.updated_at, template_git_sources.created_by, template_git_sources.repository_url, template_git_sources.ref, template_git_sources.subdirectory, template_git_sources.auto_promote, template_git_sources.commit_sha, template_git_sources.pending_version_id, template_git_sources.pending_dry_run_job_id, template_git_sources.synced_at, template_git_sources.sync_error, template_git_sources.sync_started_at
+FROM
+	template_git_sources
+INNER JOIN
+	templates ON templates.id = template_git_sources.template_id
+WHERE
+	templates.deleted = false
+ORDER BY
+	template_git_sources.created_at ASC
+`
+
+func (q *sqlQuerier) GetTemplateGitSources(ctx context.Context) ([]TemplateGitSource, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateGitSources)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateGitSource
+	for rows.Next() {
+		var i TemplateGitSource
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CreatedBy,
+			&i.RepositoryUrl,
+			&i.Ref,
+			&i.Subdirectory,
+			&i.AutoPromote,
+			&i.CommitSha,
+			&i.PendingVersionID,
+			&i.PendingDryRunJobID,
+			&i.SyncedAt,
+			&i.SyncError,
+			&i.SyncStartedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateTemplateGitSourceSyncByTemplateID = `-- name: UpdateTemplateGitSourceSyncByTemplateID :exec
+UPDATE
+	template_git_sources
+SET
+	commit_sha = $2,
+	pending_version_id = $3,
+	pending_dry_run_job_id = $4,
+	synced_at = $5,
+	sync_error = $6,
+	sync_started_at = NULL
+WHERE
+	template_id = $1
+`
+
+type UpdateTemplateGitSourceSyncByTemplateIDParams struct {
+	TemplateID         uuid.UUID     `db:"template_id" json:"template_id"`
+	CommitSha          string        `db:"commit_sha" json:"commit_sha"`
+	PendingVersionID   uuid.NullUUID `db:"pending_version_id" json:"pending_version_id"`
+	PendingDryRunJobID uuid.NullUUID `db:"pending_dry_run_job_id" json:"pending_dry_run_job_id"`
+	SyncedAt           sql.NullTime  `db:"synced_at" json:"synced_at"`
+	SyncError          string        `db:"sync_error" json:"sync_error"`
+}
+
+func (q *sqlQuerier) UpdateTemplateGitSourceSyncByTemplateID(ctx context.Context, arg UpdateTemplateGitSourceSyncByTemplateIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateGitSourceSyncByTemplateID,
+		arg.TemplateID,
+		arg.CommitSha,
+		arg.PendingVersionID,
+		arg.PendingDryRunJobID,
+		arg.SyncedAt,
+		arg.SyncError,
+	)
+	return err
+}
+
+const upsertTemplateGitSource = `-- name: UpsertTemplateGitSource :one
+INSERT INTO
+	template_git_sources (
+		template_id,
+		created_at,
+		updated_at,
+		created_by,
+		repository_url,
+		ref,
+		subdirectory,
+		auto_promote
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (template_id) DO UPDATE
+SET
+	updated_at = $3,
+	created_by = $4,
+	repository_url = $5,
+	ref = $6,
+	subdirectory = $7,
+	auto_promote = $8,
+	commit_sha = CASE
+		WHEN (template_git_sources.repository_url, template_git_sources.ref, template_git_sources.subdirectory) = ($5, $6, $7)
+			THEN template_git_sources.commit_sha
+		ELSE ''
+	END
+RETURNING template_id, created_at, updated_at, created_by, repository_url, ref, subdirectory, auto_promote, commit_sha, pending_version_id, pending_dry_run_job_id, synced_at, sync_error, sync_started_at
+`
+
+type UpsertTemplateGitSourceParams struct {
+	TemplateID    uuid.UUID `db:"template_id" json:"template_id"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+	CreatedBy     uuid.UUID `db:"created_by" json:"created_by"`
+	RepositoryUrl string    `db:"repository_url" json:"repository_url"`
+	Ref           string    `db:"ref" json:"ref"`
+	Subdirectory  string    `db:"subdirectory" json:"subdirectory"`
+	AutoPromote   bool      `db:"auto_promote" json:"auto_promote"`
+}
+
+// Changing where the template is sourced from resets the sync state, so the
+// next sync creates a template version.
+func (q *sqlQuerier) UpsertTemplateGitSource(ctx context.Context, arg UpsertTemplateGitSourceParams) (TemplateGitSource, error) {
+	row := q.db.QueryRowContext(ctx, upsertTemplateGitSource,
+		arg.TemplateID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.CreatedBy,
+		arg.RepositoryUrl,
+		arg.Ref,
+		arg.Subdirectory,
+		arg.AutoPromote,
+	)
+	var i TemplateGitSource
+	err := row.Scan(
+		&i.TemplateID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.RepositoryUrl,
+		&i.Ref,
+		&i.Subdirectory,
+		&i.AutoPromote,
+		&i.CommitSha,
+		&i.PendingVersionID,
+		&i.PendingDryRunJobID,
+		&i.SyncedAt,
+		&i.SyncError,
+		&i.SyncStartedAt,
+	)
+	return i, err
+}
+
+const getTemplateInsights = `-- name: GetTemplateInsights :many
+SELECT
+	template_id, date, updated_at, active_users, builds_total, builds_succeeded, build_duration_median_ms, agent_connect_median_ms, app_usage
+FROM
+	template_insights
+WHERE
+	template_id = $1
+	AND date >= $2 :: date
+	AND date < $3 :: date
+ORDER BY
+	date ASC
+`
+
+type GetTemplateInsightsParams struct {
+	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
+	StartDate  time.Time `db:"start_date" json:"start_date"`
+	EndDate    time.Time `db:"end_date" json:"end_date"`
+}
+
+func (q *sqlQuerier) GetTemplateInsights(ctx context.Context, arg GetTemplateInsightsParams) ([]TemplateInsight, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateInsights, arg.TemplateID, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateInsight
+	for rows.Next() {
+		var i TemplateInsight
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.Date,
+			&i.UpdatedAt,
+			&i.ActiveUsers,
+			&i.BuildsTotal,
+			&i.BuildsSucceeded,
+			&i.BuildDurationMedianMs,
+			&i.AgentConnectMedianMs,
+			&i.AppUsage,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTemplateUsageStatsAfter = `-- name: GetTemplateUsageStatsAfter :many
+SELECT
+	template_id, user_id, date, app_name
+FROM
+	template_usage_stats
+WHERE
+	date >= $1
+`
+
+func (q *sqlQuerier) GetTemplateUsageStatsAfter(ctx context.Context, date time.Time) ([]TemplateUsageStat, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateUsageStatsAfter, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateUsageStat
+	for rows.Next() {
+		var i TemplateUsageStat
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.UserID,
+			&i.Date,
+			&i.AppName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertTemplateUsageStat = `-- name: InsertTemplateUsageStat :exec
+INSERT INTO
+	template_usage_stats (template_id, user_id, date, app_name)
+VALUES
+	($1, $2, $3, $4)
+ON CONFLICT DO NOTHING
+`
+
+type InsertTemplateUsageStatParams struct {
+	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
+	UserID     uuid.UUID `db:"user_id" json:"user_id"`
+	Date       time.Time `db:"date" json:"date"`
+	AppName    string    `db:"app_name" json:"app_name"`
+}
+
+func (q *sqlQuerier) InsertTemplateUsageStat(ctx context.Context, arg InsertTemplateUsageStatParams) error {
+	_, err := q.db.ExecContext(ctx, insertTemplateUsageStat,
+		arg.TemplateID,
+		arg.UserID,
+		arg.Date,
+		arg.AppName,
+	)
+	return err
+}
+
+const upsertTemplateInsights = `-- name: UpsertTemplateInsights :exec
+INSERT INTO
+	template_insights (
+		template_id,
+		date,
+		updated_at,
+		active_users,
+		builds_total,
+		builds_succeeded,
+		build_duration_median_ms,
+		agent_connect_median_ms,
+		app_usage
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (template_id, date) DO UPDATE
+SET
+	updated_at = $3,
+	active_users = $4,
+	builds_total = $5,
+	builds_succeeded = $6,
+	build_duration_median_ms = $7,
+	agent_connect_median_ms = $8,
+	app_usage = $9
+`
+
+type UpsertTemplateInsightsParams struct {
+	TemplateID            uuid.UUID       `db:"template_id" json:"template_id"`
+	Date                  time.Time       `db:"date" json:"date"`
+	UpdatedAt             time.Time       `db:"updated_at" json:"updated_at"`
+	ActiveUsers           int32           `db:"active_users" json:"active_users"`
+	BuildsTotal           int32           `db:"builds_total" json:"builds_total"`
+	BuildsSucceeded       int32           `db:"builds_succeeded" json:"builds_succeeded"`
+	BuildDurationMedianMs sql.NullInt64   `db:"build_duration_median_ms" json:"build_duration_median_ms"`
+	AgentConnectMedianMs  sql.NullInt64   `db:"agent_connect_median_ms" json:"agent_connect_median_ms"`
+	AppUsage              json.RawMessage `db:"app_usage" json:"app_usage"`
+}
+
+func (q *sqlQuerier) UpsertTemplateInsights(ctx context.Context, arg UpsertTemplateInsightsParams) error {
+	_, err := q.db.ExecContext(ctx, upsertTemplateInsights,
+		arg.TemplateID,
+		arg.Date,
+		arg.UpdatedAt,
+		arg.ActiveUsers,
+		arg.BuildsTotal,
+		arg.BuildsSucceeded,
+		arg.BuildDurationMedianMs,
+		arg.AgentConnectMedianMs,
+		arg.AppUsage,
+	)
+	return err
+}
+
+const getTemplateByID = `-- name: GetTemplateByID :one
+SELECT
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, inactivity_ttl, dormant_autodelete_ttl, maintenance_window, maintenance_window_duration, maintenance_restart_outdated, prebuild_count, prebuild_parameters, record_sessions
+FROM
+	templates
+WHERE
+	id = $1
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Template, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateByID, id)
 	var i Template
 	err := row.Scan(
 		&i.ID,
@@ -1888,13 +2665,21 @@ func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Templat
 		&i.MinAutostartInterval,
 		&i.CreatedBy,
 		&i.Icon,
+		&i.InactivityTtl,
+		&i.DormantAutodeleteTtl,
+		&i.MaintenanceWindow,
+		&i.MaintenanceWindowDuration,
+		&i.MaintenanceRestartOutdated,
+		&i.PrebuildCount,
+		&i.PrebuildParameters,
+		&i.RecordSessions,
 	)
 	return i, err
 }
 
 const getTemplateByOrganizationAndName = `-- name: GetTemplateByOrganizationAndName :one
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, inactivity_ttl, dormant_autodelete_ttl, maintenance_window, maintenance_window_duration, maintenance_restart_outdated, prebuild_count, prebuild_parameters, record_sessions
 FROM
 	templates
 WHERE
@@ -1928,12 +2713,20 @@ func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg G
 		&i.MinAutostartInterval,
 		&i.CreatedBy,
 		&i.Icon,
+		&i.InactivityTtl,
+		&i.DormantAutodeleteTtl,
+		&i.MaintenanceWindow,
+		&i.MaintenanceWindowDuration,
+		&i.MaintenanceRestartOutdated,
+		&i.PrebuildCount,
+		&i.PrebuildParameters,
+		&i.RecordSessions,
 	)
 	return i, err
 }
 
 const getTemplates = `-- name: GetTemplates :many
-SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon FROM templates
+SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, inactivity_ttl, dormant_autodelete_ttl, maintenance_window, maintenance_window_duration, maintenance_restart_outdated, prebuild_count, prebuild_parameters, record_sessions FROM templates
 ORDER BY (name, id) ASC
 `
 
@@ -1960,6 +2753,14 @@ func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
 			&i.MinAutostartInterval,
 			&i.CreatedBy,
 			&i.Icon,
+			&i.InactivityTtl,
+			&i.DormantAutodeleteTtl,
+			&i.MaintenanceWindow,
+			&i.MaintenanceWindowDuration,
+			&i.MaintenanceRestartOutdated,
+			&i.PrebuildCount,
+			&i.PrebuildParameters,
+			&i.RecordSessions,
 		); err != nil {
 			return nil, err
 		}
@@ -1976,7 +2777,7 @@ func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
 
 const getTemplatesWithFilter = `-- name: GetTemplatesWithFilter :many
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, inactivity_ttl, dormant_autodelete_ttl, maintenance_window, maintenance_window_duration, maintenance_restart_outdated, prebuild_count, prebuild_parameters, record_sessions
 FROM
 	templates
 WHERE
@@ -2038,6 +2839,83 @@ func (q *sqlQuerier) GetTemplatesWithFilter(ctx context.Context, arg GetTemplate
 			&i.MinAutostartInterval,
 			&i.CreatedBy,
 			&i.Icon,
+			&i.InactivityTtl,
+			&i.DormantAutodeleteTtl,
+			&i.MaintenanceWindow,
+			&i.MaintenanceWindowDuration,
+			&i.MaintenanceRestartOutdated,
+			&i.PrebuildCount,
+			&i.PrebuildParameters,
+			&i.RecordSessions,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTemplatesWithPrebuilds = `-- name: GetTemplatesWithPrebuilds :many
+SELECT
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, inactivity_ttl, dormant_autodelete_ttl, maintenance_window, maintenance_window_duration, maintenance_restart_outdated, prebuild_count, prebuild_parameters, record_sessions
+FROM
+	templates
+WHERE
+	deleted = false
+AND (
+	prebuild_count > 0
+	OR id IN (
+		SELECT
+			template_id
+		FROM
+			workspaces
+		WHERE
+			prebuild = true
+		AND
+			deleted = false
+	)
+)
+`
+
+// Returns templates that want prebuilt workspaces, or that still have
+// prebuilt workspaces that need to be cleaned up.
+func (q *sqlQuerier) GetTemplatesWithPrebuilds(ctx context.Context) ([]Template, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplatesWithPrebuilds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Template
+	for rows.Next() {
+		var i Template
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OrganizationID,
+			&i.Deleted,
+			&i.Name,
+			&i.Provisioner,
+			&i.ActiveVersionID,
+			&i.Description,
+			&i.MaxTtl,
+			&i.MinAutostartInterval,
+			&i.CreatedBy,
+			&i.Icon,
+			&i.InactivityTtl,
+			&i.DormantAutodeleteTtl,
+			&i.MaintenanceWindow,
+			&i.MaintenanceWindowDuration,
+			&i.MaintenanceRestartOutdated,
+			&i.PrebuildCount,
+			&i.PrebuildParameters,
+			&i.RecordSessions,
 		); err != nil {
 			return nil, err
 		}
@@ -2069,7 +2947,7 @@ INSERT INTO
 		icon
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, inactivity_ttl, dormant_autodelete_ttl, maintenance_window, maintenance_window_duration, maintenance_restart_outdated, prebuild_count, prebuild_parameters, record_sessions
 `
 
 type InsertTemplateParams struct {
@@ -2117,6 +2995,14 @@ func (q *sqlQuerier) InsertTemplate(ctx context.Context, arg InsertTemplateParam
 		&i.MinAutostartInterval,
 		&i.CreatedBy,
 		&i.Icon,
+		&i.InactivityTtl,
+		&i.DormantAutodeleteTtl,
+		&i.MaintenanceWindow,
+		&i.MaintenanceWindowDuration,
+		&i.MaintenanceRestartOutdated,
+		&i.PrebuildCount,
+		&i.PrebuildParameters,
+		&i.RecordSessions,
 	)
 	return i, err
 }
@@ -2172,21 +3058,33 @@ SET
 	max_ttl = $4,
 	min_autostart_interval = $5,
 	name = $6,
-	icon = $7
+	icon = $7,
+	inactivity_ttl = $8,
+	dormant_autodelete_ttl = $9,
+	maintenance_window = $10,
+	maintenance_window_duration = $11,
+	maintenance_restart_outdated = $12,
+	record_sessions = $13
 WHERE
 	id = $1
 RETURNING
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, inactivity_ttl, dormant_autodelete_ttl, maintenance_window, maintenance_window_duration, maintenance_restart_outdated, prebuild_count, prebuild_parameters, record_sessions
 `
 
 type UpdateTemplateMetaByIDParams struct {
-	ID                   uuid.UUID `db:"id" json:"id"`
-	UpdatedAt            time.Time `db:"updated_at" json:"updated_at"`
-	Description          string    `db:"description" json:"description"`
-	MaxTtl               int64     `db:"max_ttl" json:"max_ttl"`
-	MinAutostartInterval int64     `db:"min_autostart_interval" json:"min_autostart_interval"`
-	Name                 string    `db:"name" json:"name"`
-	Icon                 string    `db:"icon" json:"icon"`
+	ID                         uuid.UUID `db:"id" json:"id"`
+	UpdatedAt                  time.Time `db:"updated_at" json:"updated_at"`
+	Description                string    `db:"description" json:"description"`
+	MaxTtl                     int64     `db:"max_ttl" json:"max_ttl"`
+	MinAutostartInterval       int64     `db:"min_autostart_interval" json:"min_autostart_interval"`
+	Name                       string    `db:"name" json:"name"`
+	Icon                       string    `db:"icon" json:"icon"`
+	InactivityTtl              int64     `db:"inactivity_ttl" json:"inactivity_ttl"`
+	DormantAutodeleteTtl       int64     `db:"dormant_autodelete_ttl" json:"dormant_autodelete_ttl"`
+	MaintenanceWindow          string    `db:"maintenance_window" json:"maintenance_window"`
+	MaintenanceWindowDuration  int64     `db:"maintenance_window_duration" json:"maintenance_window_duration"`
+	MaintenanceRestartOutdated bool      `db:"maintenance_restart_outdated" json:"maintenance_restart_outdated"`
+	RecordSessions             bool      `db:"record_sessions" json:"record_sessions"`
 }
 
 func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTemplateMetaByIDParams) error {
@@ -2197,14 +3095,149 @@ func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTempl
 		arg.MaxTtl,
 		arg.MinAutostartInterval,
 		arg.Name,
-		arg.Icon,
+		arg.Icon,
+		arg.InactivityTtl,
+		arg.DormantAutodeleteTtl,
+		arg.MaintenanceWindow,
+		arg.MaintenanceWindowDuration,
+		arg.MaintenanceRestartOutdated,
+		arg.RecordSessions,
+	)
+	return err
+}
+
+const updateTemplateOrganizationByID = `-- name: UpdateTemplateOrganizationByID :exec
+UPDATE
+	templates
+SET
+	organization_id = $2,
+	updated_at = $3
+WHERE
+	id = $1
+`
+
+type UpdateTemplateOrganizationByIDParams struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateTemplateOrganizationByID(ctx context.Context, arg UpdateTemplateOrganizationByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateOrganizationByID, arg.ID, arg.OrganizationID, arg.UpdatedAt)
+	return err
+}
+
+const updateTemplatePrebuildsByID = `-- name: UpdateTemplatePrebuildsByID :exec
+UPDATE
+	templates
+SET
+	updated_at = $2,
+	prebuild_count = $3,
+	prebuild_parameters = $4
+WHERE
+	id = $1
+`
+
+type UpdateTemplatePrebuildsByIDParams struct {
+	ID                 uuid.UUID       `db:"id" json:"id"`
+	UpdatedAt          time.Time       `db:"updated_at" json:"updated_at"`
+	PrebuildCount      int32           `db:"prebuild_count" json:"prebuild_count"`
+	PrebuildParameters json.RawMessage `db:"prebuild_parameters" json:"prebuild_parameters"`
+}
+
+func (q *sqlQuerier) UpdateTemplatePrebuildsByID(ctx context.Context, arg UpdateTemplatePrebuildsByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplatePrebuildsByID,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.PrebuildCount,
+		arg.PrebuildParameters,
+	)
+	return err
+}
+
+const getTemplateVersionPresets = `-- name: GetTemplateVersionPresets :many
+SELECT
+	template_version_id, name, description, parameters, index
+FROM
+	template_version_presets
+WHERE
+	template_version_id = $1
+ORDER BY
+	"index"
+`
+
+func (q *sqlQuerier) GetTemplateVersionPresets(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionPreset, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionPresets, templateVersionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateVersionPreset
+	for rows.Next() {
+		var i TemplateVersionPreset
+		if err := rows.Scan(
+			&i.TemplateVersionID,
+			&i.Name,
+			&i.Description,
+			&i.Parameters,
+			&i.Index,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertTemplateVersionPreset = `-- name: InsertTemplateVersionPreset :one
+INSERT INTO
+	template_version_presets (
+		template_version_id,
+		"name",
+		description,
+		parameters,
+		"index"
+	)
+VALUES
+	($1, $2, $3, $4, $5) RETURNING template_version_id, name, description, parameters, index
+`
+
+type InsertTemplateVersionPresetParams struct {
+	TemplateVersionID uuid.UUID       `db:"template_version_id" json:"template_version_id"`
+	Name              string          `db:"name" json:"name"`
+	Description       string          `db:"description" json:"description"`
+	Parameters        json.RawMessage `db:"parameters" json:"parameters"`
+	Index             int32           `db:"index" json:"index"`
+}
+
+func (q *sqlQuerier) InsertTemplateVersionPreset(ctx context.Context, arg InsertTemplateVersionPresetParams) (TemplateVersionPreset, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplateVersionPreset,
+		arg.TemplateVersionID,
+		arg.Name,
+		arg.Description,
+		arg.Parameters,
+		arg.Index,
 	)
-	return err
+	var i TemplateVersionPreset
+	err := row.Scan(
+		&i.TemplateVersionID,
+		&i.Name,
+		&i.Description,
+		&i.Parameters,
+		&i.Index,
+	)
+	return i, err
 }
 
 const getTemplateVersionByID = `-- name: GetTemplateVersionByID :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, message, git_metadata, diagnostics
 FROM
 	template_versions
 WHERE
@@ -2224,13 +3257,16 @@ func (q *sqlQuerier) GetTemplateVersionByID(ctx context.Context, id uuid.UUID) (
 		&i.Readme,
 		&i.JobID,
 		&i.CreatedBy,
+		&i.Message,
+		&i.GitMetadata,
+		&i.Diagnostics,
 	)
 	return i, err
 }
 
 const getTemplateVersionByJobID = `-- name: GetTemplateVersionByJobID :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, message, git_metadata, diagnostics
 FROM
 	template_versions
 WHERE
@@ -2250,13 +3286,16 @@ func (q *sqlQuerier) GetTemplateVersionByJobID(ctx context.Context, jobID uuid.U
 		&i.Readme,
 		&i.JobID,
 		&i.CreatedBy,
+		&i.Message,
+		&i.GitMetadata,
+		&i.Diagnostics,
 	)
 	return i, err
 }
 
 const getTemplateVersionByTemplateIDAndName = `-- name: GetTemplateVersionByTemplateIDAndName :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, message, git_metadata, diagnostics
 FROM
 	template_versions
 WHERE
@@ -2282,13 +3321,16 @@ func (q *sqlQuerier) GetTemplateVersionByTemplateIDAndName(ctx context.Context,
 		&i.Readme,
 		&i.JobID,
 		&i.CreatedBy,
+		&i.Message,
+		&i.GitMetadata,
+		&i.Diagnostics,
 	)
 	return i, err
 }
 
 const getTemplateVersionsByTemplateID = `-- name: GetTemplateVersionsByTemplateID :many
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, message, git_metadata, diagnostics
 FROM
 	template_versions
 WHERE
@@ -2352,6 +3394,9 @@ func (q *sqlQuerier) GetTemplateVersionsByTemplateID(ctx context.Context, arg Ge
 			&i.Readme,
 			&i.JobID,
 			&i.CreatedBy,
+			&i.Message,
+			&i.GitMetadata,
+			&i.Diagnostics,
 		); err != nil {
 			return nil, err
 		}
@@ -2367,28 +3412,355 @@ func (q *sqlQuerier) GetTemplateVersionsByTemplateID(ctx context.Context, arg Ge
 }
 
 const getTemplateVersionsCreatedAfter = `-- name: GetTemplateVersionsCreatedAfter :many
-SELECT id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by FROM template_versions WHERE created_at > $1
+SELECT id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, message, git_metadata, diagnostics FROM template_versions WHERE created_at > $1
+`
+
+func (q *sqlQuerier) GetTemplateVersionsCreatedAfter(ctx context.Context, createdAt time.Time) ([]TemplateVersion, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionsCreatedAfter, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateVersion
+	for rows.Next() {
+		var i TemplateVersion
+		if err := rows.Scan(
+			&i.ID,
+			&i.TemplateID,
+			&i.OrganizationID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Readme,
+			&i.JobID,
+			&i.CreatedBy,
+			&i.Message,
+			&i.GitMetadata,
+			&i.Diagnostics,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertTemplateVersion = `-- name: InsertTemplateVersion :one
+INSERT INTO
+	template_versions (
+		id,
+		template_id,
+		organization_id,
+		created_at,
+		updated_at,
+		"name",
+		readme,
+		job_id,
+		created_by,
+		message,
+		git_metadata
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, message, git_metadata, diagnostics
+`
+
+type InsertTemplateVersionParams struct {
+	ID             uuid.UUID             `db:"id" json:"id"`
+	TemplateID     uuid.NullUUID         `db:"template_id" json:"template_id"`
+	OrganizationID uuid.UUID             `db:"organization_id" json:"organization_id"`
+	CreatedAt      time.Time             `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time             `db:"updated_at" json:"updated_at"`
+	Name           string                `db:"name" json:"name"`
+	Readme         string                `db:"readme" json:"readme"`
+	JobID          uuid.UUID             `db:"job_id" json:"job_id"`
+	CreatedBy      uuid.NullUUID         `db:"created_by" json:"created_by"`
+	Message        string                `db:"message" json:"message"`
+	GitMetadata    pqtype.NullRawMessage `db:"git_metadata" json:"git_metadata"`
+}
+
+func (q *sqlQuerier) InsertTemplateVersion(ctx context.Context, arg InsertTemplateVersionParams) (TemplateVersion, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplateVersion,
+		arg.ID,
+		arg.TemplateID,
+		arg.OrganizationID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Name,
+		arg.Readme,
+		arg.JobID,
+		arg.CreatedBy,
+		arg.Message,
+		arg.GitMetadata,
+	)
+	var i TemplateVersion
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
+		&i.OrganizationID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Readme,
+		&i.JobID,
+		&i.CreatedBy,
+		&i.Message,
+		&i.GitMetadata,
+		&i.Diagnostics,
+	)
+	return i, err
+}
+
+const updateTemplateVersionByID = `-- name: UpdateTemplateVersionByID :exec
+UPDATE
+	template_versions
+SET
+	template_id = $2,
+	updated_at = $3
+WHERE
+	id = $1
+`
+
+type UpdateTemplateVersionByIDParams struct {
+	ID         uuid.UUID     `db:"id" json:"id"`
+	TemplateID uuid.NullUUID `db:"template_id" json:"template_id"`
+	UpdatedAt  time.Time     `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateTemplateVersionByID(ctx context.Context, arg UpdateTemplateVersionByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateVersionByID, arg.ID, arg.TemplateID, arg.UpdatedAt)
+	return err
+}
+
+const updateTemplateVersionDescriptionByJobID = `-- name: UpdateTemplateVersionDescriptionByJobID :exec
+UPDATE
+	template_versions
+SET
+	readme = $2,
+	updated_at = $3
+WHERE
+	job_id = $1
+`
+
+type UpdateTemplateVersionDescriptionByJobIDParams struct {
+	JobID     uuid.UUID `db:"job_id" json:"job_id"`
+	Readme    string    `db:"readme" json:"readme"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateTemplateVersionDescriptionByJobID(ctx context.Context, arg UpdateTemplateVersionDescriptionByJobIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateVersionDescriptionByJobID, arg.JobID, arg.Readme, arg.UpdatedAt)
+	return err
+}
+
+const updateTemplateVersionDiagnosticsByJobID = `-- name: UpdateTemplateVersionDiagnosticsByJobID :exec
+UPDATE
+	template_versions
+SET
+	diagnostics = $2,
+	updated_at = $3
+WHERE
+	job_id = $1
+`
+
+type UpdateTemplateVersionDiagnosticsByJobIDParams struct {
+	JobID       uuid.UUID       `db:"job_id" json:"job_id"`
+	Diagnostics json.RawMessage `db:"diagnostics" json:"diagnostics"`
+	UpdatedAt   time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateTemplateVersionDiagnosticsByJobID(ctx context.Context, arg UpdateTemplateVersionDiagnosticsByJobIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateVersionDiagnosticsByJobID, arg.JobID, arg.Diagnostics, arg.UpdatedAt)
+	return err
+}
+
+const updateTemplateVersionsOrganizationByTemplateID = `-- name: UpdateTemplateVersionsOrganizationByTemplateID :exec
+UPDATE
+	template_versions
+SET
+	organization_id = $2,
+	updated_at = $3
+WHERE
+	template_id = $1
+`
+
+type UpdateTemplateVersionsOrganizationByTemplateIDParams struct {
+	TemplateID     uuid.NullUUID `db:"template_id" json:"template_id"`
+	OrganizationID uuid.UUID     `db:"organization_id" json:"organization_id"`
+	UpdatedAt      time.Time     `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateTemplateVersionsOrganizationByTemplateID(ctx context.Context, arg UpdateTemplateVersionsOrganizationByTemplateIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateVersionsOrganizationByTemplateID, arg.TemplateID, arg.OrganizationID, arg.UpdatedAt)
+	return err
+}
+
+const deleteTemplateVersionVariable = `-- name: DeleteTemplateVersionVariable :exec
+DELETE FROM
+	template_version_variables
+WHERE
+	template_version_id = $1
+	AND "name" = $2
+`
+
+type DeleteTemplateVersionVariableParams struct {
+	TemplateVersionID uuid.UUID `db:"template_version_id" json:"template_version_id"`
+	Name              string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) DeleteTemplateVersionVariable(ctx context.Context, arg DeleteTemplateVersionVariableParams) error {
+	_, err := q.db.ExecContext(ctx, deleteTemplateVersionVariable, arg.TemplateVersionID, arg.Name)
+	return err
+}
+
+const getTemplateVersionVariables = `-- name: GetTemplateVersionVariables :many
+SELECT
+	template_version_id, name, value, sensitive
+FROM
+	template_version_variables
+WHERE
+	template_version_id = $1
+ORDER BY
+	"name"
+`
+
+func (q *sqlQuerier) GetTemplateVersionVariables(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionVariable, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionVariables, templateVersionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateVersionVariable
+	for rows.Next() {
+		var i TemplateVersionVariable
+		if err := rows.Scan(
+			&i.TemplateVersionID,
+			&i.Name,
+			&i.Value,
+			&i.Sensitive,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertTemplateVersionVariable = `-- name: InsertTemplateVersionVariable :one
+INSERT INTO
+	template_version_variables (
+		template_version_id,
+		"name",
+		"value",
+		sensitive
+	)
+VALUES
+	($1, $2, $3, $4) RETURNING template_version_id, name, value, sensitive
+`
+
+type InsertTemplateVersionVariableParams struct {
+	TemplateVersionID uuid.UUID `db:"template_version_id" json:"template_version_id"`
+	Name              string    `db:"name" json:"name"`
+	Value             string    `db:"value" json:"value"`
+	Sensitive         bool      `db:"sensitive" json:"sensitive"`
+}
+
+func (q *sqlQuerier) InsertTemplateVersionVariable(ctx context.Context, arg InsertTemplateVersionVariableParams) (TemplateVersionVariable, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplateVersionVariable,
+		arg.TemplateVersionID,
+		arg.Name,
+		arg.Value,
+		arg.Sensitive,
+	)
+	var i TemplateVersionVariable
+	err := row.Scan(
+		&i.TemplateVersionID,
+		&i.Name,
+		&i.Value,
+		&i.Sensitive,
+	)
+	return i, err
+}
+
+const deleteTerminalShareByID = `-- name: DeleteTerminalShareByID :exec
+DELETE FROM
+	terminal_shares
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) DeleteTerminalShareByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteTerminalShareByID, id)
+	return err
+}
+
+const getTerminalShareByID = `-- name: GetTerminalShareByID :one
+SELECT
+	id, created_at, workspace_id, agent_id, reconnect_id, created_by, mode, recipients, expires_at
+FROM
+	terminal_shares
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetTerminalShareByID(ctx context.Context, id uuid.UUID) (TerminalShare, error) {
+	row := q.db.QueryRowContext(ctx, getTerminalShareByID, id)
+	var i TerminalShare
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.WorkspaceID,
+		&i.AgentID,
+		&i.ReconnectID,
+		&i.CreatedBy,
+		&i.Mode,
+		pq.Array(&i.Recipients),
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getTerminalSharesByAgentID = `-- name: GetTerminalSharesByAgentID :many
+SELECT
+	id, created_at, workspace_id, agent_id, reconnect_id, created_by, mode, recipients, expires_at
+FROM
+	terminal_shares
+WHERE
+	agent_id = $1
+ORDER BY
+	created_at ASC
 `
 
-func (q *sqlQuerier) GetTemplateVersionsCreatedAfter(ctx context.Context, createdAt time.Time) ([]TemplateVersion, error) {
-	rows, err := q.db.QueryContext(ctx, getTemplateVersionsCreatedAfter, createdAt)
+func (q *sqlQuerier) GetTerminalSharesByAgentID(ctx context.Context, agentID uuid.UUID) ([]TerminalShare, error) {
+	rows, err := q.db.QueryContext(ctx, getTerminalSharesByAgentID, agentID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []TemplateVersion
+	var items []TerminalShare
 	for rows.Next() {
-		var i TemplateVersion
+		var i TerminalShare
 		if err := rows.Scan(
 			&i.ID,
-			&i.TemplateID,
-			&i.OrganizationID,
 			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.Name,
-			&i.Readme,
-			&i.JobID,
+			&i.WorkspaceID,
+			&i.AgentID,
+			&i.ReconnectID,
 			&i.CreatedBy,
+			&i.Mode,
+			pq.Array(&i.Recipients),
+			&i.ExpiresAt,
 		); err != nil {
 			return nil, err
 		}
@@ -2403,107 +3775,65 @@ func (q *sqlQuerier) GetTemplateVersionsCreatedAfter(ctx context.Context, create
 	return items, nil
 }
 
-const insertTemplateVersion = `-- name: InsertTemplateVersion :one
+const insertTerminalShare = `-- name: InsertTerminalShare :one
 INSERT INTO
-	template_versions (
+	terminal_shares (
 		id,
-		template_id,
-		organization_id,
 		created_at,
-		updated_at,
-		"name",
-		readme,
-		job_id,
-		created_by
+		workspace_id,
+		agent_id,
+		reconnect_id,
+		created_by,
+		mode,
+		recipients,
+		expires_at
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by
+	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, workspace_id, agent_id, reconnect_id, created_by, mode, recipients, expires_at
 `
 
-type InsertTemplateVersionParams struct {
-	ID             uuid.UUID     `db:"id" json:"id"`
-	TemplateID     uuid.NullUUID `db:"template_id" json:"template_id"`
-	OrganizationID uuid.UUID     `db:"organization_id" json:"organization_id"`
-	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time     `db:"updated_at" json:"updated_at"`
-	Name           string        `db:"name" json:"name"`
-	Readme         string        `db:"readme" json:"readme"`
-	JobID          uuid.UUID     `db:"job_id" json:"job_id"`
-	CreatedBy      uuid.NullUUID `db:"created_by" json:"created_by"`
+type InsertTerminalShareParams struct {
+	ID          uuid.UUID         `db:"id" json:"id"`
+	CreatedAt   time.Time         `db:"created_at" json:"created_at"`
+	WorkspaceID uuid.UUID         `db:"workspace_id" json:"workspace_id"`
+	AgentID     uuid.UUID         `db:"agent_id" json:"agent_id"`
+	ReconnectID uuid.UUID         `db:"reconnect_id" json:"reconnect_id"`
+	CreatedBy   uuid.UUID         `db:"created_by" json:"created_by"`
+	Mode        TerminalShareMode `db:"mode" json:"mode"`
+	Recipients  []uuid.UUID       `db:"recipients" json:"recipients"`
+	ExpiresAt   time.Time         `db:"expires_at" json:"expires_at"`
 }
 
-func (q *sqlQuerier) InsertTemplateVersion(ctx context.Context, arg InsertTemplateVersionParams) (TemplateVersion, error) {
-	row := q.db.QueryRowContext(ctx, insertTemplateVersion,
+func (q *sqlQuerier) InsertTerminalShare(ctx context.Context, arg InsertTerminalShareParams) (TerminalShare, error) {
+	row := q.db.QueryRowContext(ctx, insertTerminalShare,
 		arg.ID,
-		arg.TemplateID,
-		arg.OrganizationID,
 		arg.CreatedAt,
-		arg.UpdatedAt,
-		arg.Name,
-		arg.Readme,
-		arg.JobID,
+		arg.WorkspaceID,
+		arg.AgentID,
+		arg.ReconnectID,
 		arg.CreatedBy,
+		arg.Mode,
+		pq.Array(arg.Recipients),
+		arg.ExpiresAt,
 	)
-	var i TemplateVersion
+	var i TerminalShare
 	err := row.Scan(
 		&i.ID,
-		&i.TemplateID,
-		&i.OrganizationID,
 		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		&i.Readme,
-		&i.JobID,
+		&i.WorkspaceID,
+		&i.AgentID,
+		&i.ReconnectID,
 		&i.CreatedBy,
+		&i.Mode,
+		pq.Array(&i.Recipients),
+		&i.ExpiresAt,
 	)
 	return i, err
 }
 
-const updateTemplateVersionByID = `-- name: UpdateTemplateVersionByID :exec
-UPDATE
-	template_versions
-SET
-	template_id = $2,
-	updated_at = $3
-WHERE
-	id = $1
-`
-
-type UpdateTemplateVersionByIDParams struct {
-	ID         uuid.UUID     `db:"id" json:"id"`
-	TemplateID uuid.NullUUID `db:"template_id" json:"template_id"`
-	UpdatedAt  time.Time     `db:"updated_at" json:"updated_at"`
-}
-
-func (q *sqlQuerier) UpdateTemplateVersionByID(ctx context.Context, arg UpdateTemplateVersionByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateTemplateVersionByID, arg.ID, arg.TemplateID, arg.UpdatedAt)
-	return err
-}
-
-const updateTemplateVersionDescriptionByJobID = `-- name: UpdateTemplateVersionDescriptionByJobID :exec
-UPDATE
-	template_versions
-SET
-	readme = $2,
-	updated_at = $3
-WHERE
-	job_id = $1
-`
-
-type UpdateTemplateVersionDescriptionByJobIDParams struct {
-	JobID     uuid.UUID `db:"job_id" json:"job_id"`
-	Readme    string    `db:"readme" json:"readme"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
-}
-
-func (q *sqlQuerier) UpdateTemplateVersionDescriptionByJobID(ctx context.Context, arg UpdateTemplateVersionDescriptionByJobIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateTemplateVersionDescriptionByJobID, arg.JobID, arg.Readme, arg.UpdatedAt)
-	return err
-}
-
 const getUserLinkByLinkedID = `-- name: GetUserLinkByLinkedID :one
 SELECT
-	user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+	user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry, oidc_provider
 FROM
 	user_links
 WHERE
@@ -2520,13 +3850,14 @@ func (q *sqlQuerier) GetUserLinkByLinkedID(ctx context.Context, linkedID string)
 		&i.OAuthAccessToken,
 		&i.OAuthRefreshToken,
 		&i.OAuthExpiry,
+		&i.OIDCProvider,
 	)
 	return i, err
 }
 
 const getUserLinkByUserIDLoginType = `-- name: GetUserLinkByUserIDLoginType :one
 SELECT
-	user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+	user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry, oidc_provider
 FROM
 	user_links
 WHERE
@@ -2548,6 +3879,7 @@ func (q *sqlQuerier) GetUserLinkByUserIDLoginType(ctx context.Context, arg GetUs
 		&i.OAuthAccessToken,
 		&i.OAuthRefreshToken,
 		&i.OAuthExpiry,
+		&i.OIDCProvider,
 	)
 	return i, err
 }
@@ -2560,10 +3892,11 @@ INSERT INTO
 		linked_id,
 		oauth_access_token,
 		oauth_refresh_token,
-		oauth_expiry
+		oauth_expiry,
+		oidc_provider
 	)
 VALUES
-	( $1, $2, $3, $4, $5, $6 ) RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+	( $1, $2, $3, $4, $5, $6, $7 ) RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry, oidc_provider
 `
 
 type InsertUserLinkParams struct {
@@ -2573,6 +3906,7 @@ type InsertUserLinkParams struct {
 	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
 	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
 	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
+	OIDCProvider      string    `db:"oidc_provider" json:"oidc_provider"`
 }
 
 func (q *sqlQuerier) InsertUserLink(ctx context.Context, arg InsertUserLinkParams) (UserLink, error) {
@@ -2583,6 +3917,7 @@ func (q *sqlQuerier) InsertUserLink(ctx context.Context, arg InsertUserLinkParam
 		arg.OAuthAccessToken,
 		arg.OAuthRefreshToken,
 		arg.OAuthExpiry,
+		arg.OIDCProvider,
 	)
 	var i UserLink
 	err := row.Scan(
@@ -2592,6 +3927,7 @@ func (q *sqlQuerier) InsertUserLink(ctx context.Context, arg InsertUserLinkParam
 		&i.OAuthAccessToken,
 		&i.OAuthRefreshToken,
 		&i.OAuthExpiry,
+		&i.OIDCProvider,
 	)
 	return i, err
 }
@@ -2604,7 +3940,7 @@ SET
 	oauth_refresh_token = $2,
 	oauth_expiry = $3
 WHERE
-	user_id = $4 AND login_type = $5 RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+	user_id = $4 AND login_type = $5 RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry, oidc_provider
 `
 
 type UpdateUserLinkParams struct {
@@ -2631,6 +3967,7 @@ func (q *sqlQuerier) UpdateUserLink(ctx context.Context, arg UpdateUserLinkParam
 		&i.OAuthAccessToken,
 		&i.OAuthRefreshToken,
 		&i.OAuthExpiry,
+		&i.OIDCProvider,
 	)
 	return i, err
 }
@@ -2639,19 +3976,26 @@ const updateUserLinkedID = `-- name: UpdateUserLinkedID :one
 UPDATE
 	user_links
 SET
-	linked_id = $1
+	linked_id = $1,
+	oidc_provider = $2
 WHERE
-	user_id = $2 AND login_type = $3 RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+	user_id = $3 AND login_type = $4 RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry, oidc_provider
 `
 
 type UpdateUserLinkedIDParams struct {
-	LinkedID  string    `db:"linked_id" json:"linked_id"`
-	UserID    uuid.UUID `db:"user_id" json:"user_id"`
-	LoginType LoginType `db:"login_type" json:"login_type"`
+	LinkedID     string    `db:"linked_id" json:"linked_id"`
+	OIDCProvider string    `db:"oidc_provider" json:"oidc_provider"`
+	UserID       uuid.UUID `db:"user_id" json:"user_id"`
+	LoginType    LoginType `db:"login_type" json:"login_type"`
 }
 
 func (q *sqlQuerier) UpdateUserLinkedID(ctx context.Context, arg UpdateUserLinkedIDParams) (UserLink, error) {
-	row := q.db.QueryRowContext(ctx, updateUserLinkedID, arg.LinkedID, arg.UserID, arg.LoginType)
+	row := q.db.QueryRowContext(ctx, updateUserLinkedID,
+		arg.LinkedID,
+		arg.OIDCProvider,
+		arg.UserID,
+		arg.LoginType,
+	)
 	var i UserLink
 	err := row.Scan(
 		&i.UserID,
@@ -2660,6 +4004,7 @@ func (q *sqlQuerier) UpdateUserLinkedID(ctx context.Context, arg UpdateUserLinke
 		&i.OAuthAccessToken,
 		&i.OAuthRefreshToken,
 		&i.OAuthExpiry,
+		&i.OIDCProvider,
 	)
 	return i, err
 }
@@ -3107,7 +4452,7 @@ func (q *sqlQuerier) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusP
 
 const getWorkspaceAgentByAuthToken = `-- name: GetWorkspaceAgentByAuthToken :one
 SELECT
-	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, wireguard_node_ipv6, wireguard_node_public_key, wireguard_disco_public_key
+	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, wireguard_node_ipv6, wireguard_node_public_key, wireguard_disco_public_key, version, health_checks
 FROM
 	workspace_agents
 WHERE
@@ -3140,13 +4485,15 @@ func (q *sqlQuerier) GetWorkspaceAgentByAuthToken(ctx context.Context, authToken
 		&i.WireguardNodeIPv6,
 		&i.WireguardNodePublicKey,
 		&i.WireguardDiscoPublicKey,
+		&i.Version,
+		&i.HealthChecks,
 	)
 	return i, err
 }
 
 const getWorkspaceAgentByID = `-- name: GetWorkspaceAgentByID :one
 SELECT
-	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, wireguard_node_ipv6, wireguard_node_public_key, wireguard_disco_public_key
+	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, wireguard_node_ipv6, wireguard_node_public_key, wireguard_disco_public_key, version, health_checks
 FROM
 	workspace_agents
 WHERE
@@ -3177,13 +4524,15 @@ func (q *sqlQuerier) GetWorkspaceAgentByID(ctx context.Context, id uuid.UUID) (W
 		&i.WireguardNodeIPv6,
 		&i.WireguardNodePublicKey,
 		&i.WireguardDiscoPublicKey,
+		&i.Version,
+		&i.HealthChecks,
 	)
 	return i, err
 }
 
 const getWorkspaceAgentByInstanceID = `-- name: GetWorkspaceAgentByInstanceID :one
 SELECT
-	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, wireguard_node_ipv6, wireguard_node_public_key, wireguard_disco_public_key
+	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, wireguard_node_ipv6, wireguard_node_public_key, wireguard_disco_public_key, version, health_checks
 FROM
 	workspace_agents
 WHERE
@@ -3216,13 +4565,15 @@ func (q *sqlQuerier) GetWorkspaceAgentByInstanceID(ctx context.Context, authInst
 		&i.WireguardNodeIPv6,
 		&i.WireguardNodePublicKey,
 		&i.WireguardDiscoPublicKey,
+		&i.Version,
+		&i.HealthChecks,
 	)
 	return i, err
 }
 
 const getWorkspaceAgentsByResourceIDs = `-- name: GetWorkspaceAgentsByResourceIDs :many
 SELECT
-	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, wireguard_node_ipv6, wireguard_node_public_key, wireguard_disco_public_key
+	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, wireguard_node_ipv6, wireguard_node_public_key, wireguard_disco_public_key, version, health_checks
 FROM
 	workspace_agents
 WHERE
@@ -3259,6 +4610,8 @@ func (q *sqlQuerier) GetWorkspaceAgentsByResourceIDs(ctx context.Context, ids []
 			&i.WireguardNodeIPv6,
 			&i.WireguardNodePublicKey,
 			&i.WireguardDiscoPublicKey,
+			&i.Version,
+			&i.HealthChecks,
 		); err != nil {
 			return nil, err
 		}
@@ -3274,7 +4627,7 @@ func (q *sqlQuerier) GetWorkspaceAgentsByResourceIDs(ctx context.Context, ids []
 }
 
 const getWorkspaceAgentsCreatedAfter = `-- name: GetWorkspaceAgentsCreatedAfter :many
-SELECT id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, wireguard_node_ipv6, wireguard_node_public_key, wireguard_disco_public_key FROM workspace_agents WHERE created_at > $1
+SELECT id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, wireguard_node_ipv6, wireguard_node_public_key, wireguard_disco_public_key, version, health_checks FROM workspace_agents WHERE created_at > $1
 `
 
 func (q *sqlQuerier) GetWorkspaceAgentsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceAgent, error) {
@@ -3307,6 +4660,8 @@ func (q *sqlQuerier) GetWorkspaceAgentsCreatedAfter(ctx context.Context, created
 			&i.WireguardNodeIPv6,
 			&i.WireguardNodePublicKey,
 			&i.WireguardDiscoPublicKey,
+			&i.Version,
+			&i.HealthChecks,
 		); err != nil {
 			return nil, err
 		}
@@ -3343,7 +4698,7 @@ INSERT INTO
 		wireguard_disco_public_key
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17) RETURNING id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, wireguard_node_ipv6, wireguard_node_public_key, wireguard_disco_public_key
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17) RETURNING id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, wireguard_node_ipv6, wireguard_node_public_key, wireguard_disco_public_key, version, health_checks
 `
 
 type InsertWorkspaceAgentParams struct {
@@ -3408,6 +4763,8 @@ func (q *sqlQuerier) InsertWorkspaceAgent(ctx context.Context, arg InsertWorkspa
 		&i.WireguardNodeIPv6,
 		&i.WireguardNodePublicKey,
 		&i.WireguardDiscoPublicKey,
+		&i.Version,
+		&i.HealthChecks,
 	)
 	return i, err
 }
@@ -3471,6 +4828,44 @@ func (q *sqlQuerier) UpdateWorkspaceAgentKeysByID(ctx context.Context, arg Updat
 	return err
 }
 
+const updateWorkspaceAgentVersionByID = `-- name: UpdateWorkspaceAgentVersionByID :exec
+UPDATE
+	workspace_agents
+SET
+	version = $2
+WHERE
+	id = $1
+`
+
+type UpdateWorkspaceAgentVersionByIDParams struct {
+	ID      uuid.UUID `db:"id" json:"id"`
+	Version string    `db:"version" json:"version"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceAgentVersionByID(ctx context.Context, arg UpdateWorkspaceAgentVersionByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceAgentVersionByID, arg.ID, arg.Version)
+	return err
+}
+
+const updateWorkspaceAgentHealthChecksByID = `-- name: UpdateWorkspaceAgentHealthChecksByID :exec
+UPDATE
+	workspace_agents
+SET
+	health_checks = $2
+WHERE
+	id = $1
+`
+
+type UpdateWorkspaceAgentHealthChecksByIDParams struct {
+	ID           uuid.UUID             `db:"id" json:"id"`
+	HealthChecks pqtype.NullRawMessage `db:"health_checks" json:"health_checks"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceAgentHealthChecksByID(ctx context.Context, arg UpdateWorkspaceAgentHealthChecksByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceAgentHealthChecksByID, arg.ID, arg.HealthChecks)
+	return err
+}
+
 const getWorkspaceAppByAgentIDAndName = `-- name: GetWorkspaceAppByAgentIDAndName :one
 SELECT id, created_at, agent_id, name, icon, command, url, relative_path FROM workspace_apps WHERE agent_id = $1 AND name = $2
 `
@@ -4325,6 +5720,45 @@ func (q *sqlQuerier) GetWorkspaceResourcesByJobID(ctx context.Context, jobID uui
 	return items, nil
 }
 
+const getWorkspaceResourcesByJobIDs = `-- name: GetWorkspaceResourcesByJobIDs :many
+SELECT
+	id, created_at, job_id, transition, type, name
+FROM
+	workspace_resources
+WHERE
+	job_id = ANY($1 :: uuid [ ])
+`
+
+func (q *sqlQuerier) GetWorkspaceResourcesByJobIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceResource, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceResourcesByJobIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceResource
+	for rows.Next() {
+		var i WorkspaceResource
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.JobID,
+			&i.Transition,
+			&i.Type,
+			&i.Name,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getWorkspaceResourcesCreatedAfter = `-- name: GetWorkspaceResourcesCreatedAfter :many
 SELECT id, created_at, job_id, transition, type, name FROM workspace_resources WHERE created_at > $1
 `
@@ -4427,9 +5861,139 @@ func (q *sqlQuerier) InsertWorkspaceResourceMetadata(ctx context.Context, arg In
 	return i, err
 }
 
+const claimPrebuiltWorkspace = `-- name: ClaimPrebuiltWorkspace :one
+UPDATE
+	workspaces
+SET
+	owner_id = $2,
+	name = $3,
+	autostart_schedule = $4,
+	ttl = $5,
+	updated_at = $6,
+	last_used_at = $6,
+	prebuild = false,
+	preset = $7
+WHERE
+	id = $1
+AND
+	prebuild = true
+RETURNING
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, dormant_at, prebuild, preset
+`
+
+type ClaimPrebuiltWorkspaceParams struct {
+	ID                uuid.UUID      `db:"id" json:"id"`
+	OwnerID           uuid.UUID      `db:"owner_id" json:"owner_id"`
+	Name              string         `db:"name" json:"name"`
+	AutostartSchedule sql.NullString `db:"autostart_schedule" json:"autostart_schedule"`
+	Ttl               sql.NullInt64  `db:"ttl" json:"ttl"`
+	UpdatedAt         time.Time      `db:"updated_at" json:"updated_at"`
+	Preset            string         `db:"preset" json:"preset"`
+}
+
+// Transfers a prebuilt workspace to its new owner. No rows are returned if
+// the workspace has already been claimed.
+func (q *sqlQuerier) ClaimPrebuiltWorkspace(ctx context.Context, arg ClaimPrebuiltWorkspaceParams) (Workspace, error) {
+	row := q.db.QueryRowContext(ctx, claimPrebuiltWorkspace,
+		arg.ID,
+		arg.OwnerID,
+		arg.Name,
+		arg.AutostartSchedule,
+		arg.Ttl,
+		arg.UpdatedAt,
+		arg.Preset,
+	)
+	var i Workspace
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OwnerID,
+		&i.OrganizationID,
+		&i.TemplateID,
+		&i.Deleted,
+		&i.Name,
+		&i.AutostartSchedule,
+		&i.Ttl,
+		&i.LastUsedAt,
+		&i.DormantAt,
+		&i.Prebuild,
+		&i.Preset,
+	)
+	return i, err
+}
+
+const getPrebuiltWorkspacesByTemplateID = `-- name: GetPrebuiltWorkspacesByTemplateID :many
+SELECT
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, dormant_at, prebuild, preset
+FROM
+	workspaces
+WHERE
+	template_id = $1
+AND
+	prebuild = true
+AND
+	deleted = false
+ORDER BY
+	created_at ASC
+`
+
+func (q *sqlQuerier) GetPrebuiltWorkspacesByTemplateID(ctx context.Context, templateID uuid.UUID) ([]Workspace, error) {
+	rows, err := q.db.QueryContext(ctx, getPrebuiltWorkspacesByTemplateID, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Workspace
+	for rows.Next() {
+		var i Workspace
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OwnerID,
+			&i.OrganizationID,
+			&i.TemplateID,
+			&i.Deleted,
+			&i.Name,
+			&i.AutostartSchedule,
+			&i.Ttl,
+			&i.LastUsedAt,
+			&i.DormantAt,
+			&i.Prebuild,
+			&i.Preset,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteDeletedWorkspacesByOrganizationID = `-- name: DeleteDeletedWorkspacesByOrganizationID :exec
+DELETE FROM
+	workspaces
+WHERE
+	organization_id = $1
+	AND deleted = true
+`
+
+// Soft-deleted workspaces restrict the deletion of their organization and
+// template, so they're removed for good before the organization is deleted.
+func (q *sqlQuerier) DeleteDeletedWorkspacesByOrganizationID(ctx context.Context, organizationID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteDeletedWorkspacesByOrganizationID, organizationID)
+	return err
+}
+
 const getWorkspaceByID = `-- name: GetWorkspaceByID :one
 SELECT
-	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, dormant_at, prebuild, preset
 FROM
 	workspaces
 WHERE
@@ -4452,13 +6016,17 @@ func (q *sqlQuerier) GetWorkspaceByID(ctx context.Context, id uuid.UUID) (Worksp
 		&i.Name,
 		&i.AutostartSchedule,
 		&i.Ttl,
+		&i.LastUsedAt,
+		&i.DormantAt,
+		&i.Prebuild,
+		&i.Preset,
 	)
 	return i, err
 }
 
 const getWorkspaceByOwnerIDAndName = `-- name: GetWorkspaceByOwnerIDAndName :one
 SELECT
-	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, dormant_at, prebuild, preset
 FROM
 	workspaces
 WHERE
@@ -4488,6 +6056,10 @@ func (q *sqlQuerier) GetWorkspaceByOwnerIDAndName(ctx context.Context, arg GetWo
 		&i.Name,
 		&i.AutostartSchedule,
 		&i.Ttl,
+		&i.LastUsedAt,
+		&i.DormantAt,
+		&i.Prebuild,
+		&i.Preset,
 	)
 	return i, err
 }
@@ -4536,7 +6108,7 @@ func (q *sqlQuerier) GetWorkspaceOwnerCountsByTemplateIDs(ctx context.Context, i
 
 const getWorkspaces = `-- name: GetWorkspaces :many
 SELECT
-    id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl
+    id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, dormant_at, prebuild, preset
 FROM
     workspaces
 WHERE
@@ -4574,15 +6146,29 @@ WHERE
 		    name ILIKE '%' || $6 || '%'
 		ELSE true
 	END
+	-- Optionally hide dormant workspaces
+	AND CASE
+		WHEN $7 :: boolean THEN
+			dormant_at IS NULL
+		ELSE true
+	END
+	-- Optionally hide prebuilt workspaces that have not been claimed
+	AND CASE
+		WHEN $8 :: boolean THEN
+			prebuild = false
+		ELSE true
+	END
 `
 
 type GetWorkspacesParams struct {
-	Deleted       bool        `db:"deleted" json:"deleted"`
-	OwnerID       uuid.UUID   `db:"owner_id" json:"owner_id"`
-	OwnerUsername string      `db:"owner_username" json:"owner_username"`
-	TemplateName  string      `db:"template_name" json:"template_name"`
-	TemplateIds   []uuid.UUID `db:"template_ids" json:"template_ids"`
-	Name          string      `db:"name" json:"name"`
+	Deleted          bool        `db:"deleted" json:"deleted"`
+	OwnerID          uuid.UUID   `db:"owner_id" json:"owner_id"`
+	OwnerUsername    string      `db:"owner_username" json:"owner_username"`
+	TemplateName     string      `db:"template_name" json:"template_name"`
+	TemplateIds      []uuid.UUID `db:"template_ids" json:"template_ids"`
+	Name             string      `db:"name" json:"name"`
+	ExcludeDormant   bool        `db:"exclude_dormant" json:"exclude_dormant"`
+	ExcludePrebuilds bool        `db:"exclude_prebuilds" json:"exclude_prebuilds"`
 }
 
 func (q *sqlQuerier) GetWorkspaces(ctx context.Context, arg GetWorkspacesParams) ([]Workspace, error) {
@@ -4593,6 +6179,8 @@ func (q *sqlQuerier) GetWorkspaces(ctx context.Context, arg GetWorkspacesParams)
 		arg.TemplateName,
 		pq.Array(arg.TemplateIds),
 		arg.Name,
+		arg.ExcludeDormant,
+		arg.ExcludePrebuilds,
 	)
 	if err != nil {
 		return nil, err
@@ -4612,6 +6200,10 @@ func (q *sqlQuerier) GetWorkspaces(ctx context.Context, arg GetWorkspacesParams)
 			&i.Name,
 			&i.AutostartSchedule,
 			&i.Ttl,
+			&i.LastUsedAt,
+			&i.DormantAt,
+			&i.Prebuild,
+			&i.Preset,
 		); err != nil {
 			return nil, err
 		}
@@ -4628,7 +6220,7 @@ func (q *sqlQuerier) GetWorkspaces(ctx context.Context, arg GetWorkspacesParams)
 
 const getWorkspacesAutostart = `-- name: GetWorkspacesAutostart :many
 SELECT
-	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, dormant_at, prebuild, preset
 FROM
 	workspaces
 WHERE
@@ -4661,6 +6253,123 @@ func (q *sqlQuerier) GetWorkspacesAutostart(ctx context.Context) ([]Workspace, e
 			&i.Name,
 			&i.AutostartSchedule,
 			&i.Ttl,
+			&i.LastUsedAt,
+			&i.DormantAt,
+			&i.Prebuild,
+			&i.Preset,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWorkspacesDormancy = `-- name: GetWorkspacesDormancy :many
+SELECT
+	workspaces.id, workspaces.created_at, workspaces.updated_at, workspaces.owner_id, workspaces.organization_id, workspaces.template_id, workspaces.deleted, workspaces.name, workspaces.autostart_schedule, workspaces.ttl, workspaces.last_used_at, workspaces.dormant_at, workspaces.prebuild, workspaces.preset
+FROM
+	workspaces
+INNER JOIN
+	templates ON workspaces.template_id = templates.id
+WHERE
+	workspaces.deleted = false
+AND
+(
+	(workspaces.dormant_at IS NULL AND templates.inactivity_ttl > 0)
+	OR
+	(workspaces.dormant_at IS NOT NULL AND templates.dormant_autodelete_ttl > 0)
+)
+`
+
+// Returns workspaces whose template has a dormancy policy that still applies
+// to them: active workspaces may become dormant, and dormant workspaces may
+// be deleted.
+func (q *sqlQuerier) GetWorkspacesDormancy(ctx context.Context) ([]Workspace, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspacesDormancy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Workspace
+	for rows.Next() {
+		var i Workspace
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OwnerID,
+			&i.OrganizationID,
+			&i.TemplateID,
+			&i.Deleted,
+			&i.Name,
+			&i.AutostartSchedule,
+			&i.Ttl,
+			&i.LastUsedAt,
+			&i.DormantAt,
+			&i.Prebuild,
+			&i.Preset,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWorkspacesMaintenance = `-- name: GetWorkspacesMaintenance :many
+SELECT
+	workspaces.id, workspaces.created_at, workspaces.updated_at, workspaces.owner_id, workspaces.organization_id, workspaces.template_id, workspaces.deleted, workspaces.name, workspaces.autostart_schedule, workspaces.ttl, workspaces.last_used_at, workspaces.dormant_at, workspaces.prebuild, workspaces.preset
+FROM
+	workspaces
+INNER JOIN
+	templates ON workspaces.template_id = templates.id
+WHERE
+	workspaces.deleted = false
+AND
+	templates.maintenance_window <> ''
+AND
+	templates.maintenance_restart_outdated = true
+`
+
+// Returns workspaces whose template restarts outdated workspaces during its
+// maintenance window.
+func (q *sqlQuerier) GetWorkspacesMaintenance(ctx context.Context) ([]Workspace, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspacesMaintenance)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Workspace
+	for rows.Next() {
+		var i Workspace
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OwnerID,
+			&i.OrganizationID,
+			&i.TemplateID,
+			&i.Deleted,
+			&i.Name,
+			&i.AutostartSchedule,
+			&i.Ttl,
+			&i.LastUsedAt,
+			&i.DormantAt,
+			&i.Prebuild,
+			&i.Preset,
 		); err != nil {
 			return nil, err
 		}
@@ -4686,10 +6395,12 @@ INSERT INTO
 		template_id,
 		name,
 		autostart_schedule,
-		ttl
+		ttl,
+		prebuild,
+		preset
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, dormant_at, prebuild, preset
 `
 
 type InsertWorkspaceParams struct {
@@ -4702,6 +6413,8 @@ type InsertWorkspaceParams struct {
 	Name              string         `db:"name" json:"name"`
 	AutostartSchedule sql.NullString `db:"autostart_schedule" json:"autostart_schedule"`
 	Ttl               sql.NullInt64  `db:"ttl" json:"ttl"`
+	Prebuild          bool           `db:"prebuild" json:"prebuild"`
+	Preset            string         `db:"preset" json:"preset"`
 }
 
 func (q *sqlQuerier) InsertWorkspace(ctx context.Context, arg InsertWorkspaceParams) (Workspace, error) {
@@ -4715,6 +6428,8 @@ func (q *sqlQuerier) InsertWorkspace(ctx context.Context, arg InsertWorkspacePar
 		arg.Name,
 		arg.AutostartSchedule,
 		arg.Ttl,
+		arg.Prebuild,
+		arg.Preset,
 	)
 	var i Workspace
 	err := row.Scan(
@@ -4728,6 +6443,10 @@ func (q *sqlQuerier) InsertWorkspace(ctx context.Context, arg InsertWorkspacePar
 		&i.Name,
 		&i.AutostartSchedule,
 		&i.Ttl,
+		&i.LastUsedAt,
+		&i.DormantAt,
+		&i.Prebuild,
+		&i.Preset,
 	)
 	return i, err
 }
@@ -4740,7 +6459,7 @@ SET
 WHERE
 	id = $1
 	AND deleted = false
-RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl
+RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, dormant_at, prebuild, preset
 `
 
 type UpdateWorkspaceParams struct {
@@ -4762,6 +6481,10 @@ func (q *sqlQuerier) UpdateWorkspace(ctx context.Context, arg UpdateWorkspacePar
 		&i.Name,
 		&i.AutostartSchedule,
 		&i.Ttl,
+		&i.LastUsedAt,
+		&i.DormantAt,
+		&i.Prebuild,
+		&i.Preset,
 	)
 	return i, err
 }
@@ -4804,6 +6527,44 @@ func (q *sqlQuerier) UpdateWorkspaceDeletedByID(ctx context.Context, arg UpdateW
 	return err
 }
 
+const updateWorkspaceDormantAt = `-- name: UpdateWorkspaceDormantAt :exec
+UPDATE
+	workspaces
+SET
+	dormant_at = $2
+WHERE
+	id = $1
+`
+
+type UpdateWorkspaceDormantAtParams struct {
+	ID        uuid.UUID    `db:"id" json:"id"`
+	DormantAt sql.NullTime `db:"dormant_at" json:"dormant_at"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceDormantAt(ctx context.Context, arg UpdateWorkspaceDormantAtParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceDormantAt, arg.ID, arg.DormantAt)
+	return err
+}
+
+const updateWorkspaceLastUsedAt = `-- name: UpdateWorkspaceLastUsedAt :exec
+UPDATE
+	workspaces
+SET
+	last_used_at = $2
+WHERE
+	id = $1
+`
+
+type UpdateWorkspaceLastUsedAtParams struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	LastUsedAt time.Time `db:"last_used_at" json:"last_used_at"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceLastUsedAt(ctx context.Context, arg UpdateWorkspaceLastUsedAtParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceLastUsedAt, arg.ID, arg.LastUsedAt)
+	return err
+}
+
 const updateWorkspaceTTL = `-- name: UpdateWorkspaceTTL :exec
 UPDATE
 	workspaces
@@ -4822,3 +6583,232 @@ func (q *sqlQuerier) UpdateWorkspaceTTL(ctx context.Context, arg UpdateWorkspace
 	_, err := q.db.ExecContext(ctx, updateWorkspaceTTL, arg.ID, arg.Ttl)
 	return err
 }
+
+const updateWorkspacesOrganizationByTemplateID = `-- name: UpdateWorkspacesOrganizationByTemplateID :exec
+UPDATE
+	workspaces
+SET
+	organization_id = $2,
+	updated_at = $3
+WHERE
+	template_id = $1
+`
+
+type UpdateWorkspacesOrganizationByTemplateIDParams struct {
+	TemplateID     uuid.UUID `db:"template_id" json:"template_id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateWorkspacesOrganizationByTemplateID(ctx context.Context, arg UpdateWorkspacesOrganizationByTemplateIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspacesOrganizationByTemplateID, arg.TemplateID, arg.OrganizationID, arg.UpdatedAt)
+	return err
+}
+
+const getWorkspaceSessionRecordingByID = `-- name: GetWorkspaceSessionRecordingByID :one
+SELECT
+	id, created_at, workspace_id, agent_id, user_id, type, started_at, ended_at, data
+FROM
+	workspace_session_recordings
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetWorkspaceSessionRecordingByID(ctx context.Context, id uuid.UUID) (WorkspaceSessionRecording, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceSessionRecordingByID, id)
+	var i WorkspaceSessionRecording
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.WorkspaceID,
+		&i.AgentID,
+		&i.UserID,
+		&i.Type,
+		&i.StartedAt,
+		&i.EndedAt,
+		&i.Data,
+	)
+	return i, err
+}
+
+const getWorkspaceSessionRecordingsByWorkspaceID = `-- name: GetWorkspaceSessionRecordingsByWorkspaceID :many
+SELECT
+	id, created_at, workspace_id, agent_id, user_id, type, started_at, ended_at, octet_length(data)::bigint AS size
+FROM
+	workspace_session_recordings
+WHERE
+	workspace_id = $1
+ORDER BY
+	started_at DESC
+`
+
+type GetWorkspaceSessionRecordingsByWorkspaceIDRow struct {
+	ID          uuid.UUID            `db:"id" json:"id"`
+	CreatedAt   time.Time            `db:"created_at" json:"created_at"`
+	WorkspaceID uuid.UUID            `db:"workspace_id" json:"workspace_id"`
+	AgentID     uuid.UUID            `db:"agent_id" json:"agent_id"`
+	UserID      uuid.UUID            `db:"user_id" json:"user_id"`
+	Type        SessionRecordingType `db:"type" json:"type"`
+	StartedAt   time.Time            `db:"started_at" json:"started_at"`
+	EndedAt     time.Time            `db:"ended_at" json:"ended_at"`
+	Size        int64                `db:"size" json:"size"`
+}
+
+// The recording data is left out, since it can be large.
+func (q *sqlQuerier) GetWorkspaceSessionRecordingsByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) ([]GetWorkspaceSessionRecordingsByWorkspaceIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceSessionRecordingsByWorkspaceID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWorkspaceSessionRecordingsByWorkspaceIDRow
+	for rows.Next() {
+		var i GetWorkspaceSessionRecordingsByWorkspaceIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.WorkspaceID,
+			&i.AgentID,
+			&i.UserID,
+			&i.Type,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.Size,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertWorkspaceSessionRecording = `-- name: InsertWorkspaceSessionRecording :one
+INSERT INTO
+	workspace_session_recordings (
+		id,
+		created_at,
+		workspace_id,
+		agent_id,
+		user_id,
+		type,
+		started_at,
+		ended_at,
+		data
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, workspace_id, agent_id, user_id, type, started_at, ended_at, data
+`
+
+type InsertWorkspaceSessionRecordingParams struct {
+	ID          uuid.UUID            `db:"id" json:"id"`
+	CreatedAt   time.Time            `db:"created_at" json:"created_at"`
+	WorkspaceID uuid.UUID            `db:"workspace_id" json:"workspace_id"`
+	AgentID     uuid.UUID            `db:"agent_id" json:"agent_id"`
+	UserID      uuid.UUID            `db:"user_id" json:"user_id"`
+	Type        SessionRecordingType `db:"type" json:"type"`
+	StartedAt   time.Time            `db:"started_at" json:"started_at"`
+	EndedAt     time.Time            `db:"ended_at" json:"ended_at"`
+	Data        []byte               `db:"data" json:"data"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceSessionRecording(ctx context.Context, arg InsertWorkspaceSessionRecordingParams) (WorkspaceSessionRecording, error) {
+	row := q.db.QueryRowContext(ctx, insertWorkspaceSessionRecording,
+		arg.ID,
+		arg.CreatedAt,
+		arg.WorkspaceID,
+		arg.AgentID,
+		arg.UserID,
+		arg.Type,
+		arg.StartedAt,
+		arg.EndedAt,
+		arg.Data,
+	)
+	var i WorkspaceSessionRecording
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.WorkspaceID,
+		&i.AgentID,
+		&i.UserID,
+		&i.Type,
+		&i.StartedAt,
+		&i.EndedAt,
+		&i.Data,
+	)
+	return i, err
+}
+
+const deleteWorkspaceSessionRecordingTokenByID = `-- name: DeleteWorkspaceSessionRecordingTokenByID :exec
+DELETE FROM
+	workspace_session_recording_tokens
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) DeleteWorkspaceSessionRecordingTokenByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteWorkspaceSessionRecordingTokenByID, id)
+	return err
+}
+
+const getWorkspaceSessionRecordingTokenByID = `-- name: GetWorkspaceSessionRecordingTokenByID :one
+SELECT
+	id, created_at, agent_id, user_id
+FROM
+	workspace_session_recording_tokens
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetWorkspaceSessionRecordingTokenByID(ctx context.Context, id uuid.UUID) (WorkspaceSessionRecordingToken, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceSessionRecordingTokenByID, id)
+	var i WorkspaceSessionRecordingToken
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.AgentID,
+		&i.UserID,
+	)
+	return i, err
+}
+
+const insertWorkspaceSessionRecordingToken = `-- name: InsertWorkspaceSessionRecordingToken :one
+INSERT INTO
+	workspace_session_recording_tokens (
+		id,
+		created_at,
+		agent_id,
+		user_id
+	)
+VALUES
+	($1, $2, $3, $4) RETURNING id, created_at, agent_id, user_id
+`
+
+type InsertWorkspaceSessionRecordingTokenParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	AgentID   uuid.UUID `db:"agent_id" json:"agent_id"`
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceSessionRecordingToken(ctx context.Context, arg InsertWorkspaceSessionRecordingTokenParams) (WorkspaceSessionRecordingToken, error) {
+	row := q.db.QueryRowContext(ctx, insertWorkspaceSessionRecordingToken,
+		arg.ID,
+		arg.CreatedAt,
+		arg.AgentID,
+		arg.UserID,
+	)
+	var i WorkspaceSessionRecordingToken
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.AgentID,
+		&i.UserID,
+	)
+	return i, err
+}
@@ -10,6 +10,75 @@ LIMIT
 
 -- name: InsertFile :one
 INSERT INTO
-	files (hash, created_at, created_by, mimetype, "data")
+	files (hash, created_at, created_by, mimetype)
 VALUES
-	($1, $2, $3, $4, $5) RETURNING *;
+	($1, $2, $3, $4) RETURNING *;
+
+-- name: UpdateFileCreatedAtByHash :one
+-- Refreshes a file that's uploaded again, so it isn't collected as garbage
+-- before it's used.
+UPDATE
+	files
+SET
+	created_at = $2
+WHERE
+	hash = $1 RETURNING *;
+
+-- name: DeleteUnreferencedFileByHash :one
+-- Deletes a file if it was created before the given time and no provisioner
+-- job uses it as its source.
+DELETE FROM
+	files
+WHERE
+	hash = $1
+	AND created_at < $2
+	AND NOT EXISTS (
+		SELECT
+			1
+		FROM
+			provisioner_jobs
+		WHERE
+			provisioner_jobs.storage_method = 'file'
+			AND provisioner_jobs.storage_source = files.hash
+	) RETURNING *;
+
+-- name: GetUnreferencedFiles :many
+-- Returns files created before the given time that no provisioner job uses
+-- as its source.
+SELECT
+	*
+FROM
+	files
+WHERE
+	created_at < $1
+	AND NOT EXISTS (
+		SELECT
+			1
+		FROM
+			provisioner_jobs
+		WHERE
+			provisioner_jobs.storage_method = 'file'
+			AND provisioner_jobs.storage_source = files.hash
+	);
+
+-- name: GetFileBlobByKey :one
+SELECT
+	*
+FROM
+	file_blobs
+WHERE
+	key = $1
+LIMIT
+	1;
+
+-- name: InsertFileBlob :exec
+INSERT INTO
+	file_blobs (key, data)
+VALUES
+	($1, $2) ON CONFLICT (key) DO NOTHING;
+
+-- name: DeleteFileBlobByKey :exec
+DELETE FROM
+	file_blobs
+WHERE
+	key = $1;
@@ -9,6 +9,16 @@ WHERE
 LIMIT
 	1;
 
+-- name: GetOrganizationMembersByOrganizationID :many
+SELECT
+	*
+FROM
+	organization_members
+WHERE
+	organization_id = $1
+ORDER BY
+	created_at ASC;
+
 -- name: InsertOrganizationMember :one
 INSERT INTO
 	organization_members (
@@ -50,3 +60,10 @@ WHERE
 	user_id = @user_id
 	AND organization_id = @org_id
 RETURNING *;
+
+-- name: DeleteOrganizationMember :exec
+DELETE FROM
+	organization_members
+WHERE
+	organization_id = $1
+	AND user_id = $2;
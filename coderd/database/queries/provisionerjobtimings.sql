@@ -0,0 +1,21 @@
+-- name: GetProvisionerJobTimingsByJobID :many
+SELECT
+	*
+FROM
+	provisioner_job_timings
+WHERE
+	job_id = @job_id
+ORDER BY
+	started_at, id;
+
+-- name: InsertProvisionerJobTimings :many
+INSERT INTO
+	provisioner_job_timings
+SELECT
+	unnest(@id :: uuid [ ]) AS id,
+	@job_id :: uuid AS job_id,
+	unnest(@stage :: text [ ]) AS stage,
+	unnest(@resource :: text [ ]) AS resource,
+	unnest(@action :: text [ ]) AS action,
+	unnest(@started_at :: timestamptz [ ]) AS started_at,
+	unnest(@ended_at :: timestamptz [ ]) AS ended_at RETURNING *;
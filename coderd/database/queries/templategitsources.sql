@@ -0,0 +1,81 @@
+-- name: AcquireTemplateGitSourceSync :one
+-- Claims the sync of a template, so only one runs at a time across replicas.
+-- A sync that started before @stale_before is assumed to have crashed.
+UPDATE
+	template_git_sources
+SET
+	sync_started_at = @sync_started_at :: timestamptz
+WHERE
+	template_id = @template_id
+	AND (sync_started_at IS NULL OR sync_started_at < @stale_before :: timestamptz)
+RETURNING *;
+
+-- name: DeleteTemplateGitSourceByTemplateID :exec
+DELETE FROM
+	template_git_sources
+WHERE
+	template_id = $1;
+
+-- name: GetTemplateGitSourceByTemplateID :one
+SELECT
+	*
+FROM
+	template_git_sources
+WHERE
+	template_id = $1;
+
+-- name: GetTemplateGitSources :many
+SELECT
+	template_git_sources.*
+FROM
+	template_git_sources
+INNER JOIN
+	templates ON templates.id = template_git_sources.template_id
+WHERE
+	templates.deleted = false
+ORDER BY
+	template_git_sources.created_at ASC;
+
+-- name: UpsertTemplateGitSource :one
+-- Changing where the template is sourced from resets the sync state, so the
+-- next sync creates a template version.
+INSERT INTO
+	template_git_sources (
+		template_id,
+		created_at,
+		updated_at,
+		created_by,
+		repository_url,
+		ref,
+		subdirectory,
+		auto_promote
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (template_id) DO UPDATE
+SET
+	updated_at = $3,
+	created_by = $4,
+	repository_url = $5,
+	ref = $6,
+	subdirectory = $7,
+	auto_promote = $8,
+	commit_sha = CASE
+		WHEN (template_git_sources.repository_url, template_git_sources.ref, template_git_sources.subdirectory) = ($5, $6, $7)
+			THEN template_git_sources.commit_sha
+		ELSE ''
+	END
+RETURNING *;
+
+-- name: UpdateTemplateGitSourceSyncByTemplateID :exec
+UPDATE
+	template_git_sources
+SET
+	commit_sha = $2,
+	pending_version_id = $3,
+	pending_dry_run_job_id = $4,
+	synced_at = $5,
+	sync_error = $6,
+	sync_started_at = NULL
+WHERE
+	template_id = $1;
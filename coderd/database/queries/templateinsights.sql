@@ -0,0 +1,51 @@
+-- name: GetTemplateInsights :many
+SELECT
+	*
+FROM
+	template_insights
+WHERE
+	template_id = @template_id
+	AND date >= @start_date :: date
+	AND date < @end_date :: date
+ORDER BY
+	date ASC;
+
+-- name: GetTemplateUsageStatsAfter :many
+SELECT
+	*
+FROM
+	template_usage_stats
+WHERE
+	date >= $1;
+
+-- name: InsertTemplateUsageStat :exec
+INSERT INTO
+	template_usage_stats (template_id, user_id, date, app_name)
+VALUES
+	($1, $2, $3, $4)
+ON CONFLICT DO NOTHING;
+
+-- name: UpsertTemplateInsights :exec
+INSERT INTO
+	template_insights (
+		template_id,
+		date,
+		updated_at,
+		active_users,
+		builds_total,
+		builds_succeeded,
+		build_duration_median_ms,
+		agent_connect_median_ms,
+		app_usage
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (template_id, date) DO UPDATE
+SET
+	updated_at = $3,
+	active_users = $4,
+	builds_total = $5,
+	builds_succeeded = $6,
+	build_duration_median_ms = $7,
+	agent_connect_median_ms = $8,
+	app_usage = $9;
@@ -91,6 +91,15 @@ SET
 WHERE
 	id = $1;
 
+-- name: UpdateTemplateOrganizationByID :exec
+UPDATE
+	templates
+SET
+	organization_id = $2,
+	updated_at = $3
+WHERE
+	id = $1;
+
 -- name: UpdateTemplateMetaByID :exec
 UPDATE
 	templates
@@ -100,8 +109,47 @@ SET
 	max_ttl = $4,
 	min_autostart_interval = $5,
 	name = $6,
-	icon = $7
+	icon = $7,
+	inactivity_ttl = $8,
+	dormant_autodelete_ttl = $9,
+	maintenance_window = $10,
+	maintenance_window_duration = $11,
+	maintenance_restart_outdated = $12,
+	record_sessions = $13
 WHERE
 	id = $1
 RETURNING
 	*;
+
+-- name: UpdateTemplatePrebuildsByID :exec
+UPDATE
+	templates
+SET
+	updated_at = $2,
+	prebuild_count = $3,
+	prebuild_parameters = $4
+WHERE
+	id = $1;
+
+-- name: GetTemplatesWithPrebuilds :many
+-- Returns templates that want prebuilt workspaces, or that still have
+-- prebuilt workspaces that need to be cleaned up.
+SELECT
+	*
+FROM
+	templates
+WHERE
+	deleted = false
+AND (
+	prebuild_count > 0
+	OR id IN (
+		SELECT
+			template_id
+		FROM
+			workspaces
+		WHERE
+			prebuild = true
+		AND
+			deleted = false
+	)
+);
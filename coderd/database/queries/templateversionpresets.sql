@@ -0,0 +1,21 @@
+-- name: GetTemplateVersionPresets :many
+SELECT
+	*
+FROM
+	template_version_presets
+WHERE
+	template_version_id = $1
+ORDER BY
+	"index";
+
+-- name: InsertTemplateVersionPreset :one
+INSERT INTO
+	template_version_presets (
+		template_version_id,
+		"name",
+		description,
+		parameters,
+		"index"
+	)
+VALUES
+	($1, $2, $3, $4, $5) RETURNING *;
@@ -71,10 +71,12 @@ INSERT INTO
 		"name",
 		readme,
 		job_id,
-		created_by
+		created_by,
+		message,
+		git_metadata
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING *;
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING *;
 
 -- name: UpdateTemplateVersionByID :exec
 UPDATE
@@ -93,3 +95,21 @@ SET
 	updated_at = $3
 WHERE
 	job_id = $1;
+
+-- name: UpdateTemplateVersionDiagnosticsByJobID :exec
+UPDATE
+	template_versions
+SET
+	diagnostics = $2,
+	updated_at = $3
+WHERE
+	job_id = $1;
+
+-- name: UpdateTemplateVersionsOrganizationByTemplateID :exec
+UPDATE
+	template_versions
+SET
+	organization_id = $2,
+	updated_at = $3
+WHERE
+	template_id = $1;
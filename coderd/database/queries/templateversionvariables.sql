@@ -0,0 +1,27 @@
+-- name: DeleteTemplateVersionVariable :exec
+DELETE FROM
+	template_version_variables
+WHERE
+	template_version_id = $1
+	AND "name" = $2;
+
+-- name: GetTemplateVersionVariables :many
+SELECT
+	*
+FROM
+	template_version_variables
+WHERE
+	template_version_id = $1
+ORDER BY
+	"name";
+
+-- name: InsertTemplateVersionVariable :one
+INSERT INTO
+	template_version_variables (
+		template_version_id,
+		"name",
+		"value",
+		sensitive
+	)
+VALUES
+	($1, $2, $3, $4) RETURNING *;
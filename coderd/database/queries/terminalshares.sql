@@ -0,0 +1,39 @@
+-- name: DeleteTerminalShareByID :exec
+DELETE FROM
+	terminal_shares
+WHERE
+	id = $1;
+
+-- name: GetTerminalShareByID :one
+SELECT
+	*
+FROM
+	terminal_shares
+WHERE
+	id = $1;
+
+-- name: GetTerminalSharesByAgentID :many
+SELECT
+	*
+FROM
+	terminal_shares
+WHERE
+	agent_id = $1
+ORDER BY
+	created_at ASC;
+
+-- name: InsertTerminalShare :one
+INSERT INTO
+	terminal_shares (
+		id,
+		created_at,
+		workspace_id,
+		agent_id,
+		reconnect_id,
+		created_by,
+		mode,
+		recipients,
+		expires_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING *;
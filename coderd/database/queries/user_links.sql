@@ -22,18 +22,20 @@ INSERT INTO
 		linked_id,
 		oauth_access_token,
 		oauth_refresh_token,
-		oauth_expiry
+		oauth_expiry,
+		oidc_provider
 	)
 VALUES
-	( $1, $2, $3, $4, $5, $6 ) RETURNING *;
+	( $1, $2, $3, $4, $5, $6, $7 ) RETURNING *;
 
 -- name: UpdateUserLinkedID :one
 UPDATE
 	user_links
 SET
-	linked_id = $1
+	linked_id = $1,
+	oidc_provider = $2
 WHERE
-	user_id = $2 AND login_type = $3 RETURNING *;
+	user_id = $3 AND login_type = $4 RETURNING *;
 
 -- name: UpdateUserLink :one
 UPDATE
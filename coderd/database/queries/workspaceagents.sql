@@ -81,3 +81,19 @@ SET
 	updated_at = $4
 WHERE
 	id = $1;
+
+-- name: UpdateWorkspaceAgentVersionByID :exec
+UPDATE
+	workspace_agents
+SET
+	version = $2
+WHERE
+	id = $1;
+
+-- name: UpdateWorkspaceAgentHealthChecksByID :exec
+UPDATE
+	workspace_agents
+SET
+	health_checks = $2
+WHERE
+	id = $1;
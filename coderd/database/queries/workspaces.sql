@@ -1,3 +1,12 @@
+-- name: DeleteDeletedWorkspacesByOrganizationID :exec
+-- Soft-deleted workspaces restrict the deletion of their organization and
+-- template, so they're removed for good before the organization is deleted.
+DELETE FROM
+	workspaces
+WHERE
+	organization_id = $1
+	AND deleted = true;
+
 -- name: GetWorkspaceByID :one
 SELECT
 	*
@@ -48,6 +57,18 @@ WHERE
 		    name ILIKE '%' || @name || '%'
 		ELSE true
 	END
+	-- Optionally hide dormant workspaces
+	AND CASE
+		WHEN @exclude_dormant :: boolean THEN
+			dormant_at IS NULL
+		ELSE true
+	END
+	-- Optionally hide prebuilt workspaces that have not been claimed
+	AND CASE
+		WHEN @exclude_prebuilds :: boolean THEN
+			prebuild = false
+		ELSE true
+	END
 ;
 
 -- name: GetWorkspacesAutostart :many
@@ -64,6 +85,41 @@ AND
 	(ttl IS NOT NULL AND ttl > 0)
 );
 
+-- name: GetWorkspacesDormancy :many
+-- Returns workspaces whose template has a dormancy policy that still applies
+-- to them: active workspaces may become dormant, and dormant workspaces may
+-- be deleted.
+SELECT
+	workspaces.*
+FROM
+	workspaces
+INNER JOIN
+	templates ON workspaces.template_id = templates.id
+WHERE
+	workspaces.deleted = false
+AND
+(
+	(workspaces.dormant_at IS NULL AND templates.inactivity_ttl > 0)
+	OR
+	(workspaces.dormant_at IS NOT NULL AND templates.dormant_autodelete_ttl > 0)
+);
+
+-- name: GetWorkspacesMaintenance :many
+-- Returns workspaces whose template restarts outdated workspaces during its
+-- maintenance window.
+SELECT
+	workspaces.*
+FROM
+	workspaces
+INNER JOIN
+	templates ON workspaces.template_id = templates.id
+WHERE
+	workspaces.deleted = false
+AND
+	templates.maintenance_window <> ''
+AND
+	templates.maintenance_restart_outdated = true;
+
 -- name: GetWorkspaceByOwnerIDAndName :one
 SELECT
 	*
@@ -99,10 +155,12 @@ INSERT INTO
 		template_id,
 		name,
 		autostart_schedule,
-		ttl
+		ttl,
+		prebuild,
+		preset
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING *;
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING *;
 
 -- name: UpdateWorkspaceDeletedByID :exec
 UPDATE
@@ -137,3 +195,63 @@ SET
 	ttl = $2
 WHERE
 	id = $1;
+
+-- name: UpdateWorkspacesOrganizationByTemplateID :exec
+UPDATE
+	workspaces
+SET
+	organization_id = $2,
+	updated_at = $3
+WHERE
+	template_id = $1;
+
+-- name: UpdateWorkspaceDormantAt :exec
+UPDATE
+	workspaces
+SET
+	dormant_at = $2
+WHERE
+	id = $1;
+
+-- name: UpdateWorkspaceLastUsedAt :exec
+UPDATE
+	workspaces
+SET
+	last_used_at = $2
+WHERE
+	id = $1;
+
+-- name: GetPrebuiltWorkspacesByTemplateID :many
+SELECT
+	*
+FROM
+	workspaces
+WHERE
+	template_id = $1
+AND
+	prebuild = true
+AND
+	deleted = false
+ORDER BY
+	created_at ASC;
+
+-- name: ClaimPrebuiltWorkspace :one
+-- Transfers a prebuilt workspace to its new owner. No rows are returned if
+-- the workspace has already been claimed.
+UPDATE
+	workspaces
+SET
+	owner_id = $2,
+	name = $3,
+	autostart_schedule = $4,
+	ttl = $5,
+	updated_at = $6,
+	last_used_at = $6,
+	prebuild = false,
+	preset = $7
+WHERE
+	id = $1
+AND
+	prebuild = true
+RETURNING
+	*;
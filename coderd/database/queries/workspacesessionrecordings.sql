@@ -0,0 +1,34 @@
+-- name: GetWorkspaceSessionRecordingByID :one
+SELECT
+	*
+FROM
+	workspace_session_recordings
+WHERE
+	id = $1;
+
+-- name: GetWorkspaceSessionRecordingsByWorkspaceID :many
+-- The recording data is left out, since it can be large.
+SELECT
+	id, created_at, workspace_id, agent_id, user_id, type, started_at, ended_at, octet_length(data)::bigint AS size
+FROM
+	workspace_session_recordings
+WHERE
+	workspace_id = $1
+ORDER BY
+	started_at DESC;
+
+-- name: InsertWorkspaceSessionRecording :one
+INSERT INTO
+	workspace_session_recordings (
+		id,
+		created_at,
+		workspace_id,
+		agent_id,
+		user_id,
+		type,
+		started_at,
+		ended_at,
+		data
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING *;
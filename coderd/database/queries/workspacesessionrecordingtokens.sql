@@ -0,0 +1,24 @@
+-- name: DeleteWorkspaceSessionRecordingTokenByID :exec
+DELETE FROM
+	workspace_session_recording_tokens
+WHERE
+	id = $1;
+
+-- name: GetWorkspaceSessionRecordingTokenByID :one
+SELECT
+	*
+FROM
+	workspace_session_recording_tokens
+WHERE
+	id = $1;
+
+-- name: InsertWorkspaceSessionRecordingToken :one
+INSERT INTO
+	workspace_session_recording_tokens (
+		id,
+		created_at,
+		agent_id,
+		user_id
+	)
+VALUES
+	($1, $2, $3, $4) RETURNING *;
@@ -8,9 +8,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/coder/coder/coderd/blobstore"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
@@ -18,6 +20,9 @@ import (
 	"github.com/coder/coder/codersdk"
 )
 
+// maxFileSize is the largest file that can be uploaded.
+const maxFileSize = 10 * (10 << 20)
+
 func (api *API) postFile(rw http.ResponseWriter, r *http.Request) {
 	apiKey := httpmw.APIKey(r)
 	// This requires the site wide action to create files.
@@ -38,8 +43,24 @@ func (api *API) postFile(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	r.Body = http.MaxBytesReader(rw, r.Body, 10*(10<<20))
-	data, err := io.ReadAll(r.Body)
+	// The upload is spooled to disk while it's hashed, so large files are
+	// never held in memory. The blob store needs the hash as the key before
+	// it can store the contents.
+	spool, err := os.CreateTemp("", "coder-upload-*")
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating temporary file.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+	hasher := sha256.New()
+	r.Body = http.MaxBytesReader(rw, r.Body, maxFileSize)
+	size, err := io.Copy(io.MultiWriter(spool, hasher), r.Body)
 	if err != nil {
 		httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
 			Message: "Failed to read file from request.",
@@ -47,23 +68,29 @@ func (api *API) postFile(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	hashBytes := sha256.Sum256(data)
-	hash := hex.EncodeToString(hashBytes[:])
-	file, err := api.Database.GetFileByHash(r.Context(), hash)
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	status := http.StatusCreated
+	_, err = api.Database.GetFileByHash(r.Context(), hash)
 	if err == nil {
-		// The file already exists!
-		httpapi.Write(rw, http.StatusOK, codersdk.UploadResponse{
-			Hash: file.Hash,
+		// The file already exists! It's stored again anyway, so it isn't
+		// collected before it's used.
+		status = http.StatusOK
+	}
+
+	_, err = spool.Seek(0, io.SeekStart)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error reading temporary file.",
+			Detail:  err.Error(),
 		})
 		return
 	}
-	file, err = api.Database.InsertFile(r.Context(), database.InsertFileParams{
+	file, err := blobstore.StoreFile(r.Context(), api.Database, api.BlobStore, database.InsertFileParams{
 		Hash:      hash,
 		CreatedBy: apiKey.UserID,
 		CreatedAt: database.Now(),
 		Mimetype:  contentType,
-		Data:      data,
-	})
+	}, spool, size)
 	if err != nil {
 		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
 			Message: "Internal error saving file.",
@@ -72,7 +99,7 @@ func (api *API) postFile(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httpapi.Write(rw, http.StatusCreated, codersdk.UploadResponse{
+	httpapi.Write(rw, status, codersdk.UploadResponse{
 		Hash: file.Hash,
 	})
 }
@@ -105,7 +132,17 @@ func (api *API) fileByHash(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	data, err := api.BlobStore.Get(r.Context(), file.Hash)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error reading file.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	defer data.Close()
+
 	rw.Header().Set("Content-Type", file.Mimetype)
 	rw.WriteHeader(http.StatusOK)
-	_, _ = rw.Write(file.Data)
+	_, _ = io.Copy(rw, data)
 }
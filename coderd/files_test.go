@@ -3,10 +3,13 @@ package coderd_test
 import (
 	"context"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/coder/coder/coderd/blobstore"
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/testutil"
@@ -52,6 +55,25 @@ func TestPostFiles(t *testing.T) {
 		_, err = client.Upload(ctx, codersdk.ContentTypeTar, data)
 		require.NoError(t, err)
 	})
+
+	t.Run("BlobStore", func(t *testing.T) {
+		t.Parallel()
+		directory := t.TempDir()
+		store, err := blobstore.NewFilesystem(directory)
+		require.NoError(t, err)
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerD: true,
+			BlobStore:           store,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		// Template versions are imported from the blob store.
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		version = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		require.Equal(t, codersdk.ProvisionerJobSucceeded, version.Job.Status)
+		_, err = os.Stat(filepath.Join(directory, version.Job.StorageSource))
+		require.NoError(t, err)
+	})
 }
 
 func TestDownload(t *testing.T) {
@@ -51,6 +51,19 @@ func AuthorizationUserRoles(r *http.Request) database.GetAuthorizationUserRolesR
 type OAuth2Configs struct {
 	Github OAuth2Config
 	OIDC   OAuth2Config
+	// OIDCProviders are additional OIDC configurations keyed by the
+	// name of the provider.
+	OIDCProviders map[string]OAuth2Config
+}
+
+// oidcConfig returns the configuration of the named provider a user is
+// linked to. An empty name is the default OIDC configuration.
+func (c *OAuth2Configs) oidcConfig(provider string) (OAuth2Config, bool) {
+	if provider == "" {
+		return c.OIDC, true
+	}
+	config, ok := c.OIDCProviders[provider]
+	return config, ok
 }
 
 const (
@@ -170,7 +183,15 @@ func ExtractAPIKey(db database.Store, oauth *OAuth2Configs, redirectToLogin bool
 					case database.LoginTypeGithub:
 						oauthConfig = oauth.Github
 					case database.LoginTypeOIDC:
-						oauthConfig = oauth.OIDC
+						var ok bool
+						oauthConfig, ok = oauth.oidcConfig(link.OIDCProvider)
+						if !ok {
+							write(http.StatusUnauthorized, codersdk.Response{
+								Message: signedOutErrorMessage,
+								Detail:  fmt.Sprintf("The OIDC provider %q is no longer configured.", link.OIDCProvider),
+							})
+							return
+						}
 					default:
 						write(http.StatusInternalServerError, codersdk.Response{
 							Message: internalErrorMessage,
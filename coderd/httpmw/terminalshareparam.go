@@ -0,0 +1,54 @@
+package httpmw
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
+)
+
+type terminalShareParamContextKey struct{}
+
+// TerminalShareParam returns the terminal share from the ExtractTerminalShareParam handler.
+func TerminalShareParam(r *http.Request) database.TerminalShare {
+	share, ok := r.Context().Value(terminalShareParamContextKey{}).(database.TerminalShare)
+	if !ok {
+		panic("developer error: terminal share middleware not provided")
+	}
+	return share
+}
+
+// ExtractTerminalShareParam grabs a terminal share from the "terminalshare" URL parameter.
+func ExtractTerminalShareParam(db database.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			shareID, parsed := parseUUID(rw, r, "terminalshare")
+			if !parsed {
+				return
+			}
+
+			share, err := db.GetTerminalShareByID(r.Context(), shareID)
+			if errors.Is(err, sql.ErrNoRows) {
+				httpapi.ResourceNotFound(rw)
+				return
+			}
+			if err != nil {
+				httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+					Message: "Internal error fetching terminal share.",
+					Detail:  err.Error(),
+				})
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), terminalShareParamContextKey{}, share)
+			chi.RouteContext(ctx).URLParams.Add("workspace", share.WorkspaceID.String())
+			next.ServeHTTP(rw, r.WithContext(ctx))
+		})
+	}
+}
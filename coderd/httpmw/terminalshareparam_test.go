@@ -0,0 +1,90 @@
+package httpmw_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/databasefake"
+	"github.com/coder/coder/coderd/httpmw"
+)
+
+func TestTerminalShareParam(t *testing.T) {
+	t.Parallel()
+
+	setup := func(db database.Store) (*http.Request, database.TerminalShare) {
+		r := httptest.NewRequest("GET", "/", nil)
+		share, err := db.InsertTerminalShare(context.Background(), database.InsertTerminalShareParams{
+			ID:          uuid.New(),
+			CreatedAt:   database.Now(),
+			WorkspaceID: uuid.New(),
+			AgentID:     uuid.New(),
+			ReconnectID: uuid.New(),
+			CreatedBy:   uuid.New(),
+			Mode:        database.TerminalShareModeReadOnly,
+		})
+		require.NoError(t, err)
+
+		ctx := chi.NewRouteContext()
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, ctx))
+		return r, share
+	}
+
+	t.Run("None", func(t *testing.T) {
+		t.Parallel()
+		db := databasefake.New()
+		rtr := chi.NewRouter()
+		rtr.Use(httpmw.ExtractTerminalShareParam(db))
+		rtr.Get("/", nil)
+		r, _ := setup(db)
+		rw := httptest.NewRecorder()
+		rtr.ServeHTTP(rw, r)
+
+		res := rw.Result()
+		defer res.Body.Close()
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		db := databasefake.New()
+		rtr := chi.NewRouter()
+		rtr.Use(httpmw.ExtractTerminalShareParam(db))
+		rtr.Get("/", nil)
+		r, _ := setup(db)
+		chi.RouteContext(r.Context()).URLParams.Add("terminalshare", uuid.NewString())
+		rw := httptest.NewRecorder()
+		rtr.ServeHTTP(rw, r)
+
+		res := rw.Result()
+		defer res.Body.Close()
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("Found", func(t *testing.T) {
+		t.Parallel()
+		db := databasefake.New()
+		rtr := chi.NewRouter()
+		rtr.Use(httpmw.ExtractTerminalShareParam(db))
+		rtr.Get("/", func(rw http.ResponseWriter, r *http.Request) {
+			share := httpmw.TerminalShareParam(r)
+			// The workspace of the share is exposed for ExtractWorkspaceParam.
+			require.Equal(t, share.WorkspaceID.String(), chi.URLParam(r, "workspace"))
+			rw.WriteHeader(http.StatusOK)
+		})
+		r, share := setup(db)
+		chi.RouteContext(r.Context()).URLParams.Add("terminalshare", share.ID.String())
+		rw := httptest.NewRecorder()
+		rtr.ServeHTTP(rw, r)
+
+		res := rw.Result()
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	})
+}
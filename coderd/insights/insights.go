@@ -0,0 +1,325 @@
+// Package insights computes daily rollups of how templates are used: active
+// users, build success rate and duration, time until agents first connect,
+// and which apps are used.
+package insights
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"cdr.dev/slog"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+)
+
+// Backfill is how far back insights are computed when an aggregator runs for
+// the first time.
+const Backfill = 30 * 24 * time.Hour
+
+// Date truncates a time to the UTC day it's in. Insights are always rolled up
+// by UTC day.
+func Date(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Tracker records which users used workspaces of a template each day. It
+// remembers what it recorded today, so repeated connections and app requests
+// don't each write to the database.
+type Tracker struct {
+	db database.Store
+
+	mutex   sync.Mutex
+	date    time.Time
+	tracked map[database.InsertTemplateUsageStatParams]struct{}
+}
+
+// NewTracker returns a tracker that records usage to the database.
+func NewTracker(db database.Store) *Tracker {
+	return &Tracker{
+		db:      db,
+		tracked: map[database.InsertTemplateUsageStatParams]struct{}{},
+	}
+}
+
+// Track records that a user used a workspace of a template. The app name is
+// empty for connections to the workspace agent.
+func (t *Tracker) Track(ctx context.Context, templateID, userID uuid.UUID, appName string) error {
+	params := database.InsertTemplateUsageStatParams{
+		TemplateID: templateID,
+		UserID:     userID,
+		Date:       Date(database.Now()),
+		AppName:    appName,
+	}
+	t.mutex.Lock()
+	if !t.date.Equal(params.Date) {
+		t.date = params.Date
+		t.tracked = map[database.InsertTemplateUsageStatParams]struct{}{}
+	}
+	_, tracked := t.tracked[params]
+	t.tracked[params] = struct{}{}
+	t.mutex.Unlock()
+	if tracked {
+		return nil
+	}
+
+	err := t.db.InsertTemplateUsageStat(ctx, params)
+	if err != nil {
+		t.mutex.Lock()
+		delete(t.tracked, params)
+		t.mutex.Unlock()
+		return xerrors.Errorf("insert template usage stat: %w", err)
+	}
+	return nil
+}
+
+// Aggregator periodically computes insights for recent days.
+type Aggregator struct {
+	ctx  context.Context
+	db   database.Store
+	log  slog.Logger
+	tick <-chan time.Time
+}
+
+// NewAggregator returns an aggregator of template insights.
+func NewAggregator(ctx context.Context, db database.Store, log slog.Logger, tick <-chan time.Time) *Aggregator {
+	return &Aggregator{
+		ctx:  ctx,
+		db:   db,
+		log:  log,
+		tick: tick,
+	}
+}
+
+// Run aggregates insights on every tick from its channel. The first run
+// backfills insights, and later runs recompute yesterday and today so builds
+// that complete after midnight are included. It will stop when its context
+// is Done, or when its channel is closed.
+func (a *Aggregator) Run() {
+	go func() {
+		backfilled := false
+		for {
+			select {
+			case <-a.ctx.Done():
+				return
+			case t, ok := <-a.tick:
+				if !ok {
+					return
+				}
+				since := Date(t).AddDate(0, 0, -1)
+				if !backfilled {
+					since = Date(t.Add(-Backfill))
+				}
+				err := Aggregate(a.ctx, a.db, since, t)
+				if err != nil {
+					a.log.Error(a.ctx, "aggregate template insights", slog.Error(err))
+					continue
+				}
+				backfilled = true
+			}
+		}
+	}()
+}
+
+type dayKey struct {
+	templateID uuid.UUID
+	date       time.Time
+}
+
+type day struct {
+	users          map[uuid.UUID]struct{}
+	apps           map[string]map[uuid.UUID]struct{}
+	buildsTotal    int32
+	buildsSuccess  int32
+	buildDurations []int64
+	agentConnects  []int64
+}
+
+// Aggregate computes the insights of every template for each day from since
+// until now.
+func Aggregate(ctx context.Context, db database.Store, since, now time.Time) error {
+	since = Date(since)
+	days := map[dayKey]*day{}
+	get := func(templateID uuid.UUID, t time.Time) *day {
+		key := dayKey{templateID: templateID, date: Date(t)}
+		d, ok := days[key]
+		if !ok {
+			d = &day{
+				users: map[uuid.UUID]struct{}{},
+				apps:  map[string]map[uuid.UUID]struct{}{},
+			}
+			days[key] = d
+		}
+		return d
+	}
+
+	stats, err := db.GetTemplateUsageStatsAfter(ctx, since)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return xerrors.Errorf("get template usage stats: %w", err)
+	}
+	for _, stat := range stats {
+		d := get(stat.TemplateID, stat.Date)
+		d.users[stat.UserID] = struct{}{}
+		if stat.AppName == "" {
+			continue
+		}
+		if _, ok := d.apps[stat.AppName]; !ok {
+			d.apps[stat.AppName] = map[uuid.UUID]struct{}{}
+		}
+		d.apps[stat.AppName][stat.UserID] = struct{}{}
+	}
+
+	err = aggregateBuilds(ctx, db, since, get)
+	if err != nil {
+		return err
+	}
+
+	for key, d := range days {
+		apps := make(map[string]int, len(d.apps))
+		for name, users := range d.apps {
+			apps[name] = len(users)
+		}
+		appUsage, err := json.Marshal(apps)
+		if err != nil {
+			return xerrors.Errorf("marshal app usage: %w", err)
+		}
+		err = db.UpsertTemplateInsights(ctx, database.UpsertTemplateInsightsParams{
+			TemplateID:            key.templateID,
+			Date:                  key.date,
+			UpdatedAt:             now,
+			ActiveUsers:           int32(len(d.users)),
+			BuildsTotal:           d.buildsTotal,
+			BuildsSucceeded:       d.buildsSuccess,
+			BuildDurationMedianMs: Median(d.buildDurations),
+			AgentConnectMedianMs:  Median(d.agentConnects),
+			AppUsage:              appUsage,
+		})
+		if err != nil {
+			return xerrors.Errorf("upsert insights of template %s: %w", key.templateID, err)
+		}
+	}
+	return nil
+}
+
+// aggregateBuilds adds the completed builds created since the given time to
+// the days they were created on. Users that start builds themselves are
+// counted as active.
+func aggregateBuilds(ctx context.Context, db database.Store, since time.Time, get func(uuid.UUID, time.Time) *day) error {
+	builds, err := db.GetWorkspaceBuildsCreatedAfter(ctx, since)
+	if errors.Is(err, sql.ErrNoRows) || len(builds) == 0 {
+		return nil
+	}
+	if err != nil {
+		return xerrors.Errorf("get workspace builds: %w", err)
+	}
+	jobIDs := make([]uuid.UUID, 0, len(builds))
+	for _, build := range builds {
+		jobIDs = append(jobIDs, build.JobID)
+	}
+	jobs, err := db.GetProvisionerJobsByIDs(ctx, jobIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return xerrors.Errorf("get provisioner jobs: %w", err)
+	}
+	jobsByID := make(map[uuid.UUID]database.ProvisionerJob, len(jobs))
+	for _, job := range jobs {
+		jobsByID[job.ID] = job
+	}
+	firstConnected, err := firstAgentConnections(ctx, db, jobIDs)
+	if err != nil {
+		return err
+	}
+
+	templateIDs := map[uuid.UUID]uuid.NullUUID{}
+	for _, build := range builds {
+		templateID, ok := templateIDs[build.TemplateVersionID]
+		if !ok {
+			version, err := db.GetTemplateVersionByID(ctx, build.TemplateVersionID)
+			if err != nil {
+				return xerrors.Errorf("get template version: %w", err)
+			}
+			templateID = version.TemplateID
+			templateIDs[build.TemplateVersionID] = templateID
+		}
+		if !templateID.Valid {
+			continue
+		}
+		d := get(templateID.UUID, build.CreatedAt)
+		if build.Reason == database.BuildReasonInitiator {
+			d.users[build.InitiatorID] = struct{}{}
+		}
+
+		job, ok := jobsByID[build.JobID]
+		if !ok || !job.CompletedAt.Valid {
+			continue
+		}
+		d.buildsTotal++
+		if job.CanceledAt.Valid || job.Error.String != "" {
+			continue
+		}
+		d.buildsSuccess++
+		if job.StartedAt.Valid {
+			d.buildDurations = append(d.buildDurations, job.CompletedAt.Time.Sub(job.StartedAt.Time).Milliseconds())
+		}
+		connected, ok := firstConnected[build.JobID]
+		if build.Transition == database.WorkspaceTransitionStart && ok {
+			d.agentConnects = append(d.agentConnects, connected.Sub(build.CreatedAt).Milliseconds())
+		}
+	}
+	return nil
+}
+
+// firstAgentConnections returns when an agent of each job first connected.
+func firstAgentConnections(ctx context.Context, db database.Store, jobIDs []uuid.UUID) (map[uuid.UUID]time.Time, error) {
+	resources, err := db.GetWorkspaceResourcesByJobIDs(ctx, jobIDs)
+	if errors.Is(err, sql.ErrNoRows) || len(resources) == 0 {
+		return map[uuid.UUID]time.Time{}, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("get workspace resources: %w", err)
+	}
+	jobIDByResourceID := make(map[uuid.UUID]uuid.UUID, len(resources))
+	resourceIDs := make([]uuid.UUID, 0, len(resources))
+	for _, resource := range resources {
+		jobIDByResourceID[resource.ID] = resource.JobID
+		resourceIDs = append(resourceIDs, resource.ID)
+	}
+	agents, err := db.GetWorkspaceAgentsByResourceIDs(ctx, resourceIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, xerrors.Errorf("get workspace agents: %w", err)
+	}
+	firstConnected := map[uuid.UUID]time.Time{}
+	for _, agent := range agents {
+		if !agent.FirstConnectedAt.Valid {
+			continue
+		}
+		jobID := jobIDByResourceID[agent.ResourceID]
+		connected, ok := firstConnected[jobID]
+		if !ok || agent.FirstConnectedAt.Time.Before(connected) {
+			firstConnected[jobID] = agent.FirstConnectedAt.Time
+		}
+	}
+	return firstConnected, nil
+}
+
+// Median returns the median of the values, which is invalid if there are no
+// values. The values are sorted in place.
+func Median(values []int64) sql.NullInt64 {
+	if len(values) == 0 {
+		return sql.NullInt64{}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i] < values[j]
+	})
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return sql.NullInt64{Int64: (values[middle-1] + values[middle]) / 2, Valid: true}
+	}
+	return sql.NullInt64{Int64: values[middle], Valid: true}
+}
@@ -0,0 +1,148 @@
+package insights_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/databasefake"
+	"github.com/coder/coder/coderd/insights"
+)
+
+func TestAggregate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := databasefake.New()
+	templateID := uuid.New()
+	versionID := uuid.New()
+	_, err := db.InsertTemplateVersion(ctx, database.InsertTemplateVersionParams{
+		ID:         versionID,
+		TemplateID: uuid.NullUUID{UUID: templateID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	day := time.Date(2022, 7, 14, 0, 0, 0, 0, time.UTC)
+	owner := uuid.New()
+	// build inserts a start build that was created at the given time, and
+	// took the given duration to complete.
+	build := func(createdAt time.Time, duration time.Duration, failed bool) uuid.UUID {
+		job, err := db.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+			ID:          uuid.New(),
+			CreatedAt:   createdAt,
+			InitiatorID: owner,
+			Provisioner: database.ProvisionerTypeEcho,
+			Type:        database.ProvisionerJobTypeWorkspaceBuild,
+		})
+		require.NoError(t, err)
+		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+			StartedAt: sql.NullTime{Time: createdAt, Valid: true},
+			Types:     []database.ProvisionerType{database.ProvisionerTypeEcho},
+		})
+		require.NoError(t, err)
+		var jobErr sql.NullString
+		if failed {
+			jobErr = sql.NullString{String: "failed", Valid: true}
+		}
+		err = db.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+			ID:          job.ID,
+			CompletedAt: sql.NullTime{Time: createdAt.Add(duration), Valid: true},
+			Error:       jobErr,
+		})
+		require.NoError(t, err)
+		_, err = db.InsertWorkspaceBuild(ctx, database.InsertWorkspaceBuildParams{
+			ID:                uuid.New(),
+			CreatedAt:         createdAt,
+			TemplateVersionID: versionID,
+			Transition:        database.WorkspaceTransitionStart,
+			InitiatorID:       owner,
+			JobID:             job.ID,
+			Reason:            database.BuildReasonInitiator,
+		})
+		require.NoError(t, err)
+		return job.ID
+	}
+
+	jobID := build(day.Add(time.Hour), time.Minute, false)
+	build(day.Add(2*time.Hour), 3*time.Minute, false)
+	build(day.Add(3*time.Hour), time.Second, true)
+
+	// The agent of the first build connected 90 seconds after it started.
+	resource, err := db.InsertWorkspaceResource(ctx, database.InsertWorkspaceResourceParams{
+		ID:         uuid.New(),
+		JobID:      jobID,
+		Transition: database.WorkspaceTransitionStart,
+	})
+	require.NoError(t, err)
+	agent, err := db.InsertWorkspaceAgent(ctx, database.InsertWorkspaceAgentParams{
+		ID:         uuid.New(),
+		ResourceID: resource.ID,
+	})
+	require.NoError(t, err)
+	err = db.UpdateWorkspaceAgentConnectionByID(ctx, database.UpdateWorkspaceAgentConnectionByIDParams{
+		ID:               agent.ID,
+		FirstConnectedAt: sql.NullTime{Time: day.Add(time.Hour + 90*time.Second), Valid: true},
+	})
+	require.NoError(t, err)
+
+	// Another user only used an app.
+	err = db.InsertTemplateUsageStat(ctx, database.InsertTemplateUsageStatParams{
+		TemplateID: templateID,
+		UserID:     uuid.New(),
+		Date:       day,
+		AppName:    "code-server",
+	})
+	require.NoError(t, err)
+
+	err = insights.Aggregate(ctx, db, day, day.Add(12*time.Hour))
+	require.NoError(t, err)
+
+	rows, err := db.GetTemplateInsights(ctx, database.GetTemplateInsightsParams{
+		TemplateID: templateID,
+		StartDate:  day,
+		EndDate:    day.AddDate(0, 0, 1),
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	row := rows[0]
+	require.EqualValues(t, 2, row.ActiveUsers)
+	require.EqualValues(t, 3, row.BuildsTotal)
+	require.EqualValues(t, 2, row.BuildsSucceeded)
+	require.Equal(t, sql.NullInt64{Int64: (2 * time.Minute).Milliseconds(), Valid: true}, row.BuildDurationMedianMs)
+	require.Equal(t, sql.NullInt64{Int64: (90 * time.Second).Milliseconds(), Valid: true}, row.AgentConnectMedianMs)
+	var apps map[string]int
+	require.NoError(t, json.Unmarshal(row.AppUsage, &apps))
+	require.Equal(t, map[string]int{"code-server": 1}, apps)
+}
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := databasefake.New()
+	tracker := insights.NewTracker(db)
+	templateID := uuid.New()
+	userID := uuid.New()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, tracker.Track(ctx, templateID, userID, ""))
+	}
+	require.NoError(t, tracker.Track(ctx, templateID, userID, "code-server"))
+
+	stats, err := db.GetTemplateUsageStatsAfter(ctx, insights.Date(database.Now()))
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+}
+
+func TestMedian(t *testing.T) {
+	t.Parallel()
+
+	require.False(t, insights.Median(nil).Valid)
+	require.Equal(t, sql.NullInt64{Int64: 2, Valid: true}, insights.Median([]int64{3, 1, 2}))
+	require.Equal(t, sql.NullInt64{Int64: 25, Valid: true}, insights.Median([]int64{40, 10, 30, 20}))
+}
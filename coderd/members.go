@@ -2,6 +2,9 @@ package coderd
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -16,6 +19,134 @@ import (
 	"github.com/coder/coder/codersdk"
 )
 
+func (api *API) organizationMembers(rw http.ResponseWriter, r *http.Request) {
+	organization := httpmw.OrganizationParam(r)
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceOrganizationMember.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	members, err := api.Database.GetOrganizationMembersByOrganizationID(r.Context(), organization.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization members.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		userIDs = append(userIDs, member.UserID)
+	}
+	users, err := api.Database.GetUsersByIDs(r.Context(), userIDs)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching users.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	usersByID := make(map[uuid.UUID]database.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	apiMembers := make([]codersdk.OrganizationMemberWithUser, 0, len(members))
+	for _, member := range members {
+		user, ok := usersByID[member.UserID]
+		if !ok {
+			continue
+		}
+		apiMembers = append(apiMembers, convertOrganizationMemberWithUser(member, user))
+	}
+
+	httpapi.Write(rw, http.StatusOK, apiMembers)
+}
+
+func (api *API) postOrganizationMember(rw http.ResponseWriter, r *http.Request) {
+	user := httpmw.UserParam(r)
+	organization := httpmw.OrganizationParam(r)
+	if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceOrganizationMember.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	_, err := api.Database.GetOrganizationMemberByUserID(r.Context(), database.GetOrganizationMemberByUserIDParams{
+		OrganizationID: organization.ID,
+		UserID:         user.ID,
+	})
+	if err == nil {
+		httpapi.Write(rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("User %q is already a member of this organization.", user.Username),
+		})
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization member.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	member, err := api.Database.InsertOrganizationMember(r.Context(), database.InsertOrganizationMemberParams{
+		OrganizationID: organization.ID,
+		UserID:         user.ID,
+		CreatedAt:      database.Now(),
+		UpdatedAt:      database.Now(),
+		Roles:          []string{},
+	})
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error inserting organization member.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(rw, http.StatusCreated, convertOrganizationMember(member))
+}
+
+func (api *API) deleteOrganizationMember(rw http.ResponseWriter, r *http.Request) {
+	organization := httpmw.OrganizationParam(r)
+	member := httpmw.OrganizationMemberParam(r)
+	apiKey := httpmw.APIKey(r)
+	if !api.Authorize(r, rbac.ActionDelete, member) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	if apiKey.UserID == member.UserID {
+		httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
+			Message: "You cannot remove yourself from an organization.",
+		})
+		return
+	}
+
+	err := api.Database.DeleteOrganizationMember(r.Context(), database.DeleteOrganizationMemberParams{
+		OrganizationID: organization.ID,
+		UserID:         member.UserID,
+	})
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting organization member.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(rw, http.StatusOK, codersdk.Response{
+		Message: "Organization member has been removed!",
+	})
+}
+
 func (api *API) putMemberRoles(rw http.ResponseWriter, r *http.Request) {
 	user := httpmw.UserParam(r)
 	organization := httpmw.OrganizationParam(r)
@@ -119,3 +250,16 @@ func convertOrganizationMember(mem database.OrganizationMember) codersdk.Organiz
 	}
 	return convertedMember
 }
+
+func convertOrganizationMemberWithUser(mem database.OrganizationMember, user database.User) codersdk.OrganizationMemberWithUser {
+	member := convertOrganizationMember(mem)
+	return codersdk.OrganizationMemberWithUser{
+		UserID:         member.UserID,
+		OrganizationID: member.OrganizationID,
+		Username:       user.Username,
+		Email:          user.Email,
+		CreatedAt:      member.CreatedAt,
+		UpdatedAt:      member.UpdatedAt,
+		Roles:          member.Roles,
+	}
+}
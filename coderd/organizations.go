@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/google/uuid"
 	"golang.org/x/xerrors"
@@ -28,6 +29,39 @@ func (api *API) organization(rw http.ResponseWriter, r *http.Request) {
 	httpapi.Write(rw, http.StatusOK, convertOrganization(organization))
 }
 
+// organizations returns every organization the caller can read. Site-wide
+// owners see all organizations, everyone else only sees their own.
+func (api *API) organizations(rw http.ResponseWriter, r *http.Request) {
+	organizations, err := api.Database.GetOrganizations(r.Context())
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+		organizations = []database.Organization{}
+	}
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organizations.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	organizations, err = AuthorizeFilter(api.httpAuth, r, rbac.ActionRead, organizations)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error authorizing organizations.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	publicOrganizations := make([]codersdk.Organization, 0, len(organizations))
+	for _, organization := range organizations {
+		publicOrganizations = append(publicOrganizations, convertOrganization(organization))
+	}
+
+	httpapi.Write(rw, http.StatusOK, publicOrganizations)
+}
+
 func (api *API) postOrganizations(rw http.ResponseWriter, r *http.Request) {
 	apiKey := httpmw.APIKey(r)
 	// Create organization uses the organization resource without an OrgID.
@@ -93,6 +127,118 @@ func (api *API) postOrganizations(rw http.ResponseWriter, r *http.Request) {
 	httpapi.Write(rw, http.StatusCreated, convertOrganization(organization))
 }
 
+func (api *API) patchOrganization(rw http.ResponseWriter, r *http.Request) {
+	organization := httpmw.OrganizationParam(r)
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceOrganization.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateOrganizationRequest
+	if !httpapi.Read(rw, r, &req) {
+		return
+	}
+
+	if req.Name != organization.Name {
+		_, err := api.Database.GetOrganizationByName(r.Context(), req.Name)
+		if err == nil {
+			httpapi.Write(rw, http.StatusConflict, codersdk.Response{
+				Message: "Organization already exists with that name.",
+			})
+			return
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+				Message: fmt.Sprintf("Internal error fetching organization %q.", req.Name),
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+
+	updated, err := api.Database.UpdateOrganizationByID(r.Context(), database.UpdateOrganizationByIDParams{
+		ID:          organization.ID,
+		Name:        req.Name,
+		Description: organization.Description,
+		UpdatedAt:   database.Now(),
+	})
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating organization.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(rw, http.StatusOK, convertOrganization(updated))
+}
+
+func (api *API) deleteOrganization(rw http.ResponseWriter, r *http.Request) {
+	organization := httpmw.OrganizationParam(r)
+	if !api.Authorize(r, rbac.ActionDelete, rbac.ResourceOrganization.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	templates, err := api.Database.GetTemplatesWithFilter(r.Context(), database.GetTemplatesWithFilterParams{
+		OrganizationID: organization.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching templates.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if len(templates) > 0 {
+		names := make([]string, 0, len(templates))
+		for _, template := range templates {
+			names = append(names, template.Name)
+		}
+		httpapi.Write(rw, http.StatusPreconditionFailed, codersdk.Response{
+			Message: "All templates must be deleted or moved before an organization can be removed.",
+			Detail:  fmt.Sprintf("Templates: %s", strings.Join(names, ", ")),
+		})
+		return
+	}
+
+	err = api.Database.InTx(func(store database.Store) error {
+		// Templates can only be deleted once their workspaces are, so
+		// only soft-deleted workspaces are left. They would restrict the
+		// deletion of the organization and its soft-deleted templates.
+		err := store.DeleteDeletedWorkspacesByOrganizationID(r.Context(), organization.ID)
+		if err != nil {
+			return xerrors.Errorf("delete deleted workspaces: %w", err)
+		}
+		err = store.DeleteOrganizationByID(r.Context(), organization.ID)
+		if err != nil {
+			return xerrors.Errorf("delete organization: %w", err)
+		}
+		return nil
+	})
+	if database.IsForeignKeyViolation(err) {
+		httpapi.Write(rw, http.StatusPreconditionFailed, codersdk.Response{
+			Message: "All workspaces must be deleted or moved before an organization can be removed.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting organization.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(rw, http.StatusOK, codersdk.Response{
+		Message: "Organization has been deleted!",
+	})
+}
+
 // convertOrganization consumes the database representation and outputs an API friendly representation.
 func convertOrganization(organization database.Organization) codersdk.Organization {
 	return codersdk.Organization{
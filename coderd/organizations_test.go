@@ -110,3 +110,225 @@ func TestPostOrganizationsByUser(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestOrganizations(t *testing.T) {
+	t.Parallel()
+	t.Run("Owner", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		other := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "another",
+		})
+		require.NoError(t, err)
+
+		orgs, err := client.Organizations(ctx)
+		require.NoError(t, err)
+		require.Len(t, orgs, 2)
+
+		// Members only see the organizations they belong to.
+		orgs, err = other.Organizations(ctx)
+		require.NoError(t, err)
+		require.Len(t, orgs, 1)
+		require.Equal(t, first.OrganizationID, orgs[0].ID)
+	})
+}
+
+func TestPatchOrganization(t *testing.T) {
+	t.Parallel()
+	t.Run("Rename", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.UpdateOrganization(ctx, first.OrganizationID, codersdk.UpdateOrganizationRequest{
+			Name: "renamed",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "renamed", org.Name)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "another",
+		})
+		require.NoError(t, err)
+		_, err = client.UpdateOrganization(ctx, first.OrganizationID, codersdk.UpdateOrganizationRequest{
+			Name: org.Name,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+
+	t.Run("NotAdmin", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		other := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := other.UpdateOrganization(ctx, first.OrganizationID, codersdk.UpdateOrganizationRequest{
+			Name: "renamed",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
+func TestDeleteOrganization(t *testing.T) {
+	t.Parallel()
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "another",
+		})
+		require.NoError(t, err)
+		err = client.DeleteOrganization(ctx, org.ID)
+		require.NoError(t, err)
+
+		_, err = client.Organization(ctx, org.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("HasTemplates", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		first := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, first.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, first.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		err := client.DeleteOrganization(ctx, first.OrganizationID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusPreconditionFailed, apiErr.StatusCode())
+		require.Contains(t, apiErr.Detail, template.Name)
+	})
+
+	t.Run("DeletedTemplates", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "another",
+		})
+		require.NoError(t, err)
+		version := coderdtest.CreateTemplateVersion(t, client, org.ID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, org.ID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, org.ID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		// The soft-deleted workspace and template don't block the deletion.
+		build, err := client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionDelete,
+		})
+		require.NoError(t, err)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, build.ID)
+		err = client.DeleteTemplate(ctx, template.ID)
+		require.NoError(t, err)
+
+		err = client.DeleteOrganization(ctx, org.ID)
+		require.NoError(t, err)
+	})
+}
+
+func TestOrganizationMembers(t *testing.T) {
+	t.Parallel()
+	t.Run("AddAndRemove", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "another",
+		})
+		require.NoError(t, err)
+		other := coderdtest.CreateAnotherUser(t, client, org.ID)
+		otherUser, err := other.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+
+		members, err := client.OrganizationMembers(ctx, org.ID)
+		require.NoError(t, err)
+		require.Len(t, members, 2)
+
+		err = client.RemoveOrganizationMember(ctx, org.ID, otherUser.Username)
+		require.NoError(t, err)
+		members, err = client.OrganizationMembers(ctx, org.ID)
+		require.NoError(t, err)
+		require.Len(t, members, 1)
+		require.NotEqual(t, otherUser.ID, members[0].UserID)
+
+		member, err := client.AddOrganizationMember(ctx, org.ID, otherUser.Username)
+		require.NoError(t, err)
+		require.Equal(t, otherUser.ID, member.UserID)
+		members, err = client.OrganizationMembers(ctx, org.ID)
+		require.NoError(t, err)
+		require.Len(t, members, 2)
+	})
+
+	t.Run("AlreadyMember", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.AddOrganizationMember(ctx, first.OrganizationID, codersdk.Me)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+
+	t.Run("RemoveSelf", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		err := client.RemoveOrganizationMember(ctx, first.OrganizationID, codersdk.Me)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
@@ -0,0 +1,156 @@
+// Package prebuild determines the state of prebuilt workspaces. Templates
+// keep a pool of prebuilt workspaces ready, which are handed to users that
+// create a workspace with the template's parameter preset.
+package prebuild
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+)
+
+// State is the state of a prebuilt workspace.
+type State string
+
+const (
+	// StatePending prebuilds are still being built.
+	StatePending State = "pending"
+	// StateReady prebuilds can be claimed by users.
+	StateReady State = "ready"
+	// StateFailed prebuilds failed to build. They should be deleted.
+	StateFailed State = "failed"
+	// StateStale prebuilds were stopped, or no longer match the template.
+	// They should be deleted.
+	StateStale State = "stale"
+	// StateDeleting prebuilds are being deleted.
+	StateDeleting State = "deleting"
+)
+
+// Parameters returns the parameter values prebuilt workspaces of the
+// template are built with.
+func Parameters(template database.Template) ([]codersdk.CreateParameterRequest, error) {
+	parameters := []codersdk.CreateParameterRequest{}
+	if len(template.PrebuildParameters) == 0 {
+		return parameters, nil
+	}
+	err := json.Unmarshal(template.PrebuildParameters, &parameters)
+	if err != nil {
+		return nil, xerrors.Errorf("unmarshal prebuild parameters: %w", err)
+	}
+	return parameters, nil
+}
+
+// MatchesParameters returns whether values sets the same parameters to the
+// same values as the preset.
+func MatchesParameters(preset, values []codersdk.CreateParameterRequest) bool {
+	if len(preset) != len(values) {
+		return false
+	}
+	presetValues := make(map[string]string, len(preset))
+	for _, parameter := range preset {
+		presetValues[parameter.Name] = parameter.SourceValue
+	}
+	for _, parameter := range values {
+		value, ok := presetValues[parameter.Name]
+		if !ok || value != parameter.SourceValue {
+			return false
+		}
+		// Each preset value can only be matched once.
+		delete(presetValues, parameter.Name)
+	}
+	return len(presetValues) == 0
+}
+
+// Workspace is a prebuilt workspace with its latest build.
+type Workspace struct {
+	database.Workspace
+	Build database.WorkspaceBuild
+	Job   database.ProvisionerJob
+	State State
+}
+
+// GetWorkspaces returns the prebuilt workspaces of a template, oldest first.
+func GetWorkspaces(ctx context.Context, db database.Store, template database.Template) ([]Workspace, error) {
+	preset, err := Parameters(template)
+	if err != nil {
+		return nil, err
+	}
+	workspaces, err := db.GetPrebuiltWorkspacesByTemplateID(ctx, template.ID)
+	if err != nil {
+		return nil, xerrors.Errorf("get prebuilt workspaces: %w", err)
+	}
+
+	prebuilds := make([]Workspace, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		build, err := db.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+		if err != nil {
+			return nil, xerrors.Errorf("get latest workspace build: %w", err)
+		}
+		job, err := db.GetProvisionerJobByID(ctx, build.JobID)
+		if err != nil {
+			return nil, xerrors.Errorf("get provisioner job: %w", err)
+		}
+		state, err := getState(ctx, db, template, preset, workspace, build, job)
+		if err != nil {
+			return nil, err
+		}
+		prebuilds = append(prebuilds, Workspace{
+			Workspace: workspace,
+			Build:     build,
+			Job:       job,
+			State:     state,
+		})
+	}
+	return prebuilds, nil
+}
+
+func getState(
+	ctx context.Context,
+	db database.Store,
+	template database.Template,
+	preset []codersdk.CreateParameterRequest,
+	workspace database.Workspace,
+	build database.WorkspaceBuild,
+	job database.ProvisionerJob,
+) (State, error) {
+	if build.Transition == database.WorkspaceTransitionDelete {
+		return StateDeleting, nil
+	}
+	if !job.CompletedAt.Valid {
+		return StatePending, nil
+	}
+	if build.Transition == database.WorkspaceTransitionStart && (job.Error.Valid || job.CanceledAt.Valid) {
+		return StateFailed, nil
+	}
+	if job.Error.Valid || job.CanceledAt.Valid ||
+		build.Transition != database.WorkspaceTransitionStart ||
+		build.TemplateVersionID != template.ActiveVersionID {
+		return StateStale, nil
+	}
+
+	parameterValues, err := db.ParameterValues(ctx, database.ParameterValuesParams{
+		Scopes:   []database.ParameterScope{database.ParameterScopeWorkspace},
+		ScopeIds: []uuid.UUID{workspace.ID},
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", xerrors.Errorf("get workspace parameter values: %w", err)
+	}
+	values := make([]codersdk.CreateParameterRequest, 0, len(parameterValues))
+	for _, parameterValue := range parameterValues {
+		values = append(values, codersdk.CreateParameterRequest{
+			Name:        parameterValue.Name,
+			SourceValue: parameterValue.SourceValue,
+		})
+	}
+	if !MatchesParameters(preset, values) {
+		return StateStale, nil
+	}
+	return StateReady, nil
+}
@@ -0,0 +1,40 @@
+package prebuild_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/prebuild"
+	"github.com/coder/coder/codersdk"
+)
+
+func TestMatchesParameters(t *testing.T) {
+	t.Parallel()
+
+	value := func(name, value string) codersdk.CreateParameterRequest {
+		return codersdk.CreateParameterRequest{Name: name, SourceValue: value}
+	}
+	preset := []codersdk.CreateParameterRequest{value("region", "eu"), value("size", "large")}
+
+	for _, c := range []struct {
+		Name    string
+		Preset  []codersdk.CreateParameterRequest
+		Values  []codersdk.CreateParameterRequest
+		Matches bool
+	}{
+		{Name: "Empty", Matches: true},
+		{Name: "Same", Preset: preset, Values: preset, Matches: true},
+		{Name: "Reordered", Preset: preset, Values: []codersdk.CreateParameterRequest{value("size", "large"), value("region", "eu")}, Matches: true},
+		{Name: "DifferentValue", Preset: preset, Values: []codersdk.CreateParameterRequest{value("region", "us"), value("size", "large")}, Matches: false},
+		{Name: "Missing", Preset: preset, Values: []codersdk.CreateParameterRequest{value("region", "eu")}, Matches: false},
+		{Name: "Duplicate", Preset: preset, Values: []codersdk.CreateParameterRequest{value("region", "eu"), value("region", "eu")}, Matches: false},
+		{Name: "NoPreset", Values: preset, Matches: false},
+	} {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, c.Matches, prebuild.MatchesParameters(c.Preset, c.Values))
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/prebuild"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) templatePrebuilds(rw http.ResponseWriter, r *http.Request) {
+	template := httpmw.TemplateParam(r)
+	if !api.Authorize(r, rbac.ActionRead, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	prebuilds, err := convertTemplatePrebuilds(r.Context(), api.Database, template)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching prebuilt workspaces.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(rw, http.StatusOK, prebuilds)
+}
+
+func (api *API) putTemplatePrebuilds(rw http.ResponseWriter, r *http.Request) {
+	template := httpmw.TemplateParam(r)
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateTemplatePrebuildsRequest
+	if !httpapi.Read(rw, r, &req) {
+		return
+	}
+
+	var validErrs []codersdk.ValidationError
+	if req.Count < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "count", Detail: "Must be a positive integer."})
+	}
+	for _, parameterValue := range req.ParameterValues {
+		if parameterValue.Name == "" || parameterValue.CloneID != uuid.Nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "parameter_values", Detail: "Parameter values must have a name and cannot be copied from other parameters."})
+			break
+		}
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid request to update template prebuilds.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	prebuildParameters := template.PrebuildParameters
+	if req.ParameterValues != nil {
+		var err error
+		prebuildParameters, err = json.Marshal(req.ParameterValues)
+		if err != nil {
+			httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error marshaling parameter values.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+
+	var updated database.Template
+	err := api.Database.InTx(func(s database.Store) error {
+		err := s.UpdateTemplatePrebuildsByID(r.Context(), database.UpdateTemplatePrebuildsByIDParams{
+			ID:                 template.ID,
+			UpdatedAt:          database.Now(),
+			PrebuildCount:      req.Count,
+			PrebuildParameters: prebuildParameters,
+		})
+		if err != nil {
+			return xerrors.Errorf("update template prebuilds: %w", err)
+		}
+		updated, err = s.GetTemplateByID(r.Context(), template.ID)
+		if err != nil {
+			return xerrors.Errorf("get updated template: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating template prebuilds.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	prebuilds, err := convertTemplatePrebuilds(r.Context(), api.Database, updated)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching prebuilt workspaces.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(rw, http.StatusOK, prebuilds)
+}
+
+// claimPrebuiltWorkspace transfers a ready prebuilt workspace of the template
+// to the user. Prebuilt workspaces are only handed out when the requested
+// parameter values match the preset of the template. False is returned if no
+// prebuilt workspace could be claimed.
+//
+// Prebuilt workspaces were built for the template creator, so a start build
+// is queued as the new owner. It updates the resources that depend on the
+// owner, like the coder_workspace data source, and starts the deadline of the
+// workspace. The returned build and job are the ones of the new build.
+func (api *API) claimPrebuiltWorkspace(
+	ctx context.Context,
+	template database.Template,
+	ownerID uuid.UUID,
+	req codersdk.CreateWorkspaceRequest,
+	autostartSchedule sql.NullString,
+	ttl sql.NullInt64,
+) (database.Workspace, database.WorkspaceBuild, database.ProvisionerJob, bool, error) {
+	if template.PrebuildCount == 0 {
+		return database.Workspace{}, database.WorkspaceBuild{}, database.ProvisionerJob{}, false, nil
+	}
+	preset, err := prebuild.Parameters(template)
+	if err != nil {
+		return database.Workspace{}, database.WorkspaceBuild{}, database.ProvisionerJob{}, false, err
+	}
+	if !prebuild.MatchesParameters(preset, req.ParameterValues) {
+		return database.Workspace{}, database.WorkspaceBuild{}, database.ProvisionerJob{}, false, nil
+	}
+	prebuilds, err := prebuild.GetWorkspaces(ctx, api.Database, template)
+	if err != nil {
+		return database.Workspace{}, database.WorkspaceBuild{}, database.ProvisionerJob{}, false, err
+	}
+
+	for _, ws := range prebuilds {
+		if ws.State != prebuild.StateReady {
+			continue
+		}
+
+		var (
+			workspace database.Workspace
+			build     database.WorkspaceBuild
+			job       database.ProvisionerJob
+		)
+		err = api.Database.InTx(func(db database.Store) error {
+			now := database.Now()
+			workspace, err = db.ClaimPrebuiltWorkspace(ctx, database.ClaimPrebuiltWorkspaceParams{
+				ID:                ws.ID,
+				OwnerID:           ownerID,
+				Name:              req.Name,
+				AutostartSchedule: autostartSchedule,
+				Ttl:               ttl,
+				UpdatedAt:         now,
+				Preset:            req.Preset,
+			})
+			if err != nil {
+				return xerrors.Errorf("claim prebuilt workspace: %w", err)
+			}
+
+			workspaceBuildID := uuid.New()
+			input, err := json.Marshal(workspaceProvisionJob{
+				WorkspaceBuildID: workspaceBuildID,
+			})
+			if err != nil {
+				return xerrors.Errorf("marshal provision job: %w", err)
+			}
+			job, err = db.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+				ID:             uuid.New(),
+				CreatedAt:      now,
+				UpdatedAt:      now,
+				InitiatorID:    ownerID,
+				OrganizationID: template.OrganizationID,
+				Provisioner:    template.Provisioner,
+				Type:           database.ProvisionerJobTypeWorkspaceBuild,
+				StorageMethod:  ws.Job.StorageMethod,
+				StorageSource:  ws.Job.StorageSource,
+				Input:          input,
+			})
+			if err != nil {
+				return xerrors.Errorf("insert provisioner job: %w", err)
+			}
+			build, err = db.InsertWorkspaceBuild(ctx, database.InsertWorkspaceBuildParams{
+				ID:                workspaceBuildID,
+				CreatedAt:         now,
+				UpdatedAt:         now,
+				WorkspaceID:       workspace.ID,
+				TemplateVersionID: ws.Build.TemplateVersionID,
+				BuildNumber:       ws.Build.BuildNumber + 1,
+				Name:              namesgenerator.GetRandomName(1),
+				ProvisionerState:  ws.Build.ProvisionerState,
+				InitiatorID:       ownerID,
+				Transition:        database.WorkspaceTransitionStart,
+				JobID:             job.ID,
+				Reason:            database.BuildReasonInitiator,
+			})
+			if err != nil {
+				return xerrors.Errorf("insert workspace build: %w", err)
+			}
+			return nil
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			// Claimed by someone else in the meantime.
+			continue
+		}
+		if err != nil {
+			return database.Workspace{}, database.WorkspaceBuild{}, database.ProvisionerJob{}, false, err
+		}
+		return workspace, build, job, true, nil
+	}
+	return database.Workspace{}, database.WorkspaceBuild{}, database.ProvisionerJob{}, false, nil
+}
+
+func convertTemplatePrebuilds(ctx context.Context, db database.Store, template database.Template) (codersdk.TemplatePrebuilds, error) {
+	preset, err := prebuild.Parameters(template)
+	if err != nil {
+		return codersdk.TemplatePrebuilds{}, err
+	}
+	prebuilds, err := prebuild.GetWorkspaces(ctx, db, template)
+	if err != nil {
+		return codersdk.TemplatePrebuilds{}, xerrors.Errorf("get prebuilt workspaces: %w", err)
+	}
+
+	converted := codersdk.TemplatePrebuilds{
+		Count:           template.PrebuildCount,
+		ParameterValues: preset,
+	}
+	for _, ws := range prebuilds {
+		switch ws.State {
+		case prebuild.StateReady:
+			converted.Ready++
+		case prebuild.StatePending:
+			converted.Pending++
+		}
+	}
+	return converted, nil
+}
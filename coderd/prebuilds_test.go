@@ -0,0 +1,142 @@
+package coderd_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/autobuild/executor"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplatePrebuilds(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Update", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		prebuilds, err := client.TemplatePrebuilds(ctx, template.ID)
+		require.NoError(t, err)
+		require.Zero(t, prebuilds.Count)
+		require.Empty(t, prebuilds.ParameterValues)
+
+		parameterValues := []codersdk.CreateParameterRequest{{
+			Name:              "region",
+			SourceValue:       "eu",
+			SourceScheme:      codersdk.ParameterSourceSchemeData,
+			DestinationScheme: codersdk.ParameterDestinationSchemeProvisionerVariable,
+		}}
+		prebuilds, err = client.UpdateTemplatePrebuilds(ctx, template.ID, codersdk.UpdateTemplatePrebuildsRequest{
+			Count:           3,
+			ParameterValues: parameterValues,
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, 3, prebuilds.Count)
+		require.Equal(t, parameterValues, prebuilds.ParameterValues)
+
+		// Parameter values are kept when only the count changes.
+		prebuilds, err = client.UpdateTemplatePrebuilds(ctx, template.ID, codersdk.UpdateTemplatePrebuildsRequest{Count: 1})
+		require.NoError(t, err)
+		require.EqualValues(t, 1, prebuilds.Count)
+		require.Equal(t, parameterValues, prebuilds.ParameterValues)
+
+		updated, err := client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, updated.PrebuildCount)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.UpdateTemplatePrebuilds(ctx, template.ID, codersdk.UpdateTemplatePrebuildsRequest{Count: -1})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		_, err = client.UpdateTemplatePrebuilds(ctx, template.ID, codersdk.UpdateTemplatePrebuildsRequest{
+			Count: 1,
+			ParameterValues: []codersdk.CreateParameterRequest{{
+				CloneID: uuid.New(),
+			}},
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
+
+func TestClaimPrebuiltWorkspace(t *testing.T) {
+	t.Parallel()
+
+	tickCh := make(chan time.Time)
+	statsCh := make(chan executor.Stats)
+	client := coderdtest.New(t, &coderdtest.Options{
+		AutobuildTicker:     tickCh,
+		IncludeProvisionerD: true,
+		AutobuildStats:      statsCh,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	_, err := client.UpdateTemplatePrebuilds(ctx, template.ID, codersdk.UpdateTemplatePrebuildsRequest{Count: 1})
+	require.NoError(t, err)
+
+	go func() {
+		tickCh <- time.Now()
+		close(tickCh)
+	}()
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.Transitions, 1)
+	var prebuiltID uuid.UUID
+	for id := range stats.Transitions {
+		prebuiltID = id
+	}
+	prebuilt := coderdtest.MustWorkspace(t, client, prebuiltID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, prebuilt.LatestBuild.ID)
+
+	// Creating a workspace with the preset hands out the prebuilt workspace.
+	memberClient, member := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+	workspace := coderdtest.CreateWorkspace(t, memberClient, user.OrganizationID, template.ID)
+	require.Equal(t, prebuiltID, workspace.ID)
+	require.Equal(t, member.ID, workspace.OwnerID)
+	require.False(t, workspace.Prebuild)
+	// The workspace is rebuilt as the new owner, so nothing refers to the
+	// template creator anymore.
+	require.NotEqual(t, prebuilt.LatestBuild.ID, workspace.LatestBuild.ID)
+	require.EqualValues(t, prebuilt.LatestBuild.BuildNumber+1, workspace.LatestBuild.BuildNumber)
+	require.Equal(t, codersdk.WorkspaceTransitionStart, workspace.LatestBuild.Transition)
+	require.Equal(t, member.ID, workspace.LatestBuild.InitiatorID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+	workspace = coderdtest.MustWorkspace(t, client, workspace.ID)
+	require.WithinDuration(t, time.Now().Add(8*time.Hour), workspace.LatestBuild.Deadline.Time, time.Minute)
+
+	// The pool is empty, so the next workspace is built normally.
+	workspace = coderdtest.CreateWorkspace(t, memberClient, user.OrganizationID, template.ID)
+	require.NotEqual(t, prebuiltID, workspace.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+}
@@ -0,0 +1,120 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) templateVersionPresets(rw http.ResponseWriter, r *http.Request) {
+	templateVersion := httpmw.TemplateVersionParam(r)
+	if !api.Authorize(r, rbac.ActionRead, templateVersion) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	presets, err := api.Database.GetTemplateVersionPresets(r.Context(), templateVersion.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version presets.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	apiPresets := make([]codersdk.TemplateVersionPreset, 0, len(presets))
+	for _, preset := range presets {
+		apiPreset, err := convertTemplateVersionPreset(preset)
+		if err != nil {
+			httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error converting template version preset.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		apiPresets = append(apiPresets, apiPreset)
+	}
+	httpapi.Write(rw, http.StatusOK, apiPresets)
+}
+
+// getTemplateVersionPreset returns the preset of a template version by name.
+// False is returned if the version has no such preset.
+func getTemplateVersionPreset(ctx context.Context, db database.Store, templateVersionID uuid.UUID, name string) (codersdk.TemplateVersionPreset, bool, error) {
+	presets, err := db.GetTemplateVersionPresets(ctx, templateVersionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return codersdk.TemplateVersionPreset{}, false, nil
+	}
+	if err != nil {
+		return codersdk.TemplateVersionPreset{}, false, xerrors.Errorf("get template version presets: %w", err)
+	}
+	for _, preset := range presets {
+		if preset.Name != name {
+			continue
+		}
+		converted, err := convertTemplateVersionPreset(preset)
+		if err != nil {
+			return codersdk.TemplateVersionPreset{}, false, err
+		}
+		return converted, true, nil
+	}
+	return codersdk.TemplateVersionPreset{}, false, nil
+}
+
+// withPresetParameterValues adds the values of a preset to values. Parameters
+// that are already set in values keep their value.
+func withPresetParameterValues(ctx context.Context, db database.Store, templateVersion database.TemplateVersion, preset codersdk.TemplateVersionPreset, values []codersdk.CreateParameterRequest) ([]codersdk.CreateParameterRequest, error) {
+	schemas, err := db.GetParameterSchemasByJobID(ctx, templateVersion.JobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("get parameter schemas: %w", err)
+	}
+
+SchemaLoop:
+	for _, schema := range schemas {
+		value, ok := preset.Parameters[schema.Name]
+		if !ok || !schema.AllowOverrideSource {
+			continue
+		}
+		for _, existing := range values {
+			if existing.Name == schema.Name {
+				continue SchemaLoop
+			}
+		}
+		values = append(values, codersdk.CreateParameterRequest{
+			Name:              schema.Name,
+			SourceValue:       value,
+			SourceScheme:      codersdk.ParameterSourceSchemeData,
+			DestinationScheme: codersdk.ParameterDestinationScheme(schema.DefaultDestinationScheme),
+		})
+	}
+	return values, nil
+}
+
+func convertTemplateVersionPreset(preset database.TemplateVersionPreset) (codersdk.TemplateVersionPreset, error) {
+	parameters := map[string]string{}
+	err := json.Unmarshal(preset.Parameters, &parameters)
+	if err != nil {
+		return codersdk.TemplateVersionPreset{}, xerrors.Errorf("unmarshal parameters of preset %q: %w", preset.Name, err)
+	}
+	return codersdk.TemplateVersionPreset{
+		Name:        preset.Name,
+		Description: preset.Description,
+		Parameters:  parameters,
+	}, nil
+}
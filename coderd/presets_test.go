@@ -0,0 +1,237 @@
+package coderd_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionerd/runner"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateVersionPresets(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+	user := coderdtest.CreateFirstUser(t, client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	version := createPresetTemplateVersion(ctx, t, client, user.OrganizationID, uuid.Nil, "")
+	presets, err := client.TemplateVersionPresets(ctx, version.ID)
+	require.NoError(t, err)
+	require.Empty(t, presets)
+
+	version = createPresetTemplateVersion(ctx, t, client, user.OrganizationID, uuid.Nil, `- name: small
+  description: A small workspace
+  parameters:
+    cpu: 2
+- name: large
+  parameters:
+    cpu: 8
+    region: eu`)
+	presets, err = client.TemplateVersionPresets(ctx, version.ID)
+	require.NoError(t, err)
+	require.Equal(t, []codersdk.TemplateVersionPreset{{
+		Name:        "small",
+		Description: "A small workspace",
+		Parameters:  map[string]string{"cpu": "2"},
+	}, {
+		Name:       "large",
+		Parameters: map[string]string{"cpu": "8", "region": "eu"},
+	}}, presets)
+}
+
+func TestWorkspacePreset(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Create", func(t *testing.T) {
+		t.Parallel()
+		client, db := newTestClientWithDatabase(t)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		version := createPresetTemplateVersion(ctx, t, client, user.OrganizationID, uuid.Nil, `- name: large
+  parameters:
+    cpu: 8
+    region: eu`)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		// Values that are set explicitly take precedence over the preset.
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(req *codersdk.CreateWorkspaceRequest) {
+			req.Preset = "large"
+			req.ParameterValues = []codersdk.CreateParameterRequest{{
+				Name:              "region",
+				SourceValue:       "us",
+				SourceScheme:      codersdk.ParameterSourceSchemeData,
+				DestinationScheme: codersdk.ParameterDestinationSchemeProvisionerVariable,
+			}}
+		})
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		require.Equal(t, "large", workspace.Preset)
+		require.Equal(t, map[string]string{"cpu": "8", "region": "us"}, workspaceParameterValues(ctx, t, db, workspace.ID))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		version := createPresetTemplateVersion(ctx, t, client, user.OrganizationID, uuid.Nil, "")
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		_, err := client.CreateWorkspace(ctx, user.OrganizationID, codersdk.CreateWorkspaceRequest{
+			TemplateID: template.ID,
+			Name:       "example",
+			Preset:     "large",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		t.Parallel()
+		client, db := newTestClientWithDatabase(t)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		version := createPresetTemplateVersion(ctx, t, client, user.OrganizationID, uuid.Nil, `- name: large
+  parameters:
+    cpu: 8`)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(req *codersdk.CreateWorkspaceRequest) {
+			req.Preset = "large"
+		})
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		// The template author made the preset larger.
+		version = createPresetTemplateVersion(ctx, t, client, user.OrganizationID, template.ID, `- name: large
+  parameters:
+    cpu: 16`)
+		build, err := client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			TemplateVersionID: version.ID,
+			Transition:        codersdk.WorkspaceTransitionStart,
+		})
+		require.NoError(t, err)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, build.ID)
+		require.Equal(t, "16", workspaceParameterValues(ctx, t, db, workspace.ID)["cpu"])
+	})
+}
+
+// createPresetTemplateVersion creates a template version with the "cpu" and
+// "region" parameters and the given presets file.
+func createPresetTemplateVersion(ctx context.Context, t *testing.T, client *codersdk.Client, organizationID, templateID uuid.UUID, presets string) codersdk.TemplateVersion {
+	t.Helper()
+	schema := func(name, value string) *proto.ParameterSchema {
+		return &proto.ParameterSchema{
+			Name:                name,
+			AllowOverrideSource: true,
+			DefaultSource: &proto.ParameterSource{
+				Scheme: proto.ParameterSource_DATA,
+				Value:  value,
+			},
+			DefaultDestination: &proto.ParameterDestination{
+				Scheme: proto.ParameterDestination_PROVISIONER_VARIABLE,
+			},
+		}
+	}
+	data, err := echo.Tar(&echo.Responses{
+		Parse: []*proto.Parse_Response{{
+			Type: &proto.Parse_Response_Complete{
+				Complete: &proto.Parse_Complete{
+					ParameterSchemas: []*proto.ParameterSchema{schema("cpu", "1"), schema("region", "us")},
+				},
+			},
+		}},
+		Provision: echo.ProvisionComplete,
+	})
+	require.NoError(t, err)
+
+	// Add the presets file to the archive of the echo provisioner.
+	var buffer bytes.Buffer
+	writer := tar.NewWriter(&buffer)
+	reader := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteHeader(header))
+		_, err = io.Copy(writer, reader)
+		require.NoError(t, err)
+	}
+	if presets != "" {
+		err = writer.WriteHeader(&tar.Header{
+			Name: runner.PresetsFile,
+			Mode: 0o644,
+			Size: int64(len(presets)),
+		})
+		require.NoError(t, err)
+		_, err = writer.Write([]byte(presets))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	file, err := client.Upload(ctx, codersdk.ContentTypeTar, buffer.Bytes())
+	require.NoError(t, err)
+	version, err := client.CreateTemplateVersion(ctx, organizationID, codersdk.CreateTemplateVersionRequest{
+		TemplateID:    templateID,
+		StorageMethod: codersdk.ProvisionerStorageMethodFile,
+		StorageSource: file.Hash,
+		Provisioner:   codersdk.ProvisionerTypeEcho,
+	})
+	require.NoError(t, err)
+	version = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	require.Equal(t, codersdk.ProvisionerJobSucceeded, version.Job.Status, version.Job.Error)
+	return version
+}
+
+// newTestClientWithDatabase returns a client with a provisioner daemon, and
+// the database of its API for state the API doesn't return.
+func newTestClientWithDatabase(t *testing.T) (*codersdk.Client, database.Store) {
+	t.Helper()
+	var db database.Store
+	client := coderdtest.New(t, &coderdtest.Options{
+		IncludeProvisionerD: true,
+		APIBuilder: func(options *coderd.Options) *coderd.API {
+			db = options.Database
+			return coderd.New(options)
+		},
+	})
+	return client, db
+}
+
+func workspaceParameterValues(ctx context.Context, t *testing.T, db database.Store, workspaceID uuid.UUID) map[string]string {
+	t.Helper()
+	parameters, err := db.ParameterValues(ctx, database.ParameterValuesParams{
+		Scopes:   []database.ParameterScope{database.ParameterScopeWorkspace},
+		ScopeIds: []uuid.UUID{workspaceID},
+	})
+	require.NoError(t, err)
+	values := make(map[string]string, len(parameters))
+	for _, parameter := range parameters {
+		values[parameter.Name] = parameter.SourceValue
+	}
+	return values
+}
@@ -22,6 +22,7 @@ import (
 
 	"cdr.dev/slog"
 
+	"github.com/coder/coder/coderd/blobstore"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbtypes"
 	"github.com/coder/coder/coderd/httpapi"
@@ -89,6 +90,7 @@ func (api *API) ListenProvisionerDaemon(ctx context.Context) (client proto.DRPCP
 		AccessURL:    api.AccessURL,
 		ID:           daemon.ID,
 		Database:     api.Database,
+		BlobStore:    api.BlobStore,
 		Pubsub:       api.Pubsub,
 		Provisioners: daemon.Provisioners,
 		Telemetry:    api.Telemetry,
@@ -138,6 +140,7 @@ type provisionerdServer struct {
 	Logger       slog.Logger
 	Provisioners []database.ProvisionerType
 	Database     database.Store
+	BlobStore    blobstore.Store
 	Pubsub       database.Pubsub
 	Telemetry    telemetry.Reporter
 }
@@ -247,6 +250,10 @@ func (server *provisionerdServer) AcquireJob(ctx context.Context, _ *proto.Empty
 		if err != nil {
 			return nil, failJob(fmt.Sprintf("convert computed parameters to protobuf: %s", err))
 		}
+		protoParameters, err = appendTemplateVersionVariables(ctx, server.Database, templateVersion.ID, protoParameters)
+		if err != nil {
+			return nil, failJob(fmt.Sprintf("get template version variables: %s", err))
+		}
 		transition, err := convertWorkspaceTransition(workspaceBuild.Transition)
 		if err != nil {
 			return nil, failJob(fmt.Sprintf("convert workspace transition: %s", err))
@@ -299,6 +306,10 @@ func (server *provisionerdServer) AcquireJob(ctx context.Context, _ *proto.Empty
 		if err != nil {
 			return nil, failJob(fmt.Sprintf("convert computed parameters to protobuf: %s", err))
 		}
+		protoParameters, err = appendTemplateVersionVariables(ctx, server.Database, templateVersion.ID, protoParameters)
+		if err != nil {
+			return nil, failJob(fmt.Sprintf("get template version variables: %s", err))
+		}
 
 		protoJob.Type = &proto.AcquiredJob_TemplateDryRun_{
 			TemplateDryRun: &proto.AcquiredJob_TemplateDryRun{
@@ -318,13 +329,10 @@ func (server *provisionerdServer) AcquireJob(ctx context.Context, _ *proto.Empty
 			},
 		}
 	}
+	// The source archive isn't sent with the job. Provisioners stream it
+	// with ReadTemplateSource instead.
 	switch job.StorageMethod {
 	case database.ProvisionerStorageMethodFile:
-		file, err := server.Database.GetFileByHash(ctx, job.StorageSource)
-		if err != nil {
-			return nil, failJob(fmt.Sprintf("get file by hash: %s", err))
-		}
-		protoJob.TemplateSourceArchive = file.Data
 	default:
 		return nil, failJob(fmt.Sprintf("unsupported storage method: %s", job.StorageMethod))
 	}
@@ -401,6 +409,24 @@ func (server *provisionerdServer) UpdateJob(ctx context.Context, request *proto.
 		server.Logger.Debug(ctx, "published job logs", slog.F("job_id", parsedID))
 	}
 
+	if len(request.Timings) > 0 {
+		insertParams := database.InsertProvisionerJobTimingsParams{
+			JobID: parsedID,
+		}
+		for _, timing := range request.Timings {
+			insertParams.ID = append(insertParams.ID, uuid.New())
+			insertParams.Stage = append(insertParams.Stage, timing.Stage)
+			insertParams.Resource = append(insertParams.Resource, timing.Resource)
+			insertParams.Action = append(insertParams.Action, timing.Action)
+			insertParams.StartedAt = append(insertParams.StartedAt, time.UnixMilli(timing.StartedAt))
+			insertParams.EndedAt = append(insertParams.EndedAt, time.UnixMilli(timing.EndedAt))
+		}
+		_, err := server.Database.InsertProvisionerJobTimings(ctx, insertParams)
+		if err != nil {
+			return nil, xerrors.Errorf("insert job timings: %w", err)
+		}
+	}
+
 	if len(request.Readme) > 0 {
 		err := server.Database.UpdateTemplateVersionDescriptionByJobID(ctx, database.UpdateTemplateVersionDescriptionByJobIDParams{
 			JobID:     job.ID,
@@ -413,7 +439,46 @@ func (server *provisionerdServer) UpdateJob(ctx context.Context, request *proto.
 	}
 
 	if len(request.ParameterSchemas) > 0 {
+		var templateVersion database.TemplateVersion
+		if job.Type == database.ProvisionerJobTypeTemplateVersionImport {
+			templateVersion, err = server.Database.GetTemplateVersionByJobID(ctx, job.ID)
+			if err != nil {
+				return nil, xerrors.Errorf("get template version by job id: %w", err)
+			}
+		}
+		variables, err := server.Database.GetTemplateVersionVariables(ctx, templateVersion.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, xerrors.Errorf("get template version variables: %w", err)
+		}
+		// Template variables are set by template admins, so users are never
+		// prompted for them. Variables the template doesn't declare are
+		// removed, since Terraform refuses values for undeclared variables.
+		declaredVariables := make([]database.TemplateVersionVariable, 0, len(variables))
+		for _, variable := range variables {
+			declared := false
+			for _, protoParameter := range request.ParameterSchemas {
+				if protoParameter.Name == variable.Name {
+					declared = true
+					break
+				}
+			}
+			if declared {
+				declaredVariables = append(declaredVariables, variable)
+				continue
+			}
+			err = server.Database.DeleteTemplateVersionVariable(ctx, database.DeleteTemplateVersionVariableParams{
+				TemplateVersionID: variable.TemplateVersionID,
+				Name:              variable.Name,
+			})
+			if err != nil {
+				return nil, xerrors.Errorf("delete undeclared template variable %q: %w", variable.Name, err)
+			}
+		}
+
 		for index, protoParameter := range request.ParameterSchemas {
+			if isTemplateVersionVariable(declaredVariables, protoParameter.Name) {
+				continue
+			}
 			validationTypeSystem, err := convertValidationTypeSystem(protoParameter.ValidationTypeSystem)
 			if err != nil {
 				return nil, xerrors.Errorf("convert validation type system for %q: %w", protoParameter.Name, err)
@@ -465,18 +530,9 @@ func (server *provisionerdServer) UpdateJob(ctx context.Context, request *proto.
 			}
 		}
 
-		var templateID uuid.NullUUID
-		if job.Type == database.ProvisionerJobTypeTemplateVersionImport {
-			templateVersion, err := server.Database.GetTemplateVersionByJobID(ctx, job.ID)
-			if err != nil {
-				return nil, xerrors.Errorf("get template version by job id: %w", err)
-			}
-			templateID = templateVersion.TemplateID
-		}
-
 		parameters, err := parameter.Compute(ctx, server.Database, parameter.ComputeScope{
 			TemplateImportJobID: job.ID,
-			TemplateID:          templateID,
+			TemplateID:          templateVersion.TemplateID,
 			OrganizationID:      job.OrganizationID,
 			UserID:              job.InitiatorID,
 		}, nil)
@@ -492,6 +548,7 @@ func (server *provisionerdServer) UpdateJob(ctx context.Context, request *proto.
 			}
 			protoParameters = append(protoParameters, converted)
 		}
+		protoParameters = mergeTemplateVersionVariables(protoParameters, declaredVariables)
 
 		return &proto.UpdateJobResponse{
 			Canceled:        job.CanceledAt.Valid,
@@ -504,6 +561,53 @@ func (server *provisionerdServer) UpdateJob(ctx context.Context, request *proto.
 	}, nil
 }
 
+// templateSourceChunkSize is the size of the chunks source archives are
+// streamed to provisioners in.
+const templateSourceChunkSize = 1 << 20
+
+func (server *provisionerdServer) ReadTemplateSource(request *proto.TemplateSourceRequest, stream proto.DRPCProvisionerDaemon_ReadTemplateSourceStream) error {
+	ctx := stream.Context()
+	jobID, err := uuid.Parse(request.JobId)
+	if err != nil {
+		return xerrors.Errorf("parse job id: %w", err)
+	}
+	job, err := server.Database.GetProvisionerJobByID(ctx, jobID)
+	if err != nil {
+		return xerrors.Errorf("get provisioner job: %w", err)
+	}
+	if job.WorkerID.UUID.String() != server.ID.String() {
+		return xerrors.New("you don't own this job")
+	}
+	if job.CompletedAt.Valid {
+		return xerrors.Errorf("job already completed")
+	}
+	if job.StorageMethod != database.ProvisionerStorageMethodFile {
+		return xerrors.Errorf("unsupported storage method: %s", job.StorageMethod)
+	}
+
+	reader, err := server.BlobStore.Get(ctx, job.StorageSource)
+	if err != nil {
+		return xerrors.Errorf("read file %q: %w", job.StorageSource, err)
+	}
+	defer reader.Close()
+	buffer := make([]byte, templateSourceChunkSize)
+	for {
+		n, err := io.ReadFull(reader, buffer)
+		if n > 0 {
+			sendErr := stream.Send(&proto.TemplateSourceChunk{Data: buffer[:n]})
+			if sendErr != nil {
+				return xerrors.Errorf("send chunk: %w", sendErr)
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return xerrors.Errorf("read file %q: %w", job.StorageSource, err)
+		}
+	}
+}
+
 func (server *provisionerdServer) FailJob(ctx context.Context, failJob *proto.FailedJob) (*proto.Empty, error) {
 	jobID, err := uuid.Parse(failJob.JobId)
 	if err != nil {
@@ -559,6 +663,10 @@ func (server *provisionerdServer) FailJob(ctx context.Context, failJob *proto.Fa
 			return nil, xerrors.Errorf("update workspace build state: %w", err)
 		}
 	case *proto.FailedJob_TemplateImport_:
+		err = updateTemplateVersionDiagnostics(ctx, server.Database, jobID, jobType.TemplateImport.Diagnostics)
+		if err != nil {
+			return nil, xerrors.Errorf("update template version diagnostics: %w", err)
+		}
 	}
 
 	data, err := json.Marshal(provisionerJobLogsMessage{EndOfLogs: true})
@@ -611,6 +719,14 @@ func (server *provisionerdServer) CompleteJob(ctx context.Context, completed *pr
 				}
 			}
 		}
+		err = updateTemplateVersionDiagnostics(ctx, server.Database, jobID, jobType.TemplateImport.Diagnostics)
+		if err != nil {
+			return nil, xerrors.Errorf("update template version diagnostics: %w", err)
+		}
+		err = insertTemplateVersionPresets(ctx, server.Database, jobID, jobType.TemplateImport.Presets)
+		if err != nil {
+			return nil, xerrors.Errorf("insert template version presets: %w", err)
+		}
 
 		err = server.Database.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
 			ID:        jobID,
@@ -746,6 +862,72 @@ func (server *provisionerdServer) CompleteJob(ctx context.Context, completed *pr
 	return &proto.Empty{}, nil
 }
 
+// updateTemplateVersionDiagnostics records the diagnostics found while
+// importing a template version.
+func updateTemplateVersionDiagnostics(ctx context.Context, db database.Store, jobID uuid.UUID, protoDiagnostics []*sdkproto.Diagnostic) error {
+	if len(protoDiagnostics) == 0 {
+		return nil
+	}
+	diagnostics := make([]codersdk.TemplateVersionDiagnostic, 0, len(protoDiagnostics))
+	for _, diagnostic := range protoDiagnostics {
+		diagnostics = append(diagnostics, convertDiagnostic(diagnostic))
+	}
+	data, err := json.Marshal(diagnostics)
+	if err != nil {
+		return xerrors.Errorf("marshal diagnostics: %w", err)
+	}
+	return db.UpdateTemplateVersionDiagnosticsByJobID(ctx, database.UpdateTemplateVersionDiagnosticsByJobIDParams{
+		JobID:       jobID,
+		Diagnostics: data,
+		UpdatedAt:   database.Now(),
+	})
+}
+
+func insertTemplateVersionPresets(ctx context.Context, db database.Store, jobID uuid.UUID, presets []*proto.TemplatePreset) error {
+	if len(presets) == 0 {
+		return nil
+	}
+	templateVersion, err := db.GetTemplateVersionByJobID(ctx, jobID)
+	if err != nil {
+		return xerrors.Errorf("get template version by job id: %w", err)
+	}
+	for index, preset := range presets {
+		parameters := preset.Parameters
+		if parameters == nil {
+			parameters = map[string]string{}
+		}
+		data, err := json.Marshal(parameters)
+		if err != nil {
+			return xerrors.Errorf("marshal preset parameters: %w", err)
+		}
+		_, err = db.InsertTemplateVersionPreset(ctx, database.InsertTemplateVersionPresetParams{
+			TemplateVersionID: templateVersion.ID,
+			Name:              preset.Name,
+			Description:       preset.Description,
+			Parameters:        data,
+			Index:             int32(index),
+		})
+		if err != nil {
+			return xerrors.Errorf("insert preset %q: %w", preset.Name, err)
+		}
+	}
+	return nil
+}
+
+func convertDiagnostic(diagnostic *sdkproto.Diagnostic) codersdk.TemplateVersionDiagnostic {
+	severity := codersdk.TemplateVersionDiagnosticSeverityWarning
+	if diagnostic.Severity == sdkproto.Diagnostic_ERROR {
+		severity = codersdk.TemplateVersionDiagnosticSeverityError
+	}
+	return codersdk.TemplateVersionDiagnostic{
+		Severity: severity,
+		Summary:  diagnostic.Summary,
+		Detail:   diagnostic.Detail,
+		Filename: diagnostic.Filename,
+		Line:     int(diagnostic.Line),
+	}
+}
+
 func insertWorkspaceResource(ctx context.Context, db database.Store, jobID uuid.UUID, transition database.WorkspaceTransition, protoResource *sdkproto.Resource, snapshot *telemetry.Snapshot) error {
 	resource, err := db.InsertWorkspaceResource(ctx, database.InsertWorkspaceResourceParams{
 		ID:         uuid.New(),
@@ -948,6 +1130,50 @@ func convertComputedParameterValue(param parameter.ComputedValue) (*sdkproto.Par
 	}, nil
 }
 
+// appendTemplateVersionVariables adds the template variables of a template
+// version to the values passed to the provisioner.
+func appendTemplateVersionVariables(ctx context.Context, db database.Store, templateVersionID uuid.UUID, protoParameters []*sdkproto.ParameterValue) ([]*sdkproto.ParameterValue, error) {
+	variables, err := db.GetTemplateVersionVariables(ctx, templateVersionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return protoParameters, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return mergeTemplateVersionVariables(protoParameters, variables), nil
+}
+
+// mergeTemplateVersionVariables passes template variables to the provisioner
+// as Terraform variables. Template variables take precedence over parameter
+// values with the same name.
+func mergeTemplateVersionVariables(protoParameters []*sdkproto.ParameterValue, variables []database.TemplateVersionVariable) []*sdkproto.ParameterValue {
+	merged := make([]*sdkproto.ParameterValue, 0, len(protoParameters)+len(variables))
+	for _, protoParameter := range protoParameters {
+		if protoParameter.DestinationScheme == sdkproto.ParameterDestination_PROVISIONER_VARIABLE &&
+			isTemplateVersionVariable(variables, protoParameter.Name) {
+			continue
+		}
+		merged = append(merged, protoParameter)
+	}
+	for _, variable := range variables {
+		merged = append(merged, &sdkproto.ParameterValue{
+			DestinationScheme: sdkproto.ParameterDestination_PROVISIONER_VARIABLE,
+			Name:              variable.Name,
+			Value:             variable.Value,
+		})
+	}
+	return merged
+}
+
+func isTemplateVersionVariable(variables []database.TemplateVersionVariable, name string) bool {
+	for _, variable := range variables {
+		if variable.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func convertWorkspaceTransition(transition database.WorkspaceTransition) (sdkproto.WorkspaceTransition, error) {
 	switch transition {
 	case database.WorkspaceTransitionStart:
@@ -305,6 +305,20 @@ func convertProvisionerJobLog(provisionerJobLog database.ProvisionerJobLog) code
 	}
 }
 
+func convertProvisionerJobTimings(provisionerJobTimings []database.ProvisionerJobTiming) []codersdk.ProvisionerTiming {
+	sdk := make([]codersdk.ProvisionerTiming, 0, len(provisionerJobTimings))
+	for _, timing := range provisionerJobTimings {
+		sdk = append(sdk, codersdk.ProvisionerTiming{
+			Stage:     timing.Stage,
+			Resource:  timing.Resource,
+			Action:    timing.Action,
+			StartedAt: timing.StartedAt,
+			EndedAt:   timing.EndedAt,
+		})
+	}
+	return sdk
+}
+
 func convertProvisionerJob(provisionerJob database.ProvisionerJob) codersdk.ProvisionerJob {
 	job := codersdk.ProvisionerJob{
 		ID:            provisionerJob.ID,
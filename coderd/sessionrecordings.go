@@ -0,0 +1,272 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) postWorkspaceAgentSessionRecording(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx            = r.Context()
+		workspaceAgent = httpmw.WorkspaceAgent(r)
+		req            agent.SessionRecording
+	)
+	if !httpapi.Read(rw, r, &req) {
+		return
+	}
+
+	var recordingType database.SessionRecordingType
+	switch req.Type {
+	case agent.SessionRecordingTypeSSH:
+		recordingType = database.SessionRecordingTypeSsh
+	case agent.SessionRecordingTypeReconnectingPTY:
+		recordingType = database.SessionRecordingTypeReconnectingPty
+	default:
+		httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Unsupported session recording type %q.", req.Type),
+		})
+		return
+	}
+	if req.EndedAt.Before(req.StartedAt) {
+		httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Session recording can't end before it started.",
+		})
+		return
+	}
+
+	workspace, template, err := api.workspaceAndTemplateByAgent(ctx, workspaceAgent)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !template.RecordSessions {
+		httpapi.Write(rw, http.StatusForbidden, codersdk.Response{
+			Message: "Session recording is disabled for this template.",
+		})
+		return
+	}
+
+	err = api.Database.InTx(func(store database.Store) error {
+		// Recordings are attributed to the user the token was issued to.
+		// Sessions of clients that didn't send one, like OpenSSH, fall
+		// back to the workspace owner.
+		userID := workspace.OwnerID
+		if req.Token != uuid.Nil {
+			token, err := store.GetWorkspaceSessionRecordingTokenByID(ctx, req.Token)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return xerrors.Errorf("get session recording token: %w", err)
+			}
+			if err == nil && token.AgentID == workspaceAgent.ID {
+				userID = token.UserID
+				// Tokens are only good for a single recording.
+				err = store.DeleteWorkspaceSessionRecordingTokenByID(ctx, token.ID)
+				if err != nil {
+					return xerrors.Errorf("delete session recording token: %w", err)
+				}
+			} else {
+				api.Logger.Warn(ctx, "agent sent invalid session recording token",
+					slog.F("agent_id", workspaceAgent.ID),
+					slog.F("token", req.Token),
+				)
+			}
+		}
+
+		_, err := store.InsertWorkspaceSessionRecording(ctx, database.InsertWorkspaceSessionRecordingParams{
+			ID:          uuid.New(),
+			CreatedAt:   database.Now(),
+			WorkspaceID: workspace.ID,
+			AgentID:     workspaceAgent.ID,
+			UserID:      userID,
+			Type:        recordingType,
+			StartedAt:   req.StartedAt,
+			EndedAt:     req.EndedAt,
+			Data:        req.Data,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert session recording: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error inserting session recording.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	rw.WriteHeader(http.StatusCreated)
+}
+
+func (api *API) postWorkspaceAgentSessionRecordingToken(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx            = r.Context()
+		workspaceAgent = httpmw.WorkspaceAgentParam(r)
+		workspace      = httpmw.WorkspaceParam(r)
+	)
+	if !api.Authorize(r, rbac.ActionCreate, workspace.ExecutionRBAC()) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	token, err := api.sessionRecordingToken(ctx, workspace, workspaceAgent.ID, httpmw.APIKey(r).UserID)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error issuing session recording token.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(rw, http.StatusCreated, codersdk.WorkspaceAgentSessionRecordingToken{
+		Token: token,
+	})
+}
+
+// sessionRecordingToken issues a token that attributes the recording of a
+// session with the agent to the user. It returns uuid.Nil if the template of
+// the workspace doesn't record sessions.
+func (api *API) sessionRecordingToken(ctx context.Context, workspace database.Workspace, agentID, userID uuid.UUID) (uuid.UUID, error) {
+	template, err := api.Database.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		return uuid.Nil, xerrors.Errorf("get template: %w", err)
+	}
+	if !template.RecordSessions {
+		return uuid.Nil, nil
+	}
+	token, err := api.Database.InsertWorkspaceSessionRecordingToken(ctx, database.InsertWorkspaceSessionRecordingTokenParams{
+		ID:        uuid.New(),
+		CreatedAt: database.Now(),
+		AgentID:   agentID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return uuid.Nil, xerrors.Errorf("insert session recording token: %w", err)
+	}
+	return token.ID, nil
+}
+
+func (api *API) workspaceSessionRecordings(rw http.ResponseWriter, r *http.Request) {
+	workspace := httpmw.WorkspaceParam(r)
+	if !api.Authorize(r, rbac.ActionRead, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	recordings, err := api.Database.GetWorkspaceSessionRecordingsByWorkspaceID(r.Context(), workspace.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching session recordings.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	usernames := make(map[uuid.UUID]string)
+	converted := make([]codersdk.WorkspaceSessionRecording, 0, len(recordings))
+	for _, recording := range recordings {
+		username, ok := usernames[recording.UserID]
+		if !ok {
+			user, err := api.Database.GetUserByID(r.Context(), recording.UserID)
+			if err != nil {
+				httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+					Message: "Internal error fetching user.",
+					Detail:  err.Error(),
+				})
+				return
+			}
+			username = user.Username
+			usernames[recording.UserID] = username
+		}
+		converted = append(converted, codersdk.WorkspaceSessionRecording{
+			ID:          recording.ID,
+			WorkspaceID: recording.WorkspaceID,
+			AgentID:     recording.AgentID,
+			UserID:      recording.UserID,
+			Username:    username,
+			Type:        codersdk.SessionRecordingType(recording.Type),
+			StartedAt:   recording.StartedAt,
+			EndedAt:     recording.EndedAt,
+			Size:        recording.Size,
+		})
+	}
+
+	httpapi.Write(rw, http.StatusOK, converted)
+}
+
+func (api *API) workspaceSessionRecording(rw http.ResponseWriter, r *http.Request) {
+	var (
+		workspace   = httpmw.WorkspaceParam(r)
+		recordingID = chi.URLParam(r, "sessionrecording")
+	)
+	if !api.Authorize(r, rbac.ActionRead, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	id, err := uuid.Parse(recordingID)
+	if err != nil {
+		httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Session recording ID %q must be a valid UUID.", recordingID),
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	recording, err := api.Database.GetWorkspaceSessionRecordingByID(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && recording.WorkspaceID != workspace.ID) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching session recording.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/x-asciicast")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(recording.Data)
+}
+
+// workspaceAndTemplateByAgent returns the workspace and template the agent
+// belongs to.
+func (api *API) workspaceAndTemplateByAgent(ctx context.Context, workspaceAgent database.WorkspaceAgent) (database.Workspace, database.Template, error) {
+	resource, err := api.Database.GetWorkspaceResourceByID(ctx, workspaceAgent.ResourceID)
+	if err != nil {
+		return database.Workspace{}, database.Template{}, xerrors.Errorf("get workspace resource: %w", err)
+	}
+	build, err := api.Database.GetWorkspaceBuildByJobID(ctx, resource.JobID)
+	if err != nil {
+		return database.Workspace{}, database.Template{}, xerrors.Errorf("get workspace build: %w", err)
+	}
+	workspace, err := api.Database.GetWorkspaceByID(ctx, build.WorkspaceID)
+	if err != nil {
+		return database.Workspace{}, database.Template{}, xerrors.Errorf("get workspace: %w", err)
+	}
+	template, err := api.Database.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		return database.Workspace{}, database.Template{}, xerrors.Errorf("get template: %w", err)
+	}
+	return workspace, template, nil
+}
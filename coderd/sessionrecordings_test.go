@@ -0,0 +1,135 @@
+package coderd_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/util/ptr"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWorkspaceSessionRecordings(t *testing.T) {
+	t.Parallel()
+
+	client := coderdtest.New(t, &coderdtest.Options{
+		IncludeProvisionerD: true,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	authToken := uuid.NewString()
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:           echo.ParseComplete,
+		ProvisionDryRun: echo.ProvisionComplete,
+		Provision: []*proto.Provision_Response{{
+			Type: &proto.Provision_Response_Complete{
+				Complete: &proto.Provision_Complete{
+					Resources: []*proto.Resource{{
+						Name: "example",
+						Type: "aws_instance",
+						Agents: []*proto.Agent{{
+							Id: uuid.NewString(),
+							Auth: &proto.Agent_Token{
+								Token: authToken,
+							},
+						}},
+					}},
+				},
+			},
+		}},
+	})
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	resources, err := client.WorkspaceResourcesByBuild(ctx, workspace.LatestBuild.ID)
+	require.NoError(t, err)
+	agentID := resources[0].Agents[0].ID
+
+	agentClient := codersdk.New(client.URL)
+	agentClient.SessionToken = authToken
+	recording := agent.SessionRecording{
+		Type:      agent.SessionRecordingTypeSSH,
+		StartedAt: time.Now().Add(-time.Minute),
+		EndedAt:   time.Now(),
+		Data:      []byte("{\"version\":2,\"width\":80,\"height\":24}\n[0.5,\"o\",\"hello\"]\n"),
+	}
+
+	// Recordings are rejected unless the template records sessions.
+	token, err := client.WorkspaceAgentSessionRecordingToken(ctx, agentID)
+	require.NoError(t, err)
+	require.Equal(t, uuid.Nil, token.Token)
+	err = agentClient.UploadWorkspaceAgentSessionRecording(ctx, recording)
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+	_, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+		RecordSessions: ptr.Ref(true),
+	})
+	require.NoError(t, err)
+
+	// The agent is told to record sessions.
+	metadata, listener, err := agentClient.ListenWorkspaceAgent(ctx, slogtest.Make(t, nil))
+	require.NoError(t, err)
+	_ = listener.Close()
+	require.True(t, metadata.RecordSessions)
+
+	err = agentClient.UploadWorkspaceAgentSessionRecording(ctx, recording)
+	require.NoError(t, err)
+
+	recordings, err := client.WorkspaceSessionRecordings(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.Len(t, recordings, 1)
+	require.Equal(t, workspace.ID, recordings[0].WorkspaceID)
+	require.Equal(t, user.UserID, recordings[0].UserID)
+	require.Equal(t, coderdtest.FirstUserParams.Username, recordings[0].Username)
+	require.Equal(t, codersdk.SessionRecordingTypeSSH, recordings[0].Type)
+	require.EqualValues(t, len(recording.Data), recordings[0].Size)
+
+	data, err := client.DownloadWorkspaceSessionRecording(ctx, workspace.ID, recordings[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, recording.Data, data)
+
+	_, err = client.DownloadWorkspaceSessionRecording(ctx, workspace.ID, uuid.New())
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+	// Recordings are attributed to the user the agent was given a token by.
+	otherClient, otherUser := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID, rbac.RoleOwner())
+	token, err = otherClient.WorkspaceAgentSessionRecordingToken(ctx, agentID)
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, token.Token)
+	recording.Token = token.Token
+	recording.StartedAt = time.Now()
+	recording.EndedAt = time.Now()
+	err = agentClient.UploadWorkspaceAgentSessionRecording(ctx, recording)
+	require.NoError(t, err)
+
+	// Tokens can't be reused.
+	recording.StartedAt = time.Now().Add(time.Minute)
+	recording.EndedAt = recording.StartedAt
+	err = agentClient.UploadWorkspaceAgentSessionRecording(ctx, recording)
+	require.NoError(t, err)
+
+	recordings, err = client.WorkspaceSessionRecordings(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.Len(t, recordings, 3)
+	require.Equal(t, user.UserID, recordings[0].UserID)
+	require.Equal(t, otherUser.ID, recordings[1].UserID)
+	require.Equal(t, otherUser.Username, recordings[1].Username)
+}
@@ -0,0 +1,183 @@
+package coderd
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/templatesync"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) templateGitSource(rw http.ResponseWriter, r *http.Request) {
+	template := httpmw.TemplateParam(r)
+	if !api.Authorize(r, rbac.ActionRead, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	source, ok := api.fetchTemplateGitSource(rw, r, template)
+	if !ok {
+		return
+	}
+
+	httpapi.Write(rw, http.StatusOK, convertTemplateGitSource(source))
+}
+
+func (api *API) putTemplateGitSource(rw http.ResponseWriter, r *http.Request) {
+	template := httpmw.TemplateParam(r)
+	apiKey := httpmw.APIKey(r)
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateTemplateGitSourceRequest
+	if !httpapi.Read(rw, r, &req) {
+		return
+	}
+	if err := templatesync.ValidateSubdirectory(req.Subdirectory); err != nil {
+		httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid request to update template git source.",
+			Validations: []codersdk.ValidationError{
+				{Field: "subdirectory", Detail: err.Error()},
+			},
+		})
+		return
+	}
+
+	if req.RepositoryURL == "" {
+		current, err := api.Database.GetTemplateGitSourceByTemplateID(r.Context(), template.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Invalid request to update template git source.",
+				Validations: []codersdk.ValidationError{
+					{Field: "repository_url", Detail: "Required unless the template is already synced from git."},
+				},
+			})
+			return
+		}
+		if err != nil {
+			httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching template git source.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		req.RepositoryURL = current.RepositoryUrl
+	}
+
+	now := database.Now()
+	source, err := api.Database.UpsertTemplateGitSource(r.Context(), database.UpsertTemplateGitSourceParams{
+		TemplateID:    template.ID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		CreatedBy:     apiKey.UserID,
+		RepositoryUrl: req.RepositoryURL,
+		Ref:           req.Ref,
+		Subdirectory:  req.Subdirectory,
+		AutoPromote:   req.AutoPromote,
+	})
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating template git source.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	// The first sync happens right away so mistakes in the source are
+	// reported to the user. Sync errors are recorded on the source. If the
+	// template is already being synced, the next sync picks up the change.
+	source, _ = templatesync.Sync(r.Context(), api.Database, api.BlobStore, source)
+
+	httpapi.Write(rw, http.StatusOK, convertTemplateGitSource(source))
+}
+
+func (api *API) deleteTemplateGitSource(rw http.ResponseWriter, r *http.Request) {
+	template := httpmw.TemplateParam(r)
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	_, ok := api.fetchTemplateGitSource(rw, r, template)
+	if !ok {
+		return
+	}
+	err := api.Database.DeleteTemplateGitSourceByTemplateID(r.Context(), template.ID)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting template git source.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(rw, http.StatusOK, codersdk.Response{
+		Message: "Template is no longer synced from git.",
+	})
+}
+
+func (api *API) postTemplateGitSourceSync(rw http.ResponseWriter, r *http.Request) {
+	template := httpmw.TemplateParam(r)
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	source, ok := api.fetchTemplateGitSource(rw, r, template)
+	if !ok {
+		return
+	}
+	source, err := templatesync.Sync(r.Context(), api.Database, api.BlobStore, source)
+	if errors.Is(err, templatesync.ErrSyncInProgress) {
+		httpapi.Write(rw, http.StatusConflict, codersdk.Response{
+			Message: "Template is already being synced from git.",
+		})
+		return
+	}
+
+	httpapi.Write(rw, http.StatusOK, convertTemplateGitSource(source))
+}
+
+func (api *API) fetchTemplateGitSource(rw http.ResponseWriter, r *http.Request, template database.Template) (database.TemplateGitSource, bool) {
+	source, err := api.Database.GetTemplateGitSourceByTemplateID(r.Context(), template.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(rw, http.StatusNotFound, codersdk.Response{
+			Message: "Template is not synced from git.",
+		})
+		return database.TemplateGitSource{}, false
+	}
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template git source.",
+			Detail:  err.Error(),
+		})
+		return database.TemplateGitSource{}, false
+	}
+	return source, true
+}
+
+func convertTemplateGitSource(source database.TemplateGitSource) codersdk.TemplateGitSource {
+	converted := codersdk.TemplateGitSource{
+		TemplateID:    source.TemplateID,
+		RepositoryURL: templatesync.RedactURL(source.RepositoryUrl),
+		Ref:           source.Ref,
+		Subdirectory:  source.Subdirectory,
+		AutoPromote:   source.AutoPromote,
+		CommitSHA:     source.CommitSha,
+		SyncError:     source.SyncError,
+	}
+	if source.PendingVersionID.Valid {
+		converted.PendingVersionID = &source.PendingVersionID.UUID
+	}
+	if source.SyncedAt.Valid {
+		converted.SyncedAt = &source.SyncedAt.Time
+	}
+	return converted
+}
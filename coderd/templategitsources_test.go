@@ -0,0 +1,259 @@
+package coderd_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateGitSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NotSynced", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.TemplateGitSource(ctx, template.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		// A repository URL is required to start syncing.
+		_, err = client.UpdateTemplateGitSource(ctx, template.ID, codersdk.UpdateTemplateGitSourceRequest{Ref: "main"})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("InvalidSubdirectory", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.UpdateTemplateGitSource(ctx, template.ID, codersdk.UpdateTemplateGitSourceRequest{
+			RepositoryURL: "https://github.com/coder/coder.git",
+			Ref:           "main",
+			Subdirectory:  "../secrets",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("Sync", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		repository, git := createGitTemplateRepository(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		// The first sync creates a version from the repository, but
+		// doesn't promote it.
+		source, err := client.UpdateTemplateGitSource(ctx, template.ID, codersdk.UpdateTemplateGitSourceRequest{
+			RepositoryURL: repository,
+			Ref:           "main",
+		})
+		require.NoError(t, err)
+		require.Empty(t, source.SyncError)
+		require.Equal(t, git("rev-parse", "HEAD"), source.CommitSHA)
+		require.NotNil(t, source.SyncedAt)
+		require.Nil(t, source.PendingVersionID)
+
+		versions, err := client.TemplateVersionsByTemplate(ctx, codersdk.TemplateVersionsByTemplateRequest{TemplateID: template.ID})
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		synced := versions[1]
+		require.Equal(t, "Add template", synced.Message)
+		require.NotNil(t, synced.Git)
+		require.Equal(t, source.CommitSHA, synced.Git.CommitSHA)
+		require.Equal(t, "main", synced.Git.Branch)
+		updated, err := client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, version.ID, updated.ActiveVersionID)
+
+		// Nothing changes until the ref moves.
+		source, err = client.SyncTemplateGitSource(ctx, template.ID)
+		require.NoError(t, err)
+		require.Empty(t, source.SyncError)
+		versions, err = client.TemplateVersionsByTemplate(ctx, codersdk.TemplateVersionsByTemplateRequest{TemplateID: template.ID})
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+
+		err = os.WriteFile(filepath.Join(repository, "README.md"), []byte("# Template\n"), 0o600)
+		require.NoError(t, err)
+		git("add", ".")
+		git("commit", "-m", "Add a readme")
+		source, err = client.SyncTemplateGitSource(ctx, template.ID)
+		require.NoError(t, err)
+		require.Empty(t, source.SyncError)
+		require.Equal(t, git("rev-parse", "HEAD"), source.CommitSHA)
+		versions, err = client.TemplateVersionsByTemplate(ctx, codersdk.TemplateVersionsByTemplateRequest{TemplateID: template.ID})
+		require.NoError(t, err)
+		require.Len(t, versions, 3)
+		require.Equal(t, "Add a readme", versions[2].Message)
+
+		err = client.DeleteTemplateGitSource(ctx, template.ID)
+		require.NoError(t, err)
+		_, err = client.TemplateGitSource(ctx, template.ID)
+		require.Error(t, err)
+	})
+
+	t.Run("AutoPromote", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		repository, _ := createGitTemplateRepository(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		source, err := client.UpdateTemplateGitSource(ctx, template.ID, codersdk.UpdateTemplateGitSourceRequest{
+			RepositoryURL: repository,
+			Ref:           "main",
+			AutoPromote:   true,
+		})
+		require.NoError(t, err)
+		require.Empty(t, source.SyncError)
+		require.NotNil(t, source.PendingVersionID)
+		pending := *source.PendingVersionID
+
+		// Each sync advances the pending version through its import and
+		// dry run until it's promoted.
+		require.Eventually(t, func() bool {
+			source, err = client.SyncTemplateGitSource(ctx, template.ID)
+			require.NoError(t, err)
+			require.Empty(t, source.SyncError)
+			return source.PendingVersionID == nil
+		}, testutil.WaitLong, testutil.IntervalFast)
+
+		updated, err := client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, pending, updated.ActiveVersionID)
+	})
+
+	t.Run("SyncInProgress", func(t *testing.T) {
+		t.Parallel()
+		client, db := newTestClientWithDatabase(t)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		repository, git := createGitTemplateRepository(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.UpdateTemplateGitSource(ctx, template.ID, codersdk.UpdateTemplateGitSourceRequest{
+			RepositoryURL: repository,
+			Ref:           "main",
+		})
+		require.NoError(t, err)
+		err = os.WriteFile(filepath.Join(repository, "README.md"), []byte("# Template\n"), 0o600)
+		require.NoError(t, err)
+		git("add", ".")
+		git("commit", "-m", "Add a readme")
+
+		// Another replica is syncing the template.
+		now := database.Now()
+		running, err := db.AcquireTemplateGitSourceSync(ctx, database.AcquireTemplateGitSourceSyncParams{
+			TemplateID:    template.ID,
+			SyncStartedAt: now,
+			StaleBefore:   now.Add(-time.Hour),
+		})
+		require.NoError(t, err)
+		_, err = client.SyncTemplateGitSource(ctx, template.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+		versions, err := client.TemplateVersionsByTemplate(ctx, codersdk.TemplateVersionsByTemplateRequest{TemplateID: template.ID})
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+
+		// Once it finishes, the template can be synced again.
+		err = db.UpdateTemplateGitSourceSyncByTemplateID(ctx, database.UpdateTemplateGitSourceSyncByTemplateIDParams{
+			TemplateID:         running.TemplateID,
+			CommitSha:          running.CommitSha,
+			PendingVersionID:   running.PendingVersionID,
+			PendingDryRunJobID: running.PendingDryRunJobID,
+			SyncedAt:           running.SyncedAt,
+		})
+		require.NoError(t, err)
+		source, err := client.SyncTemplateGitSource(ctx, template.ID)
+		require.NoError(t, err)
+		require.Empty(t, source.SyncError)
+		require.Equal(t, git("rev-parse", "HEAD"), source.CommitSHA)
+		versions, err = client.TemplateVersionsByTemplate(ctx, codersdk.TemplateVersionsByTemplateRequest{TemplateID: template.ID})
+		require.NoError(t, err)
+		require.Len(t, versions, 3)
+	})
+}
+
+// createGitTemplateRepository creates a git repository with a template for
+// the echo provisioner committed to its main branch.
+func createGitTemplateRepository(t *testing.T) (string, func(args ...string) string) {
+	t.Helper()
+	directory := t.TempDir()
+	archive, err := echo.Tar(nil)
+	require.NoError(t, err)
+	reader := tar.NewReader(bytes.NewReader(archive))
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		err = os.WriteFile(filepath.Join(directory, filepath.Base(header.Name)), data, 0o600)
+		require.NoError(t, err)
+	}
+
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = directory
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "--initial-branch", "main")
+	git("config", "user.email", "ci@coder.com")
+	git("config", "user.name", "C I")
+	git("add", ".")
+	git("commit", "-m", "Add template")
+	return directory, git
+}
@@ -0,0 +1,173 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"cdr.dev/slog"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/insights"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// maxInsightsDays limits how many days of insights can be requested at once.
+const maxInsightsDays = 366
+
+func (api *API) templateInsights(rw http.ResponseWriter, r *http.Request) {
+	template := httpmw.TemplateParam(r)
+	// Insights are about the users of a template, so only those that can
+	// manage the template may see them.
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	endDate := insights.Date(database.Now())
+	startDate := endDate.AddDate(0, 0, -6)
+	var validations []codersdk.ValidationError
+	for _, param := range []struct {
+		name string
+		date *time.Time
+	}{
+		{name: "start_date", date: &startDate},
+		{name: "end_date", date: &endDate},
+	} {
+		raw := r.URL.Query().Get(param.name)
+		if raw == "" {
+			continue
+		}
+		date, err := time.Parse(codersdk.InsightsDateFormat, raw)
+		if err != nil {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  param.name,
+				Detail: fmt.Sprintf("Must be a date formatted as %q.", codersdk.InsightsDateFormat),
+			})
+			continue
+		}
+		*param.date = date
+	}
+	if len(validations) == 0 && endDate.Before(startDate) {
+		validations = append(validations, codersdk.ValidationError{
+			Field:  "end_date",
+			Detail: "Must not be before the start date.",
+		})
+	}
+	if len(validations) == 0 && endDate.Sub(startDate) >= maxInsightsDays*24*time.Hour {
+		validations = append(validations, codersdk.ValidationError{
+			Field:  "start_date",
+			Detail: fmt.Sprintf("At most %d days can be requested.", maxInsightsDays),
+		})
+	}
+	if len(validations) > 0 {
+		httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid date range for template insights.",
+			Validations: validations,
+		})
+		return
+	}
+
+	rows, err := api.Database.GetTemplateInsights(r.Context(), database.GetTemplateInsightsParams{
+		TemplateID: template.ID,
+		StartDate:  startDate,
+		EndDate:    endDate.AddDate(0, 0, 1),
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template insights.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	apiInsights, err := convertTemplateInsights(template.ID, startDate, endDate, rows)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error converting template insights.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(rw, http.StatusOK, apiInsights)
+}
+
+// trackTemplateUsage records that a user used a workspace, so they're counted
+// in the insights of its template. Failures are logged and never interrupt
+// the connection.
+func (api *API) trackTemplateUsage(ctx context.Context, workspace database.Workspace, userID uuid.UUID, appName string) {
+	err := api.insightsTracker.Track(ctx, workspace.TemplateID, userID, appName)
+	if err != nil {
+		api.Logger.Warn(ctx, "track template usage",
+			slog.F("workspace_id", workspace.ID),
+			slog.Error(err),
+		)
+	}
+}
+
+// convertTemplateInsights returns the insights of every day from the start
+// until the end date. Days without insights are zero.
+func convertTemplateInsights(templateID uuid.UUID, startDate, endDate time.Time, rows []database.TemplateInsight) (codersdk.TemplateInsights, error) {
+	rowsByDate := make(map[time.Time]database.TemplateInsight, len(rows))
+	for _, row := range rows {
+		rowsByDate[insights.Date(row.Date)] = row
+	}
+
+	apiInsights := codersdk.TemplateInsights{
+		TemplateID: templateID,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Days:       []codersdk.TemplateInsightsDay{},
+	}
+	var buildDurations, agentConnects []int64
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+		day := codersdk.TemplateInsightsDay{
+			Date:     date,
+			AppUsage: map[string]int64{},
+		}
+		row, ok := rowsByDate[date]
+		if ok {
+			day.ActiveUsers = int64(row.ActiveUsers)
+			day.BuildsTotal = int64(row.BuildsTotal)
+			day.BuildsSucceeded = int64(row.BuildsSucceeded)
+			if row.BuildDurationMedianMs.Valid {
+				day.BuildDurationMedianMillis = &row.BuildDurationMedianMs.Int64
+				buildDurations = append(buildDurations, row.BuildDurationMedianMs.Int64)
+			}
+			if row.AgentConnectMedianMs.Valid {
+				day.AgentConnectMedianMillis = &row.AgentConnectMedianMs.Int64
+				agentConnects = append(agentConnects, row.AgentConnectMedianMs.Int64)
+			}
+			err := json.Unmarshal(row.AppUsage, &day.AppUsage)
+			if err != nil {
+				return codersdk.TemplateInsights{}, xerrors.Errorf("unmarshal app usage of %s: %w", date.Format(codersdk.InsightsDateFormat), err)
+			}
+		}
+		apiInsights.BuildsTotal += day.BuildsTotal
+		apiInsights.BuildsSucceeded += day.BuildsSucceeded
+		apiInsights.Days = append(apiInsights.Days, day)
+	}
+	if apiInsights.BuildsTotal > 0 {
+		apiInsights.BuildSuccessRate = float64(apiInsights.BuildsSucceeded) / float64(apiInsights.BuildsTotal)
+	}
+	apiInsights.BuildDurationMedianMillis = medianMillis(buildDurations)
+	apiInsights.AgentConnectMedianMillis = medianMillis(agentConnects)
+	return apiInsights, nil
+}
+
+func medianMillis(values []int64) *int64 {
+	median := insights.Median(values)
+	if !median.Valid {
+		return nil
+	}
+	return &median.Int64
+}
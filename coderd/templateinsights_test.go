@@ -0,0 +1,103 @@
+package coderd_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateInsights(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Days", func(t *testing.T) {
+		t.Parallel()
+		client, db := newTestClientWithDatabase(t)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		start := time.Date(2022, 7, 11, 0, 0, 0, 0, time.UTC)
+		for i, buildDuration := range []int64{1000, 3000} {
+			err := db.UpsertTemplateInsights(ctx, database.UpsertTemplateInsightsParams{
+				TemplateID:            template.ID,
+				Date:                  start.AddDate(0, 0, i*2),
+				UpdatedAt:             database.Now(),
+				ActiveUsers:           2,
+				BuildsTotal:           4,
+				BuildsSucceeded:       3,
+				BuildDurationMedianMs: sql.NullInt64{Int64: buildDuration, Valid: true},
+				AppUsage:              []byte(`{"code-server":1}`),
+			})
+			require.NoError(t, err)
+		}
+
+		insights, err := client.TemplateInsights(ctx, template.ID, codersdk.TemplateInsightsRequest{
+			StartDate: start,
+			EndDate:   start.AddDate(0, 0, 2),
+		})
+		require.NoError(t, err)
+		require.Len(t, insights.Days, 3)
+		require.EqualValues(t, 8, insights.BuildsTotal)
+		require.EqualValues(t, 6, insights.BuildsSucceeded)
+		require.Equal(t, 0.75, insights.BuildSuccessRate)
+		require.NotNil(t, insights.BuildDurationMedianMillis)
+		require.EqualValues(t, 2000, *insights.BuildDurationMedianMillis)
+		require.Nil(t, insights.AgentConnectMedianMillis)
+
+		// Days without insights are zero.
+		require.EqualValues(t, 2, insights.Days[0].ActiveUsers)
+		require.Equal(t, map[string]int64{"code-server": 1}, insights.Days[0].AppUsage)
+		require.EqualValues(t, 0, insights.Days[1].ActiveUsers)
+		require.Nil(t, insights.Days[1].BuildDurationMedianMillis)
+		require.Empty(t, insights.Days[1].AppUsage)
+	})
+
+	t.Run("DefaultsToLastWeek", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		insights, err := client.TemplateInsights(ctx, template.ID, codersdk.TemplateInsightsRequest{})
+		require.NoError(t, err)
+		require.Len(t, insights.Days, 7)
+		require.Zero(t, insights.BuildSuccessRate)
+	})
+
+	t.Run("EndBeforeStart", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		start := time.Date(2022, 7, 11, 0, 0, 0, 0, time.UTC)
+		_, err := client.TemplateInsights(ctx, template.ID, codersdk.TemplateInsightsRequest{
+			StartDate: start,
+			EndDate:   start.AddDate(0, 0, -1),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Contains(t, err.Error(), "end_date")
+	})
+}
@@ -1,6 +1,7 @@
 package coderd
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"database/sql"
@@ -15,6 +16,8 @@ import (
 	"github.com/moby/moby/pkg/namesgenerator"
 	"golang.org/x/xerrors"
 
+	"github.com/coder/coder/coderd/autobuild/schedule"
+	"github.com/coder/coder/coderd/blobstore"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
@@ -414,6 +417,29 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 	if req.MinAutostartIntervalMillis < 0 {
 		validErrs = append(validErrs, codersdk.ValidationError{Field: "min_autostart_interval_ms", Detail: "Must be a positive integer."})
 	}
+	if req.InactivityTTLMillis != nil && *req.InactivityTTLMillis < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "inactivity_ttl_ms", Detail: "Must be a positive integer."})
+	}
+	if req.DormantAutoDeleteTTLMillis != nil && *req.DormantAutoDeleteTTLMillis < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "dormant_autodelete_ttl_ms", Detail: "Must be a positive integer."})
+	}
+	maintenanceWindow := template.MaintenanceWindow
+	if req.MaintenanceWindow != nil {
+		maintenanceWindow = *req.MaintenanceWindow
+	}
+	maintenanceWindowDuration := time.Duration(template.MaintenanceWindowDuration)
+	if req.MaintenanceWindowMillis != nil {
+		maintenanceWindowDuration = time.Duration(*req.MaintenanceWindowMillis) * time.Millisecond
+	}
+	maintenanceRestartOutdated := template.MaintenanceRestartOutdated
+	if req.MaintenanceRestartOutdated != nil {
+		maintenanceRestartOutdated = *req.MaintenanceRestartOutdated
+	}
+	validErrs = append(validErrs, validTemplateMaintenanceWindow(maintenanceWindow, maintenanceWindowDuration, maintenanceRestartOutdated)...)
+	recordSessions := template.RecordSessions
+	if req.RecordSessions != nil {
+		recordSessions = *req.RecordSessions
+	}
 	if req.MaxTTLMillis > maxTTLDefault.Milliseconds() {
 		httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
 			Message: "Invalid create template request.",
@@ -448,11 +474,26 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 			count = uint32(workspaceCounts[0].Count)
 		}
 
+		inactivityTTL := time.Duration(template.InactivityTtl)
+		if req.InactivityTTLMillis != nil {
+			inactivityTTL = time.Duration(*req.InactivityTTLMillis) * time.Millisecond
+		}
+		dormantAutoDeleteTTL := time.Duration(template.DormantAutodeleteTtl)
+		if req.DormantAutoDeleteTTLMillis != nil {
+			dormantAutoDeleteTTL = time.Duration(*req.DormantAutoDeleteTTLMillis) * time.Millisecond
+		}
+
 		if req.Name == template.Name &&
 			req.Description == template.Description &&
 			req.Icon == template.Icon &&
 			req.MaxTTLMillis == time.Duration(template.MaxTtl).Milliseconds() &&
-			req.MinAutostartIntervalMillis == time.Duration(template.MinAutostartInterval).Milliseconds() {
+			req.MinAutostartIntervalMillis == time.Duration(template.MinAutostartInterval).Milliseconds() &&
+			int64(inactivityTTL) == template.InactivityTtl &&
+			int64(dormantAutoDeleteTTL) == template.DormantAutodeleteTtl &&
+			maintenanceWindow == template.MaintenanceWindow &&
+			int64(maintenanceWindowDuration) == template.MaintenanceWindowDuration &&
+			maintenanceRestartOutdated == template.MaintenanceRestartOutdated &&
+			recordSessions == template.RecordSessions {
 			return nil
 		}
 
@@ -477,13 +518,19 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 		}
 
 		if err := s.UpdateTemplateMetaByID(r.Context(), database.UpdateTemplateMetaByIDParams{
-			ID:                   template.ID,
-			UpdatedAt:            database.Now(),
-			Name:                 name,
-			Description:          desc,
-			Icon:                 icon,
-			MaxTtl:               int64(maxTTL),
-			MinAutostartInterval: int64(minAutostartInterval),
+			ID:                         template.ID,
+			UpdatedAt:                  database.Now(),
+			Name:                       name,
+			Description:                desc,
+			Icon:                       icon,
+			MaxTtl:                     int64(maxTTL),
+			MinAutostartInterval:       int64(minAutostartInterval),
+			InactivityTtl:              int64(inactivityTTL),
+			DormantAutodeleteTtl:       int64(dormantAutoDeleteTTL),
+			MaintenanceWindow:          maintenanceWindow,
+			MaintenanceWindowDuration:  int64(maintenanceWindowDuration),
+			MaintenanceRestartOutdated: maintenanceRestartOutdated,
+			RecordSessions:             recordSessions,
 		}); err != nil {
 			return err
 		}
@@ -519,6 +566,177 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 	httpapi.Write(rw, http.StatusOK, convertTemplate(updated, count, createdByNameMap[updated.ID.String()]))
 }
 
+// putTemplateOrganization moves a template, its versions and the workspaces
+// built from it into another organization.
+func (api *API) putTemplateOrganization(rw http.ResponseWriter, r *http.Request) {
+	template := httpmw.TemplateParam(r)
+	if !api.Authorize(r, rbac.ActionDelete, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateTemplateOrganizationRequest
+	if !httpapi.Read(rw, r, &req) {
+		return
+	}
+
+	if req.OrganizationID == template.OrganizationID {
+		httpapi.Write(rw, http.StatusNotModified, nil)
+		return
+	}
+
+	organization, err := api.Database.GetOrganizationByID(r.Context(), req.OrganizationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Organization %q does not exist.", req.OrganizationID),
+			Validations: []codersdk.ValidationError{
+				{Field: "organization_id", Detail: "organization not found"},
+			},
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceTemplate.InOrg(organization.ID)) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	_, err = api.Database.GetTemplateByOrganizationAndName(r.Context(), database.GetTemplateByOrganizationAndNameParams{
+		OrganizationID: organization.ID,
+		Name:           template.Name,
+	})
+	if err == nil {
+		httpapi.Write(rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("Template with name %q already exists in organization %q.", template.Name, organization.Name),
+			Validations: []codersdk.ValidationError{{
+				Field:  "organization_id",
+				Detail: "This template name is already in use.",
+			}},
+		})
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template by name.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	var updated database.Template
+	err = api.Database.InTx(func(s database.Store) error {
+		now := database.Now()
+		err := s.UpdateTemplateOrganizationByID(r.Context(), database.UpdateTemplateOrganizationByIDParams{
+			ID:             template.ID,
+			OrganizationID: organization.ID,
+			UpdatedAt:      now,
+		})
+		if err != nil {
+			return xerrors.Errorf("update template organization: %w", err)
+		}
+		err = s.UpdateTemplateVersionsOrganizationByTemplateID(r.Context(), database.UpdateTemplateVersionsOrganizationByTemplateIDParams{
+			TemplateID:     uuid.NullUUID{UUID: template.ID, Valid: true},
+			OrganizationID: organization.ID,
+			UpdatedAt:      now,
+		})
+		if err != nil {
+			return xerrors.Errorf("update template versions organization: %w", err)
+		}
+		err = s.UpdateWorkspacesOrganizationByTemplateID(r.Context(), database.UpdateWorkspacesOrganizationByTemplateIDParams{
+			TemplateID:     template.ID,
+			OrganizationID: organization.ID,
+			UpdatedAt:      now,
+		})
+		if err != nil {
+			return xerrors.Errorf("update workspaces organization: %w", err)
+		}
+
+		// Workspace owners must be members of the organization to keep
+		// access to their workspaces.
+		workspaces, err := s.GetWorkspaces(r.Context(), database.GetWorkspacesParams{
+			TemplateIds: []uuid.UUID{template.ID},
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil
+		}
+		if err != nil {
+			return xerrors.Errorf("get workspaces: %w", err)
+		}
+		owners := map[uuid.UUID]struct{}{}
+		for _, workspace := range workspaces {
+			owners[workspace.OwnerID] = struct{}{}
+		}
+		for ownerID := range owners {
+			_, err := s.GetOrganizationMemberByUserID(r.Context(), database.GetOrganizationMemberByUserIDParams{
+				OrganizationID: organization.ID,
+				UserID:         ownerID,
+			})
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				return xerrors.Errorf("get organization member: %w", err)
+			}
+			_, err = s.InsertOrganizationMember(r.Context(), database.InsertOrganizationMemberParams{
+				OrganizationID: organization.ID,
+				UserID:         ownerID,
+				CreatedAt:      now,
+				UpdatedAt:      now,
+				Roles:          []string{},
+			})
+			if err != nil {
+				return xerrors.Errorf("insert organization member: %w", err)
+			}
+		}
+
+		updated, err = s.GetTemplateByID(r.Context(), template.ID)
+		if err != nil {
+			return xerrors.Errorf("get updated template: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error moving template.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	count := uint32(0)
+	workspaceCounts, err := api.Database.GetWorkspaceOwnerCountsByTemplateIDs(r.Context(), []uuid.UUID{template.ID})
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace counts.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if len(workspaceCounts) > 0 {
+		count = uint32(workspaceCounts[0].Count)
+	}
+
+	createdByNameMap, err := getCreatedByNamesByTemplateIDs(r.Context(), api.Database, []database.Template{updated})
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching creator name.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(rw, http.StatusOK, convertTemplate(updated, count, createdByNameMap[updated.ID.String()]))
+}
+
 type autoImportTemplateOpts struct {
 	name    string
 	archive []byte
@@ -528,24 +746,22 @@ type autoImportTemplateOpts struct {
 }
 
 func (api *API) autoImportTemplate(ctx context.Context, opts autoImportTemplateOpts) (database.Template, error) {
-	var template database.Template
-	err := api.Database.InTx(func(s database.Store) error {
-		// Insert the archive into the files table.
-		var (
-			hash = sha256.Sum256(opts.archive)
-			now  = database.Now()
-		)
-		file, err := s.InsertFile(ctx, database.InsertFileParams{
-			Hash:      hex.EncodeToString(hash[:]),
-			CreatedAt: now,
-			CreatedBy: opts.userID,
-			Mimetype:  "application/x-tar",
-			Data:      opts.archive,
-		})
-		if err != nil {
-			return xerrors.Errorf("insert auto-imported template archive into files table: %w", err)
-		}
+	// The file is stored outside of the transaction, since the store may
+	// use the database itself.
+	hash := sha256.Sum256(opts.archive)
+	file, err := blobstore.StoreFile(ctx, api.Database, api.BlobStore, database.InsertFileParams{
+		Hash:      hex.EncodeToString(hash[:]),
+		CreatedAt: database.Now(),
+		CreatedBy: opts.userID,
+		Mimetype:  "application/x-tar",
+	}, bytes.NewReader(opts.archive), int64(len(opts.archive)))
+	if err != nil {
+		return database.Template{}, xerrors.Errorf("store auto-imported template archive: %w", err)
+	}
 
+	var template database.Template
+	err = api.Database.InTx(func(s database.Store) error {
+		now := database.Now()
 		jobID := uuid.New()
 
 		// Insert parameters
@@ -706,5 +922,35 @@ func convertTemplate(template database.Template, workspaceOwnerCount uint32, cre
 		MinAutostartIntervalMillis: time.Duration(template.MinAutostartInterval).Milliseconds(),
 		CreatedByID:                template.CreatedBy,
 		CreatedByName:              createdByName,
+		InactivityTTLMillis:        time.Duration(template.InactivityTtl).Milliseconds(),
+		DormantAutoDeleteTTLMillis: time.Duration(template.DormantAutodeleteTtl).Milliseconds(),
+		MaintenanceWindow:          template.MaintenanceWindow,
+		MaintenanceWindowMillis:    time.Duration(template.MaintenanceWindowDuration).Milliseconds(),
+		MaintenanceRestartOutdated: template.MaintenanceRestartOutdated,
+		PrebuildCount:              template.PrebuildCount,
+		RecordSessions:             template.RecordSessions,
+	}
+}
+
+// validTemplateMaintenanceWindow validates the resulting maintenance window
+// of a template.
+func validTemplateMaintenanceWindow(window string, duration time.Duration, restartOutdated bool) []codersdk.ValidationError {
+	if window == "" {
+		if restartOutdated {
+			return []codersdk.ValidationError{{Field: "maintenance_restart_outdated", Detail: "Requires a maintenance window."}}
+		}
+		return nil
+	}
+
+	var validErrs []codersdk.ValidationError
+	if _, err := schedule.Weekly(window); err != nil {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "maintenance_window", Detail: err.Error()})
+	}
+	if duration < time.Minute {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "maintenance_window_ms", Detail: "Must be at least one minute."})
+	}
+	if duration > 24*time.Hour {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "maintenance_window_ms", Detail: "Cannot be longer than 24 hours."})
 	}
+	return validErrs
 }
@@ -148,6 +148,52 @@ func TestPostTemplateByOrganization(t *testing.T) {
 		require.Contains(t, err.Error(), "max_ttl_ms: Cannot be greater than")
 	})
 
+	t.Run("MaintenanceWindow", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		updated, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			MaintenanceWindow:          ptr.Ref("CRON_TZ=Europe/Dublin 0 2 * * 1-5"),
+			MaintenanceWindowMillis:    ptr.Ref((2 * time.Hour).Milliseconds()),
+			MaintenanceRestartOutdated: ptr.Ref(true),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "CRON_TZ=Europe/Dublin 0 2 * * 1-5", updated.MaintenanceWindow)
+		assert.Equal(t, (2 * time.Hour).Milliseconds(), updated.MaintenanceWindowMillis)
+		assert.True(t, updated.MaintenanceRestartOutdated)
+
+		_, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			MaintenanceWindow: ptr.Ref("not a schedule"),
+		})
+		require.ErrorContains(t, err, "maintenance_window:")
+
+		_, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			MaintenanceWindowMillis: ptr.Ref((25 * time.Hour).Milliseconds()),
+		})
+		require.ErrorContains(t, err, "maintenance_window_ms: Cannot be longer than 24 hours")
+
+		// Removing the window requires disabling restarts.
+		_, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			MaintenanceWindow: ptr.Ref(""),
+		})
+		require.ErrorContains(t, err, "maintenance_restart_outdated: Requires a maintenance window")
+
+		updated, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			MaintenanceWindow:          ptr.Ref(""),
+			MaintenanceRestartOutdated: ptr.Ref(false),
+		})
+		require.NoError(t, err)
+		assert.Empty(t, updated.MaintenanceWindow)
+		assert.False(t, updated.MaintenanceRestartOutdated)
+	})
+
 	t.Run("NoMaxTTL", func(t *testing.T) {
 		t.Parallel()
 		client := coderdtest.New(t, nil)
@@ -329,6 +375,47 @@ func TestPatchTemplateMeta(t *testing.T) {
 		assert.Equal(t, req.MinAutostartIntervalMillis, updated.MinAutostartIntervalMillis)
 	})
 
+	t.Run("Dormancy", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		require.Zero(t, template.InactivityTTLMillis)
+		require.Zero(t, template.DormantAutoDeleteTTLMillis)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		updated, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:                       template.Name,
+			MaxTTLMillis:               template.MaxTTLMillis,
+			MinAutostartIntervalMillis: template.MinAutostartIntervalMillis,
+			InactivityTTLMillis:        ptr.Ref((30 * 24 * time.Hour).Milliseconds()),
+			DormantAutoDeleteTTLMillis: ptr.Ref((7 * 24 * time.Hour).Milliseconds()),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, (30 * 24 * time.Hour).Milliseconds(), updated.InactivityTTLMillis)
+		assert.Equal(t, (7 * 24 * time.Hour).Milliseconds(), updated.DormantAutoDeleteTTLMillis)
+
+		// Omitting the policy leaves it unchanged.
+		updated, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:                       template.Name,
+			Description:                "changed",
+			MaxTTLMillis:               template.MaxTTLMillis,
+			MinAutostartIntervalMillis: template.MinAutostartIntervalMillis,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, (30 * 24 * time.Hour).Milliseconds(), updated.InactivityTTLMillis)
+		assert.Equal(t, (7 * 24 * time.Hour).Milliseconds(), updated.DormantAutoDeleteTTLMillis)
+
+		_, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			InactivityTTLMillis: ptr.Ref(int64(-1)),
+		})
+		require.ErrorContains(t, err, "inactivity_ttl_ms: Must be a positive integer")
+	})
+
 	t.Run("NoMaxTTL", func(t *testing.T) {
 		t.Parallel()
 
@@ -483,6 +570,78 @@ func TestPatchTemplateMeta(t *testing.T) {
 	})
 }
 
+func TestUpdateTemplateOrganization(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Moves", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "another",
+		})
+		require.NoError(t, err)
+
+		updated, err := client.UpdateTemplateOrganization(ctx, template.ID, codersdk.UpdateTemplateOrganizationRequest{
+			OrganizationID: org.ID,
+		})
+		require.NoError(t, err)
+		require.Equal(t, org.ID, updated.OrganizationID)
+
+		movedVersion, err := client.TemplateVersion(ctx, version.ID)
+		require.NoError(t, err)
+		require.Equal(t, org.ID, movedVersion.OrganizationID)
+
+		movedWorkspace, err := client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Equal(t, updated.ID, movedWorkspace.TemplateID)
+
+		templates, err := client.TemplatesByOrganization(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Len(t, templates, 0)
+	})
+
+	t.Run("NameConflict", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "another",
+		})
+		require.NoError(t, err)
+		otherVersion := coderdtest.CreateTemplateVersion(t, client, org.ID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, otherVersion.ID)
+		_, err = client.CreateTemplate(ctx, org.ID, codersdk.CreateTemplateRequest{
+			Name:      template.Name,
+			VersionID: otherVersion.ID,
+		})
+		require.NoError(t, err)
+
+		_, err = client.UpdateTemplateOrganization(ctx, template.ID, codersdk.UpdateTemplateOrganizationRequest{
+			OrganizationID: org.ID,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+}
+
 func TestDeleteTemplate(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,26 @@
+//go:build !windows
+// +build !windows
+
+package templatesync
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setGitProcessGroup starts git in a new process group, so the processes it
+// starts for remotes, like ssh, can be killed with it.
+func setGitProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// killGitProcessGroup kills git and every process it started.
+func killGitProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process == nil {
+		return
+	}
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,17 @@
+//go:build windows
+// +build windows
+
+package templatesync
+
+import "os/exec"
+
+func setGitProcessGroup(_ *exec.Cmd) {}
+
+// killGitProcessGroup kills git. Processes it started are left running, since
+// Windows doesn't have process groups.
+func killGitProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process == nil {
+		return
+	}
+	_ = cmd.Process.Kill()
+}
@@ -0,0 +1,643 @@
+// Package templatesync keeps templates in sync with the git repositories
+// they're sourced from. Whenever the ref of a repository points at a new
+// commit, a template version is created from it. Versions can be promoted
+// automatically once a dry run of them succeeds.
+package templatesync
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cdr.dev/slog"
+	"github.com/google/uuid"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/tabbed/pqtype"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/blobstore"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisionersdk"
+)
+
+// Syncer periodically syncs every template that has a git source.
+type Syncer struct {
+	ctx   context.Context
+	db    database.Store
+	store blobstore.Store
+	log   slog.Logger
+	tick  <-chan time.Time
+}
+
+// New returns a syncer for templates sourced from git repositories.
+func New(ctx context.Context, db database.Store, store blobstore.Store, log slog.Logger, tick <-chan time.Time) *Syncer {
+	return &Syncer{
+		ctx:   ctx,
+		db:    db,
+		store: store,
+		log:   log,
+		tick:  tick,
+	}
+}
+
+// Run syncs templates on every tick from its channel. It will stop when its
+// context is Done, or when its channel is closed.
+func (s *Syncer) Run() {
+	go func() {
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case _, ok := <-s.tick:
+				if !ok {
+					return
+				}
+				s.runOnce()
+			}
+		}
+	}()
+}
+
+func (s *Syncer) runOnce() {
+	sources, err := s.db.GetTemplateGitSources(s.ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		s.log.Error(s.ctx, "get template git sources", slog.Error(err))
+		return
+	}
+	for _, source := range sources {
+		_, err := Sync(s.ctx, s.db, s.store, source)
+		if errors.Is(err, ErrSyncInProgress) {
+			// Another replica, or a user, is already syncing the template.
+			continue
+		}
+		if err != nil {
+			s.log.Warn(s.ctx, "sync template from git",
+				slog.F("template_id", source.TemplateID),
+				slog.F("repository_url", RedactURL(source.RepositoryUrl)),
+				slog.F("ref", source.Ref),
+				slog.Error(err))
+		}
+	}
+}
+
+// ErrSyncInProgress is returned by Sync when the template is already being
+// synced.
+var ErrSyncInProgress = xerrors.New("template is already being synced")
+
+// staleSyncTimeout is how long a sync can run before it's assumed to have
+// crashed, and the template can be synced again. It's much longer than a sync
+// takes, since git commands time out.
+const staleSyncTimeout = time.Hour
+
+// Sync advances the sync of a single template. A pending template version is
+// promoted once its import and dry run succeed, and a new template version is
+// created if the ref points at a different commit than the last sync. The
+// outcome is recorded on the source, which is returned.
+//
+// Only one sync of a template runs at a time, including across replicas.
+// ErrSyncInProgress is returned with the current source if another sync is
+// running. The state of the source is read once the sync is acquired, so the
+// outcome of a sync that just finished isn't overwritten.
+func Sync(ctx context.Context, db database.Store, store blobstore.Store, source database.TemplateGitSource) (database.TemplateGitSource, error) {
+	now := database.Now()
+	acquired, err := db.AcquireTemplateGitSourceSync(ctx, database.AcquireTemplateGitSourceSyncParams{
+		TemplateID:    source.TemplateID,
+		SyncStartedAt: now,
+		StaleBefore:   now.Add(-staleSyncTimeout),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// The source is either being synced or was deleted.
+		current, err := db.GetTemplateGitSourceByTemplateID(ctx, source.TemplateID)
+		if err != nil {
+			return source, xerrors.Errorf("get template git source: %w", err)
+		}
+		return current, ErrSyncInProgress
+	}
+	if err != nil {
+		return source, xerrors.Errorf("acquire template git source sync: %w", err)
+	}
+	source = acquired
+
+	state := database.UpdateTemplateGitSourceSyncByTemplateIDParams{
+		TemplateID:         source.TemplateID,
+		CommitSha:          source.CommitSha,
+		PendingVersionID:   source.PendingVersionID,
+		PendingDryRunJobID: source.PendingDryRunJobID,
+		SyncedAt: sql.NullTime{
+			Time:  now,
+			Valid: true,
+		},
+	}
+	var syncErr error
+	template, err := db.GetTemplateByID(ctx, source.TemplateID)
+	if err != nil {
+		syncErr = xerrors.Errorf("get template: %w", err)
+	} else {
+		syncErr = sync(ctx, db, store, template, source, &state)
+	}
+	if syncErr != nil {
+		state.SyncError = syncErr.Error()
+	}
+	// This also releases the sync. It's recorded even if ctx is canceled, so
+	// the template isn't stuck until the sync is considered stale.
+	recordCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	err = db.UpdateTemplateGitSourceSyncByTemplateID(recordCtx, state)
+	if err != nil {
+		return source, xerrors.Errorf("update template git source: %w", err)
+	}
+	updated, err := db.GetTemplateGitSourceByTemplateID(ctx, source.TemplateID)
+	if err != nil {
+		return source, xerrors.Errorf("get template git source: %w", err)
+	}
+	return updated, syncErr
+}
+
+func sync(ctx context.Context, db database.Store, store blobstore.Store, template database.Template, source database.TemplateGitSource, state *database.UpdateTemplateGitSourceSyncByTemplateIDParams) error {
+	if state.PendingVersionID.Valid {
+		err := promote(ctx, db, template, source, state)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Checking the remote is much cheaper than fetching it, so the
+	// repository is only fetched when the ref has moved.
+	remoteSHA, err := remoteCommit(ctx, source.RepositoryUrl, source.Ref)
+	if err != nil {
+		return err
+	}
+	if state.CommitSha != "" && (remoteSHA == state.CommitSha || source.Ref == state.CommitSha) {
+		return nil
+	}
+
+	directory, err := os.MkdirTemp("", "coder-template-sync-")
+	if err != nil {
+		return xerrors.Errorf("create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(directory)
+	commit, err := Checkout(ctx, directory, source.RepositoryUrl, source.Ref)
+	if err != nil {
+		return err
+	}
+	if commit.CommitSHA == state.CommitSha {
+		return nil
+	}
+	// Templates created from a repository with "coder templates create"
+	// are already at the commit on their first sync.
+	activeCommit, err := versionCommit(ctx, db, template.ActiveVersionID)
+	if err != nil {
+		return err
+	}
+	if commit.CommitSHA == activeCommit {
+		state.CommitSha = commit.CommitSHA
+		return nil
+	}
+
+	templateDirectory, err := SourceDirectory(directory, source.Subdirectory)
+	if err != nil {
+		return err
+	}
+	archive, err := provisionersdk.Tar(templateDirectory, provisionersdk.TemplateArchiveLimit)
+	if err != nil {
+		return xerrors.Errorf("archive template: %w", err)
+	}
+	version, err := createVersion(ctx, db, store, template, source, commit, archive)
+	if err != nil {
+		return err
+	}
+
+	state.CommitSha = commit.CommitSHA
+	state.PendingVersionID = uuid.NullUUID{}
+	state.PendingDryRunJobID = uuid.NullUUID{}
+	if source.AutoPromote {
+		// A newer version supersedes a version that's still waiting to be
+		// promoted.
+		state.PendingVersionID = uuid.NullUUID{
+			UUID:  version.ID,
+			Valid: true,
+		}
+	}
+	return nil
+}
+
+// promote advances the pending template version through its import and dry
+// run, and makes it the active version of the template once both succeed.
+// The pending version is cleared if either fails.
+func promote(ctx context.Context, db database.Store, template database.Template, source database.TemplateGitSource, state *database.UpdateTemplateGitSourceSyncByTemplateIDParams) error {
+	clearPending := func() {
+		state.PendingVersionID = uuid.NullUUID{}
+		state.PendingDryRunJobID = uuid.NullUUID{}
+	}
+	version, err := db.GetTemplateVersionByID(ctx, state.PendingVersionID.UUID)
+	if err != nil {
+		return xerrors.Errorf("get pending template version: %w", err)
+	}
+	importJob, err := db.GetProvisionerJobByID(ctx, version.JobID)
+	if err != nil {
+		return xerrors.Errorf("get import job: %w", err)
+	}
+	if !importJob.CompletedAt.Valid {
+		return nil
+	}
+	if err := jobError(importJob); err != nil {
+		clearPending()
+		return xerrors.Errorf("import template version %q: %w", version.Name, err)
+	}
+
+	if !state.PendingDryRunJobID.Valid {
+		dryRunJob, err := insertDryRunJob(ctx, db, source, version, importJob)
+		if err != nil {
+			return err
+		}
+		state.PendingDryRunJobID = uuid.NullUUID{
+			UUID:  dryRunJob.ID,
+			Valid: true,
+		}
+		return nil
+	}
+	dryRunJob, err := db.GetProvisionerJobByID(ctx, state.PendingDryRunJobID.UUID)
+	if err != nil {
+		return xerrors.Errorf("get dry-run job: %w", err)
+	}
+	if !dryRunJob.CompletedAt.Valid {
+		return nil
+	}
+	clearPending()
+	if err := jobError(dryRunJob); err != nil {
+		return xerrors.Errorf("dry run template version %q: %w", version.Name, err)
+	}
+	err = db.UpdateTemplateActiveVersionByID(ctx, database.UpdateTemplateActiveVersionByIDParams{
+		ID:              template.ID,
+		ActiveVersionID: version.ID,
+		UpdatedAt:       database.Now(),
+	})
+	if err != nil {
+		return xerrors.Errorf("update active template version: %w", err)
+	}
+	return nil
+}
+
+// versionCommit returns the commit a template version was created from, or an
+// empty string if it wasn't created from a git repository.
+func versionCommit(ctx context.Context, db database.Store, versionID uuid.UUID) (string, error) {
+	version, err := db.GetTemplateVersionByID(ctx, versionID)
+	if err != nil {
+		return "", xerrors.Errorf("get template version: %w", err)
+	}
+	if !version.GitMetadata.Valid {
+		return "", nil
+	}
+	var metadata codersdk.TemplateVersionGitMetadata
+	err = json.Unmarshal(version.GitMetadata.RawMessage, &metadata)
+	if err != nil {
+		return "", xerrors.Errorf("unmarshal git metadata: %w", err)
+	}
+	return metadata.CommitSHA, nil
+}
+
+func jobError(job database.ProvisionerJob) error {
+	if job.CanceledAt.Valid {
+		return xerrors.New("job was canceled")
+	}
+	if job.Error.Valid {
+		return xerrors.New(job.Error.String)
+	}
+	return nil
+}
+
+func insertDryRunJob(ctx context.Context, db database.Store, source database.TemplateGitSource, version database.TemplateVersion, importJob database.ProvisionerJob) (database.ProvisionerJob, error) {
+	// This matches the input of "template_version_dry_run" jobs. Workspace
+	// parameters use their defaults.
+	input, err := json.Marshal(struct {
+		TemplateVersionID uuid.UUID                 `json:"template_version_id"`
+		WorkspaceName     string                    `json:"workspace_name"`
+		ParameterValues   []database.ParameterValue `json:"parameter_values"`
+	}{
+		TemplateVersionID: version.ID,
+		WorkspaceName:     "git-sync",
+		ParameterValues:   []database.ParameterValue{},
+	})
+	if err != nil {
+		return database.ProvisionerJob{}, xerrors.Errorf("marshal dry-run job input: %w", err)
+	}
+	now := database.Now()
+	job, err := db.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+		ID:             uuid.New(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		OrganizationID: version.OrganizationID,
+		InitiatorID:    source.CreatedBy,
+		Provisioner:    importJob.Provisioner,
+		StorageMethod:  importJob.StorageMethod,
+		StorageSource:  importJob.StorageSource,
+		Type:           database.ProvisionerJobTypeTemplateVersionDryRun,
+		Input:          input,
+	})
+	if err != nil {
+		return database.ProvisionerJob{}, xerrors.Errorf("insert dry-run job: %w", err)
+	}
+	return job, nil
+}
+
+// createVersion creates a template version from archive. Parameter values of
+// the import job of the active version are reused, the same way
+// "coder templates push" does.
+func createVersion(ctx context.Context, db database.Store, store blobstore.Store, template database.Template, source database.TemplateGitSource, commit Commit, archive []byte) (database.TemplateVersion, error) {
+	activeVersion, err := db.GetTemplateVersionByID(ctx, template.ActiveVersionID)
+	if err != nil {
+		return database.TemplateVersion{}, xerrors.Errorf("get active template version: %w", err)
+	}
+	activeJob, err := db.GetProvisionerJobByID(ctx, activeVersion.JobID)
+	if err != nil {
+		return database.TemplateVersion{}, xerrors.Errorf("get active template version job: %w", err)
+	}
+	parameterValues, err := db.ParameterValues(ctx, database.ParameterValuesParams{
+		Scopes:   []database.ParameterScope{database.ParameterScopeImportJob},
+		ScopeIds: []uuid.UUID{activeJob.ID},
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return database.TemplateVersion{}, xerrors.Errorf("get active template version parameters: %w", err)
+	}
+	gitMetadata, err := json.Marshal(commit.TemplateVersionGitMetadata)
+	if err != nil {
+		return database.TemplateVersion{}, xerrors.Errorf("marshal git metadata: %w", err)
+	}
+
+	hash := sha256.Sum256(archive)
+	key := hex.EncodeToString(hash[:])
+	_, err = blobstore.StoreFile(ctx, db, store, database.InsertFileParams{
+		Hash:      key,
+		CreatedAt: database.Now(),
+		CreatedBy: source.CreatedBy,
+		Mimetype:  "application/x-tar",
+	}, bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return database.TemplateVersion{}, xerrors.Errorf("store template archive: %w", err)
+	}
+
+	var version database.TemplateVersion
+	err = db.InTx(func(db database.Store) error {
+		now := database.Now()
+		jobID := uuid.New()
+		for _, parameterValue := range parameterValues {
+			_, err = db.InsertParameterValue(ctx, database.InsertParameterValueParams{
+				ID:                uuid.New(),
+				Name:              parameterValue.Name,
+				CreatedAt:         now,
+				UpdatedAt:         now,
+				Scope:             database.ParameterScopeImportJob,
+				ScopeID:           jobID,
+				SourceScheme:      parameterValue.SourceScheme,
+				SourceValue:       parameterValue.SourceValue,
+				DestinationScheme: parameterValue.DestinationScheme,
+			})
+			if err != nil {
+				return xerrors.Errorf("insert parameter value %q: %w", parameterValue.Name, err)
+			}
+		}
+
+		job, err := db.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+			ID:             jobID,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+			OrganizationID: template.OrganizationID,
+			InitiatorID:    source.CreatedBy,
+			Provisioner:    template.Provisioner,
+			StorageMethod:  database.ProvisionerStorageMethodFile,
+			StorageSource:  key,
+			Type:           database.ProvisionerJobTypeTemplateVersionImport,
+			Input:          []byte{'{', '}'},
+		})
+		if err != nil {
+			return xerrors.Errorf("insert provisioner job: %w", err)
+		}
+
+		version, err = db.InsertTemplateVersion(ctx, database.InsertTemplateVersionParams{
+			ID: uuid.New(),
+			TemplateID: uuid.NullUUID{
+				UUID:  template.ID,
+				Valid: true,
+			},
+			OrganizationID: template.OrganizationID,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+			Name:           namesgenerator.GetRandomName(1),
+			JobID:          job.ID,
+			CreatedBy: uuid.NullUUID{
+				UUID:  source.CreatedBy,
+				Valid: true,
+			},
+			Message: commit.Subject,
+			GitMetadata: pqtype.NullRawMessage{
+				RawMessage: gitMetadata,
+				Valid:      true,
+			},
+		})
+		if err != nil {
+			return xerrors.Errorf("insert template version: %w", err)
+		}
+
+		// Template variables aren't stored in the repository, so they're
+		// kept from the active version.
+		variables, err := db.GetTemplateVersionVariables(ctx, template.ActiveVersionID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return xerrors.Errorf("get template variables: %w", err)
+		}
+		for _, variable := range variables {
+			_, err = db.InsertTemplateVersionVariable(ctx, database.InsertTemplateVersionVariableParams{
+				TemplateVersionID: version.ID,
+				Name:              variable.Name,
+				Value:             variable.Value,
+				Sensitive:         variable.Sensitive,
+			})
+			if err != nil {
+				return xerrors.Errorf("insert template variable %q: %w", variable.Name, err)
+			}
+		}
+		return nil
+	})
+	return version, err
+}
+
+// SourceDirectory returns the directory of the template within a checkout of
+// its repository. The subdirectory must not point outside of the checkout.
+func SourceDirectory(checkout, subdirectory string) (string, error) {
+	if err := ValidateSubdirectory(subdirectory); err != nil {
+		return "", err
+	}
+	directory := filepath.Join(checkout, filepath.FromSlash(subdirectory))
+	info, err := os.Stat(directory)
+	if err != nil {
+		return "", xerrors.Errorf("subdirectory %q does not exist in the repository", subdirectory)
+	}
+	if !info.IsDir() {
+		return "", xerrors.Errorf("subdirectory %q is not a directory", subdirectory)
+	}
+	return directory, nil
+}
+
+// ValidateSubdirectory returns an error if subdirectory isn't a relative path
+// within a repository.
+func ValidateSubdirectory(subdirectory string) error {
+	if subdirectory == "" {
+		return nil
+	}
+	cleaned := filepath.ToSlash(filepath.Clean(filepath.FromSlash(subdirectory)))
+	if filepath.IsAbs(subdirectory) || strings.HasPrefix(subdirectory, "/") ||
+		cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return xerrors.Errorf("subdirectory %q must be a relative path within the repository", subdirectory)
+	}
+	return nil
+}
+
+// RedactURL removes credentials from a repository URL so they aren't shown
+// to users or stored with template versions.
+func RedactURL(repositoryURL string) string {
+	parsed, err := url.Parse(repositoryURL)
+	if err != nil || parsed.User == nil || parsed.Scheme == "" {
+		// SCP-like URLs such as "git@github.com:coder/coder.git" don't
+		// contain credentials.
+		return repositoryURL
+	}
+	parsed.User = nil
+	return parsed.String()
+}
+
+// Commit is the commit a repository was checked out at.
+type Commit struct {
+	codersdk.TemplateVersionGitMetadata
+	// Subject is the first line of the commit message.
+	Subject string
+}
+
+// gitTimeout limits how long a git command runs, so an unresponsive remote
+// can't hold up syncing templates.
+const gitTimeout = 5 * time.Minute
+
+func git(ctx context.Context, directory string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitTimeout)
+	defer cancel()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = directory
+	// Never prompt for credentials. They must be in the repository URL or
+	// configured for the user coderd runs as.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	setGitProcessGroup(cmd)
+	err := cmd.Start()
+	if err != nil {
+		return "", xerrors.Errorf("git %s: %w", args[0], err)
+	}
+	// Killing only git would leave the processes it started for remotes
+	// running, and waiting for git waits for their output too.
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			killGitProcessGroup(cmd)
+		case <-done:
+		}
+	}()
+	err = cmd.Wait()
+	close(done)
+	if ctx.Err() != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", xerrors.Errorf("git %s: timed out after %s", args[0], gitTimeout)
+		}
+		return "", ctx.Err()
+	}
+	if err != nil {
+		return "", xerrors.Errorf("git %s: %w: %s", args[0], err, redactOutput(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// redactOutput removes credentials from URLs git prints in errors.
+func redactOutput(output string) string {
+	fields := strings.Fields(output)
+	for i, field := range fields {
+		if strings.Contains(field, "://") {
+			fields[i] = RedactURL(strings.Trim(field, "'\""))
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// remoteCommit returns the commit ref points at in the remote repository, or
+// an empty string if ref isn't a branch or tag, such as a commit SHA.
+func remoteCommit(ctx context.Context, repositoryURL, ref string) (string, error) {
+	out, err := git(ctx, "", "ls-remote", "--", repositoryURL, ref)
+	if err != nil {
+		return "", err
+	}
+	sha := ""
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		// Annotated tags are listed twice. The peeled entry is the commit
+		// the tag points at.
+		if strings.HasSuffix(fields[1], "^{}") {
+			return fields[0], nil
+		}
+		if sha == "" {
+			sha = fields[0]
+		}
+	}
+	return sha, nil
+}
+
+// Checkout fetches ref of the repository into directory without its history.
+// Credentials for private repositories can be included in the URL.
+func Checkout(ctx context.Context, directory, repositoryURL, ref string) (Commit, error) {
+	_, err := git(ctx, directory, "init", "--quiet")
+	if err != nil {
+		return Commit{}, err
+	}
+	_, err = git(ctx, directory, "fetch", "--quiet", "--depth", "1", "--", repositoryURL, ref)
+	if err != nil {
+		return Commit{}, err
+	}
+	_, err = git(ctx, directory, "checkout", "--quiet", "FETCH_HEAD")
+	if err != nil {
+		return Commit{}, err
+	}
+	sha, err := git(ctx, directory, "rev-parse", "HEAD")
+	if err != nil {
+		return Commit{}, err
+	}
+	commit := Commit{
+		TemplateVersionGitMetadata: codersdk.TemplateVersionGitMetadata{
+			CommitSHA: sha,
+			RemoteURL: RedactURL(repositoryURL),
+		},
+	}
+	if ref != sha {
+		commit.Branch = ref
+	}
+	if author, err := git(ctx, directory, "log", "-1", "--format=%an <%ae>"); err == nil {
+		commit.Author = author
+	}
+	if subject, err := git(ctx, directory, "log", "-1", "--format=%s"); err == nil {
+		commit.Subject = subject
+	}
+	return commit, nil
+}
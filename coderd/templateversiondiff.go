@@ -0,0 +1,192 @@
+package coderd
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pkg/diff"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/blobstore"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// templateVersionDiff returns a file-by-file unified diff between the source
+// archive of the template version in the path and the target version.
+func (api *API) templateVersionDiff(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx         = r.Context()
+		fromVersion = httpmw.TemplateVersionParam(r)
+		target      = chi.URLParam(r, "target")
+	)
+	if !api.Authorize(r, rbac.ActionRead, fromVersion) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	toVersionID, err := uuid.Parse(target)
+	if err != nil {
+		httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Template version ID %q must be a valid UUID.", target),
+			Detail:  err.Error(),
+		})
+		return
+	}
+	toVersion, err := api.Database.GetTemplateVersionByID(ctx, toVersionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !api.Authorize(r, rbac.ActionRead, toVersion) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	fromFiles, err := templateVersionSourceFiles(ctx, api.Database, api.BlobStore, fromVersion)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: fmt.Sprintf("Internal error reading the source of template version %q.", fromVersion.Name),
+			Detail:  err.Error(),
+		})
+		return
+	}
+	toFiles, err := templateVersionSourceFiles(ctx, api.Database, api.BlobStore, toVersion)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: fmt.Sprintf("Internal error reading the source of template version %q.", toVersion.Name),
+			Detail:  err.Error(),
+		})
+		return
+	}
+	files, err := diffSourceFiles(fromFiles, toFiles)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error computing diff.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(rw, http.StatusOK, codersdk.TemplateVersionDiff{
+		From:  fromVersion.ID,
+		To:    toVersion.ID,
+		Files: files,
+	})
+}
+
+// templateVersionSourceFiles returns the contents of the regular files in the
+// source archive of a template version, keyed by path.
+func templateVersionSourceFiles(ctx context.Context, db database.Store, store blobstore.Store, version database.TemplateVersion) (map[string][]byte, error) {
+	job, err := db.GetProvisionerJobByID(ctx, version.JobID)
+	if err != nil {
+		return nil, xerrors.Errorf("get provisioner job: %w", err)
+	}
+	if job.StorageMethod != database.ProvisionerStorageMethodFile {
+		return nil, xerrors.Errorf("unsupported storage method %q", job.StorageMethod)
+	}
+	archive, err := blobstore.ReadAll(ctx, store, job.StorageSource)
+	if err != nil {
+		return nil, xerrors.Errorf("read source archive: %w", err)
+	}
+
+	files := map[string][]byte{}
+	reader := tar.NewReader(bytes.NewReader(archive))
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, xerrors.Errorf("read %q: %w", header.Name, err)
+		}
+		files[path.Clean(header.Name)] = data
+	}
+	return files, nil
+}
+
+// diffSourceFiles returns the files that differ between two source archives,
+// sorted by path.
+func diffSourceFiles(from, to map[string][]byte) ([]codersdk.TemplateVersionFileDiff, error) {
+	paths := make([]string, 0, len(from)+len(to))
+	for name := range from {
+		paths = append(paths, name)
+	}
+	for name := range to {
+		if _, ok := from[name]; !ok {
+			paths = append(paths, name)
+		}
+	}
+	sort.Strings(paths)
+
+	diffs := make([]codersdk.TemplateVersionFileDiff, 0)
+	for _, name := range paths {
+		fromData, inFrom := from[name]
+		toData, inTo := to[name]
+		if inFrom && inTo && bytes.Equal(fromData, toData) {
+			continue
+		}
+
+		fileDiff := codersdk.TemplateVersionFileDiff{
+			Path:   name,
+			Status: codersdk.TemplateVersionFileDiffStatusModified,
+		}
+		fromName, toName := "a/"+name, "b/"+name
+		switch {
+		case !inFrom:
+			fileDiff.Status = codersdk.TemplateVersionFileDiffStatusAdded
+			fromName = "/dev/null"
+		case !inTo:
+			fileDiff.Status = codersdk.TemplateVersionFileDiffStatusRemoved
+			toName = "/dev/null"
+		}
+		if isBinary(fromData) || isBinary(toData) {
+			fileDiff.Binary = true
+			diffs = append(diffs, fileDiff)
+			continue
+		}
+
+		var buf bytes.Buffer
+		err := diff.Text(fromName, toName, fromData, toData, &buf)
+		if err != nil {
+			return nil, xerrors.Errorf("diff %q: %w", name, err)
+		}
+		fileDiff.Diff = buf.String()
+		diffs = append(diffs, fileDiff)
+	}
+	return diffs, nil
+}
+
+// isBinary guesses whether data is binary the same way git does, by looking
+// for a NUL byte. Data that isn't valid UTF-8 is also treated as binary.
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data, 0) != -1 || !utf8.Valid(data)
+}
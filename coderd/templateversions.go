@@ -11,6 +11,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/tabbed/pqtype"
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/coderd/database"
@@ -46,7 +47,16 @@ func (api *API) templateVersion(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httpapi.Write(rw, http.StatusOK, convertTemplateVersion(templateVersion, convertProvisionerJob(job), createdByName))
+	apiVersion, err := convertTemplateVersion(templateVersion, convertProvisionerJob(job), createdByName)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error converting template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(rw, http.StatusOK, apiVersion)
 }
 
 func (api *API) patchCancelTemplateVersion(rw http.ResponseWriter, r *http.Request) {
@@ -95,6 +105,34 @@ func (api *API) patchCancelTemplateVersion(rw http.ResponseWriter, r *http.Reque
 	})
 }
 
+func (api *API) templateVersionVariables(rw http.ResponseWriter, r *http.Request) {
+	templateVersion := httpmw.TemplateVersionParam(r)
+	// Variables are set by template admins and may hold secrets, so reading
+	// them requires the same permission as changing the template.
+	if !api.Authorize(r, rbac.ActionUpdate, templateVersion) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	variables, err := api.Database.GetTemplateVersionVariables(r.Context(), templateVersion.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template variables.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	apiVariables := make([]codersdk.TemplateVersionVariable, 0, len(variables))
+	for _, variable := range variables {
+		apiVariables = append(apiVariables, convertTemplateVersionVariable(variable))
+	}
+	httpapi.Write(rw, http.StatusOK, apiVariables)
+}
+
 func (api *API) templateVersionSchema(rw http.ResponseWriter, r *http.Request) {
 	templateVersion := httpmw.TemplateVersionParam(r)
 	if !api.Authorize(r, rbac.ActionRead, templateVersion) {
@@ -494,7 +532,15 @@ func (api *API) templateVersionsByTemplate(rw http.ResponseWriter, r *http.Reque
 				})
 				return err
 			}
-			apiVersions = append(apiVersions, convertTemplateVersion(version, convertProvisionerJob(job), createdByName))
+			apiVersion, err := convertTemplateVersion(version, convertProvisionerJob(job), createdByName)
+			if err != nil {
+				httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+					Message: "Internal error converting template version.",
+					Detail:  err.Error(),
+				})
+				return err
+			}
+			apiVersions = append(apiVersions, apiVersion)
 		}
 
 		return nil
@@ -552,7 +598,16 @@ func (api *API) templateVersionByName(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httpapi.Write(rw, http.StatusOK, convertTemplateVersion(templateVersion, convertProvisionerJob(job), createdByName))
+	apiVersion, err := convertTemplateVersion(templateVersion, convertProvisionerJob(job), createdByName)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error converting template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(rw, http.StatusOK, apiVersion)
 }
 
 func (api *API) patchActiveTemplateVersion(rw http.ResponseWriter, r *http.Request) {
@@ -619,8 +674,10 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 		return
 	}
 
+	var template database.Template
 	if req.TemplateID != uuid.Nil {
-		_, err := api.Database.GetTemplateByID(r.Context(), req.TemplateID)
+		var err error
+		template, err = api.Database.GetTemplateByID(r.Context(), req.TemplateID)
 		if errors.Is(err, sql.ErrNoRows) {
 			httpapi.Write(rw, http.StatusNotFound, codersdk.Response{
 				Message: "Template does not exist.",
@@ -636,6 +693,17 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 		}
 	}
 
+	variableNames := map[string]struct{}{}
+	for _, variable := range req.Variables {
+		if _, ok := variableNames[variable.Name]; ok {
+			httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Variable %q is set more than once.", variable.Name),
+			})
+			return
+		}
+		variableNames[variable.Name] = struct{}{}
+	}
+
 	file, err := api.Database.GetFileByHash(r.Context(), req.StorageSource)
 	if errors.Is(err, sql.ErrNoRows) {
 		httpapi.Write(rw, http.StatusNotFound, codersdk.Response{
@@ -745,8 +813,17 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 			return xerrors.Errorf("insert provisioner job: %w", err)
 		}
 
+		var gitMetadata pqtype.NullRawMessage
+		if req.Git != nil {
+			gitMetadata.RawMessage, err = json.Marshal(req.Git)
+			if err != nil {
+				return xerrors.Errorf("marshal git metadata: %w", err)
+			}
+			gitMetadata.Valid = true
+		}
+
 		var templateID uuid.NullUUID
-		if req.TemplateID != uuid.Nil {
+		if req.TemplateID != uuid.Nil && !req.Detached {
 			templateID = uuid.NullUUID{
 				UUID:  req.TemplateID,
 				Valid: true,
@@ -766,10 +843,32 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 				UUID:  apiKey.UserID,
 				Valid: true,
 			},
+			Message:     req.Message,
+			GitMetadata: gitMetadata,
 		})
 		if err != nil {
 			return xerrors.Errorf("insert template version: %w", err)
 		}
+
+		for _, variable := range req.Variables {
+			_, err = db.InsertTemplateVersionVariable(r.Context(), database.InsertTemplateVersionVariableParams{
+				TemplateVersionID: templateVersion.ID,
+				Name:              variable.Name,
+				Value:             variable.Value,
+				Sensitive:         variable.Sensitive,
+			})
+			if err != nil {
+				return xerrors.Errorf("insert template variable %q: %w", variable.Name, err)
+			}
+		}
+		if req.TemplateID != uuid.Nil {
+			// Variables that aren't set are kept from the active version, so
+			// admins don't have to pass secrets on every push.
+			err = inheritTemplateVersionVariables(r.Context(), db, template.ActiveVersionID, templateVersion.ID, variableNames)
+			if err != nil {
+				return xerrors.Errorf("inherit template variables: %w", err)
+			}
+		}
 		return nil
 	})
 	if err != nil {
@@ -788,7 +887,16 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 		return
 	}
 
-	httpapi.Write(rw, http.StatusCreated, convertTemplateVersion(templateVersion, convertProvisionerJob(provisionerJob), createdByName))
+	apiVersion, err := convertTemplateVersion(templateVersion, convertProvisionerJob(provisionerJob), createdByName)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error converting template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(rw, http.StatusCreated, apiVersion)
 }
 
 // templateVersionResources returns the workspace agent resources associated
@@ -847,7 +955,22 @@ func getUsernameByUserID(ctx context.Context, db database.Store, userID uuid.Nul
 	return user.Username, nil
 }
 
-func convertTemplateVersion(version database.TemplateVersion, job codersdk.ProvisionerJob, createdByName string) codersdk.TemplateVersion {
+func convertTemplateVersion(version database.TemplateVersion, job codersdk.ProvisionerJob, createdByName string) (codersdk.TemplateVersion, error) {
+	var git *codersdk.TemplateVersionGitMetadata
+	if version.GitMetadata.Valid {
+		git = &codersdk.TemplateVersionGitMetadata{}
+		err := json.Unmarshal(version.GitMetadata.RawMessage, git)
+		if err != nil {
+			return codersdk.TemplateVersion{}, xerrors.Errorf("unmarshal git metadata: %w", err)
+		}
+	}
+	diagnostics := make([]codersdk.TemplateVersionDiagnostic, 0)
+	if len(version.Diagnostics) > 0 {
+		err := json.Unmarshal(version.Diagnostics, &diagnostics)
+		if err != nil {
+			return codersdk.TemplateVersion{}, xerrors.Errorf("unmarshal diagnostics: %w", err)
+		}
+	}
 	return codersdk.TemplateVersion{
 		ID:             version.ID,
 		TemplateID:     &version.TemplateID.UUID,
@@ -859,5 +982,47 @@ func convertTemplateVersion(version database.TemplateVersion, job codersdk.Provi
 		Readme:         version.Readme,
 		CreatedByID:    version.CreatedBy.UUID,
 		CreatedByName:  createdByName,
+		Message:        version.Message,
+		Git:            git,
+		Diagnostics:    diagnostics,
+	}, nil
+}
+
+// inheritTemplateVersionVariables copies the variables of a template version
+// that aren't in skip to another version.
+func inheritTemplateVersionVariables(ctx context.Context, db database.Store, from, to uuid.UUID, skip map[string]struct{}) error {
+	variables, err := db.GetTemplateVersionVariables(ctx, from)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return xerrors.Errorf("get template variables: %w", err)
+	}
+	for _, variable := range variables {
+		if _, ok := skip[variable.Name]; ok {
+			continue
+		}
+		_, err = db.InsertTemplateVersionVariable(ctx, database.InsertTemplateVersionVariableParams{
+			TemplateVersionID: to,
+			Name:              variable.Name,
+			Value:             variable.Value,
+			Sensitive:         variable.Sensitive,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert template variable %q: %w", variable.Name, err)
+		}
+	}
+	return nil
+}
+
+func convertTemplateVersionVariable(variable database.TemplateVersionVariable) codersdk.TemplateVersionVariable {
+	value := variable.Value
+	if variable.Sensitive {
+		value = ""
+	}
+	return codersdk.TemplateVersionVariable{
+		Name:      variable.Name,
+		Value:     value,
+		Sensitive: variable.Sensitive,
 	}
 }
@@ -1,6 +1,8 @@
 package coderd_test
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
 	"net/http"
 	"testing"
@@ -32,6 +34,61 @@ func TestTemplateVersion(t *testing.T) {
 		_, err := client.TemplateVersion(ctx, version.ID)
 		require.NoError(t, err)
 	})
+
+	t.Run("Diagnostics", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse: []*proto.Parse_Response{{
+				Type: &proto.Parse_Response_Complete{
+					Complete: &proto.Parse_Complete{
+						Diagnostics: []*proto.Diagnostic{{
+							Severity: proto.Diagnostic_WARNING,
+							Summary:  `Agent "main" isn't attached to a resource`,
+							Filename: "main.tf",
+							Line:     3,
+						}},
+					},
+				},
+			}},
+			Provision: echo.ProvisionComplete,
+		})
+		version = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		require.Equal(t, codersdk.ProvisionerJobSucceeded, version.Job.Status)
+		require.Equal(t, []codersdk.TemplateVersionDiagnostic{{
+			Severity: codersdk.TemplateVersionDiagnosticSeverityWarning,
+			Summary:  `Agent "main" isn't attached to a resource`,
+			Filename: "main.tf",
+			Line:     3,
+		}}, version.Diagnostics)
+	})
+
+	t.Run("DiagnosticErrors", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse: []*proto.Parse_Response{{
+				Type: &proto.Parse_Response_Complete{
+					Complete: &proto.Parse_Complete{
+						Diagnostics: []*proto.Diagnostic{{
+							Severity: proto.Diagnostic_ERROR,
+							Summary:  `Duplicate app name "code"`,
+							Filename: "apps.tf",
+							Line:     5,
+						}},
+					},
+				},
+			}},
+			Provision: echo.ProvisionComplete,
+		})
+		version = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		require.Equal(t, codersdk.ProvisionerJobFailed, version.Job.Status)
+		require.Contains(t, version.Job.Error, "validation errors")
+		require.Len(t, version.Diagnostics, 1)
+		require.Equal(t, codersdk.TemplateVersionDiagnosticSeverityError, version.Diagnostics[0].Severity)
+	})
 }
 
 func TestPostTemplateVersionsByOrganization(t *testing.T) {
@@ -103,6 +160,137 @@ func TestPostTemplateVersionsByOrganization(t *testing.T) {
 		})
 		require.NoError(t, err)
 	})
+
+	t.Run("WithMessage", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		data, err := echo.Tar(nil)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		file, err := client.Upload(ctx, codersdk.ContentTypeTar, data)
+		require.NoError(t, err)
+		git := &codersdk.TemplateVersionGitMetadata{
+			CommitSHA: "4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+			Branch:    "main",
+			RemoteURL: "https://github.com/coder/coder.git",
+			Author:    "Coder <dev@coder.com>",
+			Dirty:     true,
+		}
+		version, err := client.CreateTemplateVersion(ctx, user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			StorageMethod: codersdk.ProvisionerStorageMethodFile,
+			StorageSource: file.Hash,
+			Provisioner:   codersdk.ProvisionerTypeEcho,
+			Message:       "Use a larger disk",
+			Git:           git,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "Use a larger disk", version.Message)
+		require.Equal(t, git, version.Git)
+
+		version, err = client.TemplateVersion(ctx, version.ID)
+		require.NoError(t, err)
+		require.Equal(t, "Use a larger disk", version.Message)
+		require.Equal(t, git, version.Git)
+	})
+}
+
+func TestTemplateVersionDiff(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	createVersion := func(files map[string]string) codersdk.TemplateVersion {
+		var buffer bytes.Buffer
+		writer := tar.NewWriter(&buffer)
+		for name, content := range files {
+			err := writer.WriteHeader(&tar.Header{
+				Name: name,
+				Mode: 0o644,
+				Size: int64(len(content)),
+			})
+			require.NoError(t, err)
+			_, err = writer.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+		file, err := client.Upload(ctx, codersdk.ContentTypeTar, buffer.Bytes())
+		require.NoError(t, err)
+		version, err := client.CreateTemplateVersion(ctx, user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			StorageMethod: codersdk.ProvisionerStorageMethodFile,
+			StorageSource: file.Hash,
+			Provisioner:   codersdk.ProvisionerTypeEcho,
+		})
+		require.NoError(t, err)
+		return version
+	}
+	from := createVersion(map[string]string{
+		"main.tf":     "resource \"null_resource\" \"dev\" {\n  count = 1\n}\n",
+		"README.md":   "# Template\n",
+		"icon.png":    "\x89PNG\x00",
+		"unchanged":   "same\n",
+		"scripts/old": "echo old\n",
+	})
+	to := createVersion(map[string]string{
+		"main.tf":     "resource \"null_resource\" \"dev\" {\n  count = 2\n}\n",
+		"README.md":   "# Template\n",
+		"icon.png":    "\x89PNG\x01",
+		"unchanged":   "same\n",
+		"scripts/new": "echo new\n",
+	})
+
+	diff, err := client.TemplateVersionDiff(ctx, from.ID, to.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.ID, diff.From)
+	require.Equal(t, to.ID, diff.To)
+	require.Equal(t, []codersdk.TemplateVersionFileDiff{{
+		Path:   "icon.png",
+		Status: codersdk.TemplateVersionFileDiffStatusModified,
+		Binary: true,
+	}, {
+		Path:   "main.tf",
+		Status: codersdk.TemplateVersionFileDiffStatusModified,
+		Diff: `--- a/main.tf
++++ b/main.tf
+@@ -1,3 +1,3 @@
+ resource "null_resource" "dev" {
+-  count = 1
++  count = 2
+ }
+`,
+	}, {
+		Path:   "scripts/new",
+		Status: codersdk.TemplateVersionFileDiffStatusAdded,
+		Diff: `--- /dev/null
++++ b/scripts/new
+@@ -0,0 +1,1 @@
++echo new
+`,
+	}, {
+		Path:   "scripts/old",
+		Status: codersdk.TemplateVersionFileDiffStatusRemoved,
+		Diff: `--- a/scripts/old
++++ /dev/null
+@@ -1,1 +0,0 @@
+-echo old
+`,
+	}}, diff.Files)
+
+	// A version has no changes against itself.
+	diff, err = client.TemplateVersionDiff(ctx, from.ID, from.ID)
+	require.NoError(t, err)
+	require.Empty(t, diff.Files)
+
+	_, err = client.TemplateVersionDiff(ctx, from.ID, uuid.New())
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
 }
 
 func TestPatchCancelTemplateVersion(t *testing.T) {
@@ -347,6 +535,191 @@ func TestTemplateVersionParameters(t *testing.T) {
 	})
 }
 
+func TestTemplateVersionVariables(t *testing.T) {
+	t.Parallel()
+
+	createVersion := func(ctx context.Context, t *testing.T, client *codersdk.Client, organizationID uuid.UUID, req codersdk.CreateTemplateVersionRequest) codersdk.TemplateVersion {
+		data, err := echo.Tar(&echo.Responses{
+			Parse: []*proto.Parse_Response{{
+				Type: &proto.Parse_Response_Complete{
+					Complete: &proto.Parse_Complete{
+						ParameterSchemas: []*proto.ParameterSchema{{
+							Name: "region",
+							DefaultDestination: &proto.ParameterDestination{
+								Scheme: proto.ParameterDestination_PROVISIONER_VARIABLE,
+							},
+						}, {
+							Name: "token",
+							DefaultDestination: &proto.ParameterDestination{
+								Scheme: proto.ParameterDestination_PROVISIONER_VARIABLE,
+							},
+						}, {
+							Name: "size",
+							DefaultSource: &proto.ParameterSource{
+								Scheme: proto.ParameterSource_DATA,
+								Value:  "small",
+							},
+							DefaultDestination: &proto.ParameterDestination{
+								Scheme: proto.ParameterDestination_PROVISIONER_VARIABLE,
+							},
+						}},
+					},
+				},
+			}},
+			Provision: echo.ProvisionComplete,
+		})
+		require.NoError(t, err)
+		file, err := client.Upload(ctx, codersdk.ContentTypeTar, data)
+		require.NoError(t, err)
+		req.StorageMethod = codersdk.ProvisionerStorageMethodFile
+		req.StorageSource = file.Hash
+		req.Provisioner = codersdk.ProvisionerTypeEcho
+		version, err := client.CreateTemplateVersion(ctx, organizationID, req)
+		require.NoError(t, err)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		return version
+	}
+
+	t.Run("List", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		version := createVersion(ctx, t, client, user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			Variables: []codersdk.CreateTemplateVersionVariable{
+				{Name: "region", Value: "us-east-1"},
+				{Name: "token", Value: "hunter2", Sensitive: true},
+				{Name: "unused", Value: "value"},
+			},
+		})
+
+		// Users aren't prompted for template variables.
+		schemas, err := client.TemplateVersionSchema(ctx, version.ID)
+		require.NoError(t, err)
+		require.Len(t, schemas, 1)
+		require.Equal(t, "size", schemas[0].Name)
+
+		// Variables the template doesn't declare are dropped, and sensitive
+		// values are never returned.
+		variables, err := client.TemplateVersionVariables(ctx, version.ID)
+		require.NoError(t, err)
+		require.Equal(t, []codersdk.TemplateVersionVariable{
+			{Name: "region", Value: "us-east-1"},
+			{Name: "token", Sensitive: true},
+		}, variables)
+	})
+
+	t.Run("Inherit", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		version := createVersion(ctx, t, client, user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			Variables: []codersdk.CreateTemplateVersionVariable{
+				{Name: "region", Value: "us-east-1"},
+				{Name: "token", Value: "hunter2", Sensitive: true},
+			},
+		})
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		version = createVersion(ctx, t, client, user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			TemplateID: template.ID,
+			Variables: []codersdk.CreateTemplateVersionVariable{
+				{Name: "region", Value: "eu-west-1"},
+			},
+		})
+		variables, err := client.TemplateVersionVariables(ctx, version.ID)
+		require.NoError(t, err)
+		require.Equal(t, []codersdk.TemplateVersionVariable{
+			{Name: "region", Value: "eu-west-1"},
+			{Name: "token", Sensitive: true},
+		}, variables)
+	})
+
+	t.Run("InheritDetached", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		version := createVersion(ctx, t, client, user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			Variables: []codersdk.CreateTemplateVersionVariable{
+				{Name: "region", Value: "us-east-1"},
+				{Name: "token", Value: "hunter2", Sensitive: true},
+			},
+		})
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		detached := createVersion(ctx, t, client, user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			TemplateID: template.ID,
+			Detached:   true,
+		})
+		variables, err := client.TemplateVersionVariables(ctx, detached.ID)
+		require.NoError(t, err)
+		require.Equal(t, []codersdk.TemplateVersionVariable{
+			{Name: "region", Value: "us-east-1"},
+			{Name: "token", Sensitive: true},
+		}, variables)
+
+		// The version isn't added to the template.
+		versions, err := client.TemplateVersionsByTemplate(ctx, codersdk.TemplateVersionsByTemplateRequest{
+			TemplateID: template.ID,
+		})
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		data, err := echo.Tar(nil)
+		require.NoError(t, err)
+		file, err := client.Upload(ctx, codersdk.ContentTypeTar, data)
+		require.NoError(t, err)
+		_, err = client.CreateTemplateVersion(ctx, user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			StorageMethod: codersdk.ProvisionerStorageMethodFile,
+			StorageSource: file.Hash,
+			Provisioner:   codersdk.ProvisionerTypeEcho,
+			Variables: []codersdk.CreateTemplateVersionVariable{
+				{Name: "region", Value: "us-east-1"},
+				{Name: "region", Value: "eu-west-1"},
+			},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := member.TemplateVersionVariables(ctx, version.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
 func TestTemplateVersionResources(t *testing.T) {
 	t.Parallel()
 	t.Run("ListRunning", func(t *testing.T) {
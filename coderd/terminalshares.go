@@ -0,0 +1,366 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+	"nhooyr.io/websocket"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+const (
+	defaultTerminalShareTTL = time.Hour
+	maxTerminalShareTTL     = 24 * time.Hour
+)
+
+func (api *API) postTerminalShare(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx            = r.Context()
+		apiKey         = httpmw.APIKey(r)
+		workspaceAgent = httpmw.WorkspaceAgentParam(r)
+		workspace      = httpmw.WorkspaceParam(r)
+		req            codersdk.CreateTerminalShareRequest
+	)
+	// Sharing a terminal grants access to it, so it requires the same
+	// permission as opening one.
+	if !api.Authorize(r, rbac.ActionCreate, workspace.ExecutionRBAC()) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if !httpapi.Read(rw, r, &req) {
+		return
+	}
+	ttl := defaultTerminalShareTTL
+	if req.TTLMillis > 0 {
+		ttl = time.Duration(req.TTLMillis) * time.Millisecond
+	}
+	if ttl > maxTerminalShareTTL {
+		httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid terminal share.",
+			Validations: []codersdk.ValidationError{{
+				Field:  "ttl_ms",
+				Detail: fmt.Sprintf("Must be at most %s.", maxTerminalShareTTL),
+			}},
+		})
+		return
+	}
+	for _, recipient := range req.Recipients {
+		_, err := api.Database.GetOrganizationMemberByUserID(ctx, database.GetOrganizationMemberByUserIDParams{
+			OrganizationID: workspace.OrganizationID,
+			UserID:         recipient,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Invalid terminal share.",
+				Validations: []codersdk.ValidationError{{
+					Field:  "recipients",
+					Detail: fmt.Sprintf("User %q isn't a member of the organization of the workspace.", recipient),
+				}},
+			})
+			return
+		}
+		if err != nil {
+			httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching organization member.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+
+	now := database.Now()
+	share, err := api.Database.InsertTerminalShare(ctx, database.InsertTerminalShareParams{
+		ID:          uuid.New(),
+		CreatedAt:   now,
+		WorkspaceID: workspace.ID,
+		AgentID:     workspaceAgent.ID,
+		ReconnectID: req.ReconnectID,
+		CreatedBy:   apiKey.UserID,
+		Mode:        database.TerminalShareMode(req.Mode),
+		Recipients:  req.Recipients,
+		ExpiresAt:   now.Add(ttl),
+	})
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating terminal share.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(rw, http.StatusCreated, api.convertTerminalShare(share))
+}
+
+func (api *API) terminalShares(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx            = r.Context()
+		workspaceAgent = httpmw.WorkspaceAgentParam(r)
+		workspace      = httpmw.WorkspaceParam(r)
+	)
+	if !api.Authorize(r, rbac.ActionCreate, workspace.ExecutionRBAC()) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	shares, err := api.Database.GetTerminalSharesByAgentID(ctx, workspaceAgent.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching terminal shares.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	apiShares := make([]codersdk.TerminalShare, 0, len(shares))
+	for _, share := range shares {
+		apiShares = append(apiShares, api.convertTerminalShare(share))
+	}
+	httpapi.Write(rw, http.StatusOK, apiShares)
+}
+
+func (api *API) terminalShare(rw http.ResponseWriter, r *http.Request) {
+	var (
+		share     = httpmw.TerminalShareParam(r)
+		workspace = httpmw.WorkspaceParam(r)
+	)
+	if !api.authorizeTerminalShareViewer(r, share, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	httpapi.Write(rw, http.StatusOK, api.convertTerminalShare(share))
+}
+
+func (api *API) deleteTerminalShare(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx       = r.Context()
+		share     = httpmw.TerminalShareParam(r)
+		workspace = httpmw.WorkspaceParam(r)
+	)
+	if !api.Authorize(r, rbac.ActionCreate, workspace.ExecutionRBAC()) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	err := api.Database.DeleteTerminalShareByID(ctx, share.ID)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting terminal share.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	api.terminalShareViewers.disconnect(share.ID)
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (api *API) terminalSharePTY(rw http.ResponseWriter, r *http.Request) {
+	api.websocketWaitMutex.Lock()
+	api.websocketWaitGroup.Add(1)
+	api.websocketWaitMutex.Unlock()
+	defer api.websocketWaitGroup.Done()
+
+	var (
+		ctx       = r.Context()
+		apiKey    = httpmw.APIKey(r)
+		share     = httpmw.TerminalShareParam(r)
+		workspace = httpmw.WorkspaceParam(r)
+	)
+	if !api.authorizeTerminalShareViewer(r, share, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	user, err := api.Database.GetUserByID(ctx, apiKey.UserID)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	workspaceAgent, err := api.Database.GetWorkspaceAgentByID(ctx, share.AgentID)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace agent.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	apiAgent, err := convertWorkspaceAgent(workspaceAgent, nil, api.AgentInactiveDisconnectTimeout)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error reading workspace agent.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if apiAgent.Status != codersdk.WorkspaceAgentConnected {
+		httpapi.Write(rw, http.StatusPreconditionRequired, codersdk.Response{
+			Message: fmt.Sprintf("Agent state is %q, it must be in the %q state.", apiAgent.Status, codersdk.WorkspaceAgentConnected),
+		})
+		return
+	}
+
+	conn, err := websocket.Accept(rw, r, &websocket.AcceptOptions{
+		CompressionMode: websocket.CompressionDisabled,
+	})
+	if err != nil {
+		httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Failed to accept websocket.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	ctx, wsNetConn := websocketNetConn(ctx, conn, websocket.MessageBinary)
+	defer wsNetConn.Close() // Also closes conn.
+
+	// Revoking the share disconnects everyone attached through it, and so
+	// does the share expiring.
+	ctx, cancel := context.WithDeadline(ctx, share.ExpiresAt)
+	defer cancel()
+	remove := api.terminalShareViewers.add(share.ID, codersdk.TerminalShareViewer{
+		UserID:      user.ID,
+		Username:    user.Username,
+		ConnectedAt: database.Now(),
+	}, cancel)
+	defer remove()
+
+	agentConn, release, err := api.workspaceAgentCache.Acquire(r, workspaceAgent.ID)
+	if err != nil {
+		_ = conn.Close(websocket.StatusInternalError, httpapi.WebsocketCloseSprintf("dial workspace agent: %s", err))
+		return
+	}
+	defer release()
+	// The agent enforces the mode of the share.
+	ptNetConn, err := agentConn.SharedReconnectingPTY(share.ReconnectID.String(), share.Mode == database.TerminalShareModeReadOnly)
+	if err != nil {
+		_ = conn.Close(websocket.StatusInternalError, httpapi.WebsocketCloseSprintf("dial: %s", err))
+		return
+	}
+	defer ptNetConn.Close()
+
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close(websocket.StatusNormalClosure, "terminal share closed")
+		_ = ptNetConn.Close()
+	}()
+	// Pipe the ends together!
+	go func() {
+		_, _ = io.Copy(wsNetConn, ptNetConn)
+		cancel()
+	}()
+	_, _ = io.Copy(ptNetConn, wsNetConn)
+}
+
+// authorizeTerminalShareViewer returns whether the user may join a share.
+// Those that can open terminals of the workspace always can, other users only
+// if they're a recipient of a share that hasn't expired.
+func (api *API) authorizeTerminalShareViewer(r *http.Request, share database.TerminalShare, workspace database.Workspace) bool {
+	if !database.Now().Before(share.ExpiresAt) {
+		return false
+	}
+	apiKey := httpmw.APIKey(r)
+	if slices.Contains(share.Recipients, apiKey.UserID) &&
+		api.Authorize(r, rbac.ActionRead, rbac.ResourceOrganization.InOrg(workspace.OrganizationID)) {
+		return true
+	}
+	return api.Authorize(r, rbac.ActionCreate, workspace.ExecutionRBAC())
+}
+
+func (api *API) convertTerminalShare(share database.TerminalShare) codersdk.TerminalShare {
+	return codersdk.TerminalShare{
+		ID:          share.ID,
+		CreatedAt:   share.CreatedAt,
+		WorkspaceID: share.WorkspaceID,
+		AgentID:     share.AgentID,
+		ReconnectID: share.ReconnectID,
+		CreatedBy:   share.CreatedBy,
+		Mode:        codersdk.TerminalShareMode(share.Mode),
+		Recipients:  share.Recipients,
+		ExpiresAt:   share.ExpiresAt,
+		Viewers:     api.terminalShareViewers.list(share.ID),
+	}
+}
+
+// terminalShareViewers tracks the users attached through terminal shares.
+// Connections are proxied by a single coderd, so viewers are kept in memory.
+type terminalShareViewers struct {
+	mutex   sync.Mutex
+	viewers map[uuid.UUID]map[uuid.UUID]terminalShareViewer
+}
+
+type terminalShareViewer struct {
+	codersdk.TerminalShareViewer
+	cancel context.CancelFunc
+}
+
+func newTerminalShareViewers() *terminalShareViewers {
+	return &terminalShareViewers{
+		viewers: map[uuid.UUID]map[uuid.UUID]terminalShareViewer{},
+	}
+}
+
+// add registers a viewer of a share. cancel is called when the share is
+// revoked, and the returned function removes the viewer again.
+func (v *terminalShareViewers) add(shareID uuid.UUID, viewer codersdk.TerminalShareViewer, cancel context.CancelFunc) func() {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	connections, ok := v.viewers[shareID]
+	if !ok {
+		connections = map[uuid.UUID]terminalShareViewer{}
+		v.viewers[shareID] = connections
+	}
+	id := uuid.New()
+	connections[id] = terminalShareViewer{
+		TerminalShareViewer: viewer,
+		cancel:              cancel,
+	}
+	return func() {
+		v.mutex.Lock()
+		defer v.mutex.Unlock()
+		delete(v.viewers[shareID], id)
+		if len(v.viewers[shareID]) == 0 {
+			delete(v.viewers, shareID)
+		}
+	}
+}
+
+// list returns the viewers of a share, longest connected first.
+func (v *terminalShareViewers) list(shareID uuid.UUID) []codersdk.TerminalShareViewer {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	viewers := make([]codersdk.TerminalShareViewer, 0, len(v.viewers[shareID]))
+	for _, viewer := range v.viewers[shareID] {
+		viewers = append(viewers, viewer.TerminalShareViewer)
+	}
+	sort.Slice(viewers, func(i, j int) bool {
+		return viewers[i].ConnectedAt.Before(viewers[j].ConnectedAt)
+	})
+	return viewers
+}
+
+// disconnect closes the connections of every viewer of a share.
+func (v *terminalShareViewers) disconnect(shareID uuid.UUID) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	for _, viewer := range v.viewers[shareID] {
+		viewer.cancel()
+	}
+	delete(v.viewers, shareID)
+}
@@ -0,0 +1,241 @@
+package coderd_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTerminalShares(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("ConPTY appears to be inconsistent on Windows.")
+	}
+	client := coderdtest.New(t, &coderdtest.Options{
+		IncludeProvisionerD: true,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	authToken := uuid.NewString()
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:           echo.ParseComplete,
+		ProvisionDryRun: echo.ProvisionComplete,
+		Provision: []*proto.Provision_Response{{
+			Type: &proto.Provision_Response_Complete{
+				Complete: &proto.Provision_Complete{
+					Resources: []*proto.Resource{{
+						Name: "example",
+						Type: "aws_instance",
+						Agents: []*proto.Agent{{
+							Id: uuid.NewString(),
+							Auth: &proto.Agent_Token{
+								Token: authToken,
+							},
+						}},
+					}},
+				},
+			},
+		}},
+	})
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	agentClient := codersdk.New(client.URL)
+	agentClient.SessionToken = authToken
+	agentCloser := agent.New(agentClient.ListenWorkspaceAgent, &agent.Options{
+		Logger: slogtest.Make(t, nil),
+	})
+	defer func() {
+		_ = agentCloser.Close()
+	}()
+	resources := coderdtest.AwaitWorkspaceAgents(t, client, workspace.LatestBuild.ID)
+	agentID := resources[0].Agents[0].ID
+	viewerClient, viewer := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+	otherClient := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	reconnectID := uuid.New()
+	owner, err := client.WorkspaceAgentReconnectingPTY(ctx, agentID, reconnectID, 80, 80, "/bin/bash")
+	require.NoError(t, err)
+	defer owner.Close()
+
+	// Members can't list the shares of workspaces they don't own.
+	_, err = viewerClient.TerminalShares(ctx, agentID)
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+	share, err := client.CreateTerminalShare(ctx, agentID, codersdk.CreateTerminalShareRequest{
+		ReconnectID: reconnectID,
+		Mode:        codersdk.TerminalShareModeReadOnly,
+		Recipients:  []uuid.UUID{viewer.ID},
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.UserID, share.CreatedBy)
+	require.Equal(t, workspace.ID, share.WorkspaceID)
+	require.WithinDuration(t, time.Now().Add(time.Hour), share.ExpiresAt, time.Minute)
+
+	// Members that the share isn't shared with can't join it.
+	_, err = otherClient.TerminalShare(ctx, share.ID)
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	_, err = otherClient.TerminalSharePTY(ctx, share.ID)
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+	found, err := viewerClient.TerminalShare(ctx, share.ID)
+	require.NoError(t, err)
+	require.Equal(t, codersdk.TerminalShareModeReadOnly, found.Mode)
+
+	shared, err := viewerClient.TerminalSharePTY(ctx, share.ID)
+	require.NoError(t, err)
+	defer shared.Close()
+
+	require.Eventually(t, func() bool {
+		shares, err := client.TerminalShares(ctx, agentID)
+		return assert.NoError(t, err) && len(shares) == 1 &&
+			len(shares[0].Viewers) == 1 && shares[0].Viewers[0].UserID == viewer.ID
+	}, testutil.WaitShort, testutil.IntervalFast)
+
+	// Input of read-only viewers is dropped by the agent.
+	data, err := json.Marshal(agent.ReconnectingPTYRequest{
+		Data: "echo viewer-input\r\n",
+	})
+	require.NoError(t, err)
+	_, err = shared.Write(data)
+	require.NoError(t, err)
+	// Brief pause to reduce the likelihood that we send keystrokes while
+	// the shell is simultaneously sending a prompt.
+	time.Sleep(100 * time.Millisecond)
+	data, err = json.Marshal(agent.ReconnectingPTYRequest{
+		Data: "echo owner-input\r\n",
+	})
+	require.NoError(t, err)
+	_, err = owner.Write(data)
+	require.NoError(t, err)
+
+	bufRead := bufio.NewReader(shared)
+	for {
+		line, err := bufRead.ReadString('\n')
+		require.NoError(t, err)
+		require.NotContains(t, line, "viewer-input")
+		if strings.Contains(line, "owner-input") && !strings.Contains(line, "echo") {
+			break
+		}
+	}
+
+	// Revoking the share disconnects the viewer.
+	err = client.DeleteTerminalShare(ctx, share.ID)
+	require.NoError(t, err)
+	_, err = io.Copy(io.Discard, bufRead)
+	require.NoError(t, err)
+	_, err = viewerClient.TerminalShare(ctx, share.ID)
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+}
+
+func TestTerminalShareRecipients(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, &coderdtest.Options{
+		IncludeProvisionerD: true,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	authToken := uuid.NewString()
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:           echo.ParseComplete,
+		ProvisionDryRun: echo.ProvisionComplete,
+		Provision: []*proto.Provision_Response{{
+			Type: &proto.Provision_Response_Complete{
+				Complete: &proto.Provision_Complete{
+					Resources: []*proto.Resource{{
+						Name: "example",
+						Type: "aws_instance",
+						Agents: []*proto.Agent{{
+							Id: uuid.NewString(),
+							Auth: &proto.Agent_Token{
+								Token: authToken,
+							},
+						}},
+					}},
+				},
+			},
+		}},
+	})
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+	resources, err := client.WorkspaceResourcesByBuild(context.Background(), workspace.LatestBuild.ID)
+	require.NoError(t, err)
+	agentID := resources[0].Agents[0].ID
+	viewerClient, viewer := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+	t.Run("NotMember", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.CreateTerminalShare(ctx, agentID, codersdk.CreateTerminalShareRequest{
+			ReconnectID: uuid.New(),
+			Mode:        codersdk.TerminalShareModeReadOnly,
+			Recipients:  []uuid.UUID{uuid.New()},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("NoRecipients", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.CreateTerminalShare(ctx, agentID, codersdk.CreateTerminalShareRequest{
+			ReconnectID: uuid.New(),
+			Mode:        codersdk.TerminalShareModeReadOnly,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		share, err := client.CreateTerminalShare(ctx, agentID, codersdk.CreateTerminalShareRequest{
+			ReconnectID: uuid.New(),
+			Mode:        codersdk.TerminalShareModeReadOnly,
+			Recipients:  []uuid.UUID{viewer.ID},
+			TTLMillis:   1,
+		})
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+		_, err = viewerClient.TerminalShare(ctx, share.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
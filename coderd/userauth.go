@@ -15,9 +15,11 @@ import (
 	"golang.org/x/oauth2"
 	"golang.org/x/xerrors"
 
+	"cdr.dev/slog"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/codersdk"
 )
 
@@ -41,10 +43,19 @@ type GithubOAuth2Config struct {
 }
 
 func (api *API) userAuthMethods(rw http.ResponseWriter, _ *http.Request) {
+	providers := make([]codersdk.OIDCAuthMethod, 0, len(api.OIDCProviders))
+	for _, provider := range api.OIDCProviders {
+		providers = append(providers, codersdk.OIDCAuthMethod{
+			Name:        provider.Name,
+			DisplayName: provider.DisplayName,
+			IconURL:     provider.IconURL,
+		})
+	}
 	httpapi.Write(rw, http.StatusOK, codersdk.AuthMethods{
-		Password: true,
-		Github:   api.GithubOAuth2Config != nil,
-		OIDC:     api.OIDCConfig != nil,
+		Password:      true,
+		Github:        api.GithubOAuth2Config != nil,
+		OIDC:          api.OIDCConfig != nil,
+		OIDCProviders: providers,
 	})
 }
 
@@ -174,12 +185,77 @@ type OIDCConfig struct {
 	httpmw.OAuth2Config
 
 	Verifier *oidc.IDTokenVerifier
+	// Name identifies a provider in its callback URL. It is empty for the
+	// default provider, which is served at /api/v2/users/oidc/callback.
+	// Named providers are served at /api/v2/users/oidc/{name}/callback.
+	Name string
+	// DisplayName and IconURL are shown on the login button of a named
+	// provider.
+	DisplayName string
+	IconURL     string
+	// IssuerURL is the issuer of the provider. Providers must not share an
+	// issuer, since users are identified by their issuer and subject.
+	IssuerURL string
 	// EmailDomain is the domain to enforce when a user authenticates.
 	EmailDomain  string
 	AllowSignups bool
+	// RoleSync maps group or role claims to Coder roles. When set, the
+	// roles of a user are replaced with the mapped roles on every login.
+	RoleSync *OIDCRoleSync
+}
+
+// OIDCRoleSync configures how claims from an identity provider are
+// converted into site and organization roles.
+type OIDCRoleSync struct {
+	// Field is the name of the claim that contains the groups or roles
+	// of a user. The claim may be a string or an array of strings.
+	Field string
+	// Mapping maps a claim value to the roles it grants. Organization
+	// roles are specified in the "<role>:<organization-id>" format.
+	Mapping map[string][]string
+}
+
+// Roles returns the roles mapped from the claims of a user. Claim values
+// without a mapping are ignored.
+func (s *OIDCRoleSync) Roles(claims map[string]interface{}) []string {
+	var values []string
+	switch claim := claims[s.Field].(type) {
+	case string:
+		values = []string{claim}
+	case []interface{}:
+		for _, value := range claim {
+			str, ok := value.(string)
+			if !ok {
+				continue
+			}
+			values = append(values, str)
+		}
+	}
+
+	roles := make([]string, 0)
+	for _, value := range values {
+		roles = append(roles, s.Mapping[value]...)
+	}
+	return roles
 }
 
-func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
+// OIDCProvider returns the named OIDC provider or nil if it does not exist.
+func (api *API) OIDCProvider(name string) *OIDCConfig {
+	for _, provider := range api.OIDCProviders {
+		if provider.Name == name {
+			return provider
+		}
+	}
+	return nil
+}
+
+func (api *API) userOIDC(config *OIDCConfig) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		api.handleOIDCCallback(rw, r, config)
+	}
+}
+
+func (api *API) handleOIDCCallback(rw http.ResponseWriter, r *http.Request, config *OIDCConfig) {
 	var (
 		ctx   = r.Context()
 		state = httpmw.OAuth2(r)
@@ -194,7 +270,7 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	idToken, err := api.OIDCConfig.Verifier.Verify(ctx, rawIDToken)
+	idToken, err := config.Verifier.Verify(ctx, rawIDToken)
 	if err != nil {
 		httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
 			Message: "Failed to verify OIDC token.",
@@ -222,6 +298,19 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	var roles []string
+	if config.RoleSync != nil {
+		var rawClaims map[string]interface{}
+		err = idToken.Claims(&rawClaims)
+		if err != nil {
+			httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Failed to extract OIDC claims.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		roles = config.RoleSync.Roles(rawClaims)
+	}
 	if !claims.Verified {
 		httpapi.Write(rw, http.StatusForbidden, codersdk.Response{
 			Message: fmt.Sprintf("Verify the %q email address on your OIDC provider to authenticate!", claims.Email),
@@ -240,10 +329,10 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 		}
 		claims.Username = httpapi.UsernameFrom(claims.Username)
 	}
-	if api.OIDCConfig.EmailDomain != "" {
-		if !strings.HasSuffix(claims.Email, api.OIDCConfig.EmailDomain) {
+	if config.EmailDomain != "" {
+		if !strings.HasSuffix(claims.Email, config.EmailDomain) {
 			httpapi.Write(rw, http.StatusForbidden, codersdk.Response{
-				Message: fmt.Sprintf("Your email %q is not a part of the %q domain!", claims.Email, api.OIDCConfig.EmailDomain),
+				Message: fmt.Sprintf("Your email %q is not a part of the %q domain!", claims.Email, config.EmailDomain),
 			})
 			return
 		}
@@ -253,9 +342,12 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 		State:        state,
 		LinkedID:     oidcLinkedID(idToken),
 		LoginType:    database.LoginTypeOIDC,
-		AllowSignups: api.OIDCConfig.AllowSignups,
+		OIDCProvider: config.Name,
+		AllowSignups: config.AllowSignups,
 		Email:        claims.Email,
 		Username:     claims.Username,
+		SyncRoles:    config.RoleSync != nil,
+		Roles:        roles,
 	})
 	var httpErr httpError
 	if xerrors.As(err, &httpErr) {
@@ -286,12 +378,20 @@ type oauthLoginParams struct {
 	State     httpmw.OAuth2State
 	LinkedID  string
 	LoginType database.LoginType
+	// OIDCProvider is the name of the OIDC provider the user signed in
+	// with. It's empty for the default provider.
+	OIDCProvider string
 
 	// The following are necessary in order to
 	// create new users.
 	AllowSignups bool
 	Email        string
 	Username     string
+
+	// SyncRoles replaces the site and organization roles of the user
+	// with Roles on every login.
+	SyncRoles bool
+	Roles     []string
 }
 
 type httpError struct {
@@ -342,6 +442,15 @@ func (api *API) oauthLogin(r *http.Request, params oauthLoginParams) (*http.Cook
 			}
 		}
 
+		// Users found by their email address may be linked to another OIDC
+		// provider, whose link must not be taken over.
+		if link.UserID != uuid.Nil && link.OIDCProvider != params.OIDCProvider && link.LinkedID != params.LinkedID {
+			return httpError{
+				code: http.StatusForbidden,
+				msg:  fmt.Sprintf("Your account is linked to %s. Sign in with it instead.", oidcProviderDescription(link.OIDCProvider)),
+			}
+		}
+
 		// This can happen if a user is a built-in user but is signing in
 		// with OIDC for the first time.
 		if user.ID == uuid.Nil {
@@ -375,22 +484,25 @@ func (api *API) oauthLogin(r *http.Request, params oauthLoginParams) (*http.Cook
 				OAuthAccessToken:  params.State.Token.AccessToken,
 				OAuthRefreshToken: params.State.Token.RefreshToken,
 				OAuthExpiry:       params.State.Token.Expiry,
+				OIDCProvider:      params.OIDCProvider,
 			})
 			if err != nil {
 				return xerrors.Errorf("insert user link: %w", err)
 			}
 		}
 
-		// LEGACY: Remove 10/2022.
-		// We started tracking linked IDs later so it's possible for a user to be a
-		// pre-existing OAuth user and not have a linked ID.
+		// The link is updated when the user was found by their email
+		// address, or their provider was renamed.
+		// LEGACY: We started tracking linked IDs later so it's possible for
+		// a user to be a pre-existing OAuth user and not have a linked ID.
 		// The migration that added the user_links table could not populate
 		// the 'linked_id' field since it requires fields off the access token.
-		if link.LinkedID == "" {
+		if link.LinkedID != params.LinkedID || link.OIDCProvider != params.OIDCProvider {
 			link, err = tx.UpdateUserLinkedID(ctx, database.UpdateUserLinkedIDParams{
-				UserID:    user.ID,
-				LoginType: params.LoginType,
-				LinkedID:  params.LinkedID,
+				UserID:       user.ID,
+				LoginType:    params.LoginType,
+				LinkedID:     params.LinkedID,
+				OIDCProvider: params.OIDCProvider,
 			})
 			if err != nil {
 				return xerrors.Errorf("update user linked ID: %w", err)
@@ -434,6 +546,13 @@ func (api *API) oauthLogin(r *http.Request, params oauthLoginParams) (*http.Cook
 			}
 		}
 
+		if params.SyncRoles {
+			err = api.syncUserRoles(ctx, tx, user, params.Roles)
+			if err != nil {
+				return xerrors.Errorf("sync user roles: %w", err)
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -451,6 +570,79 @@ func (api *API) oauthLogin(r *http.Request, params oauthLoginParams) (*http.Cook
 	return cookie, nil
 }
 
+// syncUserRoles replaces the site and organization roles of a user with
+// the roles provided. Organization roles for organizations the user is not
+// a member of will add the user to that organization. Unknown roles are
+// skipped so a misconfigured mapping cannot lock users out.
+func (api *API) syncUserRoles(ctx context.Context, tx database.Store, user database.User, roles []string) error {
+	siteRoles := make([]string, 0)
+	orgRoles := make(map[uuid.UUID][]string)
+	for _, role := range roles {
+		if _, err := rbac.RoleByName(role); err != nil {
+			api.Logger.Warn(ctx, "skipping unknown role from identity provider",
+				slog.F("user_id", user.ID), slog.F("role", role))
+			continue
+		}
+		orgIDStr, ok := rbac.IsOrgRole(role)
+		if !ok {
+			siteRoles = append(siteRoles, role)
+			continue
+		}
+		orgID, err := uuid.Parse(orgIDStr)
+		if err != nil {
+			continue
+		}
+		orgRoles[orgID] = append(orgRoles[orgID], role)
+	}
+
+	_, err := tx.UpdateUserRoles(ctx, database.UpdateUserRolesParams{
+		GrantedRoles: siteRoles,
+		ID:           user.ID,
+	})
+	if err != nil {
+		return xerrors.Errorf("update site roles: %w", err)
+	}
+
+	memberships, err := tx.GetOrganizationMembershipsByUserID(ctx, user.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return xerrors.Errorf("get organization memberships: %w", err)
+	}
+	for _, membership := range memberships {
+		_, err = tx.UpdateMemberRoles(ctx, database.UpdateMemberRolesParams{
+			GrantedRoles: append([]string{}, orgRoles[membership.OrganizationID]...),
+			UserID:       user.ID,
+			OrgID:        membership.OrganizationID,
+		})
+		if err != nil {
+			return xerrors.Errorf("update organization roles: %w", err)
+		}
+		delete(orgRoles, membership.OrganizationID)
+	}
+
+	for orgID, granted := range orgRoles {
+		_, err = tx.GetOrganizationByID(ctx, orgID)
+		if errors.Is(err, sql.ErrNoRows) {
+			api.Logger.Warn(ctx, "skipping roles for unknown organization from identity provider",
+				slog.F("user_id", user.ID), slog.F("organization_id", orgID))
+			continue
+		}
+		if err != nil {
+			return xerrors.Errorf("get organization: %w", err)
+		}
+		_, err = tx.InsertOrganizationMember(ctx, database.InsertOrganizationMemberParams{
+			OrganizationID: orgID,
+			UserID:         user.ID,
+			CreatedAt:      database.Now(),
+			UpdatedAt:      database.Now(),
+			Roles:          granted,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert organization member: %w", err)
+		}
+	}
+	return nil
+}
+
 // githubLinkedID returns the unique ID for a GitHub user.
 func githubLinkedID(u *github.User) string {
 	return strconv.FormatInt(u.GetID(), 10)
@@ -462,6 +654,14 @@ func oidcLinkedID(tok *oidc.IDToken) string {
 	return strings.Join([]string{tok.Issuer, tok.Subject}, "||")
 }
 
+// oidcProviderDescription describes a provider in messages to users.
+func oidcProviderDescription(name string) string {
+	if name == "" {
+		return "the default OIDC provider"
+	}
+	return fmt.Sprintf("the %q OIDC provider", name)
+}
+
 // findLinkedUser tries to find a user by their unique OAuth-linked ID.
 // If it doesn't not find it, it returns the user by their email.
 func findLinkedUser(ctx context.Context, db database.Store, linkedID string, emails ...string) (database.User, database.UserLink, error) {
@@ -21,6 +21,7 @@ import (
 
 	"github.com/coder/coder/coderd"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/testutil"
 )
@@ -74,6 +75,32 @@ func TestUserAuthMethods(t *testing.T) {
 		require.True(t, methods.Password)
 		require.True(t, methods.Github)
 	})
+	t.Run("OIDCProviders", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{
+			OIDCProviders: []*coderd.OIDCConfig{{
+				Name:        "okta",
+				DisplayName: "Okta",
+			}, {
+				Name:        "azure",
+				DisplayName: "Azure AD",
+			}},
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		methods, err := client.AuthMethods(ctx)
+		require.NoError(t, err)
+		require.False(t, methods.OIDC)
+		require.Equal(t, []codersdk.OIDCAuthMethod{{
+			Name:        "okta",
+			DisplayName: "Okta",
+		}, {
+			Name:        "azure",
+			DisplayName: "Azure AD",
+		}}, methods.OIDCProviders)
+	})
 }
 
 // nolint:bodyclose
@@ -389,6 +416,119 @@ func TestUserOIDC(t *testing.T) {
 		require.Equal(t, http.StatusPreconditionRequired, resp.StatusCode)
 	})
 
+	t.Run("NamedProvider", func(t *testing.T) {
+		t.Parallel()
+		config := createOIDCConfig(t, jwt.MapClaims{
+			"email":          "kyle@kwc.io",
+			"email_verified": true,
+		})
+		config.Name = "okta"
+		config.AllowSignups = true
+		client := coderdtest.New(t, &coderdtest.Options{
+			OIDCProviders: []*coderd.OIDCConfig{config},
+		})
+
+		resp := oidcCallback(t, client)
+		require.Equal(t, http.StatusPreconditionRequired, resp.StatusCode)
+
+		resp = oidcCallbackPath(t, client, "/api/v2/users/oidc/okta/callback")
+		require.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		client.SessionToken = resp.Cookies()[0].Value
+		user, err := client.User(ctx, "me")
+		require.NoError(t, err)
+		require.Equal(t, "kyle@kwc.io", user.Email)
+	})
+
+	t.Run("NamedProviderMismatch", func(t *testing.T) {
+		t.Parallel()
+		config := createOIDCConfig(t, jwt.MapClaims{
+			"email":          "kyle@kwc.io",
+			"email_verified": true,
+		})
+		config.AllowSignups = true
+		okta := createOIDCConfigWithIssuer(t, "https://okta.example.com", jwt.MapClaims{
+			"email":          "kyle@kwc.io",
+			"email_verified": true,
+		})
+		okta.Name = "okta"
+		okta.AllowSignups = true
+		client := coderdtest.New(t, &coderdtest.Options{
+			OIDCConfig:    config,
+			OIDCProviders: []*coderd.OIDCConfig{okta},
+		})
+
+		resp := oidcCallback(t, client)
+		require.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		client.SessionToken = resp.Cookies()[0].Value
+		user, err := client.User(ctx, "me")
+		require.NoError(t, err)
+
+		// Signing in through another provider with the same email must not
+		// take over the link created by the default provider.
+		resp = oidcCallbackPath(t, client, "/api/v2/users/oidc/okta/callback")
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		// The original provider must still resolve to the same account.
+		resp = oidcCallback(t, client)
+		require.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+		client.SessionToken = resp.Cookies()[0].Value
+		again, err := client.User(ctx, "me")
+		require.NoError(t, err)
+		require.Equal(t, user.ID, again.ID)
+	})
+
+	t.Run("RoleSync", func(t *testing.T) {
+		t.Parallel()
+		config := createOIDCConfig(t, jwt.MapClaims{
+			"email":          "kyle@kwc.io",
+			"email_verified": true,
+			"groups":         []string{"coder-admins", "unmapped"},
+		})
+		config.AllowSignups = true
+		config.RoleSync = &coderd.OIDCRoleSync{
+			Field:   "groups",
+			Mapping: map[string][]string{},
+		}
+		client := coderdtest.New(t, &coderdtest.Options{
+			OIDCConfig: config,
+		})
+		first := coderdtest.CreateFirstUser(t, client)
+		config.RoleSync.Mapping["coder-admins"] = []string{
+			rbac.RoleTemplateAdmin(),
+			rbac.RoleOrgAdmin(first.OrganizationID),
+		}
+
+		resp := oidcCallback(t, client)
+		require.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		client.SessionToken = resp.Cookies()[0].Value
+		roles, err := client.GetUserRoles(ctx, "me")
+		require.NoError(t, err)
+		require.Equal(t, []string{rbac.RoleTemplateAdmin()}, roles.Roles)
+		require.Equal(t, []string{rbac.RoleOrgAdmin(first.OrganizationID)}, roles.OrganizationRoles[first.OrganizationID])
+
+		// Removing the mapping should revoke the roles on the next login.
+		delete(config.RoleSync.Mapping, "coder-admins")
+		resp = oidcCallback(t, client)
+		require.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+
+		roles, err = client.GetUserRoles(ctx, "me")
+		require.NoError(t, err)
+		require.Empty(t, roles.Roles)
+		require.Empty(t, roles.OrganizationRoles[first.OrganizationID])
+	})
+
 	t.Run("NoIDToken", func(t *testing.T) {
 		t.Parallel()
 		client := coderdtest.New(t, &coderdtest.Options{
@@ -426,19 +566,24 @@ func TestUserOIDC(t *testing.T) {
 // createOIDCConfig generates a new OIDCConfig that returns a static token
 // with the claims provided.
 func createOIDCConfig(t *testing.T, claims jwt.MapClaims) *coderd.OIDCConfig {
+	t.Helper()
+	return createOIDCConfigWithIssuer(t, "https://coder.com", claims)
+}
+
+func createOIDCConfigWithIssuer(t *testing.T, issuer string, claims jwt.MapClaims) *coderd.OIDCConfig {
 	t.Helper()
 	key, err := rsa.GenerateKey(rand.Reader, 2048)
 	require.NoError(t, err)
 
 	// https://datatracker.ietf.org/doc/html/rfc7519#section-4.1
 	claims["exp"] = time.Now().Add(time.Hour).UnixMilli()
-	claims["iss"] = "https://coder.com"
+	claims["iss"] = issuer
 	claims["sub"] = "hello"
 
 	signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
 	require.NoError(t, err)
 
-	verifier := oidc.NewVerifier("https://coder.com", &oidc.StaticKeySet{
+	verifier := oidc.NewVerifier(issuer, &oidc.StaticKeySet{
 		PublicKeys: []crypto.PublicKey{key.Public()},
 	}, &oidc.Config{
 		SkipClientIDCheck: true,
@@ -478,12 +623,17 @@ func oauth2Callback(t *testing.T, client *codersdk.Client) *http.Response {
 }
 
 func oidcCallback(t *testing.T, client *codersdk.Client) *http.Response {
+	t.Helper()
+	return oidcCallbackPath(t, client, "/api/v2/users/oidc/callback")
+}
+
+func oidcCallbackPath(t *testing.T, client *codersdk.Client, path string) *http.Response {
 	t.Helper()
 	client.HTTPClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
 		return http.ErrUseLastResponse
 	}
 	state := "somestate"
-	oauthURL, err := client.URL.Parse("/api/v2/users/oidc/callback?code=asd&state=" + state)
+	oauthURL, err := client.URL.Parse(path + "?code=asd&state=" + state)
 	require.NoError(t, err)
 	req, err := http.NewRequestWithContext(context.Background(), "GET", oauthURL.String(), nil)
 	require.NoError(t, err)
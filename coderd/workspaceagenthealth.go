@@ -0,0 +1,153 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tabbed/pqtype"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) postWorkspaceAgentHealth(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx            = r.Context()
+		workspaceAgent = httpmw.WorkspaceAgent(r)
+		req            codersdk.PostWorkspaceAgentHealthRequest
+	)
+	if !httpapi.Read(rw, r, &req) {
+		return
+	}
+
+	for index, check := range req.Checks {
+		if check.Name == "" {
+			httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Health checks must have a name.",
+			})
+			return
+		}
+		// Staleness is computed when health is read.
+		req.Checks[index].Stale = false
+	}
+	data, err := json.Marshal(req.Checks)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error encoding health checks.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	err = api.Database.UpdateWorkspaceAgentHealthChecksByID(ctx, database.UpdateWorkspaceAgentHealthChecksByIDParams{
+		ID: workspaceAgent.ID,
+		HealthChecks: pqtype.NullRawMessage{
+			RawMessage: data,
+			Valid:      true,
+		},
+	})
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error setting agent health checks.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// defaultHealthCheckInterval is the interval of health checks reported by
+// agents that didn't send their interval.
+const defaultHealthCheckInterval = time.Minute
+
+func convertWorkspaceAgentHealth(healthChecks pqtype.NullRawMessage) (codersdk.WorkspaceAgentHealth, error) {
+	health := codersdk.WorkspaceAgentHealth{
+		Healthy: true,
+		Checks:  []codersdk.WorkspaceAgentHealthCheck{},
+	}
+	if !healthChecks.Valid {
+		return health, nil
+	}
+	err := json.Unmarshal(healthChecks.RawMessage, &health.Checks)
+	if err != nil {
+		return codersdk.WorkspaceAgentHealth{}, xerrors.Errorf("unmarshal health checks: %w", err)
+	}
+	now := database.Now()
+	for index, check := range health.Checks {
+		interval := time.Duration(check.IntervalMillis) * time.Millisecond
+		if interval <= 0 {
+			interval = defaultHealthCheckInterval
+		}
+		// Agents report after every run, so a check that missed two runs
+		// belongs to an agent that stopped reporting.
+		check.Stale = now.Sub(check.CheckedAt) > 2*interval
+		health.Checks[index] = check
+		if !check.Healthy || check.Stale {
+			health.Healthy = false
+		}
+	}
+	return health, nil
+}
+
+// workspaceHealthByJobID returns the health of the agents created by each of
+// the provisioner jobs, keyed by job ID.
+func workspaceHealthByJobID(ctx context.Context, db database.Store, jobIDs []uuid.UUID) (map[uuid.UUID]codersdk.WorkspaceHealth, error) {
+	healthByJobID := make(map[uuid.UUID]codersdk.WorkspaceHealth, len(jobIDs))
+	for _, jobID := range jobIDs {
+		healthByJobID[jobID] = codersdk.WorkspaceHealth{
+			Healthy:       true,
+			FailingAgents: []uuid.UUID{},
+		}
+	}
+
+	resources, err := db.GetWorkspaceResourcesByJobIDs(ctx, jobIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, xerrors.Errorf("get workspace resources: %w", err)
+	}
+	if len(resources) == 0 {
+		return healthByJobID, nil
+	}
+	jobIDByResourceID := make(map[uuid.UUID]uuid.UUID, len(resources))
+	resourceIDs := make([]uuid.UUID, 0, len(resources))
+	for _, resource := range resources {
+		jobIDByResourceID[resource.ID] = resource.JobID
+		resourceIDs = append(resourceIDs, resource.ID)
+	}
+	agents, err := db.GetWorkspaceAgentsByResourceIDs(ctx, resourceIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, xerrors.Errorf("get workspace agents: %w", err)
+	}
+	for _, workspaceAgent := range agents {
+		agentHealth, err := convertWorkspaceAgentHealth(workspaceAgent.HealthChecks)
+		if err != nil {
+			return nil, xerrors.Errorf("convert health of agent %q: %w", workspaceAgent.Name, err)
+		}
+		if agentHealth.Healthy {
+			continue
+		}
+		jobID := jobIDByResourceID[workspaceAgent.ResourceID]
+		health := healthByJobID[jobID]
+		health.Healthy = false
+		health.FailingAgents = append(health.FailingAgents, workspaceAgent.ID)
+		healthByJobID[jobID] = health
+	}
+	return healthByJobID, nil
+}
+
+// workspaceHealth returns the health of the agents of a workspace build.
+func workspaceHealth(ctx context.Context, db database.Store, build database.WorkspaceBuild) (codersdk.WorkspaceHealth, error) {
+	healthByJobID, err := workspaceHealthByJobID(ctx, db, []uuid.UUID{build.JobID})
+	if err != nil {
+		return codersdk.WorkspaceHealth{}, err
+	}
+	return healthByJobID[build.JobID], nil
+}
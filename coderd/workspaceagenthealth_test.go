@@ -0,0 +1,151 @@
+package coderd_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWorkspaceAgentHealth(t *testing.T) {
+	t.Parallel()
+
+	client := coderdtest.New(t, &coderdtest.Options{
+		IncludeProvisionerD: true,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	authToken := uuid.NewString()
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:           echo.ParseComplete,
+		ProvisionDryRun: echo.ProvisionComplete,
+		Provision: []*proto.Provision_Response{{
+			Type: &proto.Provision_Response_Complete{
+				Complete: &proto.Provision_Complete{
+					Resources: []*proto.Resource{{
+						Name: "example",
+						Type: "aws_instance",
+						Agents: []*proto.Agent{{
+							Id: uuid.NewString(),
+							Auth: &proto.Agent_Token{
+								Token: authToken,
+							},
+						}},
+					}},
+				},
+			},
+		}},
+	})
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	resources, err := client.WorkspaceResourcesByBuild(ctx, workspace.LatestBuild.ID)
+	require.NoError(t, err)
+	agentID := resources[0].Agents[0].ID
+
+	// Agents without health checks are healthy.
+	workspaceAgent, err := client.WorkspaceAgent(ctx, agentID)
+	require.NoError(t, err)
+	require.True(t, workspaceAgent.Health.Healthy)
+	require.Empty(t, workspaceAgent.Health.Checks)
+	workspace, err = client.Workspace(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.True(t, workspace.Health.Healthy)
+
+	agentClient := codersdk.New(client.URL)
+	agentClient.SessionToken = authToken
+	checkedAt := time.Now().UTC().Truncate(time.Second)
+	err = agentClient.PostWorkspaceAgentHealth(ctx, []agent.HealthCheckResult{{
+		Name:      "docker",
+		Healthy:   true,
+		CheckedAt: checkedAt,
+	}, {
+		Name:      "disk",
+		Healthy:   false,
+		Output:    "disk full",
+		CheckedAt: checkedAt,
+	}})
+	require.NoError(t, err)
+
+	workspaceAgent, err = client.WorkspaceAgent(ctx, agentID)
+	require.NoError(t, err)
+	require.False(t, workspaceAgent.Health.Healthy)
+	require.Equal(t, []codersdk.WorkspaceAgentHealthCheck{{
+		Name:      "docker",
+		Healthy:   true,
+		CheckedAt: checkedAt,
+	}, {
+		Name:      "disk",
+		Healthy:   false,
+		Output:    "disk full",
+		CheckedAt: checkedAt,
+	}}, workspaceAgent.Health.Checks)
+
+	workspace, err = client.Workspace(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.False(t, workspace.Health.Healthy)
+	require.Equal(t, []uuid.UUID{agentID}, workspace.Health.FailingAgents)
+	workspaces, err := client.Workspaces(ctx, codersdk.WorkspaceFilter{})
+	require.NoError(t, err)
+	require.Len(t, workspaces, 1)
+	require.False(t, workspaces[0].Health.Healthy)
+
+	// Reports replace the previous results.
+	err = agentClient.PostWorkspaceAgentHealth(ctx, []agent.HealthCheckResult{{
+		Name:      "disk",
+		Healthy:   true,
+		CheckedAt: checkedAt,
+	}})
+	require.NoError(t, err)
+	workspace, err = client.Workspace(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.True(t, workspace.Health.Healthy)
+	require.Empty(t, workspace.Health.FailingAgents)
+
+	// Checks that weren't reported for two intervals are stale.
+	err = agentClient.PostWorkspaceAgentHealth(ctx, []agent.HealthCheckResult{{
+		Name:      "disk",
+		Healthy:   true,
+		CheckedAt: checkedAt.Add(-3 * time.Minute),
+		Interval:  time.Minute,
+	}, {
+		Name:      "docker",
+		Healthy:   true,
+		CheckedAt: checkedAt.Add(-3 * time.Minute),
+		Interval:  time.Hour,
+	}})
+	require.NoError(t, err)
+	workspaceAgent, err = client.WorkspaceAgent(ctx, agentID)
+	require.NoError(t, err)
+	require.False(t, workspaceAgent.Health.Healthy)
+	require.Len(t, workspaceAgent.Health.Checks, 2)
+	require.True(t, workspaceAgent.Health.Checks[0].Stale)
+	require.Equal(t, time.Minute.Milliseconds(), workspaceAgent.Health.Checks[0].IntervalMillis)
+	require.False(t, workspaceAgent.Health.Checks[1].Stale)
+	workspace, err = client.Workspace(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.False(t, workspace.Health.Healthy)
+	require.Equal(t, []uuid.UUID{agentID}, workspace.Health.FailingAgents)
+
+	// Health checks must be named.
+	err = agentClient.PostWorkspaceAgentHealth(ctx, []agent.HealthCheckResult{{
+		Healthy: true,
+	}})
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+}
@@ -21,6 +21,7 @@ import (
 
 	"cdr.dev/slog"
 	"github.com/coder/coder/agent"
+	"github.com/coder/coder/buildinfo"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbtypes"
 	"github.com/coder/coder/coderd/httpapi"
@@ -113,6 +114,9 @@ func (api *API) workspaceAgentDial(rw http.ResponseWriter, r *http.Request) {
 	// end span so we don't get long lived trace data
 	tracing.EndHTTPSpan(r, 200)
 
+	api.markWorkspaceUsed(ctx, workspace)
+	api.trackTemplateUsage(ctx, workspace, httpmw.APIKey(r).UserID, "")
+
 	err = peerbroker.ProxyListen(ctx, session, peerbroker.ProxyOptions{
 		ChannelID: workspaceAgent.ID.String(),
 		Logger:    api.Logger.Named("peerbroker-proxy-dial"),
@@ -144,11 +148,21 @@ func (api *API) workspaceAgentMetadata(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	_, template, err := api.workspaceAndTemplateByAgent(r.Context(), workspaceAgent)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace template.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
 	httpapi.Write(rw, http.StatusOK, agent.Metadata{
 		WireguardAddresses:   []netaddr.IPPrefix{ipp},
 		EnvironmentVariables: apiAgent.EnvironmentVariables,
 		StartupScript:        apiAgent.StartupScript,
 		Directory:            apiAgent.Directory,
+		RecordSessions:       template.RecordSessions,
 	})
 }
 
@@ -202,6 +216,28 @@ func (api *API) workspaceAgentListen(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Agents send their version when they connect. Older agents don't, so
+	// the version is cleared instead of reporting a stale one.
+	version := r.URL.Query().Get("version")
+	if version != "" && !buildinfo.VersionsMatch(version, buildinfo.Version()) {
+		api.Logger.Warn(r.Context(), "agent version doesn't match coderd",
+			slog.F("agent", workspaceAgent.ID),
+			slog.F("agent_version", version),
+			slog.F("coderd_version", buildinfo.Version()),
+		)
+	}
+	err = api.Database.UpdateWorkspaceAgentVersionByID(r.Context(), database.UpdateWorkspaceAgentVersionByIDParams{
+		ID:      workspaceAgent.ID,
+		Version: version,
+	})
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating workspace agent version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
 	conn, err := websocket.Accept(rw, r, &websocket.AcceptOptions{
 		CompressionMode: websocket.CompressionDisabled,
 	})
@@ -405,6 +441,9 @@ func (api *API) workspaceAgentPTY(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	api.markWorkspaceUsed(r.Context(), workspace)
+	api.trackTemplateUsage(r.Context(), workspace, httpmw.APIKey(r).UserID, "")
+
 	reconnect, err := uuid.Parse(r.URL.Query().Get("reconnect"))
 	if err != nil {
 		httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
@@ -423,6 +462,14 @@ func (api *API) workspaceAgentPTY(rw http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		width = 80
 	}
+	recordingToken, err := api.sessionRecordingToken(r.Context(), workspace, workspaceAgent.ID, httpmw.APIKey(r).UserID)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error issuing session recording token.",
+			Detail:  err.Error(),
+		})
+		return
+	}
 
 	conn, err := websocket.Accept(rw, r, &websocket.AcceptOptions{
 		CompressionMode: websocket.CompressionDisabled,
@@ -444,7 +491,7 @@ func (api *API) workspaceAgentPTY(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer release()
-	ptNetConn, err := agentConn.ReconnectingPTY(reconnect.String(), uint16(height), uint16(width), r.URL.Query().Get("command"))
+	ptNetConn, err := agentConn.ReconnectingPTY(reconnect.String(), uint16(height), uint16(width), recordingToken, r.URL.Query().Get("command"))
 	if err != nil {
 		_ = conn.Close(websocket.StatusInternalError, httpapi.WebsocketCloseSprintf("dial: %s", err))
 		return
@@ -453,6 +500,9 @@ func (api *API) workspaceAgentPTY(rw http.ResponseWriter, r *http.Request) {
 	// Pipe the ends together!
 	go func() {
 		_, _ = io.Copy(wsNetConn, ptNetConn)
+		// The agent closes the PTY when its process exits or its
+		// session is terminated.
+		_ = wsNetConn.Close()
 	}()
 	_, _ = io.Copy(ptNetConn, wsNetConn)
 }
@@ -533,6 +583,8 @@ func (api *API) postWorkspaceAgentWireguardPeer(rw http.ResponseWriter, r *http.
 		})
 		return
 	}
+	// Clients send a handshake every time they connect over wireguard.
+	api.markWorkspaceUsed(r.Context(), workspace)
 
 	rw.WriteHeader(http.StatusNoContent)
 }
@@ -588,6 +640,27 @@ func (api *API) workspaceAgentWireguardListener(rw http.ResponseWriter, r *http.
 	_, _, _ = conn.Reader(ctx)
 }
 
+// markWorkspaceUsed bumps the last used timestamp of a workspace so it is not
+// marked dormant by its template's inactivity policy. Failures are logged and
+// never interrupt the connection.
+func (api *API) markWorkspaceUsed(ctx context.Context, workspace database.Workspace) {
+	// Apps call this for every proxied request, so recent timestamps aren't
+	// written again.
+	if database.Now().Sub(workspace.LastUsedAt) < time.Minute {
+		return
+	}
+	err := api.Database.UpdateWorkspaceLastUsedAt(ctx, database.UpdateWorkspaceLastUsedAtParams{
+		ID:         workspace.ID,
+		LastUsedAt: database.Now(),
+	})
+	if err != nil {
+		api.Logger.Warn(ctx, "update workspace last used at",
+			slog.F("workspace_id", workspace.ID),
+			slog.Error(err),
+		)
+	}
+}
+
 // dialWorkspaceAgent connects to a workspace agent by ID. Only rely on
 // r.Context() for cancellation if it's use is safe or r.Hijack() has
 // not been performed.
@@ -691,6 +764,11 @@ func convertWorkspaceAgent(dbAgent database.WorkspaceAgent, apps []codersdk.Work
 		}
 	}
 
+	health, err := convertWorkspaceAgentHealth(dbAgent.HealthChecks)
+	if err != nil {
+		return codersdk.WorkspaceAgent{}, err
+	}
+
 	workspaceAgent := codersdk.WorkspaceAgent{
 		ID:                   dbAgent.ID,
 		CreatedAt:            dbAgent.CreatedAt,
@@ -707,6 +785,9 @@ func convertWorkspaceAgent(dbAgent database.WorkspaceAgent, apps []codersdk.Work
 		IPv6:                 inetToNetaddr(dbAgent.WireguardNodeIPv6),
 		WireguardPublicKey:   key.NodePublic(dbAgent.WireguardNodePublicKey),
 		DiscoPublicKey:       key.DiscoPublic(dbAgent.WireguardDiscoPublicKey),
+		Version:              dbAgent.Version,
+		Outdated:             dbAgent.Version != "" && !buildinfo.VersionsMatch(dbAgent.Version, buildinfo.Version()),
+		Health:               health,
 	}
 
 	if dbAgent.FirstConnectedAt.Valid {
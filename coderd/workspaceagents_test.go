@@ -16,6 +16,7 @@ import (
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/slogtest"
 	"github.com/coder/coder/agent"
+	"github.com/coder/coder/buildinfo"
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/peer"
@@ -127,6 +128,12 @@ func TestWorkspaceAgentListen(t *testing.T) {
 		}()
 		_, err = conn.Ping()
 		require.NoError(t, err)
+
+		// The agent reports the version it was built with.
+		workspaceAgent, err := client.WorkspaceAgent(ctx, resources[0].Agents[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, buildinfo.Version(), workspaceAgent.Version)
+		require.False(t, workspaceAgent.Outdated)
 	})
 
 	t.Run("FailNonLatestBuild", func(t *testing.T) {
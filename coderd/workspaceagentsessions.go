@@ -0,0 +1,131 @@
+package coderd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) workspaceAgentSessions(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx            = r.Context()
+		workspaceAgent = httpmw.WorkspaceAgentParam(r)
+		workspace      = httpmw.WorkspaceParam(r)
+	)
+	if !api.Authorize(r, rbac.ActionRead, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if !api.requireConnectedAgent(rw, workspaceAgent) {
+		return
+	}
+
+	agentConn, release, err := api.workspaceAgentCache.Acquire(r, workspaceAgent.ID)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error dialing workspace agent.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	defer release()
+	sessions, err := agentConn.Sessions(ctx)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching sessions from workspace agent.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	apiSessions := make([]codersdk.WorkspaceAgentSession, 0, len(sessions))
+	for _, session := range sessions {
+		apiSessions = append(apiSessions, codersdk.WorkspaceAgentSession{
+			ID:         session.ID,
+			Type:       codersdk.WorkspaceAgentSessionType(session.Type),
+			RemoteUser: session.RemoteUser,
+			StartedAt:  session.StartedAt,
+			RxBytes:    session.RxBytes,
+			TxBytes:    session.TxBytes,
+			Command:    session.Command,
+		})
+	}
+	httpapi.Write(rw, http.StatusOK, apiSessions)
+}
+
+func (api *API) deleteWorkspaceAgentSession(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx            = r.Context()
+		workspaceAgent = httpmw.WorkspaceAgentParam(r)
+		workspace      = httpmw.WorkspaceParam(r)
+	)
+	if !api.Authorize(r, rbac.ActionUpdate, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	sessionID, err := uuid.Parse(chi.URLParam(r, "workspaceagentsession"))
+	if err != nil {
+		httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid session ID.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !api.requireConnectedAgent(rw, workspaceAgent) {
+		return
+	}
+
+	agentConn, release, err := api.workspaceAgentCache.Acquire(r, workspaceAgent.ID)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error dialing workspace agent.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	defer release()
+	err = agentConn.TerminateSession(ctx, sessionID)
+	if errors.Is(err, agent.ErrSessionNotFound) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error terminating session.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// requireConnectedAgent writes an error and returns false unless the
+// workspace agent is connected.
+func (api *API) requireConnectedAgent(rw http.ResponseWriter, workspaceAgent database.WorkspaceAgent) bool {
+	apiAgent, err := convertWorkspaceAgent(workspaceAgent, nil, api.AgentInactiveDisconnectTimeout)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error reading workspace agent.",
+			Detail:  err.Error(),
+		})
+		return false
+	}
+	if apiAgent.Status != codersdk.WorkspaceAgentConnected {
+		httpapi.Write(rw, http.StatusPreconditionRequired, codersdk.Response{
+			Message: fmt.Sprintf("Agent state is %q, it must be in the %q state.", apiAgent.Status, codersdk.WorkspaceAgentConnected),
+		})
+		return false
+	}
+	return true
+}
@@ -170,6 +170,8 @@ func (api *API) workspaceAppsProxyPath(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 	defer release()
+	api.markWorkspaceUsed(r.Context(), workspace)
+	api.trackTemplateUsage(r.Context(), workspace, httpmw.APIKey(r).UserID, app.Name)
 
 	// This strips the session token from a workspace app request.
 	cookieHeaders := r.Header.Values("Cookie")[:]
@@ -15,7 +15,9 @@ import (
 
 	"cdr.dev/slog/sloggers/slogtest"
 	"github.com/coder/coder/agent"
+	"github.com/coder/coder/coderd"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/provisioner/echo"
 	"github.com/coder/coder/provisionersdk/proto"
@@ -42,8 +44,13 @@ func TestWorkspaceAppsProxyPath(t *testing.T) {
 	go server.Serve(ln)
 	tcpAddr, _ := ln.Addr().(*net.TCPAddr)
 
+	var db database.Store
 	client := coderdtest.New(t, &coderdtest.Options{
 		IncludeProvisionerD: true,
+		APIBuilder: func(options *coderd.Options) *coderd.API {
+			db = options.Database
+			return coderd.New(options)
+		},
 	})
 	user := coderdtest.CreateFirstUser(t, client)
 	authToken := uuid.NewString()
@@ -153,6 +160,29 @@ func TestWorkspaceAppsProxyPath(t *testing.T) {
 		require.Equal(t, http.StatusOK, resp.StatusCode)
 	})
 
+	t.Run("MarksWorkspaceUsed", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		lastUsedAt := database.Now().Add(-time.Hour)
+		err := db.UpdateWorkspaceLastUsedAt(ctx, database.UpdateWorkspaceLastUsedAtParams{
+			ID:         workspace.ID,
+			LastUsedAt: lastUsedAt,
+		})
+		require.NoError(t, err)
+
+		resp, err := client.Request(ctx, http.MethodGet, "/@me/"+workspace.Name+"/apps/example/?query=true", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		updated, err := client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.True(t, updated.LastUsedAt.After(lastUsedAt))
+	})
+
 	t.Run("ProxyError", func(t *testing.T) {
 		t.Parallel()
 
@@ -307,6 +307,15 @@ func (api *API) postWorkspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Dormant workspaces must be acknowledged by their owner before they can
+	// be started again.
+	if workspace.DormantAt.Valid && createBuild.Transition == codersdk.WorkspaceTransitionStart {
+		httpapi.Write(rw, http.StatusPreconditionFailed, codersdk.Response{
+			Message: "Workspace is dormant and must be woken up before it can be started.",
+		})
+		return
+	}
+
 	if createBuild.TemplateVersionID == uuid.Nil {
 		latestBuild, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(r.Context(), workspace.ID)
 		if err != nil {
@@ -393,6 +402,22 @@ func (api *API) postWorkspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Updating a workspace to a new template version applies the values of
+	// its preset in that version again, unless they're set explicitly.
+	if workspace.Preset != "" && priorHistory.TemplateVersionID != templateVersion.ID {
+		preset, ok, err := getTemplateVersionPreset(r.Context(), api.Database, templateVersion.ID, workspace.Preset)
+		if err == nil && ok {
+			createBuild.ParameterValues, err = withPresetParameterValues(r.Context(), api.Database, templateVersion, preset, createBuild.ParameterValues)
+		}
+		if err != nil {
+			httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error applying preset.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+
 	var workspaceBuild database.WorkspaceBuild
 	var provisionerJob database.ProvisionerJob
 	// This must happen in a transaction to ensure history can be inserted, and
@@ -633,6 +658,32 @@ func (api *API) workspaceBuildState(rw http.ResponseWriter, r *http.Request) {
 	_, _ = rw.Write(workspaceBuild.ProvisionerState)
 }
 
+func (api *API) workspaceBuildTimings(rw http.ResponseWriter, r *http.Request) {
+	workspaceBuild := httpmw.WorkspaceBuildParam(r)
+	workspace, err := api.Database.GetWorkspaceByID(r.Context(), workspaceBuild.WorkspaceID)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "No workspace exists for this job.",
+		})
+		return
+	}
+
+	if !api.Authorize(r, rbac.ActionRead, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	timings, err := api.Database.GetProvisionerJobTimingsByJobID(r.Context(), workspaceBuild.JobID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job timings.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(rw, http.StatusOK, convertProvisionerJobTimings(timings))
+}
+
 func convertWorkspaceBuild(
 	workspaceOwner *database.User,
 	buildInitiator *database.User,
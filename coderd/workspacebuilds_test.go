@@ -413,3 +413,45 @@ func TestWorkspaceBuildState(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, wantState, gotState)
 }
+
+func TestWorkspaceBuildTimings(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+	user := coderdtest.CreateFirstUser(t, client)
+	startedAt := time.Date(2022, 7, 14, 10, 0, 0, 0, time.UTC)
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:           echo.ParseComplete,
+		ProvisionDryRun: echo.ProvisionComplete,
+		Provision: []*proto.Provision_Response{{
+			Type: &proto.Provision_Response_Complete{
+				Complete: &proto.Provision_Complete{
+					Timings: []*proto.Timing{{
+						Stage:     "apply",
+						Resource:  "docker_container.workspace",
+						Action:    "create",
+						StartedAt: startedAt.UnixMilli(),
+						EndedAt:   startedAt.Add(5 * time.Second).UnixMilli(),
+					}},
+				},
+			},
+		}},
+	})
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	timings, err := client.WorkspaceBuildTimings(ctx, workspace.LatestBuild.ID)
+	require.NoError(t, err)
+	require.Len(t, timings, 2)
+	// The apply started before the job did, so it's ordered first.
+	require.Equal(t, "apply", timings[0].Stage)
+	require.Equal(t, "docker_container.workspace", timings[0].Resource)
+	require.Equal(t, "create", timings[0].Action)
+	require.Equal(t, 5*time.Second, timings[0].EndedAt.Sub(timings[0].StartedAt))
+	require.Equal(t, "setup", timings[1].Stage)
+	require.Empty(t, timings[1].Resource)
+}
@@ -87,11 +87,16 @@ func (api *API) workspace(rw http.ResponseWriter, r *http.Request) {
 		job      database.ProvisionerJob
 		template database.Template
 		users    []database.User
+		health   codersdk.WorkspaceHealth
 	)
 	group.Go(func() (err error) {
 		job, err = api.Database.GetProvisionerJobByID(r.Context(), build.JobID)
 		return err
 	})
+	group.Go(func() (err error) {
+		health, err = workspaceHealth(r.Context(), api.Database, build)
+		return err
+	})
 	group.Go(func() (err error) {
 		template, err = api.Database.GetTemplateByID(r.Context(), workspace.TemplateID)
 		return err
@@ -110,7 +115,7 @@ func (api *API) workspace(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	httpapi.Write(rw, http.StatusOK, convertWorkspace(workspace, build, job, template,
-		findUser(workspace.OwnerID, users), findUser(build.InitiatorID, users)))
+		findUser(workspace.OwnerID, users), findUser(build.InitiatorID, users), health))
 }
 
 // workspaces returns all workspaces a user can read.
@@ -243,7 +248,16 @@ func (api *API) workspaceByOwnerAndName(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	httpapi.Write(rw, http.StatusOK, convertWorkspace(workspace, build, job, template, &owner, &initiator))
+	health, err := workspaceHealth(r.Context(), api.Database, build)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace health.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(rw, http.StatusOK, convertWorkspace(workspace, build, job, template, &owner, &initiator, health))
 }
 
 // Create a new workspace for the currently authenticated user.
@@ -368,6 +382,67 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if createWorkspace.Preset != "" {
+		preset, ok, err := getTemplateVersionPreset(r.Context(), api.Database, templateVersion.ID, createWorkspace.Preset)
+		if err != nil {
+			httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching preset.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		if !ok {
+			httpapi.Write(rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Preset %q doesn't exist.", createWorkspace.Preset),
+				Validations: []codersdk.ValidationError{{
+					Field:  "preset",
+					Detail: "preset not found",
+				}},
+			})
+			return
+		}
+		createWorkspace.ParameterValues, err = withPresetParameterValues(r.Context(), api.Database, templateVersion, preset, createWorkspace.ParameterValues)
+		if err != nil {
+			httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error applying preset.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+
+	// Requests matching the parameter preset of the template are handed a
+	// prebuilt workspace, so users don't have to wait for a build.
+	claimed, claimedBuild, claimedJob, ok, err := api.claimPrebuiltWorkspace(r.Context(), template, apiKey.UserID, createWorkspace, dbAutostartSchedule, dbTTL)
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error claiming prebuilt workspace.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if ok {
+		users, err := api.Database.GetUsersByIDs(r.Context(), []uuid.UUID{apiKey.UserID, claimedBuild.InitiatorID})
+		if err != nil {
+			httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching user.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		health, err := workspaceHealth(r.Context(), api.Database, claimedBuild)
+		if err != nil {
+			httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching workspace health.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		httpapi.Write(rw, http.StatusCreated, convertWorkspace(claimed, claimedBuild, claimedJob, template,
+			findUser(apiKey.UserID, users), findUser(claimedBuild.InitiatorID, users), health))
+		return
+	}
+
 	var provisionerJob database.ProvisionerJob
 	var workspaceBuild database.WorkspaceBuild
 	err = api.Database.InTx(func(db database.Store) error {
@@ -384,6 +459,7 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 			Name:              createWorkspace.Name,
 			AutostartSchedule: dbAutostartSchedule,
 			Ttl:               dbTTL,
+			Preset:            createWorkspace.Preset,
 		})
 		if err != nil {
 			return xerrors.Errorf("insert workspace: %w", err)
@@ -466,8 +542,12 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		WorkspaceBuilds: []telemetry.WorkspaceBuild{telemetry.ConvertWorkspaceBuild(workspaceBuild)},
 	})
 
+	// The workspace has no agents until the build completes.
 	httpapi.Write(rw, http.StatusCreated, convertWorkspace(workspace, workspaceBuild, templateVersionJob, template,
-		findUser(apiKey.UserID, users), findUser(workspaceBuild.InitiatorID, users)))
+		findUser(apiKey.UserID, users), findUser(workspaceBuild.InitiatorID, users), codersdk.WorkspaceHealth{
+			Healthy:       true,
+			FailingAgents: []uuid.UUID{},
+		}))
 }
 
 func (api *API) patchWorkspace(rw http.ResponseWriter, r *http.Request) {
@@ -628,6 +708,61 @@ func (api *API) putWorkspaceTTL(rw http.ResponseWriter, r *http.Request) {
 	httpapi.Write(rw, http.StatusOK, nil)
 }
 
+func (api *API) putWorkspaceDormant(rw http.ResponseWriter, r *http.Request) {
+	workspace := httpmw.WorkspaceParam(r)
+	if !api.Authorize(r, rbac.ActionUpdate, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateWorkspaceDormancyRequest
+	if !httpapi.Read(rw, r, &req) {
+		return
+	}
+
+	err := api.Database.InTx(func(s database.Store) error {
+		now := database.Now()
+		dormantAt := sql.NullTime{}
+		if req.Dormant {
+			dormantAt = sql.NullTime{Time: now, Valid: true}
+			// Keep the original timestamp if the workspace is already dormant.
+			if workspace.DormantAt.Valid {
+				dormantAt = workspace.DormantAt
+			}
+		}
+		err := s.UpdateWorkspaceDormantAt(r.Context(), database.UpdateWorkspaceDormantAtParams{
+			ID:        workspace.ID,
+			DormantAt: dormantAt,
+		})
+		if err != nil {
+			return xerrors.Errorf("update workspace dormant at: %w", err)
+		}
+		if req.Dormant {
+			return nil
+		}
+
+		// Waking a workspace counts as usage, otherwise it would be marked
+		// dormant again on the next executor tick.
+		err = s.UpdateWorkspaceLastUsedAt(r.Context(), database.UpdateWorkspaceLastUsedAtParams{
+			ID:         workspace.ID,
+			LastUsedAt: now,
+		})
+		if err != nil {
+			return xerrors.Errorf("update workspace last used at: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		httpapi.Write(rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating workspace dormancy.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(rw, http.StatusOK, nil)
+}
+
 func (api *API) putExtendWorkspace(rw http.ResponseWriter, r *http.Request) {
 	workspace := httpmw.WorkspaceParam(r)
 
@@ -781,11 +916,16 @@ func (api *API) watchWorkspace(rw http.ResponseWriter, r *http.Request) {
 				job      database.ProvisionerJob
 				template database.Template
 				users    []database.User
+				health   codersdk.WorkspaceHealth
 			)
 			group.Go(func() (err error) {
 				job, err = api.Database.GetProvisionerJobByID(r.Context(), build.JobID)
 				return err
 			})
+			group.Go(func() (err error) {
+				health, err = workspaceHealth(r.Context(), api.Database, build)
+				return err
+			})
 			group.Go(func() (err error) {
 				template, err = api.Database.GetTemplateByID(r.Context(), workspace.TemplateID)
 				return err
@@ -804,7 +944,7 @@ func (api *API) watchWorkspace(rw http.ResponseWriter, r *http.Request) {
 			}
 
 			_ = wsjson.Write(ctx, c, convertWorkspace(workspace, build, job, template,
-				findUser(workspace.OwnerID, users), findUser(build.InitiatorID, users)))
+				findUser(workspace.OwnerID, users), findUser(build.InitiatorID, users), health))
 		case <-ctx.Done():
 			return
 		}
@@ -856,6 +996,11 @@ func convertWorkspaces(ctx context.Context, db database.Store, workspaces []data
 		return nil, xerrors.Errorf("get provisioner jobs: %w", err)
 	}
 
+	healthByJobID, err := workspaceHealthByJobID(ctx, db, jobIDs)
+	if err != nil {
+		return nil, xerrors.Errorf("get workspace health: %w", err)
+	}
+
 	buildByWorkspaceID := map[uuid.UUID]database.WorkspaceBuild{}
 	for _, workspaceBuild := range workspaceBuilds {
 		buildByWorkspaceID[workspaceBuild.WorkspaceID] = database.WorkspaceBuild{
@@ -908,7 +1053,7 @@ func convertWorkspaces(ctx context.Context, db database.Store, workspaces []data
 		if !exists {
 			return nil, xerrors.Errorf("build initiator not found for workspace: %q", workspace.Name)
 		}
-		apiWorkspaces = append(apiWorkspaces, convertWorkspace(workspace, build, job, template, &owner, &initiator))
+		apiWorkspaces = append(apiWorkspaces, convertWorkspace(workspace, build, job, template, &owner, &initiator, healthByJobID[build.JobID]))
 	}
 	return apiWorkspaces, nil
 }
@@ -920,12 +1065,33 @@ func convertWorkspace(
 	template database.Template,
 	owner *database.User,
 	initiator *database.User,
+	health codersdk.WorkspaceHealth,
 ) codersdk.Workspace {
 	var autostartSchedule *string
 	if workspace.AutostartSchedule.Valid {
 		autostartSchedule = &workspace.AutostartSchedule.String
 	}
 
+	var dormantAt, deletingAt, becomesDormantAt *time.Time
+	if workspace.DormantAt.Valid {
+		dormantAt = &workspace.DormantAt.Time
+		if template.DormantAutodeleteTtl > 0 {
+			t := workspace.DormantAt.Time.Add(time.Duration(template.DormantAutodeleteTtl))
+			deletingAt = &t
+		}
+	} else if template.InactivityTtl > 0 && workspaceBuild.Transition == database.WorkspaceTransitionStop &&
+		job.CompletedAt.Valid && job.Error.String == "" {
+		// This matches when the autobuild executor marks the workspace
+		// dormant. Workspaces that were never used count from their last
+		// build.
+		lastUsedAt := workspace.LastUsedAt
+		if workspaceBuild.CreatedAt.After(lastUsedAt) {
+			lastUsedAt = workspaceBuild.CreatedAt
+		}
+		t := lastUsedAt.Add(time.Duration(template.InactivityTtl))
+		becomesDormantAt = &t
+	}
+
 	ttlMillis := convertWorkspaceTTLMillis(workspace.Ttl)
 	return codersdk.Workspace{
 		ID:                workspace.ID,
@@ -941,6 +1107,13 @@ func convertWorkspace(
 		Name:              workspace.Name,
 		AutostartSchedule: autostartSchedule,
 		TTLMillis:         ttlMillis,
+		LastUsedAt:        workspace.LastUsedAt,
+		DormantAt:         dormantAt,
+		DeletingAt:        deletingAt,
+		BecomesDormantAt:  becomesDormantAt,
+		Prebuild:          workspace.Prebuild,
+		Preset:            workspace.Preset,
+		Health:            health,
 	}
 }
 
@@ -1023,8 +1196,9 @@ func validWorkspaceSchedule(s *string, min time.Duration) (sql.NullString, error
 func workspaceSearchQuery(query string) (database.GetWorkspacesParams, []codersdk.ValidationError) {
 	searchParams := make(url.Values)
 	if query == "" {
-		// No filter
-		return database.GetWorkspacesParams{}, nil
+		// No filter, but dormant and prebuilt workspaces are hidden unless
+		// asked for.
+		return database.GetWorkspacesParams{ExcludeDormant: true, ExcludePrebuilds: true}, nil
 	}
 	query = strings.ToLower(query)
 	// Because we do this in 2 passes, we want to maintain quotes on the first
@@ -1065,6 +1239,11 @@ func workspaceSearchQuery(query string) (database.GetWorkspacesParams, []codersd
 		OwnerUsername: parser.String(searchParams, "", "owner"),
 		TemplateName:  parser.String(searchParams, "", "template"),
 		Name:          parser.String(searchParams, "", "name"),
+		// Dormant workspaces are excluded unless "dormant:true" is specified.
+		ExcludeDormant: !httpapi.ParseCustom(parser, searchParams, false, "dormant", strconv.ParseBool),
+		// Unclaimed prebuilt workspaces are excluded unless "prebuild:true"
+		// is specified.
+		ExcludePrebuilds: !httpapi.ParseCustom(parser, searchParams, false, "prebuild", strconv.ParseBool),
 	}
 
 	return filter, parser.Errors
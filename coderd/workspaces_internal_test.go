@@ -21,100 +21,138 @@ func TestSearchWorkspace(t *testing.T) {
 		{
 			Name:     "Empty",
 			Query:    "",
-			Expected: database.GetWorkspacesParams{},
+			Expected: database.GetWorkspacesParams{ExcludeDormant: true, ExcludePrebuilds: true},
 		},
 		{
 			Name:  "Owner/Name",
 			Query: "Foo/Bar",
 			Expected: database.GetWorkspacesParams{
-				OwnerUsername: "foo",
-				Name:          "bar",
+				ExcludeDormant:   true,
+				ExcludePrebuilds: true,
+				OwnerUsername:    "foo",
+				Name:             "bar",
 			},
 		},
 		{
 			Name:  "Owner/NameWithSpaces",
 			Query: "     Foo/Bar     ",
 			Expected: database.GetWorkspacesParams{
-				OwnerUsername: "foo",
-				Name:          "bar",
+				ExcludeDormant:   true,
+				ExcludePrebuilds: true,
+				OwnerUsername:    "foo",
+				Name:             "bar",
 			},
 		},
 		{
 			Name:  "Name",
 			Query: "workspace-name",
 			Expected: database.GetWorkspacesParams{
-				Name: "workspace-name",
+				ExcludeDormant:   true,
+				ExcludePrebuilds: true,
+				Name:             "workspace-name",
 			},
 		},
 		{
 			Name:  "Name+Param",
 			Query: "workspace-name TEMPLATE:docker",
 			Expected: database.GetWorkspacesParams{
-				Name:         "workspace-name",
-				TemplateName: "docker",
+				ExcludeDormant:   true,
+				ExcludePrebuilds: true,
+				Name:             "workspace-name",
+				TemplateName:     "docker",
 			},
 		},
 		{
 			Name:  "OnlyParams",
 			Query: "name:workspace-name template:docker OWNER:Alice",
 			Expected: database.GetWorkspacesParams{
-				Name:          "workspace-name",
-				TemplateName:  "docker",
-				OwnerUsername: "alice",
+				ExcludeDormant:   true,
+				ExcludePrebuilds: true,
+				Name:             "workspace-name",
+				TemplateName:     "docker",
+				OwnerUsername:    "alice",
 			},
 		},
 		{
 			Name:  "QuotedParam",
 			Query: `name:workspace-name template:"docker template" owner:alice`,
 			Expected: database.GetWorkspacesParams{
-				Name:          "workspace-name",
-				TemplateName:  "docker template",
-				OwnerUsername: "alice",
+				ExcludeDormant:   true,
+				ExcludePrebuilds: true,
+				Name:             "workspace-name",
+				TemplateName:     "docker template",
+				OwnerUsername:    "alice",
 			},
 		},
 		{
 			Name:  "QuotedKey",
 			Query: `"name":baz "template":foo "owner":bar`,
 			Expected: database.GetWorkspacesParams{
-				Name:          "baz",
-				TemplateName:  "foo",
-				OwnerUsername: "bar",
+				ExcludeDormant:   true,
+				ExcludePrebuilds: true,
+				Name:             "baz",
+				TemplateName:     "foo",
+				OwnerUsername:    "bar",
 			},
 		},
 		{
 			// This will not return an error
 			Name:     "ExtraKeys",
 			Query:    `foo:bar`,
-			Expected: database.GetWorkspacesParams{},
+			Expected: database.GetWorkspacesParams{ExcludeDormant: true, ExcludePrebuilds: true},
 		},
 		{
 			// Quotes keep elements together
 			Name:  "QuotedSpecial",
 			Query: `name:"workspace:name"`,
 			Expected: database.GetWorkspacesParams{
-				Name: "workspace:name",
+				ExcludeDormant:   true,
+				ExcludePrebuilds: true,
+				Name:             "workspace:name",
 			},
 		},
 		{
 			Name:  "QuotedMadness",
 			Query: `"name":"foo:bar:baz/baz/zoo:zonk"`,
 			Expected: database.GetWorkspacesParams{
-				Name: "foo:bar:baz/baz/zoo:zonk",
+				ExcludeDormant:   true,
+				ExcludePrebuilds: true,
+				Name:             "foo:bar:baz/baz/zoo:zonk",
 			},
 		},
 		{
 			Name:  "QuotedName",
 			Query: `"foo/bar"`,
 			Expected: database.GetWorkspacesParams{
-				Name: "foo/bar",
+				ExcludeDormant:   true,
+				ExcludePrebuilds: true,
+				Name:             "foo/bar",
 			},
 		},
 		{
 			Name:  "QuotedOwner/Name",
 			Query: `"foo"/"bar"`,
 			Expected: database.GetWorkspacesParams{
-				Name:          "bar",
-				OwnerUsername: "foo",
+				ExcludeDormant:   true,
+				ExcludePrebuilds: true,
+				Name:             "bar",
+				OwnerUsername:    "foo",
+			},
+		},
+		{
+			Name:  "Dormant",
+			Query: "dormant:true",
+			Expected: database.GetWorkspacesParams{
+				ExcludeDormant:   false,
+				ExcludePrebuilds: true,
+			},
+		},
+		{
+			Name:  "Prebuild",
+			Query: "prebuild:true",
+			Expected: database.GetWorkspacesParams{
+				ExcludeDormant:   true,
+				ExcludePrebuilds: false,
 			},
 		},
 
@@ -129,6 +167,11 @@ func TestSearchWorkspace(t *testing.T) {
 			Query:                 `owner:name:extra`,
 			ExpectedErrorContains: "can only contain 1 ':'",
 		},
+		{
+			Name:                  "InvalidDormant",
+			Query:                 `dormant:maybe`,
+			ExpectedErrorContains: "has invalid value",
+		},
 	}
 
 	for _, c := range testCases {
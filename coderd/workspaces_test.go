@@ -13,6 +13,7 @@ import (
 
 	"github.com/coder/coder/coderd/autobuild/schedule"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/util/ptr"
 	"github.com/coder/coder/codersdk"
@@ -1254,6 +1255,73 @@ func TestWorkspaceExtend(t *testing.T) {
 	require.WithinDuration(t, oldDeadline.Add(-time.Hour), updated.LatestBuild.Deadline.Time, time.Minute)
 }
 
+func TestWorkspaceDormancy(t *testing.T) {
+	t.Parallel()
+	var (
+		client    = coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
+		user      = coderdtest.CreateFirstUser(t, client)
+		version   = coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_         = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template  = coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace = coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		_         = coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	_, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+		InactivityTTLMillis:        ptr.Ref((48 * time.Hour).Milliseconds()),
+		DormantAutoDeleteTTLMillis: ptr.Ref((24 * time.Hour).Milliseconds()),
+	})
+	require.NoError(t, err)
+
+	workspace = coderdtest.MustTransitionWorkspace(t, client, workspace.ID, database.WorkspaceTransitionStart, database.WorkspaceTransitionStop)
+	// Stopped workspaces report when they become dormant.
+	require.NotNil(t, workspace.BecomesDormantAt)
+	require.WithinDuration(t, time.Now().Add(48*time.Hour), *workspace.BecomesDormantAt, time.Minute)
+	err = client.UpdateWorkspaceDormancy(ctx, workspace.ID, codersdk.UpdateWorkspaceDormancyRequest{Dormant: true})
+	require.NoError(t, err)
+
+	workspace, err = client.Workspace(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.NotNil(t, workspace.DormantAt)
+	require.NotNil(t, workspace.DeletingAt)
+	require.WithinDuration(t, workspace.DormantAt.Add(24*time.Hour), *workspace.DeletingAt, time.Second)
+	require.Nil(t, workspace.BecomesDormantAt)
+
+	// Dormant workspaces are hidden unless explicitly asked for.
+	workspaces, err := client.Workspaces(ctx, codersdk.WorkspaceFilter{})
+	require.NoError(t, err)
+	require.Len(t, workspaces, 0)
+	workspaces, err = client.Workspaces(ctx, codersdk.WorkspaceFilter{Dormant: true})
+	require.NoError(t, err)
+	require.Len(t, workspaces, 1)
+
+	// Dormant workspaces cannot be started.
+	_, err = client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+		Transition: codersdk.WorkspaceTransitionStart,
+	})
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusPreconditionFailed, apiErr.StatusCode())
+
+	// Waking the workspace resets the inactivity timer.
+	err = client.UpdateWorkspaceDormancy(ctx, workspace.ID, codersdk.UpdateWorkspaceDormancyRequest{Dormant: false})
+	require.NoError(t, err)
+	updated, err := client.Workspace(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.Nil(t, updated.DormantAt)
+	require.Nil(t, updated.DeletingAt)
+	require.True(t, updated.LastUsedAt.After(workspace.LastUsedAt))
+
+	build, err := client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+		Transition: codersdk.WorkspaceTransitionStart,
+	})
+	require.NoError(t, err)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, build.ID)
+}
+
 func TestWorkspaceWatcher(t *testing.T) {
 	t.Parallel()
 	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerD: true})
@@ -1,6 +1,10 @@
 package codersdk
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,3 +17,57 @@ type OrganizationMember struct {
 	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
 	Roles          []Role    `db:"roles" json:"roles"`
 }
+
+// OrganizationMemberWithUser is an organization member along with the
+// identifying details of the user.
+type OrganizationMemberWithUser struct {
+	UserID         uuid.UUID `json:"user_id" table:"user id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Username       string    `json:"username" table:"username"`
+	Email          string    `json:"email" table:"email"`
+	CreatedAt      time.Time `json:"created_at" table:"created at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	Roles          []Role    `json:"roles"`
+}
+
+// OrganizationMembers lists the members of an organization.
+func (c *Client) OrganizationMembers(ctx context.Context, organizationID uuid.UUID) ([]OrganizationMemberWithUser, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/members", organizationID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var members []OrganizationMemberWithUser
+	return members, json.NewDecoder(res.Body).Decode(&members)
+}
+
+// AddOrganizationMember adds a user to an organization. The user can be
+// identified by ID or username.
+func (c *Client) AddOrganizationMember(ctx context.Context, organizationID uuid.UUID, user string) (OrganizationMember, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/organizations/%s/members/%s", organizationID, user), nil)
+	if err != nil {
+		return OrganizationMember{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return OrganizationMember{}, readBodyAsError(res)
+	}
+	var member OrganizationMember
+	return member, json.NewDecoder(res.Body).Decode(&member)
+}
+
+// RemoveOrganizationMember removes a user from an organization.
+func (c *Client) RemoveOrganizationMember(ctx context.Context, organizationID uuid.UUID, user string) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/organizations/%s/members/%s", organizationID, user), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return readBodyAsError(res)
+	}
+	return nil
+}
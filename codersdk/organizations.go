@@ -26,16 +26,24 @@ const (
 
 // Organization is the JSON representation of a Coder organization.
 type Organization struct {
-	ID        uuid.UUID `json:"id" validate:"required"`
-	Name      string    `json:"name" validate:"required"`
-	CreatedAt time.Time `json:"created_at" validate:"required"`
-	UpdatedAt time.Time `json:"updated_at" validate:"required"`
+	ID        uuid.UUID `json:"id" validate:"required" table:"id"`
+	Name      string    `json:"name" validate:"required" table:"name"`
+	CreatedAt time.Time `json:"created_at" validate:"required" table:"created at"`
+	UpdatedAt time.Time `json:"updated_at" validate:"required" table:"updated at"`
+}
+
+// UpdateOrganizationRequest updates the metadata of an organization.
+type UpdateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,username"`
 }
 
 // CreateTemplateVersionRequest enables callers to create a new Template Version.
 type CreateTemplateVersionRequest struct {
 	// TemplateID optionally associates a version with a template.
 	TemplateID uuid.UUID `json:"template_id,omitempty"`
+	// Detached creates the version without adding it to the template. The
+	// TemplateID is then only used to inherit parameters and variables.
+	Detached bool `json:"detached,omitempty"`
 
 	StorageMethod ProvisionerStorageMethod `json:"storage_method" validate:"oneof=file,required"`
 	StorageSource string                   `json:"storage_source" validate:"required"`
@@ -43,6 +51,21 @@ type CreateTemplateVersionRequest struct {
 	// ParameterValues allows for additional parameters to be provided
 	// during the dry-run provision stage.
 	ParameterValues []CreateParameterRequest `json:"parameter_values,omitempty"`
+	// Message describes the changes in this version.
+	Message string `json:"message,omitempty"`
+	// Git optionally records the git commit the version was pushed from.
+	Git *TemplateVersionGitMetadata `json:"git,omitempty"`
+	// Variables set the values of template variables. Variables of the
+	// active version of the template that aren't set are kept.
+	Variables []CreateTemplateVersionVariable `json:"variables,omitempty"`
+}
+
+// CreateTemplateVersionVariable sets the value of a template variable.
+type CreateTemplateVersionVariable struct {
+	Name  string `json:"name" validate:"required"`
+	Value string `json:"value"`
+	// Sensitive values are never returned by the API.
+	Sensitive bool `json:"sensitive"`
 }
 
 // CreateTemplateRequest provides options when creating a template.
@@ -84,6 +107,25 @@ type CreateWorkspaceRequest struct {
 	// ParameterValues allows for additional parameters to be provided
 	// during the initial provision.
 	ParameterValues []CreateParameterRequest `json:"parameter_values,omitempty"`
+	// Preset is the name of a parameter preset of the template's active
+	// version. ParameterValues take precedence over the values of the preset.
+	Preset string `json:"preset,omitempty"`
+}
+
+// Organizations returns all organizations the caller can read.
+func (c *Client) Organizations(ctx context.Context) ([]Organization, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/organizations", nil)
+	if err != nil {
+		return nil, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+
+	var organizations []Organization
+	return organizations, json.NewDecoder(res.Body).Decode(&organizations)
 }
 
 func (c *Client) Organization(ctx context.Context, id uuid.UUID) (Organization, error) {
@@ -212,3 +254,34 @@ func (c *Client) CreateWorkspace(ctx context.Context, organizationID uuid.UUID,
 	var workspace Workspace
 	return workspace, json.NewDecoder(res.Body).Decode(&workspace)
 }
+
+// UpdateOrganization updates the metadata of an organization.
+func (c *Client) UpdateOrganization(ctx context.Context, organizationID uuid.UUID, req UpdateOrganizationRequest) (Organization, error) {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/organizations/%s", organizationID), req)
+	if err != nil {
+		return Organization{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Organization{}, readBodyAsError(res)
+	}
+
+	var organization Organization
+	return organization, json.NewDecoder(res.Body).Decode(&organization)
+}
+
+// DeleteOrganization deletes an organization. All templates must be deleted
+// or moved to another organization first.
+func (c *Client) DeleteOrganization(ctx context.Context, organizationID uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/organizations/%s", organizationID), nil)
+	if err != nil {
+		return xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return readBodyAsError(res)
+	}
+	return nil
+}
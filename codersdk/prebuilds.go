@@ -0,0 +1,59 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// TemplatePrebuilds describes the pool of prebuilt workspaces kept ready for
+// a template. Workspaces created with exactly the preset parameter values
+// are handed a ready prebuilt workspace instead of waiting for a build.
+type TemplatePrebuilds struct {
+	Count           int32                    `json:"count"`
+	ParameterValues []CreateParameterRequest `json:"parameter_values"`
+	// Ready is the number of prebuilt workspaces that can be claimed, and
+	// Pending is the number still building.
+	Ready   int32 `json:"ready"`
+	Pending int32 `json:"pending"`
+}
+
+// UpdateTemplatePrebuildsRequest sets the size of a template's prebuilt
+// workspace pool. A count of zero removes the pool. ParameterValues are left
+// unchanged when nil.
+type UpdateTemplatePrebuildsRequest struct {
+	Count           int32                    `json:"count" validate:"min=0"`
+	ParameterValues []CreateParameterRequest `json:"parameter_values,omitempty"`
+}
+
+// TemplatePrebuilds returns the prebuilt workspace pool for a template.
+func (c *Client) TemplatePrebuilds(ctx context.Context, template uuid.UUID) (TemplatePrebuilds, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/prebuilds", template), nil)
+	if err != nil {
+		return TemplatePrebuilds{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplatePrebuilds{}, readBodyAsError(res)
+	}
+	var prebuilds TemplatePrebuilds
+	return prebuilds, json.NewDecoder(res.Body).Decode(&prebuilds)
+}
+
+// UpdateTemplatePrebuilds changes the prebuilt workspace pool for a template.
+// The pool is filled or drained asynchronously.
+func (c *Client) UpdateTemplatePrebuilds(ctx context.Context, template uuid.UUID, req UpdateTemplatePrebuildsRequest) (TemplatePrebuilds, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templates/%s/prebuilds", template), req)
+	if err != nil {
+		return TemplatePrebuilds{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplatePrebuilds{}, readBodyAsError(res)
+	}
+	var prebuilds TemplatePrebuilds
+	return prebuilds, json.NewDecoder(res.Body).Decode(&prebuilds)
+}
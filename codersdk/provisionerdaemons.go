@@ -82,6 +82,16 @@ type ProvisionerJobLog struct {
 	Output    string    `json:"output"`
 }
 
+// ProvisionerTiming is how long a stage of a provisioner job took. Resource
+// and Action are set for stages that time each resource, such as "apply".
+type ProvisionerTiming struct {
+	Stage     string    `json:"stage"`
+	Resource  string    `json:"resource,omitempty"`
+	Action    string    `json:"action,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+}
+
 // provisionerJobLogsBefore provides log output that occurred before a time.
 // This is abstracted from a specific job type to provide consistency between
 // APIs. Logs is the only shared route between jobs.
@@ -0,0 +1,102 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/agent"
+)
+
+type SessionRecordingType string
+
+const (
+	SessionRecordingTypeSSH             SessionRecordingType = "ssh"
+	SessionRecordingTypeReconnectingPTY SessionRecordingType = "reconnecting_pty"
+)
+
+// WorkspaceSessionRecording is a recorded terminal session of a workspace.
+// The recording itself is downloaded separately in the asciicast v2 format.
+type WorkspaceSessionRecording struct {
+	ID          uuid.UUID            `json:"id"`
+	WorkspaceID uuid.UUID            `json:"workspace_id"`
+	AgentID     uuid.UUID            `json:"agent_id"`
+	UserID      uuid.UUID            `json:"user_id"`
+	Username    string               `json:"username"`
+	Type        SessionRecordingType `json:"type"`
+	StartedAt   time.Time            `json:"started_at"`
+	EndedAt     time.Time            `json:"ended_at"`
+	// Size is the size of the recording in bytes.
+	Size int64 `json:"size"`
+}
+
+// WorkspaceSessionRecordings returns the recorded terminal sessions of a
+// workspace, newest first.
+func (c *Client) WorkspaceSessionRecordings(ctx context.Context, workspaceID uuid.UUID) ([]WorkspaceSessionRecording, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/%s/sessions", workspaceID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var recordings []WorkspaceSessionRecording
+	return recordings, json.NewDecoder(res.Body).Decode(&recordings)
+}
+
+// DownloadWorkspaceSessionRecording returns a recorded terminal session in the
+// asciicast v2 format.
+func (c *Client) DownloadWorkspaceSessionRecording(ctx context.Context, workspaceID, recordingID uuid.UUID) ([]byte, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/%s/sessions/%s", workspaceID, recordingID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	return io.ReadAll(res.Body)
+}
+
+// WorkspaceAgentSessionRecordingToken attributes the recording of a session
+// with a workspace agent to the user it was issued to. The token is nil if
+// the template of the workspace doesn't record sessions.
+type WorkspaceAgentSessionRecordingToken struct {
+	Token uuid.UUID `json:"token"`
+}
+
+// WorkspaceAgentSessionRecordingToken issues a token for the authenticated
+// user to pass to the workspace agent when starting a session.
+func (c *Client) WorkspaceAgentSessionRecordingToken(ctx context.Context, agentID uuid.UUID) (WorkspaceAgentSessionRecordingToken, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/workspaceagents/%s/sessionrecordingtoken", agentID), nil)
+	if err != nil {
+		return WorkspaceAgentSessionRecordingToken{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return WorkspaceAgentSessionRecordingToken{}, readBodyAsError(res)
+	}
+	var token WorkspaceAgentSessionRecordingToken
+	return token, json.NewDecoder(res.Body).Decode(&token)
+}
+
+// UploadWorkspaceAgentSessionRecording uploads a terminal session recorded by
+// the workspace agent.
+func (c *Client) UploadWorkspaceAgentSessionRecording(ctx context.Context, recording agent.SessionRecording) error {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/workspaceagents/me/sessions", recording)
+	if err != nil {
+		return xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return readBodyAsError(res)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TemplateGitSource is the git repository a template is synced from. Coderd
+// fetches the repository periodically, and creates a template version
+// whenever the ref points at a new commit.
+type TemplateGitSource struct {
+	TemplateID uuid.UUID `json:"template_id"`
+	// RepositoryURL has any credentials removed.
+	RepositoryURL string `json:"repository_url"`
+	Ref           string `json:"ref"`
+	Subdirectory  string `json:"subdirectory"`
+	// AutoPromote makes new template versions active once a dry run of them
+	// succeeds.
+	AutoPromote bool `json:"auto_promote"`
+	// CommitSHA is the commit the latest template version was created from.
+	CommitSHA string `json:"commit_sha"`
+	// PendingVersionID is the template version waiting to be promoted.
+	PendingVersionID *uuid.UUID `json:"pending_version_id,omitempty"`
+	SyncedAt         *time.Time `json:"synced_at,omitempty"`
+	// SyncError is the error of the latest sync, if it failed.
+	SyncError string `json:"sync_error,omitempty"`
+}
+
+// UpdateTemplateGitSourceRequest sets the git repository a template is synced
+// from. Credentials for private repositories can be included in the URL.
+type UpdateTemplateGitSourceRequest struct {
+	// RepositoryURL is required unless the template is already synced from
+	// git, in which case an empty URL keeps the current one.
+	RepositoryURL string `json:"repository_url"`
+	Ref           string `json:"ref" validate:"required"`
+	Subdirectory  string `json:"subdirectory,omitempty"`
+	AutoPromote   bool   `json:"auto_promote"`
+}
+
+// TemplateGitSource returns the git repository a template is synced from.
+func (c *Client) TemplateGitSource(ctx context.Context, template uuid.UUID) (TemplateGitSource, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/git-source", template), nil)
+	if err != nil {
+		return TemplateGitSource{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateGitSource{}, readBodyAsError(res)
+	}
+	var source TemplateGitSource
+	return source, json.NewDecoder(res.Body).Decode(&source)
+}
+
+// UpdateTemplateGitSource syncs a template from a git repository. The
+// repository is fetched before returning, and a template version is created
+// if the ref points at a new commit.
+func (c *Client) UpdateTemplateGitSource(ctx context.Context, template uuid.UUID, req UpdateTemplateGitSourceRequest) (TemplateGitSource, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templates/%s/git-source", template), req)
+	if err != nil {
+		return TemplateGitSource{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateGitSource{}, readBodyAsError(res)
+	}
+	var source TemplateGitSource
+	return source, json.NewDecoder(res.Body).Decode(&source)
+}
+
+// DeleteTemplateGitSource stops syncing a template from a git repository.
+// Existing template versions are kept.
+func (c *Client) DeleteTemplateGitSource(ctx context.Context, template uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/templates/%s/git-source", template), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return readBodyAsError(res)
+	}
+	return nil
+}
+
+// SyncTemplateGitSource syncs a template from its git repository now instead
+// of waiting for the next periodic sync. It fails with a conflict if the
+// template is already being synced.
+func (c *Client) SyncTemplateGitSource(ctx context.Context, template uuid.UUID) (TemplateGitSource, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/templates/%s/git-source/sync", template), nil)
+	if err != nil {
+		return TemplateGitSource{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateGitSource{}, readBodyAsError(res)
+	}
+	var source TemplateGitSource
+	return source, json.NewDecoder(res.Body).Decode(&source)
+}
@@ -0,0 +1,85 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InsightsDateFormat is the format of the dates insights are requested for.
+const InsightsDateFormat = "2006-01-02"
+
+// TemplateInsightsRequest selects the days to return insights for. Days are
+// in UTC, and both dates are inclusive. The last week is returned if the
+// dates are zero.
+type TemplateInsightsRequest struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+// asRequestOption returns a function that can be used in (*Client).Request.
+// It modifies the request query parameters.
+func (r TemplateInsightsRequest) asRequestOption() requestOption {
+	return func(req *http.Request) {
+		q := req.URL.Query()
+		if !r.StartDate.IsZero() {
+			q.Set("start_date", r.StartDate.Format(InsightsDateFormat))
+		}
+		if !r.EndDate.IsZero() {
+			q.Set("end_date", r.EndDate.Format(InsightsDateFormat))
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+}
+
+// TemplateInsights summarizes how a template was used over a range of days.
+// Insights are computed periodically, so the current day may lag behind.
+type TemplateInsights struct {
+	TemplateID      uuid.UUID `json:"template_id"`
+	StartDate       time.Time `json:"start_date"`
+	EndDate         time.Time `json:"end_date"`
+	BuildsTotal     int64     `json:"builds_total"`
+	BuildsSucceeded int64     `json:"builds_succeeded"`
+	// BuildSuccessRate is between 0 and 1. It's 0 if there were no builds.
+	BuildSuccessRate float64 `json:"build_success_rate"`
+	// BuildDurationMedianMillis and AgentConnectMedianMillis are the medians
+	// of the daily medians.
+	BuildDurationMedianMillis *int64                `json:"build_duration_median_ms,omitempty"`
+	AgentConnectMedianMillis  *int64                `json:"agent_connect_median_ms,omitempty"`
+	Days                      []TemplateInsightsDay `json:"days"`
+}
+
+// TemplateInsightsDay is how a template was used on a single day.
+type TemplateInsightsDay struct {
+	Date time.Time `json:"date"`
+	// ActiveUsers is the number of users that started a build, connected to
+	// a workspace agent or used an app.
+	ActiveUsers     int64 `json:"active_users"`
+	BuildsTotal     int64 `json:"builds_total"`
+	BuildsSucceeded int64 `json:"builds_succeeded"`
+	// BuildDurationMedianMillis is the median duration of successful builds.
+	BuildDurationMedianMillis *int64 `json:"build_duration_median_ms,omitempty"`
+	// AgentConnectMedianMillis is the median time from the start of a build
+	// until an agent of the workspace first connected.
+	AgentConnectMedianMillis *int64 `json:"agent_connect_median_ms,omitempty"`
+	// AppUsage maps app names to the number of users that used them.
+	AppUsage map[string]int64 `json:"app_usage"`
+}
+
+// TemplateInsights returns usage insights of a template.
+func (c *Client) TemplateInsights(ctx context.Context, template uuid.UUID, req TemplateInsightsRequest) (TemplateInsights, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/insights", template), nil, req.asRequestOption())
+	if err != nil {
+		return TemplateInsights{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateInsights{}, readBodyAsError(res)
+	}
+	var insights TemplateInsights
+	return insights, json.NewDecoder(res.Body).Decode(&insights)
+}
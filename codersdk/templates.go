@@ -28,6 +28,13 @@ type Template struct {
 	MinAutostartIntervalMillis int64           `json:"min_autostart_interval_ms"`
 	CreatedByID                uuid.UUID       `json:"created_by_id"`
 	CreatedByName              string          `json:"created_by_name"`
+	InactivityTTLMillis        int64           `json:"inactivity_ttl_ms"`
+	DormantAutoDeleteTTLMillis int64           `json:"dormant_autodelete_ttl_ms"`
+	MaintenanceWindow          string          `json:"maintenance_window"`
+	MaintenanceWindowMillis    int64           `json:"maintenance_window_ms"`
+	MaintenanceRestartOutdated bool            `json:"maintenance_restart_outdated"`
+	PrebuildCount              int32           `json:"prebuild_count"`
+	RecordSessions             bool            `json:"record_sessions"`
 }
 
 type UpdateActiveTemplateVersion struct {
@@ -40,6 +47,25 @@ type UpdateTemplateMeta struct {
 	Icon                       string `json:"icon,omitempty"`
 	MaxTTLMillis               int64  `json:"max_ttl_ms,omitempty"`
 	MinAutostartIntervalMillis int64  `json:"min_autostart_interval_ms,omitempty"`
+	// InactivityTTLMillis and DormantAutoDeleteTTLMillis are left unchanged
+	// when nil. Zero disables the policy.
+	InactivityTTLMillis        *int64 `json:"inactivity_ttl_ms,omitempty"`
+	DormantAutoDeleteTTLMillis *int64 `json:"dormant_autodelete_ttl_ms,omitempty"`
+	// MaintenanceWindow is a weekly cron schedule for when maintenance
+	// windows start, and MaintenanceWindowMillis is how long they last.
+	// Pending autostops are deferred until a window. An empty schedule
+	// removes the window. Nil fields are left unchanged.
+	MaintenanceWindow          *string `json:"maintenance_window,omitempty"`
+	MaintenanceWindowMillis    *int64  `json:"maintenance_window_ms,omitempty"`
+	MaintenanceRestartOutdated *bool   `json:"maintenance_restart_outdated,omitempty"`
+	// RecordSessions enables recording of terminal sessions in workspaces of
+	// the template. Nil leaves it unchanged.
+	RecordSessions *bool `json:"record_sessions,omitempty"`
+}
+
+// UpdateTemplateOrganizationRequest moves a template to another organization.
+type UpdateTemplateOrganizationRequest struct {
+	OrganizationID uuid.UUID `json:"organization_id" validate:"required"`
 }
 
 // Template returns a single template.
@@ -84,6 +110,24 @@ func (c *Client) UpdateTemplateMeta(ctx context.Context, templateID uuid.UUID, r
 	return updated, json.NewDecoder(res.Body).Decode(&updated)
 }
 
+// UpdateTemplateOrganization moves a template, its versions and the
+// workspaces built from it into another organization.
+func (c *Client) UpdateTemplateOrganization(ctx context.Context, templateID uuid.UUID, req UpdateTemplateOrganizationRequest) (Template, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templates/%s/organization", templateID), req)
+	if err != nil {
+		return Template{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotModified {
+		return Template{}, xerrors.New("template is already in this organization")
+	}
+	if res.StatusCode != http.StatusOK {
+		return Template{}, readBodyAsError(res)
+	}
+	var updated Template
+	return updated, json.NewDecoder(res.Body).Decode(&updated)
+}
+
 // UpdateActiveTemplateVersion updates the active template version to the ID provided.
 // The template version must be attached to the template.
 func (c *Client) UpdateActiveTemplateVersion(ctx context.Context, template uuid.UUID, req UpdateActiveTemplateVersion) error {
@@ -22,6 +22,94 @@ type TemplateVersion struct {
 	Readme         string         `json:"readme"`
 	CreatedByID    uuid.UUID      `json:"created_by_id"`
 	CreatedByName  string         `json:"created_by_name"`
+	// Message describes the changes in this version. It defaults to the
+	// subject of the commit the version was pushed from.
+	Message string `json:"message"`
+	// Git is set when the version was pushed from a git repository.
+	Git *TemplateVersionGitMetadata `json:"git,omitempty"`
+	// Diagnostics are problems found while validating the source of the
+	// version when it was imported.
+	Diagnostics []TemplateVersionDiagnostic `json:"diagnostics"`
+}
+
+// TemplateVersionPreset is a named set of parameter values, defined by the
+// template author in presets.yaml. Workspaces can be created from a preset
+// instead of answering each parameter.
+type TemplateVersionPreset struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Parameters  map[string]string `json:"parameters"`
+}
+
+// TemplateVersionVariable is a Terraform variable that's set by template
+// admins when they push a version. Unlike parameters, workspace owners are
+// never prompted for them.
+type TemplateVersionVariable struct {
+	Name string `json:"name"`
+	// Value is empty for sensitive variables.
+	Value     string `json:"value"`
+	Sensitive bool   `json:"sensitive"`
+}
+
+type TemplateVersionDiagnosticSeverity string
+
+const (
+	TemplateVersionDiagnosticSeverityWarning TemplateVersionDiagnosticSeverity = "warning"
+	TemplateVersionDiagnosticSeverityError   TemplateVersionDiagnosticSeverity = "error"
+)
+
+// TemplateVersionDiagnostic is a problem found in the source of a template
+// version, such as an agent that's never started.
+type TemplateVersionDiagnostic struct {
+	Severity TemplateVersionDiagnosticSeverity `json:"severity"`
+	Summary  string                            `json:"summary"`
+	Detail   string                            `json:"detail,omitempty"`
+	// Filename and Line locate the problem in the source, if it relates to a
+	// particular line.
+	Filename string `json:"filename,omitempty"`
+	Line     int    `json:"line,omitempty"`
+}
+
+// TemplateVersionGitMetadata describes the git commit a template version was
+// pushed from.
+type TemplateVersionGitMetadata struct {
+	CommitSHA string `json:"commit_sha"`
+	Branch    string `json:"branch,omitempty"`
+	RemoteURL string `json:"remote_url,omitempty"`
+	Author    string `json:"author,omitempty"`
+	// Dirty is true if the working tree had uncommitted changes when the
+	// version was pushed.
+	Dirty bool `json:"dirty"`
+}
+
+// TemplateVersionFileDiffStatus describes how a file changed between two
+// template versions.
+type TemplateVersionFileDiffStatus string
+
+const (
+	TemplateVersionFileDiffStatusAdded    TemplateVersionFileDiffStatus = "added"
+	TemplateVersionFileDiffStatusRemoved  TemplateVersionFileDiffStatus = "removed"
+	TemplateVersionFileDiffStatusModified TemplateVersionFileDiffStatus = "modified"
+)
+
+// TemplateVersionFileDiff is the difference of a single file in the source
+// archives of two template versions.
+type TemplateVersionFileDiff struct {
+	Path   string                        `json:"path"`
+	Status TemplateVersionFileDiffStatus `json:"status"`
+	// Binary is true if either side of the file is not text. Binary files
+	// have no diff.
+	Binary bool `json:"binary"`
+	// Diff is the unified diff of the file.
+	Diff string `json:"diff,omitempty"`
+}
+
+// TemplateVersionDiff is the difference between the source archives of two
+// template versions. Files that are identical in both are omitted.
+type TemplateVersionDiff struct {
+	From  uuid.UUID                 `json:"from"`
+	To    uuid.UUID                 `json:"to"`
+	Files []TemplateVersionFileDiff `json:"files"`
 }
 
 // TemplateVersion returns a template version by ID.
@@ -38,6 +126,21 @@ func (c *Client) TemplateVersion(ctx context.Context, id uuid.UUID) (TemplateVer
 	return version, json.NewDecoder(res.Body).Decode(&version)
 }
 
+// TemplateVersionDiff returns the difference between the source archives of
+// the "from" and "to" template versions.
+func (c *Client) TemplateVersionDiff(ctx context.Context, from, to uuid.UUID) (TemplateVersionDiff, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templateversions/%s/diff/%s", from, to), nil)
+	if err != nil {
+		return TemplateVersionDiff{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateVersionDiff{}, readBodyAsError(res)
+	}
+	var diff TemplateVersionDiff
+	return diff, json.NewDecoder(res.Body).Decode(&diff)
+}
+
 // CancelTemplateVersion marks a template version job as canceled.
 func (c *Client) CancelTemplateVersion(ctx context.Context, version uuid.UUID) error {
 	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/templateversions/%s/cancel", version), nil)
@@ -65,6 +168,36 @@ func (c *Client) TemplateVersionSchema(ctx context.Context, version uuid.UUID) (
 	return params, json.NewDecoder(res.Body).Decode(&params)
 }
 
+// TemplateVersionVariables returns the template variables set for a template
+// version. Values of sensitive variables are empty.
+func (c *Client) TemplateVersionVariables(ctx context.Context, version uuid.UUID) ([]TemplateVersionVariable, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templateversions/%s/variables", version), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var variables []TemplateVersionVariable
+	return variables, json.NewDecoder(res.Body).Decode(&variables)
+}
+
+// TemplateVersionPresets returns the parameter presets defined by the author of
+// a template version.
+func (c *Client) TemplateVersionPresets(ctx context.Context, version uuid.UUID) ([]TemplateVersionPreset, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templateversions/%s/presets", version), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var presets []TemplateVersionPreset
+	return presets, json.NewDecoder(res.Body).Decode(&presets)
+}
+
 // TemplateVersionParameters returns computed parameters for a template version.
 func (c *Client) TemplateVersionParameters(ctx context.Context, version uuid.UUID) ([]ComputedParameter, error) {
 	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templateversions/%s/parameters", version), nil)
@@ -0,0 +1,143 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	"nhooyr.io/websocket"
+)
+
+type TerminalShareMode string
+
+const (
+	// TerminalShareModeReadOnly shares can only watch the terminal.
+	TerminalShareModeReadOnly TerminalShareMode = "read_only"
+	// TerminalShareModeReadWrite shares can type into the terminal.
+	TerminalShareModeReadWrite TerminalShareMode = "read_write"
+)
+
+// TerminalShare lets the recipients attach to a reconnecting PTY of a
+// workspace agent until it expires.
+type TerminalShare struct {
+	ID          uuid.UUID         `json:"id"`
+	CreatedAt   time.Time         `json:"created_at"`
+	WorkspaceID uuid.UUID         `json:"workspace_id"`
+	AgentID     uuid.UUID         `json:"agent_id"`
+	ReconnectID uuid.UUID         `json:"reconnect_id"`
+	CreatedBy   uuid.UUID         `json:"created_by"`
+	Mode        TerminalShareMode `json:"mode"`
+	// Recipients are the users that may join the terminal.
+	Recipients []uuid.UUID `json:"recipients"`
+	ExpiresAt  time.Time   `json:"expires_at"`
+	// Viewers are the users currently attached through the share.
+	Viewers []TerminalShareViewer `json:"viewers"`
+}
+
+type TerminalShareViewer struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Username    string    `json:"username"`
+	ConnectedAt time.Time `json:"connected_at"`
+}
+
+// CreateTerminalShareRequest shares the reconnecting PTY with the given
+// reconnect ID with the recipients, which must be members of the organization
+// of the workspace.
+type CreateTerminalShareRequest struct {
+	ReconnectID uuid.UUID         `json:"reconnect_id" validate:"required"`
+	Mode        TerminalShareMode `json:"mode" validate:"required,oneof=read_only read_write"`
+	Recipients  []uuid.UUID       `json:"recipients" validate:"required,min=1"`
+	// TTLMillis is how long the share lasts. Defaults to an hour.
+	TTLMillis int64 `json:"ttl_ms,omitempty" validate:"min=0"`
+}
+
+// CreateTerminalShare shares a reconnecting PTY of the agent.
+func (c *Client) CreateTerminalShare(ctx context.Context, agentID uuid.UUID, req CreateTerminalShareRequest) (TerminalShare, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/workspaceagents/%s/terminalshares", agentID), req)
+	if err != nil {
+		return TerminalShare{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return TerminalShare{}, readBodyAsError(res)
+	}
+	var share TerminalShare
+	return share, json.NewDecoder(res.Body).Decode(&share)
+}
+
+// TerminalShares returns the terminal shares of the agent.
+func (c *Client) TerminalShares(ctx context.Context, agentID uuid.UUID) ([]TerminalShare, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaceagents/%s/terminalshares", agentID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var shares []TerminalShare
+	return shares, json.NewDecoder(res.Body).Decode(&shares)
+}
+
+// TerminalShare returns a terminal share by ID.
+func (c *Client) TerminalShare(ctx context.Context, id uuid.UUID) (TerminalShare, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/terminalshares/%s", id), nil)
+	if err != nil {
+		return TerminalShare{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TerminalShare{}, readBodyAsError(res)
+	}
+	var share TerminalShare
+	return share, json.NewDecoder(res.Body).Decode(&share)
+}
+
+// DeleteTerminalShare revokes a terminal share and disconnects its viewers.
+func (c *Client) DeleteTerminalShare(ctx context.Context, id uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/terminalshares/%s", id), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return readBodyAsError(res)
+	}
+	return nil
+}
+
+// TerminalSharePTY attaches to a shared reconnecting PTY. Input is dropped
+// for read-only shares, and the size of the PTY is controlled by its owner.
+func (c *Client) TerminalSharePTY(ctx context.Context, id uuid.UUID) (net.Conn, error) {
+	serverURL, err := c.URL.Parse(fmt.Sprintf("/api/v2/terminalshares/%s/pty", id))
+	if err != nil {
+		return nil, xerrors.Errorf("parse url: %w", err)
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, xerrors.Errorf("create cookie jar: %w", err)
+	}
+	jar.SetCookies(serverURL, []*http.Cookie{{
+		Name:  SessionTokenKey,
+		Value: c.SessionToken,
+	}})
+	httpClient := &http.Client{
+		Jar: jar,
+	}
+	conn, res, err := websocket.Dial(ctx, serverURL.String(), &websocket.DialOptions{
+		HTTPClient: httpClient,
+	})
+	if err != nil {
+		if res == nil {
+			return nil, err
+		}
+		return nil, readBodyAsError(res)
+	}
+	return websocket.NetConn(ctx, conn, websocket.MessageBinary), nil
+}
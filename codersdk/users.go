@@ -176,6 +176,16 @@ type AuthMethods struct {
 	Password bool `json:"password"`
 	Github   bool `json:"github"`
 	OIDC     bool `json:"oidc"`
+	// OIDCProviders are named OIDC providers that are each
+	// authenticated with at /api/v2/users/oidc/{name}/callback.
+	OIDCProviders []OIDCAuthMethod `json:"oidc_providers"`
+}
+
+// OIDCAuthMethod describes a named OIDC provider.
+type OIDCAuthMethod struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	IconURL     string `json:"icon_url"`
 }
 
 // HasFirstUser returns whether the first user has been created.
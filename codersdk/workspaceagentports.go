@@ -0,0 +1,35 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// WorkspaceAgentListeningPort is a TCP port that a process in the workspace
+// listens on.
+type WorkspaceAgentListeningPort struct {
+	// ProcessName is empty if the process is owned by another user.
+	ProcessName string `json:"process_name"`
+	Network     string `json:"network"`
+	Port        uint16 `json:"port"`
+}
+
+// WorkspaceAgentListeningPorts returns the TCP ports that processes in the
+// workspace of a connected agent listen on, ordered by port. Only Linux
+// agents scan listening ports.
+func (c *Client) WorkspaceAgentListeningPorts(ctx context.Context, agentID uuid.UUID) ([]WorkspaceAgentListeningPort, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaceagents/%s/listening-ports", agentID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var ports []WorkspaceAgentListeningPort
+	return ports, json.NewDecoder(res.Body).Decode(&ports)
+}
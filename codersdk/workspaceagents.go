@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/cookiejar"
+	"net/url"
 
 	"cloud.google.com/go/compute/metadata"
 	"github.com/google/uuid"
@@ -20,6 +21,7 @@ import (
 	"cdr.dev/slog"
 
 	"github.com/coder/coder/agent"
+	"github.com/coder/coder/buildinfo"
 	"github.com/coder/coder/coderd/turnconn"
 	"github.com/coder/coder/peer"
 	"github.com/coder/coder/peer/peerwg"
@@ -183,6 +185,10 @@ func (c *Client) ListenWorkspaceAgent(ctx context.Context, logger slog.Logger) (
 	if err != nil {
 		return agent.Metadata{}, nil, xerrors.Errorf("parse url: %w", err)
 	}
+	// coderd reports the version of agents, and flags outdated ones.
+	serverURL.RawQuery = url.Values{
+		"version": []string{buildinfo.Version()},
+	}.Encode()
 	jar, err := cookiejar.New(nil)
 	if err != nil {
 		return agent.Metadata{}, nil, xerrors.Errorf("create cookie jar: %w", err)
@@ -343,6 +349,36 @@ func (c *Client) UploadWorkspaceAgentKeys(ctx context.Context, keys agent.Wiregu
 	return nil
 }
 
+type PostWorkspaceAgentHealthRequest struct {
+	Checks []WorkspaceAgentHealthCheck `json:"checks"`
+}
+
+// PostWorkspaceAgentHealth reports the results of the health checks of the
+// workspace agent. The results replace those of the previous report.
+func (c *Client) PostWorkspaceAgentHealth(ctx context.Context, results []agent.HealthCheckResult) error {
+	req := PostWorkspaceAgentHealthRequest{
+		Checks: make([]WorkspaceAgentHealthCheck, 0, len(results)),
+	}
+	for _, result := range results {
+		req.Checks = append(req.Checks, WorkspaceAgentHealthCheck{
+			Name:           result.Name,
+			Healthy:        result.Healthy,
+			Output:         result.Output,
+			CheckedAt:      result.CheckedAt,
+			IntervalMillis: result.Interval.Milliseconds(),
+		})
+	}
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/workspaceagents/me/health", req)
+	if err != nil {
+		return xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return readBodyAsError(res)
+	}
+	return nil
+}
+
 // DialWorkspaceAgent creates a connection to the specified resource.
 func (c *Client) DialWorkspaceAgent(ctx context.Context, agentID uuid.UUID, options *peer.ConnOptions) (*agent.Conn, error) {
 	serverURL, err := c.URL.Parse(fmt.Sprintf("/api/v2/workspaceagents/%s/dial", agentID.String()))
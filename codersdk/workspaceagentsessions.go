@@ -0,0 +1,67 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WorkspaceAgentSessionType string
+
+const (
+	WorkspaceAgentSessionTypeSSH             WorkspaceAgentSessionType = "ssh"
+	WorkspaceAgentSessionTypeReconnectingPTY WorkspaceAgentSessionType = "reconnecting_pty"
+	// WorkspaceAgentSessionTypeDial sessions are port forwards, and
+	// connections of the application proxy.
+	WorkspaceAgentSessionTypeDial WorkspaceAgentSessionType = "dial"
+)
+
+// WorkspaceAgentSession is a connection to a workspace agent that is
+// currently active.
+type WorkspaceAgentSession struct {
+	ID   uuid.UUID                 `json:"id"`
+	Type WorkspaceAgentSessionType `json:"type"`
+	// RemoteUser is the user name sent by SSH clients. It's empty for other
+	// sessions.
+	RemoteUser string    `json:"remote_user"`
+	StartedAt  time.Time `json:"started_at"`
+	// RxBytes is the number of bytes the agent received from the client.
+	RxBytes int64 `json:"rx_bytes"`
+	// TxBytes is the number of bytes the agent sent to the client.
+	TxBytes int64 `json:"tx_bytes"`
+	// Command is the command of terminal sessions, or the dialed address of
+	// dial sessions. It's empty for shells.
+	Command string `json:"command"`
+}
+
+// WorkspaceAgentSessions returns the active sessions of a connected workspace
+// agent, oldest first.
+func (c *Client) WorkspaceAgentSessions(ctx context.Context, agentID uuid.UUID) ([]WorkspaceAgentSession, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaceagents/%s/sessions", agentID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var sessions []WorkspaceAgentSession
+	return sessions, json.NewDecoder(res.Body).Decode(&sessions)
+}
+
+// TerminateWorkspaceAgentSession ends an active session of a workspace agent.
+func (c *Client) TerminateWorkspaceAgentSession(ctx context.Context, agentID, sessionID uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/workspaceagents/%s/sessions/%s", agentID, sessionID), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return readBodyAsError(res)
+	}
+	return nil
+}
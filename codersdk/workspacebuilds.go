@@ -31,6 +31,14 @@ const (
 	// "autostop" is used when a build to stop a workspace is triggered by Autostop.
 	// The initiator id/username in this case is the workspace owner and can be ignored.
 	BuildReasonAutostop BuildReason = "autostop"
+	// "autodelete" is used when a build to delete a dormant workspace is triggered
+	// by the template's dormancy policy.
+	// The initiator id/username in this case is the workspace owner and can be ignored.
+	BuildReasonAutodelete BuildReason = "autodelete"
+	// "maintenance" is used when an outdated workspace is restarted on the
+	// latest template version during the template's maintenance window.
+	// The initiator id/username in this case is the workspace owner and can be ignored.
+	BuildReasonMaintenance BuildReason = "maintenance"
 )
 
 // WorkspaceBuild is an at-point representation of a workspace state.
@@ -119,6 +127,21 @@ func (c *Client) WorkspaceBuildState(ctx context.Context, build uuid.UUID) ([]by
 	return io.ReadAll(res.Body)
 }
 
+// WorkspaceBuildTimings returns how long each stage of the build took,
+// ordered by when they started.
+func (c *Client) WorkspaceBuildTimings(ctx context.Context, build uuid.UUID) ([]ProvisionerTiming, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspacebuilds/%s/timings", build), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var timings []ProvisionerTiming
+	return timings, json.NewDecoder(res.Body).Decode(&timings)
+}
+
 func (c *Client) WorkspaceBuildByUsernameAndWorkspaceNameAndBuildNumber(ctx context.Context, username string, workspaceName string, buildNumber string) (WorkspaceBuild, error) {
 	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/users/%s/workspace/%s/builds/%s", username, workspaceName, buildNumber), nil)
 	if err != nil {
@@ -57,6 +57,34 @@ type WorkspaceAgent struct {
 	WireguardPublicKey   key.NodePublic       `json:"wireguard_public_key"`
 	DiscoPublicKey       key.DiscoPublic      `json:"disco_public_key"`
 	IPv6                 netaddr.IPPrefix     `json:"ipv6"`
+	// Version is the version of the agent that connected last. It's empty
+	// if the agent never connected.
+	Version string `json:"version"`
+	// Outdated is true if the major or minor version of the agent doesn't
+	// match coderd.
+	Outdated bool `json:"outdated"`
+	// Health is the result of the last run of the health checks of the
+	// agent.
+	Health WorkspaceAgentHealth `json:"health"`
+}
+
+// WorkspaceAgentHealth summarizes the health checks of an agent. Agents
+// without health checks are healthy. Agents with stale checks aren't.
+type WorkspaceAgentHealth struct {
+	Healthy bool                        `json:"healthy"`
+	Checks  []WorkspaceAgentHealthCheck `json:"checks"`
+}
+
+type WorkspaceAgentHealthCheck struct {
+	Name      string    `json:"name"`
+	Healthy   bool      `json:"healthy"`
+	Output    string    `json:"output"`
+	CheckedAt time.Time `json:"checked_at"`
+	// IntervalMillis is how often the agent runs the check.
+	IntervalMillis int64 `json:"interval_ms"`
+	// Stale is true if the agent hasn't reported the check for two
+	// intervals. Healthy is the result of the last report.
+	Stale bool `json:"stale"`
 }
 
 type WorkspaceAgentResourceMetadata struct {
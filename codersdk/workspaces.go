@@ -30,6 +30,31 @@ type Workspace struct {
 	Name              string         `json:"name"`
 	AutostartSchedule *string        `json:"autostart_schedule,omitempty"`
 	TTLMillis         *int64         `json:"ttl_ms,omitempty"`
+	LastUsedAt        time.Time      `json:"last_used_at"`
+	// DormantAt is set when the workspace has been marked dormant by its
+	// template's inactivity policy. Dormant workspaces cannot be started.
+	DormantAt *time.Time `json:"dormant_at,omitempty"`
+	// DeletingAt is when a dormant workspace will be deleted automatically.
+	DeletingAt *time.Time `json:"deleting_at,omitempty"`
+	// BecomesDormantAt is when a stopped workspace will be marked dormant,
+	// unless it's used before then.
+	BecomesDormantAt *time.Time `json:"becomes_dormant_at,omitempty"`
+	// Prebuild is set for prebuilt workspaces that have not been claimed by
+	// a user yet.
+	Prebuild bool `json:"prebuild"`
+	// Preset is the parameter preset the workspace was created with. Its
+	// values are applied again when the workspace is updated.
+	Preset string `json:"preset,omitempty"`
+	// Health summarizes the health checks of the agents of the latest build.
+	Health WorkspaceHealth `json:"health"`
+}
+
+// WorkspaceHealth is degraded if the health checks of any agent of the
+// workspace fail.
+type WorkspaceHealth struct {
+	Healthy bool `json:"healthy"`
+	// FailingAgents are the IDs of the agents with failing health checks.
+	FailingAgents []uuid.UUID `json:"failing_agents"`
 }
 
 // CreateWorkspaceBuildRequest provides options to update the latest workspace build.
@@ -219,6 +244,27 @@ func (c *Client) UpdateWorkspaceTTL(ctx context.Context, id uuid.UUID, req Updat
 	return nil
 }
 
+// UpdateWorkspaceDormancyRequest is a request to mark a workspace as dormant,
+// or to wake up a dormant workspace.
+type UpdateWorkspaceDormancyRequest struct {
+	Dormant bool `json:"dormant"`
+}
+
+// UpdateWorkspaceDormancy marks a workspace as dormant or wakes it up. Waking
+// a workspace acknowledges the dormancy and resets its inactivity timer.
+func (c *Client) UpdateWorkspaceDormancy(ctx context.Context, id uuid.UUID, req UpdateWorkspaceDormancyRequest) error {
+	path := fmt.Sprintf("/api/v2/workspaces/%s/dormant", id.String())
+	res, err := c.Request(ctx, http.MethodPut, path, req)
+	if err != nil {
+		return xerrors.Errorf("update workspace dormancy: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return readBodyAsError(res)
+	}
+	return nil
+}
+
 // PutExtendWorkspaceRequest is a request to extend the deadline of
 // the active workspace build.
 type PutExtendWorkspaceRequest struct {
@@ -246,6 +292,11 @@ type WorkspaceFilter struct {
 	Template string `json:"template,omitempty" typescript:"-"`
 	// Name will return partial matches
 	Name string `json:"name,omitempty" typescript:"-"`
+	// Dormant includes dormant workspaces, which are hidden by default.
+	Dormant bool `json:"dormant,omitempty" typescript:"-"`
+	// Prebuild includes unclaimed prebuilt workspaces, which are hidden by
+	// default.
+	Prebuild bool `json:"prebuild,omitempty" typescript:"-"`
 	// FilterQuery supports a raw filter query string
 	FilterQuery string `json:"q,omitempty"`
 }
@@ -266,6 +317,12 @@ func (f WorkspaceFilter) asRequestOption() requestOption {
 		if f.Template != "" {
 			params = append(params, fmt.Sprintf("template:%q", f.Template))
 		}
+		if f.Dormant {
+			params = append(params, "dormant:true")
+		}
+		if f.Prebuild {
+			params = append(params, "prebuild:true")
+		}
 		if f.FilterQuery != "" {
 			// If custom stuff is added, just add it on here.
 			params = append(params, f.FilterQuery)
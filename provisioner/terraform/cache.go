@@ -0,0 +1,483 @@
+package terraform
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"golang.org/x/xerrors"
+)
+
+// The cache directory holds a provider mirror and a module cache:
+//
+//	providers/  The Terraform plugin cache, and the filesystem mirror
+//	            providers are installed from in offline mode.
+//	modules/    Installed modules, keyed by the remote modules a template calls.
+//
+// Provisioners in different processes may share the cache directory, so it's
+// only modified while holding the cache lock.
+const (
+	cacheProvidersDirectory = "providers"
+	cacheModulesDirectory   = "modules"
+	cacheLockFile           = "cache.lock"
+	// offlineConfigFile is the Terraform CLI configuration used in offline
+	// mode. It only allows installing providers from the mirror.
+	offlineConfigFile = "offline.tfrc"
+)
+
+// providerPlatform is the directory name of providers built for this machine.
+var providerPlatform = runtime.GOOS + "_" + runtime.GOARCH
+
+// prepareCache creates the directories of the cache, and the CLI
+// configuration that's used in offline mode.
+func prepareCache(cachePath string) error {
+	for _, dir := range []string{cacheProvidersDirectory, cacheModulesDirectory} {
+		err := os.MkdirAll(filepath.Join(cachePath, dir), 0o700)
+		if err != nil {
+			return xerrors.Errorf("mkdir %q: %w", dir, err)
+		}
+	}
+	providersPath, err := filepath.Abs(filepath.Join(cachePath, cacheProvidersDirectory))
+	if err != nil {
+		return xerrors.Errorf("absolute provider mirror path: %w", err)
+	}
+	config := fmt.Sprintf("provider_installation {\n  filesystem_mirror {\n    path = %q\n  }\n}\n", providersPath)
+	err = os.WriteFile(filepath.Join(cachePath, offlineConfigFile), []byte(config), 0o600)
+	if err != nil {
+		return xerrors.Errorf("write offline configuration: %w", err)
+	}
+	return nil
+}
+
+// lockCache blocks until no other provisioner is using the cache.
+func lockCache(ctx context.Context, cachePath string) (unlock func(), err error) {
+	lock := flock.New(filepath.Join(cachePath, cacheLockFile))
+	locked, err := lock.TryLockContext(ctx, 100*time.Millisecond)
+	if err != nil {
+		return nil, xerrors.Errorf("lock cache: %w", err)
+	}
+	if !locked {
+		return nil, xerrors.New("lock cache: lock was not acquired")
+	}
+	return func() {
+		_ = lock.Unlock()
+	}, nil
+}
+
+// remoteModuleCalls returns the modules a template calls that Terraform
+// downloads, ordered by name. Local modules are part of the template, so they
+// aren't cached, but the remote modules they call are. Those are named by
+// their path, like "local.vpc".
+func remoteModuleCalls(directory string) []*tfconfig.ModuleCall {
+	calls := make([]*tfconfig.ModuleCall, 0)
+	walkLocalModules(directory, func(path string, _ string, module *tfconfig.Module) {
+		for _, call := range module.ModuleCalls {
+			if isLocalModuleSource(call.Source) {
+				continue
+			}
+			remote := *call
+			remote.Name = path + call.Name
+			calls = append(calls, &remote)
+		}
+	})
+	sort.Slice(calls, func(i, j int) bool {
+		return calls[i].Name < calls[j].Name
+	})
+	return calls
+}
+
+// localModuleDirectories returns the directory of the template and of every
+// local module it calls.
+func localModuleDirectories(directory string) []string {
+	directories := make([]string, 0)
+	walkLocalModules(directory, func(_ string, directory string, _ *tfconfig.Module) {
+		directories = append(directories, directory)
+	})
+	return directories
+}
+
+// walkLocalModules calls fn with the module in directory, and recursively
+// with the local modules it calls. path is the prefix of the names of calls
+// in the module, like "local.".
+func walkLocalModules(directory string, fn func(path, directory string, module *tfconfig.Module)) {
+	visited := map[string]struct{}{}
+	var walk func(path, directory string)
+	walk = func(path, directory string) {
+		directory = filepath.Clean(directory)
+		if _, ok := visited[directory]; ok {
+			return
+		}
+		visited[directory] = struct{}{}
+		module, diags := tfconfig.LoadModule(directory)
+		if diags.HasErrors() {
+			// Terraform reports the problem itself when it initializes.
+			return
+		}
+		fn(path, directory, module)
+		names := make([]string, 0, len(module.ModuleCalls))
+		for name := range module.ModuleCalls {
+			names = append(names, name)
+		}
+		// Modules called more than once are named after the first call.
+		sort.Strings(names)
+		for _, name := range names {
+			call := module.ModuleCalls[name]
+			if isLocalModuleSource(call.Source) {
+				walk(path+call.Name+".", filepath.Join(directory, filepath.FromSlash(call.Source)))
+			}
+		}
+	}
+	walk("", directory)
+}
+
+func isLocalModuleSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
+
+// moduleCacheKey identifies the installed modules of templates that make the
+// same module calls.
+func moduleCacheKey(calls []*tfconfig.ModuleCall) string {
+	if len(calls) == 0 {
+		return ""
+	}
+	hash := sha256.New()
+	for _, call := range calls {
+		_, _ = fmt.Fprintf(hash, "%s\x00%s\x00%s\n", call.Name, call.Source, call.Version)
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:32]
+}
+
+// moduleRefPattern matches the ref argument of module sources like
+// "git::https://example.com/vpc.git?ref=v1.2.0".
+var moduleRefPattern = regexp.MustCompile(`[?&]ref=([^&]+)`)
+
+// commitHashPattern matches abbreviated and full git commit hashes.
+var commitHashPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// modulesPinned returns whether every module call pins an exact version, so
+// the cached modules are the ones Terraform would download. Registry modules
+// must use an exact version, and other sources a ref that's a version tag or
+// a commit. Branches can't be told apart from tags, so other refs don't
+// count.
+func modulesPinned(calls []*tfconfig.ModuleCall) bool {
+	for _, call := range calls {
+		if call.Version != "" {
+			raw := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(call.Version), "="))
+			if _, err := version.NewVersion(raw); err != nil {
+				return false
+			}
+			continue
+		}
+		match := moduleRefPattern.FindStringSubmatch(call.Source)
+		if match == nil {
+			return false
+		}
+		if commitHashPattern.MatchString(match[1]) {
+			continue
+		}
+		if _, err := version.NewVersion(match[1]); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// restoreModules copies cached modules into the working directory. It
+// returns false when the modules aren't cached.
+func restoreModules(cachePath, key, workdir string) (bool, error) {
+	cached := filepath.Join(cachePath, cacheModulesDirectory, key)
+	if _, err := os.Stat(cached); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	installed := filepath.Join(workdir, ".terraform", "modules")
+	if _, err := os.Stat(installed); err == nil {
+		// The template shipped with its modules installed.
+		return true, nil
+	}
+	err := copyDirectory(cached, installed, nil)
+	if err != nil {
+		return false, xerrors.Errorf("copy cached modules: %w", err)
+	}
+	return true, nil
+}
+
+// storeModules caches the modules Terraform installed into the working
+// directory.
+func storeModules(cachePath, key, workdir string) error {
+	installed := filepath.Join(workdir, ".terraform", "modules")
+	if _, err := os.Stat(installed); err != nil {
+		return err
+	}
+	modulesPath := filepath.Join(cachePath, cacheModulesDirectory)
+	// Copy to a temporary directory first, so an interrupted copy is never
+	// mistaken for cached modules.
+	tempDir, err := os.MkdirTemp(modulesPath, key+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+	err = copyDirectory(installed, tempDir, nil)
+	if err != nil {
+		return xerrors.Errorf("copy installed modules: %w", err)
+	}
+	// Mirroring replaces modules that were cached before.
+	err = os.RemoveAll(filepath.Join(modulesPath, key))
+	if err != nil {
+		return err
+	}
+	return os.Rename(tempDir, filepath.Join(modulesPath, key))
+}
+
+// missingFromCache lists the providers and modules a template needs that
+// aren't in the cache, so offline mode can report all of them at once instead
+// of failing on the first one Terraform tries to download.
+func missingFromCache(cachePath, workdir string, calls []*tfconfig.ModuleCall, modulesCached bool) ([]string, error) {
+	missing := make([]string, 0)
+	directories := localModuleDirectories(workdir)
+	if !modulesCached {
+		for _, call := range calls {
+			module := fmt.Sprintf("module %q (%s", call.Name, call.Source)
+			if call.Version != "" {
+				module += " " + call.Version
+			}
+			missing = append(missing, module+")")
+		}
+	} else {
+		// The providers of modules are needed too.
+		dirs, err := installedModuleDirectories(workdir)
+		if err != nil {
+			return nil, err
+		}
+		directories = append(directories, dirs...)
+	}
+
+	providers := map[string][]string{}
+	for _, directory := range directories {
+		module, diags := tfconfig.LoadModule(directory)
+		if diags.HasErrors() {
+			continue
+		}
+		for address, constraints := range requiredProviders(module) {
+			providers[address] = append(providers[address], constraints...)
+		}
+	}
+	addresses := make([]string, 0, len(providers))
+	for address := range providers {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	for _, address := range addresses {
+		constraints := providers[address]
+		found, err := mirrorHasProvider(filepath.Join(cachePath, cacheProvidersDirectory), address, constraints)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			continue
+		}
+		provider := fmt.Sprintf("provider %s", address)
+		if len(constraints) > 0 {
+			provider += fmt.Sprintf(" (%s)", strings.Join(constraints, ", "))
+		}
+		missing = append(missing, provider+" for "+providerPlatform)
+	}
+	return missing, nil
+}
+
+// installedModuleDirectories returns the directories of the modules Terraform
+// installed, as listed in its modules manifest.
+func installedModuleDirectories(workdir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(workdir, ".terraform", "modules", "modules.json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var manifest struct {
+		Modules []struct {
+			Key string `json:"Key"`
+			Dir string `json:"Dir"`
+		} `json:"Modules"`
+	}
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return nil, xerrors.Errorf("parse modules manifest: %w", err)
+	}
+	dirs := make([]string, 0, len(manifest.Modules))
+	for _, module := range manifest.Modules {
+		if module.Key == "" {
+			// The root module.
+			continue
+		}
+		dirs = append(dirs, filepath.Join(workdir, filepath.FromSlash(module.Dir)))
+	}
+	return dirs, nil
+}
+
+// requiredProviders maps the fully qualified addresses of the providers a
+// module uses, such as "registry.terraform.io/coder/coder", to their version
+// constraints. Providers that resources use without requiring them are
+// implied to be from the hashicorp namespace, like Terraform does.
+func requiredProviders(module *tfconfig.Module) map[string][]string {
+	providers := map[string][]string{}
+	for name, requirement := range module.RequiredProviders {
+		source := requirement.Source
+		if source == "" {
+			source = "hashicorp/" + name
+		}
+		address := providerAddress(source)
+		providers[address] = append(providers[address], requirement.VersionConstraints...)
+	}
+	for _, resources := range []map[string]*tfconfig.Resource{module.ManagedResources, module.DataResources} {
+		for _, resource := range resources {
+			name := resource.Provider.Name
+			if name == "" || name == "terraform" {
+				// The terraform provider is built in.
+				continue
+			}
+			if _, ok := module.RequiredProviders[name]; ok {
+				continue
+			}
+			address := providerAddress("hashicorp/" + name)
+			if _, ok := providers[address]; !ok {
+				providers[address] = nil
+			}
+		}
+	}
+	return providers
+}
+
+func providerAddress(source string) string {
+	source = strings.ToLower(source)
+	if strings.Count(source, "/") == 1 {
+		return "registry.terraform.io/" + source
+	}
+	return source
+}
+
+// mirrorHasProvider returns whether a version of the provider that satisfies
+// the constraints is in the mirror for this platform. Both layouts of
+// filesystem mirrors are supported: the unpacked layout of the plugin cache,
+// and the packed layout of "terraform providers mirror".
+func mirrorHasProvider(mirrorPath, address string, constraints []string) (bool, error) {
+	var versionConstraints version.Constraints
+	if len(constraints) > 0 {
+		var err error
+		versionConstraints, err = version.NewConstraint(strings.Join(constraints, ","))
+		if err != nil {
+			// Terraform reports invalid constraints itself.
+			return true, nil
+		}
+	}
+	parts := strings.Split(address, "/")
+	if len(parts) != 3 {
+		return false, nil
+	}
+	providerPath := filepath.Join(mirrorPath, parts[0], parts[1], parts[2])
+	entries, err := os.ReadDir(providerPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	packed := regexp.MustCompile(`^terraform-provider-` + regexp.QuoteMeta(parts[2]) + `_(.+)_` + regexp.QuoteMeta(providerPlatform) + `\.zip$`)
+	for _, entry := range entries {
+		var raw string
+		if entry.IsDir() {
+			if _, err := os.Stat(filepath.Join(providerPath, entry.Name(), providerPlatform)); err != nil {
+				continue
+			}
+			raw = entry.Name()
+		} else if match := packed.FindStringSubmatch(entry.Name()); match != nil {
+			raw = match[1]
+		} else {
+			continue
+		}
+		providerVersion, err := version.NewVersion(raw)
+		if err != nil {
+			continue
+		}
+		if versionConstraints == nil || versionConstraints.Check(providerVersion) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// copyDirectory copies the files, directories and symlinks in src to dst.
+// Paths relative to src that skip returns true for aren't copied.
+func copyDirectory(src, dst string, skip func(path string) bool) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if skip != nil && rel != "." && skip(rel) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		target := filepath.Join(dst, rel)
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	source, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	// #nosec
+	destination, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(destination, source)
+	if err != nil {
+		_ = destination.Close()
+		return err
+	}
+	return destination.Close()
+}
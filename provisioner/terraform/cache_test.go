@@ -0,0 +1,229 @@
+// nolint:testpackage
+package terraform
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/stretchr/testify/require"
+)
+
+const cacheTestTemplate = `terraform {
+	required_providers {
+		coder = {
+			source  = "coder/coder"
+			version = "~> 0.4.0"
+		}
+	}
+}
+module "vpc" {
+	source  = "terraform-aws-modules/vpc/aws"
+	version = "3.14.0"
+}
+module "local" {
+	source = "./local"
+}
+resource "coder_agent" "main" {
+	os   = "linux"
+	arch = "amd64"
+}
+resource "docker_container" "workspace" {
+	image = "ubuntu"
+}`
+
+func writeCacheTestTemplate(t *testing.T) string {
+	t.Helper()
+	directory := t.TempDir()
+	err := os.WriteFile(filepath.Join(directory, "main.tf"), []byte(cacheTestTemplate), 0o600)
+	require.NoError(t, err)
+	return directory
+}
+
+func TestMissingFromCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+		cachePath := t.TempDir()
+		require.NoError(t, prepareCache(cachePath))
+		directory := writeCacheTestTemplate(t)
+
+		calls := remoteModuleCalls(directory)
+		require.Len(t, calls, 1)
+		missing, err := missingFromCache(cachePath, directory, calls, false)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			`module "vpc" (terraform-aws-modules/vpc/aws 3.14.0)`,
+			"provider registry.terraform.io/coder/coder (~> 0.4.0) for " + providerPlatform,
+			"provider registry.terraform.io/hashicorp/docker for " + providerPlatform,
+		}, missing)
+	})
+
+	t.Run("Mirrored", func(t *testing.T) {
+		t.Parallel()
+		cachePath := t.TempDir()
+		require.NoError(t, prepareCache(cachePath))
+		directory := writeCacheTestTemplate(t)
+		mirrorPath := filepath.Join(cachePath, cacheProvidersDirectory)
+
+		// The unpacked layout of the plugin cache.
+		err := os.MkdirAll(filepath.Join(mirrorPath, "registry.terraform.io", "coder", "coder", "0.4.9", providerPlatform), 0o700)
+		require.NoError(t, err)
+		// The packed layout of "terraform providers mirror".
+		dockerPath := filepath.Join(mirrorPath, "registry.terraform.io", "hashicorp", "docker")
+		require.NoError(t, os.MkdirAll(dockerPath, 0o700))
+		err = os.WriteFile(filepath.Join(dockerPath, "terraform-provider-docker_2.20.0_"+providerPlatform+".zip"), nil, 0o600)
+		require.NoError(t, err)
+
+		missing, err := missingFromCache(cachePath, directory, remoteModuleCalls(directory), true)
+		require.NoError(t, err)
+		require.Empty(t, missing)
+	})
+
+	t.Run("VersionMismatch", func(t *testing.T) {
+		t.Parallel()
+		cachePath := t.TempDir()
+		require.NoError(t, prepareCache(cachePath))
+		directory := writeCacheTestTemplate(t)
+		mirrorPath := filepath.Join(cachePath, cacheProvidersDirectory)
+
+		err := os.MkdirAll(filepath.Join(mirrorPath, "registry.terraform.io", "coder", "coder", "0.5.0", providerPlatform), 0o700)
+		require.NoError(t, err)
+		// Providers for other platforms don't count.
+		err = os.MkdirAll(filepath.Join(mirrorPath, "registry.terraform.io", "hashicorp", "docker", "2.20.0", "plan9_386"), 0o700)
+		require.NoError(t, err)
+
+		missing, err := missingFromCache(cachePath, directory, remoteModuleCalls(directory), true)
+		require.NoError(t, err)
+		require.Len(t, missing, 2)
+	})
+}
+
+func TestModuleCache(t *testing.T) {
+	t.Parallel()
+	cachePath := t.TempDir()
+	require.NoError(t, prepareCache(cachePath))
+	directory := writeCacheTestTemplate(t)
+	key := moduleCacheKey(remoteModuleCalls(directory))
+	require.NotEmpty(t, key)
+
+	restored, err := restoreModules(cachePath, key, directory)
+	require.NoError(t, err)
+	require.False(t, restored)
+
+	modulesPath := filepath.Join(directory, ".terraform", "modules")
+	require.NoError(t, os.MkdirAll(filepath.Join(modulesPath, "vpc"), 0o700))
+	manifest := `{"Modules":[{"Key":"","Source":"","Dir":"."},{"Key":"vpc","Source":"terraform-aws-modules/vpc/aws","Version":"3.14.0","Dir":".terraform/modules/vpc"}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(modulesPath, "modules.json"), []byte(manifest), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(modulesPath, "vpc", "main.tf"), []byte(`resource "aws_vpc" "this" {}`), 0o600))
+	require.NoError(t, storeModules(cachePath, key, directory))
+
+	other := writeCacheTestTemplate(t)
+	restored, err = restoreModules(cachePath, key, other)
+	require.NoError(t, err)
+	require.True(t, restored)
+	_, err = os.Stat(filepath.Join(other, ".terraform", "modules", "vpc", "main.tf"))
+	require.NoError(t, err)
+
+	// The providers of cached modules are needed too.
+	missing, err := missingFromCache(cachePath, other, remoteModuleCalls(other), true)
+	require.NoError(t, err)
+	require.Contains(t, missing, "provider registry.terraform.io/hashicorp/aws for "+providerPlatform)
+}
+
+func TestModuleCacheKey(t *testing.T) {
+	t.Parallel()
+
+	local := t.TempDir()
+	err := os.WriteFile(filepath.Join(local, "main.tf"), []byte(`module "local" {
+		source = "../modules/local"
+	}`), 0o600)
+	require.NoError(t, err)
+	require.Empty(t, moduleCacheKey(remoteModuleCalls(local)))
+
+	require.Equal(t,
+		moduleCacheKey(remoteModuleCalls(writeCacheTestTemplate(t))),
+		moduleCacheKey(remoteModuleCalls(writeCacheTestTemplate(t))))
+}
+
+func TestLocalModuleCalls(t *testing.T) {
+	t.Parallel()
+	cachePath := t.TempDir()
+	require.NoError(t, prepareCache(cachePath))
+	directory := writeCacheTestTemplate(t)
+	local := filepath.Join(directory, "local")
+	require.NoError(t, os.MkdirAll(filepath.Join(local, "nested"), 0o700))
+	err := os.WriteFile(filepath.Join(local, "main.tf"), []byte(`module "consul" {
+		source  = "hashicorp/consul/aws"
+		version = "0.11.0"
+	}
+	module "nested" {
+		source = "./nested"
+	}`), 0o600)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(local, "nested", "main.tf"), []byte(`resource "google_compute_instance" "dev" {}`), 0o600)
+	require.NoError(t, err)
+
+	calls := remoteModuleCalls(directory)
+	require.Len(t, calls, 2)
+	require.Equal(t, "local.consul", calls[0].Name)
+	require.Equal(t, "vpc", calls[1].Name)
+	require.NotEqual(t, moduleCacheKey(calls), moduleCacheKey(remoteModuleCalls(writeCacheTestTemplate(t))))
+
+	// The providers of local modules are needed too.
+	missing, err := missingFromCache(cachePath, directory, calls, false)
+	require.NoError(t, err)
+	require.Contains(t, missing, `module "local.consul" (hashicorp/consul/aws 0.11.0)`)
+	require.Contains(t, missing, "provider registry.terraform.io/hashicorp/google for "+providerPlatform)
+}
+
+func TestModulesPinned(t *testing.T) {
+	t.Parallel()
+	for _, testCase := range []struct {
+		Source  string
+		Version string
+		Pinned  bool
+	}{
+		{"terraform-aws-modules/vpc/aws", "3.14.0", true},
+		{"terraform-aws-modules/vpc/aws", "= 3.14.0", true},
+		{"terraform-aws-modules/vpc/aws", "~> 3.14", false},
+		{"terraform-aws-modules/vpc/aws", "", false},
+		{"git::https://example.com/vpc.git?ref=v1.2.0", "", true},
+		{"github.com/coder/modules?ref=51c3c2b", "", true},
+		{"git::https://example.com/vpc.git?ref=main", "", false},
+		{"git::https://example.com/vpc.git", "", false},
+		{"https://example.com/vpc.zip", "", false},
+	} {
+		pinned := modulesPinned([]*tfconfig.ModuleCall{{
+			Name:    "test",
+			Source:  testCase.Source,
+			Version: testCase.Version,
+		}})
+		require.Equal(t, testCase.Pinned, pinned, "%s %s", testCase.Source, testCase.Version)
+	}
+}
+
+func TestLockCache(t *testing.T) {
+	t.Parallel()
+	cachePath := t.TempDir()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	unlock, err := lockCache(ctx, cachePath)
+	require.NoError(t, err)
+
+	// Another provisioner waits until the lock is released.
+	waitCtx, waitCancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer waitCancel()
+	_, err = lockCache(waitCtx, cachePath)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	unlock()
+	unlock, err = lockCache(ctx, cachePath)
+	require.NoError(t, err)
+	unlock()
+}
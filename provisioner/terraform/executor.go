@@ -26,17 +26,32 @@ type executor struct {
 	initMu     sync.Locker
 	binaryPath string
 	cachePath  string
-	workdir    string
+	offline    bool
+	// mirror downloads modules even if they're cached, and caches modules
+	// that aren't pinned, for offline provisioners.
+	mirror  bool
+	workdir string
 }
 
 func (e executor) basicEnv() []string {
 	// Required for "terraform init" to find "git" to
 	// clone Terraform modules.
 	env := os.Environ()
+	if e.cachePath == "" {
+		return env
+	}
+	if e.offline {
+		// Providers are only installed from the mirror, so
+		// Terraform never reaches out to a registry.
+		return append(env,
+			"TF_CLI_CONFIG_FILE="+filepath.Join(e.cachePath, offlineConfigFile),
+			"CHECKPOINT_DISABLE=1",
+		)
+	}
 	// Only Linux reliably works with the Terraform plugin
 	// cache directory. It's unknown why this is.
-	if e.cachePath != "" && runtime.GOOS == "linux" {
-		env = append(env, "TF_PLUGIN_CACHE_DIR="+e.cachePath)
+	if runtime.GOOS == "linux" {
+		env = append(env, "TF_PLUGIN_CACHE_DIR="+filepath.Join(e.cachePath, cacheProvidersDirectory))
 	}
 	return env
 }
@@ -156,6 +171,76 @@ func versionFromBinaryPath(ctx context.Context, binaryPath string) (*version.Ver
 }
 
 func (e executor) init(ctx, killCtx context.Context, logr logger) error {
+	if e.cachePath == "" {
+		return e.execInit(ctx, killCtx, logr)
+	}
+
+	// When cache path is set, we must protect against multiple calls
+	// to `terraform init`, including those of provisioners in other
+	// processes that share the cache.
+	//
+	// From the Terraform documentation:
+	//     Note: The plugin cache directory is not guaranteed to be
+	//     concurrency safe. The provider installer's behavior in
+	//     environments with multiple terraform init calls is undefined.
+	e.initMu.Lock()
+	defer e.initMu.Unlock()
+	unlock, err := lockCache(ctx, e.cachePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	calls := remoteModuleCalls(e.workdir)
+	key := ""
+	// Modules that aren't pinned can change upstream, so they're only
+	// cached for offline provisioners, which can't download them anyway.
+	if e.offline || e.mirror || modulesPinned(calls) {
+		key = moduleCacheKey(calls)
+	}
+	modulesCached := false
+	if key != "" && !e.mirror {
+		modulesCached, err = restoreModules(e.cachePath, key, e.workdir)
+		if err != nil {
+			return xerrors.Errorf("restore cached modules: %w", err)
+		}
+	}
+	if e.offline {
+		missing, err := missingFromCache(e.cachePath, e.workdir, calls, modulesCached)
+		if err != nil {
+			return xerrors.Errorf("check cache: %w", err)
+		}
+		if len(missing) > 0 {
+			for _, item := range missing {
+				_ = logr.Log(&proto.Log{
+					Level:  proto.LogLevel_ERROR,
+					Output: "missing from the offline cache: " + item,
+				})
+			}
+			return xerrors.Errorf("offline mode refuses to download %s. Populate the cache at %q with \"coder templates mirror\" on a machine with network access",
+				strings.Join(missing, ", "), e.cachePath)
+		}
+	}
+
+	err = e.execInit(ctx, killCtx, logr)
+	if err != nil {
+		return err
+	}
+	if key != "" && !modulesCached && !e.offline {
+		err = storeModules(e.cachePath, key, e.workdir)
+		if err != nil {
+			// Provisioning works without the cache, the modules are only
+			// downloaded again next time.
+			_ = logr.Log(&proto.Log{
+				Level:  proto.LogLevel_WARN,
+				Output: fmt.Sprintf("cache terraform modules: %s", err),
+			})
+		}
+	}
+	return nil
+}
+
+func (e executor) execInit(ctx, killCtx context.Context, logr logger) error {
 	outWriter, doneOut := logWriter(logr, proto.LogLevel_DEBUG)
 	errWriter, doneErr := logWriter(logr, proto.LogLevel_ERROR)
 	defer func() {
@@ -168,24 +253,11 @@ func (e executor) init(ctx, killCtx context.Context, logr logger) error {
 		"-no-color",
 		"-input=false",
 	}
-
-	// When cache path is set, we must protect against multiple calls
-	// to `terraform init`.
-	//
-	// From the Terraform documentation:
-	//     Note: The plugin cache directory is not guaranteed to be
-	//     concurrency safe. The provider installer's behavior in
-	//     environments with multiple terraform init calls is undefined.
-	if e.cachePath != "" {
-		e.initMu.Lock()
-		defer e.initMu.Unlock()
-	}
-
 	return e.execWriteOutput(ctx, killCtx, args, e.basicEnv(), outWriter, errWriter)
 }
 
 // revive:disable-next-line:flag-parameter
-func (e executor) plan(ctx, killCtx context.Context, env, vars []string, logr logger, destroy bool) (*proto.Provision_Response, error) {
+func (e executor) plan(ctx, killCtx context.Context, env, vars []string, logr logger, timings *timings, destroy bool) (*proto.Provision_Response, error) {
 	planfilePath := filepath.Join(e.workdir, "terraform.tfplan")
 	args := []string{
 		"plan",
@@ -202,7 +274,7 @@ func (e executor) plan(ctx, killCtx context.Context, env, vars []string, logr lo
 		args = append(args, "-var", variable)
 	}
 
-	outWriter, doneOut := provisionLogWriter(logr)
+	outWriter, doneOut := provisionLogWriter(logr, timings)
 	errWriter, doneErr := logWriter(logr, proto.LogLevel_ERROR)
 	defer func() {
 		<-doneOut
@@ -271,7 +343,7 @@ func (e executor) graph(ctx, killCtx context.Context) (string, error) {
 }
 
 // revive:disable-next-line:flag-parameter
-func (e executor) apply(ctx, killCtx context.Context, env, vars []string, logr logger, destroy bool,
+func (e executor) apply(ctx, killCtx context.Context, env, vars []string, logr logger, timings *timings, destroy bool,
 ) (*proto.Provision_Response, error) {
 	args := []string{
 		"apply",
@@ -288,7 +360,7 @@ func (e executor) apply(ctx, killCtx context.Context, env, vars []string, logr l
 		args = append(args, "-var", variable)
 	}
 
-	outWriter, doneOut := provisionLogWriter(logr)
+	outWriter, doneOut := provisionLogWriter(logr, timings)
 	errWriter, doneErr := logWriter(logr, proto.LogLevel_ERROR)
 	defer func() {
 		<-doneOut
@@ -402,17 +474,18 @@ func readAndLog(logr logger, r io.Reader, done chan<- any, level proto.LogLevel)
 	}
 }
 
-// provisionLogWriter creates a WriteCloser that will log each JSON formatted terraform log.  The WriteCloser must be
-// closed by the caller to end logging, after which the returned channel will be closed to indicate that logging of the
-// written data has finished.  Failure to close the WriteCloser will leak a goroutine.
-func provisionLogWriter(logr logger) (io.WriteCloser, <-chan any) {
+// provisionLogWriter creates a WriteCloser that will log each JSON formatted terraform log, and record how long
+// applying each resource took.  The WriteCloser must be closed by the caller to end logging, after which the returned
+// channel will be closed to indicate that logging of the written data has finished.  Failure to close the WriteCloser
+// will leak a goroutine.
+func provisionLogWriter(logr logger, timings *timings) (io.WriteCloser, <-chan any) {
 	r, w := io.Pipe()
 	done := make(chan any)
-	go provisionReadAndLog(logr, r, done)
+	go provisionReadAndLog(logr, timings, r, done)
 	return w, done
 }
 
-func provisionReadAndLog(logr logger, reader io.Reader, done chan<- any) {
+func provisionReadAndLog(logr logger, timings *timings, reader io.Reader, done chan<- any) {
 	defer close(done)
 	decoder := json.NewDecoder(reader)
 	for {
@@ -421,6 +494,7 @@ func provisionReadAndLog(logr logger, reader io.Reader, done chan<- any) {
 		if err != nil {
 			return
 		}
+		timings.ingest(log)
 		logLevel := convertTerraformLogLevel(log.Level, logr)
 
 		err = logr.Log(&proto.Log{Level: logLevel, Output: log.Message})
@@ -465,10 +539,22 @@ func convertTerraformLogLevel(logLevel string, logr logger) proto.LogLevel {
 }
 
 type terraformProvisionLog struct {
-	Level   string `json:"@level"`
-	Message string `json:"@message"`
+	Level     string `json:"@level"`
+	Message   string `json:"@message"`
+	Timestamp string `json:"@timestamp"`
+	Type      string `json:"type"`
 
 	Diagnostic *terraformProvisionLogDiagnostic `json:"diagnostic"`
+	Hook       *terraformProvisionLogHook       `json:"hook"`
+}
+
+// terraformProvisionLogHook is set on logs about applying a resource.
+type terraformProvisionLogHook struct {
+	Resource struct {
+		Addr string `json:"addr"`
+	} `json:"resource"`
+	Action         string  `json:"action"`
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
 }
 
 type terraformProvisionLogDiagnostic struct {
@@ -3,6 +3,7 @@ package terraform
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"golang.org/x/xerrors"
@@ -61,3 +62,45 @@ From standing in the English rain`))
 	expected := []*proto.Log{{Level: proto.LogLevel_INFO, Output: "Sitting in an English garden"}}
 	require.Equal(t, logr.logs, expected)
 }
+
+func TestProvisionLogWriter_Timings(t *testing.T) {
+	t.Parallel()
+
+	logr := &mockLogger{}
+	timings := newTimings("")
+	writer, doneLogging := provisionLogWriter(logr, timings)
+
+	_, err := writer.Write([]byte(`{"@level":"info","@message":"docker_image.main: Creating...","@timestamp":"2022-07-14T10:00:00.000000+02:00","hook":{"resource":{"addr":"docker_image.main"},"action":"create"},"type":"apply_start"}
+{"@level":"info","@message":"docker_container.workspace[0]: Creating...","@timestamp":"2022-07-14T10:00:01.000000+02:00","hook":{"resource":{"addr":"docker_container.workspace[0]"},"action":"create"},"type":"apply_start"}
+{"@level":"info","@message":"docker_image.main: Creation complete after 5s","@timestamp":"2022-07-14T10:00:05.500000+02:00","hook":{"resource":{"addr":"docker_image.main"},"action":"create","elapsed_seconds":5},"type":"apply_complete"}
+{"@level":"error","@message":"docker_container.workspace[0]: Creation errored after 2s","@timestamp":"2022-07-14T10:00:03.000000+02:00","hook":{"resource":{"addr":"docker_container.workspace[0]"},"action":"create","elapsed_seconds":2},"type":"apply_errored"}
+{"@level":"info","@message":"Apply complete!","@timestamp":"2022-07-14T10:00:06.000000+02:00","type":"change_summary"}
+`))
+	require.NoError(t, err)
+	err = writer.Close()
+	require.NoError(t, err)
+	<-doneLogging
+
+	require.Len(t, logr.logs, 5)
+	all := timings.all()
+	require.Len(t, all, 2)
+	require.Equal(t, "docker_image.main", all[0].Resource)
+	require.Equal(t, "create", all[0].Action)
+	require.Equal(t, "apply", all[0].Stage)
+	require.EqualValues(t, 5500, all[0].EndedAt-all[0].StartedAt)
+	require.Equal(t, "docker_container.workspace[0]", all[1].Resource)
+	require.EqualValues(t, 2000, all[1].EndedAt-all[1].StartedAt)
+}
+
+func TestTimings_Prefix(t *testing.T) {
+	t.Parallel()
+
+	timings := newTimings("start:")
+	timings.stage("init", time.Now())
+	timings.stage("plan", time.Now())
+
+	all := timings.all()
+	require.Len(t, all, 2)
+	require.Equal(t, "start:init", all[0].Stage)
+	require.Equal(t, "start:plan", all[1].Stage)
+}
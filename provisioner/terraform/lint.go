@@ -0,0 +1,452 @@
+package terraform
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/provisionersdk/proto"
+)
+
+// Lint statically validates the Terraform module in a directory. It finds
+// mistakes in a template that would otherwise only surface once the template
+// is imported, or a workspace is built from it.
+func Lint(directory string) ([]*proto.Diagnostic, error) {
+	module, diags := tfconfig.LoadModule(directory)
+	diagnostics := convertDiagnostics(directory, diags)
+	if diags.HasErrors() {
+		return diagnostics, nil
+	}
+	lint, err := lintModule(directory, module)
+	if err != nil {
+		return nil, err
+	}
+	return append(diagnostics, lint...), nil
+}
+
+// lintShells are shells that are able to run startup scripts written for a
+// POSIX shell.
+var lintShells = map[string]bool{
+	"sh":   true,
+	"ash":  true,
+	"bash": true,
+	"dash": true,
+	"ksh":  true,
+	"zsh":  true,
+}
+
+func lintModule(directory string, module *tfconfig.Module) ([]*proto.Diagnostic, error) {
+	diagnostics := make([]*proto.Diagnostic, 0)
+	for _, variable := range sortedVariables(module) {
+		if !variable.Required {
+			continue
+		}
+		diagnostics = append(diagnostics, &proto.Diagnostic{
+			Severity: proto.Diagnostic_WARNING,
+			Summary:  fmt.Sprintf("Variable %q has no default value", variable.Name),
+			Detail:   "A value must be provided for the variable whenever a version of the template is created.",
+			Filename: relativeFilename(directory, variable.Pos.Filename),
+			Line:     int32(variable.Pos.Line),
+		})
+	}
+
+	bodies, err := parseModuleBodies(directory)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		agents = map[string]*hclsyntax.Block{}
+		apps   = make([]*hclsyntax.Block, 0)
+		// Agents are attached to a resource when it refers to them,
+		// directly or through locals.
+		attached    = map[string]bool{}
+		localAgents = map[string]map[string]bool{}
+		localLocals = map[string]map[string]bool{}
+		usedLocals  = map[string]bool{}
+	)
+	for _, body := range bodies {
+		for _, block := range body.Blocks {
+			switch block.Type {
+			case "locals":
+				for name, attribute := range block.Body.Attributes {
+					localAgents[name], localLocals[name] = expressionReferences(attribute.Expr)
+				}
+			case "module":
+				referencedAgents, referencedLocals := bodyReferences(block.Body)
+				mergeReferences(attached, referencedAgents)
+				mergeReferences(usedLocals, referencedLocals)
+			case "resource":
+				if len(block.Labels) != 2 {
+					continue
+				}
+				switch block.Labels[0] {
+				case "coder_agent":
+					agents[block.Labels[1]] = block
+				case "coder_app":
+					apps = append(apps, block)
+				default:
+					if strings.HasPrefix(block.Labels[0], "coder_") {
+						continue
+					}
+					referencedAgents, referencedLocals := bodyReferences(block.Body)
+					mergeReferences(attached, referencedAgents)
+					mergeReferences(usedLocals, referencedLocals)
+				}
+			}
+		}
+	}
+	resolved := map[string]bool{}
+	var resolve func(name string)
+	resolve = func(name string) {
+		if resolved[name] {
+			return
+		}
+		resolved[name] = true
+		mergeReferences(attached, localAgents[name])
+		for local := range localLocals[name] {
+			resolve(local)
+		}
+	}
+	for local := range usedLocals {
+		resolve(local)
+	}
+
+	agentNames := make([]string, 0, len(agents))
+	for name := range agents {
+		agentNames = append(agentNames, name)
+	}
+	sort.Slice(agentNames, func(i, j int) bool {
+		return compareSourcePos(resourcePos(module, "coder_agent", agentNames[i]), resourcePos(module, "coder_agent", agentNames[j]))
+	})
+	for _, name := range agentNames {
+		pos := resourcePos(module, "coder_agent", name)
+		if !attached[name] {
+			diagnostics = append(diagnostics, &proto.Diagnostic{
+				Severity: proto.Diagnostic_WARNING,
+				Summary:  fmt.Sprintf("Agent %q isn't attached to a resource", name),
+				Detail:   "Use the token or init_script of the agent in the resource it should run on, such as a container or virtual machine. Otherwise the agent is never started.",
+				Filename: relativeFilename(directory, pos.Filename),
+				Line:     int32(pos.Line),
+			})
+		}
+		diagnostics = append(diagnostics, lintStartupScript(directory, name, agents[name])...)
+	}
+
+	// App names must be unique for each agent. Apps and agents with count
+	// or for_each are skipped, since they're commonly created conditionally
+	// and may never exist at the same time.
+	seenApps := map[string]tfconfig.SourcePos{}
+	for _, app := range apps {
+		if repeated(app) {
+			continue
+		}
+		name, ok := literalString(directory, app.Body, "name")
+		if !ok {
+			continue
+		}
+		attribute, ok := app.Body.Attributes["agent_id"]
+		if !ok {
+			continue
+		}
+		agent, ok := referencedAgent(attribute.Expr)
+		if !ok {
+			continue
+		}
+		if block, ok := agents[agent]; ok && repeated(block) {
+			continue
+		}
+		pos := resourcePos(module, "coder_app", app.Labels[1])
+		key := agent + "/" + name
+		if previous, exists := seenApps[key]; exists {
+			diagnostics = append(diagnostics, &proto.Diagnostic{
+				Severity: proto.Diagnostic_ERROR,
+				Summary:  fmt.Sprintf("Duplicate app name %q", name),
+				Detail: fmt.Sprintf("Agent %q already has an app named %q at %s:%d. App names must be unique for each agent.",
+					agent, name, relativeFilename(directory, previous.Filename), previous.Line),
+				Filename: relativeFilename(directory, pos.Filename),
+				Line:     int32(pos.Line),
+			})
+			continue
+		}
+		seenApps[key] = pos
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return compareSourcePos(
+			tfconfig.SourcePos{Filename: diagnostics[i].Filename, Line: int(diagnostics[i].Line)},
+			tfconfig.SourcePos{Filename: diagnostics[j].Filename, Line: int(diagnostics[j].Line)},
+		)
+	})
+	return diagnostics, nil
+}
+
+// repeated returns whether a resource uses count or for_each.
+func repeated(block *hclsyntax.Block) bool {
+	_, count := block.Body.Attributes["count"]
+	_, forEach := block.Body.Attributes["for_each"]
+	return count || forEach
+}
+
+// lintStartupScript validates the startup script of an agent. The agent runs
+// it with the login shell of the user, so it must be a shell script.
+func lintStartupScript(directory, agent string, block *hclsyntax.Block) []*proto.Diagnostic {
+	attribute, ok := block.Body.Attributes["startup_script"]
+	if !ok {
+		return nil
+	}
+	script, ok := literalString(directory, block.Body, "startup_script")
+	if !ok {
+		return nil
+	}
+	operatingSystem, _ := literalString(directory, block.Body, "os")
+	filename := relativeFilename(directory, attribute.SrcRange.Filename)
+	line := int32(attribute.SrcRange.Start.Line)
+
+	diagnostics := make([]*proto.Diagnostic, 0)
+	if operatingSystem != "windows" && strings.Contains(script, "\r") {
+		diagnostics = append(diagnostics, &proto.Diagnostic{
+			Severity: proto.Diagnostic_WARNING,
+			Summary:  fmt.Sprintf("Startup script of agent %q has Windows line endings", agent),
+			Detail:   "The shell treats carriage returns as part of each command, so the script fails. Save the script with Unix line endings.",
+			Filename: filename,
+			Line:     line,
+		})
+	}
+	if operatingSystem != "windows" && strings.HasPrefix(script, "#!") {
+		shebang := strings.TrimSpace(strings.SplitN(script, "\n", 2)[0])
+		fields := strings.Fields(strings.TrimPrefix(shebang, "#!"))
+		interpreter := ""
+		if len(fields) > 0 {
+			interpreter = path.Base(fields[0])
+			if interpreter == "env" && len(fields) > 1 {
+				interpreter = fields[1]
+			}
+		}
+		if !lintShells[interpreter] {
+			diagnostics = append(diagnostics, &proto.Diagnostic{
+				Severity: proto.Diagnostic_WARNING,
+				Summary:  fmt.Sprintf("Startup script of agent %q isn't a shell script", agent),
+				Detail: fmt.Sprintf("The agent runs the startup script with the login shell of the user, which ignores the %q shebang. "+
+					"Write the script for a POSIX shell, and run other interpreters from it.", shebang),
+				Filename: filename,
+				Line:     line,
+			})
+		}
+	}
+	return diagnostics
+}
+
+// parseModuleBodies parses the native syntax Terraform files of a module.
+// Syntax errors are already reported by tfconfig, so files that contain them
+// are skipped.
+func parseModuleBodies(directory string) ([]*hclsyntax.Body, error) {
+	filenames, err := filepath.Glob(filepath.Join(directory, "*.tf"))
+	if err != nil {
+		return nil, xerrors.Errorf("list files: %w", err)
+	}
+	sort.Strings(filenames)
+	bodies := make([]*hclsyntax.Body, 0, len(filenames))
+	for _, filename := range filenames {
+		if strings.HasSuffix(filename, "_override.tf") || filepath.Base(filename) == "override.tf" {
+			continue
+		}
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, xerrors.Errorf("read file %q: %w", filename, err)
+		}
+		file, diags := hclsyntax.ParseConfig(data, filename, hcl.InitialPos)
+		if diags.HasErrors() {
+			continue
+		}
+		body, ok := file.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		bodies = append(bodies, body)
+	}
+	return bodies, nil
+}
+
+// literalString evaluates an attribute that's a string known without
+// applying the template. Besides literals, file() of a path within the
+// module is supported, since that's a common way to write startup scripts.
+func literalString(directory string, body *hclsyntax.Body, name string) (string, bool) {
+	attribute, ok := body.Attributes[name]
+	if !ok {
+		return "", false
+	}
+	value, diags := attribute.Expr.Value(&hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"path": cty.ObjectVal(map[string]cty.Value{
+				"module": cty.StringVal(directory),
+				"root":   cty.StringVal(directory),
+			}),
+		},
+		Functions: map[string]function.Function{
+			"file": lintFileFunction(directory),
+		},
+	})
+	if diags.HasErrors() || value.IsNull() || !value.IsWhollyKnown() || !value.Type().Equals(cty.String) {
+		return "", false
+	}
+	return value.AsString(), true
+}
+
+// lintFileFunction is the file() function of Terraform, limited to files
+// within the module. Templates are linted on the machine that imports them, so
+// they mustn't be able to read other files of it.
+func lintFileFunction(directory string) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{Name: "path", Type: cty.String}},
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			name := args[0].AsString()
+			if !filepath.IsAbs(name) {
+				name = filepath.Join(directory, name)
+			}
+			inside, err := withinDirectory(directory, name)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			if !inside {
+				return cty.NilVal, xerrors.Errorf("file %q is outside of the module", args[0].AsString())
+			}
+			data, err := os.ReadFile(name)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return cty.StringVal(string(data)), nil
+		},
+	})
+}
+
+// withinDirectory returns whether a path resolves to a file within the
+// directory, following symbolic links.
+func withinDirectory(directory, name string) (bool, error) {
+	directory, err := filepath.Abs(directory)
+	if err != nil {
+		return false, err
+	}
+	directory, err = filepath.EvalSymlinks(directory)
+	if err != nil {
+		return false, err
+	}
+	name, err = filepath.EvalSymlinks(name)
+	if err != nil {
+		return false, err
+	}
+	name, err = filepath.Abs(name)
+	if err != nil {
+		return false, err
+	}
+	relative, err := filepath.Rel(directory, name)
+	if err != nil {
+		return false, nil
+	}
+	return relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator)), nil
+}
+
+// bodyReferences returns the agents and locals referred to by a body, including
+// its nested blocks.
+func bodyReferences(body *hclsyntax.Body) (map[string]bool, map[string]bool) {
+	agents, locals := map[string]bool{}, map[string]bool{}
+	for _, attribute := range body.Attributes {
+		referencedAgents, referencedLocals := expressionReferences(attribute.Expr)
+		mergeReferences(agents, referencedAgents)
+		mergeReferences(locals, referencedLocals)
+	}
+	for _, block := range body.Blocks {
+		referencedAgents, referencedLocals := bodyReferences(block.Body)
+		mergeReferences(agents, referencedAgents)
+		mergeReferences(locals, referencedLocals)
+	}
+	return agents, locals
+}
+
+// expressionReferences returns the agents and locals referred to by an
+// expression.
+func expressionReferences(expression hcl.Expression) (map[string]bool, map[string]bool) {
+	agents, locals := map[string]bool{}, map[string]bool{}
+	for _, traversal := range expression.Variables() {
+		if len(traversal) < 2 {
+			continue
+		}
+		attribute, ok := traversal[1].(hcl.TraverseAttr)
+		if !ok {
+			continue
+		}
+		switch traversal.RootName() {
+		case "coder_agent":
+			agents[attribute.Name] = true
+		case "local":
+			locals[attribute.Name] = true
+		}
+	}
+	return agents, locals
+}
+
+// referencedAgent returns the agent an expression refers to, if it refers to
+// exactly one.
+func referencedAgent(expression hcl.Expression) (string, bool) {
+	agents, _ := expressionReferences(expression)
+	if len(agents) != 1 {
+		return "", false
+	}
+	for agent := range agents {
+		return agent, true
+	}
+	return "", false
+}
+
+func mergeReferences(into, from map[string]bool) {
+	for name := range from {
+		into[name] = true
+	}
+}
+
+func resourcePos(module *tfconfig.Module, resourceType, name string) tfconfig.SourcePos {
+	resource, ok := module.ManagedResources[resourceType+"."+name]
+	if !ok {
+		return tfconfig.SourcePos{}
+	}
+	return resource.Pos
+}
+
+// convertDiagnostics converts diagnostics from loading a module.
+func convertDiagnostics(directory string, diags tfconfig.Diagnostics) []*proto.Diagnostic {
+	diagnostics := make([]*proto.Diagnostic, 0, len(diags))
+	for _, diag := range diags {
+		diagnostic := &proto.Diagnostic{
+			Severity: proto.Diagnostic_WARNING,
+			Summary:  diag.Summary,
+			Detail:   diag.Detail,
+		}
+		if diag.Severity == tfconfig.DiagError {
+			diagnostic.Severity = proto.Diagnostic_ERROR
+		}
+		if diag.Pos != nil {
+			diagnostic.Filename = relativeFilename(directory, diag.Pos.Filename)
+			diagnostic.Line = int32(diag.Pos.Line)
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+	return diagnostics
+}
+
+func relativeFilename(directory, filename string) string {
+	relative, err := filepath.Rel(directory, filename)
+	if err != nil {
+		return filename
+	}
+	return relative
+}
@@ -0,0 +1,214 @@
+package terraform_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/provisioner/terraform"
+	"github.com/coder/coder/provisionersdk/proto"
+)
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		Name        string
+		Files       map[string]string
+		Diagnostics []*proto.Diagnostic
+	}{{
+		Name: "Valid",
+		Files: map[string]string{
+			"main.tf": `variable "image" {
+				default = "ubuntu"
+			}
+			resource "coder_agent" "main" {
+				os             = "linux"
+				arch           = "amd64"
+				startup_script = "#!/usr/bin/env bash\ncode-server &"
+			}
+			resource "coder_app" "code" {
+				agent_id = coder_agent.main.id
+				name     = "code-server"
+			}
+			resource "docker_container" "workspace" {
+				image = var.image
+				env   = ["CODER_AGENT_TOKEN=${coder_agent.main.token}"]
+			}`,
+		},
+	}, {
+		Name: "AgentAttachedThroughLocal",
+		Files: map[string]string{
+			"main.tf": `resource "coder_agent" "main" {
+				os   = "linux"
+				arch = "amd64"
+			}
+			locals {
+				init   = coder_agent.main.init_script
+				script = local.init
+			}
+			resource "aws_instance" "dev" {
+				user_data = local.script
+			}`,
+		},
+	}, {
+		Name: "AgentNotAttached",
+		Files: map[string]string{
+			"main.tf": `resource "coder_agent" "main" {
+				os   = "linux"
+				arch = "amd64"
+			}
+			resource "docker_container" "workspace" {
+				image = "ubuntu"
+			}`,
+		},
+		Diagnostics: []*proto.Diagnostic{{
+			Severity: proto.Diagnostic_WARNING,
+			Summary:  `Agent "main" isn't attached to a resource`,
+			Filename: "main.tf",
+			Line:     1,
+		}},
+	}, {
+		Name: "DuplicateAppNames",
+		Files: map[string]string{
+			"main.tf": `resource "coder_agent" "main" {
+				os   = "linux"
+				arch = "amd64"
+			}
+			resource "docker_container" "workspace" {
+				env = [coder_agent.main.token]
+			}`,
+			"apps.tf": `resource "coder_app" "code" {
+				agent_id = coder_agent.main.id
+				name     = "code"
+			}
+			resource "coder_app" "vscode" {
+				agent_id = coder_agent.main.id
+				name     = "code"
+			}`,
+		},
+		Diagnostics: []*proto.Diagnostic{{
+			Severity: proto.Diagnostic_ERROR,
+			Summary:  `Duplicate app name "code"`,
+			Filename: "apps.tf",
+			Line:     5,
+		}},
+	}, {
+		Name: "ConditionalDuplicateAppNames",
+		Files: map[string]string{
+			"main.tf": `variable "insiders" {
+				default = false
+			}
+			resource "coder_agent" "main" {
+				os   = "linux"
+				arch = "amd64"
+			}
+			resource "docker_container" "workspace" {
+				env = [coder_agent.main.token]
+			}
+			resource "coder_app" "code" {
+				count    = var.insiders ? 0 : 1
+				agent_id = coder_agent.main.id
+				name     = "code"
+			}
+			resource "coder_app" "code_insiders" {
+				count    = var.insiders ? 1 : 0
+				agent_id = coder_agent.main.id
+				name     = "code"
+			}`,
+		},
+	}, {
+		Name: "StartupScriptOutsideModule",
+		Files: map[string]string{
+			"main.tf": `resource "coder_agent" "main" {
+				os             = "linux"
+				arch           = "amd64"
+				startup_script = file("${path.module}/../startup.sh")
+			}
+			resource "docker_container" "workspace" {
+				env = [coder_agent.main.token]
+			}`,
+			// Scripts outside of the module aren't read, so they aren't
+			// linted.
+			"../startup.sh": "#!/usr/bin/python3\r\nprint('hello')\r\n",
+		},
+	}, {
+		Name: "StartupScript",
+		Files: map[string]string{
+			"main.tf": `resource "coder_agent" "main" {
+				os             = "linux"
+				arch           = "amd64"
+				startup_script = file("${path.module}/startup.sh")
+			}
+			resource "docker_container" "workspace" {
+				env = [coder_agent.main.token]
+			}`,
+			"startup.sh": "#!/usr/bin/python3\r\nprint('hello')\r\n",
+		},
+		Diagnostics: []*proto.Diagnostic{{
+			Severity: proto.Diagnostic_WARNING,
+			Summary:  `Startup script of agent "main" has Windows line endings`,
+			Filename: "main.tf",
+			Line:     4,
+		}, {
+			Severity: proto.Diagnostic_WARNING,
+			Summary:  `Startup script of agent "main" isn't a shell script`,
+			Filename: "main.tf",
+			Line:     4,
+		}},
+	}, {
+		Name: "VariableWithoutDefault",
+		Files: map[string]string{
+			"main.tf": `variable "region" {
+				description = "Where to deploy workspaces"
+			}`,
+		},
+		Diagnostics: []*proto.Diagnostic{{
+			Severity: proto.Diagnostic_WARNING,
+			Summary:  `Variable "region" has no default value`,
+			Filename: "main.tf",
+			Line:     1,
+		}},
+	}, {
+		Name: "SyntaxError",
+		Files: map[string]string{
+			"main.tf": "a;sd;ajsd;lajsd;lasjdf;a",
+		},
+		Diagnostics: []*proto.Diagnostic{{
+			Severity: proto.Diagnostic_ERROR,
+			Summary:  "Invalid character",
+			Filename: "main.tf",
+			Line:     1,
+		}, {
+			Severity: proto.Diagnostic_ERROR,
+			Summary:  "Argument or block definition required",
+			Filename: "main.tf",
+			Line:     1,
+		}},
+	}}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.Name, func(t *testing.T) {
+			t.Parallel()
+			directory := t.TempDir()
+			for path, content := range testCase.Files {
+				err := os.WriteFile(filepath.Join(directory, path), []byte(content), 0o600)
+				require.NoError(t, err)
+			}
+
+			diagnostics, err := terraform.Lint(directory)
+			require.NoError(t, err)
+			require.Len(t, diagnostics, len(testCase.Diagnostics), "%v", diagnostics)
+			for i, expected := range testCase.Diagnostics {
+				// Details are meant for people, so they're not compared.
+				require.Equal(t, expected.Severity, diagnostics[i].Severity)
+				require.Equal(t, expected.Summary, diagnostics[i].Summary)
+				require.Equal(t, expected.Filename, diagnostics[i].Filename)
+				require.Equal(t, expected.Line, diagnostics[i].Line)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/provisionersdk/proto"
+)
+
+type MirrorOptions struct {
+	// BinaryPath specifies the "terraform" binary to use.
+	// If omitted, the $PATH will attempt to find it.
+	BinaryPath string
+	// CachePath is the cache directory of the provisioners that use
+	// the mirror.
+	CachePath string
+	// Directory is the template to mirror the providers and modules of.
+	Directory string
+	// Platforms to mirror providers for, such as "linux_amd64". Defaults
+	// to the platform of this machine.
+	Platforms []string
+	// Output receives the output of Terraform.
+	Output io.Writer
+}
+
+// Mirror downloads the providers and modules a template needs into the cache
+// of provisioners, so they can provision the template in offline mode.
+func Mirror(ctx context.Context, options MirrorOptions) error {
+	if options.CachePath == "" {
+		return xerrors.New("cache path is required")
+	}
+	if options.Output == nil {
+		options.Output = io.Discard
+	}
+	err := prepareCache(options.CachePath)
+	if err != nil {
+		return xerrors.Errorf("prepare cache: %w", err)
+	}
+	if options.BinaryPath == "" {
+		options.BinaryPath, err = installedBinaryPath(ctx, options.CachePath, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Terraform writes into the directory it initializes, so a copy of the
+	// template is mirrored to leave the template untouched.
+	workdir, err := os.MkdirTemp("", "coder-mirror")
+	if err != nil {
+		return xerrors.Errorf("create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(workdir)
+	err = copyDirectory(options.Directory, workdir, func(path string) bool {
+		return path == ".terraform"
+	})
+	if err != nil {
+		return xerrors.Errorf("copy template: %w", err)
+	}
+
+	logr := writerLogger{w: options.Output}
+	e := executor{
+		initMu:     &sync.Mutex{},
+		binaryPath: options.BinaryPath,
+		cachePath:  options.CachePath,
+		mirror:     true,
+		workdir:    workdir,
+	}
+	// Initializing stores the modules in the module cache.
+	err = e.init(ctx, ctx, logr)
+	if err != nil {
+		return xerrors.Errorf("initialize terraform: %w", err)
+	}
+
+	unlock, err := lockCache(ctx, options.CachePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	args := []string{"providers", "mirror"}
+	for _, platform := range options.Platforms {
+		args = append(args, "-platform="+platform)
+	}
+	args = append(args, filepath.Join(options.CachePath, cacheProvidersDirectory))
+	outWriter, doneOut := logWriter(logr, proto.LogLevel_DEBUG)
+	errWriter, doneErr := logWriter(logr, proto.LogLevel_ERROR)
+	defer func() {
+		<-doneOut
+		<-doneErr
+	}()
+	err = e.execWriteOutput(ctx, ctx, args, e.basicEnv(), outWriter, errWriter)
+	if err != nil {
+		return xerrors.Errorf("mirror providers: %w", err)
+	}
+	return nil
+}
+
+// writerLogger logs the output of Terraform to a writer.
+type writerLogger struct {
+	w io.Writer
+}
+
+func (l writerLogger) Log(log *proto.Log) error {
+	_, err := fmt.Fprintln(l.w, log.Output)
+	return err
+}
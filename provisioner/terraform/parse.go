@@ -23,15 +23,7 @@ func (*server) Parse(request *proto.Parse_Request, stream proto.DRPCProvisioner_
 		return xerrors.Errorf("load module: %s", formatDiagnostics(request.Directory, diags))
 	}
 
-	// Sort variables by (filename, line) to make the ordering consistent
-	variables := make([]*tfconfig.Variable, 0, len(module.Variables))
-	for _, v := range module.Variables {
-		variables = append(variables, v)
-	}
-	sort.Slice(variables, func(i, j int) bool {
-		return compareSourcePos(variables[i].Pos, variables[j].Pos)
-	})
-
+	variables := sortedVariables(module)
 	parameters := make([]*proto.ParameterSchema, 0, len(variables))
 	for _, v := range variables {
 		schema, err := convertVariableToParameter(v)
@@ -42,15 +34,34 @@ func (*server) Parse(request *proto.Parse_Request, stream proto.DRPCProvisioner_
 		parameters = append(parameters, schema)
 	}
 
+	diagnostics, err := lintModule(request.Directory, module)
+	if err != nil {
+		return xerrors.Errorf("lint module: %w", err)
+	}
+
 	return stream.Send(&proto.Parse_Response{
 		Type: &proto.Parse_Response_Complete{
 			Complete: &proto.Parse_Complete{
 				ParameterSchemas: parameters,
+				Diagnostics:      diagnostics,
 			},
 		},
 	})
 }
 
+// sortedVariables returns the variables of a module sorted by (filename, line)
+// to make the ordering consistent.
+func sortedVariables(module *tfconfig.Module) []*tfconfig.Variable {
+	variables := make([]*tfconfig.Variable, 0, len(module.Variables))
+	for _, v := range module.Variables {
+		variables = append(variables, v)
+	}
+	sort.Slice(variables, func(i, j int) bool {
+		return compareSourcePos(variables[i].Pos, variables[j].Pos)
+	})
+	return variables
+}
+
 // Converts a Terraform variable to a provisioner parameter.
 func convertVariableToParameter(variable *tfconfig.Variable) (*proto.ParameterSchema, error) {
 	schema := &proto.ParameterSchema{
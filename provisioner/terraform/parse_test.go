@@ -4,6 +4,7 @@ package terraform_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -45,6 +46,7 @@ func TestParse(t *testing.T) {
 								Scheme: proto.ParameterDestination_PROVISIONER_VARIABLE,
 							},
 						}},
+						Diagnostics: []*proto.Diagnostic{variableWithoutDefault("A", "main.tf", 1)},
 					},
 				},
 			},
@@ -97,6 +99,7 @@ func TestParse(t *testing.T) {
 								Scheme: proto.ParameterDestination_PROVISIONER_VARIABLE,
 							},
 						}},
+						Diagnostics: []*proto.Diagnostic{variableWithoutDefault("A", "main.tf", 1)},
 					},
 				},
 			},
@@ -157,6 +160,12 @@ func TestParse(t *testing.T) {
 								},
 							},
 						},
+						Diagnostics: []*proto.Diagnostic{
+							variableWithoutDefault("foo", "main1.tf", 1),
+							variableWithoutDefault("bar", "main1.tf", 2),
+							variableWithoutDefault("baz", "main2.tf", 1),
+							variableWithoutDefault("quux", "main2.tf", 2),
+						},
 					},
 				},
 			},
@@ -210,3 +219,13 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func variableWithoutDefault(name, filename string, line int32) *proto.Diagnostic {
+	return &proto.Diagnostic{
+		Severity: proto.Diagnostic_WARNING,
+		Summary:  fmt.Sprintf("Variable %q has no default value", name),
+		Detail:   "A value must be provided for the variable whenever a version of the template is created.",
+		Filename: filename,
+		Line:     line,
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"golang.org/x/xerrors"
 
+	"cdr.dev/slog"
 	"github.com/coder/coder/provisionersdk"
 	"github.com/coder/coder/provisionersdk/proto"
 )
@@ -110,14 +111,24 @@ func (s *server) Provision(stream proto.DRPCProvisioner_ProvisionStream) error {
 		})
 	}
 
+	// Template imports plan the start and stop transitions in one job, so the
+	// stages of dry runs are prefixed with their transition.
+	stagePrefix := ""
+	if start.DryRun {
+		stagePrefix = strings.ToLower(start.Metadata.WorkspaceTransition.String()) + ":"
+	}
+	timings := newTimings(stagePrefix)
 	s.logger.Debug(ctx, "running initialization")
+	initStartedAt := time.Now()
 	err = e.init(ctx, killCtx, logr)
+	timings.stage("init", initStartedAt)
 	if err != nil {
 		if ctx.Err() != nil {
 			return stream.Send(&proto.Provision_Response{
 				Type: &proto.Provision_Response_Complete{
 					Complete: &proto.Provision_Complete{
-						Error: err.Error(),
+						Error:   err.Error(),
+						Timings: timings.all(),
 					},
 				},
 			})
@@ -135,12 +146,15 @@ func (s *server) Provision(stream proto.DRPCProvisioner_ProvisionStream) error {
 		return err
 	}
 	var resp *proto.Provision_Response
+	stageStartedAt := time.Now()
 	if start.DryRun {
-		resp, err = e.plan(ctx, killCtx, env, vars, logr,
+		resp, err = e.plan(ctx, killCtx, env, vars, logr, timings,
 			start.Metadata.WorkspaceTransition == proto.WorkspaceTransition_DESTROY)
+		timings.stage("plan", stageStartedAt)
 	} else {
-		resp, err = e.apply(ctx, killCtx, env, vars, logr,
+		resp, err = e.apply(ctx, killCtx, env, vars, logr, timings,
 			start.Metadata.WorkspaceTransition == proto.WorkspaceTransition_DESTROY)
+		timings.stage("apply", stageStartedAt)
 	}
 	if err != nil {
 		if start.DryRun {
@@ -148,7 +162,8 @@ func (s *server) Provision(stream proto.DRPCProvisioner_ProvisionStream) error {
 				return stream.Send(&proto.Provision_Response{
 					Type: &proto.Provision_Response_Complete{
 						Complete: &proto.Provision_Complete{
-							Error: err.Error(),
+							Error:   err.Error(),
+							Timings: timings.all(),
 						},
 					},
 				})
@@ -162,13 +177,25 @@ func (s *server) Provision(stream proto.DRPCProvisioner_ProvisionStream) error {
 		return stream.Send(&proto.Provision_Response{
 			Type: &proto.Provision_Response_Complete{
 				Complete: &proto.Provision_Complete{
-					State: stateData,
-					Error: errorMessage,
+					State:   stateData,
+					Error:   errorMessage,
+					Timings: timings.all(),
 				},
 			},
 		})
 	}
 
+	// Problems found by linting don't fail the build, but are reported
+	// alongside its result.
+	if complete := resp.GetComplete(); complete != nil {
+		diagnostics, err := Lint(start.Directory)
+		if err != nil {
+			s.logger.Warn(ctx, "lint template", slog.Error(err))
+		}
+		complete.Diagnostics = diagnostics
+		complete.Timings = timings.all()
+	}
+
 	return stream.Send(resp)
 }
 
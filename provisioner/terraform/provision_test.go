@@ -28,6 +28,7 @@ import (
 type provisionerServeOptions struct {
 	binaryPath  string
 	exitTimeout time.Duration
+	offline     bool
 }
 
 func setupProvisioner(t *testing.T, opts *provisionerServeOptions) (context.Context, proto.DRPCProvisionerClient) {
@@ -54,6 +55,7 @@ func setupProvisioner(t *testing.T, opts *provisionerServeOptions) (context.Cont
 			CachePath:   cachePath,
 			Logger:      slogtest.Make(t, nil).Leveled(slog.LevelDebug),
 			ExitTimeout: opts.exitTimeout,
+			Offline:     opts.offline,
 		})
 	}()
 	api := proto.NewDRPCProvisionerClient(provisionersdk.Conn(client))
@@ -164,6 +166,58 @@ func TestProvision_Cancel(t *testing.T) {
 	}
 }
 
+func TestProvision_Offline(t *testing.T) {
+	t.Parallel()
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	fakeBin := filepath.Join(cwd, "testdata", "bin", "terraform_fake_cancel.sh")
+	dir := t.TempDir()
+	binPath := filepath.Join(dir, "terraform")
+	content := fmt.Sprintf("#!/bin/sh\nexec %q %s apply \"$@\"\n", fakeBin, terraform.TerraformVersion.String())
+	err = os.WriteFile(binPath, []byte(content), 0o755) //#nosec
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`terraform {
+		required_providers {
+			coder = {
+				source  = "coder/coder"
+				version = "0.4.9"
+			}
+		}
+	}`), 0o600)
+	require.NoError(t, err)
+
+	ctx, api := setupProvisioner(t, &provisionerServeOptions{
+		binaryPath: binPath,
+		offline:    true,
+	})
+	response, err := api.Provision(ctx)
+	require.NoError(t, err)
+	err = response.Send(&proto.Provision_Request{
+		Type: &proto.Provision_Request_Start{
+			Start: &proto.Provision_Start{
+				Directory: dir,
+				Metadata:  &proto.Provision_Metadata{},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	var gotLog []string
+	for {
+		msg, err := response.Recv()
+		if err != nil {
+			// Terraform never ran, since the provider would be downloaded.
+			require.ErrorContains(t, err, "offline mode refuses to download provider registry.terraform.io/coder/coder (0.4.9)")
+			break
+		}
+		if log := msg.GetLog(); log != nil {
+			gotLog = append(gotLog, log.Output)
+		}
+	}
+	require.Contains(t, gotLog, "missing from the offline cache: provider registry.terraform.io/coder/coder (0.4.9) for "+runtime.GOOS+"_"+runtime.GOARCH)
+}
+
 func TestProvision(t *testing.T) {
 	t.Parallel()
 
@@ -40,6 +40,11 @@ type ServeOptions struct {
 	CachePath  string
 	Logger     slog.Logger
 
+	// Offline refuses to download Terraform, providers and modules.
+	// They're installed from the provider mirror and module cache in
+	// CachePath instead, which Mirror populates.
+	Offline bool
+
 	// ExitTimeout defines how long we will wait for a running Terraform
 	// command to exit (cleanly) if the provision was stopped. This only
 	// happens when the command is still running after the provision
@@ -81,32 +86,52 @@ func absoluteBinaryPath(ctx context.Context) (string, error) {
 	return absoluteBinary, nil
 }
 
+// installedBinaryPath returns the "terraform" binary in $PATH, or installs
+// it into the cache path when it isn't found.
+func installedBinaryPath(ctx context.Context, cachePath string, offline bool) (string, error) {
+	absoluteBinary, err := absoluteBinaryPath(ctx)
+	if err == nil {
+		return absoluteBinary, nil
+	}
+	// This is an early exit to prevent extra execution in case the context is canceled.
+	// It generally happens in unit tests since this method is asynchronous and
+	// the unit test kills the app before this is complete.
+	if xerrors.Is(err, context.Canceled) {
+		return "", xerrors.Errorf("absolute binary context canceled: %w", err)
+	}
+	if offline {
+		return "", xerrors.Errorf("offline mode refuses to download Terraform %s: %w", TerraformVersion, err)
+	}
+
+	installer := &releases.ExactVersion{
+		InstallDir: cachePath,
+		Product:    product.Terraform,
+		Version:    TerraformVersion,
+	}
+	execPath, err := installer.Install(ctx)
+	if err != nil {
+		return "", xerrors.Errorf("install terraform: %w", err)
+	}
+	return execPath, nil
+}
+
 // Serve starts a dRPC server on the provided transport speaking Terraform provisioner.
 func Serve(ctx context.Context, options *ServeOptions) error {
+	if options.Offline && options.CachePath == "" {
+		return xerrors.New("offline mode requires a cache path to install providers and modules from")
+	}
+	if options.CachePath != "" {
+		err := prepareCache(options.CachePath)
+		if err != nil {
+			return xerrors.Errorf("prepare cache: %w", err)
+		}
+	}
 	if options.BinaryPath == "" {
-		absoluteBinary, err := absoluteBinaryPath(ctx)
+		binaryPath, err := installedBinaryPath(ctx, options.CachePath, options.Offline)
 		if err != nil {
-			// This is an early exit to prevent extra execution in case the context is canceled.
-			// It generally happens in unit tests since this method is asynchronous and
-			// the unit test kills the app before this is complete.
-			if xerrors.Is(err, context.Canceled) {
-				return xerrors.Errorf("absolute binary context canceled: %w", err)
-			}
-
-			installer := &releases.ExactVersion{
-				InstallDir: options.CachePath,
-				Product:    product.Terraform,
-				Version:    TerraformVersion,
-			}
-
-			execPath, err := installer.Install(ctx)
-			if err != nil {
-				return xerrors.Errorf("install terraform: %w", err)
-			}
-			options.BinaryPath = execPath
-		} else {
-			options.BinaryPath = absoluteBinary
+			return err
 		}
+		options.BinaryPath = binaryPath
 	}
 	if options.ExitTimeout == 0 {
 		options.ExitTimeout = defaultExitTimeout
@@ -114,6 +139,7 @@ func Serve(ctx context.Context, options *ServeOptions) error {
 	return provisionersdk.Serve(ctx, &server{
 		binaryPath:  options.BinaryPath,
 		cachePath:   options.CachePath,
+		offline:     options.Offline,
 		logger:      options.Logger,
 		exitTimeout: options.ExitTimeout,
 	}, options.ServeOptions)
@@ -126,6 +152,7 @@ type server struct {
 
 	binaryPath string
 	cachePath  string
+	offline    bool
 	logger     slog.Logger
 
 	exitTimeout time.Duration
@@ -136,6 +163,7 @@ func (s *server) executor(workdir string) executor {
 		initMu:     &s.initMu,
 		binaryPath: s.binaryPath,
 		cachePath:  s.cachePath,
+		offline:    s.offline,
 		workdir:    workdir,
 	}
 }
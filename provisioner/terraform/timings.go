@@ -0,0 +1,76 @@
+package terraform
+
+import (
+	"sync"
+	"time"
+
+	"github.com/coder/coder/provisionersdk/proto"
+)
+
+// timings collects how long each stage of provisioning took, and how long
+// applying each resource took. Resources are recorded from Terraform's JSON
+// logs while they're read, so it's safe for concurrent use.
+type timings struct {
+	mutex   sync.Mutex
+	prefix  string
+	applies map[string]time.Time
+	timings []*proto.Timing
+}
+
+// newTimings returns timings whose stages start with the prefix.
+func newTimings(prefix string) *timings {
+	return &timings{
+		prefix:  prefix,
+		applies: map[string]time.Time{},
+	}
+}
+
+// stage records a stage that started at the given time, and ended now.
+func (t *timings) stage(stage string, startedAt time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.timings = append(t.timings, &proto.Timing{
+		Stage:     t.prefix + stage,
+		StartedAt: startedAt.UnixMilli(),
+		EndedAt:   time.Now().UnixMilli(),
+	})
+}
+
+// ingest records the apply duration of a resource once Terraform logs that
+// applying it completed or errored.
+func (t *timings) ingest(log terraformProvisionLog) {
+	if log.Hook == nil || log.Hook.Resource.Addr == "" {
+		return
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, log.Timestamp)
+	if err != nil {
+		return
+	}
+	addr := log.Hook.Resource.Addr
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	switch log.Type {
+	case "apply_start":
+		t.applies[addr] = timestamp
+	case "apply_complete", "apply_errored":
+		startedAt, ok := t.applies[addr]
+		if !ok {
+			startedAt = timestamp.Add(-time.Duration(log.Hook.ElapsedSeconds * float64(time.Second)))
+		}
+		delete(t.applies, addr)
+		t.timings = append(t.timings, &proto.Timing{
+			Stage:     t.prefix + "apply",
+			Resource:  addr,
+			Action:    log.Hook.Action,
+			StartedAt: startedAt.UnixMilli(),
+			EndedAt:   timestamp.UnixMilli(),
+		})
+	}
+}
+
+func (t *timings) all() []*proto.Timing {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return append([]*proto.Timing{}, t.timings...)
+}
@@ -42,6 +42,7 @@ type DRPCProvisionerDaemonClient interface {
 	UpdateJob(ctx context.Context, in *UpdateJobRequest) (*UpdateJobResponse, error)
 	FailJob(ctx context.Context, in *FailedJob) (*Empty, error)
 	CompleteJob(ctx context.Context, in *CompletedJob) (*Empty, error)
+	ReadTemplateSource(ctx context.Context, in *TemplateSourceRequest) (DRPCProvisionerDaemon_ReadTemplateSourceClient, error)
 }
 
 type drpcProvisionerDaemonClient struct {
@@ -90,11 +91,48 @@ func (c *drpcProvisionerDaemonClient) CompleteJob(ctx context.Context, in *Compl
 	return out, nil
 }
 
+func (c *drpcProvisionerDaemonClient) ReadTemplateSource(ctx context.Context, in *TemplateSourceRequest) (DRPCProvisionerDaemon_ReadTemplateSourceClient, error) {
+	stream, err := c.cc.NewStream(ctx, "/provisionerd.ProvisionerDaemon/ReadTemplateSource", drpcEncoding_File_provisionerd_proto_provisionerd_proto{})
+	if err != nil {
+		return nil, err
+	}
+	x := &drpcProvisionerDaemon_ReadTemplateSourceClient{stream}
+	if err := x.MsgSend(in, drpcEncoding_File_provisionerd_proto_provisionerd_proto{}); err != nil {
+		return nil, err
+	}
+	if err := x.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DRPCProvisionerDaemon_ReadTemplateSourceClient interface {
+	drpc.Stream
+	Recv() (*TemplateSourceChunk, error)
+}
+
+type drpcProvisionerDaemon_ReadTemplateSourceClient struct {
+	drpc.Stream
+}
+
+func (x *drpcProvisionerDaemon_ReadTemplateSourceClient) Recv() (*TemplateSourceChunk, error) {
+	m := new(TemplateSourceChunk)
+	if err := x.MsgRecv(m, drpcEncoding_File_provisionerd_proto_provisionerd_proto{}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (x *drpcProvisionerDaemon_ReadTemplateSourceClient) RecvMsg(m *TemplateSourceChunk) error {
+	return x.MsgRecv(m, drpcEncoding_File_provisionerd_proto_provisionerd_proto{})
+}
+
 type DRPCProvisionerDaemonServer interface {
 	AcquireJob(context.Context, *Empty) (*AcquiredJob, error)
 	UpdateJob(context.Context, *UpdateJobRequest) (*UpdateJobResponse, error)
 	FailJob(context.Context, *FailedJob) (*Empty, error)
 	CompleteJob(context.Context, *CompletedJob) (*Empty, error)
+	ReadTemplateSource(*TemplateSourceRequest, DRPCProvisionerDaemon_ReadTemplateSourceStream) error
 }
 
 type DRPCProvisionerDaemonUnimplementedServer struct{}
@@ -115,9 +153,13 @@ func (s *DRPCProvisionerDaemonUnimplementedServer) CompleteJob(context.Context,
 	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
 }
 
+func (s *DRPCProvisionerDaemonUnimplementedServer) ReadTemplateSource(*TemplateSourceRequest, DRPCProvisionerDaemon_ReadTemplateSourceStream) error {
+	return drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
 type DRPCProvisionerDaemonDescription struct{}
 
-func (DRPCProvisionerDaemonDescription) NumMethods() int { return 4 }
+func (DRPCProvisionerDaemonDescription) NumMethods() int { return 5 }
 
 func (DRPCProvisionerDaemonDescription) Method(n int) (string, drpc.Encoding, drpc.Receiver, interface{}, bool) {
 	switch n {
@@ -157,6 +199,15 @@ func (DRPCProvisionerDaemonDescription) Method(n int) (string, drpc.Encoding, dr
 						in1.(*CompletedJob),
 					)
 			}, DRPCProvisionerDaemonServer.CompleteJob, true
+	case 4:
+		return "/provisionerd.ProvisionerDaemon/ReadTemplateSource", drpcEncoding_File_provisionerd_proto_provisionerd_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return nil, srv.(DRPCProvisionerDaemonServer).
+					ReadTemplateSource(
+						in1.(*TemplateSourceRequest),
+						&drpcProvisionerDaemon_ReadTemplateSourceStream{in2.(drpc.Stream)},
+					)
+			}, DRPCProvisionerDaemonServer.ReadTemplateSource, true
 	default:
 		return "", nil, nil, nil, false
 	}
@@ -229,3 +280,16 @@ func (x *drpcProvisionerDaemon_CompleteJobStream) SendAndClose(m *Empty) error {
 	}
 	return x.CloseSend()
 }
+
+type DRPCProvisionerDaemon_ReadTemplateSourceStream interface {
+	drpc.Stream
+	Send(*TemplateSourceChunk) error
+}
+
+type drpcProvisionerDaemon_ReadTemplateSourceStream struct {
+	drpc.Stream
+}
+
+func (x *drpcProvisionerDaemon_ReadTemplateSourceStream) Send(m *TemplateSourceChunk) error {
+	return x.MsgSend(m, drpcEncoding_File_provisionerd_proto_provisionerd_proto{})
+}
@@ -289,6 +289,17 @@ func (p *Server) CompleteJob(ctx context.Context, in *proto.CompletedJob) error
 	return err
 }
 
+func (p *Server) ReadTemplateSource(ctx context.Context, in *proto.TemplateSourceRequest) (proto.DRPCProvisionerDaemon_ReadTemplateSourceClient, error) {
+	out, err := p.clientDoWithRetries(ctx, func(ctx context.Context, client proto.DRPCProvisionerDaemonClient) (any, error) {
+		return client.ReadTemplateSource(ctx, in)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// nolint: forcetypeassert
+	return out.(proto.DRPCProvisionerDaemon_ReadTemplateSourceClient), nil
+}
+
 // isClosed returns whether the API is closed or not.
 func (p *Server) isClosed() bool {
 	select {
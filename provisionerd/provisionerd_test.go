@@ -331,6 +331,200 @@ func TestProvisionerd(t *testing.T) {
 		require.NoError(t, closer.Close())
 	})
 
+	t.Run("StreamedTemplateSource", func(t *testing.T) {
+		t.Parallel()
+		var (
+			didComplete   atomic.Bool
+			didAcquireJob atomic.Bool
+			completeChan  = make(chan struct{})
+			completeOnce  sync.Once
+		)
+
+		closer := createProvisionerd(t, func(ctx context.Context) (proto.DRPCProvisionerDaemonClient, error) {
+			return createProvisionerDaemonClient(t, provisionerDaemonTestServer{
+				acquireJob: func(ctx context.Context, _ *proto.Empty) (*proto.AcquiredJob, error) {
+					if !didAcquireJob.CAS(false, true) {
+						completeOnce.Do(func() { close(completeChan) })
+						return &proto.AcquiredJob{}, nil
+					}
+
+					return &proto.AcquiredJob{
+						JobId:       "test",
+						Provisioner: "someprovisioner",
+						Type: &proto.AcquiredJob_TemplateImport_{
+							TemplateImport: &proto.AcquiredJob_TemplateImport{
+								Metadata: &sdkproto.Provision_Metadata{},
+							},
+						},
+					}, nil
+				},
+				readTemplateSource: func(request *proto.TemplateSourceRequest, stream proto.DRPCProvisionerDaemon_ReadTemplateSourceStream) error {
+					assert.Equal(t, "test", request.JobId)
+					archive := createTar(t, map[string]string{
+						"test.txt": "content",
+					})
+					// The archive is split across chunks.
+					for len(archive) > 0 {
+						size := 100
+						if size > len(archive) {
+							size = len(archive)
+						}
+						err := stream.Send(&proto.TemplateSourceChunk{Data: archive[:size]})
+						if err != nil {
+							return err
+						}
+						archive = archive[size:]
+					}
+					return nil
+				},
+				updateJob: noopUpdateJob,
+				completeJob: func(ctx context.Context, job *proto.CompletedJob) (*proto.Empty, error) {
+					didComplete.Store(true)
+					return &proto.Empty{}, nil
+				},
+			}), nil
+		}, provisionerd.Provisioners{
+			"someprovisioner": createProvisionerClient(t, provisionerTestServer{
+				parse: func(request *sdkproto.Parse_Request, stream sdkproto.DRPCProvisioner_ParseStream) error {
+					data, err := os.ReadFile(filepath.Join(request.Directory, "test.txt"))
+					require.NoError(t, err)
+					require.Equal(t, "content", string(data))
+					return stream.Send(&sdkproto.Parse_Response{
+						Type: &sdkproto.Parse_Response_Complete{
+							Complete: &sdkproto.Parse_Complete{},
+						},
+					})
+				},
+				provision: func(stream sdkproto.DRPCProvisioner_ProvisionStream) error {
+					_, err := stream.Recv()
+					require.NoError(t, err)
+					return stream.Send(&sdkproto.Provision_Response{
+						Type: &sdkproto.Provision_Response_Complete{
+							Complete: &sdkproto.Provision_Complete{},
+						},
+					})
+				},
+			}),
+		})
+		require.Condition(t, closedWithin(completeChan, testutil.WaitShort))
+		require.True(t, didComplete.Load())
+		require.NoError(t, closer.Close())
+	})
+
+	t.Run("TemplateImportPresets", func(t *testing.T) {
+		t.Parallel()
+		for _, testCase := range []struct {
+			name    string
+			presets string
+			error   string
+		}{{
+			name: "Valid",
+			presets: `- name: small
+  description: A small workspace
+  parameters:
+    cpu: 2
+- name: large
+  parameters:
+    cpu: 8`,
+		}, {
+			name:    "UnknownParameter",
+			presets: "- name: small\n  parameters:\n    memory: 4",
+			error:   `preset "small" sets unknown parameter "memory"`,
+		}, {
+			name:    "Duplicate",
+			presets: "- name: small\n- name: small",
+			error:   `duplicate preset "small"`,
+		}} {
+			testCase := testCase
+			t.Run(testCase.name, func(t *testing.T) {
+				t.Parallel()
+				var (
+					completed     *proto.CompletedJob
+					failed        *proto.FailedJob
+					didAcquireJob atomic.Bool
+					completeChan  = make(chan struct{})
+					completeOnce  sync.Once
+				)
+				closer := createProvisionerd(t, func(ctx context.Context) (proto.DRPCProvisionerDaemonClient, error) {
+					return createProvisionerDaemonClient(t, provisionerDaemonTestServer{
+						acquireJob: func(ctx context.Context, _ *proto.Empty) (*proto.AcquiredJob, error) {
+							if !didAcquireJob.CAS(false, true) {
+								return &proto.AcquiredJob{}, nil
+							}
+							return &proto.AcquiredJob{
+								JobId:       "test",
+								Provisioner: "someprovisioner",
+								TemplateSourceArchive: createTar(t, map[string]string{
+									runner.PresetsFile: testCase.presets,
+								}),
+								Type: &proto.AcquiredJob_TemplateImport_{
+									TemplateImport: &proto.AcquiredJob_TemplateImport{
+										Metadata: &sdkproto.Provision_Metadata{},
+									},
+								},
+							}, nil
+						},
+						updateJob: func(ctx context.Context, update *proto.UpdateJobRequest) (*proto.UpdateJobResponse, error) {
+							values := make([]*sdkproto.ParameterValue, 0, len(update.ParameterSchemas))
+							for _, parameterSchema := range update.ParameterSchemas {
+								values = append(values, &sdkproto.ParameterValue{Name: parameterSchema.Name, Value: "1"})
+							}
+							return &proto.UpdateJobResponse{ParameterValues: values}, nil
+						},
+						completeJob: func(ctx context.Context, job *proto.CompletedJob) (*proto.Empty, error) {
+							completed = job
+							completeOnce.Do(func() { close(completeChan) })
+							return &proto.Empty{}, nil
+						},
+						failJob: func(ctx context.Context, job *proto.FailedJob) (*proto.Empty, error) {
+							failed = job
+							completeOnce.Do(func() { close(completeChan) })
+							return &proto.Empty{}, nil
+						},
+					}), nil
+				}, provisionerd.Provisioners{
+					"someprovisioner": createProvisionerClient(t, provisionerTestServer{
+						parse: func(request *sdkproto.Parse_Request, stream sdkproto.DRPCProvisioner_ParseStream) error {
+							return stream.Send(&sdkproto.Parse_Response{
+								Type: &sdkproto.Parse_Response_Complete{
+									Complete: &sdkproto.Parse_Complete{
+										ParameterSchemas: []*sdkproto.ParameterSchema{{Name: "cpu"}},
+									},
+								},
+							})
+						},
+						provision: func(stream sdkproto.DRPCProvisioner_ProvisionStream) error {
+							_, err := stream.Recv()
+							if err != nil {
+								return err
+							}
+							return stream.Send(&sdkproto.Provision_Response{
+								Type: &sdkproto.Provision_Response_Complete{
+									Complete: &sdkproto.Provision_Complete{},
+								},
+							})
+						},
+					}),
+				})
+				require.Condition(t, closedWithin(completeChan, testutil.WaitShort))
+				require.NoError(t, closer.Close())
+
+				if testCase.error != "" {
+					require.NotNil(t, failed)
+					require.Contains(t, failed.Error, testCase.error)
+					return
+				}
+				require.NotNil(t, completed)
+				presets := completed.GetTemplateImport().Presets
+				require.Len(t, presets, 2)
+				require.Equal(t, "small", presets[0].Name)
+				require.Equal(t, "A small workspace", presets[0].Description)
+				require.Equal(t, map[string]string{"cpu": "2"}, presets[0].Parameters)
+				require.Equal(t, "large", presets[1].Name)
+			})
+		}
+	})
+
 	t.Run("TemplateDryRun", func(t *testing.T) {
 		t.Parallel()
 		var (
@@ -481,6 +675,134 @@ func TestProvisionerd(t *testing.T) {
 		require.NoError(t, closer.Close())
 	})
 
+	t.Run("WorkspaceBuildTimings", func(t *testing.T) {
+		t.Parallel()
+		var (
+			mutex         sync.Mutex
+			stages        []string
+			didAcquireJob atomic.Bool
+			completeChan  = make(chan struct{})
+			completeOnce  sync.Once
+		)
+
+		closer := createProvisionerd(t, func(ctx context.Context) (proto.DRPCProvisionerDaemonClient, error) {
+			return createProvisionerDaemonClient(t, provisionerDaemonTestServer{
+				acquireJob: func(ctx context.Context, _ *proto.Empty) (*proto.AcquiredJob, error) {
+					if !didAcquireJob.CAS(false, true) {
+						completeOnce.Do(func() { close(completeChan) })
+						return &proto.AcquiredJob{}, nil
+					}
+
+					return &proto.AcquiredJob{
+						JobId:       "test",
+						Provisioner: "someprovisioner",
+						TemplateSourceArchive: createTar(t, map[string]string{
+							"test.txt": "content",
+						}),
+						Type: &proto.AcquiredJob_WorkspaceBuild_{
+							WorkspaceBuild: &proto.AcquiredJob_WorkspaceBuild{
+								Metadata: &sdkproto.Provision_Metadata{},
+							},
+						},
+					}, nil
+				},
+				updateJob: func(ctx context.Context, update *proto.UpdateJobRequest) (*proto.UpdateJobResponse, error) {
+					mutex.Lock()
+					defer mutex.Unlock()
+					for _, timing := range update.Timings {
+						stages = append(stages, timing.Stage+timing.Resource)
+					}
+					return &proto.UpdateJobResponse{}, nil
+				},
+				completeJob: func(ctx context.Context, job *proto.CompletedJob) (*proto.Empty, error) {
+					return &proto.Empty{}, nil
+				},
+			}), nil
+		}, provisionerd.Provisioners{
+			"someprovisioner": createProvisionerClient(t, provisionerTestServer{
+				provision: func(stream sdkproto.DRPCProvisioner_ProvisionStream) error {
+					return stream.Send(&sdkproto.Provision_Response{
+						Type: &sdkproto.Provision_Response_Complete{
+							Complete: &sdkproto.Provision_Complete{
+								Timings: []*sdkproto.Timing{
+									{Stage: "init"},
+									{Stage: "apply", Resource: "/example"},
+								},
+							},
+						},
+					})
+				},
+			}),
+		})
+		require.Condition(t, closedWithin(completeChan, testutil.WaitShort))
+		require.NoError(t, closer.Close())
+		mutex.Lock()
+		defer mutex.Unlock()
+		require.Equal(t, []string{"setup", "init", "apply/example"}, stages)
+	})
+
+	t.Run("WorkspaceBuildTimingsFail", func(t *testing.T) {
+		t.Parallel()
+		var (
+			didAcquireJob atomic.Bool
+			completeChan  = make(chan struct{})
+			completeOnce  sync.Once
+			state         atomic.Value
+		)
+
+		closer := createProvisionerd(t, func(ctx context.Context) (proto.DRPCProvisionerDaemonClient, error) {
+			return createProvisionerDaemonClient(t, provisionerDaemonTestServer{
+				acquireJob: func(ctx context.Context, _ *proto.Empty) (*proto.AcquiredJob, error) {
+					if !didAcquireJob.CAS(false, true) {
+						completeOnce.Do(func() { close(completeChan) })
+						return &proto.AcquiredJob{}, nil
+					}
+
+					return &proto.AcquiredJob{
+						JobId:       "test",
+						Provisioner: "someprovisioner",
+						TemplateSourceArchive: createTar(t, map[string]string{
+							"test.txt": "content",
+						}),
+						Type: &proto.AcquiredJob_WorkspaceBuild_{
+							WorkspaceBuild: &proto.AcquiredJob_WorkspaceBuild{
+								Metadata: &sdkproto.Provision_Metadata{},
+							},
+						},
+					}, nil
+				},
+				updateJob: func(ctx context.Context, update *proto.UpdateJobRequest) (*proto.UpdateJobResponse, error) {
+					if len(update.Timings) > 0 {
+						return nil, xerrors.New("timings unavailable")
+					}
+					return &proto.UpdateJobResponse{}, nil
+				},
+				completeJob: func(ctx context.Context, job *proto.CompletedJob) (*proto.Empty, error) {
+					state.Store(job.GetWorkspaceBuild().GetState())
+					return &proto.Empty{}, nil
+				},
+			}), nil
+		}, provisionerd.Provisioners{
+			"someprovisioner": createProvisionerClient(t, provisionerTestServer{
+				provision: func(stream sdkproto.DRPCProvisioner_ProvisionStream) error {
+					return stream.Send(&sdkproto.Provision_Response{
+						Type: &sdkproto.Provision_Response_Complete{
+							Complete: &sdkproto.Provision_Complete{
+								State:   []byte("state"),
+								Timings: []*sdkproto.Timing{{Stage: "apply"}},
+							},
+						},
+					})
+				},
+			}),
+		})
+		require.Condition(t, closedWithin(completeChan, testutil.WaitShort))
+		require.NoError(t, closer.Close())
+		// The job completes with its state even though the timings
+		// couldn't be sent.
+		require.Equal(t, []byte("state"), state.Load())
+	})
+
 	t.Run("WorkspaceBuildFailComplete", func(t *testing.T) {
 		t.Parallel()
 		var (
@@ -1030,6 +1352,8 @@ type provisionerDaemonTestServer struct {
 	updateJob   func(ctx context.Context, update *proto.UpdateJobRequest) (*proto.UpdateJobResponse, error)
 	failJob     func(ctx context.Context, job *proto.FailedJob) (*proto.Empty, error)
 	completeJob func(ctx context.Context, job *proto.CompletedJob) (*proto.Empty, error)
+
+	readTemplateSource func(request *proto.TemplateSourceRequest, stream proto.DRPCProvisionerDaemon_ReadTemplateSourceStream) error
 }
 
 func (p *provisionerDaemonTestServer) AcquireJob(ctx context.Context, empty *proto.Empty) (*proto.AcquiredJob, error) {
@@ -1047,3 +1371,7 @@ func (p *provisionerDaemonTestServer) FailJob(ctx context.Context, job *proto.Fa
 func (p *provisionerDaemonTestServer) CompleteJob(ctx context.Context, job *proto.CompletedJob) (*proto.Empty, error) {
 	return p.completeJob(ctx, job)
 }
+
+func (p *provisionerDaemonTestServer) ReadTemplateSource(request *proto.TemplateSourceRequest, stream proto.DRPCProvisionerDaemon_ReadTemplateSourceStream) error {
+	return p.readTemplateSource(request, stream)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/spf13/afero"
 	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
 
 	"cdr.dev/slog"
 
@@ -65,6 +66,7 @@ type JobUpdater interface {
 	UpdateJob(ctx context.Context, in *proto.UpdateJobRequest) (*proto.UpdateJobResponse, error)
 	FailJob(ctx context.Context, in *proto.FailedJob) error
 	CompleteJob(ctx context.Context, in *proto.CompletedJob) error
+	ReadTemplateSource(ctx context.Context, in *proto.TemplateSourceRequest) (proto.DRPCProvisionerDaemon_ReadTemplateSourceClient, error)
 }
 
 func NewRunner(
@@ -277,6 +279,7 @@ func (r *Runner) doCleanFinish() {
 
 // do actually does the work of running the job
 func (r *Runner) do() (*proto.CompletedJob, *proto.FailedJob) {
+	setupStartedAt := time.Now()
 	err := r.filesystem.MkdirAll(r.workDirectory, 0700)
 	if err != nil {
 		return nil, r.failedJobf("create work directory %q: %s", r.workDirectory, err)
@@ -295,9 +298,19 @@ func (r *Runner) do() (*proto.CompletedJob, *proto.FailedJob) {
 		return nil, r.failedJobf("write log: %s", err)
 	}
 
-	r.logger.Info(r.notStopped, "unpacking template source archive",
-		slog.F("size_bytes", len(r.job.TemplateSourceArchive)))
-	reader := tar.NewReader(bytes.NewBuffer(r.job.TemplateSourceArchive))
+	var archive io.Reader = bytes.NewReader(r.job.TemplateSourceArchive)
+	if len(r.job.TemplateSourceArchive) == 0 {
+		stream, err := r.sender.ReadTemplateSource(r.notStopped, &proto.TemplateSourceRequest{
+			JobId: r.job.JobId,
+		})
+		if err != nil {
+			return nil, r.failedJobf("read template source archive: %s", err)
+		}
+		defer stream.Close()
+		archive = &templateSourceReader{stream: stream}
+	}
+	r.logger.Info(r.notStopped, "unpacking template source archive")
+	reader := tar.NewReader(archive)
 	for {
 		header, err := reader.Next()
 		if errors.Is(err, io.EOF) {
@@ -348,6 +361,16 @@ func (r *Runner) do() (*proto.CompletedJob, *proto.FailedJob) {
 		}
 	}
 
+	err = r.sendTimings(&sdkproto.Timing{
+		Stage:     "setup",
+		StartedAt: setupStartedAt.UnixMilli(),
+		EndedAt:   time.Now().UnixMilli(),
+	})
+	if err != nil {
+		// Timings are informational, so the job doesn't fail without them.
+		r.logger.Warn(context.Background(), "send timings", slog.Error(err))
+	}
+
 	switch jobType := r.job.Type.(type) {
 	case *proto.AcquiredJob_TemplateImport_:
 		r.logger.Debug(context.Background(), "acquired job is template import")
@@ -376,6 +399,19 @@ func (r *Runner) do() (*proto.CompletedJob, *proto.FailedJob) {
 	}
 }
 
+// sendTimings reports how long stages of the job took, so they're stored
+// while the job is still running.
+func (r *Runner) sendTimings(timings ...*sdkproto.Timing) error {
+	if len(timings) == 0 {
+		return nil
+	}
+	_, err := r.update(r.notStopped, &proto.UpdateJobRequest{
+		JobId:   r.job.JobId,
+		Timings: timings,
+	})
+	return err
+}
+
 // heartbeat periodically sends updates on the job, which keeps coder server from assuming the job
 // is stalled, and allows the runner to learn if the job has been canceled by the user.
 func (r *Runner) heartbeat() {
@@ -462,6 +498,74 @@ func (r *Runner) runReadmeParse() *proto.FailedJob {
 	return nil
 }
 
+// PresetsFile is the location we look for parameter presets defined by the
+// template author. It's a YAML list of presets, each with a name, an optional
+// description and the parameter values it sets.
+const PresetsFile = "presets.yaml"
+
+type templatePreset struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Parameters  map[string]string `yaml:"parameters"`
+}
+
+// runTemplateImportPresets reads the parameter presets of the template. Presets
+// may only set parameters the template has.
+func (r *Runner) runTemplateImportPresets(parameterSchemas []*sdkproto.ParameterSchema) ([]*proto.TemplatePreset, *proto.FailedJob) {
+	data, err := afero.ReadFile(r.filesystem, path.Join(r.workDirectory, PresetsFile))
+	if err != nil {
+		return nil, nil
+	}
+	var presets []templatePreset
+	err = yaml.Unmarshal(data, &presets)
+	if err != nil {
+		return nil, r.failedJobf("parse %s: %s", PresetsFile, err)
+	}
+
+	protoPresets := make([]*proto.TemplatePreset, 0, len(presets))
+	for _, preset := range presets {
+		if preset.Name == "" {
+			return nil, r.failedJobf("parse %s: presets must have a name", PresetsFile)
+		}
+		for _, existing := range protoPresets {
+			if existing.Name == preset.Name {
+				return nil, r.failedJobf("parse %s: duplicate preset %q", PresetsFile, preset.Name)
+			}
+		}
+		for name := range preset.Parameters {
+			found := false
+			for _, parameterSchema := range parameterSchemas {
+				if parameterSchema.Name == name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, r.failedJobf("parse %s: preset %q sets unknown parameter %q", PresetsFile, preset.Name, name)
+			}
+		}
+		protoPresets = append(protoPresets, &proto.TemplatePreset{
+			Name:        preset.Name,
+			Description: preset.Description,
+			Parameters:  preset.Parameters,
+		})
+	}
+
+	_, err = r.update(r.notStopped, &proto.UpdateJobRequest{
+		JobId: r.job.JobId,
+		Logs: []*proto.Log{{
+			Source:    proto.LogSource_PROVISIONER_DAEMON,
+			Level:     sdkproto.LogLevel_INFO,
+			Stage:     fmt.Sprintf("Adding %d parameter presets", len(protoPresets)),
+			CreatedAt: time.Now().UTC().UnixMilli(),
+		}},
+	})
+	if err != nil {
+		return nil, r.failedJobf("write log: %s", err)
+	}
+	return protoPresets, nil
+}
+
 func (r *Runner) runTemplateImport() (*proto.CompletedJob, *proto.FailedJob) {
 	// Parse parameters and update the job with the parameter specs
 	_, err := r.update(r.notStopped, &proto.UpdateJobRequest{
@@ -476,10 +580,27 @@ func (r *Runner) runTemplateImport() (*proto.CompletedJob, *proto.FailedJob) {
 	if err != nil {
 		return nil, r.failedJobf("write log: %s", err)
 	}
-	parameterSchemas, err := r.runTemplateImportParse()
+	parameterSchemas, diagnostics, err := r.runTemplateImportParse()
 	if err != nil {
 		return nil, r.failedJobf("run parse: %s", err)
 	}
+	err = r.logDiagnostics("Parse parameters", diagnostics)
+	if err != nil {
+		return nil, r.failedJobf("write log: %s", err)
+	}
+	// Errors found by validating the template would make the import fail
+	// anyway, so fail before provisioning anything.
+	if errorCount := countDiagnosticErrors(diagnostics); errorCount > 0 {
+		return nil, &proto.FailedJob{
+			JobId: r.job.JobId,
+			Error: fmt.Sprintf("template has %d validation errors", errorCount),
+			Type: &proto.FailedJob_TemplateImport_{
+				TemplateImport: &proto.FailedJob_TemplateImport{
+					Diagnostics: diagnostics,
+				},
+			},
+		}
+	}
 	updateResponse, err := r.update(r.notStopped, &proto.UpdateJobRequest{
 		JobId:            r.job.JobId,
 		ParameterSchemas: parameterSchemas,
@@ -499,6 +620,11 @@ func (r *Runner) runTemplateImport() (*proto.CompletedJob, *proto.FailedJob) {
 		}
 	}
 
+	presets, failedJob := r.runTemplateImportPresets(parameterSchemas)
+	if failedJob != nil {
+		return nil, failedJob
+	}
+
 	// Determine persistent resources
 	_, err = r.update(r.notStopped, &proto.UpdateJobRequest{
 		JobId: r.job.JobId,
@@ -547,24 +673,26 @@ func (r *Runner) runTemplateImport() (*proto.CompletedJob, *proto.FailedJob) {
 			TemplateImport: &proto.CompletedJob_TemplateImport{
 				StartResources: startResources,
 				StopResources:  stopResources,
+				Diagnostics:    diagnostics,
+				Presets:        presets,
 			},
 		},
 	}, nil
 }
 
-// Parses parameter schemas from source.
-func (r *Runner) runTemplateImportParse() ([]*sdkproto.ParameterSchema, error) {
+// Parses parameter schemas from source, and validates it.
+func (r *Runner) runTemplateImportParse() ([]*sdkproto.ParameterSchema, []*sdkproto.Diagnostic, error) {
 	stream, err := r.provisioner.Parse(r.notStopped, &sdkproto.Parse_Request{
 		Directory: r.workDirectory,
 	})
 	if err != nil {
-		return nil, xerrors.Errorf("parse source: %w", err)
+		return nil, nil, xerrors.Errorf("parse source: %w", err)
 	}
 	defer stream.Close()
 	for {
 		msg, err := stream.Recv()
 		if err != nil {
-			return nil, xerrors.Errorf("recv parse source: %w", err)
+			return nil, nil, xerrors.Errorf("recv parse source: %w", err)
 		}
 		switch msgType := msg.Type.(type) {
 		case *sdkproto.Parse_Response_Log:
@@ -584,20 +712,65 @@ func (r *Runner) runTemplateImportParse() ([]*sdkproto.ParameterSchema, error) {
 				}},
 			})
 			if err != nil {
-				return nil, xerrors.Errorf("update job: %w", err)
+				return nil, nil, xerrors.Errorf("update job: %w", err)
 			}
 		case *sdkproto.Parse_Response_Complete:
 			r.logger.Info(context.Background(), "parse complete",
-				slog.F("parameter_schemas", msgType.Complete.ParameterSchemas))
+				slog.F("parameter_schemas", msgType.Complete.ParameterSchemas),
+				slog.F("diagnostics", msgType.Complete.Diagnostics))
 
-			return msgType.Complete.ParameterSchemas, nil
+			return msgType.Complete.ParameterSchemas, msgType.Complete.Diagnostics, nil
 		default:
-			return nil, xerrors.Errorf("invalid message type %q received from provisioner",
+			return nil, nil, xerrors.Errorf("invalid message type %q received from provisioner",
 				reflect.TypeOf(msg.Type).String())
 		}
 	}
 }
 
+// logDiagnostics writes diagnostics to the job logs, so they're shown along
+// with the output of the job.
+func (r *Runner) logDiagnostics(stage string, diagnostics []*sdkproto.Diagnostic) error {
+	if len(diagnostics) == 0 {
+		return nil
+	}
+	logs := make([]*proto.Log, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		level := sdkproto.LogLevel_WARN
+		if diagnostic.Severity == sdkproto.Diagnostic_ERROR {
+			level = sdkproto.LogLevel_ERROR
+		}
+		output := diagnostic.Summary
+		if diagnostic.Filename != "" {
+			output = fmt.Sprintf("%s:%d: %s", diagnostic.Filename, diagnostic.Line, output)
+		}
+		if diagnostic.Detail != "" {
+			output += ": " + diagnostic.Detail
+		}
+		logs = append(logs, &proto.Log{
+			Source:    proto.LogSource_PROVISIONER,
+			Level:     level,
+			CreatedAt: time.Now().UTC().UnixMilli(),
+			Output:    output,
+			Stage:     stage,
+		})
+	}
+	_, err := r.update(r.notStopped, &proto.UpdateJobRequest{
+		JobId: r.job.JobId,
+		Logs:  logs,
+	})
+	return err
+}
+
+func countDiagnosticErrors(diagnostics []*sdkproto.Diagnostic) int {
+	count := 0
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == sdkproto.Diagnostic_ERROR {
+			count++
+		}
+	}
+	return count
+}
+
 // Performs a dry-run provision when importing a template.
 // This is used to detect resources that would be provisioned
 // for a workspace in various states.
@@ -667,6 +840,12 @@ func (r *Runner) runTemplateImportProvision(values []*sdkproto.ParameterValue, m
 				return nil, xerrors.Errorf("send job update: %w", err)
 			}
 		case *sdkproto.Provision_Response_Complete:
+			// Timings are informational, so the import doesn't fail
+			// without them.
+			err = r.sendTimings(msgType.Complete.Timings...)
+			if err != nil {
+				r.logger.Warn(context.Background(), "send timings", slog.Error(err))
+			}
 			if msgType.Complete.Error != "" {
 				r.logger.Info(context.Background(), "dry-run provision failure",
 					slog.F("error", msgType.Complete.Error),
@@ -823,6 +1002,12 @@ func (r *Runner) runWorkspaceBuild() (
 				return nil, r.failedJobf("send job update: %s", err)
 			}
 		case *sdkproto.Provision_Response_Complete:
+			// The state must be stored even if the timings couldn't be,
+			// otherwise the resources it tracks are orphaned.
+			err = r.sendTimings(msgType.Complete.Timings...)
+			if err != nil {
+				r.logger.Warn(context.Background(), "send timings", slog.Error(err))
+			}
 			if msgType.Complete.Error != "" {
 				r.logger.Info(context.Background(), "provision failed; updating state",
 					slog.F("state_length", len(msgType.Complete.State)),
@@ -867,3 +1052,23 @@ func (r *Runner) failedJobf(format string, args ...interface{}) *proto.FailedJob
 		Error: fmt.Sprintf(format, args...),
 	}
 }
+
+// templateSourceReader reads a template source archive from the chunks it's
+// streamed in.
+type templateSourceReader struct {
+	stream proto.DRPCProvisionerDaemon_ReadTemplateSourceClient
+	chunk  []byte
+}
+
+func (t *templateSourceReader) Read(p []byte) (int, error) {
+	for len(t.chunk) == 0 {
+		chunk, err := t.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		t.chunk = chunk.Data
+	}
+	n := copy(p, t.chunk)
+	t.chunk = t.chunk[n:]
+	return n, nil
+}
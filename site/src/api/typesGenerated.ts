@@ -39,6 +39,7 @@ export interface AuthMethods {
   readonly password: boolean
   readonly github: boolean
   readonly oidc: boolean
+  readonly oidc_providers: OIDCAuthMethod[]
 }
 
 // From codersdk/workspaceagents.go
@@ -108,10 +109,29 @@ export interface CreateTemplateVersionDryRunRequest {
 // From codersdk/organizations.go
 export interface CreateTemplateVersionRequest {
   readonly template_id?: string
+  readonly detached?: boolean
   readonly storage_method: ProvisionerStorageMethod
   readonly storage_source: string
   readonly provisioner: ProvisionerType
   readonly parameter_values?: CreateParameterRequest[]
+  readonly message?: string
+  readonly git?: TemplateVersionGitMetadata
+  readonly variables?: CreateTemplateVersionVariable[]
+}
+
+// From codersdk/organizations.go
+export interface CreateTemplateVersionVariable {
+  readonly name: string
+  readonly value: string
+  readonly sensitive: boolean
+}
+
+// From codersdk/terminalshares.go
+export interface CreateTerminalShareRequest {
+  readonly reconnect_id: string
+  readonly mode: TerminalShareMode
+  readonly recipients: string[]
+  readonly ttl_ms?: number
 }
 
 // From codersdk/users.go
@@ -138,6 +158,7 @@ export interface CreateWorkspaceRequest {
   readonly autostart_schedule?: string
   readonly ttl_ms?: number
   readonly parameter_values?: CreateParameterRequest[]
+  readonly preset?: string
 }
 
 // From codersdk/features.go
@@ -192,6 +213,13 @@ export interface LoginWithPasswordResponse {
   readonly session_token: string
 }
 
+// From codersdk/users.go
+export interface OIDCAuthMethod {
+  readonly name: string
+  readonly display_name: string
+  readonly icon_url: string
+}
+
 // From codersdk/organizations.go
 export interface Organization {
   readonly id: string
@@ -209,6 +237,17 @@ export interface OrganizationMember {
   readonly roles: Role[]
 }
 
+// From codersdk/organizationmember.go
+export interface OrganizationMemberWithUser {
+  readonly user_id: string
+  readonly organization_id: string
+  readonly username: string
+  readonly email: string
+  readonly created_at: string
+  readonly updated_at: string
+  readonly roles: Role[]
+}
+
 // From codersdk/pagination.go
 export interface Pagination {
   readonly after_id?: string
@@ -249,6 +288,11 @@ export interface ParameterSchema {
   readonly validation_contains?: string[]
 }
 
+// From codersdk/workspaceagents.go
+export interface PostWorkspaceAgentHealthRequest {
+  readonly checks: WorkspaceAgentHealthCheck[]
+}
+
 // From codersdk/provisionerdaemons.go
 export interface ProvisionerDaemon {
   readonly id: string
@@ -280,6 +324,15 @@ export interface ProvisionerJobLog {
   readonly output: string
 }
 
+// From codersdk/provisionerdaemons.go
+export interface ProvisionerTiming {
+  readonly stage: string
+  readonly resource?: string
+  readonly action?: string
+  readonly started_at: string
+  readonly ended_at: string
+}
+
 // From codersdk/workspaces.go
 export interface PutExtendWorkspaceRequest {
   readonly deadline: string
@@ -314,6 +367,64 @@ export interface Template {
   readonly min_autostart_interval_ms: number
   readonly created_by_id: string
   readonly created_by_name: string
+  readonly inactivity_ttl_ms: number
+  readonly dormant_autodelete_ttl_ms: number
+  readonly maintenance_window: string
+  readonly maintenance_window_ms: number
+  readonly maintenance_restart_outdated: boolean
+  readonly prebuild_count: number
+  readonly record_sessions: boolean
+}
+
+// From codersdk/templategitsources.go
+export interface TemplateGitSource {
+  readonly template_id: string
+  readonly repository_url: string
+  readonly ref: string
+  readonly subdirectory: string
+  readonly auto_promote: boolean
+  readonly commit_sha: string
+  readonly pending_version_id?: string
+  readonly synced_at?: string
+  readonly sync_error?: string
+}
+
+// From codersdk/templateinsights.go
+export interface TemplateInsights {
+  readonly template_id: string
+  readonly start_date: string
+  readonly end_date: string
+  readonly builds_total: number
+  readonly builds_succeeded: number
+  readonly build_success_rate: number
+  readonly build_duration_median_ms?: number
+  readonly agent_connect_median_ms?: number
+  readonly days: TemplateInsightsDay[]
+}
+
+// From codersdk/templateinsights.go
+export interface TemplateInsightsDay {
+  readonly date: string
+  readonly active_users: number
+  readonly builds_total: number
+  readonly builds_succeeded: number
+  readonly build_duration_median_ms?: number
+  readonly agent_connect_median_ms?: number
+  readonly app_usage: Record<string, number>
+}
+
+// From codersdk/templateinsights.go
+export interface TemplateInsightsRequest {
+  readonly start_date: string
+  readonly end_date: string
+}
+
+// From codersdk/prebuilds.go
+export interface TemplatePrebuilds {
+  readonly count: number
+  readonly parameter_values: CreateParameterRequest[]
+  readonly ready: number
+  readonly pending: number
 }
 
 // From codersdk/templateversions.go
@@ -328,6 +439,56 @@ export interface TemplateVersion {
   readonly readme: string
   readonly created_by_id: string
   readonly created_by_name: string
+  readonly message: string
+  readonly git?: TemplateVersionGitMetadata
+  readonly diagnostics: TemplateVersionDiagnostic[]
+}
+
+// From codersdk/templateversions.go
+export interface TemplateVersionDiagnostic {
+  readonly severity: TemplateVersionDiagnosticSeverity
+  readonly summary: string
+  readonly detail?: string
+  readonly filename?: string
+  readonly line?: number
+}
+
+// From codersdk/templateversions.go
+export interface TemplateVersionDiff {
+  readonly from: string
+  readonly to: string
+  readonly files: TemplateVersionFileDiff[]
+}
+
+// From codersdk/templateversions.go
+export interface TemplateVersionFileDiff {
+  readonly path: string
+  readonly status: TemplateVersionFileDiffStatus
+  readonly binary: boolean
+  readonly diff?: string
+}
+
+// From codersdk/templateversions.go
+export interface TemplateVersionGitMetadata {
+  readonly commit_sha: string
+  readonly branch?: string
+  readonly remote_url?: string
+  readonly author?: string
+  readonly dirty: boolean
+}
+
+// From codersdk/templateversions.go
+export interface TemplateVersionPreset {
+  readonly name: string
+  readonly description: string
+  readonly parameters: Record<string, string>
+}
+
+// From codersdk/templateversions.go
+export interface TemplateVersionVariable {
+  readonly name: string
+  readonly value: string
+  readonly sensitive: boolean
 }
 
 // From codersdk/templates.go
@@ -335,16 +496,50 @@ export interface TemplateVersionsByTemplateRequest extends Pagination {
   readonly template_id: string
 }
 
+// From codersdk/terminalshares.go
+export interface TerminalShare {
+  readonly id: string
+  readonly created_at: string
+  readonly workspace_id: string
+  readonly agent_id: string
+  readonly reconnect_id: string
+  readonly created_by: string
+  readonly mode: TerminalShareMode
+  readonly recipients: string[]
+  readonly expires_at: string
+  readonly viewers: TerminalShareViewer[]
+}
+
+// From codersdk/terminalshares.go
+export interface TerminalShareViewer {
+  readonly user_id: string
+  readonly username: string
+  readonly connected_at: string
+}
+
 // From codersdk/templates.go
 export interface UpdateActiveTemplateVersion {
   readonly id: string
 }
 
+// From codersdk/organizations.go
+export interface UpdateOrganizationRequest {
+  readonly name: string
+}
+
 // From codersdk/users.go
 export interface UpdateRoles {
   readonly roles: string[]
 }
 
+// From codersdk/templategitsources.go
+export interface UpdateTemplateGitSourceRequest {
+  readonly repository_url: string
+  readonly ref: string
+  readonly subdirectory?: string
+  readonly auto_promote: boolean
+}
+
 // From codersdk/templates.go
 export interface UpdateTemplateMeta {
   readonly name?: string
@@ -352,6 +547,23 @@ export interface UpdateTemplateMeta {
   readonly icon?: string
   readonly max_ttl_ms?: number
   readonly min_autostart_interval_ms?: number
+  readonly inactivity_ttl_ms?: number
+  readonly dormant_autodelete_ttl_ms?: number
+  readonly maintenance_window?: string
+  readonly maintenance_window_ms?: number
+  readonly maintenance_restart_outdated?: boolean
+  readonly record_sessions?: boolean
+}
+
+// From codersdk/templates.go
+export interface UpdateTemplateOrganizationRequest {
+  readonly organization_id: string
+}
+
+// From codersdk/prebuilds.go
+export interface UpdateTemplatePrebuildsRequest {
+  readonly count: number
+  readonly parameter_values?: CreateParameterRequest[]
 }
 
 // From codersdk/users.go
@@ -370,6 +582,11 @@ export interface UpdateWorkspaceAutostartRequest {
   readonly schedule?: string
 }
 
+// From codersdk/workspaces.go
+export interface UpdateWorkspaceDormancyRequest {
+  readonly dormant: boolean
+}
+
 // From codersdk/workspaces.go
 export interface UpdateWorkspaceRequest {
   readonly name?: string
@@ -450,6 +667,13 @@ export interface Workspace {
   readonly name: string
   readonly autostart_schedule?: string
   readonly ttl_ms?: number
+  readonly last_used_at: string
+  readonly dormant_at?: string
+  readonly deleting_at?: string
+  readonly becomes_dormant_at?: string
+  readonly prebuild: boolean
+  readonly preset?: string
+  readonly health: WorkspaceHealth
 }
 
 // From codersdk/workspaceresources.go
@@ -479,6 +703,9 @@ export interface WorkspaceAgent {
   // Named type "inet.af/netaddr.IPPrefix" unknown, using "any"
   // eslint-disable-next-line @typescript-eslint/no-explicit-any
   readonly ipv6: any
+  readonly version: string
+  readonly outdated: boolean
+  readonly health: WorkspaceAgentHealth
 }
 
 // From codersdk/workspaceagents.go
@@ -486,6 +713,22 @@ export interface WorkspaceAgentAuthenticateResponse {
   readonly session_token: string
 }
 
+// From codersdk/workspaceresources.go
+export interface WorkspaceAgentHealth {
+  readonly healthy: boolean
+  readonly checks: WorkspaceAgentHealthCheck[]
+}
+
+// From codersdk/workspaceresources.go
+export interface WorkspaceAgentHealthCheck {
+  readonly name: string
+  readonly healthy: boolean
+  readonly output: string
+  readonly checked_at: string
+  readonly interval_ms: number
+  readonly stale: boolean
+}
+
 // From codersdk/workspaceresources.go
 export interface WorkspaceAgentInstanceMetadata {
   readonly jail_orchestrator: string
@@ -499,6 +742,13 @@ export interface WorkspaceAgentInstanceMetadata {
   readonly vnc: boolean
 }
 
+// From codersdk/workspaceagentports.go
+export interface WorkspaceAgentListeningPort {
+  readonly process_name: string
+  readonly network: string
+  readonly port: number
+}
+
 // From codersdk/workspaceresources.go
 export interface WorkspaceAgentResourceMetadata {
   readonly memory_total: number
@@ -508,6 +758,22 @@ export interface WorkspaceAgentResourceMetadata {
   readonly cpu_mhz: number
 }
 
+// From codersdk/workspaceagentsessions.go
+export interface WorkspaceAgentSession {
+  readonly id: string
+  readonly type: WorkspaceAgentSessionType
+  readonly remote_user: string
+  readonly started_at: string
+  readonly rx_bytes: number
+  readonly tx_bytes: number
+  readonly command: string
+}
+
+// From codersdk/sessionrecordings.go
+export interface WorkspaceAgentSessionRecordingToken {
+  readonly token: string
+}
+
 // From codersdk/workspaceapps.go
 export interface WorkspaceApp {
   readonly id: string
@@ -546,6 +812,12 @@ export interface WorkspaceFilter {
   readonly q?: string
 }
 
+// From codersdk/workspaces.go
+export interface WorkspaceHealth {
+  readonly healthy: boolean
+  readonly failing_agents: string[]
+}
+
 // From codersdk/workspaces.go
 export interface WorkspaceOptions {
   readonly include_deleted?: boolean
@@ -570,8 +842,21 @@ export interface WorkspaceResourceMetadata {
   readonly sensitive: boolean
 }
 
+// From codersdk/sessionrecordings.go
+export interface WorkspaceSessionRecording {
+  readonly id: string
+  readonly workspace_id: string
+  readonly agent_id: string
+  readonly user_id: string
+  readonly username: string
+  readonly type: SessionRecordingType
+  readonly started_at: string
+  readonly ended_at: string
+  readonly size: number
+}
+
 // From codersdk/workspacebuilds.go
-export type BuildReason = "autostart" | "autostop" | "initiator"
+export type BuildReason = "autodelete" | "autostart" | "autostop" | "initiator" | "maintenance"
 
 // From codersdk/features.go
 export type Entitlement = "entitled" | "grace_period" | "not_entitled"
@@ -612,9 +897,24 @@ export type ProvisionerStorageMethod = "file"
 // From codersdk/organizations.go
 export type ProvisionerType = "echo" | "terraform"
 
+// From codersdk/sessionrecordings.go
+export type SessionRecordingType = "reconnecting_pty" | "ssh"
+
+// From codersdk/templateversions.go
+export type TemplateVersionDiagnosticSeverity = "error" | "warning"
+
+// From codersdk/templateversions.go
+export type TemplateVersionFileDiffStatus = "added" | "modified" | "removed"
+
+// From codersdk/terminalshares.go
+export type TerminalShareMode = "read_only" | "read_write"
+
 // From codersdk/users.go
 export type UserStatus = "active" | "suspended"
 
+// From codersdk/workspaceagentsessions.go
+export type WorkspaceAgentSessionType = "dial" | "reconnecting_pty" | "ssh"
+
 // From codersdk/workspaceresources.go
 export type WorkspaceAgentStatus = "connected" | "connecting" | "disconnected"
 
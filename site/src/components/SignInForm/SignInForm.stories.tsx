@@ -100,6 +100,7 @@ WithGithub.args = {
     password: true,
     github: true,
     oidc: false,
+    oidc_providers: [],
   },
 }
 
@@ -110,6 +111,7 @@ WithOIDC.args = {
     password: true,
     github: false,
     oidc: true,
+    oidc_providers: [],
   },
 }
 
@@ -120,5 +122,20 @@ WithGithubAndOIDC.args = {
     password: true,
     github: true,
     oidc: true,
+    oidc_providers: [],
+  },
+}
+
+export const WithOIDCProviders = Template.bind({})
+WithOIDCProviders.args = {
+  ...SignedOut.args,
+  authMethods: {
+    password: true,
+    github: false,
+    oidc: false,
+    oidc_providers: [
+      { name: "okta", display_name: "Okta", icon_url: "" },
+      { name: "azure", display_name: "Azure AD", icon_url: "" },
+    ],
   },
 }
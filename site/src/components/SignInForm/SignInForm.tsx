@@ -158,7 +158,9 @@ export const SignInForm: FC<React.PropsWithChildren<SignInFormProps>> = ({
           </div>
         </Stack>
       </form>
-      {(authMethods?.github || authMethods?.oidc) && (
+      {(authMethods?.github ||
+        authMethods?.oidc ||
+        (authMethods?.oidc_providers ?? []).length > 0) && (
         <>
           <div className={styles.divider}>
             <div className={styles.dividerLine} />
@@ -202,6 +204,36 @@ export const SignInForm: FC<React.PropsWithChildren<SignInFormProps>> = ({
                 </Button>
               </Link>
             )}
+
+            {authMethods.oidc_providers?.map((provider) => (
+              <Link
+                key={provider.name}
+                underline="none"
+                href={`/api/v2/users/oidc/${encodeURIComponent(
+                  provider.name,
+                )}/callback?redirect=${encodeURIComponent(redirectTo)}`}
+              >
+                <Button
+                  startIcon={
+                    provider.icon_url ? (
+                      <img
+                        alt=""
+                        src={provider.icon_url}
+                        className={styles.buttonIcon}
+                      />
+                    ) : (
+                      <KeyIcon className={styles.buttonIcon} />
+                    )
+                  }
+                  disabled={isLoading}
+                  fullWidth
+                  type="submit"
+                  variant="contained"
+                >
+                  {provider.display_name}
+                </Button>
+              </Link>
+            ))}
           </Box>
         </>
       )}
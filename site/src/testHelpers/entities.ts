@@ -153,6 +153,8 @@ You can add instructions here
 [Some link info](https://coder.com)`,
   created_by_id: "test-creator-id",
   created_by_name: "test_creator",
+  message: "",
+  diagnostics: [],
 }
 
 export const MockTemplate: TypesGen.Template = {
@@ -170,6 +172,13 @@ export const MockTemplate: TypesGen.Template = {
   created_by_id: "test-creator-id",
   created_by_name: "test_creator",
   icon: "/icon/code.svg",
+  inactivity_ttl_ms: 0,
+  dormant_autodelete_ttl_ms: 0,
+  maintenance_window: "",
+  maintenance_window_ms: 0,
+  maintenance_restart_outdated: false,
+  prebuild_count: 0,
+  record_sessions: false,
 }
 
 export const MockWorkspaceAutostartDisabled: TypesGen.UpdateWorkspaceAutostartRequest = {
@@ -229,6 +238,12 @@ export const MockWorkspace: TypesGen.Workspace = {
   autostart_schedule: MockWorkspaceAutostartEnabled.schedule,
   ttl_ms: 2 * 60 * 60 * 1000, // 2 hours as milliseconds
   latest_build: MockWorkspaceBuild,
+  last_used_at: "",
+  prebuild: false,
+  health: {
+    healthy: true,
+    failing_agents: [],
+  },
 }
 
 export const MockStoppedWorkspace: TypesGen.Workspace = {
@@ -305,6 +320,12 @@ export const MockWorkspaceAgent: TypesGen.WorkspaceAgent = {
   wireguard_public_key: "",
   disco_public_key: "",
   ipv6: "",
+  version: "",
+  outdated: false,
+  health: {
+    healthy: true,
+    checks: [],
+  },
 }
 
 export const MockWorkspaceAgentDisconnected: TypesGen.WorkspaceAgent = {
@@ -349,6 +370,7 @@ export const MockAuthMethods: TypesGen.AuthMethods = {
   password: true,
   github: false,
   oidc: false,
+  oidc_providers: [],
 }
 
 export const MockGitSSHKey: TypesGen.GitSSHKey = {
@@ -139,6 +139,8 @@ export const getDisplayWorkspaceBuildStatus = (
 export const DisplayWorkspaceBuildInitiatedByLanguage = {
   autostart: "system/autostart",
   autostop: "system/autostop",
+  autodelete: "system/autodelete",
+  maintenance: "system/maintenance",
 }
 
 export const getDisplayWorkspaceBuildInitiatedBy = (build: TypesGen.WorkspaceBuild): string => {
@@ -149,6 +151,10 @@ export const getDisplayWorkspaceBuildInitiatedBy = (build: TypesGen.WorkspaceBui
       return DisplayWorkspaceBuildInitiatedByLanguage.autostart
     case "autostop":
       return DisplayWorkspaceBuildInitiatedByLanguage.autostop
+    case "autodelete":
+      return DisplayWorkspaceBuildInitiatedByLanguage.autodelete
+    case "maintenance":
+      return DisplayWorkspaceBuildInitiatedByLanguage.maintenance
   }
 }
 